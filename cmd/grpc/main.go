@@ -1,20 +1,47 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
 
+	"mastercom-service/internal/config"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/mtls"
+
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load configuration: %v", err)
+	}
+
 	lis, err := net.Listen("tcp", ":50000")
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	// Serve TLS when a certificate is configured, reading it again when its files change
+	var options []grpc.ServerOption
+	if cfg.TLSEnabled() {
+		certificates, err := mtls.NewCertificates(cfg.TLSOptions(), logger.NewDatadogLogger())
+		if err != nil {
+			log.Fatalf("failed to load TLS certificates: %v", err)
+		}
+		go certificates.Watch(context.Background())
+
+		allowlist := mtls.NewAllowlist(cfg.ClientSubjects)
+		options = append(options,
+			grpc.Creds(credentials.NewTLS(certificates.TLSConfig())),
+			grpc.ChainUnaryInterceptor(allowlist.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(allowlist.StreamServerInterceptor()),
+		)
+	}
+	s := grpc.NewServer(options...)
 
 	// Register your gRPC services here
 	// pb.RegisterYourServiceServer(s, &server{})
@@ -12,9 +12,11 @@ import (
 	"mastercom-service/internal/handlers"
 	"mastercom-service/pkg/logger"
 	"mastercom-service/pkg/middleware"
+	"mastercom-service/pkg/mtls"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/DataDog/datadog-go/v5/statsd"
 	"github.com/gin-gonic/gin"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
@@ -50,6 +52,69 @@ func main() {
 	handlers.InitHandlers(logger)
 	handlers.InitDocumentHandlers(logger)
 	handlers.InitEthocaWebhookHandlers(logger)
+	handlers.InitClaimHandlers(logger)
+	handlers.InitTransactionHandlers(logger)
+	handlers.InitQueueHandlers(logger)
+	handlers.InitReconReportHandlers(logger)
+	handlers.InitCaseFilingStatusHandlers(logger)
+	handlers.InitCaseDocumentHandlers(logger)
+	handlers.InitUploadHandlers(logger)
+	handlers.InitRetentionHandlers(logger)
+	handlers.InitPANHandlers(logger)
+	if err := handlers.InitEncryption(logger); err != nil {
+		panic("Failed to enable encryption at rest: " + err.Error())
+	}
+	authenticate, err := handlers.InitAuth(logger)
+	if err != nil {
+		panic("Failed to configure API authentication: " + err.Error())
+	}
+
+	// Report partner API rate limiting and circuit breaker state to DogStatsD
+	statsdClient, err := ddConfig.NewStatsdClient()
+	if err != nil {
+		logger.Error("Failed to create DogStatsD client", logrus.Fields{"error": err.Error()})
+		statsdClient = &statsd.NoOpClient{}
+	}
+	defer statsdClient.Close()
+	handlers.InitPartnerPolicies(statsdClient, logger)
+
+	// Start the case submission and queue sync workers when Mastercom credentials are configured
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	if submissionWorker := handlers.InitSubmissionWorker(logger); submissionWorker != nil {
+		go submissionWorker.Run(workerCtx)
+	}
+	if queueSyncWorker := handlers.InitQueueSyncWorker(logger); queueSyncWorker != nil {
+		go queueSyncWorker.Run(workerCtx)
+	}
+
+	// Discard resumable uploads abandoned part way through
+	if uploadExpiryWorker := handlers.InitUploadExpiryWorker(logger); uploadExpiryWorker != nil {
+		go uploadExpiryWorker.Run(workerCtx)
+	}
+
+	// Scan uploaded documents for malware when a clamd daemon is configured
+	if scanWorker := handlers.InitDocumentScanning(logger); scanWorker != nil {
+		go scanWorker.Run(workerCtx)
+	}
+
+	// Mask card numbers in uploaded PDFs when redaction is enabled
+	handlers.InitDocumentRedaction(logger)
+
+	// Purge evidence past its retention period when scheduled purges are enabled
+	if retentionWorker := handlers.InitRetentionWorker(logger); retentionWorker != nil {
+		go retentionWorker.Run(workerCtx)
+	}
+
+	// Serve TLS when a certificate is configured, reading it again when its files change
+	var certificates *mtls.Certificates
+	if cfg.TLSEnabled() {
+		certificates, err = mtls.NewCertificates(cfg.TLSOptions(), logger)
+		if err != nil {
+			panic("Failed to load TLS certificates: " + err.Error())
+		}
+		go certificates.Watch(workerCtx)
+	}
 
 	// Initialize router
 	router := gin.New()
@@ -59,6 +124,8 @@ func main() {
 	router.Use(middleware.Logger(logger))
 	router.Use(middleware.CORS())
 	router.Use(middleware.DatadogMiddleware())
+	router.Use(middleware.ClientCertificate(mtls.NewAllowlist(cfg.ClientSubjects), logger))
+	router.Use(authenticate...)
 
 	// Template-style health check endpoint
 	router.GET("/__ops/ping", func(c *gin.Context) {
@@ -72,34 +139,123 @@ func main() {
 
 		logger.InfoWithSpan(span, "Health check requested", nil)
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"status":      "ok",
 			"service":     "mastercom-service",
 			"version":     "v1.0.0",
 			"dd_trace_id": span.Context().TraceID(),
 			"dd_span_id":  span.Context().SpanID(),
-		})
+		}
+		if syncStatus := handlers.QueueSyncStatus(); syncStatus != nil {
+			response["queueSync"] = syncStatus
+			if syncStatus.Stale {
+				response["status"] = "degraded"
+			}
+		}
+		if partners, degraded := handlers.PartnerStatus(); len(partners) > 0 {
+			response["partners"] = partners
+			if degraded {
+				response["status"] = "degraded"
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
 	})
 
 	// API routes
 	api := router.Group("/api/v6")
 	{
 		// Case Filing endpoints
-		cases := api.Group("/cases")
+		cases := api.Group("/cases", handlers.RequireCaseScope)
 		{
 			cases.POST("", handlers.CreateCase)
 			cases.GET("", handlers.ListCases)
 			cases.GET("/:id", handlers.GetCase)
 			cases.PUT("/:id", handlers.UpdateCase)
-			cases.DELETE("/:id", handlers.DeleteCase)
+			cases.DELETE("/:id", handlers.DeleteCaseWithDocuments)
+			cases.POST("/from-transaction", handlers.CreateCaseFromTransaction)
+			cases.PUT("/status", handlers.GetCaseFilingStatus)
+			cases.PUT("/imagestatus", handlers.GetCaseFilingImageStatus)
+			cases.POST("/:id/acknowledge", handlers.AcknowledgeCase)
+			cases.POST("/:id/reject", handlers.RejectCase)
+			cases.POST("/:id/submit", handlers.SubmitCase)
+			cases.POST("/:id/close", handlers.CloseCase)
+			cases.POST("/:id/documents", handlers.AttachCaseDocument)
+			cases.GET("/:id/documents", handlers.ListCaseDocuments)
+			cases.POST("/:id/documents/bundle", handlers.CreateEvidenceBundle)
+			cases.GET("/:id/export", handlers.ExportCase)
+			cases.PUT("/:id/legal-hold", handlers.PlaceLegalHold)
+			cases.DELETE("/:id/legal-hold", handlers.ReleaseLegalHold)
+			cases.POST("/:id/pan/detokenize", handlers.DetokenizeCasePAN)
+		}
+
+		// Queue endpoints
+		queues := api.Group("/queues")
+		{
+			queues.GET("", handlers.GetQueue)
+			queues.POST("", handlers.GetQueueContent)
+			queues.GET("/names", handlers.GetQueueNames)
+			queues.GET("/counts", handlers.GetQueueCounts)
+		}
+
+		// Reconciliation report endpoints
+		reconReport := api.Group("/reconreport/data")
+		{
+			reconReport.POST("/request", handlers.RequestReconReport)
+			reconReport.POST("/retrieval/:reportIdentifier", handlers.RetrieveReconReport)
+			reconReport.GET("/retrieval/:reportIdentifier", handlers.DownloadReconReport)
+		}
+
+		// Transaction endpoints
+		transactions := api.Group("/transactions")
+		{
+			transactions.POST("/search", handlers.SearchTransactions)
+		}
+
+		// Claim endpoints
+		claims := api.Group("/claims")
+		{
+			claims.GET("/:claimId", handlers.GetClaim)
+			claims.GET("/:claimId/transactions/clearing/:id", handlers.GetClearingDetail)
+			claims.GET("/:claimId/transactions/authorization/:id", handlers.GetAuthorizationDetail)
 		}
 
 		// Document endpoints
-		documents := api.Group("/documents")
+		documents := api.Group("/documents", handlers.RequireDocumentScope)
 		{
 			documents.POST("", handlers.UploadDocument)
 			documents.GET("/:id", handlers.GetDocument)
 			documents.DELETE("/:id", handlers.DeleteDocument)
+			documents.POST("/:id/restore", handlers.RestoreDocument)
+			documents.PUT("/:id/content", handlers.ReplaceDocumentContent)
+			documents.GET("/:id/versions", handlers.ListDocumentVersions)
+			documents.GET("/:id/versions/:version", handlers.GetDocumentVersion)
+		}
+
+		// Resumable upload endpoints (tus protocol)
+		uploads := api.Group("/uploads")
+		{
+			uploads.POST("", handlers.CreateUpload)
+			uploads.HEAD("/:id", handlers.GetUploadOffset)
+			uploads.PATCH("/:id", handlers.PatchUpload)
+			uploads.DELETE("/:id", handlers.DeleteUpload)
+			uploads.POST("/:id/finalize", handlers.FinalizeUpload)
+		}
+
+		// Evidence retention endpoints
+		retention := api.Group("/retention")
+		{
+			retention.GET("/policies", handlers.GetRetentionPolicies)
+			retention.POST("/purge", handlers.PurgeEvidence)
+		}
+
+		// Administration endpoints
+		admin := api.Group("/admin")
+		{
+			admin.POST("/encryption/rotate", handlers.RotateEncryptionKeys)
+			admin.POST("/api-keys", handlers.CreateAPIKey)
+			admin.GET("/api-keys", handlers.ListAPIKeys)
+			admin.DELETE("/api-keys/:id", handlers.RevokeAPIKey)
 		}
 
 		// Ethoca Webhook endpoints
@@ -119,6 +275,11 @@ func main() {
 		Addr:    ":" + cfg.Port,
 		Handler: router,
 	}
+	serve := srv.ListenAndServe
+	if certificates != nil {
+		srv.TLSConfig = certificates.TLSConfig()
+		serve = func() error { return srv.ListenAndServeTLS("", "") }
+	}
 
 	// Start server in a goroutine
 	go func() {
@@ -127,9 +288,10 @@ func main() {
 			"environment": cfg.Environment,
 			"dd_service":  ddConfig.ServiceName,
 			"dd_env":      ddConfig.Environment,
+			"tls":         certificates != nil,
 		})
 
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			logger.Error("Failed to start server", logrus.Fields{"error": err.Error()})
 			os.Exit(1)
 		}
@@ -141,6 +303,7 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...", nil)
+	stopWorkers()
 
 	// Create a deadline for server shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
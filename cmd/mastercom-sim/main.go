@@ -0,0 +1,114 @@
+// Command mastercom-sim serves an in-memory Mastercom API for offline development and
+// integration testing. Point MASTERCOM_BASE_URL at it to exercise the service end to end.
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"mastercom-service/internal/simulator"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", getEnv("SIM_ADDR", ":8089"), "listen address")
+	spec := flag.String("spec", getEnv("SIM_SPEC", "mastercom-swagger.yaml"), "OpenAPI spec whose samples back the stateless operations; empty to disable")
+	imageDelay := flag.Duration("image-delay", getEnvDuration("SIM_IMAGE_DELAY", 5*time.Second), "time before uploaded documents are COMPLETED")
+	reconDelay := flag.Duration("recon-delay", getEnvDuration("SIM_RECON_DELAY", 5*time.Second), "time before requested recon reports are Available")
+	pageSize := flag.Int("page-size", getEnvInt("SIM_PAGE_SIZE", 100), "claims per queue content page")
+	faultsFile := flag.String("faults", getEnv("SIM_FAULTS", ""), "JSON file of fault rules installed at startup")
+	seed := flag.Int64("seed", int64(getEnvInt("SIM_SEED", 0)), "random seed for probabilistic faults; 0 seeds from the clock")
+	requireOAuth := flag.Bool("require-oauth", getEnv("SIM_REQUIRE_OAUTH", "false") == "true", "reject requests without an OAuth Authorization header")
+	flag.Parse()
+
+	logger := logger.NewDatadogLogger()
+	gin.SetMode(gin.ReleaseMode)
+
+	options := simulator.Options{
+		SpecPath:     *spec,
+		ImageDelay:   *imageDelay,
+		ReconDelay:   *reconDelay,
+		PageSize:     *pageSize,
+		Seed:         *seed,
+		RequireOAuth: *requireOAuth,
+	}
+	if *faultsFile != "" {
+		faults, err := simulator.LoadFaults(*faultsFile)
+		if err != nil {
+			logger.Error("Failed to load fault rules", logrus.Fields{"error": err.Error()})
+			os.Exit(1)
+		}
+		options.Faults = faults
+	}
+
+	sim, err := simulator.New(options, logger)
+	if err != nil {
+		logger.Error("Failed to create simulator", logrus.Fields{"error": err.Error()})
+		os.Exit(1)
+	}
+
+	srv := &http.Server{
+		Addr:    *addr,
+		Handler: sim.Handler(),
+	}
+
+	go func() {
+		logger.Info("Starting Mastercom simulator", logrus.Fields{
+			"addr":       *addr,
+			"spec":       *spec,
+			"imageDelay": imageDelay.String(),
+			"reconDelay": reconDelay.String(),
+			"faults":     len(options.Faults),
+		})
+
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Failed to start simulator", logrus.Fields{"error": err.Error()})
+			os.Exit(1)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("Shutting down simulator...", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("Simulator forced to shutdown", logrus.Fields{"error": err.Error()})
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,63 @@
+// Command rotate-keys rotates the key-encryption keys of a running service. Add a new key
+// first in the service's ENCRYPTION_KEYS_FILE, generated with -generate, then run the command
+// to have every stored data key re-wrapped with it. Once it succeeds, keys that are no longer
+// primary can be removed from the file.
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"mastercom-service/pkg/envelope"
+)
+
+func main() {
+	serviceURL := flag.String("url", getEnv("SERVICE_URL", "http://localhost:8080"), "base URL of the service")
+	generate := flag.String("generate", "", "print a new key entry with this key ID for the keys file, and exit")
+	timeout := flag.Duration("timeout", 5*time.Minute, "time allowed for the rotation")
+	apiKey := flag.String("api-key", getEnv("SERVICE_API_KEY", ""), "API key of an admin, when the service requires authentication")
+	flag.Parse()
+
+	if *generate != "" {
+		fmt.Printf("%s:%s\n", *generate, base64.StdEncoding.EncodeToString(envelope.GenerateKey()))
+		return
+	}
+
+	request, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(*serviceURL, "/")+"/api/v6/admin/encryption/rotate", nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Key rotation failed:", err)
+		os.Exit(1)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if *apiKey != "" {
+		request.Header.Set("X-API-Key", *apiKey)
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Key rotation failed:", err)
+		os.Exit(1)
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Key rotation failed: %s: %s\n", response.Status, body)
+		os.Exit(1)
+	}
+	fmt.Println(string(body))
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
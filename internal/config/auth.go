@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadAuthConfig loads API authentication configuration from environment variables.
+// AUTH_API_KEYS lists API keys as name:sha256Hex:roles:icas, separated by commas, where roles
+// and ICAs are separated by | and the ICA * stands for every ICA. AUTH_JWKS_FILE takes
+// precedence over AUTH_JWKS_URL. An AUTH_ENABLED that is not a boolean, and authentication
+// that is enabled with an entry that cannot be parsed or with no way to authenticate, are
+// errors, so a mistyped setting never turns authentication off.
+func LoadAuthConfig() (*models.AuthConfig, error) {
+	enabled, err := strconv.ParseBool(getEnv("AUTH_ENABLED", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_ENABLED must be true or false, got %q", getEnv("AUTH_ENABLED", ""))
+	}
+	refreshInterval, _ := strconv.Atoi(getEnv("AUTH_JWKS_REFRESH_INTERVAL", "3600"))
+	if refreshInterval <= 0 {
+		refreshInterval = 3600
+	}
+
+	config := &models.AuthConfig{
+		Enabled:             enabled,
+		JWKSSource:          getEnv("AUTH_JWKS_FILE", getEnv("AUTH_JWKS_URL", "")),
+		JWKSRefreshInterval: refreshInterval,
+		JWTIssuer:           getEnv("AUTH_JWT_ISSUER", ""),
+		JWTAudience:         getEnv("AUTH_JWT_AUDIENCE", ""),
+		JWTRolesClaim:       getEnv("AUTH_JWT_ROLES_CLAIM", "roles"),
+		JWTICAsClaim:        getEnv("AUTH_JWT_ICAS_CLAIM", "icas"),
+		AdminRoles:          splitList(getEnv("AUTH_ADMIN_ROLES", "admin"), ","),
+	}
+	if !enabled {
+		return config, nil
+	}
+
+	apiKeys, err := parseAPIKeys(getEnv("AUTH_API_KEYS", ""))
+	if err != nil {
+		return nil, err
+	}
+	config.APIKeys = apiKeys
+	if len(config.APIKeys) == 0 && config.JWKSSource == "" {
+		return nil, fmt.Errorf("authentication is enabled but neither AUTH_API_KEYS nor a JWKS is configured")
+	}
+	return config, nil
+}
+
+func parseAPIKeys(value string) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	seen := make(map[string]bool)
+	for _, entry := range splitList(value, ",") {
+		fields := strings.Split(entry, ":")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("API key entries must be name:sha256Hex:roles:icas")
+		}
+		name, hash := strings.TrimSpace(fields[0]), strings.ToLower(strings.TrimSpace(fields[1]))
+		if name == "" {
+			return nil, fmt.Errorf("API key entries must be name:sha256Hex:roles:icas")
+		}
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("API key %q: hash must be 64 hex digits of SHA-256", name)
+		}
+		roles, icas := splitList(fields[2], "|"), splitList(fields[3], "|")
+		if len(roles) == 0 {
+			return nil, fmt.Errorf("API key %q has no roles", name)
+		}
+		if len(icas) == 0 {
+			return nil, fmt.Errorf("API key %q has no ICAs", name)
+		}
+		if seen[name] || seen[hash] {
+			return nil, fmt.Errorf("API key %q is listed twice", name)
+		}
+		seen[name], seen[hash] = true, true
+		apiKeys = append(apiKeys, models.APIKey{Name: name, Hash: hash, Roles: roles, ICAs: icas})
+	}
+	return apiKeys, nil
+}
+
+// splitList splits value on sep, dropping empty entries
+func splitList(value, sep string) []string {
+	var values []string
+	for _, entry := range strings.Split(value, sep) {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+	return values
+}
@@ -1,19 +1,69 @@
 package config
 
 import (
+	"fmt"
+	"strings"
+
+	"mastercom-service/pkg/mtls"
+
 	"github.com/spf13/viper"
 )
 
+// Client certificate requirements of TLS_CLIENT_AUTH
+const (
+	// ClientAuthOptional verifies client certificates that are sent, for routes that need them
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire refuses connections without a verified client certificate
+	ClientAuthRequire = "require"
+)
+
 type Config struct {
 	Environment string `mapstructure:"ENVIRONMENT"`
 	Port        string `mapstructure:"PORT"`
 	LogLevel    string `mapstructure:"LOG_LEVEL"`
+
+	// TLSCertFile and TLSKeyFile, when set, serve the HTTP and gRPC listeners over TLS
+	TLSCertFile string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile  string `mapstructure:"TLS_KEY_FILE"`
+	// TLSClientCAFile is the bundle of CAs verifying client certificates
+	TLSClientCAFile string `mapstructure:"TLS_CLIENT_CA_FILE"`
+	// TLSClientAuth is ClientAuthOptional or ClientAuthRequire
+	TLSClientAuth string `mapstructure:"TLS_CLIENT_AUTH"`
+	// TLSClientSubjects lists the client certificate subjects allowed each route, parsed into
+	// ClientSubjects
+	TLSClientSubjects string `mapstructure:"TLS_CLIENT_SUBJECTS"`
+	// ClientSubjects maps route prefixes, HTTP paths or gRPC methods, to the subjects allowed them
+	ClientSubjects map[string][]string `mapstructure:"-"`
+}
+
+// TLSEnabled reports whether the listeners serve TLS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
+// TLSOptions returns the certificates the listeners serve TLS with
+func (c *Config) TLSOptions() mtls.Options {
+	return mtls.Options{
+		CertFile:          c.TLSCertFile,
+		KeyFile:           c.TLSKeyFile,
+		ClientCAFile:      c.TLSClientCAFile,
+		RequireClientCert: c.TLSClientAuth == ClientAuthRequire,
+	}
 }
 
+// Load reads the service configuration from environment variables. TLS_CLIENT_SUBJECTS lists
+// route prefixes and the client certificate subjects allowed them as
+// prefix=subject|subject, separated by semicolons. TLS settings that are incomplete or
+// inconsistent are an error.
 func Load() (*Config, error) {
 	viper.SetDefault("ENVIRONMENT", "development")
 	viper.SetDefault("PORT", "8080")
 	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("TLS_CERT_FILE", "")
+	viper.SetDefault("TLS_KEY_FILE", "")
+	viper.SetDefault("TLS_CLIENT_CA_FILE", "")
+	viper.SetDefault("TLS_CLIENT_AUTH", ClientAuthOptional)
+	viper.SetDefault("TLS_CLIENT_SUBJECTS", "")
 
 	viper.AutomaticEnv()
 
@@ -21,6 +71,50 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
+	if err := config.checkTLS(); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
+
+func (c *Config) checkTLS() error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLSClientCAFile != "" && !c.TLSEnabled() {
+		return fmt.Errorf("TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+	if c.TLSClientAuth != ClientAuthOptional && c.TLSClientAuth != ClientAuthRequire {
+		return fmt.Errorf("TLS_CLIENT_AUTH must be %s or %s", ClientAuthOptional, ClientAuthRequire)
+	}
+
+	subjects, err := parseClientSubjects(c.TLSClientSubjects)
+	if err != nil {
+		return err
+	}
+	if len(subjects) > 0 && c.TLSClientCAFile == "" {
+		return fmt.Errorf("TLS_CLIENT_SUBJECTS needs TLS_CLIENT_CA_FILE")
+	}
+	c.ClientSubjects = subjects
+	return nil
+}
+
+func parseClientSubjects(value string) (map[string][]string, error) {
+	subjects := make(map[string][]string)
+	for _, entry := range splitList(value, ";") {
+		prefix, allowed, found := strings.Cut(entry, "=")
+		prefix = strings.TrimSpace(prefix)
+		if !found || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("TLS_CLIENT_SUBJECTS entries must be /prefix=subject|subject")
+		}
+		if _, exists := subjects[prefix]; exists {
+			return nil, fmt.Errorf("TLS_CLIENT_SUBJECTS lists %s twice", prefix)
+		}
+		subjects[prefix] = splitList(allowed, "|")
+		if len(subjects[prefix]) == 0 {
+			return nil, fmt.Errorf("TLS_CLIENT_SUBJECTS allows no subjects for %s", prefix)
+		}
+	}
+	return subjects, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/DataDog/datadog-go/v5/statsd"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/profiler"
 )
@@ -15,6 +16,7 @@ type DatadogConfig struct {
 	Version     string  `mapstructure:"DD_VERSION"`
 	AgentHost   string  `mapstructure:"DD_AGENT_HOST"`
 	AgentPort   string  `mapstructure:"DD_AGENT_PORT"`
+	StatsdPort  string  `mapstructure:"DD_DOGSTATSD_PORT"`
 	LogLevel    string  `mapstructure:"DD_LOG_LEVEL"`
 	TraceRate   float64 `mapstructure:"DD_TRACE_SAMPLE_RATE"`
 }
@@ -27,6 +29,7 @@ func LoadDatadogConfig() *DatadogConfig {
 		Version:     getEnv("DD_VERSION", "1.0.0"),
 		AgentHost:   getEnv("DD_AGENT_HOST", "localhost"),
 		AgentPort:   getEnv("DD_AGENT_PORT", "8126"),
+		StatsdPort:  getEnv("DD_DOGSTATSD_PORT", "8125"),
 		LogLevel:    getEnv("DD_LOG_LEVEL", "info"),
 		TraceRate:   getEnvFloat("DD_TRACE_SAMPLE_RATE", 1.0),
 	}
@@ -67,6 +70,27 @@ func (c *DatadogConfig) StartProfiler() {
 	)
 }
 
+// NewStatsdClient creates a DogStatsD client tagged with the service, environment and version.
+// It returns a no-op client when Datadog is disabled.
+func (c *DatadogConfig) NewStatsdClient() (statsd.ClientInterface, error) {
+	if !c.Enabled {
+		return &statsd.NoOpClient{}, nil
+	}
+
+	client, err := statsd.New(
+		c.AgentHost+":"+c.StatsdPort,
+		statsd.WithTags([]string{
+			"service:" + c.ServiceName,
+			"env:" + c.Environment,
+			"version:" + c.Version,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 func (c *DatadogConfig) Stop() {
 	if !c.Enabled {
 		return
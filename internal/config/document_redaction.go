@@ -0,0 +1,25 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadRedactionConfig loads card number redaction configuration from environment variables
+func LoadRedactionConfig() *models.RedactionConfig {
+	enabled, _ := strconv.ParseBool(getEnv("DOCUMENT_REDACTION_ENABLED", "false"))
+
+	var roles []string
+	for _, role := range strings.Split(getEnv("DOCUMENT_ORIGINAL_ROLES", "supervisor,admin"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+
+	return &models.RedactionConfig{
+		Enabled:       enabled,
+		OriginalRoles: roles,
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"strconv"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadScanConfig loads document malware scanning configuration from environment variables
+func LoadScanConfig() *models.ScanConfig {
+	timeout, _ := strconv.Atoi(getEnv("CLAMD_TIMEOUT", "30"))
+	rescanInterval, _ := strconv.Atoi(getEnv("DOCUMENT_RESCAN_INTERVAL", "60"))
+	maxAttempts, _ := strconv.Atoi(getEnv("DOCUMENT_SCAN_MAX_ATTEMPTS", "5"))
+
+	return &models.ScanConfig{
+		ClamdAddress:   getEnv("CLAMD_ADDRESS", ""),
+		Timeout:        timeout,
+		RescanInterval: rescanInterval,
+		MaxAttempts:    maxAttempts,
+	}
+}
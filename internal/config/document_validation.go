@@ -0,0 +1,29 @@
+package config
+
+import (
+	"strconv"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/docformat"
+)
+
+// LoadDocumentValidationConfig loads the formats accepted for case evidence from environment
+// variables. Mastercom accepts PDF, TIFF and JPEG evidence; PNG uploads are accepted too and
+// converted before submission.
+func LoadDocumentValidationConfig() *models.DocumentValidationConfig {
+	pdfMaxSize, _ := strconv.ParseInt(getEnv("DOCUMENT_PDF_MAX_SIZE", "15728640"), 10, 64)
+	pdfMaxPages, _ := strconv.Atoi(getEnv("DOCUMENT_PDF_MAX_PAGES", "100"))
+	tiffMaxSize, _ := strconv.ParseInt(getEnv("DOCUMENT_TIFF_MAX_SIZE", "15728640"), 10, 64)
+	tiffMaxPages, _ := strconv.Atoi(getEnv("DOCUMENT_TIFF_MAX_PAGES", "100"))
+	jpegMaxSize, _ := strconv.ParseInt(getEnv("DOCUMENT_JPEG_MAX_SIZE", "5242880"), 10, 64)
+	pngMaxSize, _ := strconv.ParseInt(getEnv("DOCUMENT_PNG_MAX_SIZE", "5242880"), 10, 64)
+
+	return &models.DocumentValidationConfig{
+		Types: []models.DocumentTypeRule{
+			{MIMEType: docformat.MIMETypePDF, Extensions: []string{".pdf"}, MaxSize: pdfMaxSize, MaxPages: pdfMaxPages},
+			{MIMEType: docformat.MIMETypeTIFF, Extensions: []string{".tif", ".tiff"}, MaxSize: tiffMaxSize, MaxPages: tiffMaxPages},
+			{MIMEType: docformat.MIMETypeJPEG, Extensions: []string{".jpg", ".jpeg"}, MaxSize: jpegMaxSize},
+			{MIMEType: docformat.MIMETypePNG, Extensions: []string{".png"}, MaxSize: pngMaxSize},
+		},
+	}
+}
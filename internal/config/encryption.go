@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadEncryptionConfig loads the key-encryption keys for data at rest. They are listed as
+// keyId:base64Key, separated by commas or newlines, in the file named by ENCRYPTION_KEYS_FILE
+// or else in ENCRYPTION_KEYS. The first key listed is the primary key. Encryption is disabled
+// when neither is set; a key file that cannot be read or an entry that cannot be parsed is an
+// error, so that sensitive data is never stored unencrypted by mistake.
+func LoadEncryptionConfig() (*models.EncryptionConfig, error) {
+	config := &models.EncryptionConfig{
+		Keys:     make(map[string][]byte),
+		KeysFile: getEnv("ENCRYPTION_KEYS_FILE", ""),
+	}
+
+	value := getEnv("ENCRYPTION_KEYS", "")
+	if config.KeysFile != "" {
+		content, err := os.ReadFile(config.KeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading encryption keys: %w", err)
+		}
+		value = string(content)
+	}
+
+	for _, entry := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, found := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !found || id == "" {
+			return nil, fmt.Errorf("encryption key entries must be keyId:base64Key")
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("decoding encryption key %s: %w", id, err)
+		}
+		if _, exists := config.Keys[id]; exists {
+			return nil, fmt.Errorf("encryption key %s is listed twice", id)
+		}
+		if config.PrimaryKeyID == "" {
+			config.PrimaryKeyID = id
+		}
+		config.Keys[id] = key
+	}
+
+	return config, nil
+}
@@ -18,5 +18,6 @@ func LoadEthocaConfig() *models.WebhookConfig {
 		Timeout:    timeout,
 		MaxRetries: maxRetries,
 		BatchSize:  batchSize,
+		ICA:        getEnv("ETHOCA_ICA", ""),
 	}
 }
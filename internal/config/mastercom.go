@@ -0,0 +1,63 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/mastercom"
+)
+
+// LoadMastercomConfig loads outbound Mastercom API configuration from environment variables
+func LoadMastercomConfig() *models.MastercomConfig {
+	timeout, _ := strconv.Atoi(getEnv("MASTERCOM_TIMEOUT", "30"))
+	rateBurst, _ := strconv.Atoi(getEnv("MASTERCOM_RATE_BURST", "10"))
+	maxConcurrent, _ := strconv.Atoi(getEnv("MASTERCOM_MAX_CONCURRENT", "10"))
+	queueTimeout, _ := strconv.Atoi(getEnv("MASTERCOM_QUEUE_TIMEOUT", "5"))
+	breakerFailures, _ := strconv.Atoi(getEnv("MASTERCOM_BREAKER_FAILURES", "5"))
+	breakerOpenTimeout, _ := strconv.Atoi(getEnv("MASTERCOM_BREAKER_OPEN_TIMEOUT", "30"))
+	breakerHalfOpenProbes, _ := strconv.Atoi(getEnv("MASTERCOM_BREAKER_HALF_OPEN_PROBES", "1"))
+	maxRetries, _ := strconv.Atoi(getEnv("MASTERCOM_MAX_RETRIES", "2"))
+	retryBackoff, _ := strconv.Atoi(getEnv("MASTERCOM_RETRY_BACKOFF", "1"))
+	maxRetryAfter, _ := strconv.Atoi(getEnv("MASTERCOM_MAX_RETRY_AFTER", "10"))
+	retryBudgetBurst, _ := strconv.Atoi(getEnv("MASTERCOM_RETRY_BUDGET_BURST", "10"))
+
+	return &models.MastercomConfig{
+		BaseURL:               getEnv("MASTERCOM_BASE_URL", mastercom.SandboxBaseURL),
+		ConsumerKey:           getEnv("MASTERCOM_CONSUMER_KEY", ""),
+		KeystorePath:          getEnv("MASTERCOM_KEYSTORE_PATH", ""),
+		KeystorePassword:      getEnv("MASTERCOM_KEYSTORE_PASSWORD", ""),
+		Timeout:               timeout,
+		RateLimit:             getEnvFloat("MASTERCOM_RATE_LIMIT", 20),
+		RateBurst:             rateBurst,
+		EndpointRateLimits:    parseEndpointRateLimits(getEnv("MASTERCOM_ENDPOINT_RATE_LIMITS", "")),
+		MaxConcurrent:         maxConcurrent,
+		QueueTimeout:          queueTimeout,
+		BreakerFailures:       breakerFailures,
+		BreakerOpenTimeout:    breakerOpenTimeout,
+		BreakerHalfOpenProbes: breakerHalfOpenProbes,
+		MaxRetries:            maxRetries,
+		RetryBackoff:          retryBackoff,
+		MaxRetryAfter:         maxRetryAfter,
+		RetryBudget:           getEnvFloat("MASTERCOM_RETRY_BUDGET", 0.2),
+		RetryBudgetBurst:      retryBudgetBurst,
+	}
+}
+
+// parseEndpointRateLimits parses a comma separated list like
+// "PUT /v6/chargebacks/acknowledge=2,POST /v6/queues=5", skipping malformed entries
+func parseEndpointRateLimits(value string) map[string]float64 {
+	limits := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		endpoint, limit, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(limit), 64)
+		if err != nil {
+			continue
+		}
+		limits[strings.Join(strings.Fields(endpoint), " ")] = rate
+	}
+	return limits
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"strings"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadTokenizationConfig loads card number tokenization configuration from environment variables
+func LoadTokenizationConfig() *models.TokenizationConfig {
+	var roles []string
+	for _, role := range strings.Split(getEnv("PAN_DETOKENIZE_ROLES", "supervisor,admin"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+
+	return &models.TokenizationConfig{DetokenizeRoles: roles}
+}
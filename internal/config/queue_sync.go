@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadQueueSyncConfig loads Mastercom queue sync configuration from environment variables
+func LoadQueueSyncConfig() *models.QueueSyncConfig {
+	enabled, _ := strconv.ParseBool(getEnv("QUEUE_SYNC_ENABLED", "true"))
+	interval, _ := strconv.Atoi(getEnv("QUEUE_SYNC_INTERVAL", "300"))
+	initialLookback, _ := strconv.Atoi(getEnv("QUEUE_SYNC_INITIAL_LOOKBACK", "24"))
+	maxLag, _ := strconv.Atoi(getEnv("QUEUE_SYNC_MAX_LAG", "1800"))
+
+	var queues []string
+	for _, queue := range strings.Split(getEnv("QUEUE_SYNC_QUEUES", ""), ",") {
+		if queue = strings.TrimSpace(queue); queue != "" {
+			queues = append(queues, queue)
+		}
+	}
+
+	return &models.QueueSyncConfig{
+		Enabled:         enabled,
+		Interval:        interval,
+		Queues:          queues,
+		InitialLookback: initialLookback,
+		MaxLag:          maxLag,
+		StateFile:       getEnv("QUEUE_SYNC_STATE_FILE", filepath.Join(os.TempDir(), "mastercom-queue-sync.json")),
+	}
+}
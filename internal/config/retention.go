@@ -0,0 +1,55 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadRetentionConfig loads evidence retention configuration from environment variables.
+// RETENTION_POLICIES lists policies as caseType:queue:documentDays:caseDays, separated by
+// commas, where caseType and queue may be *; malformed entries are ignored.
+func LoadRetentionConfig() *models.RetentionConfig {
+	enabled, _ := strconv.ParseBool(getEnv("RETENTION_ENABLED", "false"))
+	dryRun, _ := strconv.ParseBool(getEnv("RETENTION_DRY_RUN", "false"))
+	interval, _ := strconv.Atoi(getEnv("RETENTION_PURGE_INTERVAL", "86400"))
+	deletedDocumentDays, _ := strconv.Atoi(getEnv("RETENTION_DELETED_DOCUMENT_DAYS", "30"))
+
+	return &models.RetentionConfig{
+		Enabled:             enabled,
+		DryRun:              dryRun,
+		Interval:            interval,
+		DeletedDocumentDays: deletedDocumentDays,
+		Policies:            parseRetentionPolicies(getEnv("RETENTION_POLICIES", "")),
+	}
+}
+
+func parseRetentionPolicies(value string) []models.RetentionPolicy {
+	var policies []models.RetentionPolicy
+	for _, entry := range strings.Split(value, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ":")
+		if len(fields) != 4 {
+			continue
+		}
+		documentDays, err := strconv.Atoi(strings.TrimSpace(fields[2]))
+		if err != nil || documentDays < 0 {
+			continue
+		}
+		caseDays, err := strconv.Atoi(strings.TrimSpace(fields[3]))
+		if err != nil || caseDays < 0 {
+			continue
+		}
+		caseType, queue := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1])
+		if caseType == "" || queue == "" {
+			continue
+		}
+		policies = append(policies, models.RetentionPolicy{
+			CaseType:     caseType,
+			Queue:        queue,
+			DocumentDays: documentDays,
+			CaseDays:     caseDays,
+		})
+	}
+	return policies
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"strconv"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadSubmissionConfig loads case submission worker configuration from environment variables
+func LoadSubmissionConfig() *models.SubmissionConfig {
+	enabled, _ := strconv.ParseBool(getEnv("SUBMISSION_WORKER_ENABLED", "true"))
+	interval, _ := strconv.Atoi(getEnv("SUBMISSION_WORKER_INTERVAL", "30"))
+	maxAttempts, _ := strconv.Atoi(getEnv("SUBMISSION_MAX_ATTEMPTS", "5"))
+	backoffBase, _ := strconv.Atoi(getEnv("SUBMISSION_BACKOFF_BASE", "30"))
+	backoffMax, _ := strconv.Atoi(getEnv("SUBMISSION_BACKOFF_MAX", "1800"))
+
+	return &models.SubmissionConfig{
+		Enabled:     enabled,
+		Interval:    interval,
+		MaxAttempts: maxAttempts,
+		BackoffBase: backoffBase,
+		BackoffMax:  backoffMax,
+	}
+}
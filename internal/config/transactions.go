@@ -0,0 +1,19 @@
+package config
+
+import (
+	"strconv"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadTransactionSourceConfig loads transaction source configuration from environment variables
+func LoadTransactionSourceConfig() *models.TransactionSourceConfig {
+	maxSearchRangeDays, _ := strconv.Atoi(getEnv("TRANSACTION_SEARCH_MAX_RANGE_DAYS", "30"))
+	maxHistoryDays, _ := strconv.Atoi(getEnv("TRANSACTION_SEARCH_MAX_HISTORY_DAYS", "730"))
+
+	return &models.TransactionSourceConfig{
+		FilePath:           getEnv("TRANSACTION_SOURCE_FILE", ""),
+		MaxSearchRangeDays: maxSearchRangeDays,
+		MaxHistoryDays:     maxHistoryDays,
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"strconv"
+
+	"mastercom-service/internal/models"
+)
+
+// LoadUploadConfig loads resumable upload configuration from environment variables
+func LoadUploadConfig() *models.UploadConfig {
+	expiry, _ := strconv.Atoi(getEnv("UPLOAD_EXPIRY", "86400"))
+	cleanupInterval, _ := strconv.Atoi(getEnv("UPLOAD_CLEANUP_INTERVAL", "600"))
+
+	return &models.UploadConfig{
+		Expiry:          expiry,
+		CleanupInterval: cleanupInterval,
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/jwt"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// authExemptPaths are served without authentication, so probes need no credentials
+var authExemptPaths = []string{"/__ops/ping", "/health", "/api/v6/webhooks/ethoca/health"}
+
+// APIKeyHandler serves the management of the API keys callers authenticate with
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+	config        *models.AuthConfig
+	logger        *logger.DatadogLogger
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService, config *models.AuthConfig, logger *logger.DatadogLogger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		config:        config,
+		logger:        logger,
+	}
+}
+
+// authorize answers with 403 unless the caller's principal holds one of the admin roles
+func (h *APIKeyHandler) authorize(c *gin.Context, span tracer.Span) bool {
+	if middleware.PrincipalFrom(c).HasRole(h.config.AdminRoles...) {
+		return true
+	}
+	h.logger.ErrorWithSpan(span, "API key management denied", logrus.Fields{"subject": principalSubject(c)})
+	span.SetTag("error", true)
+	span.SetTag("error.message", "API key management requires an admin role")
+	c.JSON(http.StatusForbidden, gin.H{"error": "API key management requires an admin role"})
+	return false
+}
+
+// CreateAPIKey handles issuing an API key. The key is only returned in this response.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	span := tracer.StartSpan("auth.api_key.create", tracer.ResourceName("CreateAPIKey"))
+	defer span.Finish()
+
+	if !h.authorize(c, span) {
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	apiKey, key, err := h.apiKeyService.CreateAPIKey(req.Name, req.Roles, req.ICAs, principalSubject(c))
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to create API key", logrus.Fields{
+			"name":  req.Name,
+			"error": err.Error(),
+		})
+		span.SetTag("error", true)
+		if errors.Is(err, services.ErrAPIKeyExists) {
+			span.SetTag("error.message", "API key name already in use")
+			c.JSON(http.StatusConflict, gin.H{"error": "API key name already in use"})
+			return
+		}
+		span.SetTag("error.message", "Failed to create API key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	span.SetTag("api_key.id", apiKey.ID)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, models.CreateAPIKeyResponse{APIKey: apiKey, Key: key})
+}
+
+// ListAPIKeys handles listing API keys, without the keys themselves
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	span := tracer.StartSpan("auth.api_key.list", tracer.ResourceName("ListAPIKeys"))
+	defer span.Finish()
+
+	if !h.authorize(c, span) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"apiKeys": h.apiKeyService.ListAPIKeys()})
+}
+
+// RevokeAPIKey handles revoking an API key, which is answered with 401 from then on
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id := c.Param("id")
+
+	span := tracer.StartSpan("auth.api_key.revoke", tracer.ResourceName("RevokeAPIKey"))
+	defer span.Finish()
+
+	span.SetTag("api_key.id", id)
+
+	if !h.authorize(c, span) {
+		return
+	}
+
+	apiKey, err := h.apiKeyService.RevokeAPIKey(id, principalSubject(c))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", "API key not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	c.JSON(http.StatusOK, apiKey)
+}
+
+// authenticateAPIKey returns the principal of an API key held by apiKeyService
+func authenticateAPIKey(apiKeyService *services.APIKeyService) func(key string) (*middleware.Principal, error) {
+	return func(key string) (*middleware.Principal, error) {
+		apiKey, ok := apiKeyService.Authenticate(key)
+		if !ok {
+			return nil, middleware.ErrInvalidCredentials
+		}
+		return &middleware.Principal{
+			Subject: apiKey.Name,
+			Roles:   apiKey.Roles,
+			ICAs:    apiKey.ICAs,
+			Method:  middleware.AuthMethodAPIKey,
+		}, nil
+	}
+}
+
+// newAuthMiddleware returns the middleware authenticating callers with the API keys of
+// apiKeyService and, when a key set is configured, bearer tokens
+func newAuthMiddleware(authConfig *models.AuthConfig, apiKeyService *services.APIKeyService, logger *logger.DatadogLogger) gin.HandlerFunc {
+	options := middleware.AuthOptions{
+		APIKey: authenticateAPIKey(apiKeyService),
+		Exempt: authExemptPaths,
+	}
+	if authConfig.JWKSSource != "" {
+		keys := jwt.NewKeySet(authConfig.JWKSSource, time.Duration(authConfig.JWKSRefreshInterval)*time.Second)
+		verifier := jwt.NewVerifier(keys, authConfig.JWTIssuer, authConfig.JWTAudience)
+		options.BearerToken = middleware.TokenPrincipal(verifier, authConfig.JWTRolesClaim, authConfig.JWTICAsClaim)
+	}
+	return middleware.Auth(options, logger)
+}
+
+// Global handler functions for compatibility with main.go
+var apiKeyHandler *APIKeyHandler
+
+// InitAuth initializes API authentication and returns the middleware that authenticates
+// requests and checks their roles against routePermissions, or nil when authentication is
+// disabled. Configuration that cannot be loaded is returned as an error rather than leaving
+// the API open.
+func InitAuth(logger *logger.DatadogLogger) (gin.HandlersChain, error) {
+	authConfig, err := config.LoadAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeyService := services.NewAPIKeyService(logger)
+	if err := apiKeyService.AddAPIKeys(authConfig.APIKeys); err != nil {
+		return nil, err
+	}
+	apiKeyHandler = NewAPIKeyHandler(apiKeyService, authConfig, logger)
+
+	if !authConfig.Enabled {
+		logger.Info("API authentication disabled: every route is open", nil)
+		return nil, nil
+	}
+	logger.Info("API authentication enabled", logrus.Fields{
+		"apiKeys":    len(authConfig.APIKeys),
+		"jwksSource": authConfig.JWKSSource,
+		"adminRoles": authConfig.AdminRoles,
+	})
+	return gin.HandlersChain{
+		newAuthMiddleware(authConfig, apiKeyService, logger),
+		middleware.RequireRoles(routePermissions, logger),
+	}, nil
+}
+
+func CreateAPIKey(c *gin.Context) {
+	if apiKeyHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	apiKeyHandler.CreateAPIKey(c)
+}
+
+func ListAPIKeys(c *gin.Context) {
+	if apiKeyHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	apiKeyHandler.ListAPIKeys(c)
+}
+
+func RevokeAPIKey(c *gin.Context) {
+	if apiKeyHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	apiKeyHandler.RevokeAPIKey(c)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminKey = "bootstrap-admin-key"
+
+// setupAuthTestRouter authenticates requests with the testAdminKey admin key, the keys it
+// creates and, when jwksFile is set, tokens signed by its keys
+func setupAuthTestRouter(t *testing.T, jwksFile string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	logger := logger.NewDatadogLogger()
+	authConfig := &models.AuthConfig{
+		Enabled:             true,
+		APIKeys:             []models.APIKey{{Name: "bootstrap", Hash: services.HashAPIKey(testAdminKey), Roles: []string{"admin"}, ICAs: []string{models.AllICAs}}},
+		JWKSSource:          jwksFile,
+		JWKSRefreshInterval: 3600,
+		JWTAudience:         "mastercom-service",
+		JWTRolesClaim:       "roles",
+		JWTICAsClaim:        "icas",
+		AdminRoles:          []string{"admin"},
+	}
+	apiKeyService := services.NewAPIKeyService(logger)
+	require.NoError(t, apiKeyService.AddAPIKeys(authConfig.APIKeys))
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService, authConfig, logger)
+	caseHandler := NewCaseHandler(services.NewCaseService(logger), logger)
+
+	router.Use(newAuthMiddleware(authConfig, apiKeyService, logger))
+	router.GET("/health", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	api := router.Group("/api/v6")
+	api.GET("/cases", caseHandler.ListCases)
+	api.POST("/admin/api-keys", apiKeyHandler.CreateAPIKey)
+	api.GET("/admin/api-keys", apiKeyHandler.ListAPIKeys)
+	api.DELETE("/admin/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+	return router
+}
+
+func serveAuthenticated(router *gin.Engine, method, path string, header map[string]string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range header {
+		request.Header.Set(name, value)
+	}
+	router.ServeHTTP(w, request)
+	return w
+}
+
+func TestAuth_APIKeys(t *testing.T) {
+	router := setupAuthTestRouter(t, "")
+	admin := map[string]string{"X-API-Key": testAdminKey}
+
+	w := serveAuthenticated(router, "GET", "/health", nil, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serveAuthenticated(router, "GET", "/api/v6/cases", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+	w = serveAuthenticated(router, "GET", "/api/v6/cases", map[string]string{"X-API-Key": "wrong"}, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = serveAuthenticated(router, "GET", "/api/v6/cases", map[string]string{"Authorization": "Bearer some.jwt.token"}, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "bearer tokens are refused without a key set")
+
+	w = serveAuthenticated(router, "POST", "/api/v6/admin/api-keys", admin, `{"name":"analyst-tool","roles":["analyst"],"icas":["1234"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var created models.CreateAPIKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Key)
+	assert.Equal(t, "bootstrap", created.APIKey.CreatedBy)
+	assert.NotContains(t, w.Body.String(), services.HashAPIKey(created.Key))
+	analyst := map[string]string{"X-API-Key": created.Key}
+
+	w = serveAuthenticated(router, "POST", "/api/v6/admin/api-keys", admin, `{"name":"analyst-tool","roles":["viewer"],"icas":["1234"]}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = serveAuthenticated(router, "POST", "/api/v6/admin/api-keys", admin, `{"name":"no-roles","roles":[],"icas":["1234"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serveAuthenticated(router, "POST", "/api/v6/admin/api-keys", admin, `{"name":"no-icas","roles":["viewer"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveAuthenticated(router, "GET", "/api/v6/cases", analyst, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serveAuthenticated(router, "GET", "/api/v6/admin/api-keys", analyst, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serveAuthenticated(router, "GET", "/api/v6/admin/api-keys", admin, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "analyst-tool")
+	assert.Contains(t, w.Body.String(), `"icas":["1234"]`)
+	assert.NotContains(t, w.Body.String(), created.Key)
+
+	w = serveAuthenticated(router, "DELETE", "/api/v6/admin/api-keys/"+created.APIKey.ID, admin, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "revokedAt")
+	w = serveAuthenticated(router, "GET", "/api/v6/cases", analyst, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serveAuthenticated(router, "DELETE", "/api/v6/admin/api-keys/missing", admin, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, claims map[string]any) string {
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(map[string]string{"alg": "RS256", "kid": "test-key"}) + "." + encode(claims)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestAuth_BearerTokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test-key",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0600))
+	router := setupAuthTestRouter(t, jwksFile)
+
+	token := signTestToken(t, key, map[string]any{
+		"sub":   "jane.admin",
+		"aud":   "mastercom-service",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin"},
+	})
+	w := serveAuthenticated(router, "POST", "/api/v6/admin/api-keys", map[string]string{"Authorization": "Bearer " + token}, `{"name":"ethoca","roles":["analyst"],"icas":["1234"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"createdBy":"jane.admin"`)
+
+	token = signTestToken(t, key, map[string]any{
+		"sub": "jane.admin",
+		"aud": "mastercom-service",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	w = serveAuthenticated(router, "GET", "/api/v6/cases", map[string]string{"Authorization": "Bearer " + token}, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	token = signTestToken(t, key, map[string]any{
+		"sub": "jane.admin",
+		"aud": "other-service",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	w = serveAuthenticated(router, "GET", "/api/v6/cases", map[string]string{"Authorization": "Bearer " + token}, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAuthorizationTestRouter authenticates requests with API keys that are named after, and
+// hold, one role each, acting for ICA 123456 (the filer of createMockCaseRequest), 999999 or
+// every ICA, and checks them against routePermissions
+func setupAuthorizationTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	logger := logger.NewDatadogLogger()
+	apiKeyService := services.NewAPIKeyService(logger)
+	require.NoError(t, apiKeyService.AddAPIKeys([]models.APIKey{
+		{Name: "viewer", Hash: services.HashAPIKey("viewer"), Roles: []string{"viewer"}, ICAs: []string{"123456"}},
+		{Name: "analyst", Hash: services.HashAPIKey("analyst"), Roles: []string{"analyst"}, ICAs: []string{"123456"}},
+		{Name: "supervisor", Hash: services.HashAPIKey("supervisor"), Roles: []string{"supervisor"}, ICAs: []string{"123456"}},
+		{Name: "other-analyst", Hash: services.HashAPIKey("other-analyst"), Roles: []string{"analyst"}, ICAs: []string{"999999"}},
+		{Name: "admin", Hash: services.HashAPIKey("admin"), Roles: []string{"admin"}, ICAs: []string{models.AllICAs}},
+	}))
+
+	caseService := services.NewCaseService(logger)
+	documentService := services.NewDocumentService(logger)
+	caseDocumentService := services.NewCaseDocumentService(caseService, documentService, logger)
+	caseHandler := NewCaseHandler(caseService, logger)
+	caseDocumentHandler := NewCaseDocumentHandler(caseDocumentService, logger)
+	documentHandler := NewDocumentHandler(documentService, caseService, logger)
+	queueHandler := NewQueueHandler(services.NewQueueService(caseService, logger), logger)
+	scopeHandler := NewScopeHandler(caseDocumentService, logger)
+
+	// Claim 200002020654 and its transaction are issued by 123456, as are Ethoca alerts
+	claimService := services.NewClaimService(logger)
+	claimService.UpsertClaim(&models.Claim{ClaimID: "200002020654", IssuerID: "123456", AcquirerID: "654321", TransactionID: "auth-1"})
+	transactionDate := time.Now().AddDate(0, 0, -5).Format("060102")
+	source := services.NewLocalTransactionSource([]models.TransactionRecord{{
+		Authorization: models.AuthorizationDetail{TransactionID: "auth-1", PrimaryAccountNumber: "5555555555554444", BanknetDate: transactionDate},
+		Clearings:     []models.ClearingDetail{{TransactionID: "clearing-1", PrimaryAccountNumber: "5555555555554444", CentralSiteBusinessDate: transactionDate}},
+		ICAs:          []string{"123456", "654321"},
+	}})
+	transactionService := services.NewTransactionService(source, &models.TransactionSourceConfig{MaxSearchRangeDays: 30, MaxHistoryDays: 730}, logger)
+	claimHandler := NewClaimHandler(claimService, logger)
+	transactionHandler := NewTransactionHandler(transactionService, caseService, claimService, logger)
+	reconReportHandler := NewReconReportHandler(services.NewReconReportService(logger, caseService), logger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(services.NewEthocaWebhookService(logger, &models.WebhookConfig{ICA: "123456"}), logger)
+
+	router.Use(middleware.Auth(middleware.AuthOptions{APIKey: authenticateAPIKey(apiKeyService)}, logger))
+	router.Use(middleware.RequireRoles(routePermissions, logger))
+	api := router.Group("/api/v6")
+	cases := api.Group("/cases", scopeHandler.RequireCaseScope)
+	{
+		cases.POST("", caseHandler.CreateCase)
+		cases.GET("", caseHandler.ListCases)
+		cases.GET("/:id", caseHandler.GetCase)
+		cases.PUT("/:id", caseHandler.UpdateCase)
+		cases.DELETE("/:id", caseHandler.DeleteCase)
+		cases.POST("/:id/acknowledge", caseHandler.AcknowledgeCase)
+		cases.POST("/:id/documents", caseDocumentHandler.AttachDocument)
+	}
+	api.GET("/queues/counts", queueHandler.GetQueueCounts)
+	documents := api.Group("/documents", scopeHandler.RequireDocumentScope)
+	{
+		documents.POST("", documentHandler.UploadDocument)
+		documents.GET("/:id", documentHandler.GetDocument)
+	}
+	api.GET("/claims/:claimId", claimHandler.GetClaim)
+	api.GET("/claims/:claimId/transactions/clearing/:id", transactionHandler.GetClearingDetail)
+	api.POST("/transactions/search", transactionHandler.SearchTransactions)
+	api.POST("/reconreport/data/request", reconReportHandler.RequestReconReport)
+	api.POST("/reconreport/data/retrieval/:reportIdentifier", reconReportHandler.RetrieveReconReport)
+	api.GET("/reconreport/data/retrieval/:reportIdentifier", reconReportHandler.DownloadReconReport)
+	api.GET("/webhooks/ethoca/stats", GetWebhookStats)
+	api.POST("/admin/encryption/rotate", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	api.GET("/admin/api-keys", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func serveAs(router *gin.Engine, apiKey, method, path string, body any) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if body != nil {
+		reqBody, _ = json.Marshal(body)
+	}
+	return serveAuthenticated(router, method, path, map[string]string{"X-API-Key": apiKey}, string(reqBody))
+}
+
+func uploadDocumentAs(t *testing.T, router *gin.Engine, apiKey, caseID string) *httptest.ResponseRecorder {
+	request, err := createMockMultipartRequest(caseID, "evidence.pdf", testPDF(1), "", "")
+	require.NoError(t, err)
+	request.Header.Set("X-API-Key", apiKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, request)
+	return w
+}
+
+func TestAuthorization_Roles(t *testing.T) {
+	router := setupAuthorizationTestRouter(t)
+
+	w := serveAs(router, "viewer", "POST", "/api/v6/cases", createMockCaseRequest())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"requiredRole":"analyst"`)
+
+	w = serveAs(router, "analyst", "POST", "/api/v6/cases", createMockCaseRequest())
+	require.Equal(t, http.StatusCreated, w.Code)
+	var caseObj models.Case
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &caseObj))
+
+	w = serveAs(router, "viewer", "GET", "/api/v6/cases/"+caseObj.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serveAs(router, "viewer", "POST", "/api/v6/cases/"+caseObj.ID+"/acknowledge", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveAs(router, "analyst", "POST", "/api/v6/cases/"+caseObj.ID+"/acknowledge", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serveAs(router, "analyst", "DELETE", "/api/v6/cases/"+caseObj.ID, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveAs(router, "supervisor", "DELETE", "/api/v6/cases/"+caseObj.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Routes without a listed permission need the admin role
+	w = serveAs(router, "supervisor", "POST", "/api/v6/admin/encryption/rotate", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveAs(router, "admin", "POST", "/api/v6/admin/encryption/rotate", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serveAs(router, "supervisor", "GET", "/api/v6/admin/api-keys", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveAs(router, "admin", "GET", "/api/v6/admin/api-keys", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthorization_ICAScope(t *testing.T) {
+	router := setupAuthorizationTestRouter(t)
+
+	w := serveAs(router, "analyst", "POST", "/api/v6/cases", createMockCaseRequest())
+	require.Equal(t, http.StatusCreated, w.Code)
+	var caseObj models.Case
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &caseObj))
+
+	// Another ICA's analyst cannot file for 123456, nor learn that its case exists
+	w = serveAs(router, "other-analyst", "POST", "/api/v6/cases", createMockCaseRequest())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveAs(router, "other-analyst", "GET", "/api/v6/cases/"+caseObj.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, serveAs(router, "other-analyst", "GET", "/api/v6/cases/missing", nil).Body.String(), w.Body.String())
+	w = serveAs(router, "other-analyst", "PUT", "/api/v6/cases/"+caseObj.ID, createMockCaseRequest())
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serveAs(router, "other-analyst", "POST", "/api/v6/cases/"+caseObj.ID+"/acknowledge", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveAs(router, "other-analyst", "GET", "/api/v6/cases", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":0`)
+	w = serveAs(router, "other-analyst", "GET", "/api/v6/queues/counts", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"count":1`)
+	w = serveAs(router, "viewer", "GET", "/api/v6/cases", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+
+	// An ICA's cases cannot be moved to another ICA
+	moved := createMockCaseRequest()
+	moved.FilingIca = "999999"
+	w = serveAs(router, "analyst", "PUT", "/api/v6/cases/"+caseObj.ID, moved)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Documents are scoped through their case
+	w = uploadDocumentAs(t, router, "other-analyst", caseObj.ID)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = uploadDocumentAs(t, router, "analyst", caseObj.ID)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var document models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+
+	w = serveAs(router, "viewer", "GET", "/api/v6/documents/"+document.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serveAs(router, "other-analyst", "GET", "/api/v6/documents/"+document.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Document not found")
+
+	// Callers acting for every ICA see every case
+	w = serveAs(router, "admin", "GET", "/api/v6/cases/"+caseObj.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthorization_ICAScope_ClaimsTransactionsReports(t *testing.T) {
+	router := setupAuthorizationTestRouter(t)
+
+	// Claims and their transactions are seen by their issuer's and acquirer's callers only
+	for _, path := range []string{"/api/v6/claims/200002020654", "/api/v6/claims/200002020654/transactions/clearing/clearing-1"} {
+		assert.Equal(t, http.StatusOK, serveAs(router, "viewer", "GET", path, nil).Code, path)
+		assert.Equal(t, http.StatusOK, serveAs(router, "admin", "GET", path, nil).Code, path)
+		w := serveAs(router, "other-analyst", "GET", path, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+		assert.Contains(t, w.Body.String(), "Claim not found")
+	}
+
+	search := models.TransactionSearchRequest{
+		PrimaryAccountNum: "5555555555554444",
+		TranStartDate:     time.Now().AddDate(0, 0, -10).Format("2006-01-02"),
+		TranEndDate:       time.Now().Format("2006-01-02"),
+	}
+	var summary models.TransactionSummary
+	w := serveAs(router, "viewer", "POST", "/api/v6/transactions/search", search)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, "1", summary.AuthorizationSummaryCount)
+	w = serveAs(router, "other-analyst", "POST", "/api/v6/transactions/search", search)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, "0", summary.AuthorizationSummaryCount)
+
+	// Reports cover the caller's ICAs, and are retrieved by callers acting for all of them
+	dates := models.ReconReportRequest{StartDate: "2024-01-01", EndDate: "2024-01-31"}
+	named := dates
+	named.ICA = []string{"999999"}
+	w = serveAs(router, "analyst", "POST", "/api/v6/reconreport/data/request", named)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var ack models.ReconReportAcknowledgement
+	w = serveAs(router, "analyst", "POST", "/api/v6/reconreport/data/request", dates)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ack))
+	retrieval := "/api/v6/reconreport/data/retrieval/" + ack.ReportIdentifier
+	assert.Equal(t, http.StatusOK, serveAs(router, "viewer", "POST", retrieval, nil).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(router, "other-analyst", "POST", retrieval, nil).Code)
+	assert.NotEqual(t, http.StatusNotFound, serveAs(router, "viewer", "GET", retrieval, nil).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(router, "other-analyst", "GET", retrieval, nil).Code)
+
+	// A report for every ICA is only retrieved by callers acting for every ICA
+	w = serveAs(router, "admin", "POST", "/api/v6/reconreport/data/request", dates)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ack))
+	retrieval = "/api/v6/reconreport/data/retrieval/" + ack.ReportIdentifier
+	assert.Equal(t, http.StatusOK, serveAs(router, "admin", "POST", retrieval, nil).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(router, "viewer", "POST", retrieval, nil).Code)
+
+	// Ethoca webhook stats belong to the Ethoca ICA
+	assert.Equal(t, http.StatusOK, serveAs(router, "viewer", "GET", "/api/v6/webhooks/ethoca/stats", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(router, "other-analyst", "GET", "/api/v6/webhooks/ethoca/stats", nil).Code)
+}
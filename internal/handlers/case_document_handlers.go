@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type CaseDocumentHandler struct {
+	caseDocumentService *services.CaseDocumentService
+	validator           *services.DocumentValidator
+	logger              *logger.DatadogLogger
+}
+
+func NewCaseDocumentHandler(caseDocumentService *services.CaseDocumentService, logger *logger.DatadogLogger) *CaseDocumentHandler {
+	return &CaseDocumentHandler{
+		caseDocumentService: caseDocumentService,
+		validator:           services.NewDocumentValidator(config.LoadDocumentValidationConfig()),
+		logger:              logger,
+	}
+}
+
+// AttachDocument handles uploading a document to an existing case
+func (h *CaseDocumentHandler) AttachDocument(c *gin.Context) {
+	caseID := c.Param("id")
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Case ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("case.document.attach", tracer.ResourceName("AttachDocument"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get uploaded file", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "No file uploaded")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+
+	span.SetTag("document.filename", file.Filename)
+	span.SetTag("document.size", file.Size)
+
+	content, err := readFormFile(file)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to read uploaded file", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Failed to process file")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
+		return
+	}
+
+	fileType, err := h.validator.Validate(file.Filename, content)
+	if err != nil {
+		respondDocumentRejected(c, span, h.logger, err)
+		return
+	}
+
+	document := models.NewDocument(
+		caseID,
+		file.Filename,
+		fileType,
+		content,
+		c.PostForm("uploadedBy"),
+		c.PostForm("description"),
+	)
+
+	if err := h.caseDocumentService.AttachDocument(caseID, document); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to attach document", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		if errors.Is(err, services.ErrCaseNotFound) {
+			span.SetTag("error.message", "Case not found")
+			c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+			return
+		}
+		span.SetTag("error.message", "Failed to attach document")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to attach document"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Document attached successfully", logrus.Fields{
+		"caseId":     caseID,
+		"documentId": document.ID,
+		"filename":   document.FileName,
+		"fileSize":   document.FileSize,
+	})
+
+	span.SetTag("document.id", document.ID)
+	setDocumentETag(c, document)
+	c.JSON(http.StatusCreated, models.NewDocumentResponse(document))
+}
+
+// ListDocuments handles listing the documents attached to a case
+func (h *CaseDocumentHandler) ListDocuments(c *gin.Context) {
+	caseID := c.Param("id")
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Case ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("case.document.list", tracer.ResourceName("ListDocuments"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+
+	documents, err := h.caseDocumentService.ListDocuments(caseID)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to list case documents", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		if errors.Is(err, services.ErrCaseNotFound) {
+			span.SetTag("error.message", "Case not found")
+			c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+			return
+		}
+		span.SetTag("error.message", "Failed to list case documents")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list case documents"})
+		return
+	}
+
+	responses := make([]models.DocumentResponse, 0, len(documents))
+	for _, document := range documents {
+		responses = append(responses, models.NewDocumentResponse(document))
+	}
+
+	h.logger.InfoWithSpan(span, "Case documents listed successfully", logrus.Fields{
+		"caseId":        caseID,
+		"documentCount": len(responses),
+	})
+
+	span.SetTag("documents.count", len(responses))
+	c.JSON(http.StatusOK, gin.H{
+		"caseId":    caseID,
+		"documents": responses,
+		"total":     len(responses),
+	})
+}
+
+// CreateEvidenceBundle handles merging a case's documents into a single PDF or TIFF for filing
+func (h *CaseDocumentHandler) CreateEvidenceBundle(c *gin.Context) {
+	caseID := c.Param("id")
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Case ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("case.document.bundle", tracer.ResourceName("CreateEvidenceBundle"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+
+	var req models.EvidenceBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorWithSpan(span, "Invalid request body", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	span.SetTag("bundle.format", req.Format)
+	span.SetTag("bundle.cover_sheet", req.CoverSheet)
+
+	bundle, err := h.caseDocumentService.BuildEvidenceBundle(caseID, &req)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to create evidence bundle", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		switch {
+		case errors.Is(err, services.ErrCaseNotFound):
+			span.SetTag("error.message", "Case not found")
+			c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+		case errors.Is(err, services.ErrInvalidEvidenceBundleRequest):
+			span.SetTag("error.message", "Invalid evidence bundle request")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid evidence bundle request", "details": err.Error()})
+		case errors.Is(err, services.ErrEvidenceBundleConversion):
+			span.SetTag("error.message", "Documents cannot be merged")
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Documents cannot be merged", "details": err.Error()})
+		default:
+			span.SetTag("error.message", "Failed to create evidence bundle")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create evidence bundle"})
+		}
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Evidence bundle created successfully", logrus.Fields{
+		"caseId":      caseID,
+		"documentId":  bundle.ID,
+		"sourceCount": len(bundle.DerivedFrom),
+		"fileSize":    bundle.FileSize,
+	})
+
+	span.SetTag("document.id", bundle.ID)
+	setDocumentETag(c, bundle)
+	c.JSON(http.StatusCreated, models.NewDocumentResponse(bundle))
+}
+
+// DeleteCase handles deleting a case. A case with documents is only deleted with
+// ?cascade=true, which deletes its documents too.
+func (h *CaseDocumentHandler) DeleteCase(c *gin.Context) {
+	caseID := c.Param("id")
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Case ID is required"})
+		return
+	}
+
+	cascade, err := strconv.ParseBool(c.DefaultQuery("cascade", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cascade must be true or false"})
+		return
+	}
+
+	span := tracer.StartSpan("case.delete", tracer.ResourceName("DeleteCase"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+	span.SetTag("case.cascade", cascade)
+
+	if err := h.caseDocumentService.DeleteCase(caseID, cascade); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to delete case", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		switch {
+		case errors.Is(err, services.ErrCaseNotFound):
+			span.SetTag("error.message", "Case not found")
+			c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+		case errors.Is(err, services.ErrCaseHasDocuments):
+			span.SetTag("error.message", "Case has documents")
+			c.JSON(http.StatusConflict, gin.H{"error": "Case has documents, delete with cascade=true to remove them", "details": err.Error()})
+		case errors.Is(err, services.ErrCaseOnLegalHold):
+			span.SetTag("error.message", "Case is on legal hold")
+			c.JSON(http.StatusConflict, gin.H{"error": "Case is on legal hold"})
+		default:
+			span.SetTag("error.message", "Failed to delete case")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete case"})
+		}
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Case deleted successfully", logrus.Fields{
+		"caseId":  caseID,
+		"cascade": cascade,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Case deleted successfully"})
+}
+
+// ExportCase handles streaming a ZIP archive of a case, its audit history and its documents
+func (h *CaseDocumentHandler) ExportCase(c *gin.Context) {
+	caseID := c.Param("id")
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Case ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("case.export", tracer.ResourceName("ExportCase"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+
+	// Documents are exported with card numbers masked unless a privileged caller asks otherwise
+	original, ok := requestOriginal(c, span, h.logger, h.caseDocumentService.OriginalAccessAllowed)
+	if !ok {
+		return
+	}
+
+	export, err := h.caseDocumentService.PrepareCaseExport(caseID, original)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to export case", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		switch {
+		case errors.Is(err, services.ErrCaseNotFound):
+			span.SetTag("error.message", "Case not found")
+			c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+		case errors.Is(err, services.ErrDocumentIntegrity):
+			span.SetTag("error.message", "Document failed integrity check")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Document failed integrity check", "details": err.Error()})
+		default:
+			span.SetTag("error.message", "Failed to export case")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export case"})
+		}
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="case-`+export.CaseID()+`-export.zip"`)
+	c.Status(http.StatusOK)
+
+	// The status is already sent, so a failure part way can only cut the archive short
+	if err := export.Write(c.Writer); err != nil {
+		h.logger.ErrorWithSpan(span, "Case export interrupted", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Case export interrupted")
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Case exported successfully", logrus.Fields{"caseId": caseID})
+}
+
+// readFormFile reads the whole content of an uploaded file
+func readFormFile(file *multipart.FileHeader) ([]byte, error) {
+	openedFile, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer openedFile.Close()
+
+	return io.ReadAll(openedFile)
+}
+
+// Global handler functions for compatibility with main.go
+var caseDocumentHandler *CaseDocumentHandler
+
+// InitCaseDocumentHandlers initializes the case document handlers. It must be called
+// after InitHandlers and InitDocumentHandlers so it shares their case and document stores.
+func InitCaseDocumentHandlers(logger *logger.DatadogLogger) {
+	caseDocumentService := services.NewCaseDocumentService(caseService, documentService, logger)
+	caseDocumentHandler = NewCaseDocumentHandler(caseDocumentService, logger)
+	scopeHandler = NewScopeHandler(caseDocumentService, logger)
+}
+
+func AttachCaseDocument(c *gin.Context) {
+	if caseDocumentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseDocumentHandler.AttachDocument(c)
+}
+
+func ListCaseDocuments(c *gin.Context) {
+	if caseDocumentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseDocumentHandler.ListDocuments(c)
+}
+
+func CreateEvidenceBundle(c *gin.Context) {
+	if caseDocumentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseDocumentHandler.CreateEvidenceBundle(c)
+}
+
+func DeleteCaseWithDocuments(c *gin.Context) {
+	if caseDocumentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseDocumentHandler.DeleteCase(c)
+}
+
+func ExportCase(c *gin.Context) {
+	if caseDocumentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseDocumentHandler.ExportCase(c)
+}
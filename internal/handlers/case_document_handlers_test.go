@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCaseDocumentTestRouter() *gin.Engine {
+	router, _ := setupScanningCaseDocumentTestRouter(nil)
+	return router
+}
+
+// setupScanningCaseDocumentTestRouter scans uploads with scanner when it is not nil, and
+// returns the document service so that tests can run the scans
+func setupScanningCaseDocumentTestRouter(scanner services.Scanner) (*gin.Engine, *services.DocumentService) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// Initialize services and handlers
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	documentService := services.NewDocumentService(logger)
+	if scanner != nil {
+		documentService.EnableScanning(scanner, &models.ScanConfig{MaxAttempts: 3})
+	}
+	caseHandler := NewCaseHandler(caseService, logger)
+	documentHandler := NewDocumentHandler(documentService, caseService, logger)
+	caseDocumentHandler := NewCaseDocumentHandler(services.NewCaseDocumentService(caseService, documentService, logger), logger)
+
+	// Setup routes
+	api := router.Group("/api/v6")
+	cases := api.Group("/cases")
+	{
+		cases.POST("", caseHandler.CreateCase)
+		cases.GET("/:id", caseHandler.GetCase)
+		cases.DELETE("/:id", caseDocumentHandler.DeleteCase)
+		cases.POST("/:id/documents", caseDocumentHandler.AttachDocument)
+		cases.GET("/:id/documents", caseDocumentHandler.ListDocuments)
+		cases.POST("/:id/documents/bundle", caseDocumentHandler.CreateEvidenceBundle)
+		cases.GET("/:id/export", caseDocumentHandler.ExportCase)
+	}
+	api.GET("/documents/:id", documentHandler.GetDocument)
+
+	return router, documentService
+}
+
+func createCaseDocumentRequest(caseID, fileName, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, _ := writer.CreateFormFile("file", fileName)
+	part.Write([]byte(content))
+	writer.WriteField("uploadedBy", "test-user")
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/api/v6/cases/"+caseID+"/documents", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestAttachCaseDocument(t *testing.T) {
+	router := setupCaseDocumentTestRouter()
+	caseObj := createTestCase(t, router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, "evidence.pdf", testPDF(1)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var document models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, caseObj.ID, document.CaseID)
+	assert.Equal(t, "application/pdf", document.FileType)
+	assert.Equal(t, int64(len(testPDF(1))), document.FileSize)
+
+	// The document is listed under the case
+	w = httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/cases/"+caseObj.ID+"/documents", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Documents []models.DocumentResponse `json:"documents"`
+		Total     int                       `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, document.ID, response.Documents[0].ID)
+
+	// And on the case itself
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/cases/"+caseObj.ID, nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var retrieved models.Case
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &retrieved))
+	require.Len(t, retrieved.Documents, 1)
+	assert.Equal(t, document.ID, retrieved.Documents[0].ID)
+}
+
+func TestAttachCaseDocument_Errors(t *testing.T) {
+	router := setupCaseDocumentTestRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, createCaseDocumentRequest("nonexistent-id", "evidence.pdf", testPDF(1)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/cases/nonexistent-id/documents", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Content Mastercom would not accept
+	caseObj := createTestCase(t, router)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, "evidence.pdf", "MZ\x90\x00"))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), models.DocumentViolationUnsupportedType)
+
+	// No file
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/api/v6/cases/"+caseObj.ID+"/documents", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// fakeScanner flags content containing "EICAR" and fails while unavailable is set
+type fakeScanner struct {
+	unavailable bool
+}
+
+func (s *fakeScanner) Name() string {
+	return "fake"
+}
+
+func (s *fakeScanner) Scan(ctx context.Context, content []byte) (string, error) {
+	if s.unavailable {
+		return "", errors.New("clamd: connection refused")
+	}
+	if strings.Contains(string(content), "EICAR") {
+		return "Eicar-Test-Signature", nil
+	}
+	return "", nil
+}
+
+func TestAttachCaseDocument_MalwareScan(t *testing.T) {
+	scanner := &fakeScanner{}
+	router, documentService := setupScanningCaseDocumentTestRouter(scanner)
+	caseObj := createTestCase(t, router)
+
+	getDocument := func(documentID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/api/v6/documents/"+documentID, nil)
+		router.ServeHTTP(w, request)
+		return w
+	}
+
+	// Uploads are answered before the scan, and held back until it finds them clean
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, "evidence.pdf", testPDF(1)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var clean models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clean))
+	assert.Equal(t, models.DocumentStatusQuarantined, clean.ProcessingStatus)
+	w = getDocument(clean.ID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NotContains(t, w.Body.String(), "content")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, "evidence.pdf", testPDF(1)+"EICAR"))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var infected models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &infected))
+	assert.Equal(t, models.DocumentStatusQuarantined, infected.ProcessingStatus)
+
+	assert.Equal(t, 2, documentService.RescanQuarantined(context.Background()))
+	w = getDocument(clean.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	var document models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, models.DocumentStatusReceived, document.ProcessingStatus)
+	require.NotNil(t, document.Scan)
+	assert.Equal(t, models.ScanStatusClean, document.Scan.Status)
+
+	// Infected uploads are rejected and their content discarded
+	w = getDocument(infected.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	var rejected models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejected))
+	assert.Equal(t, models.DocumentStatusRejected, rejected.ProcessingStatus)
+	assert.Equal(t, "Eicar-Test-Signature", rejected.Scan.Signature)
+	assert.Empty(t, rejected.Content)
+
+	// Uploads made while the scanner is down stay held back
+	scanner.unavailable = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, "evidence.pdf", testPDF(1)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var pending models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pending))
+	documentService.RescanQuarantined(context.Background())
+	assert.Equal(t, http.StatusConflict, getDocument(pending.ID).Code)
+}
+
+func createEvidenceBundleRequest(caseID, body string) *http.Request {
+	req, _ := http.NewRequest("POST", "/api/v6/cases/"+caseID+"/documents/bundle", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestCreateEvidenceBundle(t *testing.T) {
+	router := setupCaseDocumentTestRouter()
+	caseObj := createTestCase(t, router)
+
+	var sources []models.DocumentResponse
+	for _, pages := range []int{2, 1} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, "evidence.pdf", testPDF(pages)))
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var document models.DocumentResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+		sources = append(sources, document)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, createEvidenceBundleRequest(caseObj.ID, `{"format":"PDF","documentIds":["`+sources[1].ID+`","`+sources[0].ID+`"],"coverSheet":true,"createdBy":"test-user"}`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var bundle models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bundle))
+	assert.Equal(t, caseObj.ID, bundle.CaseID)
+	assert.Equal(t, "application/pdf", bundle.FileType)
+	assert.Equal(t, []string{sources[1].ID, sources[0].ID}, bundle.DerivedFrom)
+
+	// The bundle is a document of the case
+	w = httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/documents/"+bundle.ID, nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCreateEvidenceBundle_Errors(t *testing.T) {
+	router := setupCaseDocumentTestRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, createEvidenceBundleRequest("nonexistent-id", `{"format":"PDF"}`))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	caseObj := createTestCase(t, router)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createEvidenceBundleRequest(caseObj.ID, `{"format":`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// No documents to merge
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createEvidenceBundleRequest(caseObj.ID, `{"format":"PDF"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, "evidence.pdf", testPDF(1)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// PDFs cannot become TIFF pages
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createEvidenceBundleRequest(caseObj.ID, `{"format":"TIFF"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "evidence.pdf")
+}
+
+func TestDeleteCase_WithDocuments(t *testing.T) {
+	router := setupCaseDocumentTestRouter()
+	caseObj := createTestCase(t, router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, "evidence.pdf", testPDF(1)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var document models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+
+	// Blocked without cascade
+	w = httptest.NewRecorder()
+	request, _ := http.NewRequest("DELETE", "/api/v6/cases/"+caseObj.ID, nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Cascade deletes the case and its documents
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("DELETE", "/api/v6/cases/"+caseObj.ID+"?cascade=true", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/documents/"+document.ID, nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("DELETE", "/api/v6/cases/"+caseObj.ID, nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestExportCase(t *testing.T) {
+	router := setupCaseDocumentTestRouter()
+	caseObj := createTestCase(t, router)
+
+	for _, name := range []string{"receipt.pdf", "receipt.pdf"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, createCaseDocumentRequest(caseObj.ID, name, testPDF(1)))
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/cases/"+caseObj.ID+"/export", nil)
+	router.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="case-`+caseObj.ID+`-export.zip"`, w.Header().Get("Content-Disposition"))
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"case.json", "audit.json", "documents/receipt.pdf", "documents/receipt (2).pdf", "manifest.json"}, names)
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/cases/nonexistent-id/export", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type CaseFilingStatusHandler struct {
+	documentService *services.DocumentService
+	caseService     *services.CaseService
+	validator       *validator.Validate
+	logger          *logger.DatadogLogger
+}
+
+func NewCaseFilingStatusHandler(documentService *services.DocumentService, caseService *services.CaseService, logger *logger.DatadogLogger) *CaseFilingStatusHandler {
+	return &CaseFilingStatusHandler{
+		documentService: documentService,
+		caseService:     caseService,
+		validator:       validator.New(),
+		logger:          logger,
+	}
+}
+
+// GetCaseFilingStatus handles retrieving the document status of up to 2,000 cases
+func (h *CaseFilingStatusHandler) GetCaseFilingStatus(c *gin.Context) {
+	span := tracer.StartSpan("case.filing_status", tracer.ResourceName("GetCaseFilingStatus"))
+	defer span.Finish()
+
+	var req models.CaseFilingStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to bind JSON request", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.ErrorWithSpan(span, "Validation failed", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Validation failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	caseIDs := make([]string, 0, len(req.CaseFilingList))
+	for _, caseFiling := range req.CaseFilingList {
+		caseIDs = append(caseIDs, caseFiling.CaseID)
+	}
+
+	statuses := h.documentService.GetCaseFilingStatus(caseIDs)
+	// Cases outside the caller's ICAs are reported as if they did not exist
+	scope := requestScope(c)
+	for i := range statuses {
+		if h.caseService.CheckCaseScope(statuses[i].CaseID, scope) != nil {
+			statuses[i] = models.CaseFilingStatusResponseStructure{CaseID: statuses[i].CaseID, Status: models.CaseImageStatusUnavailable}
+		}
+	}
+
+	h.logger.InfoWithSpan(span, "Case filing status retrieved successfully", logrus.Fields{
+		"caseCount": len(caseIDs),
+	})
+
+	span.SetTag("case.count", len(caseIDs))
+	c.JSON(http.StatusOK, models.CaseFilingStatusResponse{CaseFilingResponseList: statuses})
+}
+
+// GetCaseFilingImageStatus handles searching for cases whose documents have a given status
+func (h *CaseFilingStatusHandler) GetCaseFilingImageStatus(c *gin.Context) {
+	span := tracer.StartSpan("case.filing_image_status", tracer.ResourceName("GetCaseFilingImageStatus"))
+	defer span.Finish()
+
+	var req models.CaseFilingImageStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to bind JSON request", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.ErrorWithSpan(span, "Validation failed", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Validation failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	statuses, err := h.documentService.GetCaseFilingImageStatus(&req)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to search case filing image status", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		if errors.Is(err, services.ErrInvalidCaseFilingImageStatusRequest) {
+			span.SetTag("error.message", "Invalid image status request")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image status request", "details": err.Error()})
+			return
+		}
+		span.SetTag("error.message", "Failed to search case filing image status")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search case filing image status"})
+		return
+	}
+	scope := requestScope(c)
+	permitted := statuses[:0]
+	for _, status := range statuses {
+		if h.caseService.CheckCaseScope(status.CaseID, scope) == nil {
+			permitted = append(permitted, status)
+		}
+	}
+	statuses = permitted
+
+	h.logger.InfoWithSpan(span, "Case filing image status retrieved successfully", logrus.Fields{
+		"status":    req.Status,
+		"caseCount": len(statuses),
+	})
+
+	span.SetTag("case.image_status", req.Status)
+	span.SetTag("case.count", len(statuses))
+	c.JSON(http.StatusOK, models.CaseFilingImageStatusResponse{CaseFilingImageStatusList: statuses})
+}
+
+// Global handler functions for compatibility with main.go
+var caseFilingStatusHandler *CaseFilingStatusHandler
+
+// InitCaseFilingStatusHandlers initializes the case filing status handlers. It must be
+// called after InitHandlers and InitDocumentHandlers so statuses reflect the shared case and
+// document stores.
+func InitCaseFilingStatusHandlers(logger *logger.DatadogLogger) {
+	caseFilingStatusHandler = NewCaseFilingStatusHandler(documentService, caseService, logger)
+}
+
+func GetCaseFilingStatus(c *gin.Context) {
+	if caseFilingStatusHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseFilingStatusHandler.GetCaseFilingStatus(c)
+}
+
+func GetCaseFilingImageStatus(c *gin.Context) {
+	if caseFilingStatusHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseFilingStatusHandler.GetCaseFilingImageStatus(c)
+}
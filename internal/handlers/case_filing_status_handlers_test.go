@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCaseFilingStatusTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// Initialize services and handlers
+	logger := logger.NewDatadogLogger()
+	documentService := services.NewDocumentService(logger)
+	caseService := services.NewCaseService(logger)
+	documentHandler := NewDocumentHandler(documentService, caseService, logger)
+	caseFilingStatusHandler := NewCaseFilingStatusHandler(documentService, caseService, logger)
+
+	// Setup routes
+	api := router.Group("/api/v6")
+	api.POST("/documents", documentHandler.UploadDocument)
+	cases := api.Group("/cases")
+	{
+		cases.PUT("/status", caseFilingStatusHandler.GetCaseFilingStatus)
+		cases.PUT("/imagestatus", caseFilingStatusHandler.GetCaseFilingImageStatus)
+	}
+
+	return router
+}
+
+func TestGetCaseFilingStatus(t *testing.T) {
+	router := setupCaseFilingStatusTestRouter()
+
+	req, err := createMockMultipartRequest("case-1", "evidence.pdf", testPDF(1), "", "test-user")
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var document models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, models.DocumentStatusReceived, document.ProcessingStatus)
+
+	reqBody, _ := json.Marshal(models.CaseFilingStatusRequest{CaseFilingList: []models.CaseFilingStatusRequestStructure{
+		{CaseID: "case-1"},
+		{CaseID: "case-2"},
+	}})
+	w = httptest.NewRecorder()
+	request, _ := http.NewRequest("PUT", "/api/v6/cases/status", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.CaseFilingStatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.CaseFilingResponseList, 2)
+	assert.Equal(t, "case-1", response.CaseFilingResponseList[0].CaseID)
+	assert.True(t, strings.HasPrefix(response.CaseFilingResponseList[0].Status, "PENDING_SND_"))
+	assert.Equal(t, models.CaseImageStatusUnavailable, response.CaseFilingResponseList[1].Status)
+}
+
+func TestGetCaseFilingStatus_Invalid(t *testing.T) {
+	router := setupCaseFilingStatusTestRouter()
+
+	// Empty list
+	reqBody, _ := json.Marshal(models.CaseFilingStatusRequest{})
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("PUT", "/api/v6/cases/status", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// More than the maximum number of cases
+	caseFilingList := make([]models.CaseFilingStatusRequestStructure, models.MaxCaseFilingStatusCases+1)
+	for i := range caseFilingList {
+		caseFilingList[i].CaseID = fmt.Sprintf("case-%d", i)
+	}
+	reqBody, _ = json.Marshal(models.CaseFilingStatusRequest{CaseFilingList: caseFilingList})
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("PUT", "/api/v6/cases/status", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetCaseFilingImageStatus(t *testing.T) {
+	router := setupCaseFilingStatusTestRouter()
+
+	req, err := createMockMultipartRequest("case-1", "evidence.pdf", testPDF(1), "", "test-user")
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	today := time.Now().Format("2006-01-02")
+	reqBody, _ := json.Marshal(models.CaseFilingImageStatusRequest{Status: models.CaseImageStatusUnprocessed, StartDate: today, EndDate: today})
+	w = httptest.NewRecorder()
+	request, _ := http.NewRequest("PUT", "/api/v6/cases/imagestatus", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.CaseFilingImageStatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.CaseFilingImageStatusList, 1)
+	assert.Equal(t, "case-1", response.CaseFilingImageStatusList[0].CaseID)
+
+	// Unsupported status
+	reqBody, _ = json.Marshal(models.CaseFilingImageStatusRequest{Status: models.CaseImageStatusPending, StartDate: today, EndDate: today})
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("PUT", "/api/v6/cases/imagestatus", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -23,7 +24,7 @@ type CaseHandler struct {
 func NewCaseHandler(caseService *services.CaseService, logger *logger.DatadogLogger) *CaseHandler {
 	return &CaseHandler{
 		caseService: caseService,
-		validator:   validator.New(),
+		validator:   newCaseValidator(),
 		logger:      logger,
 	}
 }
@@ -53,9 +54,21 @@ func (h *CaseHandler) CreateCase(c *gin.Context) {
 
 	// Create case
 	caseObj := models.NewCase(&req)
+	if err := services.CheckFilingICA(caseObj, requestScope(c)); err != nil {
+		h.logger.ErrorWithSpan(span, "Filing ICA outside the caller's ICAs", logrus.Fields{"filingIca": caseObj.FilingIca})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Filing ICA not permitted")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Filing ICA not permitted"})
+		return
+	}
 	if err := h.caseService.CreateCase(caseObj); err != nil {
 		h.logger.ErrorWithSpan(span, "Failed to create case", logrus.Fields{"error": err.Error()})
 		span.SetTag("error", true)
+		if errors.Is(err, services.ErrInvalidPAN) {
+			span.SetTag("error.message", "Validation failed")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
 		span.SetTag("error.message", "Failed to create case")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create case"})
 		return
@@ -85,7 +98,7 @@ func (h *CaseHandler) ListCases(c *gin.Context) {
 	span.SetTag("pagination.limit", limit)
 	span.SetTag("filter.status", status)
 
-	cases, total, err := h.caseService.ListCases(page, limit, status)
+	cases, total, err := h.caseService.ListCases(page, limit, status, requestScope(c))
 	if err != nil {
 		h.logger.ErrorWithSpan(span, "Failed to list cases", logrus.Fields{"error": err.Error()})
 		span.SetTag("error", true)
@@ -179,12 +192,24 @@ func (h *CaseHandler) UpdateCase(c *gin.Context) {
 	// Update case
 	caseObj := models.NewCase(&req)
 	caseObj.ID = caseID
+	if err := services.CheckFilingICA(caseObj, requestScope(c)); err != nil {
+		h.logger.ErrorWithSpan(span, "Filing ICA outside the caller's ICAs", logrus.Fields{"filingIca": caseObj.FilingIca})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Filing ICA not permitted")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Filing ICA not permitted"})
+		return
+	}
 	if err := h.caseService.UpdateCase(caseObj); err != nil {
 		h.logger.ErrorWithSpan(span, "Failed to update case", logrus.Fields{
 			"caseId": caseID,
 			"error": err.Error(),
 		})
 		span.SetTag("error", true)
+		if errors.Is(err, services.ErrInvalidPAN) {
+			span.SetTag("error.message", "Validation failed")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
 		span.SetTag("error.message", "Failed to update case")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update case"})
 		return
@@ -229,6 +254,68 @@ func (h *CaseHandler) DeleteCase(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Case deleted successfully"})
 }
 
+// AcknowledgeCase handles moving a case into the Worked queue
+func (h *CaseHandler) AcknowledgeCase(c *gin.Context) {
+	h.transitionCase(c, models.QueueActionAcknowledge)
+}
+
+// RejectCase handles moving a case into the Rejects queue
+func (h *CaseHandler) RejectCase(c *gin.Context) {
+	h.transitionCase(c, models.QueueActionReject)
+}
+
+// SubmitCase handles moving a case into the Submitted queue
+func (h *CaseHandler) SubmitCase(c *gin.Context) {
+	h.transitionCase(c, models.QueueActionSubmit)
+}
+
+// CloseCase handles moving a case into the Closed queue
+func (h *CaseHandler) CloseCase(c *gin.Context) {
+	h.transitionCase(c, models.QueueActionClose)
+}
+
+// transitionCase applies a queue action to the case identified in the path
+func (h *CaseHandler) transitionCase(c *gin.Context, action models.QueueAction) {
+	caseID := c.Param("id")
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Case ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("case.transition", tracer.ResourceName("TransitionCase"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+	span.SetTag("queue.action", string(action))
+
+	caseObj, err := h.caseService.TransitionCase(caseID, action)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to move case between queues", logrus.Fields{
+			"caseId": caseID,
+			"action": action,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		if errors.Is(err, models.ErrInvalidQueueTransition) {
+			span.SetTag("error.message", "Invalid queue transition")
+			c.JSON(http.StatusConflict, gin.H{"error": "Invalid queue transition", "details": err.Error()})
+			return
+		}
+		span.SetTag("error.message", "Case not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Case moved between queues successfully", logrus.Fields{
+		"caseId":    caseID,
+		"action":    action,
+		"queueName": caseObj.QueueName,
+	})
+
+	span.SetTag("queue.name", caseObj.QueueName)
+	c.JSON(http.StatusOK, caseObj)
+}
+
 // Global handler functions for compatibility with main.go
 var (
 	caseService *services.CaseService
@@ -279,3 +366,35 @@ func DeleteCase(c *gin.Context) {
 	}
 	caseHandler.DeleteCase(c)
 }
+
+func AcknowledgeCase(c *gin.Context) {
+	if caseHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseHandler.AcknowledgeCase(c)
+}
+
+func RejectCase(c *gin.Context) {
+	if caseHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseHandler.RejectCase(c)
+}
+
+func SubmitCase(c *gin.Context) {
+	if caseHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseHandler.SubmitCase(c)
+}
+
+func CloseCase(c *gin.Context) {
+	if caseHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	caseHandler.CloseCase(c)
+}
@@ -13,7 +13,6 @@ import (
 	"mastercom-service/pkg/logger"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -84,7 +83,10 @@ func TestCreateCase_Success(t *testing.T) {
 	
 	assert.NotEmpty(t, response.ID)
 	assert.Equal(t, req.CaseType, response.CaseType)
-	assert.Equal(t, req.PrimaryAccountNumber, response.PrimaryAccountNumber)
+	assert.Equal(t, "411111******1111", response.PrimaryAccountNumber)
+	assert.Equal(t, "411111", response.PANBIN)
+	assert.Equal(t, "1111", response.PANLast4)
+	assert.NotContains(t, w.Body.String(), req.PrimaryAccountNumber)
 	assert.Equal(t, req.TransactionAmount, response.TransactionAmount)
 	assert.Equal(t, "PENDING", response.Status)
 }
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/middleware"
+	"mastercom-service/pkg/pan"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// newCaseValidator returns a validator for case requests. Their pan fields take a card
+// number that passes the Luhn check, or one truncated as cases return it.
+func newCaseValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterValidation("pan", func(field validator.FieldLevel) bool {
+		number := field.Field().String()
+		return pan.Valid(number) || pan.Truncated(number)
+	})
+	return validate
+}
+
+// PANHandler serves the full card numbers of cases, which are otherwise only returned truncated
+type PANHandler struct {
+	caseService *services.CaseService
+	config      *models.TokenizationConfig
+	logger      *logger.DatadogLogger
+}
+
+func NewPANHandler(caseService *services.CaseService, config *models.TokenizationConfig, logger *logger.DatadogLogger) *PANHandler {
+	return &PANHandler{
+		caseService: caseService,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// DetokenizeCasePAN handles retrieving a case's full card number. It is answered with 403
+// unless the caller's principal holds one of the detokenize roles, and every request is
+// logged and audited on the case with its reason.
+func (h *PANHandler) DetokenizeCasePAN(c *gin.Context) {
+	caseID := c.Param("id")
+
+	span := tracer.StartSpan("case.pan.detokenize", tracer.ResourceName("DetokenizeCasePAN"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+
+	principal := middleware.PrincipalFrom(c)
+	subject := principalSubject(c)
+	if !principal.HasRole(h.config.DetokenizeRoles...) {
+		h.logger.ErrorWithSpan(span, "Card number detokenization denied", logrus.Fields{
+			"caseId":  caseID,
+			"subject": subject,
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Card numbers require a privileged role")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Card numbers require a privileged role"})
+		return
+	}
+
+	var req models.DetokenizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	number, err := h.caseService.DetokenizePAN(c.Request.Context(), caseID, map[string]string{
+		"subject": subject,
+		"reason":  req.Reason,
+	})
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to detokenize card number", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		if errors.Is(err, services.ErrCaseNotFound) {
+			span.SetTag("error.message", "Case not found")
+			c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+			return
+		}
+		span.SetTag("error.message", "Failed to detokenize card number")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detokenize card number"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Card number detokenized", logrus.Fields{
+		"caseId":  caseID,
+		"subject": subject,
+		"reason":  req.Reason,
+	})
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, models.DetokenizeResponse{CaseID: caseID, PrimaryAccountNumber: number})
+}
+
+// Global handler functions for compatibility with main.go
+var panHandler *PANHandler
+
+// InitPANHandlers initializes the card number handlers. It must be called after InitHandlers
+// so it shares the case store.
+func InitPANHandlers(logger *logger.DatadogLogger) {
+	tokenizationConfig := config.LoadTokenizationConfig()
+	panHandler = NewPANHandler(caseService, tokenizationConfig, logger)
+	logger.Info("Card numbers tokenized in the local vault", logrus.Fields{"detokenizeRoles": tokenizationConfig.DetokenizeRoles})
+}
+
+func DetokenizeCasePAN(c *gin.Context) {
+	if panHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	panHandler.DetokenizeCasePAN(c)
+}
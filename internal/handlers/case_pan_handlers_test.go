@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPANTestRouter takes the caller's roles from the X-Test-Roles header
+func setupPANTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	caseHandler := NewCaseHandler(caseService, logger)
+	panHandler := NewPANHandler(caseService, &models.TokenizationConfig{DetokenizeRoles: []string{"supervisor", "admin"}}, logger)
+
+	router.Use(func(c *gin.Context) {
+		if roles := c.GetHeader("X-Test-Roles"); roles != "" {
+			middleware.SetPrincipal(c, &middleware.Principal{Subject: "test-user", Roles: strings.Split(roles, ","), ICAs: []string{models.AllICAs}})
+		}
+		c.Next()
+	})
+	cases := router.Group("/api/v6/cases")
+	{
+		cases.POST("", caseHandler.CreateCase)
+		cases.GET("", caseHandler.ListCases)
+		cases.GET("/:id", caseHandler.GetCase)
+		cases.PUT("/:id", caseHandler.UpdateCase)
+		cases.POST("/:id/pan/detokenize", panHandler.DetokenizeCasePAN)
+	}
+
+	return router
+}
+
+func detokenizeAs(router *gin.Engine, caseID, roles, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/cases/"+caseID+"/pan/detokenize", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if roles != "" {
+		request.Header.Set("X-Test-Roles", roles)
+	}
+	router.ServeHTTP(w, request)
+	return w
+}
+
+func TestCaseResponses_MaskPAN(t *testing.T) {
+	router := setupPANTestRouter()
+	caseObj := createTestCase(t, router)
+	assert.Equal(t, "411111******1111", caseObj.PrimaryAccountNumber)
+
+	for _, path := range []string{"/api/v6/cases/" + caseObj.ID, "/api/v6/cases"} {
+		w := getDocumentAs(router, path, "admin")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "4111111111111111")
+		assert.NotContains(t, w.Body.String(), "tok_")
+		assert.Contains(t, w.Body.String(), "411111******1111")
+	}
+
+	// Updates can send the card number back truncated
+	req := createMockCaseRequest()
+	req.PrimaryAccountNumber = caseObj.PrimaryAccountNumber
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("PUT", "/api/v6/cases/"+caseObj.ID, bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = detokenizeAs(router, caseObj.ID, "supervisor", `{"reason":"Issuer query"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.DetokenizeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "4111111111111111", response.PrimaryAccountNumber)
+}
+
+func TestCreateCase_InvalidPAN(t *testing.T) {
+	router := setupPANTestRouter()
+
+	for _, number := range []string{"4111111111111112", "411111******1111", "4111"} {
+		req := createMockCaseRequest()
+		req.PrimaryAccountNumber = number
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/api/v6/cases", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, request)
+		assert.Equal(t, http.StatusBadRequest, w.Code, number)
+	}
+}
+
+func TestDetokenizeCasePAN(t *testing.T) {
+	router := setupPANTestRouter()
+	caseObj := createTestCase(t, router)
+
+	for _, roles := range []string{"", "viewer,analyst"} {
+		w := detokenizeAs(router, caseObj.ID, roles, `{"reason":"Issuer query"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code, roles)
+		assert.NotContains(t, w.Body.String(), "4111111111111111")
+	}
+
+	w := detokenizeAs(router, caseObj.ID, "admin", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = detokenizeAs(router, "unknown", "admin", `{"reason":"Issuer query"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = detokenizeAs(router, caseObj.ID, "admin", `{"reason":"Issuer query"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var response models.DetokenizeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, caseObj.ID, response.CaseID)
+	assert.Equal(t, "4111111111111111", response.PrimaryAccountNumber)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type ClaimHandler struct {
+	claimService *services.ClaimService
+	logger       *logger.DatadogLogger
+}
+
+func NewClaimHandler(claimService *services.ClaimService, logger *logger.DatadogLogger) *ClaimHandler {
+	return &ClaimHandler{
+		claimService: claimService,
+		logger:       logger,
+	}
+}
+
+// GetClaim handles retrieving a claim synchronised from Mastercom
+func (h *ClaimHandler) GetClaim(c *gin.Context) {
+	claimID := c.Param("claimId")
+
+	span := tracer.StartSpan("claim.get", tracer.ResourceName("GetClaim"))
+	defer span.Finish()
+
+	span.SetTag("claim.id", claimID)
+
+	claim, err := h.claimService.GetClaim(claimID)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get claim", logrus.Fields{
+			"claimId": claimID,
+			"error":   err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Claim not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Claim not found"})
+		return
+	}
+	if !requestScope(c).PermitsClaim(claim) {
+		h.logger.ErrorWithSpan(span, "Claim outside the caller's ICAs", logrus.Fields{"claimId": claimID})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Claim not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Claim not found"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Claim retrieved successfully", logrus.Fields{
+		"claimId":     claimID,
+		"chargebacks": len(claim.Chargebacks),
+	})
+
+	c.JSON(http.StatusOK, claim.Masked())
+}
+
+// Global handler functions for compatibility with main.go
+var (
+	claimService    *services.ClaimService
+	claimHandler    *ClaimHandler
+	queueSyncWorker *services.QueueSyncWorker
+)
+
+// InitClaimHandlers initializes the store for claims synchronised from Mastercom. It must be
+// called before InitReconReportHandlers so reports include synchronised chargebacks.
+func InitClaimHandlers(logger *logger.DatadogLogger) {
+	claimService = services.NewClaimService(logger)
+	claimHandler = NewClaimHandler(claimService, logger)
+}
+
+// InitQueueSyncWorker creates the Mastercom queue sync worker over the shared claim and case
+// services. It returns nil when the sync is disabled or Mastercom credentials are not
+// configured. It must be called after InitHandlers and InitClaimHandlers.
+func InitQueueSyncWorker(logger *logger.DatadogLogger) *services.QueueSyncWorker {
+	syncConfig := config.LoadQueueSyncConfig()
+	if !syncConfig.Enabled {
+		logger.Info("Queue sync disabled", nil)
+		return nil
+	}
+
+	client, err := services.NewMastercomClient(config.LoadMastercomConfig())
+	if err != nil {
+		if errors.Is(err, services.ErrMastercomNotConfigured) {
+			logger.Info("Queue sync not started: Mastercom credentials not configured", nil)
+		} else {
+			logger.Error("Queue sync not started", logrus.Fields{"error": err.Error()})
+		}
+		return nil
+	}
+
+	store := services.NewFileSyncStateStore(syncConfig.StateFile)
+	queueSyncWorker = services.NewQueueSyncWorker(claimService, caseService, client, store, syncConfig, logger)
+	return queueSyncWorker
+}
+
+// QueueSyncStatus returns the queue sync status for the health endpoint, or nil when the sync
+// is not running
+func QueueSyncStatus() *models.QueueSyncStatus {
+	if queueSyncWorker == nil {
+		return nil
+	}
+	status := queueSyncWorker.Status()
+	return &status
+}
+
+func GetClaim(c *gin.Context) {
+	if claimHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	claimHandler.GetClaim(c)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupClaimTestRouter() (*gin.Engine, *services.ClaimService) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// Initialize services and handlers
+	logger := logger.NewDatadogLogger()
+	claimService := services.NewClaimService(logger)
+	claimHandler := NewClaimHandler(claimService, logger)
+
+	// Setup routes
+	router.GET("/api/v6/claims/:claimId", claimHandler.GetClaim)
+
+	return router, claimService
+}
+
+func TestGetClaim(t *testing.T) {
+	router, claimService := setupClaimTestRouter()
+	claimService.UpsertClaim(&models.Claim{
+		ClaimID:           "200002020654",
+		QueueName:         "Unworked",
+		PrimaryAccountNum: "5555555555554444",
+		Chargebacks:       []models.Chargeback{{ChargebackID: "300002063556", ClaimID: "200002020654"}},
+	})
+
+	req, _ := http.NewRequest("GET", "/api/v6/claims/200002020654", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var claim models.Claim
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &claim))
+	assert.Equal(t, "Unworked", claim.QueueName)
+	assert.Equal(t, 1, claim.Version)
+	require.Len(t, claim.Chargebacks, 1)
+	assert.Equal(t, "555555******4444", claim.PrimaryAccountNum)
+
+	// Unknown claim
+	req, _ = http.NewRequest("GET", "/api/v6/claims/unknown", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
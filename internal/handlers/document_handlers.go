@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
-	"path/filepath"
+	"strings"
 
+	"mastercom-service/internal/config"
 	"mastercom-service/internal/models"
 	"mastercom-service/internal/services"
 	"mastercom-service/pkg/logger"
@@ -15,12 +18,16 @@ import (
 
 type DocumentHandler struct {
 	documentService *services.DocumentService
+	caseService     *services.CaseService
+	validator       *services.DocumentValidator
 	logger          *logger.DatadogLogger
 }
 
-func NewDocumentHandler(documentService *services.DocumentService, logger *logger.DatadogLogger) *DocumentHandler {
+func NewDocumentHandler(documentService *services.DocumentService, caseService *services.CaseService, logger *logger.DatadogLogger) *DocumentHandler {
 	return &DocumentHandler{
 		documentService: documentService,
+		caseService:     caseService,
+		validator:       services.NewDocumentValidator(config.LoadDocumentValidationConfig()),
 		logger:          logger,
 	}
 }
@@ -47,6 +54,13 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Case ID is required"})
 		return
 	}
+	if err := h.caseService.CheckCaseScope(caseID, requestScope(c)); err != nil {
+		h.logger.ErrorWithSpan(span, "Case outside the caller's ICAs", logrus.Fields{"caseId": caseID, "error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Case not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+		return
+	}
 
 	description := c.PostForm("description")
 	uploadedBy := c.PostForm("uploadedBy")
@@ -69,7 +83,7 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 
 	// Read file content into byte slice
 	content := make([]byte, file.Size)
-	_, err = openedFile.Read(content)
+	_, err = io.ReadFull(openedFile, content)
 	if err != nil {
 		h.logger.ErrorWithSpan(span, "Failed to read file content", logrus.Fields{"error": err.Error()})
 		span.SetTag("error", true)
@@ -78,11 +92,18 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 		return
 	}
 
+	// Identify the file type from its content and reject files Mastercom would not accept
+	fileType, err := h.validator.Validate(file.Filename, content)
+	if err != nil {
+		respondDocumentRejected(c, span, h.logger, err)
+		return
+	}
+
 	// Create document
 	document := models.NewDocument(
 		caseID,
 		file.Filename,
-		filepath.Ext(file.Filename),
+		fileType,
 		content,
 		uploadedBy,
 		description,
@@ -106,6 +127,7 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 	span.SetTag("document.id", document.ID)
 	span.SetTag("document.file_type", document.FileType)
 
+	setDocumentETag(c, document)
 	c.JSON(http.StatusCreated, document)
 }
 
@@ -123,6 +145,16 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 	span.SetTag("document.id", documentID)
 
 	document, err := h.documentService.GetDocument(documentID)
+	if errors.Is(err, services.ErrDocumentIntegrity) {
+		h.logger.ErrorWithSpan(span, "Document failed integrity check", logrus.Fields{
+			"documentId": documentID,
+			"error":      err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Document failed integrity check")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Document failed integrity check"})
+		return
+	}
 	if err != nil {
 		h.logger.ErrorWithSpan(span, "Failed to get document", logrus.Fields{
 			"documentId": documentID,
@@ -134,13 +166,28 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 		return
 	}
 
+	// Content is not served until the malware scan has found it clean
+	if document.ProcessingStatus == models.DocumentStatusQuarantined {
+		h.logger.ErrorWithSpan(span, "Document is quarantined", logrus.Fields{"documentId": documentID})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Document is quarantined")
+		c.JSON(http.StatusConflict, gin.H{"error": "Document is quarantined", "document": models.NewDocumentResponse(document)})
+		return
+	}
+
+	// Card numbers are masked unless a privileged caller asks for the original
+	document = h.documentView(c, span, document)
+	if document == nil {
+		return
+	}
+
 	h.logger.InfoWithSpan(span, "Document retrieved successfully", logrus.Fields{
 		"documentId": documentID,
 		"filename": document.FileName,
 		"fileSize": document.FileSize,
 	})
 
-	c.JSON(http.StatusOK, document)
+	respondDocumentContent(c, span, document)
 }
 
 // DeleteDocument handles deleting a document
@@ -180,9 +227,61 @@ var (
 	documentHandler *DocumentHandler
 )
 
+// respondDocumentRejected responds to an upload that failed validation, listing every
+// violation when the document was rejected
+func respondDocumentRejected(c *gin.Context, span tracer.Span, logger *logger.DatadogLogger, err error) {
+	logger.ErrorWithSpan(span, "Document rejected", logrus.Fields{"error": err.Error()})
+	span.SetTag("error", true)
+	span.SetTag("error.message", "Document rejected")
+
+	var validationErr *services.DocumentValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Document rejected", "violations": validationErr.Violations})
+		return
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Document rejected", "details": err.Error()})
+}
+
+// documentETag is a document's strong entity tag, its content checksum
+func documentETag(document *models.Document) string {
+	return `"` + document.SHA256 + `"`
+}
+
+// setDocumentETag sets the ETag header of a response carrying a document
+func setDocumentETag(c *gin.Context, document *models.Document) {
+	if document.SHA256 != "" {
+		c.Header("ETag", documentETag(document))
+	}
+}
+
+// respondDocumentContent responds with a document and its content, or with 304 when the
+// client's If-None-Match already holds it
+func respondDocumentContent(c *gin.Context, span tracer.Span, document *models.Document) {
+	setDocumentETag(c, document)
+	if document.SHA256 != "" && etagMatches(c.GetHeader("If-None-Match"), documentETag(document)) {
+		span.SetTag("http.not_modified", true)
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, document)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or is *
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// InitDocumentHandlers initializes the document handlers. It must be called after
+// InitHandlers so documents are scoped by the shared case store.
 func InitDocumentHandlers(logger *logger.DatadogLogger) {
 	documentService = services.NewDocumentService(logger)
-	documentHandler = NewDocumentHandler(documentService, logger)
+	documentHandler = NewDocumentHandler(documentService, caseService, logger)
 }
 
 func UploadDocument(c *gin.Context) {
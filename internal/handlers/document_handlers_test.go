@@ -2,7 +2,13 @@ package handlers
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -14,7 +20,6 @@ import (
 	"mastercom-service/pkg/logger"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,7 +31,7 @@ func setupDocumentTestRouter() *gin.Engine {
 	// Initialize services and handlers
 	logger := logger.NewDatadogLogger()
 	documentService := services.NewDocumentService(logger)
-	documentHandler := NewDocumentHandler(documentService, logger)
+	documentHandler := NewDocumentHandler(documentService, services.NewCaseService(logger), logger)
 	
 	// Setup routes
 	api := router.Group("/api/v6")
@@ -40,6 +45,22 @@ func setupDocumentTestRouter() *gin.Engine {
 	return router
 }
 
+// testPDF returns a minimal PDF document with the given number of pages
+func testPDF(pages int) string {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	kids := ""
+	for i := 0; i < pages; i++ {
+		kids += fmt.Sprintf("%d 0 R ", i+3)
+	}
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", kids, pages)
+	for i := 0; i < pages; i++ {
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>\nendobj\n", i+3)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.String()
+}
+
 func createMockMultipartRequest(caseID, fileName, content, description, uploadedBy string) (*http.Request, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
@@ -74,7 +95,7 @@ func TestUploadDocument_Success(t *testing.T) {
 	req, err := createMockMultipartRequest(
 		"test-case-id",
 		"test-document.pdf",
-		"This is test document content",
+		testPDF(1),
 		"Test document description",
 		"test-user",
 	)
@@ -92,8 +113,8 @@ func TestUploadDocument_Success(t *testing.T) {
 	assert.NotEmpty(t, response.ID)
 	assert.Equal(t, "test-case-id", response.CaseID)
 	assert.Equal(t, "test-document.pdf", response.FileName)
-	assert.Equal(t, ".pdf", response.FileType)
-	assert.Equal(t, int64(29), response.FileSize) // Length of "This is test document content"
+	assert.Equal(t, "application/pdf", response.FileType)
+	assert.Equal(t, int64(len(testPDF(1))), response.FileSize)
 	assert.Equal(t, "test-user", response.UploadedBy)
 	assert.Equal(t, "Test document description", response.Description)
 }
@@ -164,7 +185,7 @@ func TestGetDocument_Success(t *testing.T) {
 	req, err := createMockMultipartRequest(
 		"test-case-id",
 		"test-document.pdf",
-		"This is test document content",
+		testPDF(1),
 		"Test document description",
 		"test-user",
 	)
@@ -192,6 +213,50 @@ func TestGetDocument_Success(t *testing.T) {
 	assert.Equal(t, uploadedDoc.FileSize, response.FileSize)
 }
 
+func TestGetDocument_ETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logger.NewDatadogLogger()
+	documentService := services.NewDocumentService(logger)
+	documentHandler := NewDocumentHandler(documentService, services.NewCaseService(logger), logger)
+	router := gin.New()
+	router.GET("/api/v6/documents/:id", documentHandler.GetDocument)
+	
+	content := []byte(testPDF(1))
+	document := models.NewDocument("test-case-id", "receipt.pdf", "application/pdf", content, "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+	sum := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/documents/"+document.ID, nil)
+	router.ServeHTTP(w, request)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	
+	var response models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, hex.EncodeToString(sum[:]), response.SHA256)
+	
+	// A client holding the current copy is not sent it again
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/documents/"+document.ID, nil)
+	request.Header.Set("If-None-Match", `"other", `+etag)
+	router.ServeHTTP(w, request)
+	
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+	
+	// Content altered after upload is never served
+	document.Content[0] = 'X'
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/documents/"+document.ID, nil)
+	router.ServeHTTP(w, request)
+	
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "integrity")
+}
+
 func TestGetDocument_NotFound(t *testing.T) {
 	router := setupDocumentTestRouter()
 	
@@ -215,7 +280,7 @@ func TestDeleteDocument_Success(t *testing.T) {
 	req, err := createMockMultipartRequest(
 		"test-case-id",
 		"test-document.pdf",
-		"This is test document content",
+		testPDF(1),
 		"Test document description",
 		"test-user",
 	)
@@ -260,18 +325,23 @@ func TestDeleteDocument_NotFound(t *testing.T) {
 
 func TestUploadDocument_DifferentFileTypes(t *testing.T) {
 	router := setupDocumentTestRouter()
-	
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var jpegContent, pngContent bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpegContent, img, nil))
+	require.NoError(t, png.Encode(&pngContent, img))
+
 	testCases := []struct {
-		fileName string
-		content  string
-		expectedExt string
+		fileName     string
+		content      string
+		expectedType string
 	}{
-		{"document.pdf", "PDF content", ".pdf"},
-		{"image.jpg", "Image content", ".jpg"},
-		{"data.xlsx", "Excel content", ".xlsx"},
-		{"text.txt", "Text content", ".txt"},
+		{"document.pdf", testPDF(2), "application/pdf"},
+		{"image.jpg", jpegContent.String(), "image/jpeg"},
+		{"IMAGE.JPEG", jpegContent.String(), "image/jpeg"},
+		{"image.png", pngContent.String(), "image/png"},
 	}
-	
+
 	for _, tc := range testCases {
 		t.Run(tc.fileName, func(t *testing.T) {
 			req, err := createMockMultipartRequest(
@@ -282,28 +352,82 @@ func TestUploadDocument_DifferentFileTypes(t *testing.T) {
 				"test-user",
 			)
 			require.NoError(t, err)
-			
+
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
-			
+
 			assert.Equal(t, http.StatusCreated, w.Code)
-			
+
 			var response models.Document
 			err = json.Unmarshal(w.Body.Bytes(), &response)
 			require.NoError(t, err)
-			
-			assert.Equal(t, tc.expectedExt, response.FileType)
+
+			assert.Equal(t, tc.expectedType, response.FileType)
 			assert.Equal(t, int64(len(tc.content)), response.FileSize)
 		})
 	}
 }
 
+func TestUploadDocument_Rejected(t *testing.T) {
+	t.Setenv("DOCUMENT_PDF_MAX_PAGES", "2")
+	t.Setenv("DOCUMENT_PDF_MAX_SIZE", "1024")
+	router := setupDocumentTestRouter()
+
+	executable := "MZ\x90\x00" + strings.Repeat("\x00", 60)
+
+	testCases := []struct {
+		name          string
+		fileName      string
+		content       string
+		expectedCodes []string
+	}{
+		{"executable named as a PDF", "invoice.pdf", executable, []string{models.DocumentViolationUnsupportedType}},
+		{"plain text", "notes.txt", "Text content", []string{models.DocumentViolationUnsupportedType}},
+		{"empty file", "empty.pdf", "", []string{models.DocumentViolationEmpty}},
+		{"PDF named as an image", "scan.jpg", testPDF(1), []string{models.DocumentViolationExtensionMismatch}},
+		{"too many pages", "document.pdf", testPDF(3), []string{models.DocumentViolationTooManyPages}},
+		{"every violation at once", "document.tif", testPDF(3) + "%" + strings.Repeat("A", 1024), []string{
+			models.DocumentViolationExtensionMismatch,
+			models.DocumentViolationTooLarge,
+			models.DocumentViolationTooManyPages,
+		}},
+		{"unreadable PDF", "document.pdf", "%PDF-1.4\n%%EOF\n", []string{models.DocumentViolationUnreadable}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := createMockMultipartRequest("test-case-id", tc.fileName, tc.content, "", "test-user")
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+			var response struct {
+				Error      string                     `json:"error"`
+				Violations []models.DocumentViolation `json:"violations"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "Document rejected", response.Error)
+
+			codes := make([]string, 0, len(response.Violations))
+			for _, violation := range response.Violations {
+				assert.NotEmpty(t, violation.Message)
+				codes = append(codes, violation.Code)
+			}
+			assert.Equal(t, tc.expectedCodes, codes)
+		})
+	}
+}
+
 func TestUploadDocument_LargeFile(t *testing.T) {
 	router := setupDocumentTestRouter()
-	
-	// Create a large content (simulating large file)
-	largeContent := strings.Repeat("A", 1024*1024) // 1MB
-	
+
+	// Pad a PDF with a comment to simulate a large file
+	content := testPDF(1)
+	largeContent := content + "%" + strings.Repeat("A", 1024*1024-len(content)-1) // 1MB
+
 	req, err := createMockMultipartRequest(
 		"test-case-id",
 		"large-file.pdf",
@@ -312,15 +436,15 @@ func TestUploadDocument_LargeFile(t *testing.T) {
 		"test-user",
 	)
 	require.NoError(t, err)
-	
+
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	
+
 	assert.Equal(t, http.StatusCreated, w.Code)
-	
+
 	var response models.Document
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
-	
+
 	assert.Equal(t, int64(1024*1024), response.FileSize)
 }
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// InitDocumentRedaction enables masking of card numbers in uploaded PDFs on the shared
+// document service when it is configured. It must be called after InitDocumentHandlers.
+func InitDocumentRedaction(logger *logger.DatadogLogger) {
+	redactionConfig := config.LoadRedactionConfig()
+	if !redactionConfig.Enabled {
+		logger.Info("Document card number redaction disabled", nil)
+		return
+	}
+
+	documentService.EnableRedaction(redactionConfig)
+	logger.Info("Document card number redaction enabled", logrus.Fields{"originalRoles": redactionConfig.OriginalRoles})
+}
+
+// requestOriginal reports whether the caller asked for the original content of redacted
+// documents with ?original=true. When the caller's principal holds none of the roles allowed
+// the originals, it responds 403 and returns ok false.
+func requestOriginal(c *gin.Context, span tracer.Span, log *logger.DatadogLogger, allowed func(roles []string) bool) (original, ok bool) {
+	if c.Query("original") != "true" {
+		return false, true
+	}
+
+	principal := middleware.PrincipalFrom(c)
+	var roles []string
+	subject := ""
+	if principal != nil {
+		roles, subject = principal.Roles, principal.Subject
+	}
+	span.SetTag("document.original", true)
+	if !allowed(roles) {
+		log.ErrorWithSpan(span, "Original document content denied", logrus.Fields{
+			"path":    c.Request.URL.Path,
+			"subject": subject,
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Original document content requires a privileged role")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Original document content requires a privileged role"})
+		return false, false
+	}
+
+	log.InfoWithSpan(span, "Original document content requested", logrus.Fields{
+		"path":    c.Request.URL.Path,
+		"subject": subject,
+	})
+	return true, true
+}
+
+// documentView returns a document as the caller may see it: with its card numbers masked,
+// unless a privileged caller asked for the original. Content is withheld from other callers
+// while a PDF awaits redaction or when it could not be checked for card numbers; documentView
+// then responds with the error itself and returns nil. document is a copy read from the
+// document service, so its redaction status and content cannot change underneath it.
+func (h *DocumentHandler) documentView(c *gin.Context, span tracer.Span, document *models.Document) *models.Document {
+	original, ok := requestOriginal(c, span, h.logger, h.documentService.OriginalAccessAllowed)
+	if !ok {
+		return nil
+	}
+	if original || document.Content == nil || document.Redaction == nil {
+		return document
+	}
+
+	var message string
+	switch document.Redaction.Status {
+	case models.RedactionStatusPending:
+		message = "Document is awaiting card number redaction"
+	case models.RedactionStatusFailed:
+		message = "Document could not be checked for card numbers"
+	default:
+		span.SetTag("document.redacted", document.Redaction.Status == models.RedactionStatusRedacted)
+		return document.Redacted()
+	}
+
+	h.logger.ErrorWithSpan(span, message, logrus.Fields{
+		"documentId": document.ID,
+		"version":    document.Version,
+	})
+	span.SetTag("error", true)
+	span.SetTag("error.message", message)
+	c.JSON(http.StatusConflict, gin.H{"error": message, "document": models.NewDocumentResponse(document)})
+	return nil
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRedactionTestRouter redacts uploaded PDFs and takes the caller's roles from the
+// X-Test-Roles header
+func setupRedactionTestRouter() (*gin.Engine, *services.DocumentService) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	logger := logger.NewDatadogLogger()
+	documentService := services.NewDocumentService(logger)
+	documentService.EnableRedaction(&models.RedactionConfig{Enabled: true, OriginalRoles: []string{"supervisor", "admin"}})
+	documentHandler := NewDocumentHandler(documentService, services.NewCaseService(logger), logger)
+
+	router.Use(func(c *gin.Context) {
+		if roles := c.GetHeader("X-Test-Roles"); roles != "" {
+			middleware.SetPrincipal(c, &middleware.Principal{Subject: "test-user", Roles: strings.Split(roles, ","), ICAs: []string{models.AllICAs}})
+		}
+		c.Next()
+	})
+	documents := router.Group("/api/v6/documents")
+	{
+		documents.GET("/:id", documentHandler.GetDocument)
+		documents.GET("/:id/versions/:version", documentHandler.GetDocumentVersion)
+	}
+
+	return router, documentService
+}
+
+// cardNumberPDF returns a one page PDF showing a line of text
+func cardNumberPDF(text string) []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 72 700 Td (%s) Tj ET", text)
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	buf.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	buf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>\nendobj\n")
+	fmt.Fprintf(&buf, "4 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(content), content)
+	buf.WriteString("5 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>\nendobj\n")
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func getDocumentAs(router *gin.Engine, path, roles string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", path, nil)
+	if roles != "" {
+		request.Header.Set("X-Test-Roles", roles)
+	}
+	router.ServeHTTP(w, request)
+	return w
+}
+
+func TestGetDocument_Redacted(t *testing.T) {
+	router, documentService := setupRedactionTestRouter()
+
+	original := cardNumberPDF("Card 4111 1111 1111 1111")
+	document := models.NewDocument("test-case-id", "receipt.pdf", "application/pdf", original, "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+	path := "/api/v6/documents/" + document.ID
+
+	// The redacted derivative is served by default, to every caller
+	for _, roles := range []string{"", "viewer", "supervisor"} {
+		w := getDocumentAs(router, path, roles)
+		require.Equal(t, http.StatusOK, w.Code, roles)
+		var response models.Document
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, document.RedactedContent, response.Content)
+		assert.Equal(t, document.Redaction.SHA256, response.SHA256)
+		assert.Equal(t, `"`+document.Redaction.SHA256+`"`, w.Header().Get("ETag"))
+		require.NotNil(t, response.Redaction)
+		assert.Equal(t, 1, response.Redaction.CardNumbers)
+	}
+
+	// The original only to privileged roles
+	for _, roles := range []string{"", "viewer,analyst"} {
+		w := getDocumentAs(router, path+"?original=true", roles)
+		assert.Equal(t, http.StatusForbidden, w.Code, roles)
+		assert.NotContains(t, w.Body.String(), `"content"`)
+	}
+	w := getDocumentAs(router, path+"?original=true", "analyst,admin")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, original, response.Content)
+	assert.Equal(t, document.SHA256, response.SHA256)
+
+	// Versions are served the same way
+	w = getDocumentAs(router, path+"/versions/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, document.RedactedContent, response.Content)
+	w = getDocumentAs(router, path+"/versions/1?original=true", "viewer")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// Run with -race: reads while the scan and redaction complete get a document whose
+// redaction decision matches the content served
+func TestGetDocument_WhileRedacting(t *testing.T) {
+	router, documentService := setupRedactionTestRouter()
+	documentService.EnableScanning(&fakeScanner{}, &models.ScanConfig{MaxAttempts: 3})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go services.NewDocumentScanWorker(documentService, &models.ScanConfig{RescanInterval: 3600}, logger.NewDatadogLogger()).Run(ctx)
+
+	original := cardNumberPDF("Card 4111 1111 1111 1111")
+	document := models.NewDocument("test-case-id", "receipt.pdf", "application/pdf", original, "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+	path := "/api/v6/documents/" + document.ID
+
+	assert.Eventually(t, func() bool {
+		w := getDocumentAs(router, path, "viewer")
+		if w.Code == http.StatusConflict {
+			return false
+		}
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.Document
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotNil(t, response.Redaction)
+		assert.Equal(t, models.RedactionStatusRedacted, response.Redaction.Status)
+		assert.Equal(t, response.Redaction.SHA256, response.SHA256)
+		assert.NotEqual(t, original, response.Content)
+		return true
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestGetDocument_NotRedactable(t *testing.T) {
+	router, documentService := setupRedactionTestRouter()
+
+	document := models.NewDocument("test-case-id", "scan.pdf", "application/pdf", []byte("%PDF-1.4 no objects"), "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+	path := "/api/v6/documents/" + document.ID
+
+	// Content that could not be checked for card numbers is withheld
+	w := getDocumentAs(router, path, "viewer")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "could not be checked for card numbers")
+	assert.NotContains(t, w.Body.String(), `"content"`)
+
+	w = getDocumentAs(router, path+"?original=true", "supervisor")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Documents without card numbers are served as they are
+	clean := models.NewDocument("test-case-id", "letter.pdf", "application/pdf", cardNumberPDF("no card here"), "test-user", "")
+	require.NoError(t, documentService.UploadDocument(clean))
+	w = getDocumentAs(router, "/api/v6/documents/"+clean.ID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, clean.Content, response.Content)
+}
+
+// exportedManifest reads the manifest of a case export archive
+func exportedManifest(t *testing.T, archive []byte) models.CaseExportManifest {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	var manifest models.CaseExportManifest
+	for _, file := range reader.File {
+		if file.Name == "manifest.json" {
+			opened, err := file.Open()
+			require.NoError(t, err)
+			require.NoError(t, json.NewDecoder(opened).Decode(&manifest))
+			opened.Close()
+		}
+	}
+	return manifest
+}
+
+func TestExportCase_Originals(t *testing.T) {
+	router, documentService := setupRedactionTestRouter()
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	caseDocumentService := services.NewCaseDocumentService(caseService, documentService, logger)
+	router.POST("/api/v6/cases", NewCaseHandler(caseService, logger).CreateCase)
+	router.GET("/api/v6/cases/:id/export", NewCaseDocumentHandler(caseDocumentService, logger).ExportCase)
+
+	caseObj := createTestCase(t, router)
+	document := models.NewDocument(caseObj.ID, "receipt.pdf", "application/pdf", cardNumberPDF("4111111111111111"), "test-user", "")
+	require.NoError(t, caseDocumentService.AttachDocument(caseObj.ID, document))
+	path := "/api/v6/cases/" + caseObj.ID + "/export"
+
+	w := getDocumentAs(router, path, "viewer")
+	require.Equal(t, http.StatusOK, w.Code)
+	manifest := exportedManifest(t, w.Body.Bytes())
+	require.Len(t, manifest.Files, 3)
+	assert.True(t, manifest.Files[2].Redacted)
+
+	w = getDocumentAs(router, path+"?original=true", "viewer")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = getDocumentAs(router, path+"?original=true", "supervisor")
+	require.Equal(t, http.StatusOK, w.Code)
+	manifest = exportedManifest(t, w.Body.Bytes())
+	require.Len(t, manifest.Files, 3)
+	assert.False(t, manifest.Files[2].Redacted)
+	assert.Equal(t, document.SHA256, manifest.Files[2].SHA256)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/clamd"
+	"mastercom-service/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InitDocumentScanning enables malware scanning of uploads on the shared document service and
+// returns the worker that retries failed scans. It returns nil when no clamd address is
+// configured. It must be called after InitDocumentHandlers.
+func InitDocumentScanning(logger *logger.DatadogLogger) *services.DocumentScanWorker {
+	scanConfig := config.LoadScanConfig()
+	if scanConfig.ClamdAddress == "" {
+		logger.Info("Document malware scanning disabled: clamd address not configured", nil)
+		return nil
+	}
+
+	client, err := clamd.NewClient(scanConfig.ClamdAddress, time.Duration(scanConfig.Timeout)*time.Second)
+	if err != nil {
+		logger.Error("Document malware scanning not enabled", logrus.Fields{"error": err.Error()})
+		return nil
+	}
+
+	// Uploads are still quarantined while the daemon is unreachable, and scanned once it is back
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if version, err := client.Version(ctx); err != nil {
+		logger.Error("clamd is not reachable; uploads stay quarantined until it is", logrus.Fields{
+			"address": scanConfig.ClamdAddress,
+			"error":   err.Error(),
+		})
+	} else {
+		logger.Info("Document malware scanning enabled", logrus.Fields{
+			"address": scanConfig.ClamdAddress,
+			"version": version,
+		})
+	}
+
+	documentService.EnableScanning(services.NewClamdScanner(client), scanConfig)
+	return services.NewDocumentScanWorker(documentService, scanConfig, logger)
+}
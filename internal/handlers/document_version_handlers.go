@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// ReplaceDocumentContent handles uploading a corrected file as the next version of a document
+func (h *DocumentHandler) ReplaceDocumentContent(c *gin.Context) {
+	documentID := c.Param("id")
+	if documentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("document.replace", tracer.ResourceName("ReplaceDocumentContent"))
+	defer span.Finish()
+
+	span.SetTag("document.id", documentID)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get uploaded file", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "No file uploaded")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+
+	uploadedBy := c.PostForm("uploadedBy")
+	span.SetTag("document.filename", file.Filename)
+	span.SetTag("document.size", file.Size)
+	span.SetTag("document.uploaded_by", uploadedBy)
+
+	openedFile, err := file.Open()
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to open uploaded file", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Failed to process file")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
+		return
+	}
+	defer openedFile.Close()
+
+	content := make([]byte, file.Size)
+	if _, err := io.ReadFull(openedFile, content); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to read file content", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Failed to read file content")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file content"})
+		return
+	}
+
+	fileType, err := h.validator.Validate(file.Filename, content)
+	if err != nil {
+		respondDocumentRejected(c, span, h.logger, err)
+		return
+	}
+
+	replacement := models.NewDocument("", file.Filename, fileType, content, uploadedBy, c.PostForm("description"))
+	if err := h.documentService.ReplaceDocumentContent(documentID, replacement); err != nil {
+		switch {
+		case errors.Is(err, services.ErrDocumentNotFound):
+			h.respondVersionError(c, span, http.StatusNotFound, "Document not found", err)
+		case errors.Is(err, services.ErrDocumentNotReplaceable):
+			h.respondVersionError(c, span, http.StatusConflict, "Document content cannot be replaced", err)
+		default:
+			h.respondVersionError(c, span, http.StatusInternalServerError, "Failed to replace document content", err)
+		}
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Document content replaced successfully", logrus.Fields{
+		"documentId": documentID,
+		"caseId":     replacement.CaseID,
+		"version":    replacement.Version,
+		"filename":   replacement.FileName,
+		"fileSize":   replacement.FileSize,
+	})
+
+	span.SetTag("document.version", replacement.Version)
+	span.SetTag("document.file_type", replacement.FileType)
+
+	setDocumentETag(c, replacement)
+	c.JSON(http.StatusOK, models.NewDocumentResponse(replacement))
+}
+
+// ListDocumentVersions handles listing every version of a document
+func (h *DocumentHandler) ListDocumentVersions(c *gin.Context) {
+	documentID := c.Param("id")
+	if documentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("document.versions.list", tracer.ResourceName("ListDocumentVersions"))
+	defer span.Finish()
+
+	span.SetTag("document.id", documentID)
+
+	versions, err := h.documentService.ListDocumentVersions(documentID)
+	if err != nil {
+		h.respondVersionError(c, span, http.StatusNotFound, "Document not found", err)
+		return
+	}
+
+	responses := make([]models.DocumentResponse, 0, len(versions))
+	for _, version := range versions {
+		responses = append(responses, models.NewDocumentResponse(version))
+	}
+
+	span.SetTag("document.versions", len(responses))
+	c.JSON(http.StatusOK, gin.H{
+		"documentId": documentID,
+		"versions":   responses,
+		"total":      len(responses),
+	})
+}
+
+// GetDocumentVersion handles retrieving a version of a document, with its content
+func (h *DocumentHandler) GetDocumentVersion(c *gin.Context) {
+	documentID := c.Param("id")
+	if documentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("document.versions.get", tracer.ResourceName("GetDocumentVersion"))
+	defer span.Finish()
+
+	span.SetTag("document.id", documentID)
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		h.respondVersionError(c, span, http.StatusBadRequest, "Version must be a positive number", errors.New("invalid version "+c.Param("version")))
+		return
+	}
+	span.SetTag("document.version", version)
+
+	document, err := h.documentService.GetDocumentVersion(documentID, version)
+	switch {
+	case errors.Is(err, services.ErrDocumentIntegrity):
+		h.respondVersionError(c, span, http.StatusInternalServerError, "Document failed integrity check", err)
+		return
+	case errors.Is(err, services.ErrDocumentVersionNotFound):
+		h.respondVersionError(c, span, http.StatusNotFound, "Document version not found", err)
+		return
+	case err != nil:
+		h.respondVersionError(c, span, http.StatusNotFound, "Document not found", err)
+		return
+	}
+
+	if document.ProcessingStatus == models.DocumentStatusQuarantined {
+		h.respondVersionError(c, span, http.StatusConflict, "Document is quarantined", errors.New("version is awaiting a malware scan"))
+		return
+	}
+
+	if document = h.documentView(c, span, document); document == nil {
+		return
+	}
+	respondDocumentContent(c, span, document)
+}
+
+// RestoreDocument handles undoing the deletion of a document
+func (h *DocumentHandler) RestoreDocument(c *gin.Context) {
+	documentID := c.Param("id")
+	if documentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		return
+	}
+
+	span := tracer.StartSpan("document.restore", tracer.ResourceName("RestoreDocument"))
+	defer span.Finish()
+
+	span.SetTag("document.id", documentID)
+
+	document, err := h.documentService.RestoreDocument(documentID)
+	switch {
+	case errors.Is(err, services.ErrDocumentNotDeleted):
+		h.respondVersionError(c, span, http.StatusConflict, "Document is not deleted", err)
+		return
+	case err != nil:
+		h.respondVersionError(c, span, http.StatusNotFound, "Document not found", err)
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Document restored successfully", logrus.Fields{
+		"documentId": documentID,
+		"caseId":     document.CaseID,
+	})
+
+	c.JSON(http.StatusOK, models.NewDocumentResponse(document))
+}
+
+func (h *DocumentHandler) respondVersionError(c *gin.Context, span tracer.Span, status int, message string, err error) {
+	h.logger.ErrorWithSpan(span, message, logrus.Fields{"error": err.Error()})
+	span.SetTag("error", true)
+	span.SetTag("error.message", message)
+	c.JSON(status, gin.H{"error": message})
+}
+
+func ReplaceDocumentContent(c *gin.Context) {
+	if documentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	documentHandler.ReplaceDocumentContent(c)
+}
+
+func ListDocumentVersions(c *gin.Context) {
+	if documentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	documentHandler.ListDocumentVersions(c)
+}
+
+func GetDocumentVersion(c *gin.Context) {
+	if documentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	documentHandler.GetDocumentVersion(c)
+}
+
+func RestoreDocument(c *gin.Context) {
+	if documentHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	documentHandler.RestoreDocument(c)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDocumentVersionTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	logger := logger.NewDatadogLogger()
+	documentService := services.NewDocumentService(logger)
+	documentHandler := NewDocumentHandler(documentService, services.NewCaseService(logger), logger)
+
+	documents := router.Group("/api/v6/documents")
+	{
+		documents.POST("", documentHandler.UploadDocument)
+		documents.GET("/:id", documentHandler.GetDocument)
+		documents.DELETE("/:id", documentHandler.DeleteDocument)
+		documents.POST("/:id/restore", documentHandler.RestoreDocument)
+		documents.PUT("/:id/content", documentHandler.ReplaceDocumentContent)
+		documents.GET("/:id/versions", documentHandler.ListDocumentVersions)
+		documents.GET("/:id/versions/:version", documentHandler.GetDocumentVersion)
+	}
+
+	return router
+}
+
+func createReplaceContentRequest(documentID, fileName, content, uploadedBy string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", fileName)
+	part.Write([]byte(content))
+	writer.WriteField("uploadedBy", uploadedBy)
+	writer.Close()
+
+	req, _ := http.NewRequest("PUT", "/api/v6/documents/"+documentID+"/content", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestReplaceDocumentContent(t *testing.T) {
+	router := setupDocumentVersionTestRouter()
+	original, replacement := testPDF(1), testPDF(2)
+
+	req, err := createMockMultipartRequest("test-case-id", "scan.pdf", original, "Receipt", "analyst-1")
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var uploaded models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &uploaded))
+	assert.Equal(t, 1, uploaded.Version)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createReplaceContentRequest(uploaded.ID, "rescan.pdf", replacement, "analyst-2"))
+	require.Equal(t, http.StatusOK, w.Code)
+	var replaced models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replaced))
+	assert.Equal(t, uploaded.ID, replaced.ID)
+	assert.Equal(t, 2, replaced.Version)
+	assert.Equal(t, "rescan.pdf", replaced.FileName)
+	assert.Equal(t, "Receipt", replaced.Description)
+	assert.Equal(t, `"`+replaced.SHA256+`"`, w.Header().Get("ETag"))
+
+	// The document shows the latest version
+	w = httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/documents/"+uploaded.ID, nil)
+	router.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+	var latest models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &latest))
+	assert.Equal(t, []byte(replacement), latest.Content)
+
+	// Every version is listed, and the original can still be fetched
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/documents/"+uploaded.ID+"/versions", nil)
+	router.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listing struct {
+		Versions []models.DocumentResponse `json:"versions"`
+		Total    int                       `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listing))
+	assert.Equal(t, 2, listing.Total)
+	assert.Equal(t, "scan.pdf", listing.Versions[0].FileName)
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/documents/"+uploaded.ID+"/versions/1", nil)
+	router.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+	var first models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	assert.Equal(t, []byte(original), first.Content)
+	assert.Equal(t, `"`+uploaded.SHA256+`"`, w.Header().Get("ETag"))
+}
+
+func TestReplaceDocumentContent_Errors(t *testing.T) {
+	router := setupDocumentVersionTestRouter()
+
+	req, err := createMockMultipartRequest("test-case-id", "scan.pdf", testPDF(1), "", "analyst-1")
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var uploaded models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &uploaded))
+
+	for name, tc := range map[string]struct {
+		request *http.Request
+		status  int
+	}{
+		"unknown document": {createReplaceContentRequest("nonexistent-id", "scan.pdf", testPDF(1), ""), http.StatusNotFound},
+		"not a document":   {createReplaceContentRequest(uploaded.ID, "scan.pdf", "MZ\x90\x00", ""), http.StatusUnprocessableEntity},
+		"bad version":      {httptest.NewRequest("GET", "/api/v6/documents/"+uploaded.ID+"/versions/latest", nil), http.StatusBadRequest},
+		"unknown version":  {httptest.NewRequest("GET", "/api/v6/documents/"+uploaded.ID+"/versions/9", nil), http.StatusNotFound},
+		"not deleted":      {httptest.NewRequest("POST", "/api/v6/documents/"+uploaded.ID+"/restore", nil), http.StatusConflict},
+		"restore unknown":  {httptest.NewRequest("POST", "/api/v6/documents/nonexistent-id/restore", nil), http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, tc.request)
+		assert.Equal(t, tc.status, w.Code, name)
+	}
+}
+
+func TestRestoreDocument(t *testing.T) {
+	router := setupDocumentVersionTestRouter()
+
+	req, err := createMockMultipartRequest("test-case-id", "scan.pdf", testPDF(1), "", "analyst-1")
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var uploaded models.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &uploaded))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v6/documents/"+uploaded.ID, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for _, path := range []string{"", "/versions", "/versions/1"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v6/documents/"+uploaded.ID+path, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v6/documents/"+uploaded.ID+"/restore", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v6/documents/"+uploaded.ID, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// EncryptionHandler serves rotation of the keys that encrypt sensitive case data at rest
+type EncryptionHandler struct {
+	// encryptionService is nil when encryption at rest is disabled
+	encryptionService *services.EncryptionService
+	// loadConfig reads the key configuration again for each rotation
+	loadConfig func() (*models.EncryptionConfig, error)
+	logger     *logger.DatadogLogger
+}
+
+func NewEncryptionHandler(encryptionService *services.EncryptionService, loadConfig func() (*models.EncryptionConfig, error), logger *logger.DatadogLogger) *EncryptionHandler {
+	return &EncryptionHandler{
+		encryptionService: encryptionService,
+		loadConfig:        loadConfig,
+		logger:            logger,
+	}
+}
+
+// RotateEncryptionKeys handles re-wrapping every stored data key with the primary key of the
+// key configuration as it now stands, such as after a new key was added to the keys file
+func (h *EncryptionHandler) RotateEncryptionKeys(c *gin.Context) {
+	span := tracer.StartSpan("encryption.rotate", tracer.ResourceName("RotateEncryptionKeys"))
+	defer span.Finish()
+
+	if h.encryptionService == nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Encryption at rest is not enabled")
+		c.JSON(http.StatusConflict, gin.H{"error": "Encryption at rest is not enabled"})
+		return
+	}
+
+	encryptionConfig, err := h.loadConfig()
+	if err == nil && !encryptionConfig.Enabled() {
+		err = errors.New("no encryption keys configured")
+	}
+	if err != nil {
+		h.respondRotationError(c, span, "Failed to load encryption keys", err)
+		return
+	}
+
+	previousKeyID := h.encryptionService.PrimaryKeyID()
+	report, err := h.encryptionService.RotateKeys(encryptionConfig)
+	if err != nil {
+		h.respondRotationError(c, span, "Failed to rotate encryption keys", err)
+		return
+	}
+
+	span.SetTag("encryption.primary_key_id", report.PrimaryKeyID)
+	h.logger.InfoWithSpan(span, "Encryption key rotation requested", logrus.Fields{
+		"previousKeyId": previousKeyID,
+		"primaryKeyId":  report.PrimaryKeyID,
+		"subject":       principalSubject(c),
+	})
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *EncryptionHandler) respondRotationError(c *gin.Context, span tracer.Span, message string, err error) {
+	h.logger.ErrorWithSpan(span, message, logrus.Fields{"error": err.Error()})
+	span.SetTag("error", true)
+	span.SetTag("error.message", message)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+}
+
+// Global handler functions for compatibility with main.go
+var encryptionHandler *EncryptionHandler
+
+// InitEncryption enables encryption at rest on the shared case and document services when
+// encryption keys are configured. It must be called after InitHandlers and
+// InitDocumentHandlers, before any case or document is stored. A key configuration that
+// cannot be loaded is returned as an error rather than leaving data unencrypted.
+func InitEncryption(logger *logger.DatadogLogger) error {
+	encryptionConfig, err := config.LoadEncryptionConfig()
+	if err != nil {
+		return err
+	}
+
+	var encryptionService *services.EncryptionService
+	if encryptionConfig.Enabled() {
+		encryptionService, err = services.NewEncryptionService(caseService, documentService, encryptionConfig, logger)
+		if err != nil {
+			return err
+		}
+		logger.Info("Encryption at rest enabled", logrus.Fields{
+			"primaryKeyId": encryptionConfig.PrimaryKeyID,
+			"keys":         len(encryptionConfig.Keys),
+			"keysFile":     encryptionConfig.KeysFile,
+		})
+	} else {
+		logger.Info("Encryption at rest disabled: no encryption keys configured", nil)
+	}
+
+	encryptionHandler = NewEncryptionHandler(encryptionService, config.LoadEncryptionConfig, logger)
+	return nil
+}
+
+func RotateEncryptionKeys(c *gin.Context) {
+	if encryptionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	encryptionHandler.RotateEncryptionKeys(c)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/envelope"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupEncryptionTestRouter serves cases encrypted under k1; rotations load the keys from keys
+func setupEncryptionTestRouter(t *testing.T, keys *models.EncryptionConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	encryptionService, err := services.NewEncryptionService(caseService, services.NewDocumentService(logger), &models.EncryptionConfig{
+		PrimaryKeyID: "k1",
+		Keys:         map[string][]byte{"k1": keys.Keys["k1"]},
+	}, logger)
+	require.NoError(t, err)
+	caseHandler := NewCaseHandler(caseService, logger)
+	encryptionHandler := NewEncryptionHandler(encryptionService, func() (*models.EncryptionConfig, error) {
+		if keys.Keys == nil {
+			return nil, errors.New("keys file not readable")
+		}
+		return keys, nil
+	}, logger)
+
+	api := router.Group("/api/v6")
+	api.POST("/cases", caseHandler.CreateCase)
+	api.GET("/cases/:id", caseHandler.GetCase)
+	api.POST("/admin/encryption/rotate", encryptionHandler.RotateEncryptionKeys)
+	return router
+}
+
+func TestRotateEncryptionKeys(t *testing.T) {
+	k1, k2 := envelope.GenerateKey(), envelope.GenerateKey()
+	keys := &models.EncryptionConfig{PrimaryKeyID: "k1", Keys: map[string][]byte{"k1": k1}}
+	router := setupEncryptionTestRouter(t, keys)
+
+	req := createMockCaseRequest()
+	req.FiledByContactEmail = "jane@example.com"
+	body, _ := json.Marshal(req)
+	w := serveRetentionRequest(router, "POST", "/api/v6/cases", body)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var caseObj models.Case
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &caseObj))
+	assert.Equal(t, "jane@example.com", caseObj.FiledByContactEmail)
+
+	keys.PrimaryKeyID = "k2"
+	keys.Keys = map[string][]byte{"k2": k2, "k1": k1}
+	w = serveRetentionRequest(router, "POST", "/api/v6/admin/encryption/rotate", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var report models.KeyRotationReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, "k2", report.PrimaryKeyID)
+	assert.Equal(t, 3, report.CaseFields)
+	assert.Equal(t, 1, report.CardNumbers)
+
+	// With everything re-wrapped the old key can go
+	keys.Keys = map[string][]byte{"k2": k2}
+	w = serveRetentionRequest(router, "POST", "/api/v6/admin/encryption/rotate", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serveRetentionRequest(router, "GET", "/api/v6/cases/"+caseObj.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "jane@example.com")
+
+	keys.Keys = map[string][]byte{}
+	w = serveRetentionRequest(router, "POST", "/api/v6/admin/encryption/rotate", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "no encryption keys configured")
+
+	keys.Keys = nil
+	w = serveRetentionRequest(router, "POST", "/api/v6/admin/encryption/rotate", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to load encryption keys")
+}
+
+func TestRotateEncryptionKeys_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewEncryptionHandler(nil, nil, logger.NewDatadogLogger())
+	router.POST("/api/v6/admin/encryption/rotate", handler.RotateEncryptionKeys)
+
+	w := serveRetentionRequest(router, "POST", "/api/v6/admin/encryption/rotate", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
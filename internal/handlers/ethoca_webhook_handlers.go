@@ -24,12 +24,12 @@ const (
 )
 
 type EthocaWebhookHandler struct {
-	webhookService *services.EthocaWebhookService
+	webhookService services.WebhookProcessor
 	logger         *logger.DatadogLogger
 }
 
 // NewEthocaWebhookHandler creates a new webhook handler instance
-func NewEthocaWebhookHandler(webhookService *services.EthocaWebhookService, logger *logger.DatadogLogger) *EthocaWebhookHandler {
+func NewEthocaWebhookHandler(webhookService services.WebhookProcessor, logger *logger.DatadogLogger) *EthocaWebhookHandler {
 	return &EthocaWebhookHandler{
 		webhookService: webhookService,
 		logger:         logger,
@@ -41,11 +41,14 @@ func InitEthocaWebhookHandlers(logger *logger.DatadogLogger) {
 	// Initialize webhook configuration
 	config := config.LoadEthocaConfig()
 
-	webhookService := services.NewEthocaWebhookService(logger, config)
-	ethocaWebhookHandler = NewEthocaWebhookHandler(webhookService, logger)
+	ethocaWebhookService = services.NewEthocaWebhookService(logger, config)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(ethocaWebhookService, logger)
 }
 
-var ethocaWebhookHandler *EthocaWebhookHandler
+var (
+	ethocaWebhookService *services.EthocaWebhookService
+	ethocaWebhookHandler *EthocaWebhookHandler
+)
 
 // HandleEthocaWebhook processes incoming Ethoca webhook requests
 func HandleEthocaWebhook(c *gin.Context) {
@@ -182,8 +185,20 @@ func GetWebhookHealth(c *gin.Context) {
 	})
 }
 
-// GetWebhookStats returns statistics about webhook processing
+// GetWebhookStats returns statistics about webhook processing. Webhooks are received for
+// the Ethoca ICA, so callers acting for other ICAs are answered with 404.
 func GetWebhookStats(c *gin.Context) {
+	if scope := requestScope(c); !scope.Unrestricted() {
+		if ethocaWebhookHandler == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+			return
+		}
+		if !scope.Permits(ethocaWebhookHandler.webhookService.GetWebhookConfig().ICA) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook stats not found"})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
 		"stats": gin.H{
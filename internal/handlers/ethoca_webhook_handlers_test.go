@@ -9,12 +9,11 @@ import (
 	"testing"
 
 	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 // MockEthocaWebhookService is a mock implementation of the webhook service
@@ -38,45 +37,13 @@ func (m *MockEthocaWebhookService) GetWebhookConfig() *models.WebhookConfig {
 	return args.Get(0).(*models.WebhookConfig)
 }
 
-// MockDatadogLogger is a mock implementation of the DatadogLogger
-type MockDatadogLogger struct {
-	*logrus.Logger
-	mock.Mock
-}
-
-func NewMockDatadogLogger() *MockDatadogLogger {
-	return &MockDatadogLogger{
-		Logger: logrus.New(),
-	}
-}
-
-func (m *MockDatadogLogger) InfoWithSpan(span tracer.Span, message string, fields logrus.Fields) {
-	m.Called(span, message, fields)
-}
-
-func (m *MockDatadogLogger) ErrorWithSpan(span tracer.Span, message string, fields logrus.Fields) {
-	m.Called(span, message, fields)
-}
-
-func (m *MockDatadogLogger) SetLevel(level logrus.Level) {
-	m.Called(level)
-}
-
-func (m *MockDatadogLogger) Info(msg string, fields logrus.Fields) {
-	m.Called(msg, fields)
-}
-
-func (m *MockDatadogLogger) Error(msg string, fields logrus.Fields) {
-	m.Called(msg, fields)
-}
-
-func setupTestHandler() (*EthocaWebhookHandler, *MockEthocaWebhookService, *MockDatadogLogger) {
+func setupTestHandler() (*EthocaWebhookHandler, *MockEthocaWebhookService, *logger.DatadogLogger) {
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
-	handler := NewEthocaWebhookHandler(mockService, mockLogger)
+	handler := NewEthocaWebhookHandler(mockService, testLogger)
 
-	return handler, mockService, mockLogger
+	return handler, mockService, testLogger
 }
 
 func setupGinContext() (*gin.Context, *httptest.ResponseRecorder) {
@@ -88,22 +55,22 @@ func setupGinContext() (*gin.Context, *httptest.ResponseRecorder) {
 
 func TestNewEthocaWebhookHandler(t *testing.T) {
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
-	handler := NewEthocaWebhookHandler(mockService, mockLogger)
+	handler := NewEthocaWebhookHandler(mockService, testLogger)
 
 	assert.NotNil(t, handler)
 	assert.Equal(t, mockService, handler.webhookService)
-	assert.Equal(t, mockLogger, handler.logger)
+	assert.Equal(t, testLogger, handler.logger)
 }
 
 func TestHandleEthocaWebhook_Success(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
@@ -135,11 +102,6 @@ func TestHandleEthocaWebhook_Success(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v6/webhooks/ethoca", bytes.NewBuffer(payload))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	// Set up mock expectations
-	mockLogger.On("InfoWithSpan", mock.Anything, "Received Ethoca webhook request", mock.Anything).Return()
-	mockLogger.On("InfoWithSpan", mock.Anything, "Webhook payload validated successfully", mock.Anything).Return()
-	mockLogger.On("InfoWithSpan", mock.Anything, "Webhook processed successfully", mock.Anything).Return()
-
 	acknowledgment := &models.OutcomeAcknowledgement{
 		OutcomeResponses: []models.StatusUpdate{
 			{
@@ -165,26 +127,21 @@ func TestHandleEthocaWebhook_Success(t *testing.T) {
 	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
 
 	mockService.AssertExpectations(t)
-	mockLogger.AssertExpectations(t)
 }
 
 func TestHandleEthocaWebhook_InvalidMethod(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
 	// Set up GET request (invalid method)
 	c.Request = httptest.NewRequest("GET", "/api/v6/webhooks/ethoca", nil)
 
-	// Set up mock expectations
-	mockLogger.On("InfoWithSpan", mock.Anything, "Received Ethoca webhook request", mock.Anything).Return()
-	mockLogger.On("ErrorWithSpan", mock.Anything, "Invalid HTTP method for webhook", mock.Anything).Return()
-
 	// Call the handler function
 	HandleEthocaWebhook(c)
 
@@ -198,16 +155,15 @@ func TestHandleEthocaWebhook_InvalidMethod(t *testing.T) {
 	assert.Equal(t, "Method not allowed", response["error"])
 	assert.Equal(t, "METHOD_NOT_ALLOWED", response["code"])
 
-	mockLogger.AssertExpectations(t)
 }
 
 func TestHandleEthocaWebhook_InvalidContentType(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
@@ -215,10 +171,6 @@ func TestHandleEthocaWebhook_InvalidContentType(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v6/webhooks/ethoca", nil)
 	c.Request.Header.Set("Content-Type", "text/plain")
 
-	// Set up mock expectations
-	mockLogger.On("InfoWithSpan", mock.Anything, "Received Ethoca webhook request", mock.Anything).Return()
-	mockLogger.On("ErrorWithSpan", mock.Anything, "Invalid content type for webhook", mock.Anything).Return()
-
 	// Call the handler function
 	HandleEthocaWebhook(c)
 
@@ -232,16 +184,15 @@ func TestHandleEthocaWebhook_InvalidContentType(t *testing.T) {
 	assert.Equal(t, "Invalid content type. Expected application/json", response["error"])
 	assert.Equal(t, "INVALID_CONTENT_TYPE", response["code"])
 
-	mockLogger.AssertExpectations(t)
 }
 
 func TestHandleEthocaWebhook_InvalidJSON(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
@@ -249,10 +200,6 @@ func TestHandleEthocaWebhook_InvalidJSON(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v6/webhooks/ethoca", bytes.NewBufferString("invalid json"))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	// Set up mock expectations
-	mockLogger.On("InfoWithSpan", mock.Anything, "Received Ethoca webhook request", mock.Anything).Return()
-	mockLogger.On("ErrorWithSpan", mock.Anything, "Failed to parse webhook payload", mock.Anything).Return()
-
 	// Call the handler function
 	HandleEthocaWebhook(c)
 
@@ -266,16 +213,15 @@ func TestHandleEthocaWebhook_InvalidJSON(t *testing.T) {
 	assert.Equal(t, "Invalid JSON payload", response["error"])
 	assert.Equal(t, "INVALID_JSON", response["code"])
 
-	mockLogger.AssertExpectations(t)
 }
 
 func TestHandleEthocaWebhook_ServiceError(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
@@ -307,11 +253,6 @@ func TestHandleEthocaWebhook_ServiceError(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v6/webhooks/ethoca", bytes.NewBuffer(payload))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	// Set up mock expectations
-	mockLogger.On("InfoWithSpan", mock.Anything, "Received Ethoca webhook request", mock.Anything).Return()
-	mockLogger.On("InfoWithSpan", mock.Anything, "Webhook payload validated successfully", mock.Anything).Return()
-	mockLogger.On("ErrorWithSpan", mock.Anything, "Webhook processing failed", mock.Anything).Return()
-
 	mockService.On("ProcessWebhook", mock.Anything, &webhook).Return(nil, assert.AnError)
 
 	// Call the handler function
@@ -328,16 +269,15 @@ func TestHandleEthocaWebhook_ServiceError(t *testing.T) {
 	assert.Equal(t, "PROCESSING_ERROR", response["code"])
 
 	mockService.AssertExpectations(t)
-	mockLogger.AssertExpectations(t)
 }
 
 func TestGetWebhookHealth(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
@@ -376,10 +316,10 @@ func TestGetWebhookHealth(t *testing.T) {
 func TestGetWebhookStats(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
@@ -402,16 +342,16 @@ func TestGetWebhookStats(t *testing.T) {
 	assert.Equal(t, float64(0), stats["successfulWebhooks"])
 	assert.Equal(t, float64(0), stats["failedWebhooks"])
 	assert.Equal(t, "0ms", stats["averageProcessingTime"])
-	assert.NotNil(t, stats["lastProcessedAt"])
+	assert.Contains(t, stats, "lastProcessedAt")
 }
 
 func TestHandleEthocaWebhook_EmptyOutcomes(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
@@ -426,10 +366,6 @@ func TestHandleEthocaWebhook_EmptyOutcomes(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v6/webhooks/ethoca", bytes.NewBuffer(payload))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	// Set up mock expectations
-	mockLogger.On("InfoWithSpan", mock.Anything, "Received Ethoca webhook request", mock.Anything).Return()
-	mockLogger.On("ErrorWithSpan", mock.Anything, "Webhook payload contains no outcomes", mock.Anything).Return()
-
 	// Call the handler function
 	HandleEthocaWebhook(c)
 
@@ -443,16 +379,15 @@ func TestHandleEthocaWebhook_EmptyOutcomes(t *testing.T) {
 	assert.Equal(t, "No outcomes provided in webhook payload", response["error"])
 	assert.Equal(t, "NO_OUTCOMES", response["code"])
 
-	mockLogger.AssertExpectations(t)
 }
 
 func TestHandleEthocaWebhook_RequestIDGeneration(t *testing.T) {
 	// Set up the global handler for testing
 	mockService := &MockEthocaWebhookService{}
-	mockLogger := NewMockDatadogLogger()
+	testLogger := logger.NewDatadogLogger()
 
 	// Set the global handler
-	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, mockLogger)
+	ethocaWebhookHandler = NewEthocaWebhookHandler(mockService, testLogger)
 
 	c, w := setupGinContext()
 
@@ -484,11 +419,6 @@ func TestHandleEthocaWebhook_RequestIDGeneration(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v6/webhooks/ethoca", bytes.NewBuffer(payload))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	// Set up mock expectations
-	mockLogger.On("InfoWithSpan", mock.Anything, "Received Ethoca webhook request", mock.Anything).Return()
-	mockLogger.On("InfoWithSpan", mock.Anything, "Webhook payload validated successfully", mock.Anything).Return()
-	mockLogger.On("InfoWithSpan", mock.Anything, "Webhook processed successfully", mock.Anything).Return()
-
 	acknowledgment := &models.OutcomeAcknowledgement{
 		OutcomeResponses: []models.StatusUpdate{
 			{
@@ -512,5 +442,4 @@ func TestHandleEthocaWebhook_RequestIDGeneration(t *testing.T) {
 	assert.Len(t, requestID, 36) // UUID length
 
 	mockService.AssertExpectations(t)
-	mockLogger.AssertExpectations(t)
 }
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/resilience"
+)
+
+// InitPartnerPolicies sets where the outbound partner clients report metrics and circuit state
+// changes. It must be called before InitSubmissionWorker and InitQueueSyncWorker.
+func InitPartnerPolicies(metrics resilience.Metrics, logger *logger.DatadogLogger) {
+	services.ConfigurePartnerPolicies(metrics, logger)
+}
+
+// PartnerStatus returns the resilience state of every partner with a client for the health
+// endpoint, and whether any partner's circuit is not closed
+func PartnerStatus() ([]resilience.Stats, bool) {
+	stats := services.PartnerStatus()
+	degraded := false
+	for _, partner := range stats {
+		if partner.State != resilience.StateClosed {
+			degraded = true
+		}
+	}
+	return stats, degraded
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"mastercom-service/pkg/middleware"
+)
+
+// routePermissions gives the least role permitted each route, keyed by method and route path.
+// Routes missing from it need the admin role. Some operations check further roles of their
+// own, from configuration: detokenizing card numbers, retrieving unredacted documents and
+// managing API keys.
+var routePermissions = map[string]string{
+	// Cases
+	"POST /api/v6/cases":                      middleware.RoleAnalyst,
+	"GET /api/v6/cases":                       middleware.RoleViewer,
+	"GET /api/v6/cases/:id":                   middleware.RoleViewer,
+	"PUT /api/v6/cases/:id":                   middleware.RoleAnalyst,
+	"DELETE /api/v6/cases/:id":                middleware.RoleSupervisor,
+	"POST /api/v6/cases/from-transaction":     middleware.RoleAnalyst,
+	"PUT /api/v6/cases/status":                middleware.RoleViewer,
+	"PUT /api/v6/cases/imagestatus":           middleware.RoleViewer,
+	"POST /api/v6/cases/:id/acknowledge":      middleware.RoleAnalyst,
+	"POST /api/v6/cases/:id/reject":           middleware.RoleAnalyst,
+	"POST /api/v6/cases/:id/submit":           middleware.RoleAnalyst,
+	"POST /api/v6/cases/:id/close":            middleware.RoleSupervisor,
+	"POST /api/v6/cases/:id/documents":        middleware.RoleAnalyst,
+	"GET /api/v6/cases/:id/documents":         middleware.RoleViewer,
+	"POST /api/v6/cases/:id/documents/bundle": middleware.RoleAnalyst,
+	"GET /api/v6/cases/:id/export":            middleware.RoleSupervisor,
+	"PUT /api/v6/cases/:id/legal-hold":        middleware.RoleSupervisor,
+	"DELETE /api/v6/cases/:id/legal-hold":     middleware.RoleSupervisor,
+	"POST /api/v6/cases/:id/pan/detokenize":   middleware.RoleViewer,
+
+	// Queues, claims, transactions and reconciliation reports
+	"GET /api/v6/queues":                                         middleware.RoleViewer,
+	"POST /api/v6/queues":                                        middleware.RoleViewer,
+	"GET /api/v6/queues/names":                                   middleware.RoleViewer,
+	"GET /api/v6/queues/counts":                                  middleware.RoleViewer,
+	"GET /api/v6/claims/:claimId":                                middleware.RoleViewer,
+	"GET /api/v6/claims/:claimId/transactions/clearing/:id":      middleware.RoleViewer,
+	"GET /api/v6/claims/:claimId/transactions/authorization/:id": middleware.RoleViewer,
+	"POST /api/v6/transactions/search":                           middleware.RoleViewer,
+	"POST /api/v6/reconreport/data/request":                      middleware.RoleAnalyst,
+	"POST /api/v6/reconreport/data/retrieval/:reportIdentifier":  middleware.RoleViewer,
+	"GET /api/v6/reconreport/data/retrieval/:reportIdentifier":   middleware.RoleViewer,
+
+	// Documents and resumable uploads
+	"POST /api/v6/documents":                      middleware.RoleAnalyst,
+	"GET /api/v6/documents/:id":                   middleware.RoleViewer,
+	"DELETE /api/v6/documents/:id":                middleware.RoleSupervisor,
+	"POST /api/v6/documents/:id/restore":          middleware.RoleSupervisor,
+	"PUT /api/v6/documents/:id/content":           middleware.RoleAnalyst,
+	"GET /api/v6/documents/:id/versions":          middleware.RoleViewer,
+	"GET /api/v6/documents/:id/versions/:version": middleware.RoleViewer,
+	"POST /api/v6/uploads":                        middleware.RoleAnalyst,
+	"HEAD /api/v6/uploads/:id":                    middleware.RoleAnalyst,
+	"PATCH /api/v6/uploads/:id":                   middleware.RoleAnalyst,
+	"DELETE /api/v6/uploads/:id":                  middleware.RoleAnalyst,
+	"POST /api/v6/uploads/:id/finalize":           middleware.RoleAnalyst,
+
+	// Retention, administration and webhooks
+	"GET /api/v6/retention/policies":    middleware.RoleViewer,
+	"POST /api/v6/retention/purge":      middleware.RoleAdmin,
+	"POST /api/v6/admin/api-keys":       middleware.RoleAdmin,
+	"GET /api/v6/admin/api-keys":        middleware.RoleAdmin,
+	"DELETE /api/v6/admin/api-keys/:id": middleware.RoleAdmin,
+	"POST /api/v6/webhooks/ethoca":      middleware.RoleAnalyst,
+	"GET /api/v6/webhooks/ethoca/stats": middleware.RoleViewer,
+}
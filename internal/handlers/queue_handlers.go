@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type QueueHandler struct {
+	queueService *services.QueueService
+	validator    *validator.Validate
+	logger       *logger.DatadogLogger
+}
+
+func NewQueueHandler(queueService *services.QueueService, logger *logger.DatadogLogger) *QueueHandler {
+	return &QueueHandler{
+		queueService: queueService,
+		validator:    validator.New(),
+		logger:       logger,
+	}
+}
+
+// GetQueueNames handles listing the available queues
+func (h *QueueHandler) GetQueueNames(c *gin.Context) {
+	span := tracer.StartSpan("queue.names", tracer.ResourceName("GetQueueNames"))
+	defer span.Finish()
+
+	queues := h.queueService.GetQueueNames()
+
+	h.logger.InfoWithSpan(span, "Queue names retrieved successfully", logrus.Fields{
+		"count": len(queues),
+	})
+
+	c.JSON(http.StatusOK, queues)
+}
+
+// GetQueue handles listing every item in a queue
+func (h *QueueHandler) GetQueue(c *gin.Context) {
+	queueName := c.Query("queue-name")
+	if queueName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Queue name is required"})
+		return
+	}
+
+	span := tracer.StartSpan("queue.get", tracer.ResourceName("GetQueue"))
+	defer span.Finish()
+
+	span.SetTag("queue.name", queueName)
+
+	summaries, err := h.queueService.GetQueue(queueName, requestScope(c))
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get queue", logrus.Fields{
+			"queueName": queueName,
+			"error":     err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Queue not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Queue retrieved successfully", logrus.Fields{
+		"queueName": queueName,
+		"count":     len(summaries),
+	})
+
+	span.SetTag("queue.count", len(summaries))
+	c.JSON(http.StatusOK, summaries)
+}
+
+// GetQueueContent handles listing a page of queue items within a date interval
+func (h *QueueHandler) GetQueueContent(c *gin.Context) {
+	span := tracer.StartSpan("queue.content", tracer.ResourceName("GetQueueContent"))
+	defer span.Finish()
+
+	var req models.QueueContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to bind JSON request", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.ErrorWithSpan(span, "Validation failed", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Validation failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	span.SetTag("queue.name", req.QueueName)
+
+	content, err := h.queueService.GetQueueContent(&req, requestScope(c))
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get queue content", logrus.Fields{
+			"queueName": req.QueueName,
+			"error":     err.Error(),
+		})
+		span.SetTag("error", true)
+		if errors.Is(err, services.ErrQueueNotFound) {
+			span.SetTag("error.message", "Queue not found")
+			c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+			return
+		}
+		span.SetTag("error.message", "Invalid queue request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid queue request", "details": err.Error()})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Queue content retrieved successfully", logrus.Fields{
+		"queueName": req.QueueName,
+		"pageCount": content.PageCount,
+		"count":     len(content.ClaimList),
+	})
+
+	c.JSON(http.StatusOK, content)
+}
+
+// GetQueueCounts handles reporting the number of items in every queue
+func (h *QueueHandler) GetQueueCounts(c *gin.Context) {
+	span := tracer.StartSpan("queue.counts", tracer.ResourceName("GetQueueCounts"))
+	defer span.Finish()
+
+	counts := h.queueService.GetQueueCounts(requestScope(c))
+
+	h.logger.InfoWithSpan(span, "Queue counts retrieved successfully", nil)
+
+	c.JSON(http.StatusOK, counts)
+}
+
+// Global handler functions for compatibility with main.go
+var queueHandler *QueueHandler
+
+// InitQueueHandlers initializes the queue service and handlers.
+// It must be called after InitHandlers so queues reflect the shared case service.
+func InitQueueHandlers(logger *logger.DatadogLogger) {
+	queueService := services.NewQueueService(caseService, logger)
+	queueHandler = NewQueueHandler(queueService, logger)
+}
+
+func GetQueueNames(c *gin.Context) {
+	if queueHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	queueHandler.GetQueueNames(c)
+}
+
+func GetQueue(c *gin.Context) {
+	if queueHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	queueHandler.GetQueue(c)
+}
+
+func GetQueueContent(c *gin.Context) {
+	if queueHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	queueHandler.GetQueueContent(c)
+}
+
+func GetQueueCounts(c *gin.Context) {
+	if queueHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	queueHandler.GetQueueCounts(c)
+}
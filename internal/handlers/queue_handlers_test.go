@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupQueueTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// Initialize services and handlers
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	caseHandler := NewCaseHandler(caseService, logger)
+	queueHandler := NewQueueHandler(services.NewQueueService(caseService, logger), logger)
+
+	// Setup routes
+	api := router.Group("/api/v6")
+	cases := api.Group("/cases")
+	{
+		cases.POST("", caseHandler.CreateCase)
+		cases.POST("/:id/acknowledge", caseHandler.AcknowledgeCase)
+		cases.POST("/:id/reject", caseHandler.RejectCase)
+		cases.POST("/:id/submit", caseHandler.SubmitCase)
+		cases.POST("/:id/close", caseHandler.CloseCase)
+	}
+	queues := api.Group("/queues")
+	{
+		queues.GET("", queueHandler.GetQueue)
+		queues.POST("", queueHandler.GetQueueContent)
+		queues.GET("/names", queueHandler.GetQueueNames)
+		queues.GET("/counts", queueHandler.GetQueueCounts)
+	}
+
+	return router
+}
+
+func createTestCase(t *testing.T, router *gin.Engine) models.Case {
+	reqBody, _ := json.Marshal(createMockCaseRequest())
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/cases", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var caseObj models.Case
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &caseObj))
+	return caseObj
+}
+
+func TestGetQueueNames(t *testing.T) {
+	router := setupQueueTestRouter()
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/queues/names", nil)
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []models.Queue
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Len(t, response, len(models.Queues))
+	assert.Equal(t, models.QueueUnworked, response[0].QueueName)
+}
+
+func TestCaseQueueWorkflow(t *testing.T) {
+	router := setupQueueTestRouter()
+	caseObj := createTestCase(t, router)
+	assert.Equal(t, models.QueueUnworked, caseObj.QueueName)
+
+	// Acknowledge moves the case to Worked
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/cases/"+caseObj.ID+"/acknowledge", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Case
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.QueueWorked, response.QueueName)
+
+	// Acknowledging again is an invalid transition
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/api/v6/cases/"+caseObj.ID+"/acknowledge", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// The case is listed in the Worked queue
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/queues?queue-name=Worked", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var summaries []models.ClaimSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summaries))
+	require.Len(t, summaries, 1)
+	assert.Equal(t, caseObj.ID, summaries[0].ClaimID)
+
+	// Counts reflect the move
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/queues/counts", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var counts []models.QueueCount
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &counts))
+	for _, count := range counts {
+		if count.QueueName == models.QueueWorked {
+			assert.Equal(t, 1, count.Count)
+		} else {
+			assert.Equal(t, 0, count.Count)
+		}
+	}
+}
+
+func TestTransitionCase_NotFound(t *testing.T) {
+	router := setupQueueTestRouter()
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/cases/nonexistent-id/submit", nil)
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetQueue_Errors(t *testing.T) {
+	router := setupQueueTestRouter()
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/queues", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/queues?queue-name=Unknown", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetQueueContent(t *testing.T) {
+	router := setupQueueTestRouter()
+	createTestCase(t, router)
+
+	reqBody, _ := json.Marshal(models.QueueContentRequest{QueueName: models.QueueUnworked})
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/queues", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.QueueContentSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "1", response.PageCount)
+	assert.Len(t, response.ClaimList, 1)
+
+	// Only one side of the interval
+	reqBody, _ = json.Marshal(models.QueueContentRequest{QueueName: models.QueueUnworked, LastModifiedDateFrom: "2017-11-08T12:01"})
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/api/v6/queues", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type ReconReportHandler struct {
+	reconReportService *services.ReconReportService
+	validator          *validator.Validate
+	logger             *logger.DatadogLogger
+}
+
+func NewReconReportHandler(reconReportService *services.ReconReportService, logger *logger.DatadogLogger) *ReconReportHandler {
+	return &ReconReportHandler{
+		reconReportService: reconReportService,
+		validator:          validator.New(),
+		logger:             logger,
+	}
+}
+
+// RequestReconReport handles requesting generation of a reconciliation report
+func (h *ReconReportHandler) RequestReconReport(c *gin.Context) {
+	span := tracer.StartSpan("reconreport.request", tracer.ResourceName("RequestReconReport"))
+	defer span.Finish()
+
+	var req models.ReconReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to bind JSON request", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.ErrorWithSpan(span, "Validation failed", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Validation failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	// Reports cover only the caller's ICAs, all of them unless some are named
+	if err := services.ScopeReconReportRequest(&req, requestScope(c)); err != nil {
+		h.logger.ErrorWithSpan(span, "ICA outside the caller's ICAs", logrus.Fields{"ica": req.ICA, "error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "ICA not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "ICA not found"})
+		return
+	}
+
+	report, err := h.reconReportService.RequestReport(&req)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to request recon report", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		if errors.Is(err, services.ErrInvalidReconReportRequest) {
+			span.SetTag("error.message", "Invalid recon report request")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recon report request", "details": err.Error()})
+			return
+		}
+		span.SetTag("error.message", "Failed to request recon report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request recon report"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Recon report requested successfully", logrus.Fields{
+		"reportId":  report.ID,
+		"startDate": req.StartDate,
+		"endDate":   req.EndDate,
+	})
+
+	span.SetTag("reconreport.id", report.ID)
+	c.JSON(http.StatusOK, models.ReconReportAcknowledgement{ReportIdentifier: report.ID})
+}
+
+// RetrieveReconReport handles polling for a report, returning the CSV report base64
+// encoded once it is available
+func (h *ReconReportHandler) RetrieveReconReport(c *gin.Context) {
+	reportID := c.Param("reportIdentifier")
+	if reportID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Report identifier is required"})
+		return
+	}
+
+	span := tracer.StartSpan("reconreport.retrieve", tracer.ResourceName("RetrieveReconReport"))
+	defer span.Finish()
+
+	span.SetTag("reconreport.id", reportID)
+
+	report, err := h.getReport(c, reportID)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get recon report", logrus.Fields{
+			"reportId": reportID,
+			"error":    err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Report not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	response := models.ReconReportRetrieval{Status: report.Status}
+	if report.Status == models.ReconReportAvailable {
+		data, err := services.RenderReconReportCSV(report.Records)
+		if err != nil {
+			h.logger.ErrorWithSpan(span, "Failed to render recon report", logrus.Fields{
+				"reportId": reportID,
+				"error":    err.Error(),
+			})
+			span.SetTag("error", true)
+			span.SetTag("error.message", "Failed to render report")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render report"})
+			return
+		}
+		response.Data = base64.StdEncoding.EncodeToString(data)
+	}
+
+	h.logger.InfoWithSpan(span, "Recon report retrieved successfully", logrus.Fields{
+		"reportId": reportID,
+		"status":   report.Status,
+	})
+
+	span.SetTag("reconreport.status", report.Status)
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadReconReport handles downloading a finished report as JSON or CSV
+func (h *ReconReportHandler) DownloadReconReport(c *gin.Context) {
+	reportID := c.Param("reportIdentifier")
+	if reportID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Report identifier is required"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be json or csv"})
+		return
+	}
+
+	span := tracer.StartSpan("reconreport.download", tracer.ResourceName("DownloadReconReport"))
+	defer span.Finish()
+
+	span.SetTag("reconreport.id", reportID)
+	span.SetTag("reconreport.format", format)
+
+	report, err := h.getReport(c, reportID)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get recon report", logrus.Fields{
+			"reportId": reportID,
+			"error":    err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Report not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	switch report.Status {
+	case models.ReconReportUnavailable:
+		c.JSON(http.StatusAccepted, gin.H{"status": report.Status})
+		return
+	case models.ReconReportFailed:
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Report generation failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"status": report.Status, "error": report.Error})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, report.Masked())
+		return
+	}
+
+	data, err := services.RenderReconReportCSV(report.Records)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to render recon report", logrus.Fields{
+			"reportId": reportID,
+			"error":    err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Failed to render report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render report"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Recon report downloaded successfully", logrus.Fields{
+		"reportId":    reportID,
+		"recordCount": len(report.Records),
+	})
+
+	c.Header("Content-Disposition", "attachment; filename=\"reconreport-"+report.ID+".csv\"")
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// getReport returns a report requested within the caller's ICAs
+func (h *ReconReportHandler) getReport(c *gin.Context, reportID string) (*models.ReconReport, error) {
+	if err := h.reconReportService.CheckReportScope(reportID, requestScope(c)); err != nil {
+		return nil, err
+	}
+	return h.reconReportService.GetReport(reportID)
+}
+
+// Global handler functions for compatibility with main.go
+var reconReportHandler *ReconReportHandler
+
+// InitReconReportHandlers initializes the recon report service and handlers.
+// It must be called after InitHandlers, InitEthocaWebhookHandlers and InitClaimHandlers
+// so reports aggregate the shared case, Ethoca refund, chargeback and fee activity.
+func InitReconReportHandlers(logger *logger.DatadogLogger) {
+	sources := []services.ReconActivitySource{caseService}
+	if ethocaWebhookService != nil {
+		sources = append(sources, ethocaWebhookService)
+	}
+	if claimService != nil {
+		sources = append(sources, claimService, services.NewClaimFeeSource(claimService))
+	}
+
+	reconReportService := services.NewReconReportService(logger, sources...)
+	reconReportHandler = NewReconReportHandler(reconReportService, logger)
+}
+
+func RequestReconReport(c *gin.Context) {
+	if reconReportHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	reconReportHandler.RequestReconReport(c)
+}
+
+func RetrieveReconReport(c *gin.Context) {
+	if reconReportHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	reconReportHandler.RetrieveReconReport(c)
+}
+
+func DownloadReconReport(c *gin.Context) {
+	if reconReportHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	reconReportHandler.DownloadReconReport(c)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupReconReportTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// Initialize services and handlers
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	caseHandler := NewCaseHandler(caseService, logger)
+	reconReportHandler := NewReconReportHandler(services.NewReconReportService(logger, caseService), logger)
+
+	// Setup routes
+	api := router.Group("/api/v6")
+	api.POST("/cases", caseHandler.CreateCase)
+	reconReport := api.Group("/reconreport/data")
+	{
+		reconReport.POST("/request", reconReportHandler.RequestReconReport)
+		reconReport.POST("/retrieval/:reportIdentifier", reconReportHandler.RetrieveReconReport)
+		reconReport.GET("/retrieval/:reportIdentifier", reconReportHandler.DownloadReconReport)
+	}
+
+	return router
+}
+
+func requestReconReport(t *testing.T, router *gin.Engine, req models.ReconReportRequest) string {
+	reqBody, _ := json.Marshal(req)
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/reconreport/data/request", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var ack models.ReconReportAcknowledgement
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ack))
+	require.NotEmpty(t, ack.ReportIdentifier)
+	return ack.ReportIdentifier
+}
+
+func TestReconReportWorkflow(t *testing.T) {
+	router := setupReconReportTestRouter()
+	caseObj := createTestCase(t, router)
+
+	today := time.Now().Format("2006-01-02")
+	reportID := requestReconReport(t, router, models.ReconReportRequest{StartDate: today, EndDate: today})
+
+	// Poll until the report is available
+	var retrieval models.ReconReportRetrieval
+	require.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/api/v6/reconreport/data/retrieval/"+reportID, nil)
+		router.ServeHTTP(w, request)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &retrieval))
+		return retrieval.Status == models.ReconReportAvailable
+	}, time.Second, 10*time.Millisecond)
+
+	data, err := base64.StdEncoding.DecodeString(retrieval.Data)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), caseObj.ID)
+
+	// Download as JSON
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/reconreport/data/retrieval/"+reportID, nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var report models.ReconReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Records, 1)
+	assert.Equal(t, caseObj.ID, report.Records[0].ReferenceID)
+	assert.Equal(t, "411111******1111", report.Records[0].PrimaryAccountNumber)
+
+	// Download as CSV
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/reconreport/data/retrieval/"+reportID+"?format=csv", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), reportID)
+	assert.Len(t, strings.Split(strings.TrimSpace(w.Body.String()), "\n"), 2)
+}
+
+func TestRequestReconReport_Invalid(t *testing.T) {
+	router := setupReconReportTestRouter()
+
+	// Missing dates
+	reqBody, _ := json.Marshal(models.ReconReportRequest{})
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/reconreport/data/request", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// End date before start date
+	reqBody, _ = json.Marshal(models.ReconReportRequest{StartDate: "2024-02-01", EndDate: "2024-01-01"})
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/api/v6/reconreport/data/request", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRetrieveReconReport_NotFound(t *testing.T) {
+	router := setupReconReportTestRouter()
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/reconreport/data/retrieval/nonexistent-id", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/reconreport/data/retrieval/nonexistent-id", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/reconreport/data/retrieval/nonexistent-id?format=xml", nil)
+	router.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
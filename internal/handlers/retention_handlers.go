@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// RetentionHandler serves evidence retention: purges past the retention period and the legal
+// holds that stop them
+type RetentionHandler struct {
+	retentionService *services.RetentionService
+	caseService      *services.CaseService
+	logger           *logger.DatadogLogger
+}
+
+func NewRetentionHandler(retentionService *services.RetentionService, caseService *services.CaseService, logger *logger.DatadogLogger) *RetentionHandler {
+	return &RetentionHandler{
+		retentionService: retentionService,
+		caseService:      caseService,
+		logger:           logger,
+	}
+}
+
+// GetRetentionPolicies handles listing the retention configuration in force
+func (h *RetentionHandler) GetRetentionPolicies(c *gin.Context) {
+	c.JSON(http.StatusOK, h.retentionService.Config())
+}
+
+// PurgeEvidence handles running a retention purge now. With ?dryRun=true it only reports
+// what would be purged.
+func (h *RetentionHandler) PurgeEvidence(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dryRun must be true or false"})
+		return
+	}
+
+	span := tracer.StartSpan("retention.purge", tracer.ResourceName("PurgeEvidence"))
+	defer span.Finish()
+
+	report := h.retentionService.Purge(dryRun)
+	span.SetTag("retention.dry_run", report.DryRun)
+	span.SetTag("retention.cases", len(report.Cases))
+	span.SetTag("retention.documents", len(report.Documents))
+
+	h.logger.InfoWithSpan(span, "Retention purge requested", logrus.Fields{
+		"dryRun":    report.DryRun,
+		"cases":     len(report.Cases),
+		"documents": len(report.Documents),
+		"held":      len(report.Held),
+		"subject":   principalSubject(c),
+	})
+
+	c.JSON(http.StatusOK, report)
+}
+
+// PlaceLegalHold handles placing a case on legal hold
+func (h *RetentionHandler) PlaceLegalHold(c *gin.Context) {
+	caseID := c.Param("id")
+
+	span := tracer.StartSpan("case.legal_hold.place", tracer.ResourceName("PlaceLegalHold"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+
+	var req models.LegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorWithSpan(span, "Invalid legal hold request", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if req.PlacedBy == "" {
+		req.PlacedBy = principalSubject(c)
+	}
+
+	caseObj, err := h.caseService.PlaceLegalHold(caseID, &models.LegalHold{Reason: req.Reason, PlacedBy: req.PlacedBy})
+	if err != nil {
+		h.respondLegalHoldError(c, span, caseID, "Failed to place legal hold", err)
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Legal hold placed", logrus.Fields{
+		"caseId":   caseID,
+		"placedBy": req.PlacedBy,
+	})
+	c.JSON(http.StatusOK, caseObj)
+}
+
+// ReleaseLegalHold handles lifting a case's legal hold
+func (h *RetentionHandler) ReleaseLegalHold(c *gin.Context) {
+	caseID := c.Param("id")
+
+	span := tracer.StartSpan("case.legal_hold.release", tracer.ResourceName("ReleaseLegalHold"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseID)
+
+	releasedBy := c.DefaultQuery("releasedBy", principalSubject(c))
+	caseObj, err := h.caseService.ReleaseLegalHold(caseID, releasedBy)
+	if err != nil {
+		h.respondLegalHoldError(c, span, caseID, "Failed to release legal hold", err)
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Legal hold released", logrus.Fields{
+		"caseId":     caseID,
+		"releasedBy": releasedBy,
+	})
+	c.JSON(http.StatusOK, caseObj)
+}
+
+func (h *RetentionHandler) respondLegalHoldError(c *gin.Context, span tracer.Span, caseID, message string, err error) {
+	h.logger.ErrorWithSpan(span, message, logrus.Fields{
+		"caseId": caseID,
+		"error":  err.Error(),
+	})
+	span.SetTag("error", true)
+	switch {
+	case errors.Is(err, services.ErrCaseNotFound):
+		span.SetTag("error.message", "Case not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+	case errors.Is(err, services.ErrCaseNotOnLegalHold):
+		span.SetTag("error.message", "Case is not on legal hold")
+		c.JSON(http.StatusConflict, gin.H{"error": "Case is not on legal hold"})
+	default:
+		span.SetTag("error.message", message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// principalSubject returns the subject of the caller's principal, if any
+func principalSubject(c *gin.Context) string {
+	if principal := middleware.PrincipalFrom(c); principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
+
+// Global handler functions for compatibility with main.go
+var (
+	retentionService *services.RetentionService
+	retentionHandler *RetentionHandler
+)
+
+// InitRetentionHandlers creates the retention service over the shared case and document stores.
+// It must be called after InitHandlers and InitDocumentHandlers.
+func InitRetentionHandlers(logger *logger.DatadogLogger) {
+	retentionService = services.NewRetentionService(caseService, documentService, config.LoadRetentionConfig(), logger)
+	retentionHandler = NewRetentionHandler(retentionService, caseService, logger)
+}
+
+// InitRetentionWorker creates the worker that purges evidence past its retention period. It
+// returns nil unless scheduled purges are enabled. It must be called after InitRetentionHandlers.
+func InitRetentionWorker(logger *logger.DatadogLogger) *services.RetentionWorker {
+	if retentionService == nil {
+		return nil
+	}
+	retentionConfig := retentionService.Config()
+	if !retentionConfig.Enabled {
+		logger.Info("Scheduled retention purge disabled", nil)
+		return nil
+	}
+	return services.NewRetentionWorker(retentionService, retentionConfig, logger)
+}
+
+func GetRetentionPolicies(c *gin.Context) {
+	if retentionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	retentionHandler.GetRetentionPolicies(c)
+}
+
+func PurgeEvidence(c *gin.Context) {
+	if retentionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	retentionHandler.PurgeEvidence(c)
+}
+
+func PlaceLegalHold(c *gin.Context) {
+	if retentionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	retentionHandler.PlaceLegalHold(c)
+}
+
+func ReleaseLegalHold(c *gin.Context) {
+	if retentionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	retentionHandler.ReleaseLegalHold(c)
+}
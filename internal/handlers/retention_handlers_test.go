@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRetentionTestRouter(config *models.RetentionConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	documentService := services.NewDocumentService(logger)
+	caseHandler := NewCaseHandler(caseService, logger)
+	caseDocumentHandler := NewCaseDocumentHandler(services.NewCaseDocumentService(caseService, documentService, logger), logger)
+	retentionHandler := NewRetentionHandler(services.NewRetentionService(caseService, documentService, config, logger), caseService, logger)
+
+	api := router.Group("/api/v6")
+	cases := api.Group("/cases")
+	{
+		cases.POST("", caseHandler.CreateCase)
+		cases.DELETE("/:id", caseDocumentHandler.DeleteCase)
+		cases.POST("/:id/close", caseHandler.CloseCase)
+		cases.PUT("/:id/legal-hold", retentionHandler.PlaceLegalHold)
+		cases.DELETE("/:id/legal-hold", retentionHandler.ReleaseLegalHold)
+	}
+	retention := api.Group("/retention")
+	{
+		retention.GET("/policies", retentionHandler.GetRetentionPolicies)
+		retention.POST("/purge", retentionHandler.PurgeEvidence)
+	}
+
+	return router
+}
+
+func serveRetentionRequest(router *gin.Engine, method, path string, body []byte) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest(method, path, bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	return w
+}
+
+func TestLegalHold(t *testing.T) {
+	router := setupRetentionTestRouter(&models.RetentionConfig{})
+	caseObj := createTestCase(t, router)
+	path := "/api/v6/cases/" + caseObj.ID + "/legal-hold"
+
+	w := serveRetentionRequest(router, "PUT", path, []byte(`{}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveRetentionRequest(router, "PUT", path, []byte(`{"reason":"Litigation","placedBy":"counsel"}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	var held models.Case
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &held))
+	require.NotNil(t, held.LegalHold)
+	assert.Equal(t, "Litigation", held.LegalHold.Reason)
+	assert.Equal(t, "counsel", held.LegalHold.PlacedBy)
+
+	// A held case cannot be deleted
+	w = serveRetentionRequest(router, "DELETE", "/api/v6/cases/"+caseObj.ID, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "legal hold")
+
+	w = serveRetentionRequest(router, "DELETE", path+"?releasedBy=counsel", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var released models.Case
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &released))
+	assert.Nil(t, released.LegalHold)
+
+	w = serveRetentionRequest(router, "DELETE", path, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = serveRetentionRequest(router, "PUT", "/api/v6/cases/unknown/legal-hold", []byte(`{"reason":"Litigation"}`))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveRetentionRequest(router, "DELETE", "/api/v6/cases/"+caseObj.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPurgeEvidence(t *testing.T) {
+	config := &models.RetentionConfig{Policies: []models.RetentionPolicy{
+		{CaseType: models.RetentionAny, Queue: models.QueueClosed, DocumentDays: 90, CaseDays: 365},
+	}}
+	router := setupRetentionTestRouter(config)
+	caseObj := createTestCase(t, router)
+	w := serveRetentionRequest(router, "POST", "/api/v6/cases/"+caseObj.ID+"/close", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = serveRetentionRequest(router, "GET", "/api/v6/retention/policies", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var policies models.RetentionConfig
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &policies))
+	assert.Equal(t, config.Policies, policies.Policies)
+
+	w = serveRetentionRequest(router, "POST", "/api/v6/retention/purge?dryRun=maybe", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// A case closed just now is within its retention period
+	w = serveRetentionRequest(router, "POST", "/api/v6/retention/purge?dryRun=true", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var report models.PurgeReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Empty(t, report.Cases)
+	assert.Empty(t, report.Documents)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"errors"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InitSubmissionWorker creates the case submission worker over the shared case and document
+// services. It returns nil when the worker is disabled or Mastercom credentials are not
+// configured. It must be called after InitHandlers and InitDocumentHandlers.
+func InitSubmissionWorker(logger *logger.DatadogLogger) *services.SubmissionWorker {
+	submissionConfig := config.LoadSubmissionConfig()
+	if !submissionConfig.Enabled {
+		logger.Info("Case submission worker disabled", nil)
+		return nil
+	}
+
+	client, err := services.NewMastercomClient(config.LoadMastercomConfig())
+	if err != nil {
+		if errors.Is(err, services.ErrMastercomNotConfigured) {
+			logger.Info("Case submission worker not started: Mastercom credentials not configured", nil)
+		} else {
+			logger.Error("Case submission worker not started", logrus.Fields{"error": err.Error()})
+		}
+		return nil
+	}
+
+	return services.NewSubmissionWorker(caseService, documentService, client, submissionConfig, logger)
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type TransactionHandler struct {
+	transactionService *services.TransactionService
+	caseService        *services.CaseService
+	claimService       *services.ClaimService
+	validator          *validator.Validate
+	logger             *logger.DatadogLogger
+}
+
+func NewTransactionHandler(transactionService *services.TransactionService, caseService *services.CaseService, claimService *services.ClaimService, logger *logger.DatadogLogger) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+		caseService:        caseService,
+		claimService:       claimService,
+		validator:          newCaseValidator(),
+		logger:             logger,
+	}
+}
+
+// SearchTransactions handles searching for original transactions
+func (h *TransactionHandler) SearchTransactions(c *gin.Context) {
+	span := tracer.StartSpan("transaction.search", tracer.ResourceName("SearchTransactions"))
+	defer span.Finish()
+
+	var req models.TransactionSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to bind JSON request", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.ErrorWithSpan(span, "Validation failed", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Validation failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	summary, err := h.transactionService.SearchTransactions(&req, requestScope(c))
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to search transactions", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		if errors.Is(err, services.ErrInvalidTransactionSearch) {
+			span.SetTag("error.message", "Invalid search")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search", "details": err.Error()})
+			return
+		}
+		span.SetTag("error.message", "Failed to search transactions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search transactions"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Transactions searched successfully", logrus.Fields{
+		"count": summary.AuthorizationSummaryCount,
+	})
+
+	span.SetTag("transactions.count", summary.AuthorizationSummaryCount)
+	c.JSON(http.StatusOK, summary.Masked())
+}
+
+// GetClearingDetail handles retrieving clearing details for a claim's transaction
+func (h *TransactionHandler) GetClearingDetail(c *gin.Context) {
+	claimID := c.Param("claimId")
+	transactionID := c.Param("id")
+	if claimID == "" || transactionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Claim ID and transaction ID are required"})
+		return
+	}
+
+	span := tracer.StartSpan("transaction.clearing.get", tracer.ResourceName("GetClearingDetail"))
+	defer span.Finish()
+
+	span.SetTag("claim.id", claimID)
+	span.SetTag("transaction.id", transactionID)
+
+	if !h.checkClaimTransaction(c, span, claimID, transactionID) {
+		return
+	}
+
+	clearing, err := h.transactionService.GetClearingDetail(transactionID)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get clearing detail", logrus.Fields{
+			"claimId":       claimID,
+			"transactionId": transactionID,
+			"error":         err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Transaction not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Clearing detail retrieved successfully", logrus.Fields{
+		"claimId":       claimID,
+		"transactionId": transactionID,
+	})
+
+	c.JSON(http.StatusOK, clearing.Masked())
+}
+
+// GetAuthorizationDetail handles retrieving authorization details for a claim's transaction
+func (h *TransactionHandler) GetAuthorizationDetail(c *gin.Context) {
+	claimID := c.Param("claimId")
+	transactionID := c.Param("id")
+	if claimID == "" || transactionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Claim ID and transaction ID are required"})
+		return
+	}
+
+	span := tracer.StartSpan("transaction.authorization.get", tracer.ResourceName("GetAuthorizationDetail"))
+	defer span.Finish()
+
+	span.SetTag("claim.id", claimID)
+	span.SetTag("transaction.id", transactionID)
+
+	if !h.checkClaimTransaction(c, span, claimID, transactionID) {
+		return
+	}
+
+	authorization, err := h.transactionService.GetAuthorizationDetail(transactionID)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get authorization detail", logrus.Fields{
+			"claimId":       claimID,
+			"transactionId": transactionID,
+			"error":         err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Transaction not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Authorization detail retrieved successfully", logrus.Fields{
+		"claimId":       claimID,
+		"transactionId": transactionID,
+	})
+
+	c.JSON(http.StatusOK, authorization.Masked())
+}
+
+// checkClaimTransaction answers with 404 unless the claim exists within the caller's ICAs and
+// the transaction belongs to it
+func (h *TransactionHandler) checkClaimTransaction(c *gin.Context, span tracer.Span, claimID, transactionID string) bool {
+	claim, err := h.claimService.GetClaim(claimID)
+	if err == nil && !requestScope(c).PermitsClaim(claim) {
+		err = services.ErrClaimNotFound
+	}
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get claim", logrus.Fields{
+			"claimId": claimID,
+			"error":   err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Claim not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Claim not found"})
+		return false
+	}
+
+	owned, err := h.transactionService.ClaimHasTransaction(claim, transactionID)
+	if err != nil || !owned {
+		fields := logrus.Fields{"claimId": claimID, "transactionId": transactionID}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		h.logger.ErrorWithSpan(span, "Transaction does not belong to the claim", fields)
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Transaction not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return false
+	}
+	return true
+}
+
+// CreateCaseFromTransaction handles creating a case from a clearing record found by a search
+func (h *TransactionHandler) CreateCaseFromTransaction(c *gin.Context) {
+	span := tracer.StartSpan("case.create_from_transaction", tracer.ResourceName("CreateCaseFromTransaction"))
+	defer span.Finish()
+
+	var req models.CreateCaseFromTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to bind JSON request", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.ErrorWithSpan(span, "Validation failed", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Validation failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	span.SetTag("transaction.id", req.ClearingTransactionID)
+
+	clearing, err := h.transactionService.GetClearingDetail(req.ClearingTransactionID)
+	if err == nil {
+		err = h.transactionService.CheckTransactionScope(req.ClearingTransactionID, requestScope(c))
+	}
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to get clearing detail", logrus.Fields{
+			"transactionId": req.ClearingTransactionID,
+			"error":         err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Transaction not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	caseReq, err := models.NewCaseRequestFromClearing(&req, clearing)
+	if err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to copy transaction fields", logrus.Fields{
+			"transactionId": req.ClearingTransactionID,
+			"error":         err.Error(),
+		})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Invalid clearing record")
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid clearing record", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(caseReq); err != nil {
+		h.logger.ErrorWithSpan(span, "Validation failed", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Validation failed")
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	caseObj := models.NewCase(caseReq)
+	if err := services.CheckFilingICA(caseObj, requestScope(c)); err != nil {
+		h.logger.ErrorWithSpan(span, "Filing ICA outside the caller's ICAs", logrus.Fields{"filingIca": caseObj.FilingIca})
+		span.SetTag("error", true)
+		span.SetTag("error.message", "Filing ICA not permitted")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Filing ICA not permitted"})
+		return
+	}
+	if err := h.caseService.CreateCase(caseObj); err != nil {
+		h.logger.ErrorWithSpan(span, "Failed to create case", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		// The clearing record's card number is not one a case can be filed with
+		if errors.Is(err, services.ErrInvalidPAN) {
+			span.SetTag("error.message", "Invalid clearing record")
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid clearing record", "details": err.Error()})
+			return
+		}
+		span.SetTag("error.message", "Failed to create case")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create case"})
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Case created from transaction successfully", logrus.Fields{
+		"caseId":        caseObj.ID,
+		"caseType":      caseObj.CaseType,
+		"transactionId": req.ClearingTransactionID,
+	})
+
+	span.SetTag("case.id", caseObj.ID)
+	span.SetTag("case.type", caseObj.CaseType)
+	c.JSON(http.StatusCreated, caseObj)
+}
+
+// Global handler functions for compatibility with main.go
+var transactionHandler *TransactionHandler
+
+// InitTransactionHandlers initializes the transaction source and handlers.
+// It must be called after InitHandlers so cases are created in the shared case service,
+// and after InitClaimHandlers so claim transactions are checked against synchronised claims.
+func InitTransactionHandlers(logger *logger.DatadogLogger) {
+	config := config.LoadTransactionSourceConfig()
+
+	source, err := services.LoadLocalTransactionSource(config.FilePath)
+	if err != nil {
+		logger.Error("Failed to load transaction source, starting empty", logrus.Fields{
+			"file":  config.FilePath,
+			"error": err.Error(),
+		})
+		source = services.NewLocalTransactionSource(nil)
+	}
+
+	transactionService := services.NewTransactionService(source, config, logger)
+	transactionHandler = NewTransactionHandler(transactionService, caseService, claimService, logger)
+}
+
+func SearchTransactions(c *gin.Context) {
+	if transactionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	transactionHandler.SearchTransactions(c)
+}
+
+func GetClearingDetail(c *gin.Context) {
+	if transactionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	transactionHandler.GetClearingDetail(c)
+}
+
+func GetAuthorizationDetail(c *gin.Context) {
+	if transactionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	transactionHandler.GetAuthorizationDetail(c)
+}
+
+func CreateCaseFromTransaction(c *gin.Context) {
+	if transactionHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	transactionHandler.CreateCaseFromTransaction(c)
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTransactionTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// Initialize services and handlers
+	logger := logger.NewDatadogLogger()
+	transactionDate := time.Now().AddDate(0, 0, -5)
+	source := services.NewLocalTransactionSource([]models.TransactionRecord{
+		{
+			Authorization: models.AuthorizationDetail{
+				TransactionID:           "auth-1",
+				PrimaryAccountNumber:    "5555555555554444",
+				BanknetDate:             transactionDate.Format("060102"),
+				TransactionAmountLocal:  "000000010000",
+				TransactionCurrencyCode: "840",
+				Track1:                  "B5555555555554444^CARDHOLDER/TEST^2512101",
+				Track2:                  "5555555555554444=2512101",
+			},
+			Clearings: []models.ClearingDetail{
+				{
+					TransactionID:            "clearing-1",
+					PrimaryAccountNumber:     "5555555555554444",
+					AcquirerReferenceData:    "05413364365000000000667",
+					CardAcceptorName:         "Amazon",
+					CardAcceptorBusinessCode: "5411",
+					CentralSiteBusinessDate:  transactionDate.Format("060102"),
+					LocalTransactionDateTime: transactionDate.Format("060102") + "160100",
+					TransactionAmountLocal:   "2500",
+					TransactionCurrencyCode:  "840",
+				},
+			},
+		},
+		{
+			// Held with a card number already truncated, which a case cannot be filed with
+			Authorization: models.AuthorizationDetail{
+				TransactionID:        "auth-3",
+				PrimaryAccountNumber: "555555******4444",
+				BanknetDate:          transactionDate.Format("060102"),
+			},
+			Clearings: []models.ClearingDetail{
+				{
+					TransactionID:            "clearing-3",
+					PrimaryAccountNumber:     "555555******4444",
+					CentralSiteBusinessDate:  transactionDate.Format("060102"),
+					LocalTransactionDateTime: transactionDate.Format("060102") + "160100",
+					TransactionAmountLocal:   "2500",
+					TransactionCurrencyCode:  "840",
+				},
+			},
+		},
+	})
+	config := &models.TransactionSourceConfig{MaxSearchRangeDays: 30, MaxHistoryDays: 730}
+	transactionService := services.NewTransactionService(source, config, logger)
+	caseService := services.NewCaseService(logger)
+	claimService := services.NewClaimService(logger)
+	claimService.UpsertClaim(&models.Claim{ClaimID: "200002020654", TransactionID: "auth-1"})
+	claimService.UpsertClaim(&models.Claim{ClaimID: "200002020655", TransactionID: "auth-2"})
+	transactionHandler := NewTransactionHandler(transactionService, caseService, claimService, logger)
+	caseHandler := NewCaseHandler(caseService, logger)
+
+	// Setup routes
+	api := router.Group("/api/v6")
+	api.POST("/transactions/search", transactionHandler.SearchTransactions)
+	api.GET("/claims/:claimId/transactions/clearing/:id", transactionHandler.GetClearingDetail)
+	api.GET("/claims/:claimId/transactions/authorization/:id", transactionHandler.GetAuthorizationDetail)
+	api.POST("/cases/from-transaction", transactionHandler.CreateCaseFromTransaction)
+	api.GET("/cases/:id", caseHandler.GetCase)
+
+	return router
+}
+
+func TestSearchTransactions_Success(t *testing.T) {
+	router := setupTransactionTestRouter()
+
+	reqBody, _ := json.Marshal(models.TransactionSearchRequest{
+		PrimaryAccountNum: "5555555555554444",
+		TranStartDate:     time.Now().AddDate(0, 0, -10).Format("2006-01-02"),
+		TranEndDate:       time.Now().Format("2006-01-02"),
+	})
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/transactions/search", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TransactionSummary
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "1", response.AuthorizationSummaryCount)
+	require.Len(t, response.AuthorizationSummary, 1)
+	assert.Equal(t, "auth-1", response.AuthorizationSummary[0].TransactionID)
+
+	// Card numbers are truncated and track data is never returned
+	assert.Equal(t, "555555******4444", response.AuthorizationSummary[0].PrimaryAccountNumber)
+	require.Len(t, response.AuthorizationSummary[0].ClearingSummary, 1)
+	assert.Equal(t, "555555******4444", response.AuthorizationSummary[0].ClearingSummary[0].PrimaryAccountNumber)
+	assert.NotContains(t, w.Body.String(), "5555555555554444")
+	assert.NotContains(t, w.Body.String(), "track")
+}
+
+func TestSearchTransactions_InvalidSearch(t *testing.T) {
+	router := setupTransactionTestRouter()
+
+	// Neither PAN nor ARN provided
+	reqBody, _ := json.Marshal(models.TransactionSearchRequest{
+		TranStartDate: time.Now().AddDate(0, 0, -10).Format("2006-01-02"),
+		TranEndDate:   time.Now().Format("2006-01-02"),
+	})
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/transactions/search", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetTransactionDetails(t *testing.T) {
+	router := setupTransactionTestRouter()
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/v6/claims/200002020654/transactions/clearing/clearing-1", nil)
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var clearing models.ClearingDetail
+	err := json.Unmarshal(w.Body.Bytes(), &clearing)
+	require.NoError(t, err)
+	assert.Equal(t, "05413364365000000000667", clearing.AcquirerReferenceData)
+	assert.Equal(t, "555555******4444", clearing.PrimaryAccountNumber)
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/claims/200002020654/transactions/authorization/auth-1", nil)
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var authorization models.AuthorizationDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &authorization))
+	assert.Equal(t, "555555******4444", authorization.PrimaryAccountNumber)
+	assert.NotContains(t, w.Body.String(), "5555555555554444")
+	assert.NotContains(t, w.Body.String(), "track")
+
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/claims/200002020654/transactions/clearing/nonexistent-id", nil)
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetTransactionDetails_OtherClaim(t *testing.T) {
+	router := setupTransactionTestRouter()
+
+	// The transactions exist, but belong to another claim's original transaction
+	for _, path := range []string{
+		"/api/v6/claims/200002020655/transactions/clearing/clearing-1",
+		"/api/v6/claims/200002020655/transactions/authorization/auth-1",
+		"/api/v6/claims/unknown/transactions/clearing/clearing-1",
+	} {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, request)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
+
+func TestCreateCaseFromTransaction_Success(t *testing.T) {
+	router := setupTransactionTestRouter()
+
+	reqBody, _ := json.Marshal(models.CreateCaseFromTransactionRequest{
+		ClearingTransactionID: "clearing-1",
+		CaseType:              "PRE_ARBITRATION",
+		ReasonCode:            "10.1",
+		FilingAs:              "ISSUER",
+		FilingIca:             "123456",
+		FiledAgainstIca:       "654321",
+	})
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/cases/from-transaction", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.Case
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "555555******4444", response.PrimaryAccountNumber)
+	assert.Equal(t, 25.00, response.TransactionAmount)
+	assert.Equal(t, "USD", response.TransactionCurrency)
+	assert.Equal(t, "clearing-1", response.TransactionID)
+	assert.Equal(t, "Amazon", response.MerchantName)
+	assert.Equal(t, "5411", response.MerchantCategoryCode)
+	assert.Equal(t, 25.00, response.DisputeAmount)
+
+	// The case is stored in the shared case service
+	w = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/v6/cases/"+response.ID, nil)
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCreateCaseFromTransaction_InvalidPAN(t *testing.T) {
+	router := setupTransactionTestRouter()
+
+	reqBody, _ := json.Marshal(models.CreateCaseFromTransactionRequest{
+		ClearingTransactionID: "clearing-3",
+		CaseType:              "PRE_ARBITRATION",
+		ReasonCode:            "10.1",
+		FilingAs:              "ISSUER",
+		FilingIca:             "123456",
+		FiledAgainstIca:       "654321",
+	})
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/cases/from-transaction", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid clearing record")
+}
+
+func TestCreateCaseFromTransaction_NotFound(t *testing.T) {
+	router := setupTransactionTestRouter()
+
+	reqBody, _ := json.Marshal(models.CreateCaseFromTransactionRequest{
+		ClearingTransactionID: "nonexistent-id",
+		CaseType:              "PRE_ARBITRATION",
+		ReasonCode:            "10.1",
+		FilingAs:              "ISSUER",
+		FilingIca:             "123456",
+		FiledAgainstIca:       "654321",
+	})
+
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/v6/cases/from-transaction", bytes.NewBuffer(reqBody))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// tusVersion is the version of the tus resumable upload protocol the upload endpoints speak
+const tusVersion = "1.0.0"
+
+// statusChecksumMismatch is the tus status for a chunk that does not match its Upload-Checksum
+const statusChecksumMismatch = 460
+
+// UploadHandler serves resumable uploads following the tus protocol: an upload is created
+// with its length, its content is sent in PATCH requests at increasing offsets, HEAD reports
+// how much has arrived, and the complete upload is finalized against its checksum.
+type UploadHandler struct {
+	uploadService *services.UploadService
+	caseService   *services.CaseService
+	logger        *logger.DatadogLogger
+}
+
+func NewUploadHandler(uploadService *services.UploadService, caseService *services.CaseService, logger *logger.DatadogLogger) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+		caseService:   caseService,
+		logger:        logger,
+	}
+}
+
+// CreateUpload handles starting a resumable upload. The file length is given in Upload-Length
+// and the document fields in Upload-Metadata, as comma separated "key base64(value)" pairs
+// with the keys filename, caseId, uploadedBy and description.
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	span := tracer.StartSpan("upload.create", tracer.ResourceName("CreateUpload"))
+	defer span.Finish()
+
+	if !h.checkTusVersion(c, span) {
+		return
+	}
+
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil {
+		h.respondError(c, span, http.StatusBadRequest, "Upload-Length header is required", err)
+		return
+	}
+	metadata, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		h.respondError(c, span, http.StatusBadRequest, "Invalid Upload-Metadata header", err)
+		return
+	}
+
+	upload := &models.Upload{
+		CaseID:      metadata["caseId"],
+		FileName:    metadata["filename"],
+		UploadedBy:  metadata["uploadedBy"],
+		Description: metadata["description"],
+		Length:      length,
+	}
+	span.SetTag("upload.case_id", upload.CaseID)
+	span.SetTag("upload.length", length)
+
+	// Callers limited to some ICAs may only upload to cases of theirs; a missing caseId is
+	// refused by the upload service
+	if upload.CaseID != "" {
+		if err := h.caseService.CheckCaseScope(upload.CaseID, requestScope(c)); err != nil {
+			h.respondError(c, span, http.StatusNotFound, "Case not found", err)
+			return
+		}
+	}
+
+	if err := h.uploadService.CreateUpload(upload); err != nil {
+		switch {
+		case errors.Is(err, services.ErrUploadTooLarge):
+			h.respondError(c, span, http.StatusRequestEntityTooLarge, "Upload too large", err)
+		case errors.Is(err, services.ErrInvalidUpload):
+			h.respondError(c, span, http.StatusBadRequest, "Invalid upload", err)
+		default:
+			h.respondError(c, span, http.StatusInternalServerError, "Failed to create upload", err)
+		}
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Upload created successfully", logrus.Fields{
+		"uploadId": upload.ID,
+		"caseId":   upload.CaseID,
+		"length":   upload.Length,
+	})
+
+	span.SetTag("upload.id", upload.ID)
+	c.Header("Location", c.Request.URL.Path+"/"+upload.ID)
+	setUploadHeaders(c, upload)
+	c.JSON(http.StatusCreated, upload)
+}
+
+// GetUploadOffset handles querying how much of an upload has arrived
+func (h *UploadHandler) GetUploadOffset(c *gin.Context) {
+	uploadID := c.Param("id")
+
+	span := tracer.StartSpan("upload.head", tracer.ResourceName("GetUploadOffset"))
+	defer span.Finish()
+
+	span.SetTag("upload.id", uploadID)
+
+	upload, err := h.uploadService.GetUpload(uploadID)
+	if err == nil {
+		err = h.checkUploadScope(c, upload)
+	}
+	if err != nil {
+		c.Header("Tus-Resumable", tusVersion)
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+}
+
+// PatchUpload handles a chunk of an upload, sent as application/offset+octet-stream starting
+// at Upload-Offset. A chunk may carry an Upload-Checksum of "sha256 base64(digest)". When the
+// connection drops part way through a chunk without a checksum, the bytes that arrived are kept.
+func (h *UploadHandler) PatchUpload(c *gin.Context) {
+	uploadID := c.Param("id")
+
+	span := tracer.StartSpan("upload.patch", tracer.ResourceName("PatchUpload"))
+	defer span.Finish()
+
+	span.SetTag("upload.id", uploadID)
+
+	if !h.checkTusVersion(c, span) {
+		return
+	}
+	if c.ContentType() != "application/offset+octet-stream" {
+		h.respondError(c, span, http.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream", nil)
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil {
+		h.respondError(c, span, http.StatusBadRequest, "Upload-Offset header is required", err)
+		return
+	}
+	var checksum []byte
+	if header := c.GetHeader("Upload-Checksum"); header != "" {
+		if checksum, err = parseChunkChecksum(header); err != nil {
+			h.respondError(c, span, http.StatusBadRequest, "Invalid Upload-Checksum header", err)
+			return
+		}
+	}
+
+	upload, err := h.uploadService.GetUpload(uploadID)
+	if err == nil {
+		err = h.checkUploadScope(c, upload)
+	}
+	if err != nil {
+		h.respondUploadError(c, span, err)
+		return
+	}
+	// Only a chunk at the upload's offset is read, so the remaining length bounds the read
+	if offset != upload.Offset {
+		h.respondUploadError(c, span, fmt.Errorf("%w: upload is at offset %d, not %d", services.ErrUploadOffsetMismatch, upload.Offset, offset))
+		return
+	}
+
+	// Read one byte past the remaining length to catch chunks that overrun it
+	data, readErr := io.ReadAll(io.LimitReader(c.Request.Body, upload.Length-upload.Offset+1))
+	if readErr != nil && (checksum != nil || len(data) == 0) {
+		h.respondError(c, span, http.StatusBadRequest, "Failed to read chunk", readErr)
+		return
+	}
+	if checksum != nil {
+		if sum := sha256.Sum256(data); string(sum[:]) != string(checksum) {
+			h.respondError(c, span, statusChecksumMismatch, "Checksum mismatch", nil)
+			return
+		}
+	}
+	span.SetTag("upload.offset", offset)
+	span.SetTag("upload.chunk_size", len(data))
+
+	upload, err = h.uploadService.AppendChunk(uploadID, offset, data)
+	if err != nil {
+		h.respondUploadError(c, span, err)
+		return
+	}
+	if readErr != nil {
+		h.logger.InfoWithSpan(span, "Partial chunk kept after interrupted upload", logrus.Fields{
+			"uploadId": uploadID,
+			"offset":   upload.Offset,
+			"error":    readErr.Error(),
+		})
+	}
+
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusNoContent)
+}
+
+// FinalizeUpload handles turning a complete upload into a document, once its content matches
+// the SHA-256 checksum of the whole file
+func (h *UploadHandler) FinalizeUpload(c *gin.Context) {
+	uploadID := c.Param("id")
+
+	span := tracer.StartSpan("upload.finalize", tracer.ResourceName("FinalizeUpload"))
+	defer span.Finish()
+
+	span.SetTag("upload.id", uploadID)
+
+	var req models.FinalizeUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, span, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if err := h.checkUploadScopeByID(c, uploadID); err != nil {
+		h.respondUploadError(c, span, err)
+		return
+	}
+
+	document, err := h.uploadService.FinalizeUpload(uploadID, req.Checksum)
+	if err != nil {
+		h.respondUploadError(c, span, err)
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Upload finalized successfully", logrus.Fields{
+		"uploadId":   uploadID,
+		"documentId": document.ID,
+		"caseId":     document.CaseID,
+	})
+
+	span.SetTag("document.id", document.ID)
+	setDocumentETag(c, document)
+	c.JSON(http.StatusCreated, models.NewDocumentResponse(document))
+}
+
+// DeleteUpload handles abandoning an upload
+func (h *UploadHandler) DeleteUpload(c *gin.Context) {
+	uploadID := c.Param("id")
+
+	span := tracer.StartSpan("upload.delete", tracer.ResourceName("DeleteUpload"))
+	defer span.Finish()
+
+	span.SetTag("upload.id", uploadID)
+
+	if err := h.checkUploadScopeByID(c, uploadID); err != nil {
+		h.respondUploadError(c, span, err)
+		return
+	}
+	if err := h.uploadService.DeleteUpload(uploadID); err != nil {
+		h.respondUploadError(c, span, err)
+		return
+	}
+
+	h.logger.InfoWithSpan(span, "Upload deleted successfully", logrus.Fields{"uploadId": uploadID})
+	c.Header("Tus-Resumable", tusVersion)
+	c.Status(http.StatusNoContent)
+}
+
+// checkUploadScope returns ErrUploadNotFound for an upload whose case is outside the caller's
+// ICAs, so that callers cannot learn of other ICAs' uploads
+func (h *UploadHandler) checkUploadScope(c *gin.Context, upload *models.Upload) error {
+	scope := requestScope(c)
+	if scope.Unrestricted() {
+		return nil
+	}
+	if upload.CaseID == "" || h.caseService.CheckCaseScope(upload.CaseID, scope) != nil {
+		return services.ErrUploadNotFound
+	}
+	return nil
+}
+
+// checkUploadScopeByID looks up an upload and checks it with checkUploadScope
+func (h *UploadHandler) checkUploadScopeByID(c *gin.Context, uploadID string) error {
+	if requestScope(c).Unrestricted() {
+		return nil
+	}
+	upload, err := h.uploadService.GetUpload(uploadID)
+	if err != nil {
+		return err
+	}
+	return h.checkUploadScope(c, upload)
+}
+
+// checkTusVersion rejects requests from tus clients speaking another protocol version.
+// Requests without Tus-Resumable are accepted.
+func (h *UploadHandler) checkTusVersion(c *gin.Context, span tracer.Span) bool {
+	version := c.GetHeader("Tus-Resumable")
+	if version == "" || version == tusVersion {
+		return true
+	}
+	c.Header("Tus-Version", tusVersion)
+	h.respondError(c, span, http.StatusPreconditionFailed, "Unsupported tus version", nil)
+	return false
+}
+
+func (h *UploadHandler) respondUploadError(c *gin.Context, span tracer.Span, err error) {
+	switch {
+	case errors.Is(err, services.ErrUploadNotFound):
+		h.respondError(c, span, http.StatusNotFound, "Upload not found", err)
+	case errors.Is(err, services.ErrUploadOffsetMismatch), errors.Is(err, services.ErrUploadIncomplete), errors.Is(err, services.ErrUploadFinalized):
+		h.respondError(c, span, http.StatusConflict, "Upload conflict", err)
+	case errors.Is(err, services.ErrUploadTooLarge):
+		h.respondError(c, span, http.StatusRequestEntityTooLarge, "Upload too large", err)
+	case errors.Is(err, services.ErrUploadChecksumMismatch):
+		h.respondError(c, span, http.StatusUnprocessableEntity, "Checksum mismatch", err)
+	case errors.Is(err, services.ErrInvalidUpload):
+		h.respondError(c, span, http.StatusBadRequest, "Invalid upload", err)
+	default:
+		h.respondError(c, span, http.StatusInternalServerError, "Upload failed", err)
+	}
+}
+
+// respondError logs and responds with an error, adding the details of err when it is set
+func (h *UploadHandler) respondError(c *gin.Context, span tracer.Span, status int, message string, err error) {
+	fields := logrus.Fields{"status": status}
+	body := gin.H{"error": message}
+	if err != nil {
+		fields["error"] = err.Error()
+		body["details"] = err.Error()
+	}
+	h.logger.ErrorWithSpan(span, message, fields)
+	span.SetTag("error", true)
+	span.SetTag("error.message", message)
+	c.Header("Tus-Resumable", tusVersion)
+	c.JSON(status, body)
+}
+
+func setUploadHeaders(c *gin.Context, upload *models.Upload) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// parseUploadMetadata decodes a tus Upload-Metadata header
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("value of " + key + " is not base64")
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// parseChunkChecksum decodes a tus Upload-Checksum header; only sha256 is supported
+func parseChunkChecksum(header string) ([]byte, error) {
+	algorithm, encoded, _ := strings.Cut(header, " ")
+	if algorithm != "sha256" {
+		return nil, errors.New("checksum algorithm must be sha256")
+	}
+	checksum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(checksum) != sha256.Size {
+		return nil, errors.New("checksum must be a base64 SHA-256 digest")
+	}
+	return checksum, nil
+}
+
+// Global handler functions for compatibility with main.go
+var (
+	uploadService *services.UploadService
+	uploadHandler *UploadHandler
+)
+
+// InitUploadHandlers creates the upload service over the shared document service. It must be
+// called after InitDocumentHandlers.
+func InitUploadHandlers(logger *logger.DatadogLogger) {
+	validator := services.NewDocumentValidator(config.LoadDocumentValidationConfig())
+	uploadService = services.NewUploadService(documentService, validator, config.LoadUploadConfig(), logger)
+	uploadHandler = NewUploadHandler(uploadService, caseService, logger)
+}
+
+// InitUploadExpiryWorker creates the worker that discards abandoned uploads. It must be called
+// after InitUploadHandlers.
+func InitUploadExpiryWorker(logger *logger.DatadogLogger) *services.UploadExpiryWorker {
+	if uploadService == nil {
+		return nil
+	}
+	return services.NewUploadExpiryWorker(uploadService, config.LoadUploadConfig(), logger)
+}
+
+func CreateUpload(c *gin.Context) {
+	if uploadHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	uploadHandler.CreateUpload(c)
+}
+
+func GetUploadOffset(c *gin.Context) {
+	if uploadHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	uploadHandler.GetUploadOffset(c)
+}
+
+func PatchUpload(c *gin.Context) {
+	if uploadHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	uploadHandler.PatchUpload(c)
+}
+
+func FinalizeUpload(c *gin.Context) {
+	if uploadHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	uploadHandler.FinalizeUpload(c)
+}
+
+func DeleteUpload(c *gin.Context) {
+	if uploadHandler == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Handler not initialized"})
+		return
+	}
+	uploadHandler.DeleteUpload(c)
+}
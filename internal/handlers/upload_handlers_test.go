@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"mastercom-service/internal/config"
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupUploadTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// Initialize services and handlers
+	logger := logger.NewDatadogLogger()
+	documentService := services.NewDocumentService(logger)
+	validator := services.NewDocumentValidator(config.LoadDocumentValidationConfig())
+	uploadService := services.NewUploadService(documentService, validator, &models.UploadConfig{Expiry: 3600}, logger)
+	uploadHandler := NewUploadHandler(uploadService, services.NewCaseService(logger), logger)
+	documentHandler := NewDocumentHandler(documentService, services.NewCaseService(logger), logger)
+
+	// Setup routes
+	api := router.Group("/api/v6")
+	uploads := api.Group("/uploads")
+	{
+		uploads.POST("", uploadHandler.CreateUpload)
+		uploads.HEAD("/:id", uploadHandler.GetUploadOffset)
+		uploads.PATCH("/:id", uploadHandler.PatchUpload)
+		uploads.DELETE("/:id", uploadHandler.DeleteUpload)
+		uploads.POST("/:id/finalize", uploadHandler.FinalizeUpload)
+	}
+	api.GET("/documents/:id", documentHandler.GetDocument)
+
+	return router
+}
+
+func createUploadRequest(length int, metadata map[string]string) *http.Request {
+	var pairs []string
+	for key, value := range metadata {
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+	req, _ := http.NewRequest("POST", "/api/v6/uploads", nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", strconv.Itoa(length))
+	req.Header.Set("Upload-Metadata", strings.Join(pairs, ","))
+	return req
+}
+
+func createPatchRequest(location string, offset int, chunk []byte) *http.Request {
+	req, _ := http.NewRequest("PATCH", location, bytes.NewReader(chunk))
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+	return req
+}
+
+func TestResumableUpload(t *testing.T) {
+	router := setupUploadTestRouter()
+	content := []byte(testPDF(3))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, createUploadRequest(len(content), map[string]string{
+		"filename":   "scan.pdf",
+		"caseId":     "case-1",
+		"uploadedBy": "test-user",
+	}))
+	require.Equal(t, http.StatusCreated, w.Code)
+	location := w.Header().Get("Location")
+	assert.Regexp(t, `^/api/v6/uploads/[0-9a-f-]+$`, location)
+	assert.Equal(t, "1.0.0", w.Header().Get("Tus-Resumable"))
+	assert.Equal(t, "0", w.Header().Get("Upload-Offset"))
+	assert.NotEmpty(t, w.Header().Get("Upload-Expires"))
+
+	// First chunk, with a checksum
+	chunk := content[:64]
+	sum := sha256.Sum256(chunk)
+	w = httptest.NewRecorder()
+	req := createPatchRequest(location, 0, chunk)
+	req.Header.Set("Upload-Checksum", "sha256 "+base64.StdEncoding.EncodeToString(sum[:]))
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "64", w.Header().Get("Upload-Offset"))
+
+	// A chunk that does not match its checksum is refused
+	w = httptest.NewRecorder()
+	req = createPatchRequest(location, 64, content[64:])
+	req.Header.Set("Upload-Checksum", "sha256 "+base64.StdEncoding.EncodeToString(sum[:]))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 460, w.Code)
+
+	// The client resumes from the offset the server reports
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("HEAD", location, nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "64", w.Header().Get("Upload-Offset"))
+	assert.Equal(t, strconv.Itoa(len(content)), w.Header().Get("Upload-Length"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createPatchRequest(location, 10, content[10:]))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createPatchRequest(location, 64, content[64:]))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, strconv.Itoa(len(content)), w.Header().Get("Upload-Offset"))
+
+	// Finalizing stores the document
+	whole := sha256.Sum256(content)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", location+"/finalize", bytes.NewBufferString(`{"checksum":"sha256:`+hex.EncodeToString(whole[:])+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"`+hex.EncodeToString(whole[:])+`"`, w.Header().Get("ETag"))
+
+	var document models.DocumentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, hex.EncodeToString(whole[:]), document.SHA256)
+	assert.Equal(t, "case-1", document.CaseID)
+	assert.Equal(t, "scan.pdf", document.FileName)
+	assert.Equal(t, "application/pdf", document.FileType)
+	assert.Equal(t, int64(len(content)), document.FileSize)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v6/documents/"+document.ID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// endlessReader is a request body that never ends, counting the bytes read from it
+type endlessReader struct {
+	read int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	r.read += int64(len(p))
+	return len(p), nil
+}
+
+func TestResumableUpload_Errors(t *testing.T) {
+	router := setupUploadTestRouter()
+
+	// Unsupported protocol version
+	w := httptest.NewRecorder()
+	req := createUploadRequest(10, map[string]string{"filename": "scan.pdf", "caseId": "case-1"})
+	req.Header.Set("Tus-Resumable", "0.2.2")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, "1.0.0", w.Header().Get("Tus-Version"))
+
+	// Missing case ID
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createUploadRequest(10, map[string]string{"filename": "scan.pdf"}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Larger than any accepted document
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createUploadRequest(1<<30, map[string]string{"filename": "scan.pdf", "caseId": "case-1"}))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createPatchRequest("/api/v6/uploads/nonexistent-id", 0, []byte("x")))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createUploadRequest(10, map[string]string{"filename": "scan.pdf", "caseId": "case-1"}))
+	require.Equal(t, http.StatusCreated, w.Code)
+	location := w.Header().Get("Location")
+
+	// Wrong content type
+	w = httptest.NewRecorder()
+	req = createPatchRequest(location, 0, []byte("x"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	// Offsets other than the upload's are refused before the chunk is read
+	for _, offset := range []int{-1 << 62, -1, 5} {
+		body := &endlessReader{}
+		req = createPatchRequest(location, 0, nil)
+		req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+		req.Body = io.NopCloser(body)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code, offset)
+		assert.Zero(t, body.read, offset)
+	}
+
+	// Past the declared length
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, createPatchRequest(location, 0, make([]byte, 11)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Finalizing before the upload is complete
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", location+"/finalize", bytes.NewBufferString(`{"checksum":"sha256:`+hex.EncodeToString(make([]byte, 32))+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Abandoned
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", location, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("HEAD", location, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
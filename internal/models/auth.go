@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// AuthConfig represents configuration for authenticating API callers
+type AuthConfig struct {
+	Enabled bool `json:"enabled"`
+	// APIKeys are API keys configured at startup, so that the first can manage the others
+	APIKeys []APIKey `json:"-"`
+	// JWKSSource is the file path or URL of the key set verifying bearer tokens; bearer
+	// tokens are refused when it is empty
+	JWKSSource string `json:"jwksSource,omitempty"`
+	// JWKSRefreshInterval is the number of seconds between reads of the key set
+	JWKSRefreshInterval int    `json:"jwksRefreshInterval"`
+	JWTIssuer           string `json:"jwtIssuer,omitempty"`
+	JWTAudience         string `json:"jwtAudience,omitempty"`
+	// JWTRolesClaim names the token claim listing the caller's roles
+	JWTRolesClaim string `json:"jwtRolesClaim"`
+	// JWTICAsClaim names the token claim listing the ICAs the caller may act for
+	JWTICAsClaim string `json:"jwtIcasClaim"`
+	// AdminRoles lists the roles allowed to manage API keys
+	AdminRoles []string `json:"adminRoles"`
+}
+
+// APIKey represents an API key. Only the SHA-256 hash of the key is stored; the key itself
+// is returned once, when it is created.
+type APIKey struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Prefix is the start of the key, to tell keys apart
+	Prefix string   `json:"prefix,omitempty"`
+	Hash   string   `json:"-"`
+	Roles  []string `json:"roles"`
+	// ICAs are the ICAs the key may act for, or AllICAs
+	ICAs       []string   `json:"icas"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	CreatedBy  string     `json:"createdBy,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy  string     `json:"revokedBy,omitempty"`
+}
+
+// Revoked reports whether the key has been revoked
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name  string   `json:"name" binding:"required"`
+	Roles []string `json:"roles" binding:"required,min=1,dive,required"`
+	ICAs  []string `json:"icas" binding:"required,min=1,dive,required"`
+}
+
+// CreateAPIKeyResponse carries a new API key, which cannot be retrieved again
+type CreateAPIKeyResponse struct {
+	APIKey *APIKey `json:"apiKey"`
+	Key    string  `json:"key"`
+}
+
+// AllICAs in an ICA scope lets a caller act for every ICA
+const AllICAs = "*"
+
+// ICAScope lists the ICAs a caller may act for. A case is within the scope when it was filed
+// by or against one of them. A nil scope is unrestricted, as for the service's own workers
+// and when authentication is disabled.
+type ICAScope struct {
+	ICAs []string
+}
+
+// Unrestricted reports whether the scope permits every ICA
+func (s *ICAScope) Unrestricted() bool {
+	if s == nil {
+		return true
+	}
+	for _, ica := range s.ICAs {
+		if ica == AllICAs {
+			return true
+		}
+	}
+	return false
+}
+
+// Permits reports whether the scope holds ica
+func (s *ICAScope) Permits(ica string) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	for _, permitted := range s.ICAs {
+		if permitted == ica && ica != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// PermitsCase reports whether a case was filed by or against an ICA of the scope
+func (s *ICAScope) PermitsCase(caseObj *Case) bool {
+	return s.Permits(caseObj.FilingIca) || s.Permits(caseObj.FiledAgainstIca)
+}
+
+// PermitsClaim reports whether a claim's issuer or acquirer is an ICA of the scope
+func (s *ICAScope) PermitsClaim(claim *Claim) bool {
+	return s.Permits(claim.IssuerID) || s.Permits(claim.AcquirerID)
+}
+
+// PermitsTransaction reports whether a transaction belongs to an ICA of the scope
+func (s *ICAScope) PermitsTransaction(record *TransactionRecord) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	for _, ica := range record.ICAs {
+		if s.Permits(ica) {
+			return true
+		}
+	}
+	return false
+}
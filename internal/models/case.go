@@ -26,19 +26,40 @@ type Case struct {
 	FilingIca             string    `json:"filingIca" validate:"required"`
 	FiledAgainstIca       string    `json:"filedAgainstIca" validate:"required"`
 	FiledBy               string    `json:"filedBy"`
-	FiledByContactName    string    `json:"filedByContactName"`
-	FiledByContactPhone   string    `json:"filedByContactPhone"`
-	FiledByContactEmail   string    `json:"filedByContactEmail"`
+	// Contact details are encrypted at rest when encryption is enabled
+	FiledByContactName    string    `json:"filedByContactName" encrypt:"true"`
+	FiledByContactPhone   string    `json:"filedByContactPhone" encrypt:"true"`
+	FiledByContactEmail   string    `json:"filedByContactEmail" encrypt:"true"`
 	Status                string    `json:"status"`
+	QueueName             string    `json:"queueName"`
 	CreatedAt             time.Time `json:"createdAt"`
 	UpdatedAt             time.Time `json:"updatedAt"`
 	Documents             []Document `json:"documents,omitempty"`
+	// Submission tracks filing the case with Mastercom
+	MastercomCaseID    string            `json:"mastercomCaseId,omitempty"`
+	SubmissionStatus   string            `json:"submissionStatus,omitempty"`
+	SubmissionAttempts int               `json:"submissionAttempts,omitempty"`
+	NextSubmissionAt   *time.Time        `json:"nextSubmissionAt,omitempty"`
+	SubmittedAt        *time.Time        `json:"submittedAt,omitempty"`
+	SubmissionErrors   []SubmissionError `json:"submissionErrors,omitempty"`
+	// MastercomStatus and MastercomImageStatus are synchronised from Mastercom after filing
+	MastercomStatus      string `json:"mastercomStatus,omitempty"`
+	MastercomImageStatus string `json:"mastercomImageStatus,omitempty"`
+	// QueuedAt is when the case entered its current queue; retention periods count from it
+	QueuedAt time.Time `json:"queuedAt"`
+	// LegalHold, while set, stops the case and its documents from being purged or deleted
+	LegalHold *LegalHold `json:"legalHold,omitempty"`
+	// The card number is kept only as a vault token, with its BIN and last four digits;
+	// PrimaryAccountNumber holds it truncated to those
+	PANToken string `json:"-"`
+	PANBIN   string `json:"panBin,omitempty"`
+	PANLast4 string `json:"panLast4,omitempty"`
 }
 
 // CreateCaseRequest represents the request to create a new case
 type CreateCaseRequest struct {
 	CaseType              string    `json:"caseType" validate:"required"`
-	PrimaryAccountNumber  string    `json:"primaryAccountNumber" validate:"required"`
+	PrimaryAccountNumber  string    `json:"primaryAccountNumber" validate:"required,pan"`
 	TransactionAmount     float64   `json:"transactionAmount" validate:"required"`
 	TransactionCurrency   string    `json:"transactionCurrency" validate:"required"`
 	TransactionDate       time.Time `json:"transactionDate" validate:"required"`
@@ -81,6 +102,7 @@ type CaseResponse struct {
 	FiledByContactPhone   string    `json:"filedByContactPhone"`
 	FiledByContactEmail   string    `json:"filedByContactEmail"`
 	Status                string    `json:"status"`
+	QueueName             string    `json:"queueName"`
 	CreatedAt             time.Time `json:"createdAt"`
 	UpdatedAt             time.Time `json:"updatedAt"`
 }
@@ -109,6 +131,8 @@ func NewCase(req *CreateCaseRequest) *Case {
 		FiledByContactPhone:   req.FiledByContactPhone,
 		FiledByContactEmail:   req.FiledByContactEmail,
 		Status:                "PENDING",
+		QueueName:             QueueUnworked,
+		QueuedAt:              now,
 		CreatedAt:             now,
 		UpdatedAt:             now,
 		Documents:             []Document{},
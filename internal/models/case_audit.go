@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Case audit actions
+const (
+	CaseAuditCreated               = "CREATED"
+	CaseAuditUpdated               = "UPDATED"
+	CaseAuditQueueTransition       = "QUEUE_TRANSITION"
+	CaseAuditSubmitted             = "SUBMITTED"
+	CaseAuditSubmissionFailed      = "SUBMISSION_FAILED"
+	CaseAuditMastercomStatus       = "MASTERCOM_STATUS_UPDATED"
+	CaseAuditDocumentAttached      = "DOCUMENT_ATTACHED"
+	CaseAuditEvidenceBundleCreated = "EVIDENCE_BUNDLE_CREATED"
+	CaseAuditExported              = "EXPORTED"
+	CaseAuditDeleted               = "DELETED"
+	CaseAuditLegalHoldPlaced       = "LEGAL_HOLD_PLACED"
+	CaseAuditLegalHoldReleased     = "LEGAL_HOLD_RELEASED"
+	CaseAuditDocumentPurged        = "DOCUMENT_PURGED"
+	CaseAuditPurged                = "PURGED"
+	CaseAuditPANDetokenized        = "PAN_DETOKENIZED"
+)
+
+// CaseAuditEntry records a change to a case, or an action taken on it
+type CaseAuditEntry struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Action    string            `json:"action"`
+	Details   map[string]string `json:"details,omitempty"`
+}
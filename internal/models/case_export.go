@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// CaseExportManifest describes the contents of a case export archive
+type CaseExportManifest struct {
+	CaseID     string    `json:"caseId"`
+	ExportedAt time.Time `json:"exportedAt"`
+	// Files lists every other file in the archive with its SHA-256 checksum
+	Files []CaseExportFile `json:"files"`
+	// Omitted lists case documents whose content could not be exported
+	Omitted []CaseExportOmission `json:"omitted,omitempty"`
+}
+
+// CaseExportFile is a file in a case export archive
+type CaseExportFile struct {
+	Path       string `json:"path"`
+	SHA256     string `json:"sha256"`
+	Size       int64  `json:"size"`
+	DocumentID string `json:"documentId,omitempty"`
+	Version    int    `json:"version,omitempty"`
+	FileName   string `json:"fileName,omitempty"`
+	// Redacted is set when the file is the document with its card numbers masked
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// CaseExportOmission is a case document left out of an export, and why
+type CaseExportOmission struct {
+	DocumentID string `json:"documentId"`
+	FileName   string `json:"fileName"`
+	Reason     string `json:"reason"`
+}
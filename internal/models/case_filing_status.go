@@ -0,0 +1,59 @@
+package models
+
+// MaxCaseFilingStatusCases is the maximum number of case IDs accepted by a single status request
+const MaxCaseFilingStatusCases = 2000
+
+// Case filing image statuses reported to callers
+const (
+	CaseImageStatusCompleted   = "COMPLETED"
+	CaseImageStatusPending     = "PENDING"
+	CaseImageStatusFailed      = "FAILED"
+	CaseImageStatusUnavailable = "UNAVAILABLE"
+	CaseImageStatusUnprocessed = "UNPROCESSED"
+)
+
+// Parties that attached documentation to a case filing
+const (
+	CaseFilingPartySender   = "SND"
+	CaseFilingPartyReceiver = "REC"
+)
+
+// CaseFilingStatusRequest represents a request for the document status of a list of cases
+type CaseFilingStatusRequest struct {
+	CaseFilingList []CaseFilingStatusRequestStructure `json:"caseFilingList" validate:"required,min=1,max=2000,dive"`
+}
+
+// CaseFilingStatusRequestStructure identifies a single case in a status request
+type CaseFilingStatusRequestStructure struct {
+	CaseID string `json:"caseId" validate:"required"`
+}
+
+// CaseFilingStatusResponse represents the document status of each requested case
+type CaseFilingStatusResponse struct {
+	CaseFilingResponseList []CaseFilingStatusResponseStructure `json:"caseFilingResponseList"`
+}
+
+// CaseFilingStatusResponseStructure represents the document status of a single case,
+// either UNAVAILABLE or Status_Party_processDate
+type CaseFilingStatusResponseStructure struct {
+	CaseID string `json:"caseId"`
+	Status string `json:"status"`
+}
+
+// CaseFilingImageStatusRequest represents a search for cases whose documents have a status
+type CaseFilingImageStatusRequest struct {
+	Status    string `json:"status" validate:"required,oneof=COMPLETED FAILED UNPROCESSED"`
+	StartDate string `json:"startDate" validate:"required,len=10"`
+	EndDate   string `json:"endDate" validate:"required,len=10"`
+}
+
+// CaseFilingImageStatusResponse represents the cases matching an image status search
+type CaseFilingImageStatusResponse struct {
+	CaseFilingImageStatusList []CaseFilingImageStatusResponseStructure `json:"caseFilingImageStatusList"`
+}
+
+// CaseFilingImageStatusResponseStructure represents the image status of a single case
+type CaseFilingImageStatusResponseStructure struct {
+	CaseID string `json:"caseId"`
+	Status string `json:"status"`
+}
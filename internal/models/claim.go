@@ -0,0 +1,128 @@
+package models
+
+import "time"
+
+// Chargeback acknowledgement statuses returned by Mastercom
+const (
+	ChargebackAckProcessed = "PROCESSED"
+	ChargebackAckFailure   = "FAILURE"
+)
+
+// Claim is a Mastercom claim synchronised from the Mastercom queues
+type Claim struct {
+	ClaimID           string `json:"claimId"`
+	ClaimType         string `json:"claimType"`
+	ClaimValue        string `json:"claimValue"`
+	QueueName         string `json:"queueName"`
+	ProgressState     string `json:"progressState"`
+	IsOpen            bool   `json:"isOpen"`
+	IsIssuer          bool   `json:"isIssuer"`
+	IsAcquirer        bool   `json:"isAcquirer"`
+	IssuerID          string `json:"issuerId"`
+	AcquirerID        string `json:"acquirerId"`
+	PrimaryAccountNum string `json:"primaryAccountNum"`
+	AcquirerRefNum    string `json:"acquirerRefNum"`
+	TransactionID     string `json:"transactionId"`
+	MerchantID        string `json:"merchantId,omitempty"`
+	DueDate           string `json:"dueDate"`
+	CreateDate        string `json:"createDate"`
+	LastModifiedBy    string `json:"lastModifiedBy"`
+	LastModifiedDate  string `json:"lastModifiedDate"`
+	// CaseID and CaseFilingStatus are set for case filing claims
+	CaseID           string       `json:"caseId,omitempty"`
+	CaseFilingStatus string       `json:"caseFilingStatus,omitempty"`
+	Chargebacks      []Chargeback `json:"chargebacks,omitempty"`
+	Fees             []Fee        `json:"fees,omitempty"`
+	// Version is incremented every time a sync changes the claim
+	Version       int       `json:"version"`
+	FirstSyncedAt time.Time `json:"firstSyncedAt"`
+	LastSyncedAt  time.Time `json:"lastSyncedAt"`
+	LastChangedAt time.Time `json:"lastChangedAt"`
+}
+
+// Masked returns a copy of the claim for API responses, with the card number truncated
+func (c *Claim) Masked() *Claim {
+	masked := *c
+	masked.PrimaryAccountNum = TruncatePAN(c.PrimaryAccountNum)
+	return &masked
+}
+
+// Chargeback is a chargeback or second presentment on a synchronised claim
+type Chargeback struct {
+	ChargebackID     string `json:"chargebackId"`
+	ClaimID          string `json:"claimId"`
+	ChargebackType   string `json:"chargebackType"`
+	ChargebackRefNum string `json:"chargebackRefNum,omitempty"`
+	Amount           string `json:"amount"`
+	Currency         string `json:"currency"`
+	ReasonCode       string `json:"reasonCode"`
+	CreateDate       string `json:"createDate"`
+	DocumentStatus   string `json:"documentStatus,omitempty"`
+	Reversed         bool   `json:"reversed"`
+	Reversal         bool   `json:"reversal"`
+	RejectReason     string `json:"rejectReason,omitempty"`
+	// Acknowledgement is local state recording that the chargeback was marked processed in Mastercom
+	Acknowledged       bool       `json:"acknowledged"`
+	AcknowledgedAt     *time.Time `json:"acknowledgedAt,omitempty"`
+	AcknowledgeFailure string     `json:"acknowledgeFailure,omitempty"`
+}
+
+// Fee is a fee collection on a synchronised claim
+type Fee struct {
+	FeeID             string `json:"feeId"`
+	ClaimID           string `json:"claimId"`
+	Amount            string `json:"amount"`
+	Currency          string `json:"currency"`
+	Reason            string `json:"reason,omitempty"`
+	FeeDate           string `json:"feeDate"`
+	DestinationMember string `json:"destinationMember,omitempty"`
+	CreditSender      bool   `json:"creditSender"`
+	CreditReceiver    bool   `json:"creditReceiver"`
+	RejectReason      string `json:"rejectReason,omitempty"`
+}
+
+// ClaimUpsertResult describes how an upsert changed the stored claims
+type ClaimUpsertResult string
+
+const (
+	ClaimCreated   ClaimUpsertResult = "CREATED"
+	ClaimUpdated   ClaimUpsertResult = "UPDATED"
+	ClaimUnchanged ClaimUpsertResult = "UNCHANGED"
+)
+
+// QueueSyncConfig represents configuration for the Mastercom queue sync job
+type QueueSyncConfig struct {
+	Enabled bool `json:"enabled"`
+	// Interval is the time between syncs, in seconds
+	Interval int `json:"interval"`
+	// Queues to poll; every queue Mastercom returns is polled when empty
+	Queues []string `json:"queues"`
+	// InitialLookback is how far back the first sync reaches when there is no high-water mark, in hours
+	InitialLookback int `json:"initialLookback"`
+	// MaxLag is the sync lag, in seconds, above which the sync is reported as stale
+	MaxLag    int    `json:"maxLag"`
+	StateFile string `json:"stateFile"`
+}
+
+// QueueSyncState is the sync progress persisted between restarts
+type QueueSyncState struct {
+	// HighWaterMark is the end of the last last-modified window that was fully synchronised
+	HighWaterMark time.Time `json:"highWaterMark"`
+}
+
+// QueueSyncStatus reports the outcome of the most recent queue syncs
+type QueueSyncStatus struct {
+	Running             bool       `json:"running"`
+	HighWaterMark       *time.Time `json:"highWaterMark,omitempty"`
+	LastSyncStartedAt   *time.Time `json:"lastSyncStartedAt,omitempty"`
+	LastSyncCompletedAt *time.Time `json:"lastSyncCompletedAt,omitempty"`
+	LastSuccessAt       *time.Time `json:"lastSuccessAt,omitempty"`
+	LastError           string     `json:"lastError,omitempty"`
+	// LagSeconds is the time since the high-water mark
+	LagSeconds              int64 `json:"lagSeconds"`
+	Stale                   bool  `json:"stale"`
+	ClaimsCreated           int   `json:"claimsCreated"`
+	ClaimsUpdated           int   `json:"claimsUpdated"`
+	ChargebacksAcknowledged int   `json:"chargebacksAcknowledged"`
+	CasesUpdated            int   `json:"casesUpdated"`
+}
@@ -1,11 +1,36 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Document processing statuses, in the order a document moves through them.
+// A document may be rejected at any point before it is delivered.
+const (
+	// DocumentStatusQuarantined documents are held until a malware scan finds them clean
+	DocumentStatusQuarantined = "QUARANTINED"
+	DocumentStatusReceived    = "RECEIVED"
+	DocumentStatusValidated   = "VALIDATED"
+	DocumentStatusConverted   = "CONVERTED"
+	DocumentStatusDelivered   = "DELIVERED"
+	DocumentStatusRejected    = "REJECTED"
+)
+
+// ErrInvalidDocumentStatusTransition is returned when a document cannot move to the requested status
+var ErrInvalidDocumentStatusTransition = errors.New("invalid document status transition")
+
+// documentStatusTransitions lists the statuses each status may move to
+var documentStatusTransitions = map[string][]string{
+	DocumentStatusQuarantined: {DocumentStatusReceived, DocumentStatusRejected},
+	DocumentStatusReceived:    {DocumentStatusValidated, DocumentStatusRejected},
+	DocumentStatusValidated:   {DocumentStatusConverted, DocumentStatusRejected},
+	DocumentStatusConverted:   {DocumentStatusDelivered, DocumentStatusRejected},
+}
+
 // Document represents a document attached to a case
 type Document struct {
 	ID          string    `json:"id" bson:"_id"`
@@ -14,34 +39,122 @@ type Document struct {
 	FileType    string    `json:"fileType" validate:"required"`
 	FileSize    int64     `json:"fileSize"`
 	Content     []byte    `json:"content,omitempty"`
+	SHA256      string    `json:"sha256"` // hex SHA-256 of the content, taken at upload
 	UploadedBy  string    `json:"uploadedBy"`
 	UploadedAt  time.Time `json:"uploadedAt"`
 	Description string    `json:"description"`
+	// ProcessingStatus tracks the document from upload through delivery to Mastercom
+	ProcessingStatus string    `json:"processingStatus"`
+	StatusUpdatedAt  time.Time `json:"statusUpdatedAt"`
+	StatusReason     string    `json:"statusReason,omitempty"`
+	// DerivedFrom lists the documents a generated document, such as an evidence bundle, was built from
+	DerivedFrom []string `json:"derivedFrom,omitempty"`
+	// Scan is the outcome of the latest malware scan
+	Scan *DocumentScan `json:"scan,omitempty"`
+	// Version counts the content uploaded for the document, starting at 1; replacing the
+	// content stores a new version and keeps the previous ones
+	Version int `json:"version"`
+	// DeletedAt is set while the document is deleted; it can be restored until it is purged
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// Redaction is the outcome of masking card numbers in the document's text
+	Redaction *DocumentRedaction `json:"redaction,omitempty"`
+	// RedactedContent is the content with its card numbers masked, when any were found
+	RedactedContent []byte `json:"-"`
+	// ContentSealed is set on stored documents whose content and redacted content are held
+	// encrypted by the document store, leaving Content and RedactedContent empty
+	ContentSealed bool `json:"-"`
 }
 
 // DocumentResponse represents the response for document operations
 type DocumentResponse struct {
-	ID          string    `json:"id"`
-	CaseID      string    `json:"caseId"`
-	FileName    string    `json:"fileName"`
-	FileType    string    `json:"fileType"`
-	FileSize    int64     `json:"fileSize"`
-	UploadedBy  string    `json:"uploadedBy"`
-	UploadedAt  time.Time `json:"uploadedAt"`
-	Description string    `json:"description"`
+	ID               string             `json:"id"`
+	CaseID           string             `json:"caseId"`
+	FileName         string             `json:"fileName"`
+	FileType         string             `json:"fileType"`
+	FileSize         int64              `json:"fileSize"`
+	SHA256           string             `json:"sha256"`
+	UploadedBy       string             `json:"uploadedBy"`
+	UploadedAt       time.Time          `json:"uploadedAt"`
+	Description      string             `json:"description"`
+	ProcessingStatus string             `json:"processingStatus"`
+	StatusUpdatedAt  time.Time          `json:"statusUpdatedAt"`
+	StatusReason     string             `json:"statusReason,omitempty"`
+	DerivedFrom      []string           `json:"derivedFrom,omitempty"`
+	Scan             *DocumentScan      `json:"scan,omitempty"`
+	Version          int                `json:"version"`
+	Redaction        *DocumentRedaction `json:"redaction,omitempty"`
 }
 
 // NewDocument creates a new document
 func NewDocument(caseID, fileName, fileType string, content []byte, uploadedBy, description string) *Document {
+	now := time.Now()
 	return &Document{
-		ID:          uuid.New().String(),
-		CaseID:      caseID,
-		FileName:    fileName,
-		FileType:    fileType,
-		FileSize:    int64(len(content)),
-		Content:     content,
-		UploadedBy:  uploadedBy,
-		UploadedAt:  time.Now(),
-		Description: description,
+		ID:               uuid.New().String(),
+		CaseID:           caseID,
+		FileName:         fileName,
+		FileType:         fileType,
+		FileSize:         int64(len(content)),
+		Content:          content,
+		UploadedBy:       uploadedBy,
+		UploadedAt:       now,
+		Description:      description,
+		ProcessingStatus: DocumentStatusReceived,
+		StatusUpdatedAt:  now,
+		Version:          1,
+	}
+}
+
+// SetProcessingStatus moves the document to a new processing status, recording
+// why when a reason is given
+func (d *Document) SetProcessingStatus(status, reason string) error {
+	allowed := false
+	for _, next := range documentStatusTransitions[d.ProcessingStatus] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidDocumentStatusTransition, d.ProcessingStatus, status)
+	}
+
+	d.ProcessingStatus = status
+	d.StatusReason = reason
+	d.StatusUpdatedAt = time.Now()
+	return nil
+}
+
+// NewDocumentResponse creates a document response, leaving out the document content
+func NewDocumentResponse(document *Document) DocumentResponse {
+	return DocumentResponse{
+		ID:               document.ID,
+		CaseID:           document.CaseID,
+		FileName:         document.FileName,
+		FileType:         document.FileType,
+		FileSize:         document.FileSize,
+		SHA256:           document.SHA256,
+		UploadedBy:       document.UploadedBy,
+		UploadedAt:       document.UploadedAt,
+		Description:      document.Description,
+		ProcessingStatus: document.ProcessingStatus,
+		StatusUpdatedAt:  document.StatusUpdatedAt,
+		StatusReason:     document.StatusReason,
+		DerivedFrom:      document.DerivedFrom,
+		Scan:             document.Scan,
+		Version:          document.Version,
+		Redaction:        document.Redaction,
+	}
+}
+
+// Redacted returns a copy of the document with its redacted content in place of the
+// original, or the document itself when it has no redacted content
+func (d *Document) Redacted() *Document {
+	if d.Redaction == nil || d.Redaction.Status != RedactionStatusRedacted {
+		return d
 	}
+	redacted := *d
+	redacted.Content = d.RedactedContent
+	redacted.SHA256 = d.Redaction.SHA256
+	redacted.FileSize = d.Redaction.FileSize
+	return &redacted
 }
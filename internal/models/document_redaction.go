@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Card number redaction outcomes
+const (
+	// RedactionStatusPending documents are not served until they have been checked
+	RedactionStatusPending = "PENDING"
+	// RedactionStatusRedacted documents have a derivative with their card numbers masked
+	RedactionStatusRedacted = "REDACTED"
+	// RedactionStatusClean documents have no card numbers in their text
+	RedactionStatusClean = "CLEAN"
+	// RedactionStatusFailed documents could not be read for card numbers; only privileged
+	// callers can retrieve their content
+	RedactionStatusFailed = "FAILED"
+)
+
+// DocumentRedaction records the card number redaction of a PDF document
+type DocumentRedaction struct {
+	Status string `json:"status"`
+	// CardNumbers counts the card numbers masked in the derivative
+	CardNumbers int `json:"cardNumbers"`
+	// SHA256 and FileSize describe the redacted derivative
+	SHA256     string    `json:"sha256,omitempty"`
+	FileSize   int64     `json:"fileSize,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	RedactedAt time.Time `json:"redactedAt"`
+}
+
+// RedactionConfig represents configuration for masking card numbers in uploaded PDFs
+type RedactionConfig struct {
+	Enabled bool `json:"enabled"`
+	// OriginalRoles lists the roles allowed to retrieve the original of a redacted document
+	OriginalRoles []string `json:"originalRoles"`
+}
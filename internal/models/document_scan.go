@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Malware scan outcomes
+const (
+	ScanStatusClean    = "CLEAN"
+	ScanStatusInfected = "INFECTED"
+	// ScanStatusFailed scans could not reach a verdict and are retried while the document is quarantined
+	ScanStatusFailed = "FAILED"
+)
+
+// DocumentScan records the latest malware scan of a document
+type DocumentScan struct {
+	Scanner   string `json:"scanner"`
+	Status    string `json:"status"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Attempts counts the scans run, including failed ones
+	Attempts  int       `json:"attempts"`
+	ScannedAt time.Time `json:"scannedAt"`
+}
+
+// ScanConfig represents configuration for malware scanning of uploaded documents
+type ScanConfig struct {
+	// ClamdAddress is the clamd socket, as tcp://host:port or unix:///path; scanning is
+	// disabled when it is empty
+	ClamdAddress string `json:"clamdAddress"`
+	// Timeout bounds a single scan, in seconds
+	Timeout int `json:"timeout"`
+	// RescanInterval is the time between retries of failed scans, in seconds
+	RescanInterval int `json:"rescanInterval"`
+	// MaxAttempts is the number of failed scans after which a quarantined document is rejected
+	MaxAttempts int `json:"maxAttempts"`
+}
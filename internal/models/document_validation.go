@@ -0,0 +1,36 @@
+package models
+
+// Document violation codes reported when an upload is rejected
+const (
+	DocumentViolationEmpty             = "EMPTY_FILE"
+	DocumentViolationUnsupportedType   = "UNSUPPORTED_TYPE"
+	DocumentViolationExtensionMismatch = "EXTENSION_MISMATCH"
+	DocumentViolationTooLarge          = "FILE_TOO_LARGE"
+	DocumentViolationTooManyPages      = "TOO_MANY_PAGES"
+	DocumentViolationUnreadable        = "UNREADABLE"
+)
+
+// DocumentTypeRule describes a document format accepted as case evidence
+type DocumentTypeRule struct {
+	MIMEType string `json:"mimeType"`
+	// Extensions lists the file name extensions expected for the format, with leading dots
+	Extensions []string `json:"extensions"`
+	// MaxSize is the largest file accepted, in bytes
+	MaxSize int64 `json:"maxSize"`
+	// MaxPages is the most pages accepted, 0 when the format has no pages
+	MaxPages int `json:"maxPages,omitempty"`
+}
+
+// DocumentValidationConfig represents the formats accepted for uploaded documents
+type DocumentValidationConfig struct {
+	Types []DocumentTypeRule `json:"types"`
+}
+
+// DocumentViolation describes one reason an uploaded document was rejected
+type DocumentViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Limit and Actual hold the allowed and found values for size and page count violations
+	Limit  int64 `json:"limit,omitempty"`
+	Actual int64 `json:"actual,omitempty"`
+}
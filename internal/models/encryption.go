@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// EncryptionConfig represents configuration for encrypting sensitive case data at rest
+type EncryptionConfig struct {
+	// Keys are the key-encryption keys by ID; PrimaryKeyID wraps new data keys and the others
+	// are kept until what they wrapped has been re-wrapped
+	Keys         map[string][]byte `json:"-"`
+	PrimaryKeyID string            `json:"primaryKeyId"`
+	// KeysFile is read for the keys at startup and again by each key rotation
+	KeysFile string `json:"keysFile,omitempty"`
+}
+
+// Enabled reports whether any key-encryption key is configured
+func (c *EncryptionConfig) Enabled() bool {
+	return len(c.Keys) > 0
+}
+
+// KeyRotationReport counts the data keys a key rotation re-wrapped with the primary key
+type KeyRotationReport struct {
+	PrimaryKeyID     string    `json:"primaryKeyId"`
+	CaseFields       int       `json:"caseFields"`
+	DocumentContents int       `json:"documentContents"`
+	CardNumbers      int       `json:"cardNumbers"`
+	RotatedAt        time.Time `json:"rotatedAt"`
+}
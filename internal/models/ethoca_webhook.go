@@ -91,4 +91,6 @@ type WebhookConfig struct {
 	Timeout    int    `json:"timeout"`
 	MaxRetries int    `json:"maxRetries"`
 	BatchSize  int    `json:"batchSize"`
+	// ICA is the ICA Ethoca alerts are received for, reported on refunds
+	ICA string `json:"ica,omitempty"`
 }
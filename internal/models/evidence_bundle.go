@@ -0,0 +1,19 @@
+package models
+
+// Evidence bundle formats
+const (
+	EvidenceBundleFormatPDF  = "PDF"
+	EvidenceBundleFormatTIFF = "TIFF"
+)
+
+// EvidenceBundleRequest represents a request to merge a case's documents into the single
+// image file Mastercom expects as case evidence
+type EvidenceBundleRequest struct {
+	// Format is PDF or TIFF
+	Format string `json:"format"`
+	// DocumentIDs orders the documents to merge; when empty every document is merged, oldest first
+	DocumentIDs []string `json:"documentIds"`
+	// CoverSheet adds a first page summarising the case
+	CoverSheet bool   `json:"coverSheet"`
+	CreatedBy  string `json:"createdBy"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// MastercomConfig represents configuration for outbound Mastercom API calls
+type MastercomConfig struct {
+	BaseURL          string `json:"baseUrl"`
+	ConsumerKey      string `json:"consumerKey"`
+	KeystorePath     string `json:"keystorePath"`
+	KeystorePassword string `json:"-"`
+	// Timeout is the per request timeout in seconds
+	Timeout int `json:"timeout"`
+
+	// RateLimit is the default requests per second per endpoint, 0 for unlimited
+	RateLimit float64 `json:"rateLimit"`
+	RateBurst int     `json:"rateBurst"`
+	// EndpointRateLimits overrides RateLimit per endpoint, keyed like "PUT /v6/chargebacks/acknowledge"
+	EndpointRateLimits map[string]float64 `json:"endpointRateLimits,omitempty"`
+	// MaxConcurrent caps the requests in flight to Mastercom, 0 for unlimited
+	MaxConcurrent int `json:"maxConcurrent"`
+	// QueueTimeout is how long a request waits for a concurrency slot, in seconds
+	QueueTimeout int `json:"queueTimeout"`
+	// BreakerFailures is the number of consecutive failures that opens the circuit, 0 to disable it
+	BreakerFailures int `json:"breakerFailures"`
+	// BreakerOpenTimeout is how long the circuit stays open before probing, in seconds
+	BreakerOpenTimeout    int `json:"breakerOpenTimeout"`
+	BreakerHalfOpenProbes int `json:"breakerHalfOpenProbes"`
+	MaxRetries            int `json:"maxRetries"`
+	// RetryBackoff is the first retry delay in seconds when Mastercom sends no Retry-After
+	RetryBackoff int `json:"retryBackoff"`
+	// MaxRetryAfter is the longest Retry-After honoured, in seconds
+	MaxRetryAfter int `json:"maxRetryAfter"`
+	// RetryBudget is the ratio of retries to requests allowed over time
+	RetryBudget      float64 `json:"retryBudget"`
+	RetryBudgetBurst int     `json:"retryBudgetBurst"`
+}
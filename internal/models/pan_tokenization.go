@@ -0,0 +1,18 @@
+package models
+
+// TokenizationConfig represents configuration for the card numbers held in the token vault
+type TokenizationConfig struct {
+	// DetokenizeRoles lists the roles allowed to retrieve a case's full card number
+	DetokenizeRoles []string `json:"detokenizeRoles"`
+}
+
+// DetokenizeRequest represents the request for a case's full card number
+type DetokenizeRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// DetokenizeResponse carries a case's full card number
+type DetokenizeResponse struct {
+	CaseID               string `json:"caseId"`
+	PrimaryAccountNumber string `json:"primaryAccountNumber"`
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Queue names used to organise cases for analysts
+const (
+	QueueUnworked  = "Unworked"
+	QueueWorked    = "Worked"
+	QueueSubmitted = "Submitted"
+	QueueRejects   = "Rejects"
+	QueueClosed    = "Closed"
+)
+
+// QueueAction represents an action that moves a case between queues
+type QueueAction string
+
+const (
+	QueueActionAcknowledge QueueAction = "ACKNOWLEDGE"
+	QueueActionReject      QueueAction = "REJECT"
+	QueueActionSubmit      QueueAction = "SUBMIT"
+	QueueActionClose       QueueAction = "CLOSE"
+)
+
+// ErrInvalidQueueTransition is returned when an action is not allowed from a case's current queue
+var ErrInvalidQueueTransition = errors.New("invalid queue transition")
+
+// Queue represents a work queue
+type Queue struct {
+	QueueName        string `json:"queueName"`
+	QueueDescription string `json:"queueDescription"`
+}
+
+// Queues lists the work queues in the order they are presented to analysts
+var Queues = []Queue{
+	{QueueName: QueueUnworked, QueueDescription: "Items not yet acknowledged by an analyst"},
+	{QueueName: QueueWorked, QueueDescription: "Items acknowledged and being worked"},
+	{QueueName: QueueSubmitted, QueueDescription: "Items submitted to Mastercom"},
+	{QueueName: QueueRejects, QueueDescription: "Items rejected and awaiting correction"},
+	{QueueName: QueueClosed, QueueDescription: "Closed items"},
+}
+
+// queueTransitions maps each action to the queues it may be applied from and the queue it moves to
+var queueTransitions = map[QueueAction]struct {
+	from []string
+	to   string
+}{
+	QueueActionAcknowledge: {from: []string{QueueUnworked, QueueRejects}, to: QueueWorked},
+	QueueActionReject:      {from: []string{QueueUnworked, QueueWorked, QueueSubmitted}, to: QueueRejects},
+	QueueActionSubmit:      {from: []string{QueueWorked, QueueRejects}, to: QueueSubmitted},
+	QueueActionClose:       {from: []string{QueueUnworked, QueueWorked, QueueSubmitted, QueueRejects}, to: QueueClosed},
+}
+
+// IsQueue reports whether name is a known queue
+func IsQueue(name string) bool {
+	for _, queue := range Queues {
+		if queue.QueueName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NextQueue returns the queue an item in current moves to when action is applied
+func NextQueue(current string, action QueueAction) (string, error) {
+	transition, ok := queueTransitions[action]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown action %s", ErrInvalidQueueTransition, action)
+	}
+
+	for _, from := range transition.from {
+		if from == current {
+			return transition.to, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: cannot %s from %s", ErrInvalidQueueTransition, action, current)
+}
+
+// ClaimSummary represents an item in a work queue
+type ClaimSummary struct {
+	AcquirerID        string `json:"acquirerId"`
+	AcquirerRefNum    string `json:"acquirerRefNum"`
+	PrimaryAccountNum string `json:"primaryAccountNum"`
+	ClaimID           string `json:"claimId"`
+	ClaimType         string `json:"claimType"`
+	ClaimValue        string `json:"claimValue"`
+	ClearingDueDate   string `json:"clearingDueDate"`
+	ClearingNetwork   string `json:"clearingNetwork"`
+	CreateDate        string `json:"createDate"`
+	DueDate           string `json:"dueDate"`
+	TransactionID     string `json:"transactionId"`
+	IsAccurate        bool   `json:"isAccurate"`
+	IsAcquirer        bool   `json:"isAcquirer"`
+	IsIssuer          bool   `json:"isIssuer"`
+	IsOpen            bool   `json:"isOpen"`
+	IssuerID          string `json:"issuerId"`
+	LastModifiedBy    string `json:"lastModifiedBy"`
+	LastModifiedDate  string `json:"lastModifiedDate"`
+	MerchantID        string `json:"merchantId"`
+	ProgressState     string `json:"progressState"`
+	QueueName         string `json:"queueName"`
+}
+
+// QueueContentRequest represents a request for queue contents within a date interval
+type QueueContentRequest struct {
+	QueueName            string `json:"queueName" validate:"required,max=30"`
+	LastModifiedDateFrom string `json:"lastModifiedDateFrom" validate:"omitempty,len=16"`
+	LastModifiedDateTo   string `json:"lastModifiedDateTo" validate:"omitempty,len=16"`
+	PageNb               string `json:"pageNb" validate:"omitempty,numeric,max=3"`
+}
+
+// QueueContentSummary represents a page of queue contents
+type QueueContentSummary struct {
+	PageCount string         `json:"pageCount"`
+	ClaimList []ClaimSummary `json:"claimList"`
+}
+
+// QueueCount represents the number of items in a queue
+type QueueCount struct {
+	QueueName string `json:"queueName"`
+	Count     int    `json:"count"`
+}
+
+// NewClaimSummary creates a queue item summary from a case
+func NewClaimSummary(caseObj *Case) ClaimSummary {
+	isIssuer := caseObj.FilingAs == "ISSUER" || caseObj.FilingAs == "I"
+	isAcquirer := caseObj.FilingAs == "ACQUIRER" || caseObj.FilingAs == "A"
+
+	summary := ClaimSummary{
+		PrimaryAccountNum: caseObj.PrimaryAccountNumber,
+		ClaimID:           caseObj.ID,
+		ClaimType:         caseObj.CaseType,
+		ClaimValue:        fmt.Sprintf("%.2f %s", caseObj.TransactionAmount, caseObj.TransactionCurrency),
+		CreateDate:        caseObj.CreatedAt.Format("2006-01-02"),
+		TransactionID:     caseObj.TransactionID,
+		IsAccurate:        true,
+		IsAcquirer:        isAcquirer,
+		IsIssuer:          isIssuer,
+		IsOpen:            caseObj.QueueName != QueueClosed,
+		LastModifiedBy:    caseObj.FiledBy,
+		LastModifiedDate:  caseObj.UpdatedAt.Format("2006-01-02T15:04:05"),
+		ProgressState:     caseObj.Status,
+		QueueName:         caseObj.QueueName,
+	}
+
+	if isIssuer {
+		summary.IssuerID = caseObj.FilingIca
+		summary.AcquirerID = caseObj.FiledAgainstIca
+	} else {
+		summary.AcquirerID = caseObj.FilingIca
+		summary.IssuerID = caseObj.FiledAgainstIca
+	}
+
+	return summary
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recon report statuses as returned by the retrieval endpoint
+const (
+	ReconReportAvailable   = "Available"
+	ReconReportUnavailable = "Unavailable"
+	ReconReportFailed      = "Failed"
+)
+
+// Activity types aggregated into a recon report
+const (
+	ReconActivityCase         = "CASE"
+	ReconActivityChargeback   = "CHARGEBACK"
+	ReconActivityFee          = "FEE"
+	ReconActivityEthocaRefund = "ETHOCA_REFUND"
+)
+
+// ReconReportRequest represents a request to generate a reconciliation report
+type ReconReportRequest struct {
+	ICA                              []string `json:"ica" validate:"omitempty,dive,numeric,min=1,max=11"`
+	StartDate                        string   `json:"startDate" validate:"required,len=10"`
+	EndDate                          string   `json:"endDate" validate:"required,len=10"`
+	Cycles                           []int    `json:"cycles" validate:"omitempty,dive,min=1,max=7"`
+	EnhancedReconciliationReportFlag bool     `json:"enhancedReconciliationReportFlag"`
+}
+
+// ReconReportAcknowledgement represents the response to a report generation request
+type ReconReportAcknowledgement struct {
+	ReportIdentifier string `json:"reportIdentifier"`
+}
+
+// ReconReportRetrieval represents a report retrieval response, with the CSV report base64 encoded in Data
+type ReconReportRetrieval struct {
+	Status string `json:"status"`
+	Data   string `json:"data,omitempty"`
+}
+
+// ReconRecord represents a single activity line in a reconciliation report
+type ReconRecord struct {
+	ActivityType         string    `json:"activityType"`
+	ReferenceID          string    `json:"referenceId"`
+	ICA                  string    `json:"ica,omitempty"`
+	PrimaryAccountNumber string    `json:"primaryAccountNumber,omitempty"`
+	Amount               float64   `json:"amount"`
+	Currency             string    `json:"currency"`
+	ReasonCode           string    `json:"reasonCode,omitempty"`
+	Status               string    `json:"status"`
+	ActivityDate         time.Time `json:"activityDate"`
+}
+
+// ReconReport represents a reconciliation report job and, once available, its records
+type ReconReport struct {
+	ID          string             `json:"reportIdentifier"`
+	Request     ReconReportRequest `json:"request"`
+	Status      string             `json:"status"`
+	Records     []ReconRecord      `json:"records,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	RequestedAt time.Time          `json:"requestedAt"`
+	CompletedAt *time.Time         `json:"completedAt,omitempty"`
+}
+
+// NewReconReport creates a new pending reconciliation report
+func NewReconReport(req *ReconReportRequest) *ReconReport {
+	return &ReconReport{
+		ID:          uuid.New().String(),
+		Request:     *req,
+		Status:      ReconReportUnavailable,
+		RequestedAt: time.Now(),
+	}
+}
+
+// Masked returns a copy of the report for API responses, with card numbers truncated
+func (r *ReconReport) Masked() *ReconReport {
+	masked := *r
+	masked.Records = make([]ReconRecord, len(r.Records))
+	for i, record := range r.Records {
+		record.PrimaryAccountNumber = TruncatePAN(record.PrimaryAccountNumber)
+		masked.Records[i] = record
+	}
+	return &masked
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// RetentionAny matches every case type or queue in a retention policy
+const RetentionAny = "*"
+
+// Retention purge reasons
+const (
+	// PurgeReasonCaseRetention documents are purged because their case's retention period ended
+	PurgeReasonCaseRetention = "CASE_RETENTION"
+	// PurgeReasonDeleted documents are purged because they stayed deleted past the grace period
+	PurgeReasonDeleted = "DELETED"
+)
+
+// RetentionPolicy sets how long the evidence of cases of a type is kept once they reach a
+// queue. A case's queue marks its status, so a Closed policy counts from case closure.
+type RetentionPolicy struct {
+	// CaseType and Queue select the cases the policy applies to; either may be RetentionAny
+	CaseType string `json:"caseType"`
+	Queue    string `json:"queue"`
+	// DocumentDays is how long after entering the queue a case's documents are purged;
+	// zero keeps them
+	DocumentDays int `json:"documentDays"`
+	// CaseDays is how long after entering the queue the case itself is purged, with its
+	// documents; zero keeps it
+	CaseDays int `json:"caseDays"`
+}
+
+// Matches reports whether the policy applies to a case of caseType in queueName
+func (p RetentionPolicy) Matches(caseType, queueName string) bool {
+	return (p.CaseType == RetentionAny || p.CaseType == caseType) &&
+		(p.Queue == RetentionAny || p.Queue == queueName)
+}
+
+// RetentionConfig represents configuration for purging evidence past its retention period
+type RetentionConfig struct {
+	// Enabled runs the scheduled purge
+	Enabled bool `json:"enabled"`
+	// DryRun reports what would be purged without purging anything
+	DryRun bool `json:"dryRun"`
+	// Interval is the time between scheduled purges, in seconds
+	Interval int `json:"interval"`
+	// DeletedDocumentDays is how long deleted documents can be restored before they are
+	// purged; zero keeps them
+	DeletedDocumentDays int               `json:"deletedDocumentDays"`
+	Policies            []RetentionPolicy `json:"policies"`
+}
+
+// LegalHold stops a case and its documents from being purged or deleted
+type LegalHold struct {
+	Reason   string    `json:"reason"`
+	PlacedBy string    `json:"placedBy"`
+	PlacedAt time.Time `json:"placedAt"`
+}
+
+// LegalHoldRequest represents the request to place a case on legal hold
+type LegalHoldRequest struct {
+	Reason   string `json:"reason" binding:"required"`
+	PlacedBy string `json:"placedBy"`
+}
+
+// PurgeReport lists what a purge removed, or would remove on a dry run
+type PurgeReport struct {
+	DryRun    bool             `json:"dryRun"`
+	StartedAt time.Time        `json:"startedAt"`
+	Cases     []PurgedCase     `json:"cases"`
+	Documents []PurgedDocument `json:"documents"`
+	// Held lists the cases past their retention period that were kept for a legal hold
+	Held []string `json:"held"`
+}
+
+// PurgedCase is a case removed by a retention purge
+type PurgedCase struct {
+	CaseID    string `json:"caseId"`
+	CaseType  string `json:"caseType"`
+	QueueName string `json:"queueName"`
+}
+
+// PurgedDocument is a document removed, with all its versions, by a retention purge
+type PurgedDocument struct {
+	DocumentID string `json:"documentId"`
+	CaseID     string `json:"caseId"`
+	FileName   string `json:"fileName"`
+	Versions   int    `json:"versions"`
+	Reason     string `json:"reason"`
+}
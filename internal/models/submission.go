@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Case submission statuses
+const (
+	// SubmissionStatusPending cases are waiting to be filed, or to be retried after a transient failure
+	SubmissionStatusPending = "PENDING"
+	// SubmissionStatusSubmitted cases were accepted by Mastercom
+	SubmissionStatusSubmitted = "SUBMITTED"
+	// SubmissionStatusRejected cases were refused by Mastercom and need correcting
+	SubmissionStatusRejected = "REJECTED"
+	// SubmissionStatusFailed cases ran out of retries
+	SubmissionStatusFailed = "FAILED"
+)
+
+// Case statuses set by the submission worker
+const (
+	CaseStatusSubmitted = "SUBMITTED"
+	CaseStatusRejected  = "REJECTED"
+)
+
+// SubmissionError describes why a case submission failed, as reported by Mastercom when available
+type SubmissionError struct {
+	Source      string            `json:"source,omitempty"`
+	ReasonCode  string            `json:"reasonCode,omitempty"`
+	Description string            `json:"description"`
+	Recoverable bool              `json:"recoverable"`
+	Details     map[string]string `json:"details,omitempty"`
+	OccurredAt  time.Time         `json:"occurredAt"`
+}
+
+// SubmissionConfig represents configuration for the case submission worker
+type SubmissionConfig struct {
+	Enabled bool `json:"enabled"`
+	// Interval is the time between polls for cases ready to file, in seconds
+	Interval    int `json:"interval"`
+	MaxAttempts int `json:"maxAttempts"`
+	// BackoffBase and BackoffMax bound the exponential retry delay, in seconds
+	BackoffBase int `json:"backoffBase"`
+	BackoffMax  int `json:"backoffMax"`
+}
+
+// ReadyForSubmission reports whether a case is waiting in the Submitted queue to be filed
+// with Mastercom and is due for an attempt at now
+func (c *Case) ReadyForSubmission(now time.Time) bool {
+	if c.QueueName != QueueSubmitted || c.MastercomCaseID != "" {
+		return false
+	}
+	if c.SubmissionStatus != "" && c.SubmissionStatus != SubmissionStatusPending {
+		return false
+	}
+	return c.NextSubmissionAt == nil || !c.NextSubmissionAt.After(now)
+}
+
+// ResetSubmission clears the outcome of any previous submission so the case is filed afresh
+func (c *Case) ResetSubmission() {
+	c.SubmissionStatus = SubmissionStatusPending
+	c.SubmissionAttempts = 0
+	c.NextSubmissionAt = nil
+	c.SubmissionErrors = nil
+}
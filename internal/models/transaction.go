@@ -0,0 +1,321 @@
+package models
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"mastercom-service/pkg/pan"
+)
+
+// TransactionSearchRequest represents a Mastercom transaction search request
+type TransactionSearchRequest struct {
+	AcquirerRefNumber string `json:"acquirerRefNumber" validate:"omitempty,numeric,len=23"`
+	BankNetRefNumber  string `json:"bankNetRefNumber" validate:"omitempty,alphanum,len=9"`
+	PrimaryAccountNum string `json:"primaryAccountNum" validate:"omitempty,numeric,min=11,max=19"`
+	TransAmountFrom   string `json:"transAmountFrom" validate:"omitempty,numeric,max=12"`
+	TransAmountTo     string `json:"transAmountTo" validate:"omitempty,numeric,max=12"`
+	TranStartDate     string `json:"tranStartDate" validate:"required,len=10"`
+	TranEndDate       string `json:"tranEndDate" validate:"required,len=10"`
+}
+
+// TransactionSummary represents the response to a transaction search
+type TransactionSummary struct {
+	AuthorizationSummaryCount string                 `json:"authorizationSummaryCount"`
+	Message                   string                 `json:"message"`
+	AuthorizationSummary      []AuthorizationSummary `json:"authorizationSummary"`
+}
+
+// AuthorizationSummary represents an authorization found by a transaction search
+type AuthorizationSummary struct {
+	OriginalMessageTypeIdentifier string            `json:"originalMessageTypeIdentifier"`
+	BanknetDate                   string            `json:"banknetDate"`
+	TransactionAmountUsd          string            `json:"transactionAmountUsd,omitempty"`
+	PrimaryAccountNumber          string            `json:"primaryAccountNumber"`
+	ProcessingCode                string            `json:"processingCode"`
+	TransactionAmountLocal        string            `json:"transactionAmountLocal"`
+	AuthorizationDateAndTime      string            `json:"authorizationDateAndTime"`
+	AuthenticationID              string            `json:"authenticationId"`
+	CardAcceptorName              string            `json:"cardAcceptorName"`
+	CardAcceptorCity              string            `json:"cardAcceptorCity"`
+	CardAcceptorState             string            `json:"cardAcceptorState"`
+	CurrencyCode                  string            `json:"currencyCode"`
+	ChipPresent                   string            `json:"chipPresent"`
+	TransactionID                 string            `json:"transactionId"`
+	Track1                        string            `json:"track1,omitempty"`
+	Track2                        string            `json:"track2,omitempty"`
+	ClearingSummary               []ClearingSummary `json:"clearingSummary"`
+}
+
+// ClearingSummary represents a clearing record linked to an authorization
+type ClearingSummary struct {
+	PrimaryAccountNumber               string `json:"primaryAccountNumber"`
+	TransactionAmountLocal             string `json:"transactionAmountLocal"`
+	DateAndTimeLocal                   string `json:"dateAndTimeLocal"`
+	CardDataInputCapability            string `json:"cardDataInputCapability"`
+	CardholderAuthenticationCapability string `json:"cardholderAuthenticationCapability"`
+	CardPresent                        string `json:"cardPresent"`
+	AcquirerReferenceNumber            string `json:"acquirerReferenceNumber"`
+	CardAcceptorName                   string `json:"cardAcceptorName"`
+	CurrencyCode                       string `json:"currencyCode"`
+	InstallmentPaymentDataBrazil       string `json:"installmentPaymentDataBrazil,omitempty"`
+	TransactionID                      string `json:"transactionId"`
+	SettlementIndicator                string `json:"settlementIndicator"`
+	MessageReversalIndicator           string `json:"messageReversalIndicator,omitempty"`
+}
+
+// AuthorizationDetail represents the authorization details of an original transaction
+type AuthorizationDetail struct {
+	TransactionID                 string `json:"transactionId,omitempty"`
+	PrimaryAccountNumber          string `json:"primaryAccountNumber"`
+	AuthenticationIndicator       string `json:"authenticationIndicator"`
+	AuthorizationIDResponse       string `json:"authorizationIdResponse"`
+	BanknetDate                   string `json:"banknetDate"`
+	BanknetReferenceNumber        string `json:"banknetReferenceNumber"`
+	BillingCurrencyCode           string `json:"billingCurrencyCode"`
+	CardAcceptorCity              string `json:"cardAcceptorCity"`
+	CardAcceptorID                string `json:"cardAcceptorId"`
+	CardAcceptorName              string `json:"cardAcceptorName"`
+	CardAcceptorState             string `json:"cardAcceptorState"`
+	CardAcceptorTerminalID        string `json:"cardAcceptorTerminalId"`
+	CardholderBillingAmount       string `json:"cardholderBillingAmount"`
+	ChipPresent                   string `json:"chipPresent,omitempty"`
+	ElectronicCommerceIndicators  string `json:"electronicCommerceIndicators"`
+	FinancialNetworkCode          string `json:"financialNetworkCode"`
+	MerchantCategoryCode          string `json:"merchantCategoryCode"`
+	OriginalMessageTypeIdentifier string `json:"originalMessageTypeIdentifier"`
+	PosEntryModePan               string `json:"posEntryModePan"`
+	ProcessingCode                string `json:"processingCode"`
+	ResponseCode                  string `json:"responseCode"`
+	RetrievalReferenceNumber      string `json:"retrievalReferenceNumber"`
+	Track1                        string `json:"track1,omitempty"`
+	Track2                        string `json:"track2,omitempty"`
+	TransactionAmountLocal        string `json:"transactionAmountLocal"`
+	TransactionAmountUsd          string `json:"transactionAmountUsd,omitempty"`
+	TransactionCurrencyCode       string `json:"transactionCurrencyCode"`
+	TransactionType               string `json:"transactionType"`
+	TransmissionDateAndTime       string `json:"transmissionDateAndTime"`
+}
+
+// ClearingDetail represents the clearing details of an original transaction
+type ClearingDetail struct {
+	TransactionID                      string `json:"transactionId,omitempty"`
+	PrimaryAccountNumber               string `json:"primaryAccountNumber"`
+	AcquirerReferenceData              string `json:"acquirerReferenceData"`
+	AcquiringInstitutionIDCode         string `json:"acquiringInstitutionIdCode"`
+	ApprovalCode                       string `json:"approvalCode"`
+	CardAcceptorBusinessCode           string `json:"cardAcceptorBusinessCode"`
+	CardAcceptorCity                   string `json:"cardAcceptorCity"`
+	CardAcceptorCountry                string `json:"cardAcceptorCountry"`
+	CardAcceptorIDCode                 string `json:"cardAcceptorIdCode"`
+	CardAcceptorName                   string `json:"cardAcceptorName"`
+	CardAcceptorPostalCode             string `json:"cardAcceptorPostalCode"`
+	CardAcceptorState                  string `json:"cardAcceptorState"`
+	CardAcceptorTerminalID             string `json:"cardAcceptorTerminalId"`
+	CardDataInputCapability            string `json:"cardDataInputCapability"`
+	CardholderAuthenticationCapability string `json:"cardholderAuthenticationCapability"`
+	CardholderBillingAmount            string `json:"cardholderBillingAmount"`
+	CardholderBillingCurrencyCode      string `json:"cardholderBillingCurrencyCode"`
+	CardPresentData                    string `json:"cardPresentData"`
+	CentralSiteBusinessDate            string `json:"centralSiteBusinessDate"`
+	CurrencyExponentTransaction        string `json:"currencyExponentTransaction"`
+	FunctionCode                       string `json:"functionCode"`
+	InstallmentPaymentDataBrazil       string `json:"installmentPaymentDataBrazil,omitempty"`
+	LocalTransactionDateTime           string `json:"localTransactionDateTime"`
+	MessageReasonCode                  string `json:"messageReasonCode"`
+	MessageReversalIndicator           string `json:"messageReversalIndicator,omitempty"`
+	ProcessingCode                     string `json:"processingCode"`
+	SettlementIndicator                string `json:"settlementIndicator"`
+	TransactionAmountLocal             string `json:"transactionAmountLocal"`
+	TransactionCurrencyCode            string `json:"transactionCurrencyCode"`
+	TransactionLifeCycleID             string `json:"transactionLifeCycleId"`
+	TransactionType                    string `json:"transactionType"`
+}
+
+// Masked returns a copy of the summary for API responses, with card numbers truncated and
+// track data removed
+func (s *TransactionSummary) Masked() *TransactionSummary {
+	masked := *s
+	masked.AuthorizationSummary = make([]AuthorizationSummary, len(s.AuthorizationSummary))
+	for i, auth := range s.AuthorizationSummary {
+		auth.PrimaryAccountNumber = TruncatePAN(auth.PrimaryAccountNumber)
+		auth.Track1 = ""
+		auth.Track2 = ""
+		auth.ClearingSummary = make([]ClearingSummary, len(s.AuthorizationSummary[i].ClearingSummary))
+		for j, clearing := range s.AuthorizationSummary[i].ClearingSummary {
+			clearing.PrimaryAccountNumber = TruncatePAN(clearing.PrimaryAccountNumber)
+			auth.ClearingSummary[j] = clearing
+		}
+		masked.AuthorizationSummary[i] = auth
+	}
+	return &masked
+}
+
+// Masked returns a copy of the authorization for API responses, with the card number
+// truncated and track data removed
+func (d *AuthorizationDetail) Masked() *AuthorizationDetail {
+	masked := *d
+	masked.PrimaryAccountNumber = TruncatePAN(d.PrimaryAccountNumber)
+	masked.Track1 = ""
+	masked.Track2 = ""
+	return &masked
+}
+
+// Masked returns a copy of the clearing record for API responses, with the card number truncated
+func (d *ClearingDetail) Masked() *ClearingDetail {
+	masked := *d
+	masked.PrimaryAccountNumber = TruncatePAN(d.PrimaryAccountNumber)
+	return &masked
+}
+
+// TruncatePAN keeps the BIN and last four digits of a valid card number. Anything else that
+// is not already truncated has every digit but the last four masked.
+func TruncatePAN(number string) string {
+	switch {
+	case pan.Valid(number):
+		return pan.Truncate(number)
+	case pan.Truncated(number):
+		return number
+	default:
+		return pan.Mask(number)
+	}
+}
+
+// TransactionRecord is an authorization and its clearing records as held by a transaction source
+type TransactionRecord struct {
+	Authorization AuthorizationDetail `json:"authorization"`
+	Clearings     []ClearingDetail    `json:"clearings"`
+	// ICAs are the issuer and acquirer ICAs the transaction belongs to
+	ICAs []string `json:"icas,omitempty"`
+}
+
+// HasTransaction reports whether the authorization or a clearing record has the transaction ID
+func (r *TransactionRecord) HasTransaction(transactionID string) bool {
+	if r.Authorization.TransactionID == transactionID {
+		return true
+	}
+	for i := range r.Clearings {
+		if r.Clearings[i].TransactionID == transactionID {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionSourceConfig represents configuration for the transaction source
+type TransactionSourceConfig struct {
+	FilePath           string `json:"filePath"`
+	MaxSearchRangeDays int    `json:"maxSearchRangeDays"`
+	MaxHistoryDays     int    `json:"maxHistoryDays"`
+}
+
+// CreateCaseFromTransactionRequest represents the request to create a case from a clearing record
+type CreateCaseFromTransactionRequest struct {
+	ClearingTransactionID string  `json:"clearingTransactionId" validate:"required"`
+	CaseType              string  `json:"caseType" validate:"required"`
+	ReasonCode            string  `json:"reasonCode" validate:"required"`
+	DisputeAmount         float64 `json:"disputeAmount"`
+	DisputeCurrency       string  `json:"disputeCurrency"`
+	FilingAs              string  `json:"filingAs" validate:"required"`
+	FilingIca             string  `json:"filingIca" validate:"required"`
+	FiledAgainstIca       string  `json:"filedAgainstIca" validate:"required"`
+	FiledBy               string  `json:"filedBy"`
+	FiledByContactName    string  `json:"filedByContactName"`
+	FiledByContactPhone   string  `json:"filedByContactPhone"`
+	FiledByContactEmail   string  `json:"filedByContactEmail"`
+}
+
+// numericCurrencyCodes maps ISO 4217 numeric codes used in clearing records to alpha codes
+var numericCurrencyCodes = map[string]string{
+	"036": "AUD",
+	"124": "CAD",
+	"156": "CNY",
+	"344": "HKD",
+	"392": "JPY",
+	"458": "MYR",
+	"484": "MXN",
+	"554": "NZD",
+	"608": "PHP",
+	"702": "SGD",
+	"764": "THB",
+	"826": "GBP",
+	"840": "USD",
+	"978": "EUR",
+	"986": "BRL",
+}
+
+// AlphaCurrencyCode converts an ISO 4217 numeric currency code to its alpha code,
+// returning the input unchanged when it is not known
+func AlphaCurrencyCode(code string) string {
+	if alpha, ok := numericCurrencyCodes[code]; ok {
+		return alpha
+	}
+	return code
+}
+
+// Amount returns the transaction amount in major units using the transaction currency exponent
+func (d *ClearingDetail) Amount() (float64, error) {
+	minor, err := strconv.ParseInt(d.TransactionAmountLocal, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	exponent := 2
+	if d.CurrencyExponentTransaction != "" {
+		if exponent, err = strconv.Atoi(d.CurrencyExponentTransaction); err != nil {
+			return 0, err
+		}
+	}
+
+	return float64(minor) / math.Pow10(exponent), nil
+}
+
+// TransactionDate returns the local transaction date and time of the clearing record
+func (d *ClearingDetail) TransactionDate() (time.Time, error) {
+	return time.Parse("060102150405", d.LocalTransactionDateTime)
+}
+
+// NewCaseRequestFromClearing builds a create case request, copying the transaction
+// fields from a clearing record
+func NewCaseRequestFromClearing(req *CreateCaseFromTransactionRequest, clearing *ClearingDetail) (*CreateCaseRequest, error) {
+	amount, err := clearing.Amount()
+	if err != nil {
+		return nil, err
+	}
+
+	transactionDate, err := clearing.TransactionDate()
+	if err != nil {
+		return nil, err
+	}
+
+	currency := AlphaCurrencyCode(clearing.TransactionCurrencyCode)
+	disputeAmount := req.DisputeAmount
+	disputeCurrency := req.DisputeCurrency
+	if disputeAmount == 0 {
+		disputeAmount = amount
+	}
+	if disputeCurrency == "" {
+		disputeCurrency = currency
+	}
+
+	return &CreateCaseRequest{
+		CaseType:             req.CaseType,
+		PrimaryAccountNumber: clearing.PrimaryAccountNumber,
+		TransactionAmount:    amount,
+		TransactionCurrency:  currency,
+		TransactionDate:      transactionDate,
+		TransactionID:        req.ClearingTransactionID,
+		MerchantName:         clearing.CardAcceptorName,
+		MerchantCategoryCode: clearing.CardAcceptorBusinessCode,
+		ReasonCode:           req.ReasonCode,
+		DisputeAmount:        disputeAmount,
+		DisputeCurrency:      disputeCurrency,
+		FilingAs:             req.FilingAs,
+		FilingIca:            req.FilingIca,
+		FiledAgainstIca:      req.FiledAgainstIca,
+		FiledBy:              req.FiledBy,
+		FiledByContactName:   req.FiledByContactName,
+		FiledByContactPhone:  req.FiledByContactPhone,
+		FiledByContactEmail:  req.FiledByContactEmail,
+	}, nil
+}
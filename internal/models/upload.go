@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Upload is a resumable upload in progress. Its content is sent in chunks and becomes a
+// document once the upload is finalized.
+type Upload struct {
+	ID          string `json:"id"`
+	CaseID      string `json:"caseId"`
+	FileName    string `json:"fileName"`
+	UploadedBy  string `json:"uploadedBy"`
+	Description string `json:"description"`
+	// Length is the size of the complete file; Offset is how much of it has been received
+	Length    int64     `json:"length"`
+	Offset    int64     `json:"offset"`
+	Content   []byte    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt is when the upload is discarded unless more of it arrives
+	ExpiresAt time.Time `json:"expiresAt"`
+	// DocumentID and Checksum are set once the upload has been finalized into a document
+	DocumentID string `json:"documentId,omitempty"`
+	Checksum   string `json:"checksum,omitempty"`
+}
+
+// FinalizeUploadRequest represents the request to turn a complete upload into a document
+type FinalizeUploadRequest struct {
+	// Checksum is the SHA-256 of the whole file, as sha256:<hex>
+	Checksum string `json:"checksum" binding:"required"`
+}
+
+// UploadConfig represents configuration for resumable uploads
+type UploadConfig struct {
+	// Expiry is how long an upload is kept after its last chunk, in seconds
+	Expiry int `json:"expiry"`
+	// CleanupInterval is the time between sweeps for expired uploads, in seconds
+	CleanupInterval int `json:"cleanupInterval"`
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// apiKeyPrefix starts every API key the service issues
+const apiKeyPrefix = "mcs_"
+
+var (
+	// ErrAPIKeyNotFound is returned for an API key ID the service does not hold
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrAPIKeyExists is returned when creating an API key with the name of a key in use
+	ErrAPIKeyExists = errors.New("an API key with this name already exists")
+)
+
+// APIKeyService manages the API keys callers authenticate with. Keys are held in memory by
+// their SHA-256 hash, so a key cannot be recovered from the service.
+type APIKeyService struct {
+	keys map[string]*models.APIKey
+	// hashes maps the hash of each key to its ID
+	hashes map[string]string
+	// lastUsed holds when each key last authenticated, in Unix nanoseconds by key ID, so that
+	// authenticating needs only the read lock
+	lastUsed map[string]*atomic.Int64
+	mutex    sync.RWMutex
+	logger   *logger.DatadogLogger
+}
+
+// NewAPIKeyService creates an API key service holding no keys
+func NewAPIKeyService(logger *logger.DatadogLogger) *APIKeyService {
+	return &APIKeyService{
+		keys:     make(map[string]*models.APIKey),
+		hashes:   make(map[string]string),
+		lastUsed: make(map[string]*atomic.Int64),
+		logger:   logger,
+	}
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash under which an API key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// AddAPIKeys adds API keys configured by their hash, such as those bootstrapping the service
+func (s *APIKeyService) AddAPIKeys(apiKeys []models.APIKey) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, apiKey := range apiKeys {
+		if err := s.checkName(apiKey.Name); err != nil {
+			return err
+		}
+		if _, exists := s.hashes[apiKey.Hash]; exists {
+			return fmt.Errorf("%w: %s", ErrAPIKeyExists, apiKey.Name)
+		}
+		apiKey.ID = uuid.New().String()
+		apiKey.CreatedAt = time.Now()
+		apiKey.CreatedBy = "configuration"
+		s.keys[apiKey.ID] = &apiKey
+		s.hashes[apiKey.Hash] = apiKey.ID
+		s.lastUsed[apiKey.ID] = new(atomic.Int64)
+	}
+	return nil
+}
+
+// CreateAPIKey issues a new API key holding roles, acting for icas. The key is returned
+// alongside its record, and is not kept.
+func (s *APIKeyService) CreateAPIKey(name string, roles, icas []string, createdBy string) (*models.APIKey, string, error) {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.checkName(name); err != nil {
+		return nil, "", err
+	}
+	apiKey := &models.APIKey{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Prefix:    key[:len(apiKeyPrefix)+6],
+		Hash:      HashAPIKey(key),
+		Roles:     append([]string(nil), roles...),
+		ICAs:      append([]string(nil), icas...),
+		CreatedAt: time.Now(),
+		CreatedBy: createdBy,
+	}
+	s.keys[apiKey.ID] = apiKey
+	s.hashes[apiKey.Hash] = apiKey.ID
+	s.lastUsed[apiKey.ID] = new(atomic.Int64)
+
+	s.logger.Info("API key created", logrus.Fields{
+		"apiKeyId":  apiKey.ID,
+		"name":      name,
+		"roles":     roles,
+		"icas":      icas,
+		"createdBy": createdBy,
+	})
+	return s.copyAPIKey(apiKey), key, nil
+}
+
+// checkName returns ErrAPIKeyExists when a key in use has name. The caller must hold the mutex.
+func (s *APIKeyService) checkName(name string) error {
+	for _, apiKey := range s.keys {
+		if apiKey.Name == name && !apiKey.Revoked() {
+			return fmt.Errorf("%w: %s", ErrAPIKeyExists, name)
+		}
+	}
+	return nil
+}
+
+// ListAPIKeys returns every API key, revoked ones included, oldest first
+func (s *APIKeyService) ListAPIKeys() []*models.APIKey {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	apiKeys := make([]*models.APIKey, 0, len(s.keys))
+	for _, apiKey := range s.keys {
+		apiKeys = append(apiKeys, s.copyAPIKey(apiKey))
+	}
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].CreatedAt.Before(apiKeys[j].CreatedAt)
+	})
+	return apiKeys
+}
+
+// RevokeAPIKey revokes an API key, which then no longer authenticates. Revoking a key again
+// leaves it as it was.
+func (s *APIKeyService) RevokeAPIKey(id, revokedBy string) (*models.APIKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	apiKey, exists := s.keys[id]
+	if !exists {
+		return nil, ErrAPIKeyNotFound
+	}
+	if !apiKey.Revoked() {
+		now := time.Now()
+		apiKey.RevokedAt = &now
+		apiKey.RevokedBy = revokedBy
+		s.logger.Info("API key revoked", logrus.Fields{
+			"apiKeyId":  id,
+			"name":      apiKey.Name,
+			"revokedBy": revokedBy,
+		})
+	}
+
+	return s.copyAPIKey(apiKey), nil
+}
+
+// Authenticate returns the API key that key is, or false when it is unknown or revoked
+func (s *APIKeyService) Authenticate(key string) (*models.APIKey, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	id := s.hashes[HashAPIKey(key)]
+	apiKey, exists := s.keys[id]
+	if !exists || apiKey.Revoked() {
+		return nil, false
+	}
+	s.lastUsed[id].Store(time.Now().UnixNano())
+
+	return s.copyAPIKey(apiKey), true
+}
+
+// copyAPIKey returns a copy of apiKey with the time it was last used. The caller must hold
+// the mutex.
+func (s *APIKeyService) copyAPIKey(apiKey *models.APIKey) *models.APIKey {
+	copied := *apiKey
+	if lastUsed := s.lastUsed[apiKey.ID].Load(); lastUsed != 0 {
+		usedAt := time.Unix(0, lastUsed)
+		copied.LastUsedAt = &usedAt
+	}
+	return &copied
+}
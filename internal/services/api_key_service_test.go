@@ -0,0 +1,93 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_CreateAuthenticateRevoke(t *testing.T) {
+	service := NewAPIKeyService(logger.NewDatadogLogger())
+
+	apiKey, key, err := service.CreateAPIKey("ethoca", []string{"analyst"}, []string{"1234"}, "admin-1")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, apiKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, apiKey.Prefix))
+	assert.Equal(t, HashAPIKey(key), apiKey.Hash)
+	assert.Equal(t, "admin-1", apiKey.CreatedBy)
+
+	// Only the hash is kept
+	for _, stored := range service.keys {
+		assert.NotContains(t, stored.Hash, key)
+	}
+
+	authenticated, ok := service.Authenticate(key)
+	require.True(t, ok)
+	assert.Equal(t, apiKey.ID, authenticated.ID)
+	assert.Equal(t, []string{"analyst"}, authenticated.Roles)
+	assert.Equal(t, []string{"1234"}, authenticated.ICAs)
+	require.NotNil(t, authenticated.LastUsedAt)
+	listed := service.ListAPIKeys()
+	require.Len(t, listed, 1)
+	assert.Equal(t, *authenticated.LastUsedAt, *listed[0].LastUsedAt)
+	_, ok = service.Authenticate(key + "x")
+	assert.False(t, ok)
+
+	_, _, err = service.CreateAPIKey("ethoca", []string{"viewer"}, []string{"1234"}, "admin-1")
+	assert.ErrorIs(t, err, ErrAPIKeyExists)
+
+	revoked, err := service.RevokeAPIKey(apiKey.ID, "admin-2")
+	require.NoError(t, err)
+	assert.True(t, revoked.Revoked())
+	assert.Equal(t, "admin-2", revoked.RevokedBy)
+	_, ok = service.Authenticate(key)
+	assert.False(t, ok)
+
+	// The name of a revoked key can be reused
+	_, _, err = service.CreateAPIKey("ethoca", []string{"viewer"}, []string{"1234"}, "admin-1")
+	require.NoError(t, err)
+	assert.Len(t, service.ListAPIKeys(), 2)
+
+	_, err = service.RevokeAPIKey("missing", "admin-2")
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyService_ConcurrentAuthenticate(t *testing.T) {
+	service := NewAPIKeyService(logger.NewDatadogLogger())
+	apiKey, key, err := service.CreateAPIKey("ethoca", []string{"analyst"}, []string{"1234"}, "admin-1")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				authenticated, ok := service.Authenticate(key)
+				assert.True(t, ok)
+				assert.Equal(t, apiKey.ID, authenticated.ID)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.NotNil(t, service.ListAPIKeys()[0].LastUsedAt)
+}
+
+func TestAPIKeyService_AddAPIKeys(t *testing.T) {
+	service := NewAPIKeyService(logger.NewDatadogLogger())
+	require.NoError(t, service.AddAPIKeys([]models.APIKey{{Name: "bootstrap", Hash: HashAPIKey("secret"), Roles: []string{"admin"}}}))
+
+	apiKey, ok := service.Authenticate("secret")
+	require.True(t, ok)
+	assert.Equal(t, "bootstrap", apiKey.Name)
+	assert.Equal(t, "configuration", apiKey.CreatedBy)
+
+	err := service.AddAPIKeys([]models.APIKey{{Name: "other", Hash: HashAPIKey("secret"), Roles: []string{"viewer"}}})
+	assert.ErrorIs(t, err, ErrAPIKeyExists)
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"time"
+
+	"mastercom-service/internal/models"
+)
+
+// RecordCaseAudit adds an entry to a case's audit history
+func (s *CaseService) RecordCaseAudit(caseID, action string, details map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.recordAudit(caseID, action, details)
+}
+
+// GetCaseAudit returns a case's audit history, oldest first. The history of a deleted case
+// is kept.
+func (s *CaseService) GetCaseAudit(caseID string) ([]models.CaseAuditEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entries, exists := s.audit[caseID]
+	if !exists {
+		if _, exists := s.cases[caseID]; !exists {
+			return nil, ErrCaseNotFound
+		}
+	}
+	return append([]models.CaseAuditEntry{}, entries...), nil
+}
+
+// recordAudit adds an audit entry; the caller must hold the mutex
+func (s *CaseService) recordAudit(caseID, action string, details map[string]string) {
+	s.audit[caseID] = append(s.audit[caseID], models.CaseAuditEntry{
+		Timestamp: time.Now(),
+		Action:    action,
+		Details:   details,
+	})
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaseService_Audit(t *testing.T) {
+	service := setupCaseService()
+	caseObj := createMockCase()
+	caseObj.QueueName = models.QueueWorked
+	require.NoError(t, service.CreateCase(caseObj))
+
+	_, err := service.TransitionCase(caseObj.ID, models.QueueActionSubmit)
+	require.NoError(t, err)
+	retryAt := time.Now().Add(time.Minute)
+	_, err = service.RecordSubmissionFailure(caseObj.ID, models.SubmissionStatusFailed, []models.SubmissionError{{Description: "Service unavailable"}}, &retryAt)
+	require.NoError(t, err)
+	_, err = service.MarkCaseSubmitted(caseObj.ID, "MC-1001")
+	require.NoError(t, err)
+	_, err = service.UpdateMastercomStatus("MC-1001", "OPEN", "")
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteCase(caseObj.ID))
+
+	// The history outlives the case
+	audit, err := service.GetCaseAudit(caseObj.ID)
+	require.NoError(t, err)
+
+	actions := make([]string, 0, len(audit))
+	for _, entry := range audit {
+		actions = append(actions, entry.Action)
+	}
+	assert.Equal(t, []string{
+		models.CaseAuditCreated,
+		models.CaseAuditQueueTransition,
+		models.CaseAuditSubmissionFailed,
+		models.CaseAuditSubmitted,
+		models.CaseAuditMastercomStatus,
+		models.CaseAuditDeleted,
+	}, actions)
+	assert.Equal(t, map[string]string{"action": string(models.QueueActionSubmit), "from": models.QueueWorked, "to": models.QueueSubmitted}, audit[1].Details)
+	assert.Equal(t, "Service unavailable", audit[2].Details["error"])
+	assert.Equal(t, "MC-1001", audit[3].Details["mastercomCaseId"])
+
+	_, err = service.GetCaseAudit("nonexistent-id")
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCaseHasDocuments is returned when deleting a case that still has documents without cascading
+var ErrCaseHasDocuments = errors.New("case has documents")
+
+// CaseDocumentService attaches documents to cases, keeping the case and document stores consistent
+type CaseDocumentService struct {
+	caseService     *CaseService
+	documentService *DocumentService
+	logger          *logger.DatadogLogger
+}
+
+// NewCaseDocumentService creates a new case document service
+func NewCaseDocumentService(caseService *CaseService, documentService *DocumentService, logger *logger.DatadogLogger) *CaseDocumentService {
+	return &CaseDocumentService{
+		caseService:     caseService,
+		documentService: documentService,
+		logger:          logger,
+	}
+}
+
+// AttachDocument stores a document against an existing case
+func (s *CaseDocumentService) AttachDocument(caseID string, document *models.Document) error {
+	if _, err := s.caseService.GetCase(caseID); err != nil {
+		return err
+	}
+
+	document.CaseID = caseID
+	if err := s.documentService.UploadDocument(document); err != nil {
+		return err
+	}
+
+	if _, err := s.syncCaseDocuments(caseID); err != nil {
+		return err
+	}
+	s.caseService.RecordCaseAudit(caseID, models.CaseAuditDocumentAttached, map[string]string{
+		"documentId": document.ID,
+		"fileName":   document.FileName,
+		"sha256":     document.SHA256,
+		"status":     document.ProcessingStatus,
+	})
+
+	s.logger.Info("Document attached to case", logrus.Fields{
+		"caseId":     caseID,
+		"documentId": document.ID,
+	})
+	return nil
+}
+
+// ListDocuments returns a case's documents, oldest first
+func (s *CaseDocumentService) ListDocuments(caseID string) ([]*models.Document, error) {
+	if _, err := s.caseService.GetCase(caseID); err != nil {
+		return nil, err
+	}
+
+	return s.syncCaseDocuments(caseID)
+}
+
+// DeleteCase deletes a case. A case with documents is only deleted when cascade is set,
+// in which case its documents are deleted with it. A case on legal hold is not deleted.
+func (s *CaseDocumentService) DeleteCase(caseID string, cascade bool) error {
+	caseObj, err := s.caseService.GetCase(caseID)
+	if err != nil {
+		return err
+	}
+	if caseObj.LegalHold != nil {
+		return ErrCaseOnLegalHold
+	}
+
+	documents, err := s.documentService.GetDocumentsByCaseID(caseID)
+	if err != nil {
+		return err
+	}
+	if len(documents) > 0 && !cascade {
+		return fmt.Errorf("%w: %d attached", ErrCaseHasDocuments, len(documents))
+	}
+
+	for _, document := range documents {
+		if err := s.documentService.DeleteDocument(document.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.caseService.DeleteCase(caseID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Case deleted with documents", logrus.Fields{
+		"caseId":        caseID,
+		"documentCount": len(documents),
+	})
+	return nil
+}
+
+// syncCaseDocuments refreshes the case's document list from the document store
+func (s *CaseDocumentService) syncCaseDocuments(caseID string) ([]*models.Document, error) {
+	documents, err := s.documentService.GetDocumentsByCaseID(caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].UploadedAt.Before(documents[j].UploadedAt)
+	})
+
+	if err := s.caseService.SetCaseDocuments(caseID, documents); err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// OriginalAccessAllowed reports whether a caller holding roles may retrieve the original
+// content of redacted documents
+func (s *CaseDocumentService) OriginalAccessAllowed(roles []string) bool {
+	return s.documentService.OriginalAccessAllowed(roles)
+}
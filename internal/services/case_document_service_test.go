@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCaseDocumentService() (*CaseDocumentService, *CaseService, *DocumentService) {
+	logger := logger.NewDatadogLogger()
+	caseService := NewCaseService(logger)
+	documentService := NewDocumentService(logger)
+	return NewCaseDocumentService(caseService, documentService, logger), caseService, documentService
+}
+
+func TestCaseDocumentService_AttachDocument(t *testing.T) {
+	service, caseService, documentService := setupCaseDocumentService()
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	document := models.NewDocument("ignored", "evidence.pdf", ".pdf", []byte("content"), "test-user", "")
+	require.NoError(t, service.AttachDocument(caseObj.ID, document))
+	assert.Equal(t, caseObj.ID, document.CaseID)
+
+	// The document is stored and listed on the case without its content
+	_, err := documentService.GetDocument(document.ID)
+	require.NoError(t, err)
+
+	retrieved, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	require.Len(t, retrieved.Documents, 1)
+	assert.Equal(t, document.ID, retrieved.Documents[0].ID)
+	assert.Nil(t, retrieved.Documents[0].Content)
+
+	documents, err := service.ListDocuments(caseObj.ID)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+
+	// Unknown case
+	err = service.AttachDocument("nonexistent-id", models.NewDocument("", "a.pdf", ".pdf", []byte("a"), "", ""))
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+
+	_, err = service.ListDocuments("nonexistent-id")
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+}
+
+func TestCaseDocumentService_DeleteCase(t *testing.T) {
+	service, caseService, documentService := setupCaseDocumentService()
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	document := models.NewDocument(caseObj.ID, "evidence.pdf", ".pdf", []byte("content"), "test-user", "")
+	require.NoError(t, service.AttachDocument(caseObj.ID, document))
+
+	// Blocked while documents are attached
+	err := service.DeleteCase(caseObj.ID, false)
+	assert.ErrorIs(t, err, ErrCaseHasDocuments)
+	_, err = caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+
+	// Cascade removes the documents with the case
+	require.NoError(t, service.DeleteCase(caseObj.ID, true))
+	_, err = caseService.GetCase(caseObj.ID)
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+	_, err = documentService.GetDocument(document.ID)
+	assert.Error(t, err)
+
+	err = service.DeleteCase(caseObj.ID, true)
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+}
+
+func TestCaseDocumentService_DeleteCase_NoDocuments(t *testing.T) {
+	service, caseService, _ := setupCaseDocumentService()
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	require.NoError(t, service.DeleteCase(caseObj.ID, false))
+	_, err := caseService.GetCase(caseObj.ID)
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/envelope"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EnableEncryption has the case fields tagged encrypt, such as contact details, stored
+// encrypted under keys from now on. It is called before any case is created.
+func (s *CaseService) EnableEncryption(keys *envelope.KeyRing) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.keys = keys
+}
+
+// RewrapKeys re-wraps the data keys of the case fields stored encrypted with the primary key
+// of the key ring, and returns the number re-wrapped
+func (s *CaseService) RewrapKeys() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rewrapped := 0
+	for caseID, sealed := range s.sealed {
+		count, err := s.keys.RewrapFields(sealed)
+		rewrapped += count
+		if err != nil {
+			return rewrapped, fmt.Errorf("re-wrapping fields of case %s: %w", caseID, err)
+		}
+	}
+	return rewrapped, nil
+}
+
+// sealCase returns the case to store: without encryption the case itself, and otherwise a
+// copy whose tagged fields are cleared and kept sealed in s.sealed. The caller must hold
+// the mutex.
+func (s *CaseService) sealCase(caseObj *models.Case) *models.Case {
+	if s.keys == nil {
+		return caseObj
+	}
+	stored := *caseObj
+	s.sealed[caseObj.ID] = s.keys.SealFields(&stored, []byte(caseObj.ID))
+	return &stored
+}
+
+// openCase returns a stored case as callers see it: a copy with its sealed fields decrypted,
+// or the case itself when none are sealed. Fields that cannot be decrypted, as when the key
+// that wrapped them is no longer configured, are left empty and the failure is logged. The
+// caller must hold the mutex.
+func (s *CaseService) openCase(caseObj *models.Case) *models.Case {
+	sealed := s.sealed[caseObj.ID]
+	if len(sealed) == 0 {
+		return caseObj
+	}
+	opened := *caseObj
+	if err := s.keys.OpenFields(&opened, sealed, []byte(caseObj.ID)); err != nil {
+		s.logger.Error("Case fields could not be decrypted", logrus.Fields{
+			"caseId": caseObj.ID,
+			"error":  err.Error(),
+		})
+	}
+	return &opened
+}
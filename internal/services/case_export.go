@@ -0,0 +1,201 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"mastercom-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CaseExport is everything about one case, ready to be written as a ZIP archive
+type CaseExport struct {
+	caseObj    models.Case
+	audit      []models.CaseAuditEntry
+	documents  []*models.Document
+	omitted    []models.CaseExportOmission
+	exportedAt time.Time
+	// originals is set when documents are exported without their card numbers masked
+	originals bool
+}
+
+// PrepareCaseExport gathers a case, its audit history and its documents for export. Every
+// document's content is checked against its checksum here, so that a failure is reported
+// before any of the archive is written. Quarantined documents and documents without content
+// are listed in the manifest as omitted. Documents are exported with their card numbers
+// masked unless originals is set; documents not yet redacted, or that could not be, are
+// then omitted too.
+func (s *CaseDocumentService) PrepareCaseExport(caseID string, originals bool) (*CaseExport, error) {
+	if _, err := s.caseService.GetCase(caseID); err != nil {
+		return nil, err
+	}
+	documents, err := s.syncCaseDocuments(caseID)
+	if err != nil {
+		return nil, err
+	}
+	s.caseService.RecordCaseAudit(caseID, models.CaseAuditExported, map[string]string{
+		"documents": fmt.Sprint(len(documents)),
+		"originals": strconv.FormatBool(originals),
+	})
+
+	caseObj, err := s.caseService.GetCase(caseID)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := s.caseService.GetCaseAudit(caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &CaseExport{caseObj: *caseObj, audit: audit, exportedAt: time.Now().UTC(), originals: originals}
+	for _, document := range documents {
+		redaction := document.Redaction
+		switch {
+		case document.ProcessingStatus == models.DocumentStatusQuarantined:
+			export.omit(document, "Awaiting malware scan")
+		case document.Content == nil:
+			export.omit(document, "Content discarded: "+document.StatusReason)
+		case !originals && redaction != nil && redaction.Status == models.RedactionStatusPending:
+			export.omit(document, "Awaiting card number redaction")
+		case !originals && redaction != nil && redaction.Status == models.RedactionStatusFailed:
+			export.omit(document, "Could not be checked for card numbers")
+		default:
+			if err := s.documentService.VerifyContent(document); err != nil {
+				return nil, err
+			}
+			if !originals {
+				document = document.Redacted()
+			}
+			export.documents = append(export.documents, document)
+		}
+	}
+
+	s.logger.Info("Case export prepared", logrus.Fields{
+		"caseId":        caseID,
+		"documentCount": len(export.documents),
+		"omittedCount":  len(export.omitted),
+	})
+	return export, nil
+}
+
+// CaseID returns the ID of the exported case
+func (e *CaseExport) CaseID() string {
+	return e.caseObj.ID
+}
+
+// Write streams the archive to w: case.json, audit.json, each document under documents/ by
+// its original file name, and a manifest.json listing every file with its SHA-256 checksum.
+// Nothing is buffered beyond the file being written.
+func (e *CaseExport) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+	manifest := models.CaseExportManifest{
+		CaseID:     e.caseObj.ID,
+		ExportedAt: e.exportedAt,
+		Files:      []models.CaseExportFile{},
+		Omitted:    e.omitted,
+	}
+
+	add := func(file models.CaseExportFile, method uint16, write func(io.Writer) error) error {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: file.Path, Method: method, Modified: e.exportedAt})
+		if err != nil {
+			return err
+		}
+		hash := sha256.New()
+		counter := &countingWriter{}
+		if err := write(io.MultiWriter(entry, hash, counter)); err != nil {
+			return fmt.Errorf("writing %s: %w", file.Path, err)
+		}
+		file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+		file.Size = counter.n
+		manifest.Files = append(manifest.Files, file)
+		return nil
+	}
+
+	if err := add(models.CaseExportFile{Path: "case.json"}, zip.Deflate, jsonContent(e.caseObj)); err != nil {
+		return err
+	}
+	if err := add(models.CaseExportFile{Path: "audit.json"}, zip.Deflate, jsonContent(e.audit)); err != nil {
+		return err
+	}
+
+	used := make(map[string]bool, len(e.documents))
+	for _, document := range e.documents {
+		file := models.CaseExportFile{
+			Path:       "documents/" + exportFileName(document.FileName, document.ID, used),
+			DocumentID: document.ID,
+			Version:    document.Version,
+			FileName:   document.FileName,
+			Redacted:   !e.originals && document.Redaction != nil && document.Redaction.Status == models.RedactionStatusRedacted,
+		}
+		// Evidence files are already compressed
+		content := document.Content
+		if err := add(file, zip.Store, func(w io.Writer) error {
+			_, err := w.Write(content)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: e.exportedAt})
+	if err != nil {
+		return err
+	}
+	if err := jsonContent(manifest)(entry); err != nil {
+		return fmt.Errorf("writing manifest.json: %w", err)
+	}
+	return archive.Close()
+}
+
+func (e *CaseExport) omit(document *models.Document, reason string) {
+	e.omitted = append(e.omitted, models.CaseExportOmission{
+		DocumentID: document.ID,
+		FileName:   document.FileName,
+		Reason:     reason,
+	})
+}
+
+// exportFileName returns a document's file name without any directory, made unique within
+// the archive regardless of case by numbering repeats: receipt.pdf, receipt (2).pdf, ...
+func exportFileName(fileName, documentID string, used map[string]bool) string {
+	name := path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		name = documentID
+	}
+
+	extension := path.Ext(name)
+	stem := strings.TrimSuffix(name, extension)
+	candidate := name
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", stem, i, extension)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func jsonContent(value interface{}) func(io.Writer) error {
+	return func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
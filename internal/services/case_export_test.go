@@ -0,0 +1,168 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"mastercom-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readExportArchive returns the content of every file in an export archive, by path
+func readExportArchive(t *testing.T, archive []byte) (map[string][]byte, []string) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	var names []string
+	for _, file := range reader.File {
+		opened, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(opened)
+		require.NoError(t, err)
+		opened.Close()
+		files[file.Name] = content
+		names = append(names, file.Name)
+	}
+	return files, names
+}
+
+func TestCaseDocumentService_ExportCase(t *testing.T) {
+	service, caseService, documentService := setupCaseDocumentService()
+	scanner := &fakeScanner{}
+	documentService.EnableScanning(scanner, &models.ScanConfig{MaxAttempts: 3})
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	receipt := attachBundleSource(t, service, caseObj.ID, "receipt.pdf", "application/pdf", bundlePDF(1), 0)
+	attachBundleSource(t, service, caseObj.ID, "Receipt.PDF", "application/pdf", bundlePDF(2), 1)
+	attachBundleSource(t, service, caseObj.ID, `..\scans\receipt.pdf`, "application/pdf", bundlePDF(3), 2)
+	attachBundleSource(t, service, caseObj.ID, "", "application/pdf", bundlePDF(4), 3)
+	infected := models.NewDocument(caseObj.ID, "virus.pdf", "application/pdf", []byte("EICAR"), "test-user", "")
+	require.NoError(t, service.AttachDocument(caseObj.ID, infected))
+	documentService.RescanQuarantined(context.Background())
+	scanner.setErr(errors.New("clamd: connection refused"))
+	pending := attachBundleSource(t, service, caseObj.ID, "pending.pdf", "application/pdf", bundlePDF(5), 4)
+
+	export, err := service.PrepareCaseExport(caseObj.ID, false)
+	require.NoError(t, err)
+	var archive bytes.Buffer
+	require.NoError(t, export.Write(&archive))
+
+	files, names := readExportArchive(t, archive.Bytes())
+	var manifest models.CaseExportManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Equal(t, caseObj.ID, manifest.CaseID)
+
+	// Original names, made unique and stripped of directories
+	require.Len(t, manifest.Files, 6)
+	assert.Equal(t, "case.json", manifest.Files[0].Path)
+	assert.Equal(t, "audit.json", manifest.Files[1].Path)
+	assert.Equal(t, "documents/receipt.pdf", manifest.Files[2].Path)
+	assert.Equal(t, "documents/Receipt (2).PDF", manifest.Files[3].Path)
+	assert.Equal(t, "documents/receipt (3).pdf", manifest.Files[4].Path)
+	assert.Equal(t, receipt.ID, manifest.Files[2].DocumentID)
+	assert.Equal(t, 1, manifest.Files[2].Version)
+	assert.Equal(t, `..\scans\receipt.pdf`, manifest.Files[4].FileName)
+	assert.Equal(t, "documents/"+manifest.Files[5].DocumentID, manifest.Files[5].Path)
+	assert.Equal(t, "manifest.json", names[len(names)-1])
+
+	// Every checksum matches the file in the archive
+	for _, file := range manifest.Files {
+		sum := sha256.Sum256(files[file.Path])
+		assert.Equal(t, hex.EncodeToString(sum[:]), file.SHA256, file.Path)
+		assert.Equal(t, int64(len(files[file.Path])), file.Size, file.Path)
+	}
+	assert.Equal(t, bundlePDF(1), files["documents/receipt.pdf"])
+	assert.Equal(t, receipt.SHA256, manifest.Files[2].SHA256)
+
+	// Documents without exportable content are listed as omitted
+	require.Len(t, manifest.Omitted, 2)
+	omitted := map[string]string{}
+	for _, omission := range manifest.Omitted {
+		omitted[omission.DocumentID] = omission.Reason
+	}
+	assert.Equal(t, "Content discarded: Malware detected: Eicar-Test-Signature", omitted[infected.ID])
+	assert.Equal(t, "Awaiting malware scan", omitted[pending.ID])
+
+	var exportedCase models.Case
+	require.NoError(t, json.Unmarshal(files["case.json"], &exportedCase))
+	assert.Equal(t, caseObj.ID, exportedCase.ID)
+	assert.Len(t, exportedCase.Documents, 6)
+
+	var audit []models.CaseAuditEntry
+	require.NoError(t, json.Unmarshal(files["audit.json"], &audit))
+	assert.Equal(t, models.CaseAuditCreated, audit[0].Action)
+	assert.Equal(t, models.CaseAuditDocumentAttached, audit[1].Action)
+	assert.Equal(t, models.CaseAuditExported, audit[len(audit)-1].Action)
+}
+
+func TestCaseDocumentService_ExportCase_Errors(t *testing.T) {
+	service, caseService, _ := setupCaseDocumentService()
+
+	_, err := service.PrepareCaseExport("nonexistent-id", false)
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+
+	// Altered content is never exported
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+	document := attachBundleSource(t, service, caseObj.ID, "receipt.pdf", "application/pdf", bundlePDF(1), 0)
+	document.Content[0] = 'X'
+
+	_, err = service.PrepareCaseExport(caseObj.ID, false)
+	assert.ErrorIs(t, err, ErrDocumentIntegrity)
+}
+
+func TestCaseDocumentService_ExportCase_Redacted(t *testing.T) {
+	service, caseService, documentService := setupCaseDocumentService()
+	documentService.EnableRedaction(&models.RedactionConfig{Enabled: true})
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	receipt := attachBundleSource(t, service, caseObj.ID, "receipt.pdf", "application/pdf", textLinePDF("Card 4111111111111111"), 0)
+	unreadable := attachBundleSource(t, service, caseObj.ID, "scan.pdf", "application/pdf", []byte("%PDF-1.4 no objects"), 1)
+
+	// Card numbers are masked by default, and unreadable documents left out
+	export, err := service.PrepareCaseExport(caseObj.ID, false)
+	require.NoError(t, err)
+	var archive bytes.Buffer
+	require.NoError(t, export.Write(&archive))
+	files, _ := readExportArchive(t, archive.Bytes())
+	var manifest models.CaseExportManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+
+	require.Len(t, manifest.Files, 3)
+	assert.True(t, manifest.Files[2].Redacted)
+	assert.Equal(t, receipt.Redaction.SHA256, manifest.Files[2].SHA256)
+	assert.Equal(t, receipt.RedactedContent, files["documents/receipt.pdf"])
+	require.Len(t, manifest.Omitted, 1)
+	assert.Equal(t, unreadable.ID, manifest.Omitted[0].DocumentID)
+	assert.Equal(t, "Could not be checked for card numbers", manifest.Omitted[0].Reason)
+
+	// Originals include everything as uploaded
+	export, err = service.PrepareCaseExport(caseObj.ID, true)
+	require.NoError(t, err)
+	archive.Reset()
+	require.NoError(t, export.Write(&archive))
+	files, _ = readExportArchive(t, archive.Bytes())
+	var originals models.CaseExportManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &originals))
+
+	require.Len(t, originals.Files, 4)
+	assert.False(t, originals.Files[2].Redacted)
+	assert.Equal(t, receipt.Content, files["documents/receipt.pdf"])
+	assert.Empty(t, originals.Omitted)
+
+	audit, err := caseService.GetCaseAudit(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "true", audit[len(audit)-1].Details["originals"])
+}
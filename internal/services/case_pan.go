@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/pan"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInvalidPAN is returned for a case whose card number is not a valid primary account number
+var ErrInvalidPAN = errors.New("invalid primary account number")
+
+// UseVault replaces the vault card numbers are tokenized in. Cases keep the tokens of the
+// vault they were stored with, so it is called before any case is created.
+func (s *CaseService) UseVault(vault pan.Vault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.vault = vault
+}
+
+// DetokenizePAN returns a case's full card number from the vault, recording the access with
+// details in the case's audit history
+func (s *CaseService) DetokenizePAN(ctx context.Context, caseID string, details map[string]string) (string, error) {
+	s.mutex.RLock()
+	caseObj, exists := s.cases[caseID]
+	var token string
+	if exists {
+		token = caseObj.PANToken
+	}
+	vault := s.vault
+	s.mutex.RUnlock()
+	if !exists {
+		return "", ErrCaseNotFound
+	}
+
+	number, err := vault.Detokenize(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("detokenizing card number of case %s: %w", caseID, err)
+	}
+
+	s.RecordCaseAudit(caseID, models.CaseAuditPANDetokenized, details)
+	s.logger.Info("Case card number detokenized", logrus.Fields{"caseId": caseID, "details": details})
+	return number, nil
+}
+
+// tokenizePAN exchanges a case's card number for a vault token, keeping its BIN and last
+// four digits and leaving PrimaryAccountNumber truncated to those. A card number that is
+// already truncated is left for keepPAN to match against the stored case.
+func (s *CaseService) tokenizePAN(caseObj *models.Case) error {
+	number := caseObj.PrimaryAccountNumber
+	if pan.Truncated(number) {
+		return nil
+	}
+	if !pan.Valid(number) {
+		return ErrInvalidPAN
+	}
+
+	s.mutex.RLock()
+	vault := s.vault
+	s.mutex.RUnlock()
+	token, err := vault.Tokenize(context.Background(), number)
+	if err != nil {
+		return fmt.Errorf("tokenizing card number: %w", err)
+	}
+
+	caseObj.PANToken = token
+	caseObj.PANBIN = pan.BIN(number)
+	caseObj.PANLast4 = pan.Last4(number)
+	caseObj.PrimaryAccountNumber = pan.Truncate(number)
+	return nil
+}
+
+// keepPAN carries the token of the stored case over to an update that left its truncated
+// card number unchanged
+func keepPAN(caseObj, existing *models.Case) error {
+	if caseObj.PANToken != "" {
+		return nil
+	}
+	if caseObj.PrimaryAccountNumber != existing.PrimaryAccountNumber {
+		return ErrInvalidPAN
+	}
+	caseObj.PANToken = existing.PANToken
+	caseObj.PANBIN = existing.PANBIN
+	caseObj.PANLast4 = existing.PANLast4
+	return nil
+}
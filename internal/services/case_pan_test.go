@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/pan"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingVault is a vault that cannot be reached
+type failingVault struct{}
+
+func (failingVault) Tokenize(ctx context.Context, number string) (string, error) {
+	return "", errors.New("vault unreachable")
+}
+
+func (failingVault) Detokenize(ctx context.Context, token string) (string, error) {
+	return "", errors.New("vault unreachable")
+}
+
+func TestCaseService_TokenizesPAN(t *testing.T) {
+	service := setupCaseService()
+	caseObj := createMockCase()
+	require.NoError(t, service.CreateCase(caseObj))
+
+	stored, err := service.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "411111******1111", stored.PrimaryAccountNumber)
+	assert.Equal(t, "411111", stored.PANBIN)
+	assert.Equal(t, "1111", stored.PANLast4)
+	assert.True(t, len(stored.PANToken) > len(pan.TokenPrefix))
+	assert.NotContains(t, stored.PANToken, "4111111111111111")
+
+	number, err := service.DetokenizePAN(context.Background(), caseObj.ID, map[string]string{"reason": "dispute review"})
+	require.NoError(t, err)
+	assert.Equal(t, "4111111111111111", number)
+
+	entries, err := service.GetCaseAudit(caseObj.ID)
+	require.NoError(t, err)
+	detokenized := entries[len(entries)-1]
+	assert.Equal(t, models.CaseAuditPANDetokenized, detokenized.Action)
+	assert.Equal(t, "dispute review", detokenized.Details["reason"])
+
+	_, err = service.DetokenizePAN(context.Background(), "unknown", nil)
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+}
+
+func TestCaseService_RejectsInvalidPAN(t *testing.T) {
+	service := setupCaseService()
+
+	for _, number := range []string{"4111111111111112", "41111111", "", "411111******1111"} {
+		caseObj := createMockCase()
+		caseObj.PrimaryAccountNumber = number
+		assert.ErrorIs(t, service.CreateCase(caseObj), ErrInvalidPAN, number)
+	}
+
+	unreachable := setupCaseService()
+	unreachable.UseVault(failingVault{})
+	assert.Error(t, unreachable.CreateCase(createMockCase()))
+}
+
+func TestCaseService_UpdateKeepsPAN(t *testing.T) {
+	service := setupCaseService()
+	caseObj := createMockCase()
+	require.NoError(t, service.CreateCase(caseObj))
+	token := caseObj.PANToken
+
+	// An update returning the truncated card number keeps the token
+	update := createMockCase()
+	update.PrimaryAccountNumber = "411111******1111"
+	require.NoError(t, service.UpdateCase(update))
+	assert.Equal(t, token, update.PANToken)
+	assert.Equal(t, "1111", update.PANLast4)
+
+	// One with another truncated number is refused
+	mismatch := createMockCase()
+	mismatch.PrimaryAccountNumber = "555555******4444"
+	assert.ErrorIs(t, service.UpdateCase(mismatch), ErrInvalidPAN)
+
+	// A new full card number is tokenized
+	replaced := createMockCase()
+	replaced.PrimaryAccountNumber = "5555555555554444"
+	require.NoError(t, service.UpdateCase(replaced))
+	assert.NotEqual(t, token, replaced.PANToken)
+	assert.Equal(t, "555555******4444", replaced.PrimaryAccountNumber)
+	number, err := service.DetokenizePAN(context.Background(), caseObj.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "5555555555554444", number)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"mastercom-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrCaseOnLegalHold is returned when deleting or purging a case under legal hold
+	ErrCaseOnLegalHold = errors.New("case is on legal hold")
+	// ErrCaseNotOnLegalHold is returned when releasing a hold from a case that has none
+	ErrCaseNotOnLegalHold = errors.New("case is not on legal hold")
+)
+
+// PlaceLegalHold stops a case and its documents from being purged or deleted until the hold
+// is released. Placing a hold on a case already held replaces it.
+func (s *CaseService) PlaceLegalHold(caseID string, hold *models.LegalHold) (*models.Case, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseObj, exists := s.cases[caseID]
+	if !exists {
+		return nil, ErrCaseNotFound
+	}
+
+	hold.PlacedAt = time.Now()
+	caseObj.LegalHold = hold
+	s.recordAudit(caseID, models.CaseAuditLegalHoldPlaced, map[string]string{
+		"reason":   hold.Reason,
+		"placedBy": hold.PlacedBy,
+	})
+	s.logger.Info("Legal hold placed on case", logrus.Fields{
+		"caseId":   caseID,
+		"placedBy": hold.PlacedBy,
+	})
+	return s.openCase(caseObj), nil
+}
+
+// ReleaseLegalHold lifts a case's legal hold, leaving it to its retention policy again
+func (s *CaseService) ReleaseLegalHold(caseID, releasedBy string) (*models.Case, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseObj, exists := s.cases[caseID]
+	if !exists {
+		return nil, ErrCaseNotFound
+	}
+	if caseObj.LegalHold == nil {
+		return nil, ErrCaseNotOnLegalHold
+	}
+
+	hold := caseObj.LegalHold
+	caseObj.LegalHold = nil
+	s.recordAudit(caseID, models.CaseAuditLegalHoldReleased, map[string]string{
+		"reason":     hold.Reason,
+		"releasedBy": releasedBy,
+	})
+	s.logger.Info("Legal hold released from case", logrus.Fields{
+		"caseId":     caseID,
+		"releasedBy": releasedBy,
+		"heldFor":    time.Since(hold.PlacedAt).String(),
+	})
+	return s.openCase(caseObj), nil
+}
+
+// OnLegalHold reports whether a case is under legal hold. A case that no longer exists is not.
+func (s *CaseService) OnLegalHold(caseID string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	caseObj, exists := s.cases[caseID]
+	return exists && caseObj.LegalHold != nil
+}
+
+// ListAllCases returns a copy of every case, for sweeps over the whole store
+func (s *CaseService) ListAllCases() []models.Case {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	cases := make([]models.Case, 0, len(s.cases))
+	for _, caseObj := range s.cases {
+		cases = append(cases, *s.openCase(caseObj))
+	}
+	return cases
+}
+
+// PurgeCase removes a case whose retention period has ended, recording the purge with
+// details in its audit history, which is kept
+func (s *CaseService) PurgeCase(caseID string, details map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseObj, exists := s.cases[caseID]
+	if !exists {
+		return ErrCaseNotFound
+	}
+	if caseObj.LegalHold != nil {
+		return ErrCaseOnLegalHold
+	}
+
+	delete(s.cases, caseID)
+	delete(s.sealed, caseID)
+	s.recordAudit(caseID, models.CaseAuditPurged, details)
+	s.logger.Info("Case purged", logrus.Fields{
+		"caseId":   caseID,
+		"caseType": caseObj.CaseType,
+		"queue":    caseObj.QueueName,
+	})
+	return nil
+}
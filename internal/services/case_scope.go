@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+
+	"mastercom-service/internal/models"
+)
+
+// ErrICANotPermitted is returned when a case is filed by an ICA outside the caller's scope
+var ErrICANotPermitted = errors.New("ICA not permitted")
+
+// CheckCaseScope returns ErrCaseNotFound for a case that does not exist or lies outside scope,
+// so that callers cannot learn of cases they may not act on
+func (s *CaseService) CheckCaseScope(caseID string, scope *models.ICAScope) error {
+	if scope.Unrestricted() {
+		return nil
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	caseObj, exists := s.cases[caseID]
+	if !exists || !scope.PermitsCase(caseObj) {
+		return ErrCaseNotFound
+	}
+	return nil
+}
+
+// CheckFilingICA returns ErrICANotPermitted unless the case is filed by an ICA of scope
+func CheckFilingICA(caseObj *models.Case, scope *models.ICAScope) error {
+	if !scope.Permits(caseObj.FilingIca) {
+		return ErrICANotPermitted
+	}
+	return nil
+}
+
+// DocumentCaseID returns the ID of the case a document belongs to, including for deleted
+// documents, which can still be restored
+func (s *DocumentService) DocumentCaseID(documentID string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	document, exists := s.documents[documentID]
+	if !exists {
+		return "", ErrDocumentNotFound
+	}
+	return document.CaseID, nil
+}
+
+// CheckDocumentScope returns ErrDocumentNotFound for a document that does not exist or whose
+// case lies outside scope. Documents without a case are only within an unrestricted scope.
+func (s *CaseDocumentService) CheckDocumentScope(documentID string, scope *models.ICAScope) error {
+	if scope.Unrestricted() {
+		return nil
+	}
+
+	caseID, err := s.documentService.DocumentCaseID(documentID)
+	if err != nil {
+		return err
+	}
+	if caseID == "" || s.caseService.CheckCaseScope(caseID, scope) != nil {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
+
+// CheckCaseScope returns ErrCaseNotFound for a case that does not exist or lies outside scope
+func (s *CaseDocumentService) CheckCaseScope(caseID string, scope *models.ICAScope) error {
+	return s.caseService.CheckCaseScope(caseID, scope)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	"mastercom-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaseService_Scope(t *testing.T) {
+	service, caseService, documentService := setupCaseDocumentService()
+	issuerCase := createMockCase()
+	issuerCase.QueueName = models.QueueUnworked
+	require.NoError(t, caseService.CreateCase(issuerCase))
+	otherCase := createMockCase()
+	otherCase.ID = "other-case-id"
+	otherCase.QueueName = models.QueueUnworked
+	otherCase.FilingIca, otherCase.FiledAgainstIca = "111111", "222222"
+	require.NoError(t, caseService.CreateCase(otherCase))
+
+	filer := &models.ICAScope{ICAs: []string{"123456"}}
+	respondent := &models.ICAScope{ICAs: []string{"654321"}}
+	everyICA := &models.ICAScope{ICAs: []string{models.AllICAs}}
+
+	// Cases are within the scope of the ICAs that filed them and were filed against
+	assert.NoError(t, caseService.CheckCaseScope(issuerCase.ID, filer))
+	assert.NoError(t, caseService.CheckCaseScope(issuerCase.ID, respondent))
+	assert.ErrorIs(t, caseService.CheckCaseScope(otherCase.ID, filer), ErrCaseNotFound)
+	assert.NoError(t, caseService.CheckCaseScope(otherCase.ID, everyICA))
+	assert.NoError(t, caseService.CheckCaseScope(otherCase.ID, nil))
+	assert.ErrorIs(t, caseService.CheckCaseScope(otherCase.ID, &models.ICAScope{}), ErrCaseNotFound)
+
+	// Only the filing ICA may file
+	assert.NoError(t, CheckFilingICA(issuerCase, filer))
+	assert.ErrorIs(t, CheckFilingICA(issuerCase, respondent), ErrICANotPermitted)
+
+	cases, total, err := caseService.ListCases(1, 10, "", filer)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, issuerCase.ID, cases[0].ID)
+	_, total, err = caseService.ListCases(1, 10, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, caseService.ListCasesByQueue(models.QueueUnworked, respondent), 1)
+	assert.Equal(t, 1, caseService.CountCasesByQueue(filer)[models.QueueUnworked])
+
+	// Documents are scoped through their case, deleted or not
+	document := models.NewDocument("", "evidence.pdf", ".pdf", []byte("content"), "test-user", "")
+	require.NoError(t, service.AttachDocument(otherCase.ID, document))
+	require.NoError(t, documentService.DeleteDocument(document.ID))
+	assert.ErrorIs(t, service.CheckDocumentScope(document.ID, filer), ErrDocumentNotFound)
+	assert.NoError(t, service.CheckDocumentScope(document.ID, &models.ICAScope{ICAs: []string{"222222"}}))
+	assert.ErrorIs(t, service.CheckDocumentScope("missing", filer), ErrDocumentNotFound)
+
+	// Documents without a case are only within an unrestricted scope
+	orphan := models.NewDocument("", "orphan.pdf", ".pdf", []byte("orphan"), "test-user", "")
+	require.NoError(t, documentService.UploadDocument(orphan))
+	assert.ErrorIs(t, service.CheckDocumentScope(orphan.ID, filer), ErrDocumentNotFound)
+	assert.NoError(t, service.CheckDocumentScope(orphan.ID, nil))
+}
@@ -2,29 +2,55 @@ package services
 
 import (
 	"errors"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"mastercom-service/internal/models"
+	"mastercom-service/pkg/envelope"
 	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/pan"
 
 	"github.com/sirupsen/logrus"
 )
 
+// ErrCaseNotFound is returned when a case ID is not known
+var ErrCaseNotFound = errors.New("case not found")
+
 type CaseService struct {
-	cases  map[string]*models.Case
+	cases map[string]*models.Case
+	// audit holds each case's audit history, oldest first
+	audit  map[string][]models.CaseAuditEntry
 	mutex  sync.RWMutex
 	logger *logger.DatadogLogger
+	// vault holds the card numbers of cases, which keep only their tokens
+	vault pan.Vault
+	// keys, when set, encrypts the tagged fields of stored cases, which are kept in sealed
+	// by case ID while the cases hold them empty
+	keys   *envelope.KeyRing
+	sealed map[string]envelope.Fields
 }
 
 func NewCaseService(logger *logger.DatadogLogger) *CaseService {
 	return &CaseService{
 		cases:  make(map[string]*models.Case),
+		audit:  make(map[string][]models.CaseAuditEntry),
 		logger: logger,
+		vault:  pan.NewLocalVault(nil),
+		sealed: make(map[string]envelope.Fields),
 	}
 }
 
+// CreateCase stores a new case, exchanging its card number for a vault token
 func (s *CaseService) CreateCase(caseObj *models.Case) error {
+	if err := s.tokenizePAN(caseObj); err != nil {
+		return err
+	}
+	if caseObj.PANToken == "" {
+		return ErrInvalidPAN
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -34,7 +60,8 @@ func (s *CaseService) CreateCase(caseObj *models.Case) error {
 	}
 
 	// Store the case
-	s.cases[caseObj.ID] = caseObj
+	s.cases[caseObj.ID] = s.sealCase(caseObj)
+	s.recordAudit(caseObj.ID, models.CaseAuditCreated, map[string]string{"queueName": caseObj.QueueName, "status": caseObj.Status})
 	s.logger.Info("Case created successfully", logrus.Fields{"caseId": caseObj.ID})
 	return nil
 }
@@ -45,20 +72,21 @@ func (s *CaseService) GetCase(caseID string) (*models.Case, error) {
 
 	caseObj, exists := s.cases[caseID]
 	if !exists {
-		return nil, errors.New("case not found")
+		return nil, ErrCaseNotFound
 	}
 
-	return caseObj, nil
+	return s.openCase(caseObj), nil
 }
 
-func (s *CaseService) ListCases(page, limit int, status string) ([]*models.Case, int, error) {
+// ListCases returns a page of the cases within scope, optionally limited to a status
+func (s *CaseService) ListCases(page, limit int, status string, scope *models.ICAScope) ([]*models.Case, int, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	var filteredCases []*models.Case
 	for _, caseObj := range s.cases {
-		if status == "" || caseObj.Status == status {
-			filteredCases = append(filteredCases, caseObj)
+		if (status == "" || caseObj.Status == status) && scope.PermitsCase(caseObj) {
+			filteredCases = append(filteredCases, s.openCase(caseObj))
 		}
 	}
 
@@ -77,35 +105,360 @@ func (s *CaseService) ListCases(page, limit int, status string) ([]*models.Case,
 	return filteredCases[start:end], total, nil
 }
 
+// UpdateCase replaces a case's details. Its card number is tokenized again unless it is
+// given truncated as stored.
 func (s *CaseService) UpdateCase(caseObj *models.Case) error {
+	if err := s.tokenizePAN(caseObj); err != nil {
+		return err
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	// Check if case exists
-	if _, exists := s.cases[caseObj.ID]; !exists {
-		return errors.New("case not found")
+	existing, exists := s.cases[caseObj.ID]
+	if !exists {
+		return ErrCaseNotFound
+	}
+	if err := keepPAN(caseObj, existing); err != nil {
+		return err
 	}
 
+	// Queue membership only changes through TransitionCase, documents through SetCaseDocuments,
+	// submission tracking through the submission worker and legal holds through PlaceLegalHold
+	caseObj.QueueName = existing.QueueName
+	caseObj.Documents = existing.Documents
+	caseObj.MastercomCaseID = existing.MastercomCaseID
+	caseObj.SubmissionStatus = existing.SubmissionStatus
+	caseObj.SubmissionAttempts = existing.SubmissionAttempts
+	caseObj.NextSubmissionAt = existing.NextSubmissionAt
+	caseObj.SubmittedAt = existing.SubmittedAt
+	caseObj.SubmissionErrors = existing.SubmissionErrors
+	caseObj.MastercomStatus = existing.MastercomStatus
+	caseObj.MastercomImageStatus = existing.MastercomImageStatus
+	caseObj.QueuedAt = existing.QueuedAt
+	caseObj.LegalHold = existing.LegalHold
+
 	// Update timestamp
 	caseObj.UpdatedAt = time.Now()
 
 	// Store the updated case
-	s.cases[caseObj.ID] = caseObj
+	s.cases[caseObj.ID] = s.sealCase(caseObj)
+	s.recordAudit(caseObj.ID, models.CaseAuditUpdated, map[string]string{"status": caseObj.Status})
 	s.logger.Info("Case updated successfully", logrus.Fields{"caseId": caseObj.ID})
 	return nil
 }
 
+// TransitionCase applies a queue action to a case, moving it to the resulting queue
+func (s *CaseService) TransitionCase(caseID string, action models.QueueAction) (*models.Case, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseObj, exists := s.cases[caseID]
+	if !exists {
+		return nil, ErrCaseNotFound
+	}
+
+	queueName, err := models.NextQueue(caseObj.QueueName, action)
+	if err != nil {
+		return nil, err
+	}
+
+	previousQueue := caseObj.QueueName
+	caseObj.QueueName = queueName
+	caseObj.UpdatedAt = time.Now()
+	caseObj.QueuedAt = caseObj.UpdatedAt
+	// Submitting (or resubmitting after a rejection) queues the case for a fresh filing
+	if action == models.QueueActionSubmit && caseObj.MastercomCaseID == "" {
+		caseObj.ResetSubmission()
+	}
+	s.recordAudit(caseID, models.CaseAuditQueueTransition, map[string]string{
+		"action": string(action),
+		"from":   previousQueue,
+		"to":     queueName,
+	})
+	s.logger.Info("Case moved between queues", logrus.Fields{
+		"caseId": caseID,
+		"action": action,
+		"from":   previousQueue,
+		"to":     queueName,
+	})
+	return s.openCase(caseObj), nil
+}
+
+// ListCasesByQueue returns the cases within scope in a queue, most recently modified first
+func (s *CaseService) ListCasesByQueue(queueName string, scope *models.ICAScope) []*models.Case {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var cases []*models.Case
+	for _, caseObj := range s.cases {
+		if caseObj.QueueName == queueName && scope.PermitsCase(caseObj) {
+			cases = append(cases, s.openCase(caseObj))
+		}
+	}
+
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].UpdatedAt.After(cases[j].UpdatedAt)
+	})
+
+	return cases
+}
+
+// ListCasesReadyForSubmission returns snapshots of the cases due to be filed with Mastercom,
+// oldest first
+func (s *CaseService) ListCasesReadyForSubmission(now time.Time) []models.Case {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var cases []models.Case
+	for _, caseObj := range s.cases {
+		if caseObj.ReadyForSubmission(now) {
+			cases = append(cases, *s.openCase(caseObj))
+		}
+	}
+
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].UpdatedAt.Before(cases[j].UpdatedAt)
+	})
+
+	return cases
+}
+
+// MarkCaseSubmitted records that Mastercom accepted a case under mastercomCaseID
+func (s *CaseService) MarkCaseSubmitted(caseID, mastercomCaseID string) (*models.Case, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseObj, exists := s.cases[caseID]
+	if !exists {
+		return nil, ErrCaseNotFound
+	}
+
+	now := time.Now()
+	caseObj.MastercomCaseID = mastercomCaseID
+	caseObj.SubmissionStatus = models.SubmissionStatusSubmitted
+	caseObj.SubmissionAttempts++
+	caseObj.NextSubmissionAt = nil
+	caseObj.SubmittedAt = &now
+	caseObj.SubmissionErrors = nil
+	caseObj.Status = models.CaseStatusSubmitted
+	caseObj.UpdatedAt = now
+
+	s.recordAudit(caseID, models.CaseAuditSubmitted, map[string]string{
+		"mastercomCaseId": mastercomCaseID,
+		"attempts":        strconv.Itoa(caseObj.SubmissionAttempts),
+	})
+	s.logger.Info("Case submitted to Mastercom", logrus.Fields{
+		"caseId":          caseID,
+		"mastercomCaseId": mastercomCaseID,
+		"attempts":        caseObj.SubmissionAttempts,
+	})
+	return s.openCase(caseObj), nil
+}
+
+// RecordSubmissionFailure records a failed submission attempt. A non-nil retryAt schedules
+// another attempt; otherwise the case is marked with status (REJECTED or FAILED) and moved
+// to the Rejects queue for an analyst to correct.
+func (s *CaseService) RecordSubmissionFailure(caseID, status string, submissionErrors []models.SubmissionError, retryAt *time.Time) (*models.Case, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseObj, exists := s.cases[caseID]
+	if !exists {
+		return nil, ErrCaseNotFound
+	}
+
+	caseObj.SubmissionAttempts++
+	caseObj.SubmissionErrors = submissionErrors
+	caseObj.NextSubmissionAt = retryAt
+	caseObj.UpdatedAt = time.Now()
+
+	details := map[string]string{"attempts": strconv.Itoa(caseObj.SubmissionAttempts)}
+	if len(submissionErrors) > 0 {
+		details["error"] = submissionErrors[0].Description
+	}
+	if retryAt != nil {
+		details["retryAt"] = retryAt.Format(time.RFC3339)
+		s.recordAudit(caseID, models.CaseAuditSubmissionFailed, details)
+		caseObj.SubmissionStatus = models.SubmissionStatusPending
+		s.logger.Info("Case submission will be retried", logrus.Fields{
+			"caseId":   caseID,
+			"attempts": caseObj.SubmissionAttempts,
+			"retryAt":  retryAt.Format(time.RFC3339),
+		})
+		return s.openCase(caseObj), nil
+	}
+
+	caseObj.SubmissionStatus = status
+	if status == models.SubmissionStatusRejected {
+		caseObj.Status = models.CaseStatusRejected
+	}
+	if queueName, err := models.NextQueue(caseObj.QueueName, models.QueueActionReject); err == nil {
+		caseObj.QueueName = queueName
+		caseObj.QueuedAt = time.Now()
+	}
+	details["status"] = status
+	s.recordAudit(caseID, models.CaseAuditSubmissionFailed, details)
+
+	s.logger.Info("Case moved to Rejects after failed submission", logrus.Fields{
+		"caseId":   caseID,
+		"status":   status,
+		"attempts": caseObj.SubmissionAttempts,
+	})
+	return s.openCase(caseObj), nil
+}
+
+// ListMastercomCaseIDs returns the Mastercom case IDs of filed cases that are not closed
+func (s *CaseService) ListMastercomCaseIDs() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var caseIDs []string
+	for _, caseObj := range s.cases {
+		if caseObj.MastercomCaseID != "" && caseObj.QueueName != models.QueueClosed {
+			caseIDs = append(caseIDs, caseObj.MastercomCaseID)
+		}
+	}
+
+	sort.Strings(caseIDs)
+	return caseIDs
+}
+
+// UpdateMastercomStatus records the status Mastercom reports for a filed case. Empty statuses
+// are left unchanged. It reports whether the case changed.
+func (s *CaseService) UpdateMastercomStatus(mastercomCaseID, status, imageStatus string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, caseObj := range s.cases {
+		if caseObj.MastercomCaseID != mastercomCaseID {
+			continue
+		}
+
+		changed := false
+		if status != "" && status != caseObj.MastercomStatus {
+			caseObj.MastercomStatus = status
+			changed = true
+		}
+		if imageStatus != "" && imageStatus != caseObj.MastercomImageStatus {
+			caseObj.MastercomImageStatus = imageStatus
+			changed = true
+		}
+		if changed {
+			caseObj.UpdatedAt = time.Now()
+			s.recordAudit(caseObj.ID, models.CaseAuditMastercomStatus, map[string]string{
+				"status":      caseObj.MastercomStatus,
+				"imageStatus": caseObj.MastercomImageStatus,
+			})
+			s.logger.Info("Case status synchronised from Mastercom", logrus.Fields{
+				"caseId":          caseObj.ID,
+				"mastercomCaseId": mastercomCaseID,
+				"status":          caseObj.MastercomStatus,
+				"imageStatus":     caseObj.MastercomImageStatus,
+			})
+		}
+		return changed, nil
+	}
+
+	return false, ErrCaseNotFound
+}
+
+// CountCasesByQueue returns the number of cases within scope in each queue
+func (s *CaseService) CountCasesByQueue(scope *models.ICAScope) map[string]int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	counts := make(map[string]int)
+	for _, caseObj := range s.cases {
+		if scope.PermitsCase(caseObj) {
+			counts[caseObj.QueueName]++
+		}
+	}
+
+	return counts
+}
+
+// SetCaseDocuments replaces the documents listed on a case. Content is not copied onto the
+// case; it stays with the document service.
+func (s *CaseService) SetCaseDocuments(caseID string, documents []*models.Document) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseObj, exists := s.cases[caseID]
+	if !exists {
+		return ErrCaseNotFound
+	}
+
+	caseObj.Documents = make([]models.Document, 0, len(documents))
+	for _, document := range documents {
+		summary := *document
+		summary.Content = nil
+		caseObj.Documents = append(caseObj.Documents, summary)
+	}
+	return nil
+}
+
 func (s *CaseService) DeleteCase(caseID string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	// Check if case exists
-	if _, exists := s.cases[caseID]; !exists {
-		return errors.New("case not found")
+	caseObj, exists := s.cases[caseID]
+	if !exists {
+		return ErrCaseNotFound
+	}
+	if caseObj.LegalHold != nil {
+		return ErrCaseOnLegalHold
 	}
 
-	// Delete the case
+	// Delete the case, keeping its audit history
 	delete(s.cases, caseID)
+	delete(s.sealed, caseID)
+	s.recordAudit(caseID, models.CaseAuditDeleted, nil)
 	s.logger.Info("Case deleted successfully", logrus.Fields{"caseId": caseID})
 	return nil
 }
+
+// ReconActivity returns cases last modified within the range, filed by or against one of the ICAs
+func (s *CaseService) ReconActivity(start, end time.Time, icas []string) ([]models.ReconRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var records []models.ReconRecord
+	for _, caseObj := range s.cases {
+		if caseObj.UpdatedAt.Before(start) || !caseObj.UpdatedAt.Before(end) {
+			continue
+		}
+
+		ica := caseObj.FilingIca
+		if len(icas) > 0 {
+			switch {
+			case containsString(icas, caseObj.FilingIca):
+			case containsString(icas, caseObj.FiledAgainstIca):
+				ica = caseObj.FiledAgainstIca
+			default:
+				continue
+			}
+		}
+
+		amount, currency := caseObj.DisputeAmount, caseObj.DisputeCurrency
+		if amount == 0 {
+			amount, currency = caseObj.TransactionAmount, caseObj.TransactionCurrency
+		}
+
+		records = append(records, models.ReconRecord{
+			ActivityType:         models.ReconActivityCase,
+			ReferenceID:          caseObj.ID,
+			ICA:                  ica,
+			PrimaryAccountNumber: caseObj.PrimaryAccountNumber,
+			Amount:               amount,
+			Currency:             currency,
+			ReasonCode:           caseObj.ReasonCode,
+			Status:               caseObj.Status,
+			ActivityDate:         caseObj.UpdatedAt,
+		})
+	}
+
+	return records, nil
+}
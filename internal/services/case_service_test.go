@@ -5,14 +5,14 @@ import (
 	"time"
 
 	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
 
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func setupCaseService() *CaseService {
-	logger := logrus.New()
+	logger := logger.NewDatadogLogger()
 	return NewCaseService(logger)
 }
 
@@ -112,13 +112,13 @@ func TestCaseService_ListCases(t *testing.T) {
 	require.NoError(t, err)
 	
 	// List all cases
-	cases, total, err := service.ListCases(1, 10, "")
+	cases, total, err := service.ListCases(1, 10, "", nil)
 	require.NoError(t, err)
 	assert.Equal(t, 3, total)
 	assert.Len(t, cases, 3)
 	
 	// List cases with status filter
-	pendingCases, total, err := service.ListCases(1, 10, "PENDING")
+	pendingCases, total, err := service.ListCases(1, 10, "PENDING", nil)
 	require.NoError(t, err)
 	assert.Equal(t, 2, total)
 	assert.Len(t, pendingCases, 2)
@@ -140,19 +140,19 @@ func TestCaseService_ListCases_Pagination(t *testing.T) {
 	}
 	
 	// Test pagination - page 1, limit 2
-	cases, total, err := service.ListCases(1, 2, "")
+	cases, total, err := service.ListCases(1, 2, "", nil)
 	require.NoError(t, err)
 	assert.Equal(t, 5, total)
 	assert.Len(t, cases, 2)
 	
 	// Test pagination - page 2, limit 2
-	cases, total, err = service.ListCases(2, 2, "")
+	cases, total, err = service.ListCases(2, 2, "", nil)
 	require.NoError(t, err)
 	assert.Equal(t, 5, total)
 	assert.Len(t, cases, 2)
 	
 	// Test pagination - page 3, limit 2
-	cases, total, err = service.ListCases(3, 2, "")
+	cases, total, err = service.ListCases(3, 2, "", nil)
 	require.NoError(t, err)
 	assert.Equal(t, 5, total)
 	assert.Len(t, cases, 1)
@@ -236,7 +236,7 @@ func TestCaseService_ConcurrentAccess(t *testing.T) {
 	}
 	
 	// Verify all cases were created
-	cases, total, err := service.ListCases(1, 20, "")
+	cases, total, err := service.ListCases(1, 20, "", nil)
 	require.NoError(t, err)
 	assert.Equal(t, 10, total)
 	assert.Len(t, cases, 10)
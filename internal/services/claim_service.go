@@ -0,0 +1,310 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// mastercomDateLayout is the yyyy-MM-dd date format used on Mastercom claims and chargebacks
+const mastercomDateLayout = "2006-01-02"
+
+// ErrClaimNotFound is returned when a claim ID is not known
+var ErrClaimNotFound = errors.New("claim not found")
+
+// ClaimService stores claims and chargebacks synchronised from Mastercom
+type ClaimService struct {
+	claims map[string]*models.Claim
+	mutex  sync.RWMutex
+	logger *logger.DatadogLogger
+}
+
+func NewClaimService(logger *logger.DatadogLogger) *ClaimService {
+	return &ClaimService{
+		claims: make(map[string]*models.Claim),
+		logger: logger,
+	}
+}
+
+// GetClaim returns a snapshot of a stored claim
+func (s *ClaimService) GetClaim(claimID string) (*models.Claim, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	claim, exists := s.claims[claimID]
+	if !exists {
+		return nil, ErrClaimNotFound
+	}
+
+	return copyClaim(claim), nil
+}
+
+// ListClaims returns snapshots of the stored claims, most recently changed first
+func (s *ClaimService) ListClaims() []*models.Claim {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	claims := make([]*models.Claim, 0, len(s.claims))
+	for _, claim := range s.claims {
+		claims = append(claims, copyClaim(claim))
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		return claims[i].LastChangedAt.After(claims[j].LastChangedAt)
+	})
+
+	return claims
+}
+
+// UpsertClaim stores a claim read from Mastercom. The stored claim is only replaced, and its
+// version incremented, when the Mastercom data changed; local acknowledgement state on
+// chargebacks is kept either way.
+func (s *ClaimService) UpsertClaim(claim *models.Claim) models.ClaimUpsertResult {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	incoming := copyClaim(claim)
+	existing, exists := s.claims[claim.ClaimID]
+
+	if !exists {
+		incoming.Version = 1
+		incoming.FirstSyncedAt = now
+		incoming.LastSyncedAt = now
+		incoming.LastChangedAt = now
+		s.claims[claim.ClaimID] = incoming
+		s.logger.Info("Claim synchronised", logrus.Fields{"claimId": claim.ClaimID, "queueName": claim.QueueName})
+		return models.ClaimCreated
+	}
+
+	// Carry local acknowledgement state over to the incoming chargebacks
+	acknowledgements := make(map[string]models.Chargeback, len(existing.Chargebacks))
+	for _, chargeback := range existing.Chargebacks {
+		acknowledgements[chargeback.ChargebackID] = chargeback
+	}
+	for i := range incoming.Chargebacks {
+		if previous, ok := acknowledgements[incoming.Chargebacks[i].ChargebackID]; ok {
+			incoming.Chargebacks[i].Acknowledged = previous.Acknowledged
+			incoming.Chargebacks[i].AcknowledgedAt = previous.AcknowledgedAt
+			incoming.Chargebacks[i].AcknowledgeFailure = previous.AcknowledgeFailure
+		}
+	}
+
+	incoming.Version = existing.Version
+	incoming.FirstSyncedAt = existing.FirstSyncedAt
+	incoming.LastChangedAt = existing.LastChangedAt
+	incoming.LastSyncedAt = existing.LastSyncedAt
+	if reflect.DeepEqual(incoming, existing) {
+		existing.LastSyncedAt = now
+		return models.ClaimUnchanged
+	}
+
+	incoming.Version++
+	incoming.LastSyncedAt = now
+	incoming.LastChangedAt = now
+	s.claims[claim.ClaimID] = incoming
+	s.logger.Info("Claim changed in Mastercom", logrus.Fields{
+		"claimId":   claim.ClaimID,
+		"queueName": claim.QueueName,
+		"version":   incoming.Version,
+	})
+	return models.ClaimUpdated
+}
+
+// IsCurrent reports whether the stored claim was last modified in Mastercom at
+// lastModifiedDate and is still in queueName, so its details need not be fetched again
+func (s *ClaimService) IsCurrent(claimID, queueName, lastModifiedDate string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	claim, exists := s.claims[claimID]
+	return exists && claim.QueueName == queueName && claim.LastModifiedDate == lastModifiedDate
+}
+
+// PendingAcknowledgements returns the stored chargebacks not yet acknowledged in Mastercom.
+// Reversed chargebacks and chargebacks that already failed to acknowledge are skipped.
+func (s *ClaimService) PendingAcknowledgements() []models.Chargeback {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var pending []models.Chargeback
+	for _, claim := range s.claims {
+		for _, chargeback := range claim.Chargebacks {
+			if chargeback.Acknowledged || chargeback.Reversed || chargeback.AcknowledgeFailure != "" {
+				continue
+			}
+			pending = append(pending, chargeback)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ChargebackID < pending[j].ChargebackID
+	})
+
+	return pending
+}
+
+// RecordAcknowledgement stores the outcome of acknowledging a chargeback in Mastercom
+func (s *ClaimService) RecordAcknowledgement(claimID, chargebackID, status, failureReason string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	claim, exists := s.claims[claimID]
+	if !exists {
+		return ErrClaimNotFound
+	}
+
+	for i := range claim.Chargebacks {
+		chargeback := &claim.Chargebacks[i]
+		if chargeback.ChargebackID != chargebackID {
+			continue
+		}
+
+		if status == models.ChargebackAckProcessed {
+			now := time.Now()
+			chargeback.Acknowledged = true
+			chargeback.AcknowledgedAt = &now
+			chargeback.AcknowledgeFailure = ""
+		} else {
+			chargeback.AcknowledgeFailure = failureReason
+			if chargeback.AcknowledgeFailure == "" {
+				chargeback.AcknowledgeFailure = status
+			}
+		}
+		return nil
+	}
+
+	return errors.New("chargeback not found")
+}
+
+// ReconActivity implements ReconActivitySource, reporting chargebacks by creation date
+func (s *ClaimService) ReconActivity(start, end time.Time, icas []string) ([]models.ReconRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var records []models.ReconRecord
+	for _, claim := range s.claims {
+		ica, ok := claimReconICA(claim, icas)
+		if !ok {
+			continue
+		}
+
+		for _, chargeback := range claim.Chargebacks {
+			createDate, err := time.Parse(mastercomDateLayout, chargeback.CreateDate)
+			if err != nil || createDate.Before(start) || !createDate.Before(end) {
+				continue
+			}
+
+			amount, _ := strconv.ParseFloat(chargeback.Amount, 64)
+			status := chargeback.ChargebackType
+			if chargeback.Reversed {
+				status = "REVERSED"
+			}
+
+			records = append(records, models.ReconRecord{
+				ActivityType:         models.ReconActivityChargeback,
+				ReferenceID:          chargeback.ChargebackID,
+				ICA:                  ica,
+				PrimaryAccountNumber: claim.PrimaryAccountNum,
+				Amount:               amount,
+				Currency:             chargeback.Currency,
+				ReasonCode:           chargeback.ReasonCode,
+				Status:               status,
+				ActivityDate:         createDate,
+			})
+		}
+	}
+
+	return records, nil
+}
+
+// ClaimFeeSource is the ReconActivitySource of the fee collections on synchronised claims
+type ClaimFeeSource struct {
+	claims *ClaimService
+}
+
+// NewClaimFeeSource creates a recon activity source for the fees of the claims in claimService
+func NewClaimFeeSource(claimService *ClaimService) *ClaimFeeSource {
+	return &ClaimFeeSource{claims: claimService}
+}
+
+// ReconActivity implements ReconActivitySource, reporting fee collections by fee date
+func (f *ClaimFeeSource) ReconActivity(start, end time.Time, icas []string) ([]models.ReconRecord, error) {
+	f.claims.mutex.RLock()
+	defer f.claims.mutex.RUnlock()
+
+	var records []models.ReconRecord
+	for _, claim := range f.claims.claims {
+		ica, ok := claimReconICA(claim, icas)
+		if !ok {
+			continue
+		}
+
+		for _, fee := range claim.Fees {
+			feeDate, err := time.Parse(mastercomDateLayout, fee.FeeDate)
+			if err != nil || feeDate.Before(start) || !feeDate.Before(end) {
+				continue
+			}
+
+			amount, _ := strconv.ParseFloat(fee.Amount, 64)
+			status := "COLLECTED"
+			if fee.RejectReason != "" {
+				status = "REJECTED"
+			}
+
+			records = append(records, models.ReconRecord{
+				ActivityType:         models.ReconActivityFee,
+				ReferenceID:          fee.FeeID,
+				ICA:                  ica,
+				PrimaryAccountNumber: claim.PrimaryAccountNum,
+				Amount:               amount,
+				Currency:             fee.Currency,
+				ReasonCode:           fee.Reason,
+				Status:               status,
+				ActivityDate:         feeDate,
+			})
+		}
+	}
+
+	return records, nil
+}
+
+// claimReconICA returns the ICA a claim's activity is reported for: the claim's own side,
+// or the side in icas when filtered. It is false when icas names neither side.
+func claimReconICA(claim *models.Claim, icas []string) (string, bool) {
+	ica := claim.IssuerID
+	if claim.IsAcquirer {
+		ica = claim.AcquirerID
+	}
+	if len(icas) == 0 {
+		return ica, true
+	}
+	switch {
+	case containsString(icas, ica):
+		return ica, true
+	case containsString(icas, claim.IssuerID):
+		return claim.IssuerID, true
+	case containsString(icas, claim.AcquirerID):
+		return claim.AcquirerID, true
+	}
+	return "", false
+}
+
+func copyClaim(claim *models.Claim) *models.Claim {
+	claimCopy := *claim
+	if claim.Chargebacks != nil {
+		claimCopy.Chargebacks = append([]models.Chargeback(nil), claim.Chargebacks...)
+	}
+	if claim.Fees != nil {
+		claimCopy.Fees = append([]models.Fee(nil), claim.Fees...)
+	}
+	return &claimCopy
+}
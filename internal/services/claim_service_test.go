@@ -0,0 +1,159 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupClaimService() *ClaimService {
+	return NewClaimService(logger.NewDatadogLogger())
+}
+
+func createMockClaim() *models.Claim {
+	return &models.Claim{
+		ClaimID:           "200002020654",
+		ClaimType:         "Standard",
+		QueueName:         "Unworked",
+		IsOpen:            true,
+		IsIssuer:          true,
+		IssuerID:          "123456",
+		AcquirerID:        "654321",
+		PrimaryAccountNum: "5154676300000001",
+		LastModifiedDate:  "2024-07-15T12:01:30",
+		Chargebacks: []models.Chargeback{{
+			ChargebackID:   "300002063556",
+			ClaimID:        "200002020654",
+			ChargebackType: "CHARGEBACK",
+			Amount:         "25.50",
+			Currency:       "USD",
+			ReasonCode:     "4853",
+			CreateDate:     "2024-07-15",
+		}},
+	}
+}
+
+func TestClaimService_UpsertClaim(t *testing.T) {
+	service := setupClaimService()
+
+	assert.Equal(t, models.ClaimCreated, service.UpsertClaim(createMockClaim()))
+	stored, err := service.GetClaim("200002020654")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Version)
+	assert.False(t, stored.FirstSyncedAt.IsZero())
+
+	// Identical data is detected as unchanged
+	assert.Equal(t, models.ClaimUnchanged, service.UpsertClaim(createMockClaim()))
+	stored, _ = service.GetClaim("200002020654")
+	assert.Equal(t, 1, stored.Version)
+
+	// A queue move is a change
+	moved := createMockClaim()
+	moved.QueueName = "Worked"
+	moved.LastModifiedDate = "2024-07-16T09:00:00"
+	assert.Equal(t, models.ClaimUpdated, service.UpsertClaim(moved))
+	stored, _ = service.GetClaim("200002020654")
+	assert.Equal(t, 2, stored.Version)
+	assert.Equal(t, "Worked", stored.QueueName)
+
+	assert.True(t, service.IsCurrent("200002020654", "Worked", "2024-07-16T09:00:00"))
+	assert.False(t, service.IsCurrent("200002020654", "Unworked", "2024-07-16T09:00:00"))
+	assert.False(t, service.IsCurrent("unknown", "Worked", "2024-07-16T09:00:00"))
+
+	_, err = service.GetClaim("unknown")
+	assert.ErrorIs(t, err, ErrClaimNotFound)
+}
+
+func TestClaimService_Acknowledgements(t *testing.T) {
+	service := setupClaimService()
+	claim := createMockClaim()
+	claim.Chargebacks = append(claim.Chargebacks,
+		models.Chargeback{ChargebackID: "300002063557", ClaimID: claim.ClaimID, Reversed: true},
+		models.Chargeback{ChargebackID: "300002063558", ClaimID: claim.ClaimID},
+	)
+	service.UpsertClaim(claim)
+
+	pending := service.PendingAcknowledgements()
+	require.Len(t, pending, 2)
+	assert.Equal(t, "300002063556", pending[0].ChargebackID)
+	assert.Equal(t, "300002063558", pending[1].ChargebackID)
+
+	require.NoError(t, service.RecordAcknowledgement(claim.ClaimID, "300002063556", models.ChargebackAckProcessed, ""))
+	require.NoError(t, service.RecordAcknowledgement(claim.ClaimID, "300002063558", models.ChargebackAckFailure, "Chargeback already processed"))
+	assert.Empty(t, service.PendingAcknowledgements())
+
+	// Acknowledgement state survives a re-sync of unchanged data
+	assert.Equal(t, models.ClaimUnchanged, service.UpsertClaim(claim))
+	stored, _ := service.GetClaim(claim.ClaimID)
+	assert.True(t, stored.Chargebacks[0].Acknowledged)
+	assert.NotNil(t, stored.Chargebacks[0].AcknowledgedAt)
+	assert.Equal(t, "Chargeback already processed", stored.Chargebacks[2].AcknowledgeFailure)
+
+	assert.ErrorIs(t, service.RecordAcknowledgement("unknown", "300002063556", models.ChargebackAckProcessed, ""), ErrClaimNotFound)
+	assert.Error(t, service.RecordAcknowledgement(claim.ClaimID, "unknown", models.ChargebackAckProcessed, ""))
+}
+
+func TestClaimService_ReconActivity(t *testing.T) {
+	service := setupClaimService()
+	service.UpsertClaim(createMockClaim())
+
+	start := time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC)
+	records, err := service.ReconActivity(start, start.AddDate(0, 0, 1), nil)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, models.ReconActivityChargeback, records[0].ActivityType)
+	assert.Equal(t, "300002063556", records[0].ReferenceID)
+	assert.Equal(t, 25.50, records[0].Amount)
+	assert.Equal(t, "123456", records[0].ICA)
+
+	// Filtered by ICA and date
+	records, _ = service.ReconActivity(start, start.AddDate(0, 0, 1), []string{"654321"})
+	require.Len(t, records, 1)
+	assert.Equal(t, "654321", records[0].ICA)
+
+	records, _ = service.ReconActivity(start, start.AddDate(0, 0, 1), []string{"999999"})
+	assert.Empty(t, records)
+
+	records, _ = service.ReconActivity(start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), nil)
+	assert.Empty(t, records)
+}
+
+func TestClaimFeeSource_ReconActivity(t *testing.T) {
+	service := setupClaimService()
+	claim := createMockClaim()
+	claim.Fees = []models.Fee{
+		{FeeID: "300002002247", ClaimID: claim.ClaimID, Amount: "0.24", Currency: "USD", Reason: "7623", FeeDate: "2024-07-15"},
+		{FeeID: "300002002248", ClaimID: claim.ClaimID, Amount: "15.00", Currency: "USD", Reason: "7604", FeeDate: "2024-07-15", RejectReason: "Duplicate"},
+		{FeeID: "300002002249", ClaimID: claim.ClaimID, Amount: "5.00", Currency: "USD", FeeDate: "2024-07-16"},
+	}
+	service.UpsertClaim(claim)
+	source := NewClaimFeeSource(service)
+
+	start := time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC)
+	records, err := source.ReconActivity(start, start.AddDate(0, 0, 1), nil)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	byID := map[string]models.ReconRecord{}
+	for _, record := range records {
+		assert.Equal(t, models.ReconActivityFee, record.ActivityType)
+		assert.Equal(t, "123456", record.ICA)
+		byID[record.ReferenceID] = record
+	}
+	assert.Equal(t, 0.24, byID["300002002247"].Amount)
+	assert.Equal(t, "7623", byID["300002002247"].ReasonCode)
+	assert.Equal(t, "COLLECTED", byID["300002002247"].Status)
+	assert.Equal(t, "REJECTED", byID["300002002248"].Status)
+
+	// Filtered by ICA
+	records, _ = source.ReconActivity(start, start.AddDate(0, 0, 1), []string{"654321"})
+	require.Len(t, records, 2)
+	assert.Equal(t, "654321", records[0].ICA)
+
+	records, _ = source.ReconActivity(start, start.AddDate(0, 0, 1), []string{"999999"})
+	assert.Empty(t, records)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"fmt"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/envelope"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EnableEncryption has document content stored from now on encrypted under keys. Stored
+// documents are then marked ContentSealed and hold no content; reads that return content
+// decrypt it into a copy. It is called before any document is uploaded.
+func (s *DocumentService) EnableEncryption(keys *envelope.KeyRing) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.keys = keys
+}
+
+// RewrapKeys re-wraps the data keys of the document content stored encrypted with the
+// primary key of the key ring, and returns the number re-wrapped
+func (s *DocumentService) RewrapKeys() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rewrapped := 0
+	for checksum, stored := range s.contents {
+		if stored.sealed == nil {
+			continue
+		}
+		changed, err := s.keys.Rewrap(stored.sealed)
+		if err != nil {
+			return rewrapped, fmt.Errorf("re-wrapping content %s: %w", checksum, err)
+		}
+		if changed {
+			rewrapped++
+		}
+	}
+	return rewrapped, nil
+}
+
+// openDocument returns a stored document as callers see it: a copy, with its content and
+// redacted content decrypted when they are sealed. The scan and redaction workers update the
+// stored document, so it is never handed out itself. Content that cannot be decrypted returns
+// an error wrapping ErrDocumentIntegrity. The caller must hold the mutex.
+func (s *DocumentService) openDocument(document *models.Document) (*models.Document, error) {
+	opened := *document
+	if !document.ContentSealed {
+		return &opened, nil
+	}
+
+	opened.ContentSealed = false
+	var err error
+	if opened.Content, err = s.openContent(document, document.SHA256); err != nil {
+		return nil, err
+	}
+	if document.Redaction != nil && document.Redaction.SHA256 != "" {
+		if opened.RedactedContent, err = s.openContent(document, document.Redaction.SHA256); err != nil {
+			return nil, err
+		}
+	}
+	return &opened, nil
+}
+
+// documentContent returns a stored document's content, decrypting it when it is sealed. The
+// caller must hold the mutex.
+func (s *DocumentService) documentContent(document *models.Document) ([]byte, error) {
+	if !document.ContentSealed {
+		return document.Content, nil
+	}
+	return s.openContent(document, document.SHA256)
+}
+
+// openContent decrypts the stored content with the given checksum, which the document refers
+// to. The checksum is bound to the ciphertext, so content cannot be swapped between
+// checksums. The caller must hold the mutex.
+func (s *DocumentService) openContent(document *models.Document, checksum string) ([]byte, error) {
+	stored, exists := s.contents[checksum]
+	if !exists || stored.sealed == nil {
+		return nil, fmt.Errorf("%w: document %s: content %s is missing", ErrDocumentIntegrity, document.ID, checksum)
+	}
+
+	content, err := s.keys.Open(stored.sealed, []byte(checksum))
+	if err != nil {
+		s.logger.Error("Document content could not be decrypted", logrus.Fields{
+			"documentId": document.ID,
+			"caseId":     document.CaseID,
+			"sha256":     checksum,
+			"keyId":      stored.sealed.KeyID,
+			"error":      err.Error(),
+		})
+		return nil, fmt.Errorf("%w: document %s: %w", ErrDocumentIntegrity, document.ID, err)
+	}
+	return content, nil
+}
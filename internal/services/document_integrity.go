@@ -0,0 +1,128 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/envelope"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrDocumentIntegrity is returned when stored document content no longer matches the
+// SHA-256 checksum taken when it was uploaded
+var ErrDocumentIntegrity = errors.New("document failed integrity check")
+
+// storedContent is document content shared by every document uploaded with the same bytes.
+// With encryption enabled it is held only sealed.
+type storedContent struct {
+	content []byte
+	sealed  *envelope.Envelope
+	refs    int
+}
+
+// contentSHA256 returns the hex SHA-256 checksum of content
+func contentSHA256(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// storeContent records the document's checksum and points its content at the single stored
+// copy of those bytes. With encryption enabled the document holds no content and is marked
+// ContentSealed instead. The caller must hold the mutex.
+func (s *DocumentService) storeContent(document *models.Document, checksum string) {
+	document.SHA256 = checksum
+	if len(document.Content) == 0 {
+		return
+	}
+
+	shared := s.shareContent(checksum, document.Content)
+	if stored := s.contents[checksum]; stored.refs > 1 {
+		s.logger.Info("Document content deduplicated", logrus.Fields{
+			"documentId": document.ID,
+			"caseId":     document.CaseID,
+			"sha256":     checksum,
+			"references": stored.refs,
+		})
+	}
+	document.Content = shared
+	document.ContentSealed = s.keys != nil
+}
+
+// shareContent returns the single stored copy of content with the given checksum, storing
+// content when it is new, and takes a reference to it. With encryption enabled the content
+// is stored sealed, bound to its checksum, and nil is returned. The caller must hold the mutex.
+func (s *DocumentService) shareContent(checksum string, content []byte) []byte {
+	if stored, exists := s.contents[checksum]; exists {
+		stored.refs++
+		return stored.content
+	}
+	if s.keys != nil {
+		s.contents[checksum] = &storedContent{sealed: s.keys.Seal(content, []byte(checksum)), refs: 1}
+		return nil
+	}
+	s.contents[checksum] = &storedContent{content: content, refs: 1}
+	return content
+}
+
+// releaseContent drops the document's references to its stored content and its redacted
+// content, freeing each once nothing refers to it. The caller must hold the mutex.
+func (s *DocumentService) releaseContent(document *models.Document) {
+	if len(document.Content) > 0 || document.ContentSealed {
+		s.unshareContent(document.SHA256)
+	}
+	if document.Redaction != nil && document.Redaction.SHA256 != "" {
+		s.unshareContent(document.Redaction.SHA256)
+	}
+}
+
+// unshareContent drops a reference to stored content. The caller must hold the mutex.
+func (s *DocumentService) unshareContent(checksum string) {
+	stored, exists := s.contents[checksum]
+	if !exists {
+		return
+	}
+	if stored.refs--; stored.refs <= 0 {
+		delete(s.contents, checksum)
+	}
+}
+
+// VerifyContent checks a document's content against the checksum taken at upload. Documents
+// without content, such as those rejected as infected, have nothing to verify.
+func (s *DocumentService) VerifyContent(document *models.Document) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.verifyContent(document)
+}
+
+// verifyContent is VerifyContent for a caller already holding the mutex. Redacted content is
+// checked against the checksum taken when it was redacted.
+func (s *DocumentService) verifyContent(document *models.Document) error {
+	if err := s.verifyChecksum(document, document.Content, document.SHA256); err != nil {
+		return err
+	}
+	if document.Redaction != nil {
+		return s.verifyChecksum(document, document.RedactedContent, document.Redaction.SHA256)
+	}
+	return nil
+}
+
+func (s *DocumentService) verifyChecksum(document *models.Document, content []byte, checksum string) error {
+	if content == nil || checksum == "" {
+		return nil
+	}
+	if actual := contentSHA256(content); actual != checksum {
+		s.logger.Error("Document content does not match its checksum", logrus.Fields{
+			"documentId": document.ID,
+			"caseId":     document.CaseID,
+			"expected":   checksum,
+			"actual":     actual,
+		})
+		return fmt.Errorf("%w: document %s", ErrDocumentIntegrity, document.ID)
+	}
+	return nil
+}
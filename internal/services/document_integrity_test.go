@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"mastercom-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentService_RecordsChecksum(t *testing.T) {
+	service := setupDocumentService()
+	content := []byte("%PDF-1.4 receipt")
+
+	document := models.NewDocument("case-1", "receipt.pdf", "application/pdf", content, "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+
+	sum := sha256.Sum256(content)
+	assert.Equal(t, hex.EncodeToString(sum[:]), document.SHA256)
+	assert.Equal(t, document.SHA256, models.NewDocumentResponse(document).SHA256)
+}
+
+func TestDocumentService_DeduplicatesContent(t *testing.T) {
+	service := setupDocumentService()
+
+	// The same receipt uploaded by two merchants
+	first := models.NewDocument("case-1", "receipt.pdf", "application/pdf", []byte("%PDF-1.4 receipt"), "merchant-1", "")
+	require.NoError(t, service.UploadDocument(first))
+	second := models.NewDocument("case-2", "scan.pdf", "application/pdf", []byte("%PDF-1.4 receipt"), "merchant-2", "")
+	require.NoError(t, service.UploadDocument(second))
+
+	// Each case keeps its own document, sharing one copy of the content
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, first.SHA256, second.SHA256)
+	assert.Same(t, &first.Content[0], &second.Content[0])
+	require.Len(t, service.contents, 1)
+	assert.Equal(t, 2, service.contents[first.SHA256].refs)
+
+	// Deleting one document leaves the other's content alone, and keeps its own for a restore
+	require.NoError(t, service.DeleteDocument(first.ID))
+	stored, err := service.GetDocument(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-1.4 receipt"), stored.Content)
+	assert.Equal(t, 2, service.contents[first.SHA256].refs)
+}
+
+func TestDocumentService_DetectsAlteredContent(t *testing.T) {
+	service := setupDocumentService()
+
+	document := models.NewDocument("case-1", "receipt.pdf", "application/pdf", []byte("%PDF-1.4 receipt"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+	document.Content[len(document.Content)-1] = 'X'
+
+	_, err := service.GetDocument(document.ID)
+	assert.ErrorIs(t, err, ErrDocumentIntegrity)
+	assert.ErrorIs(t, service.VerifyContent(document), ErrDocumentIntegrity)
+
+	_, err = service.GetDocument("nonexistent-id")
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+}
+
+func TestDocumentService_ReleasesInfectedContent(t *testing.T) {
+	service, _ := setupScanningDocumentService()
+
+	infected := models.NewDocument("case-1", "evidence.pdf", "application/pdf", []byte("%PDF-1.4 EICAR"), "test-user", "")
+	require.NoError(t, service.UploadDocument(infected))
+	service.RescanQuarantined(context.Background())
+	stored, err := service.GetDocument(infected.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusRejected, stored.ProcessingStatus)
+
+	// The checksum of what was uploaded is kept with the rejected document
+	assert.NotEmpty(t, stored.SHA256)
+	assert.Empty(t, service.contents)
+}
+
+func TestSubmissionWorker_RejectsAlteredDocuments(t *testing.T) {
+	client := &fakeCaseFilingClient{}
+	worker, caseService, documentService := setupSubmissionWorker(client)
+	caseObj := createSubmittedCase(t, caseService)
+
+	document := models.NewDocument(caseObj.ID, "evidence.pdf", "application/pdf", []byte("%PDF-1.4"), "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+	document.Content[0] = 'X'
+
+	worker.ProcessOnce(context.Background())
+	assert.Empty(t, client.requests)
+
+	rejected, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubmissionStatusRejected, rejected.SubmissionStatus)
+	require.Len(t, rejected.SubmissionErrors, 1)
+	assert.Contains(t, rejected.SubmissionErrors[0].Description, "integrity")
+}
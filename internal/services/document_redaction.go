@@ -0,0 +1,109 @@
+package services
+
+import (
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/docformat"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EnableRedaction masks card numbers in the text of PDFs uploaded from now on. The original
+// content is kept; the redacted derivative is stored alongside it as RedactedContent.
+func (s *DocumentService) EnableRedaction(config *models.RedactionConfig) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.redactionConfig = config
+}
+
+// OriginalAccessAllowed reports whether a caller holding roles may retrieve the original
+// content of a redacted document. Anyone may when redaction is disabled.
+func (s *DocumentService) OriginalAccessAllowed(roles []string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.redactionConfig == nil || !s.redactionConfig.Enabled {
+		return true
+	}
+	for _, role := range roles {
+		for _, allowed := range s.redactionConfig.OriginalRoles {
+			if role == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// awaitRedaction marks a PDF as pending redaction when redaction is enabled, so that its
+// content is not served before it has been checked for card numbers. The caller must hold
+// the mutex.
+func (s *DocumentService) awaitRedaction(document *models.Document) {
+	if s.redactionConfig == nil || !s.redactionConfig.Enabled || document.FileType != docformat.MIMETypePDF {
+		return
+	}
+	document.Redaction = &models.DocumentRedaction{Status: models.RedactionStatusPending}
+}
+
+// redactDocument masks the card numbers in a document pending redaction, once it is out of
+// quarantine. A document that cannot be read is marked failed, and its content is then only
+// served to privileged callers.
+func (s *DocumentService) redactDocument(document *models.Document) {
+	s.mutex.RLock()
+	pending := document.Redaction != nil && document.Redaction.Status == models.RedactionStatusPending &&
+		document.ProcessingStatus != models.DocumentStatusQuarantined
+	var content []byte
+	var err error
+	if pending {
+		content, err = s.documentContent(document)
+	}
+	s.mutex.RUnlock()
+	if !pending {
+		return
+	}
+
+	redaction := &models.DocumentRedaction{RedactedAt: time.Now()}
+	var redacted []byte
+	if err != nil {
+		redaction.Status = models.RedactionStatusFailed
+		redaction.Error = err.Error()
+	} else if content != nil {
+		redacted, redaction.CardNumbers, err = docformat.RedactPDF(content)
+		switch {
+		case err != nil:
+			redaction.Status = models.RedactionStatusFailed
+			redaction.Error = err.Error()
+		case redaction.CardNumbers == 0:
+			redaction.Status = models.RedactionStatusClean
+		default:
+			redaction.Status = models.RedactionStatusRedacted
+			redaction.SHA256 = contentSHA256(redacted)
+			redaction.FileSize = int64(len(redacted))
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fields := logrus.Fields{
+		"documentId": document.ID,
+		"caseId":     document.CaseID,
+		"version":    document.Version,
+	}
+	switch redaction.Status {
+	case "":
+		// Content discarded, such as by the malware scan; there is nothing to serve
+		document.Redaction = nil
+		return
+	case models.RedactionStatusFailed:
+		fields["error"] = redaction.Error
+		s.logger.Error("Document could not be checked for card numbers", fields)
+	case models.RedactionStatusRedacted:
+		document.RedactedContent = s.shareContent(redaction.SHA256, redacted)
+		fields["cardNumbers"] = redaction.CardNumbers
+		s.logger.Info("Card numbers redacted from document", fields)
+	}
+	document.Redaction = redaction
+}
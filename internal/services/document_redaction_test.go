@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/docformat"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textLinePDF returns a one page PDF showing a line of text
+func textLinePDF(text string) []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 72 700 Td (%s) Tj ET", text)
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	buf.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	buf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>\nendobj\n")
+	fmt.Fprintf(&buf, "4 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(content), content)
+	buf.WriteString("5 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>\nendobj\n")
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func setupRedactingDocumentService() *DocumentService {
+	service := NewDocumentService(logger.NewDatadogLogger())
+	service.EnableRedaction(&models.RedactionConfig{Enabled: true, OriginalRoles: []string{"supervisor", "admin"}})
+	return service
+}
+
+func TestDocumentService_RedactsCardNumbers(t *testing.T) {
+	service := setupRedactingDocumentService()
+
+	original := textLinePDF("Card 4111 1111 1111 1111")
+	document := models.NewDocument("case-1", "receipt.pdf", docformat.MIMETypePDF, original, "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+
+	require.NotNil(t, document.Redaction)
+	assert.Equal(t, models.RedactionStatusRedacted, document.Redaction.Status)
+	assert.Equal(t, 1, document.Redaction.CardNumbers)
+	assert.Equal(t, contentSHA256(document.RedactedContent), document.Redaction.SHA256)
+	assert.Equal(t, int64(len(document.RedactedContent)), document.Redaction.FileSize)
+	assert.Equal(t, original, document.Content, "the original is kept")
+	assert.NotContains(t, string(document.RedactedContent), "4111 1111 1111 1111")
+	assert.Contains(t, string(document.RedactedContent), "**** **** **** 1111")
+
+	// The redacted view swaps in the derivative without touching the document
+	redacted := document.Redacted()
+	assert.Equal(t, document.RedactedContent, redacted.Content)
+	assert.Equal(t, document.Redaction.SHA256, redacted.SHA256)
+	assert.Equal(t, contentSHA256(original), document.SHA256)
+
+	// Reads return a copy of the stored document
+	stored, err := service.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.NotSame(t, document, stored)
+	assert.Equal(t, document, stored)
+}
+
+func TestDocumentService_RedactionOutcomes(t *testing.T) {
+	service := setupRedactingDocumentService()
+
+	clean := models.NewDocument("case-1", "letter.pdf", docformat.MIMETypePDF, textLinePDF("Order 41111111111111111111"), "test-user", "")
+	require.NoError(t, service.UploadDocument(clean))
+	require.NotNil(t, clean.Redaction)
+	assert.Equal(t, models.RedactionStatusClean, clean.Redaction.Status)
+	assert.Nil(t, clean.RedactedContent)
+	assert.Same(t, clean, clean.Redacted())
+
+	unreadable := models.NewDocument("case-1", "scan.pdf", docformat.MIMETypePDF, []byte("%PDF-1.4 no objects"), "test-user", "")
+	require.NoError(t, service.UploadDocument(unreadable))
+	require.NotNil(t, unreadable.Redaction)
+	assert.Equal(t, models.RedactionStatusFailed, unreadable.Redaction.Status)
+	assert.NotEmpty(t, unreadable.Redaction.Error)
+
+	// Images are not redacted
+	image := models.NewDocument("case-1", "photo.jpg", docformat.MIMETypeJPEG, []byte("4111111111111111"), "test-user", "")
+	require.NoError(t, service.UploadDocument(image))
+	assert.Nil(t, image.Redaction)
+
+	// Nor is anything when redaction is disabled
+	disabled := setupDocumentService()
+	document := models.NewDocument("case-1", "receipt.pdf", docformat.MIMETypePDF, textLinePDF("4111111111111111"), "test-user", "")
+	require.NoError(t, disabled.UploadDocument(document))
+	assert.Nil(t, document.Redaction)
+}
+
+func TestDocumentService_RedactsAfterScan(t *testing.T) {
+	service, scanner := setupScanningDocumentService()
+	service.EnableRedaction(&models.RedactionConfig{Enabled: true})
+
+	scanner.setErr(errors.New("clamd unreachable"))
+	document := models.NewDocument("case-1", "receipt.pdf", docformat.MIMETypePDF, textLinePDF("4111111111111111"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+	assert.Equal(t, models.DocumentStatusQuarantined, document.ProcessingStatus)
+	require.NotNil(t, document.Redaction)
+	assert.Equal(t, models.RedactionStatusPending, document.Redaction.Status)
+
+	scanner.setErr(nil)
+	service.RescanQuarantined(t.Context())
+	document, err := service.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusReceived, document.ProcessingStatus)
+	assert.Equal(t, models.RedactionStatusRedacted, document.Redaction.Status)
+
+	// Infected content is discarded, leaving nothing to redact
+	infected := models.NewDocument("case-1", "eicar.pdf", docformat.MIMETypePDF, append(textLinePDF("4111111111111111"), "EICAR"...), "test-user", "")
+	require.NoError(t, service.UploadDocument(infected))
+	service.RescanQuarantined(t.Context())
+	infected, err = service.GetDocument(infected.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusRejected, infected.ProcessingStatus)
+	assert.Nil(t, infected.Redaction)
+}
+
+func TestDocumentService_RedactsReplacedContent(t *testing.T) {
+	service := setupRedactingDocumentService()
+
+	document := models.NewDocument("case-1", "receipt.pdf", docformat.MIMETypePDF, textLinePDF("no card here"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+	assert.Equal(t, models.RedactionStatusClean, document.Redaction.Status)
+
+	replacement := models.NewDocument("", "receipt.pdf", docformat.MIMETypePDF, textLinePDF("5555 5555 5555 4444"), "test-user", "")
+	require.NoError(t, service.ReplaceDocumentContent(document.ID, replacement))
+	assert.Equal(t, models.RedactionStatusRedacted, replacement.Redaction.Status)
+
+	first, err := service.GetDocumentVersion(document.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.RedactionStatusClean, first.Redaction.Status)
+}
+
+func TestDocumentService_DetectsAlteredRedactedContent(t *testing.T) {
+	service := setupRedactingDocumentService()
+
+	document := models.NewDocument("case-1", "receipt.pdf", docformat.MIMETypePDF, textLinePDF("4111111111111111"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+	require.NoError(t, service.VerifyContent(document))
+
+	document.RedactedContent[0] ^= 0xff
+	_, err := service.GetDocument(document.ID)
+	assert.ErrorIs(t, err, ErrDocumentIntegrity)
+}
+
+func TestDocumentService_OriginalAccessAllowed(t *testing.T) {
+	service := setupRedactingDocumentService()
+	assert.True(t, service.OriginalAccessAllowed([]string{"viewer", "supervisor"}))
+	assert.True(t, service.OriginalAccessAllowed([]string{"admin"}))
+	assert.False(t, service.OriginalAccessAllowed([]string{"viewer", "analyst"}))
+	assert.False(t, service.OriginalAccessAllowed(nil))
+
+	// Without redaction there is nothing to restrict
+	assert.True(t, setupDocumentService().OriginalAccessAllowed(nil))
+}
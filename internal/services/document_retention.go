@@ -0,0 +1,65 @@
+package services
+
+import (
+	"time"
+
+	"mastercom-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ListAllCaseDocuments returns the latest version of every document of a case, including
+// deleted ones, for retention sweeps
+func (s *DocumentService) ListAllCaseDocuments(caseID string) []*models.Document {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var documents []*models.Document
+	for _, document := range s.documents {
+		if document.CaseID == caseID {
+			documentCopy := *document
+			documents = append(documents, &documentCopy)
+		}
+	}
+	return documents
+}
+
+// ListDeletedDocuments returns the documents deleted before a time
+func (s *DocumentService) ListDeletedDocuments(deletedBefore time.Time) []*models.Document {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var documents []*models.Document
+	for _, document := range s.documents {
+		if document.DeletedAt != nil && document.DeletedAt.Before(deletedBefore) {
+			documentCopy := *document
+			documents = append(documents, &documentCopy)
+		}
+	}
+	return documents
+}
+
+// PurgeDocument permanently removes a document, deleted or not, with all its versions,
+// freeing content that no other document shares. It returns the number of versions purged.
+func (s *DocumentService) PurgeDocument(documentID string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	document, exists := s.documents[documentID]
+	if !exists {
+		return 0, ErrDocumentNotFound
+	}
+
+	versions := append(s.versions[documentID], document)
+	for _, version := range versions {
+		s.releaseContent(version)
+	}
+	delete(s.versions, documentID)
+	delete(s.documents, documentID)
+	s.logger.Info("Document purged", logrus.Fields{
+		"documentId": documentID,
+		"caseId":     document.CaseID,
+		"versions":   len(versions),
+	})
+	return len(versions), nil
+}
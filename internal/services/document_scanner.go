@@ -0,0 +1,233 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/clamd"
+	"mastercom-service/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrDocumentInfected is returned when a malware scan finds an uploaded document infected
+	ErrDocumentInfected = errors.New("malware detected")
+	// ErrDocumentUnscannable is returned when a quarantined document could not be scanned
+	// within the allowed attempts
+	ErrDocumentUnscannable = errors.New("malware scan failed")
+)
+
+// scanQueueSize bounds the documents waiting for the DocumentScanWorker
+const scanQueueSize = 256
+
+// Scanner inspects document content for malware
+type Scanner interface {
+	// Name identifies the scanner in scan results
+	Name() string
+	// Scan returns the name of the malware found in content, or "" when it is clean
+	Scan(ctx context.Context, content []byte) (string, error)
+}
+
+// clamdScanner scans documents with a ClamAV daemon
+type clamdScanner struct {
+	client *clamd.Client
+}
+
+// NewClamdScanner creates a scanner backed by a ClamAV daemon
+func NewClamdScanner(client *clamd.Client) Scanner {
+	return &clamdScanner{client: client}
+}
+
+func (s *clamdScanner) Name() string {
+	return "clamav"
+}
+
+func (s *clamdScanner) Scan(ctx context.Context, content []byte) (string, error) {
+	result, err := s.client.Scan(ctx, bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	return result.Signature, nil
+}
+
+// EnableScanning quarantines documents uploaded from now on until scanner finds them clean.
+// Generated documents, such as evidence bundles, are built from scanned documents and are
+// not scanned again.
+func (s *DocumentService) EnableScanning(scanner Scanner, config *models.ScanConfig) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.scanner = scanner
+	s.scanConfig = config
+	s.scanQueue = make(chan *models.Document, scanQueueSize)
+}
+
+// queueScan hands a quarantined document to the DocumentScanWorker. When the queue is full
+// the document is left for the worker's next rescan of quarantined documents.
+func (s *DocumentService) queueScan(document *models.Document) {
+	select {
+	case s.scanQueue <- document:
+	default:
+		s.logger.Info("Malware scan queue full, document waits for the next rescan", logrus.Fields{
+			"documentId": document.ID,
+			"caseId":     document.CaseID,
+		})
+	}
+}
+
+// scanDocument scans a quarantined document and records the result. A clean document is
+// released for processing; an infected one is rejected and its content discarded. A failed
+// scan leaves the document quarantined for a later attempt, until the attempts run out.
+func (s *DocumentService) scanDocument(ctx context.Context, document *models.Document) error {
+	if timeout := time.Duration(s.scanConfig.Timeout) * time.Second; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	s.mutex.RLock()
+	content, scanErr := s.documentContent(document)
+	s.mutex.RUnlock()
+	var signature string
+	if scanErr == nil {
+		signature, scanErr = s.scanner.Scan(ctx, content)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Replaced or already released while the scan ran
+	if current, exists := s.documents[document.ID]; !exists || current != document || current.ProcessingStatus != models.DocumentStatusQuarantined {
+		return nil
+	}
+
+	attempts := 1
+	if document.Scan != nil {
+		attempts = document.Scan.Attempts + 1
+	}
+	document.Scan = &models.DocumentScan{
+		Scanner:   s.scanner.Name(),
+		Attempts:  attempts,
+		ScannedAt: time.Now(),
+	}
+	fields := logrus.Fields{
+		"documentId": document.ID,
+		"caseId":     document.CaseID,
+		"filename":   document.FileName,
+		"uploadedBy": document.UploadedBy,
+		"scanner":    document.Scan.Scanner,
+		"attempts":   attempts,
+	}
+
+	switch {
+	case scanErr != nil:
+		document.Scan.Status = models.ScanStatusFailed
+		document.Scan.Error = scanErr.Error()
+		fields["error"] = scanErr.Error()
+		if attempts < s.scanConfig.MaxAttempts {
+			s.logger.Error("Malware scan failed, document stays quarantined", fields)
+			return nil
+		}
+		document.SetProcessingStatus(models.DocumentStatusRejected, fmt.Sprintf("Malware scan failed %d times", attempts))
+		s.logger.Error("Document rejected after failed malware scans", fields)
+		return fmt.Errorf("%w: %w: %v", ErrDocumentRejected, ErrDocumentUnscannable, scanErr)
+
+	case signature != "":
+		document.Scan.Status = models.ScanStatusInfected
+		document.Scan.Signature = signature
+		s.releaseContent(document)
+		document.Content = nil
+		document.ContentSealed = false
+		document.SetProcessingStatus(models.DocumentStatusRejected, "Malware detected: "+signature)
+		fields["signature"] = signature
+		s.logger.Error("Infected document rejected", fields)
+		return fmt.Errorf("%w: %w: %s", ErrDocumentRejected, ErrDocumentInfected, signature)
+
+	default:
+		document.Scan.Status = models.ScanStatusClean
+		document.SetProcessingStatus(models.DocumentStatusReceived, "")
+		s.logger.Info("Document scanned clean", fields)
+		return nil
+	}
+}
+
+// RescanQuarantined retries the scan of every quarantined document and returns the number
+// of documents scanned
+func (s *DocumentService) RescanQuarantined(ctx context.Context) int {
+	s.mutex.RLock()
+	scanning := s.scanner != nil
+	var quarantined []*models.Document
+	for _, document := range s.documents {
+		if document.ProcessingStatus == models.DocumentStatusQuarantined {
+			quarantined = append(quarantined, document)
+		}
+	}
+	s.mutex.RUnlock()
+
+	if !scanning {
+		return 0
+	}
+
+	scanned := 0
+	for _, document := range quarantined {
+		if ctx.Err() != nil {
+			break
+		}
+		s.scanDocument(ctx, document)
+		s.redactDocument(document)
+		scanned++
+	}
+	return scanned
+}
+
+// DocumentScanWorker retries the malware scans of quarantined documents, such as those
+// uploaded while the scanner was unreachable
+type DocumentScanWorker struct {
+	documentService *DocumentService
+	config          *models.ScanConfig
+	logger          *logger.DatadogLogger
+}
+
+// NewDocumentScanWorker creates a new document scan worker
+func NewDocumentScanWorker(documentService *DocumentService, config *models.ScanConfig, logger *logger.DatadogLogger) *DocumentScanWorker {
+	return &DocumentScanWorker{
+		documentService: documentService,
+		config:          config,
+		logger:          logger,
+	}
+}
+
+// Run scans documents as they are quarantined, and rescans those still quarantined, until
+// ctx is cancelled
+func (w *DocumentScanWorker) Run(ctx context.Context) {
+	w.documentService.mutex.RLock()
+	queue := w.documentService.scanQueue
+	w.documentService.mutex.RUnlock()
+
+	interval := time.Duration(w.config.RescanInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	w.logger.Info("Document scan worker started", logrus.Fields{"interval": interval.String()})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Document scan worker stopped", nil)
+			return
+		case document := <-queue:
+			w.documentService.scanDocument(ctx, document)
+			w.documentService.redactDocument(document)
+		case <-ticker.C:
+			w.documentService.RescanQuarantined(ctx)
+		}
+	}
+}
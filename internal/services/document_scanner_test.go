@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/docformat"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner flags content containing "EICAR" and fails while err is set
+type fakeScanner struct {
+	mutex sync.Mutex
+	err   error
+	calls int
+}
+
+func (s *fakeScanner) Name() string {
+	return "fake"
+}
+
+func (s *fakeScanner) Scan(ctx context.Context, content []byte) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.calls++
+	if s.err != nil {
+		return "", s.err
+	}
+	if bytes.Contains(content, []byte("EICAR")) {
+		return "Eicar-Test-Signature", nil
+	}
+	return "", nil
+}
+
+func (s *fakeScanner) setErr(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err
+}
+
+func setupScanningDocumentService() (*DocumentService, *fakeScanner) {
+	service := NewDocumentService(logger.NewDatadogLogger())
+	scanner := &fakeScanner{}
+	service.EnableScanning(scanner, &models.ScanConfig{Timeout: 5, RescanInterval: 60, MaxAttempts: 3})
+	return service, scanner
+}
+
+func TestDocumentService_ScansUploads(t *testing.T) {
+	service, _ := setupScanningDocumentService()
+
+	clean := models.NewDocument("case-1", "evidence.pdf", "application/pdf", []byte("%PDF-1.4 clean"), "test-user", "")
+	require.NoError(t, service.UploadDocument(clean))
+	assert.Equal(t, models.DocumentStatusQuarantined, clean.ProcessingStatus)
+	assert.Nil(t, clean.Scan)
+
+	assert.Equal(t, 1, service.RescanQuarantined(context.Background()))
+	clean, err := service.GetDocument(clean.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusReceived, clean.ProcessingStatus)
+	require.NotNil(t, clean.Scan)
+	assert.Equal(t, models.ScanStatusClean, clean.Scan.Status)
+	assert.Equal(t, "fake", clean.Scan.Scanner)
+	assert.Equal(t, 1, clean.Scan.Attempts)
+
+	// Infected documents are kept as rejected, without their content
+	infected := models.NewDocument("case-1", "evidence.pdf", "application/pdf", []byte("%PDF-1.4 EICAR"), "test-user", "")
+	require.NoError(t, service.UploadDocument(infected))
+	service.RescanQuarantined(context.Background())
+
+	stored, err := service.GetDocument(infected.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusRejected, stored.ProcessingStatus)
+	assert.Equal(t, "Malware detected: Eicar-Test-Signature", stored.StatusReason)
+	assert.Equal(t, models.ScanStatusInfected, stored.Scan.Status)
+	assert.Equal(t, "Eicar-Test-Signature", stored.Scan.Signature)
+	assert.Nil(t, stored.Content)
+}
+
+func TestDocumentService_QuarantinesUntilScanned(t *testing.T) {
+	service, scanner := setupScanningDocumentService()
+	scanner.setErr(errors.New("clamd: connection refused"))
+
+	document := models.NewDocument("case-1", "evidence.pdf", "application/pdf", []byte("%PDF-1.4"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+	service.RescanQuarantined(context.Background())
+	document, err := service.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusQuarantined, document.ProcessingStatus)
+	assert.Equal(t, models.ScanStatusFailed, document.Scan.Status)
+	assert.Equal(t, "clamd: connection refused", document.Scan.Error)
+
+	// Released once the scanner is back
+	scanner.setErr(nil)
+	assert.Equal(t, 1, service.RescanQuarantined(context.Background()))
+	document, err = service.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusReceived, document.ProcessingStatus)
+	assert.Equal(t, models.ScanStatusClean, document.Scan.Status)
+	assert.Equal(t, 2, document.Scan.Attempts)
+	assert.Equal(t, 0, service.RescanQuarantined(context.Background()))
+}
+
+func TestDocumentService_RejectsAfterFailedScans(t *testing.T) {
+	service, scanner := setupScanningDocumentService()
+	scanner.setErr(errors.New("clamd: i/o timeout"))
+
+	document := models.NewDocument("case-1", "evidence.pdf", "application/pdf", []byte("%PDF-1.4"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+	service.RescanQuarantined(context.Background())
+	service.RescanQuarantined(context.Background())
+	document, err := service.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusQuarantined, document.ProcessingStatus)
+
+	service.RescanQuarantined(context.Background())
+	document, err = service.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusRejected, document.ProcessingStatus)
+	assert.Equal(t, 3, document.Scan.Attempts)
+	assert.Equal(t, "Malware scan failed 3 times", document.StatusReason)
+}
+
+func TestDocumentService_SkipsScanningGeneratedDocuments(t *testing.T) {
+	service, scanner := setupScanningDocumentService()
+
+	bundle := models.NewDocument("case-1", "bundle.pdf", "application/pdf", []byte("%PDF-1.4"), "test-user", "")
+	bundle.DerivedFrom = []string{"source-id"}
+	require.NoError(t, service.UploadDocument(bundle))
+	assert.Equal(t, models.DocumentStatusReceived, bundle.ProcessingStatus)
+	assert.Nil(t, bundle.Scan)
+	assert.Equal(t, 0, scanner.calls)
+}
+
+func TestCaseDocumentService_AttachInfectedDocument(t *testing.T) {
+	service, caseService, documentService := setupCaseDocumentService()
+	documentService.EnableScanning(&fakeScanner{}, &models.ScanConfig{MaxAttempts: 3})
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	document := models.NewDocument("", "evidence.pdf", "application/pdf", []byte("EICAR"), "test-user", "")
+	require.NoError(t, service.AttachDocument(caseObj.ID, document))
+	assert.Equal(t, models.DocumentStatusQuarantined, document.ProcessingStatus)
+	assert.Equal(t, 1, documentService.RescanQuarantined(context.Background()))
+
+	// The rejected document stays listed on the case with its scan result
+	documents, err := service.ListDocuments(caseObj.ID)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, models.DocumentStatusRejected, documents[0].ProcessingStatus)
+	assert.Equal(t, models.ScanStatusInfected, documents[0].Scan.Status)
+}
+
+func TestDocumentScanWorker_ScansUploadsAsTheyArrive(t *testing.T) {
+	service, _ := setupScanningDocumentService()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The rescan interval is far longer than the test, so only the queue can release uploads
+	go NewDocumentScanWorker(service, &models.ScanConfig{RescanInterval: 3600}, logger.NewDatadogLogger()).Run(ctx)
+
+	clean := models.NewDocument("case-1", "receipt.pdf", "application/pdf", []byte("%PDF-1.4"), "test-user", "")
+	infected := models.NewDocument("case-1", "evidence.pdf", "application/pdf", []byte("%PDF-1.4 EICAR"), "test-user", "")
+	require.NoError(t, service.UploadDocument(clean))
+	require.NoError(t, service.UploadDocument(infected))
+
+	status := func(document *models.Document) string {
+		service.mutex.RLock()
+		defer service.mutex.RUnlock()
+		return service.documents[document.ID].ProcessingStatus
+	}
+	assert.Eventually(t, func() bool {
+		return status(clean) == models.DocumentStatusReceived && status(infected) == models.DocumentStatusRejected
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// Run with -race: the uploader's document and every read are copies that the scan and
+// redaction workers do not write to
+func TestDocumentService_ReadsWhileScanning(t *testing.T) {
+	service, _ := setupScanningDocumentService()
+	service.EnableRedaction(&models.RedactionConfig{Enabled: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewDocumentScanWorker(service, &models.ScanConfig{RescanInterval: 3600}, logger.NewDatadogLogger()).Run(ctx)
+
+	document := models.NewDocument("case-1", "receipt.pdf", docformat.MIMETypePDF, textLinePDF("4111111111111111"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+
+	assert.Eventually(t, func() bool {
+		read, err := service.GetDocument(document.ID)
+		require.NoError(t, err)
+		versions, err := service.ListDocumentVersions(document.ID)
+		require.NoError(t, err)
+		return read.ProcessingStatus == models.DocumentStatusReceived && versions[0].Scan != nil &&
+			read.Redaction != nil && read.Redaction.Status == models.RedactionStatusRedacted &&
+			len(read.Redacted().Content) > 0
+	}, 5*time.Second, time.Millisecond)
+
+	// The uploader's document stays as it was uploaded
+	assert.Equal(t, models.DocumentStatusQuarantined, document.ProcessingStatus)
+	assert.Nil(t, document.Scan)
+	assert.Equal(t, models.RedactionStatusPending, document.Redaction.Status)
+}
@@ -2,79 +2,288 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"mastercom-service/internal/models"
+	"mastercom-service/pkg/envelope"
 	"mastercom-service/pkg/logger"
 
 	"github.com/sirupsen/logrus"
 )
 
+// caseFilingProcessDateLayout is the MM/DD/YYYY HH:MM:SS AM/PM process date in case filing statuses
+const caseFilingProcessDateLayout = "1/2/2006 3:04:05 PM"
+
+var (
+	// ErrInvalidCaseFilingImageStatusRequest is returned when an image status search is malformed
+	ErrInvalidCaseFilingImageStatusRequest = errors.New("invalid case filing image status request")
+	// ErrDocumentNotFound is returned for unknown document IDs
+	ErrDocumentNotFound = errors.New("document not found")
+)
+
 type DocumentService struct {
 	documents map[string]*models.Document
-	mutex     sync.RWMutex
-	logger    *logger.DatadogLogger
+	// versions holds the superseded versions of each document, oldest first
+	versions map[string][]*models.Document
+	// contents holds each distinct document content once, keyed by its SHA-256 checksum
+	contents map[string]*storedContent
+	mutex    sync.RWMutex
+	logger   *logger.DatadogLogger
+	// scanner, when set, holds uploaded documents in quarantine until they are scanned clean
+	scanner    Scanner
+	scanConfig *models.ScanConfig
+	// scanQueue hands quarantined documents to the DocumentScanWorker as they arrive
+	scanQueue chan *models.Document
+	// redactionConfig, when enabled, has card numbers masked in a derivative of uploaded PDFs
+	redactionConfig *models.RedactionConfig
+	// keys, when set, encrypts stored content
+	keys *envelope.KeyRing
 }
 
 func NewDocumentService(logger *logger.DatadogLogger) *DocumentService {
 	return &DocumentService{
 		documents: make(map[string]*models.Document),
+		versions:  make(map[string][]*models.Document),
+		contents:  make(map[string]*storedContent),
 		logger:    logger,
 	}
 }
 
+// UploadDocument stores a document as its first version, recording the SHA-256 checksum of
+// its content. Content already stored for another document is shared rather than stored
+// again. When scanning is enabled the document is stored quarantined and queued for the
+// DocumentScanWorker, which releases or rejects it once scanned. When redaction is enabled a
+// PDF is redacted once it is out of quarantine.
 func (s *DocumentService) UploadDocument(document *models.Document) error {
+	checksum := contentSHA256(document.Content)
+
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
 
 	// Check if document already exists
 	if _, exists := s.documents[document.ID]; exists {
+		s.mutex.Unlock()
 		return errors.New("document already exists")
 	}
 
-	// Store the document
-	s.documents[document.ID] = document
+	document.Version = 1
+	scan := s.quarantine(document)
+	s.awaitRedaction(document)
+
+	// Store a copy of the document, so the scan and redaction workers never update the
+	// caller's
+	s.storeContent(document, checksum)
+	stored := *document
+	s.documents[document.ID] = &stored
 	s.logger.Info("Document uploaded successfully", logrus.Fields{"documentId": document.ID})
+	s.mutex.Unlock()
+
+	if scan {
+		s.queueScan(&stored)
+		return nil
+	}
+	s.redactDocument(&stored)
+	s.snapshotDocument(document, &stored)
 	return nil
 }
 
+// snapshotDocument copies a stored document into the caller's document
+func (s *DocumentService) snapshotDocument(document, stored *models.Document) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	*document = *stored
+}
+
+// quarantine holds a document for a malware scan when scanning is enabled, and reports
+// whether it was. Generated documents are not scanned. The caller must hold the mutex.
+func (s *DocumentService) quarantine(document *models.Document) bool {
+	if s.scanner == nil || len(document.DerivedFrom) > 0 {
+		return false
+	}
+	document.ProcessingStatus = models.DocumentStatusQuarantined
+	document.StatusReason = "Awaiting malware scan"
+	return true
+}
+
+// GetDocument returns a document after verifying its content against its checksum; content
+// that has changed since upload returns an error wrapping ErrDocumentIntegrity
 func (s *DocumentService) GetDocument(documentID string) (*models.Document, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	document, exists := s.documents[documentID]
-	if !exists {
-		return nil, errors.New("document not found")
+	if !exists || document.DeletedAt != nil {
+		return nil, ErrDocumentNotFound
+	}
+	document, err := s.openDocument(document)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyContent(document); err != nil {
+		return nil, err
 	}
 
 	return document, nil
 }
 
+// DeleteDocument marks a document deleted. It is hidden from reads and case listings, with
+// its versions and content kept so that RestoreDocument can bring it back.
 func (s *DocumentService) DeleteDocument(documentID string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	// Check if document exists
-	if _, exists := s.documents[documentID]; !exists {
-		return errors.New("document not found")
+	document, exists := s.documents[documentID]
+	if !exists || document.DeletedAt != nil {
+		return ErrDocumentNotFound
 	}
 
 	// Delete the document
-	delete(s.documents, documentID)
+	now := time.Now()
+	document.DeletedAt = &now
 	s.logger.Info("Document deleted successfully", logrus.Fields{"documentId": documentID})
 	return nil
 }
 
+// GetDocumentsByCaseID returns the latest version of a case's documents that are not deleted,
+// without verifying their content; callers that use the content check it with VerifyContent
 func (s *DocumentService) GetDocumentsByCaseID(caseID string) ([]*models.Document, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	var documents []*models.Document
 	for _, document := range s.documents {
-		if document.CaseID == caseID {
-			documents = append(documents, document)
+		if document.CaseID == caseID && document.DeletedAt == nil {
+			opened, err := s.openDocument(document)
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, opened)
 		}
 	}
 
 	return documents, nil
 }
+
+// UpdateDocumentStatus moves a document to a new processing status
+func (s *DocumentService) UpdateDocumentStatus(documentID, status, reason string) (*models.Document, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	document, exists := s.documents[documentID]
+	if !exists || document.DeletedAt != nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	previous := document.ProcessingStatus
+	if err := document.SetProcessingStatus(status, reason); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Document status updated", logrus.Fields{
+		"documentId": documentID,
+		"caseId":     document.CaseID,
+		"from":       previous,
+		"to":         status,
+	})
+	updated := *document
+	return &updated, nil
+}
+
+// GetCaseFilingStatus returns the aggregate document status of each case, in request order
+func (s *DocumentService) GetCaseFilingStatus(caseIDs []string) []models.CaseFilingStatusResponseStructure {
+	byCase := s.documentsByCase()
+
+	statuses := make([]models.CaseFilingStatusResponseStructure, 0, len(caseIDs))
+	for _, caseID := range caseIDs {
+		status := models.CaseImageStatusUnavailable
+		if documents := byCase[caseID]; len(documents) > 0 {
+			imageStatus, processedAt := caseImageStatus(documents)
+			status = fmt.Sprintf("%s_%s_%s", imageStatus, models.CaseFilingPartySender, processedAt.Format(caseFilingProcessDateLayout))
+		}
+		statuses = append(statuses, models.CaseFilingStatusResponseStructure{CaseID: caseID, Status: status})
+	}
+
+	return statuses
+}
+
+// GetCaseFilingImageStatus returns the cases whose documents have the requested status,
+// last processed within the date range
+func (s *DocumentService) GetCaseFilingImageStatus(req *models.CaseFilingImageStatusRequest) ([]models.CaseFilingImageStatusResponseStructure, error) {
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: startDate must be yyyy-MM-dd", ErrInvalidCaseFilingImageStatusRequest)
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: endDate must be yyyy-MM-dd", ErrInvalidCaseFilingImageStatusRequest)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: endDate is before startDate", ErrInvalidCaseFilingImageStatusRequest)
+	}
+	end = end.AddDate(0, 0, 1)
+
+	statuses := []models.CaseFilingImageStatusResponseStructure{}
+	for caseID, documents := range s.documentsByCase() {
+		imageStatus, processedAt := caseImageStatus(documents)
+		if imageStatus == models.CaseImageStatusPending {
+			imageStatus = models.CaseImageStatusUnprocessed
+		}
+		if imageStatus != req.Status || processedAt.Before(start) || !processedAt.Before(end) {
+			continue
+		}
+		statuses = append(statuses, models.CaseFilingImageStatusResponseStructure{CaseID: caseID, Status: imageStatus})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].CaseID < statuses[j].CaseID
+	})
+
+	return statuses, nil
+}
+
+// documentsByCase groups snapshots of every document by case ID
+func (s *DocumentService) documentsByCase() map[string][]models.Document {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	byCase := make(map[string][]models.Document)
+	for _, document := range s.documents {
+		if document.DeletedAt != nil {
+			continue
+		}
+		byCase[document.CaseID] = append(byCase[document.CaseID], *document)
+	}
+	return byCase
+}
+
+// caseImageStatus aggregates a case's documents into a single image status: FAILED if any
+// document was rejected, COMPLETED once every document is delivered and PENDING otherwise.
+// It also returns when a document's status last changed.
+func caseImageStatus(documents []models.Document) (string, time.Time) {
+	var processedAt time.Time
+	rejected, delivered := false, 0
+	for _, document := range documents {
+		switch document.ProcessingStatus {
+		case models.DocumentStatusRejected:
+			rejected = true
+		case models.DocumentStatusDelivered:
+			delivered++
+		}
+		if document.StatusUpdatedAt.After(processedAt) {
+			processedAt = document.StatusUpdatedAt
+		}
+	}
+
+	switch {
+	case rejected:
+		return models.CaseImageStatusFailed, processedAt
+	case delivered == len(documents):
+		return models.CaseImageStatusCompleted, processedAt
+	default:
+		return models.CaseImageStatusPending, processedAt
+	}
+}
@@ -5,14 +5,14 @@ import (
 	"time"
 
 	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
 
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func setupDocumentService() *DocumentService {
-	logger := logrus.New()
+	logger := logger.NewDatadogLogger()
 	return NewDocumentService(logger)
 }
 
@@ -255,3 +255,95 @@ func TestDocumentService_ConcurrentAccess(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestDocumentService_UpdateDocumentStatus(t *testing.T) {
+	service := setupDocumentService()
+	document := models.NewDocument("case-1", "evidence.pdf", ".pdf", []byte("content"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+	assert.Equal(t, models.DocumentStatusReceived, document.ProcessingStatus)
+
+	updated, err := service.UpdateDocumentStatus(document.ID, models.DocumentStatusValidated, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusValidated, updated.ProcessingStatus)
+
+	// Skipping conversion is not allowed
+	_, err = service.UpdateDocumentStatus(document.ID, models.DocumentStatusDelivered, "")
+	assert.ErrorIs(t, err, models.ErrInvalidDocumentStatusTransition)
+
+	updated, err = service.UpdateDocumentStatus(document.ID, models.DocumentStatusRejected, "unsupported format")
+	require.NoError(t, err)
+	assert.Equal(t, "unsupported format", updated.StatusReason)
+
+	// Rejected is final
+	_, err = service.UpdateDocumentStatus(document.ID, models.DocumentStatusValidated, "")
+	assert.ErrorIs(t, err, models.ErrInvalidDocumentStatusTransition)
+
+	_, err = service.UpdateDocumentStatus("nonexistent-id", models.DocumentStatusValidated, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func deliverDocument(t *testing.T, service *DocumentService, documentID string) {
+	for _, status := range []string{models.DocumentStatusValidated, models.DocumentStatusConverted, models.DocumentStatusDelivered} {
+		_, err := service.UpdateDocumentStatus(documentID, status, "")
+		require.NoError(t, err)
+	}
+}
+
+func TestDocumentService_GetCaseFilingStatus(t *testing.T) {
+	service := setupDocumentService()
+
+	delivered := models.NewDocument("case-completed", "a.pdf", ".pdf", []byte("a"), "test-user", "")
+	require.NoError(t, service.UploadDocument(delivered))
+	deliverDocument(t, service, delivered.ID)
+
+	pending := models.NewDocument("case-pending", "b.pdf", ".pdf", []byte("b"), "test-user", "")
+	require.NoError(t, service.UploadDocument(pending))
+	otherDelivered := models.NewDocument("case-pending", "c.pdf", ".pdf", []byte("c"), "test-user", "")
+	require.NoError(t, service.UploadDocument(otherDelivered))
+	deliverDocument(t, service, otherDelivered.ID)
+
+	rejected := models.NewDocument("case-failed", "d.pdf", ".pdf", []byte("d"), "test-user", "")
+	require.NoError(t, service.UploadDocument(rejected))
+	_, err := service.UpdateDocumentStatus(rejected.ID, models.DocumentStatusRejected, "")
+	require.NoError(t, err)
+
+	statuses := service.GetCaseFilingStatus([]string{"case-completed", "case-pending", "case-failed", "case-none"})
+	require.Len(t, statuses, 4)
+	assert.Equal(t, "case-completed", statuses[0].CaseID)
+	assert.Regexp(t, `^COMPLETED_SND_\d{1,2}/\d{1,2}/\d{4} \d{1,2}:\d{2}:\d{2} (AM|PM)$`, statuses[0].Status)
+	assert.Regexp(t, `^PENDING_SND_`, statuses[1].Status)
+	assert.Regexp(t, `^FAILED_SND_`, statuses[2].Status)
+	assert.Equal(t, models.CaseImageStatusUnavailable, statuses[3].Status)
+}
+
+func TestDocumentService_GetCaseFilingImageStatus(t *testing.T) {
+	service := setupDocumentService()
+
+	delivered := models.NewDocument("case-completed", "a.pdf", ".pdf", []byte("a"), "test-user", "")
+	require.NoError(t, service.UploadDocument(delivered))
+	deliverDocument(t, service, delivered.ID)
+
+	received := models.NewDocument("case-unprocessed", "b.pdf", ".pdf", []byte("b"), "test-user", "")
+	require.NoError(t, service.UploadDocument(received))
+
+	today := time.Now().Format("2006-01-02")
+
+	statuses, err := service.GetCaseFilingImageStatus(&models.CaseFilingImageStatusRequest{Status: models.CaseImageStatusCompleted, StartDate: today, EndDate: today})
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "case-completed", statuses[0].CaseID)
+
+	statuses, err = service.GetCaseFilingImageStatus(&models.CaseFilingImageStatusRequest{Status: models.CaseImageStatusUnprocessed, StartDate: today, EndDate: today})
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "case-unprocessed", statuses[0].CaseID)
+
+	// A range excluding every document
+	statuses, err = service.GetCaseFilingImageStatus(&models.CaseFilingImageStatusRequest{Status: models.CaseImageStatusCompleted, StartDate: "2017-01-01", EndDate: "2017-01-31"})
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+
+	_, err = service.GetCaseFilingImageStatus(&models.CaseFilingImageStatusRequest{Status: models.CaseImageStatusCompleted, StartDate: "2024-02-01", EndDate: "2024-01-01"})
+	assert.ErrorIs(t, err, ErrInvalidCaseFilingImageStatusRequest)
+}
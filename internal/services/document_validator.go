@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/docformat"
+)
+
+// ErrDocumentRejected is returned when an uploaded document is not acceptable case evidence
+var ErrDocumentRejected = errors.New("document rejected")
+
+// DocumentValidationError lists every reason a document was rejected
+type DocumentValidationError struct {
+	Violations []models.DocumentViolation
+}
+
+func (e *DocumentValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrDocumentRejected, strings.Join(messages, "; "))
+}
+
+func (e *DocumentValidationError) Unwrap() error {
+	return ErrDocumentRejected
+}
+
+// DocumentValidator checks uploaded documents against the formats accepted as case evidence,
+// identifying each document by its content rather than its file name
+type DocumentValidator struct {
+	rules    map[string]models.DocumentTypeRule
+	accepted []string
+}
+
+// NewDocumentValidator creates a validator accepting the configured document types
+func NewDocumentValidator(config *models.DocumentValidationConfig) *DocumentValidator {
+	validator := &DocumentValidator{rules: make(map[string]models.DocumentTypeRule)}
+	for _, rule := range config.Types {
+		validator.rules[rule.MIMEType] = rule
+		validator.accepted = append(validator.accepted, rule.MIMEType)
+	}
+	return validator
+}
+
+// MaxSize returns the size of the largest document any accepted type allows, or 0 when
+// some type is unlimited
+func (v *DocumentValidator) MaxSize() int64 {
+	var largest int64
+	for _, rule := range v.rules {
+		if rule.MaxSize <= 0 {
+			return 0
+		}
+		if rule.MaxSize > largest {
+			largest = rule.MaxSize
+		}
+	}
+	return largest
+}
+
+// Validate returns the MIME type detected from a document's content, or a
+// *DocumentValidationError listing every way the document breaks the rules for its type
+func (v *DocumentValidator) Validate(fileName string, content []byte) (string, error) {
+	if len(content) == 0 {
+		return "", &DocumentValidationError{Violations: []models.DocumentViolation{{
+			Code:    models.DocumentViolationEmpty,
+			Message: "file is empty",
+		}}}
+	}
+
+	mimeType := docformat.Detect(content)
+	rule, ok := v.rules[mimeType]
+	if !ok {
+		return mimeType, &DocumentValidationError{Violations: []models.DocumentViolation{{
+			Code:    models.DocumentViolationUnsupportedType,
+			Message: fmt.Sprintf("file content is %s; accepted types are %s", mimeType, strings.Join(v.accepted, ", ")),
+		}}}
+	}
+
+	var violations []models.DocumentViolation
+	if extension := strings.ToLower(filepath.Ext(fileName)); !containsString(rule.Extensions, extension) {
+		violations = append(violations, models.DocumentViolation{
+			Code:    models.DocumentViolationExtensionMismatch,
+			Message: fmt.Sprintf("file name extension %q does not match %s content; expected %s", extension, mimeType, strings.Join(rule.Extensions, " or ")),
+		})
+	}
+
+	if size := int64(len(content)); rule.MaxSize > 0 && size > rule.MaxSize {
+		violations = append(violations, models.DocumentViolation{
+			Code:    models.DocumentViolationTooLarge,
+			Message: fmt.Sprintf("%s files may be at most %d bytes", mimeType, rule.MaxSize),
+			Limit:   rule.MaxSize,
+			Actual:  size,
+		})
+	}
+
+	if rule.MaxPages > 0 {
+		pages, err := docformat.PageCount(mimeType, content)
+		switch {
+		case err != nil:
+			violations = append(violations, models.DocumentViolation{
+				Code:    models.DocumentViolationUnreadable,
+				Message: fmt.Sprintf("page count could not be read: %v", err),
+			})
+		case pages > rule.MaxPages:
+			violations = append(violations, models.DocumentViolation{
+				Code:    models.DocumentViolationTooManyPages,
+				Message: fmt.Sprintf("%s files may have at most %d pages", mimeType, rule.MaxPages),
+				Limit:   int64(rule.MaxPages),
+				Actual:  int64(pages),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return mimeType, &DocumentValidationError{Violations: violations}
+	}
+	return mimeType, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"mastercom-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDocumentValidator() *DocumentValidator {
+	return NewDocumentValidator(&models.DocumentValidationConfig{Types: []models.DocumentTypeRule{
+		{MIMEType: "application/pdf", Extensions: []string{".pdf"}, MaxSize: 1024, MaxPages: 10},
+		{MIMEType: "image/tiff", Extensions: []string{".tif", ".tiff"}, MaxSize: 1024, MaxPages: 1},
+	}})
+}
+
+// singlePageTIFF returns a little endian TIFF with one image directory
+func singlePageTIFF() []byte {
+	content := []byte("II*\x00\x08\x00\x00\x00")
+	content = binary.LittleEndian.AppendUint16(content, 0)
+	return binary.LittleEndian.AppendUint32(content, 0)
+}
+
+func TestDocumentValidator_Validate(t *testing.T) {
+	validator := setupDocumentValidator()
+
+	mimeType, err := validator.Validate("scan.TIFF", singlePageTIFF())
+	require.NoError(t, err)
+	assert.Equal(t, "image/tiff", mimeType)
+
+	// JPEG is not in this allowlist
+	_, err = validator.Validate("photo.jpg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"))
+	assert.True(t, errors.Is(err, ErrDocumentRejected))
+
+	var validationErr *DocumentValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Violations, 1)
+	assert.Equal(t, models.DocumentViolationUnsupportedType, validationErr.Violations[0].Code)
+	assert.Contains(t, err.Error(), "accepted types are application/pdf, image/tiff")
+}
+
+func TestDocumentValidator_ReportsEveryViolation(t *testing.T) {
+	validator := setupDocumentValidator()
+
+	tiff := singlePageTIFF()
+	// Link the directory to a second one, past the size limit
+	binary.LittleEndian.PutUint32(tiff[10:], 1100)
+	padded := append(tiff, make([]byte, 1100-len(tiff))...)
+	padded = binary.LittleEndian.AppendUint16(padded, 0)
+	padded = binary.LittleEndian.AppendUint32(padded, 0)
+
+	_, err := validator.Validate("scan.pdf", padded)
+
+	var validationErr *DocumentValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Violations, 3)
+	assert.Equal(t, models.DocumentViolationExtensionMismatch, validationErr.Violations[0].Code)
+	assert.Equal(t, models.DocumentViolationTooLarge, validationErr.Violations[1].Code)
+	assert.Equal(t, int64(1024), validationErr.Violations[1].Limit)
+	assert.Equal(t, int64(len(padded)), validationErr.Violations[1].Actual)
+	assert.Equal(t, models.DocumentViolationTooManyPages, validationErr.Violations[2].Code)
+	assert.Equal(t, int64(2), validationErr.Violations[2].Actual)
+}
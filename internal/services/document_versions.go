@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mastercom-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrDocumentNotReplaceable is returned when replacing the content of a generated document
+	ErrDocumentNotReplaceable = errors.New("document content cannot be replaced")
+	// ErrDocumentVersionNotFound is returned for a version a document never had
+	ErrDocumentVersionNotFound = errors.New("document version not found")
+	// ErrDocumentNotDeleted is returned when restoring a document that is not deleted
+	ErrDocumentNotDeleted = errors.New("document is not deleted")
+)
+
+// ReplaceDocumentContent stores replacement as the next version of a document, keeping the
+// document's ID and case. The previous version remains retrievable by its version number.
+// The new version is quarantined for a scan and redacted like an upload. Evidence bundles
+// cannot be replaced.
+func (s *DocumentService) ReplaceDocumentContent(documentID string, replacement *models.Document) error {
+	checksum := contentSHA256(replacement.Content)
+
+	s.mutex.Lock()
+
+	current, exists := s.documents[documentID]
+	if !exists || current.DeletedAt != nil {
+		s.mutex.Unlock()
+		return ErrDocumentNotFound
+	}
+	if len(current.DerivedFrom) > 0 {
+		s.mutex.Unlock()
+		return fmt.Errorf("%w: document %s is an evidence bundle", ErrDocumentNotReplaceable, documentID)
+	}
+
+	replacement.ID = current.ID
+	replacement.CaseID = current.CaseID
+	replacement.Version = current.Version + 1
+	replacement.DerivedFrom = nil
+	replacement.Scan = nil
+	replacement.Redaction = nil
+	replacement.RedactedContent = nil
+	replacement.DeletedAt = nil
+	if replacement.Description == "" {
+		replacement.Description = current.Description
+	}
+	scan := s.quarantine(replacement)
+	s.awaitRedaction(replacement)
+
+	s.versions[documentID] = append(s.versions[documentID], current)
+	s.storeContent(replacement, checksum)
+	stored := *replacement
+	s.documents[documentID] = &stored
+	s.logger.Info("Document content replaced", logrus.Fields{
+		"documentId": documentID,
+		"caseId":     replacement.CaseID,
+		"version":    replacement.Version,
+		"uploadedBy": replacement.UploadedBy,
+	})
+	s.mutex.Unlock()
+
+	if scan {
+		s.queueScan(&stored)
+		return nil
+	}
+	s.redactDocument(&stored)
+	s.snapshotDocument(replacement, &stored)
+	return nil
+}
+
+// GetDocumentVersion returns a version of a document after verifying its content
+func (s *DocumentService) GetDocumentVersion(documentID string, version int) (*models.Document, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	versions, err := s.documentVersions(documentID)
+	if err != nil {
+		return nil, err
+	}
+	if version < 1 || version > len(versions) {
+		return nil, fmt.Errorf("%w: document %s has no version %d", ErrDocumentVersionNotFound, documentID, version)
+	}
+
+	document, err := s.openDocument(versions[version-1])
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyContent(document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// ListDocumentVersions returns a copy of every version of a document, oldest first, without
+// content when it is stored encrypted
+func (s *DocumentService) ListDocumentVersions(documentID string) ([]*models.Document, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	versions, err := s.documentVersions(documentID)
+	if err != nil {
+		return nil, err
+	}
+	copies := make([]*models.Document, len(versions))
+	for i, version := range versions {
+		versionCopy := *version
+		copies[i] = &versionCopy
+	}
+	return copies, nil
+}
+
+// RestoreDocument undoes the deletion of a document, with all its versions
+func (s *DocumentService) RestoreDocument(documentID string) (*models.Document, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	document, exists := s.documents[documentID]
+	if !exists {
+		return nil, ErrDocumentNotFound
+	}
+	if document.DeletedAt == nil {
+		return nil, ErrDocumentNotDeleted
+	}
+
+	deletedAt := *document.DeletedAt
+	document.DeletedAt = nil
+	s.logger.Info("Document restored successfully", logrus.Fields{
+		"documentId": documentID,
+		"caseId":     document.CaseID,
+		"deletedFor": time.Since(deletedAt).String(),
+	})
+	restored := *document
+	return &restored, nil
+}
+
+// documentVersions returns the versions of a document that is not deleted, oldest first.
+// The caller must hold the mutex.
+func (s *DocumentService) documentVersions(documentID string) ([]*models.Document, error) {
+	document, exists := s.documents[documentID]
+	if !exists || document.DeletedAt != nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	previous := s.versions[documentID]
+	versions := make([]*models.Document, 0, len(previous)+1)
+	versions = append(versions, previous...)
+	return append(versions, document), nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"mastercom-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentService_ReplaceDocumentContent(t *testing.T) {
+	service := setupDocumentService()
+
+	original := models.NewDocument("case-1", "scan.pdf", "application/pdf", []byte("%PDF-1.4 blurred"), "analyst-1", "Receipt")
+	require.NoError(t, service.UploadDocument(original))
+	assert.Equal(t, 1, original.Version)
+
+	replacement := models.NewDocument("", "rescan.pdf", "application/pdf", []byte("%PDF-1.4 legible"), "analyst-2", "")
+	require.NoError(t, service.ReplaceDocumentContent(original.ID, replacement))
+	assert.Equal(t, original.ID, replacement.ID)
+	assert.Equal(t, "case-1", replacement.CaseID)
+	assert.Equal(t, 2, replacement.Version)
+	assert.Equal(t, "Receipt", replacement.Description)
+
+	// The case shows the latest version
+	latest, err := service.GetDocument(original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-1.4 legible"), latest.Content)
+	documents, err := service.GetDocumentsByCaseID("case-1")
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, 2, documents[0].Version)
+
+	// The original stays retrievable
+	first, err := service.GetDocumentVersion(original.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-1.4 blurred"), first.Content)
+	assert.Equal(t, "scan.pdf", first.FileName)
+	assert.Equal(t, "analyst-1", first.UploadedBy)
+
+	versions, err := service.ListDocumentVersions(original.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, original.SHA256, versions[0].SHA256)
+	assert.Equal(t, replacement.SHA256, versions[1].SHA256)
+
+	_, err = service.GetDocumentVersion(original.ID, 3)
+	assert.ErrorIs(t, err, ErrDocumentVersionNotFound)
+	assert.ErrorIs(t, service.ReplaceDocumentContent("nonexistent-id", models.NewDocument("", "scan.pdf", "application/pdf", []byte("%PDF"), "", "")), ErrDocumentNotFound)
+}
+
+func TestDocumentService_ReplaceDocumentContent_Bundle(t *testing.T) {
+	service := setupDocumentService()
+
+	bundle := models.NewDocument("case-1", "bundle.pdf", "application/pdf", []byte("%PDF-1.4"), "test-user", "")
+	bundle.DerivedFrom = []string{"source-id"}
+	require.NoError(t, service.UploadDocument(bundle))
+
+	err := service.ReplaceDocumentContent(bundle.ID, models.NewDocument("", "bundle.pdf", "application/pdf", []byte("%PDF-1.5"), "test-user", ""))
+	assert.ErrorIs(t, err, ErrDocumentNotReplaceable)
+}
+
+func TestDocumentService_ReplacementIsScanned(t *testing.T) {
+	service, _ := setupScanningDocumentService()
+
+	original := models.NewDocument("case-1", "scan.pdf", "application/pdf", []byte("%PDF-1.4"), "test-user", "")
+	require.NoError(t, service.UploadDocument(original))
+	assert.Equal(t, 1, service.RescanQuarantined(context.Background()))
+
+	infected := models.NewDocument("", "scan.pdf", "application/pdf", []byte("%PDF-1.4 EICAR"), "test-user", "")
+	require.NoError(t, service.ReplaceDocumentContent(original.ID, infected))
+	assert.Equal(t, models.DocumentStatusQuarantined, infected.ProcessingStatus)
+	assert.Equal(t, 1, service.RescanQuarantined(context.Background()))
+	current, err := service.GetDocument(original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusRejected, current.ProcessingStatus)
+
+	// The clean version is untouched
+	first, err := service.GetDocumentVersion(original.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentStatusReceived, first.ProcessingStatus)
+	assert.Equal(t, 0, service.RescanQuarantined(context.Background()))
+}
+
+func TestDocumentService_SoftDelete(t *testing.T) {
+	service := setupDocumentService()
+
+	document := models.NewDocument("case-1", "scan.pdf", "application/pdf", []byte("%PDF-1.4"), "test-user", "")
+	require.NoError(t, service.UploadDocument(document))
+	require.NoError(t, service.ReplaceDocumentContent(document.ID, models.NewDocument("", "scan.pdf", "application/pdf", []byte("%PDF-1.5"), "test-user", "")))
+
+	require.NoError(t, service.DeleteDocument(document.ID))
+	assert.ErrorIs(t, service.DeleteDocument(document.ID), ErrDocumentNotFound)
+
+	// Hidden from reads, listings and case statuses
+	_, err := service.GetDocument(document.ID)
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	_, err = service.ListDocumentVersions(document.ID)
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	documents, err := service.GetDocumentsByCaseID("case-1")
+	require.NoError(t, err)
+	assert.Empty(t, documents)
+	assert.Equal(t, models.CaseImageStatusUnavailable, service.GetCaseFilingStatus([]string{"case-1"})[0].Status)
+
+	// Restored with every version
+	restored, err := service.RestoreDocument(document.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, 2, restored.Version)
+	versions, err := service.ListDocumentVersions(document.ID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	_, err = service.RestoreDocument(document.ID)
+	assert.ErrorIs(t, err, ErrDocumentNotDeleted)
+	_, err = service.RestoreDocument("nonexistent-id")
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+}
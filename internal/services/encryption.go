@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/envelope"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/pan"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EncryptionService encrypts sensitive case data at rest: the tagged fields of cases, the
+// content of documents and the card numbers in the vault. Each value is sealed under its own
+// data key, wrapped by the primary key-encryption key of a key ring shared by the stores.
+type EncryptionService struct {
+	keys            *envelope.KeyRing
+	caseService     *CaseService
+	documentService *DocumentService
+	vault           *pan.LocalVault
+	logger          *logger.DatadogLogger
+	// rotation allows one key rotation at a time
+	rotation sync.Mutex
+}
+
+// NewEncryptionService enables encryption on the case and document services with the
+// configured keys, and gives the case service a vault sealing card numbers under them. It is
+// called before any case or document is stored.
+func NewEncryptionService(caseService *CaseService, documentService *DocumentService, config *models.EncryptionConfig, logger *logger.DatadogLogger) (*EncryptionService, error) {
+	keys, err := envelope.NewKeyRing(config.PrimaryKeyID, config.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	vault := pan.NewLocalVault(keys)
+	caseService.UseVault(vault)
+	caseService.EnableEncryption(keys)
+	documentService.EnableEncryption(keys)
+
+	return &EncryptionService{
+		keys:            keys,
+		caseService:     caseService,
+		documentService: documentService,
+		vault:           vault,
+		logger:          logger,
+	}, nil
+}
+
+// PrimaryKeyID returns the ID of the key-encryption key that wraps new data keys
+func (s *EncryptionService) PrimaryKeyID() string {
+	return s.keys.PrimaryKeyID()
+}
+
+// RotateKeys makes the primary key of config the key that wraps data keys, and re-wraps every
+// stored data key with it. The data itself is not re-encrypted. Keys config no longer lists
+// are kept until every data key they wrapped has been re-wrapped, then dropped; after a
+// failed rotation they stay, and the rotation can be retried.
+func (s *EncryptionService) RotateKeys(config *models.EncryptionConfig) (*models.KeyRotationReport, error) {
+	s.rotation.Lock()
+	defer s.rotation.Unlock()
+
+	if err := s.keys.Add(config.PrimaryKeyID, config.Keys); err != nil {
+		return nil, err
+	}
+
+	report := &models.KeyRotationReport{PrimaryKeyID: config.PrimaryKeyID, RotatedAt: time.Now()}
+	var err error
+	if report.CaseFields, err = s.caseService.RewrapKeys(); err != nil {
+		return nil, err
+	}
+	if report.DocumentContents, err = s.documentService.RewrapKeys(); err != nil {
+		return nil, err
+	}
+	if report.CardNumbers, err = s.vault.RewrapKeys(); err != nil {
+		return nil, fmt.Errorf("re-wrapping card numbers: %w", err)
+	}
+
+	// Every data key is now wrapped by the primary key, which config holds
+	if err := s.keys.Load(config.PrimaryKeyID, config.Keys); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Encryption keys rotated", logrus.Fields{
+		"primaryKeyId":     report.PrimaryKeyID,
+		"caseFields":       report.CaseFields,
+		"documentContents": report.DocumentContents,
+		"cardNumbers":      report.CardNumbers,
+	})
+	return report, nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/docformat"
+	"mastercom-service/pkg/envelope"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEncryptionService(t *testing.T) (*EncryptionService, *CaseDocumentService, *CaseService, *DocumentService) {
+	logger := logger.NewDatadogLogger()
+	caseService := NewCaseService(logger)
+	documentService := NewDocumentService(logger)
+	encryptionService, err := NewEncryptionService(caseService, documentService, &models.EncryptionConfig{
+		PrimaryKeyID: "k1",
+		Keys:         map[string][]byte{"k1": envelope.GenerateKey()},
+	}, logger)
+	require.NoError(t, err)
+	return encryptionService, NewCaseDocumentService(caseService, documentService, logger), caseService, documentService
+}
+
+func createContactCase(t *testing.T, caseService *CaseService) *models.Case {
+	caseObj := createMockCase()
+	caseObj.QueueName = models.QueueUnworked
+	caseObj.FiledByContactName = "Jane Doe"
+	caseObj.FiledByContactPhone = "+1 555 0100"
+	caseObj.FiledByContactEmail = "jane@example.com"
+	require.NoError(t, caseService.CreateCase(caseObj))
+	return caseObj
+}
+
+func TestCaseService_EncryptsContactDetails(t *testing.T) {
+	_, _, caseService, _ := setupEncryptionService(t)
+	caseObj := createContactCase(t, caseService)
+
+	// The store holds the contact details only sealed, under the primary key
+	stored := caseService.cases[caseObj.ID]
+	assert.Empty(t, stored.FiledByContactName)
+	assert.Empty(t, stored.FiledByContactPhone)
+	assert.Empty(t, stored.FiledByContactEmail)
+	require.Len(t, caseService.sealed[caseObj.ID], 3)
+	assert.Equal(t, "k1", caseService.sealed[caseObj.ID]["FiledByContactEmail"].KeyID)
+	assert.Equal(t, "Jane Doe", caseObj.FiledByContactName, "the caller's case is left as it was")
+
+	// Callers see them decrypted
+	fetched, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", fetched.FiledByContactEmail)
+	cases, _, err := caseService.ListCases(1, 10, "", nil)
+	require.NoError(t, err)
+	require.Len(t, cases, 1)
+	assert.Equal(t, "+1 555 0100", cases[0].FiledByContactPhone)
+	transitioned, err := caseService.TransitionCase(caseObj.ID, models.QueueActionAcknowledge)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", transitioned.FiledByContactName)
+	assert.Equal(t, models.QueueWorked, caseService.cases[caseObj.ID].QueueName)
+
+	update := createMockCase()
+	update.FiledByContactEmail = "john@example.com"
+	require.NoError(t, caseService.UpdateCase(update))
+	fetched, err = caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john@example.com", fetched.FiledByContactEmail)
+	assert.Empty(t, fetched.FiledByContactName)
+	assert.Len(t, caseService.sealed[caseObj.ID], 1)
+
+	require.NoError(t, caseService.DeleteCase(caseObj.ID))
+	assert.NotContains(t, caseService.sealed, caseObj.ID)
+}
+
+func TestDocumentService_EncryptsContent(t *testing.T) {
+	_, caseDocumentService, caseService, documentService := setupEncryptionService(t)
+	caseObj := createContactCase(t, caseService)
+
+	content := []byte("%PDF-1.4 evidence")
+	document := models.NewDocument(caseObj.ID, "receipt.pdf", "application/pdf", content, "test-user", "")
+	require.NoError(t, caseDocumentService.AttachDocument(caseObj.ID, document))
+
+	// The store holds the content only sealed, under the primary key
+	stored := documentService.documents[document.ID]
+	assert.True(t, stored.ContentSealed)
+	assert.Nil(t, stored.Content)
+	sealed := documentService.contents[stored.SHA256]
+	assert.Nil(t, sealed.content)
+	require.NotNil(t, sealed.sealed)
+	assert.Equal(t, "k1", sealed.sealed.KeyID)
+	assert.NotContains(t, string(sealed.sealed.Ciphertext), "evidence")
+
+	// Reads decrypt it into a copy
+	fetched, err := documentService.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, content, fetched.Content)
+	assert.False(t, fetched.ContentSealed)
+	assert.Nil(t, stored.Content)
+	documents, err := documentService.GetDocumentsByCaseID(caseObj.ID)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, content, documents[0].Content)
+
+	require.NoError(t, documentService.ReplaceDocumentContent(document.ID, models.NewDocument("", "receipt.pdf", "application/pdf", []byte("%PDF-1.4 corrected"), "test-user", "")))
+	first, err := documentService.GetDocumentVersion(document.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, content, first.Content)
+
+	// Identical content is still stored once
+	duplicate := models.NewDocument(caseObj.ID, "copy.pdf", "application/pdf", content, "test-user", "")
+	require.NoError(t, caseDocumentService.AttachDocument(caseObj.ID, duplicate))
+	assert.Equal(t, 2, documentService.contents[stored.SHA256].refs)
+
+	// Content that cannot be decrypted fails its integrity check
+	sealed.sealed.Ciphertext[len(sealed.sealed.Ciphertext)-1] ^= 1
+	_, err = documentService.GetDocument(duplicate.ID)
+	assert.ErrorIs(t, err, ErrDocumentIntegrity)
+	assert.ErrorIs(t, err, envelope.ErrOpen)
+	_, err = documentService.GetDocumentsByCaseID(caseObj.ID)
+	assert.ErrorIs(t, err, ErrDocumentIntegrity)
+
+	// Purging frees the sealed content
+	_, err = documentService.PurgeDocument(duplicate.ID)
+	require.NoError(t, err)
+	_, err = documentService.PurgeDocument(document.ID)
+	require.NoError(t, err)
+	assert.Empty(t, documentService.contents)
+}
+
+func TestDocumentService_EncryptedScanningAndRedaction(t *testing.T) {
+	_, _, _, documentService := setupEncryptionService(t)
+	documentService.EnableScanning(&fakeScanner{}, &models.ScanConfig{Timeout: 5, RescanInterval: 60, MaxAttempts: 3})
+	documentService.EnableRedaction(&models.RedactionConfig{Enabled: true})
+
+	original := textLinePDF("Card 4111 1111 1111 1111")
+	document := models.NewDocument("case-1", "receipt.pdf", docformat.MIMETypePDF, original, "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+	documentService.RescanQuarantined(context.Background())
+	stored := documentService.documents[document.ID]
+	assert.Equal(t, models.DocumentStatusReceived, stored.ProcessingStatus)
+	require.NotNil(t, stored.Redaction)
+	assert.Equal(t, models.RedactionStatusRedacted, stored.Redaction.Status)
+	assert.Nil(t, stored.RedactedContent)
+	require.NotNil(t, documentService.contents[stored.Redaction.SHA256].sealed)
+
+	fetched, err := documentService.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, original, fetched.Content)
+	assert.Contains(t, string(fetched.Redacted().Content), "**** **** **** 1111")
+
+	// Infected content is discarded as without encryption
+	infected := models.NewDocument("case-1", "evidence.pdf", "application/pdf", []byte("%PDF-1.4 EICAR"), "test-user", "")
+	require.NoError(t, documentService.UploadDocument(infected))
+	documentService.RescanQuarantined(context.Background())
+	infected = documentService.documents[infected.ID]
+	assert.Equal(t, models.ScanStatusInfected, infected.Scan.Status)
+	assert.False(t, infected.ContentSealed)
+	assert.NotContains(t, documentService.contents, infected.SHA256)
+	_, err = documentService.PurgeDocument(infected.ID)
+	require.NoError(t, err)
+}
+
+func TestEncryptionService_RotateKeys(t *testing.T) {
+	encryptionService, caseDocumentService, caseService, documentService := setupEncryptionService(t)
+	caseObj := createContactCase(t, caseService)
+	document := models.NewDocument(caseObj.ID, "receipt.pdf", "application/pdf", []byte("%PDF-1.4 evidence"), "test-user", "")
+	require.NoError(t, caseDocumentService.AttachDocument(caseObj.ID, document))
+	k1 := encryptionService.keys
+
+	k2 := envelope.GenerateKey()
+	_, err := encryptionService.RotateKeys(&models.EncryptionConfig{PrimaryKeyID: "k2", Keys: map[string][]byte{"k1": envelope.GenerateKey(), "k2": k2}})
+	assert.ErrorIs(t, err, envelope.ErrKeyConflict)
+	assert.Equal(t, "k1", encryptionService.PrimaryKeyID())
+
+	// The old key can already be left out of the configuration
+	report, err := encryptionService.RotateKeys(&models.EncryptionConfig{PrimaryKeyID: "k2", Keys: map[string][]byte{"k2": k2}})
+	require.NoError(t, err)
+	assert.Equal(t, "k2", report.PrimaryKeyID)
+	assert.Equal(t, 3, report.CaseFields)
+	assert.Equal(t, 1, report.DocumentContents)
+	assert.Equal(t, 1, report.CardNumbers)
+	assert.Equal(t, "k2", caseService.sealed[caseObj.ID]["FiledByContactName"].KeyID)
+	assert.Same(t, k1, encryptionService.keys)
+
+	fetched, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", fetched.FiledByContactName)
+	stored, err := documentService.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-1.4 evidence"), stored.Content)
+	number, err := caseService.DetokenizePAN(context.Background(), caseObj.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "4111111111111111", number)
+
+	// Rotating again to the same key has nothing to re-wrap
+	report, err = encryptionService.RotateKeys(&models.EncryptionConfig{PrimaryKeyID: "k2", Keys: map[string][]byte{"k2": k2}})
+	require.NoError(t, err)
+	assert.Zero(t, report.CaseFields+report.DocumentContents+report.CardNumbers)
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"mastercom-service/internal/models"
@@ -12,10 +13,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// WebhookProcessor processes Ethoca webhook payloads for the webhook handlers
+type WebhookProcessor interface {
+	ProcessWebhook(ctx context.Context, webhook *models.EthocaWebhook) (*models.OutcomeAcknowledgement, error)
+	GetWebhookConfig() *models.WebhookConfig
+}
+
 // EthocaWebhookService handles processing of Ethoca webhook events
 type EthocaWebhookService struct {
-	logger *logger.DatadogLogger
-	config *models.WebhookConfig
+	logger  *logger.DatadogLogger
+	config  *models.WebhookConfig
+	refunds []models.ReconRecord
+	mutex   sync.RWMutex
 }
 
 // NewEthocaWebhookService creates a new webhook service instance
@@ -139,6 +148,10 @@ func (s *EthocaWebhookService) processOutcome(ctx context.Context, outcome *mode
 		"eventId": webhookEvent.ID,
 	})
 
+	if outcome.RefundStatus == "REFUNDED" {
+		s.recordRefund(outcome, webhookEvent)
+	}
+
 	// TODO: Store webhook event in database for audit trail
 	// s.storeWebhookEvent(ctx, webhookEvent)
 
@@ -213,6 +226,51 @@ func (s *EthocaWebhookService) processOtherOutcome(ctx context.Context, outcome
 	return nil
 }
 
+// recordRefund keeps a refunded outcome for reconciliation reporting
+func (s *EthocaWebhookService) recordRefund(outcome *models.AlertOutcome, event *models.WebhookEvent) {
+	refundedAt := event.ProcessedAt
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, outcome.Refund.Timestamp); err == nil {
+			refundedAt = t
+			break
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.refunds = append(s.refunds, models.ReconRecord{
+		ActivityType: models.ReconActivityEthocaRefund,
+		ReferenceID:  outcome.AlertID,
+		ICA:          s.config.ICA,
+		Amount:       outcome.Refund.Amount.Value,
+		Currency:     outcome.Refund.Amount.CurrencyCode,
+		Status:       outcome.Outcome,
+		ActivityDate: refundedAt,
+	})
+}
+
+// ReconActivity returns refunds made within the range for one of the ICAs. Ethoca alerts
+// carry no ICA, so refunds are reported for the configured Ethoca ICA, and only reach reports
+// without an ICA filter when none is configured.
+func (s *EthocaWebhookService) ReconActivity(start, end time.Time, icas []string) ([]models.ReconRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var records []models.ReconRecord
+	for _, refund := range s.refunds {
+		if refund.ActivityDate.Before(start) || !refund.ActivityDate.Before(end) {
+			continue
+		}
+		if len(icas) > 0 && !containsString(icas, refund.ICA) {
+			continue
+		}
+		records = append(records, refund)
+	}
+
+	return records, nil
+}
+
 // GetWebhookConfig returns the webhook configuration
 func (s *EthocaWebhookService) GetWebhookConfig() *models.WebhookConfig {
 	return s.config
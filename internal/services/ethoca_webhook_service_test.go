@@ -3,11 +3,13 @@ package services
 import (
 	"context"
 	"testing"
+	"time"
 
 	"mastercom-service/internal/models"
 	"mastercom-service/pkg/logger"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewEthocaWebhookService(t *testing.T) {
@@ -445,3 +447,41 @@ func TestGetWebhookConfig(t *testing.T) {
 	assert.Equal(t, 3, retrievedConfig.MaxRetries)
 	assert.Equal(t, 25, retrievedConfig.BatchSize)
 }
+
+func TestReconActivity_FiltersRefundsByICA(t *testing.T) {
+	logger := logger.NewDatadogLogger()
+	service := NewEthocaWebhookService(logger, &models.WebhookConfig{ICA: "123456"})
+
+	webhook := &models.EthocaWebhook{
+		Outcomes: []models.AlertOutcome{
+			{
+				AlertID:      "C6KO1M4OKAN1H4DRH1VYWKZWV",
+				Outcome:      "RESOLVED",
+				RefundStatus: "REFUNDED",
+				Refund: models.Refund{
+					Amount:    models.RefundAmount{Value: 75.00, CurrencyCode: "GBP"},
+					Timestamp: "2021-06-18T22:11:05Z",
+				},
+				AmountStopped: models.AmountStopped{Value: 75.00, CurrencyCode: "GBP"},
+			},
+		},
+	}
+	_, err := service.ProcessWebhook(context.Background(), webhook)
+	require.NoError(t, err)
+
+	start := time.Date(2021, 6, 18, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	records, err := service.ReconActivity(start, end, []string{"123456"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "123456", records[0].ICA)
+
+	records, err = service.ReconActivity(start, end, []string{"654321"})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	records, err = service.ReconActivity(start, end, nil)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/docformat"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidEvidenceBundleRequest is returned when an evidence bundle request is malformed
+	ErrInvalidEvidenceBundleRequest = errors.New("invalid evidence bundle request")
+	// ErrEvidenceBundleConversion is returned when the documents cannot be merged into the requested format
+	ErrEvidenceBundleConversion = errors.New("evidence bundle conversion failed")
+)
+
+// BuildEvidenceBundle merges a case's documents, in the requested order, into a single PDF or
+// multi-page TIFF with an optional cover sheet, and stores it as a document derived from them
+func (s *CaseDocumentService) BuildEvidenceBundle(caseID string, req *models.EvidenceBundleRequest) (*models.Document, error) {
+	caseObj, err := s.caseService.GetCase(caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	format := strings.ToUpper(req.Format)
+	if format != models.EvidenceBundleFormatPDF && format != models.EvidenceBundleFormatTIFF {
+		return nil, fmt.Errorf("%w: format must be PDF or TIFF", ErrInvalidEvidenceBundleRequest)
+	}
+
+	documents, err := s.bundleSources(caseID, req.DocumentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []docformat.Source
+	if req.CoverSheet {
+		sources = append(sources, docformat.Source{Image: docformat.RenderTextPage(coverSheetLines(caseObj, documents, time.Now()))})
+	}
+	for _, document := range documents {
+		// PDFs would need rendering to become TIFF pages, and TIFFs decoding to become PDF pages
+		if (format == models.EvidenceBundleFormatTIFF && document.FileType == docformat.MIMETypePDF) ||
+			(format == models.EvidenceBundleFormatPDF && document.FileType == docformat.MIMETypeTIFF) {
+			return nil, fmt.Errorf("%w: %s (%s) cannot be merged into a %s bundle", ErrEvidenceBundleConversion, document.FileName, document.FileType, format)
+		}
+		sources = append(sources, docformat.Source{MIMEType: document.FileType, Content: document.Content})
+	}
+
+	var content []byte
+	var fileType, extension string
+	if format == models.EvidenceBundleFormatPDF {
+		content, err = docformat.MergePDF(sources)
+		fileType, extension = docformat.MIMETypePDF, ".pdf"
+	} else {
+		content, err = docformat.MergeTIFF(sources)
+		fileType, extension = docformat.MIMETypeTIFF, ".tif"
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEvidenceBundleConversion, err)
+	}
+
+	derivedFrom := make([]string, 0, len(documents))
+	for _, document := range documents {
+		derivedFrom = append(derivedFrom, document.ID)
+	}
+
+	bundle := models.NewDocument(
+		caseID,
+		"case-"+caseID+"-evidence"+extension,
+		fileType,
+		content,
+		req.CreatedBy,
+		fmt.Sprintf("Evidence bundle of %d documents", len(documents)),
+	)
+	bundle.DerivedFrom = derivedFrom
+
+	if err := s.documentService.UploadDocument(bundle); err != nil {
+		return nil, err
+	}
+	if _, err := s.syncCaseDocuments(caseID); err != nil {
+		return nil, err
+	}
+	s.caseService.RecordCaseAudit(caseID, models.CaseAuditEvidenceBundleCreated, map[string]string{
+		"documentId": bundle.ID,
+		"format":     format,
+		"sources":    strings.Join(derivedFrom, ","),
+	})
+
+	s.logger.Info("Evidence bundle created", logrus.Fields{
+		"caseId":      caseID,
+		"documentId":  bundle.ID,
+		"format":      format,
+		"sourceCount": len(documents),
+		"coverSheet":  req.CoverSheet,
+		"bundleSize":  bundle.FileSize,
+	})
+	return bundle, nil
+}
+
+// bundleSources returns the documents to merge: those requested, in the order requested, or
+// else every uploaded document of the case that is neither quarantined nor rejected, oldest
+// first. Evidence bundles are never merged into another bundle.
+func (s *CaseDocumentService) bundleSources(caseID string, documentIDs []string) ([]*models.Document, error) {
+	if len(documentIDs) == 0 {
+		documents, err := s.documentService.GetDocumentsByCaseID(caseID)
+		if err != nil {
+			return nil, err
+		}
+
+		var sources []*models.Document
+		for _, document := range documents {
+			if len(document.DerivedFrom) == 0 && document.ProcessingStatus != models.DocumentStatusRejected &&
+				document.ProcessingStatus != models.DocumentStatusQuarantined {
+				sources = append(sources, document)
+			}
+		}
+		if len(sources) == 0 {
+			return nil, fmt.Errorf("%w: case has no documents", ErrInvalidEvidenceBundleRequest)
+		}
+		for _, document := range sources {
+			if err := s.documentService.VerifyContent(document); err != nil {
+				return nil, err
+			}
+		}
+		sort.Slice(sources, func(i, j int) bool {
+			return sources[i].UploadedAt.Before(sources[j].UploadedAt)
+		})
+		return sources, nil
+	}
+
+	seen := make(map[string]bool, len(documentIDs))
+	sources := make([]*models.Document, 0, len(documentIDs))
+	for _, documentID := range documentIDs {
+		if seen[documentID] {
+			return nil, fmt.Errorf("%w: document %s is listed twice", ErrInvalidEvidenceBundleRequest, documentID)
+		}
+		seen[documentID] = true
+
+		document, err := s.documentService.GetDocument(documentID)
+		if errors.Is(err, ErrDocumentIntegrity) {
+			return nil, err
+		}
+		if err != nil || document.CaseID != caseID {
+			return nil, fmt.Errorf("%w: document %s is not attached to the case", ErrInvalidEvidenceBundleRequest, documentID)
+		}
+		if len(document.DerivedFrom) > 0 {
+			return nil, fmt.Errorf("%w: document %s is an evidence bundle", ErrInvalidEvidenceBundleRequest, documentID)
+		}
+		if document.ProcessingStatus == models.DocumentStatusQuarantined || document.ProcessingStatus == models.DocumentStatusRejected {
+			return nil, fmt.Errorf("%w: document %s is %s", ErrInvalidEvidenceBundleRequest, documentID, strings.ToLower(document.ProcessingStatus))
+		}
+		sources = append(sources, document)
+	}
+	return sources, nil
+}
+
+// coverSheetLines summarises a case and the documents bundled for it. The card number is masked.
+func coverSheetLines(caseObj *models.Case, documents []*models.Document, generatedAt time.Time) []string {
+	field := func(label, value string) string {
+		return fmt.Sprintf("%-20s %s", label+":", value)
+	}
+
+	caseType := caseObj.CaseType
+	if caseObj.CaseTypeDescription != "" {
+		caseType += " - " + caseObj.CaseTypeDescription
+	}
+	reason := caseObj.ReasonCode
+	if caseObj.ReasonDescription != "" {
+		reason += " - " + caseObj.ReasonDescription
+	}
+
+	lines := []string{
+		"MASTERCOM CASE EVIDENCE",
+		"",
+		field("Case ID", caseObj.ID),
+	}
+	if caseObj.MastercomCaseID != "" {
+		lines = append(lines, field("Mastercom case ID", caseObj.MastercomCaseID))
+	}
+	lines = append(lines,
+		field("Case type", caseType),
+		field("Filing as", caseObj.FilingAs),
+		field("Filing ICA", caseObj.FilingIca),
+		field("Filed against ICA", caseObj.FiledAgainstIca),
+		field("Reason code", reason),
+		"",
+		field("Card number", maskAccountNumber(caseObj.PrimaryAccountNumber)),
+		field("Transaction ID", caseObj.TransactionID),
+		field("Transaction date", caseObj.TransactionDate.Format("2006-01-02")),
+		field("Transaction amount", fmt.Sprintf("%.2f %s", caseObj.TransactionAmount, caseObj.TransactionCurrency)),
+	)
+	if caseObj.DisputeAmount > 0 {
+		lines = append(lines, field("Dispute amount", fmt.Sprintf("%.2f %s", caseObj.DisputeAmount, caseObj.DisputeCurrency)))
+	}
+	if caseObj.MerchantName != "" {
+		lines = append(lines, field("Merchant", strings.TrimSpace(caseObj.MerchantName+" "+caseObj.MerchantCategoryCode)))
+	}
+	if caseObj.FiledByContactName != "" {
+		lines = append(lines, field("Contact", strings.Join(nonEmpty(caseObj.FiledByContactName, caseObj.FiledByContactPhone, caseObj.FiledByContactEmail), ", ")))
+	}
+
+	lines = append(lines, "", fmt.Sprintf("Documents (%d):", len(documents)))
+	for i, document := range documents {
+		lines = append(lines, fmt.Sprintf("%3d. %s", i+1, document.FileName))
+	}
+	lines = append(lines, "", field("Generated", generatedAt.UTC().Format(time.RFC3339)))
+	return lines
+}
+
+func nonEmpty(values ...string) []string {
+	var kept []string
+	for _, value := range values {
+		if value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/docformat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bundlePDF returns a PDF with the given number of letter-size pages
+func bundlePDF(pages int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	kids := ""
+	for i := 0; i < pages; i++ {
+		kids += fmt.Sprintf("%d 0 R ", i+3)
+	}
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", kids, pages)
+	for i := 0; i < pages; i++ {
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>\nendobj\n", i+3)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func bundleJPEG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 40, 30)), nil))
+	return buf.Bytes()
+}
+
+// attachBundleSource attaches a document uploaded the given number of seconds after the first
+func attachBundleSource(t *testing.T, service *CaseDocumentService, caseID, fileName, fileType string, content []byte, offset int) *models.Document {
+	document := models.NewDocument(caseID, fileName, fileType, content, "test-user", "")
+	document.UploadedAt = time.Date(2024, 1, 15, 10, 0, offset, 0, time.UTC)
+	require.NoError(t, service.AttachDocument(caseID, document))
+	return document
+}
+
+func TestCaseDocumentService_BuildEvidenceBundle(t *testing.T) {
+	service, caseService, documentService := setupCaseDocumentService()
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	receipt := attachBundleSource(t, service, caseObj.ID, "receipt.jpg", docformat.MIMETypeJPEG, bundleJPEG(t), 0)
+	letter := attachBundleSource(t, service, caseObj.ID, "letter.pdf", docformat.MIMETypePDF, bundlePDF(2), 1)
+
+	// Requested order, with a cover sheet
+	bundle, err := service.BuildEvidenceBundle(caseObj.ID, &models.EvidenceBundleRequest{
+		Format:      "pdf",
+		DocumentIDs: []string{letter.ID, receipt.ID},
+		CoverSheet:  true,
+		CreatedBy:   "test-user",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "case-"+caseObj.ID+"-evidence.pdf", bundle.FileName)
+	assert.Equal(t, docformat.MIMETypePDF, bundle.FileType)
+	assert.Equal(t, []string{letter.ID, receipt.ID}, bundle.DerivedFrom)
+	assert.Equal(t, docformat.MIMETypePDF, docformat.Detect(bundle.Content))
+	pages, err := docformat.PageCount(docformat.MIMETypePDF, bundle.Content)
+	require.NoError(t, err)
+	assert.Equal(t, 4, pages)
+
+	// The bundle is listed on the case like any other document
+	retrieved, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Len(t, retrieved.Documents, 3)
+
+	// Every unrejected document, oldest first, leaving out earlier bundles
+	rejected := attachBundleSource(t, service, caseObj.ID, "bad.jpg", docformat.MIMETypeJPEG, bundleJPEG(t), 2)
+	_, err = documentService.UpdateDocumentStatus(rejected.ID, models.DocumentStatusRejected, "illegible")
+	require.NoError(t, err)
+
+	bundle, err = service.BuildEvidenceBundle(caseObj.ID, &models.EvidenceBundleRequest{Format: models.EvidenceBundleFormatPDF})
+	require.NoError(t, err)
+	assert.Equal(t, []string{receipt.ID, letter.ID}, bundle.DerivedFrom)
+
+	// Images only can be bundled as a TIFF
+	bundle, err = service.BuildEvidenceBundle(caseObj.ID, &models.EvidenceBundleRequest{
+		Format:      models.EvidenceBundleFormatTIFF,
+		DocumentIDs: []string{receipt.ID},
+		CoverSheet:  true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "case-"+caseObj.ID+"-evidence.tif", bundle.FileName)
+	pages, err = docformat.PageCount(docformat.MIMETypeTIFF, bundle.Content)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pages)
+}
+
+func TestCaseDocumentService_BuildEvidenceBundle_Errors(t *testing.T) {
+	service, caseService, _ := setupCaseDocumentService()
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	_, err := service.BuildEvidenceBundle("nonexistent-id", &models.EvidenceBundleRequest{Format: models.EvidenceBundleFormatPDF})
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+
+	// A case without documents
+	_, err = service.BuildEvidenceBundle(caseObj.ID, &models.EvidenceBundleRequest{Format: models.EvidenceBundleFormatPDF})
+	assert.ErrorIs(t, err, ErrInvalidEvidenceBundleRequest)
+
+	letter := attachBundleSource(t, service, caseObj.ID, "letter.pdf", docformat.MIMETypePDF, bundlePDF(1), 0)
+
+	other := createMockCase()
+	other.ID = "other-case-id"
+	require.NoError(t, caseService.CreateCase(other))
+	foreign := attachBundleSource(t, service, other.ID, "other.pdf", docformat.MIMETypePDF, bundlePDF(1), 0)
+
+	bundle, err := service.BuildEvidenceBundle(caseObj.ID, &models.EvidenceBundleRequest{Format: models.EvidenceBundleFormatPDF})
+	require.NoError(t, err)
+
+	for name, req := range map[string]*models.EvidenceBundleRequest{
+		"unknown format":  {Format: "DOCX"},
+		"listed twice":    {Format: models.EvidenceBundleFormatPDF, DocumentIDs: []string{letter.ID, letter.ID}},
+		"other case":      {Format: models.EvidenceBundleFormatPDF, DocumentIDs: []string{foreign.ID}},
+		"unknown":         {Format: models.EvidenceBundleFormatPDF, DocumentIDs: []string{"nonexistent-id"}},
+		"existing bundle": {Format: models.EvidenceBundleFormatPDF, DocumentIDs: []string{bundle.ID}},
+	} {
+		_, err := service.BuildEvidenceBundle(caseObj.ID, req)
+		assert.ErrorIs(t, err, ErrInvalidEvidenceBundleRequest, name)
+	}
+
+	// PDFs cannot be rasterised into TIFF pages
+	_, err = service.BuildEvidenceBundle(caseObj.ID, &models.EvidenceBundleRequest{Format: models.EvidenceBundleFormatTIFF, DocumentIDs: []string{letter.ID}})
+	assert.ErrorIs(t, err, ErrEvidenceBundleConversion)
+	assert.Contains(t, err.Error(), "letter.pdf")
+
+	// Content that does not parse
+	broken := attachBundleSource(t, service, caseObj.ID, "broken.pdf", docformat.MIMETypePDF, []byte("%PDF-1.4\ngarbage"), 1)
+	_, err = service.BuildEvidenceBundle(caseObj.ID, &models.EvidenceBundleRequest{Format: models.EvidenceBundleFormatPDF, DocumentIDs: []string{broken.ID}})
+	assert.ErrorIs(t, err, ErrEvidenceBundleConversion)
+}
+
+func TestCoverSheetLines(t *testing.T) {
+	caseObj := createMockCase()
+	documents := []*models.Document{{FileName: "letter.pdf"}, {FileName: "receipt.jpg"}}
+
+	lines := coverSheetLines(caseObj, documents, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	text := fmt.Sprint(lines)
+	assert.Contains(t, text, caseObj.ID)
+	assert.Contains(t, text, "  1. letter.pdf")
+	assert.Contains(t, text, "  2. receipt.jpg")
+	assert.Contains(t, text, "2024-01-15T10:00:00Z")
+	assert.NotContains(t, text, caseObj.PrimaryAccountNumber)
+	assert.Contains(t, text, maskAccountNumber(caseObj.PrimaryAccountNumber))
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/mastercom"
+	"mastercom-service/pkg/oauth1"
+)
+
+// ErrMastercomNotConfigured is returned when Mastercom credentials are missing
+var ErrMastercomNotConfigured = errors.New("mastercom credentials not configured")
+
+// NewSignedHTTPClient creates an HTTP client that signs every request with the configured
+// consumer key and keystore and applies the shared Mastercom resilience policy
+func NewSignedHTTPClient(config *models.MastercomConfig) (*http.Client, error) {
+	return NewPartnerHTTPClient(PartnerMastercom, config)
+}
+
+// NewPartnerHTTPClient creates a signed HTTP client for a Mastercard API such as Mastercom or
+// Ethoca. Clients for the same partner share its rate limits, concurrency cap, circuit
+// breaker and retry budget.
+func NewPartnerHTTPClient(partner string, config *models.MastercomConfig) (*http.Client, error) {
+	if config.ConsumerKey == "" || config.KeystorePath == "" {
+		return nil, ErrMastercomNotConfigured
+	}
+
+	key, err := oauth1.LoadSigningKey(config.KeystorePath, config.KeystorePassword)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := oauth1.NewSigner(config.ConsumerKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: partnerPolicy(partner, config).Wrap(oauth1.NewTransport(signer, http.DefaultTransport)),
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+	}, nil
+}
+
+// NewMastercomClient creates a Mastercom API client that signs its requests
+func NewMastercomClient(config *models.MastercomConfig) (*mastercom.Client, error) {
+	httpClient, err := NewSignedHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return mastercom.NewClient(config.BaseURL, mastercom.WithHTTPClient(httpClient))
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMastercomClient_SignsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(authorization, "OAuth "))
+		assert.Contains(t, authorization, `oauth_consumer_key="test-consumer-key"`)
+		assert.Contains(t, authorization, `oauth_signature_method="RSA-SHA256"`)
+		json.NewEncoder(w).Encode([]mastercom.Queue{{QueueName: "Unworked"}})
+	}))
+	defer server.Close()
+
+	client, err := NewMastercomClient(&models.MastercomConfig{
+		BaseURL:          server.URL,
+		ConsumerKey:      "test-consumer-key",
+		KeystorePath:     "../../pkg/oauth1/testdata/test_keystore.p12",
+		KeystorePassword: "keystorepassword",
+		Timeout:          5,
+	})
+	require.NoError(t, err)
+
+	queues, err := client.GetQueues(context.Background())
+	require.NoError(t, err)
+	require.Len(t, queues, 1)
+	assert.Equal(t, "Unworked", queues[0].QueueName)
+}
+
+func TestNewMastercomClient_NotConfigured(t *testing.T) {
+	_, err := NewMastercomClient(&models.MastercomConfig{BaseURL: mastercom.SandboxBaseURL})
+	assert.ErrorIs(t, err, ErrMastercomNotConfigured)
+
+	_, err = NewMastercomClient(&models.MastercomConfig{
+		BaseURL:      mastercom.SandboxBaseURL,
+		ConsumerKey:  "test-consumer-key",
+		KeystorePath: "../../pkg/oauth1/testdata/test_keystore.p12",
+	})
+	assert.Error(t, err)
+}
+
+func TestNewPartnerHTTPClient_RetriesAreResigned(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if len(authorizations) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := &models.MastercomConfig{
+		ConsumerKey:      "test-consumer-key",
+		KeystorePath:     "../../pkg/oauth1/testdata/test_keystore.p12",
+		KeystorePassword: "keystorepassword",
+		Timeout:          5,
+		BreakerFailures:  5,
+		MaxRetries:       1,
+		MaxRetryAfter:    1,
+		RetryBudgetBurst: 1,
+	}
+	client, err := NewPartnerHTTPClient("retry-test", config)
+	require.NoError(t, err)
+
+	resp, err := client.Post(server.URL+"/v6/cases", "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// Each attempt carries its own nonce and signature
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, authorizations, 2)
+	assert.NotEqual(t, authorizations[0], authorizations[1])
+
+	// Clients of the same partner share one policy
+	_, err = NewPartnerHTTPClient("retry-test", config)
+	require.NoError(t, err)
+	var found bool
+	for _, stats := range PartnerStatus() {
+		if stats.Name == "retry-test" {
+			found = true
+			assert.Equal(t, int64(2), stats.Requests)
+			assert.Equal(t, int64(1), stats.Retries)
+			assert.Equal(t, "closed", string(stats.State))
+		}
+	}
+	assert.True(t, found)
+}
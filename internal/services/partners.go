@@ -0,0 +1,78 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/resilience"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PartnerMastercom names the resilience policy shared by every Mastercom client
+const PartnerMastercom = "mastercom"
+
+// partnerPolicies holds one policy per partner, so that every client of a partner shares its
+// rate limits, concurrency cap, circuit breaker and retry budget
+var partnerPolicies = resilience.NewRegistry()
+
+var (
+	partnerMutex   sync.RWMutex
+	partnerMetrics resilience.Metrics
+	partnerLogger  *logger.DatadogLogger
+)
+
+// ConfigurePartnerPolicies sets where partner policies report metrics and circuit state
+// changes. It must be called before the first partner client is created.
+func ConfigurePartnerPolicies(metrics resilience.Metrics, logger *logger.DatadogLogger) {
+	partnerMutex.Lock()
+	defer partnerMutex.Unlock()
+	partnerMetrics = metrics
+	partnerLogger = logger
+}
+
+// PartnerStatus returns the resilience state of every partner with a client
+func PartnerStatus() []resilience.Stats {
+	return partnerPolicies.Stats()
+}
+
+// partnerPolicy returns the shared policy for a partner, creating it from config on first use
+func partnerPolicy(partner string, config *models.MastercomConfig) *resilience.Policy {
+	partnerMutex.RLock()
+	metrics, log := partnerMetrics, partnerLogger
+	partnerMutex.RUnlock()
+
+	limits := make(map[string]resilience.Limit, len(config.EndpointRateLimits))
+	for endpoint, rate := range config.EndpointRateLimits {
+		limits[endpoint] = resilience.Limit{Rate: rate, Burst: config.RateBurst}
+	}
+
+	return partnerPolicies.Policy(partner, resilience.Config{
+		DefaultLimit:     resilience.Limit{Rate: config.RateLimit, Burst: config.RateBurst},
+		EndpointLimits:   limits,
+		MaxConcurrent:    config.MaxConcurrent,
+		QueueTimeout:     time.Duration(config.QueueTimeout) * time.Second,
+		FailureThreshold: config.BreakerFailures,
+		OpenTimeout:      time.Duration(config.BreakerOpenTimeout) * time.Second,
+		HalfOpenProbes:   config.BreakerHalfOpenProbes,
+		MaxRetries:       config.MaxRetries,
+		RetryBackoff:     time.Duration(config.RetryBackoff) * time.Second,
+		MaxRetryAfter:    time.Duration(config.MaxRetryAfter) * time.Second,
+		RetryBudget:      config.RetryBudget,
+		RetryBudgetBurst: config.RetryBudgetBurst,
+		Metrics:          metrics,
+		OnStateChange: func(name string, from, to resilience.State) {
+			if log == nil {
+				return
+			}
+			fields := logrus.Fields{"partner": name, "from": string(from), "to": string(to)}
+			if to == resilience.StateOpen {
+				log.Error("Partner circuit opened", fields)
+				return
+			}
+			log.Info("Partner circuit state changed", fields)
+		},
+	})
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+)
+
+// QueuePageSize is the maximum number of items returned per page by GetQueueContent
+const QueuePageSize = 2000
+
+const queueDateLayout = "2006-01-02T15:04"
+
+var (
+	// ErrQueueNotFound is returned when a queue name is not known
+	ErrQueueNotFound = errors.New("queue not found")
+	// ErrInvalidQueueRequest is returned when a queue content request is malformed
+	ErrInvalidQueueRequest = errors.New("invalid queue request")
+)
+
+// QueueService exposes cases through their work queues
+type QueueService struct {
+	caseService *CaseService
+	logger      *logger.DatadogLogger
+}
+
+// NewQueueService creates a new queue service instance
+func NewQueueService(caseService *CaseService, logger *logger.DatadogLogger) *QueueService {
+	return &QueueService{
+		caseService: caseService,
+		logger:      logger,
+	}
+}
+
+// GetQueueNames returns the available queues
+func (s *QueueService) GetQueueNames() []models.Queue {
+	queues := make([]models.Queue, len(models.Queues))
+	copy(queues, models.Queues)
+	return queues
+}
+
+// GetQueue returns every item within scope in a queue, most recently modified first
+func (s *QueueService) GetQueue(queueName string, scope *models.ICAScope) ([]models.ClaimSummary, error) {
+	if !models.IsQueue(queueName) {
+		return nil, ErrQueueNotFound
+	}
+
+	cases := s.caseService.ListCasesByQueue(queueName, scope)
+	summaries := make([]models.ClaimSummary, 0, len(cases))
+	for _, caseObj := range cases {
+		summaries = append(summaries, models.NewClaimSummary(caseObj))
+	}
+
+	return summaries, nil
+}
+
+// GetQueueContent returns a page of items within scope in a queue, optionally limited to a
+// last modified date interval
+func (s *QueueService) GetQueueContent(req *models.QueueContentRequest, scope *models.ICAScope) (*models.QueueContentSummary, error) {
+	if !models.IsQueue(req.QueueName) {
+		return nil, ErrQueueNotFound
+	}
+
+	if (req.LastModifiedDateFrom == "") != (req.LastModifiedDateTo == "") {
+		return nil, fmt.Errorf("%w: lastModifiedDateFrom and lastModifiedDateTo must be provided together", ErrInvalidQueueRequest)
+	}
+
+	var from, to time.Time
+	if req.LastModifiedDateFrom != "" {
+		var err error
+		if from, err = time.Parse(queueDateLayout, req.LastModifiedDateFrom); err != nil {
+			return nil, fmt.Errorf("%w: lastModifiedDateFrom must be yyyy-MM-ddTHH:mm", ErrInvalidQueueRequest)
+		}
+		if to, err = time.Parse(queueDateLayout, req.LastModifiedDateTo); err != nil {
+			return nil, fmt.Errorf("%w: lastModifiedDateTo must be yyyy-MM-ddTHH:mm", ErrInvalidQueueRequest)
+		}
+		if to.Before(from) {
+			return nil, fmt.Errorf("%w: lastModifiedDateTo is before lastModifiedDateFrom", ErrInvalidQueueRequest)
+		}
+	}
+
+	page := 1
+	if req.PageNb != "" {
+		var err error
+		if page, err = strconv.Atoi(req.PageNb); err != nil || page < 1 {
+			return nil, fmt.Errorf("%w: pageNb must be a positive number", ErrInvalidQueueRequest)
+		}
+	}
+
+	var summaries []models.ClaimSummary
+	for _, caseObj := range s.caseService.ListCasesByQueue(req.QueueName, scope) {
+		// Dates carry minute precision, so compare against the case's modification minute
+		modified := caseObj.UpdatedAt.UTC().Truncate(time.Minute)
+		if !from.IsZero() && (modified.Before(from) || modified.After(to)) {
+			continue
+		}
+		summaries = append(summaries, models.NewClaimSummary(caseObj))
+	}
+
+	pageCount := (len(summaries) + QueuePageSize - 1) / QueuePageSize
+	if pageCount == 0 {
+		pageCount = 1
+	}
+
+	start := (page - 1) * QueuePageSize
+	end := start + QueuePageSize
+	if start > len(summaries) {
+		start = len(summaries)
+	}
+	if end > len(summaries) {
+		end = len(summaries)
+	}
+
+	return &models.QueueContentSummary{
+		PageCount: strconv.Itoa(pageCount),
+		ClaimList: append([]models.ClaimSummary{}, summaries[start:end]...),
+	}, nil
+}
+
+// GetQueueCounts returns the number of items within scope in every queue
+func (s *QueueService) GetQueueCounts(scope *models.ICAScope) []models.QueueCount {
+	counts := s.caseService.CountCasesByQueue(scope)
+
+	queueCounts := make([]models.QueueCount, 0, len(models.Queues))
+	for _, queue := range models.Queues {
+		queueCounts = append(queueCounts, models.QueueCount{
+			QueueName: queue.QueueName,
+			Count:     counts[queue.QueueName],
+		})
+	}
+
+	return queueCounts
+}
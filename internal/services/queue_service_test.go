@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupQueueService() (*QueueService, *CaseService) {
+	logger := logger.NewDatadogLogger()
+	caseService := NewCaseService(logger)
+	return NewQueueService(caseService, logger), caseService
+}
+
+func createQueuedCase(t *testing.T, caseService *CaseService, id string) *models.Case {
+	caseObj := createMockCase()
+	caseObj.ID = id
+	caseObj.QueueName = models.QueueUnworked
+	require.NoError(t, caseService.CreateCase(caseObj))
+	return caseObj
+}
+
+func TestCaseService_TransitionCase(t *testing.T) {
+	_, caseService := setupQueueService()
+	createQueuedCase(t, caseService, "case-1")
+
+	caseObj, err := caseService.TransitionCase("case-1", models.QueueActionAcknowledge)
+	require.NoError(t, err)
+	assert.Equal(t, models.QueueWorked, caseObj.QueueName)
+
+	caseObj, err = caseService.TransitionCase("case-1", models.QueueActionSubmit)
+	require.NoError(t, err)
+	assert.Equal(t, models.QueueSubmitted, caseObj.QueueName)
+
+	caseObj, err = caseService.TransitionCase("case-1", models.QueueActionReject)
+	require.NoError(t, err)
+	assert.Equal(t, models.QueueRejects, caseObj.QueueName)
+
+	// Acknowledging a submitted case is not allowed
+	_, err = caseService.TransitionCase("case-1", models.QueueActionSubmit)
+	require.NoError(t, err)
+	_, err = caseService.TransitionCase("case-1", models.QueueActionAcknowledge)
+	assert.ErrorIs(t, err, models.ErrInvalidQueueTransition)
+
+	_, err = caseService.TransitionCase("nonexistent-id", models.QueueActionAcknowledge)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestCaseService_UpdateCase_PreservesQueue(t *testing.T) {
+	_, caseService := setupQueueService()
+	createQueuedCase(t, caseService, "case-1")
+
+	_, err := caseService.TransitionCase("case-1", models.QueueActionAcknowledge)
+	require.NoError(t, err)
+
+	updated := createMockCase()
+	updated.ID = "case-1"
+	updated.QueueName = models.QueueUnworked
+	require.NoError(t, caseService.UpdateCase(updated))
+
+	retrieved, err := caseService.GetCase("case-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.QueueWorked, retrieved.QueueName)
+}
+
+func TestQueueService_GetQueue(t *testing.T) {
+	queueService, caseService := setupQueueService()
+	older := createQueuedCase(t, caseService, "case-1")
+	older.UpdatedAt = time.Now().Add(-time.Hour)
+	createQueuedCase(t, caseService, "case-2")
+	createQueuedCase(t, caseService, "case-3")
+
+	_, err := caseService.TransitionCase("case-3", models.QueueActionAcknowledge)
+	require.NoError(t, err)
+
+	summaries, err := queueService.GetQueue(models.QueueUnworked, nil)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "case-2", summaries[0].ClaimID)
+	assert.Equal(t, "case-1", summaries[1].ClaimID)
+	assert.True(t, summaries[0].IsIssuer)
+	assert.Equal(t, "123456", summaries[0].IssuerID)
+	assert.Equal(t, "100.00 USD", summaries[0].ClaimValue)
+
+	_, err = queueService.GetQueue("Unknown", nil)
+	assert.ErrorIs(t, err, ErrQueueNotFound)
+}
+
+func TestQueueService_GetQueueContent(t *testing.T) {
+	queueService, caseService := setupQueueService()
+	for i := 0; i < QueuePageSize+5; i++ {
+		createQueuedCase(t, caseService, fmt.Sprintf("case-%d", i))
+	}
+
+	content, err := queueService.GetQueueContent(&models.QueueContentRequest{QueueName: models.QueueUnworked}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2", content.PageCount)
+	assert.Len(t, content.ClaimList, QueuePageSize)
+
+	content, err = queueService.GetQueueContent(&models.QueueContentRequest{QueueName: models.QueueUnworked, PageNb: "2"}, nil)
+	require.NoError(t, err)
+	assert.Len(t, content.ClaimList, 5)
+
+	// A date interval that excludes every case
+	content, err = queueService.GetQueueContent(&models.QueueContentRequest{
+		QueueName:            models.QueueUnworked,
+		LastModifiedDateFrom: "2017-11-08T12:01",
+		LastModifiedDateTo:   "2017-11-09T12:01",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "1", content.PageCount)
+	assert.Empty(t, content.ClaimList)
+
+	_, err = queueService.GetQueueContent(&models.QueueContentRequest{QueueName: models.QueueUnworked, LastModifiedDateFrom: "2017-11-08T12:01"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidQueueRequest)
+
+	_, err = queueService.GetQueueContent(&models.QueueContentRequest{QueueName: "Unknown"}, nil)
+	assert.ErrorIs(t, err, ErrQueueNotFound)
+}
+
+func TestQueueService_GetQueueCounts(t *testing.T) {
+	queueService, caseService := setupQueueService()
+	createQueuedCase(t, caseService, "case-1")
+	createQueuedCase(t, caseService, "case-2")
+
+	_, err := caseService.TransitionCase("case-2", models.QueueActionReject)
+	require.NoError(t, err)
+
+	counts := queueService.GetQueueCounts(nil)
+	require.Len(t, counts, len(models.Queues))
+
+	byName := make(map[string]int)
+	for _, count := range counts {
+		byName[count.QueueName] = count.Count
+	}
+	assert.Equal(t, 1, byName[models.QueueUnworked])
+	assert.Equal(t, 1, byName[models.QueueRejects])
+	assert.Equal(t, 0, byName[models.QueueClosed])
+}
@@ -0,0 +1,496 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+const (
+	// queueSyncWindow is the widest last-modified range Mastercom returns exact page counts for
+	queueSyncWindow = 5 * 24 * time.Hour
+	// queueSyncOverlap re-reads the minute before the high-water mark, since Mastercom date
+	// ranges only have minute precision
+	queueSyncOverlap = time.Minute
+	// queueSyncDateLayout is the yyyy-MM-ddTHH:mm format of queue content date ranges
+	queueSyncDateLayout = "2006-01-02T15:04"
+	// maxChargebackAcknowledgements is the most chargebacks Mastercom acknowledges per request
+	maxChargebackAcknowledgements = 500
+)
+
+// QueueSyncClient reads Mastercom queues, claims and statuses; *mastercom.Client implements it
+type QueueSyncClient interface {
+	GetQueues(ctx context.Context) ([]mastercom.Queue, error)
+	GetQueueSummaryPost(ctx context.Context, body *mastercom.GetQueueContentRequest) (*mastercom.QueueContentSummary, error)
+	GetClaimDetail(ctx context.Context, claimID string) (*mastercom.ClaimDetail, error)
+	AcknowledgeChargebacks(ctx context.Context, body *mastercom.ChargebackMarkProcessedRequest) (*mastercom.ChargebackMarkProcessedResponse, error)
+	RetrieveCaseFilingStatus(ctx context.Context, body *mastercom.CaseFilingStatusRequest) (*mastercom.CaseFilingStatusResponse, error)
+}
+
+// SyncStateStore persists queue sync progress so restarts resume from the high-water mark
+type SyncStateStore interface {
+	Load() (*models.QueueSyncState, error)
+	Save(state *models.QueueSyncState) error
+}
+
+// FileSyncStateStore keeps queue sync state in a JSON file
+type FileSyncStateStore struct {
+	path string
+}
+
+// NewFileSyncStateStore creates a state store backed by the file at path
+func NewFileSyncStateStore(path string) *FileSyncStateStore {
+	return &FileSyncStateStore{path: path}
+}
+
+// Load reads the saved state, returning an empty state when nothing has been saved yet
+func (s *FileSyncStateStore) Load() (*models.QueueSyncState, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &models.QueueSyncState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read queue sync state: %w", err)
+	}
+
+	var state models.QueueSyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode queue sync state: %w", err)
+	}
+	return &state, nil
+}
+
+// Save writes the state atomically, so a crash never leaves a partial file behind
+func (s *FileSyncStateStore) Save(state *models.QueueSyncState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode queue sync state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("write queue sync state: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write queue sync state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write queue sync state: %w", err)
+	}
+	return nil
+}
+
+// QueueSyncWorker periodically copies claims, chargebacks and case statuses from the
+// Mastercom queues into local storage and acknowledges newly stored chargebacks
+type QueueSyncWorker struct {
+	claimService *ClaimService
+	caseService  *CaseService
+	client       QueueSyncClient
+	store        SyncStateStore
+	config       *models.QueueSyncConfig
+	logger       *logger.DatadogLogger
+	now          func() time.Time
+
+	mutex  sync.RWMutex
+	state  models.QueueSyncState
+	status models.QueueSyncStatus
+}
+
+// NewQueueSyncWorker creates a new queue sync worker, resuming from the stored high-water mark
+func NewQueueSyncWorker(claimService *ClaimService, caseService *CaseService, client QueueSyncClient, store SyncStateStore, config *models.QueueSyncConfig, logger *logger.DatadogLogger) *QueueSyncWorker {
+	w := &QueueSyncWorker{
+		claimService: claimService,
+		caseService:  caseService,
+		client:       client,
+		store:        store,
+		config:       config,
+		logger:       logger,
+		now:          time.Now,
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		logger.Error("Failed to load queue sync state, starting from the initial lookback", logrus.Fields{"error": err.Error()})
+	} else {
+		w.state = *state
+	}
+
+	return w
+}
+
+// Run syncs on every interval until ctx is cancelled
+func (w *QueueSyncWorker) Run(ctx context.Context) {
+	interval := time.Duration(w.config.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	w.logger.Info("Queue sync worker started", logrus.Fields{"interval": interval.String()})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Failures are recorded in the sync status and retried on the next tick
+		_ = w.SyncOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Queue sync worker stopped", nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status returns the outcome of the most recent syncs and the current lag
+func (w *QueueSyncWorker) Status() models.QueueSyncStatus {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	status := w.status
+	if !w.state.HighWaterMark.IsZero() {
+		highWaterMark := w.state.HighWaterMark
+		status.HighWaterMark = &highWaterMark
+		status.LagSeconds = int64(w.now().Sub(highWaterMark).Seconds())
+	}
+	status.Stale = w.config.MaxLag > 0 && (status.HighWaterMark == nil || status.LagSeconds > int64(w.config.MaxLag))
+	return status
+}
+
+// SyncOnce synchronises every window between the high-water mark and now, then acknowledges
+// new chargebacks and refreshes filed case statuses. The high-water mark only advances past
+// windows that were fully synchronised.
+func (w *QueueSyncWorker) SyncOnce(ctx context.Context) error {
+	span := tracer.StartSpan("queue.sync", tracer.ResourceName("SyncQueues"))
+	defer span.Finish()
+
+	startedAt := w.now()
+	w.mutex.Lock()
+	w.status.Running = true
+	w.status.LastSyncStartedAt = &startedAt
+	w.status.ClaimsCreated = 0
+	w.status.ClaimsUpdated = 0
+	w.status.ChargebacksAcknowledged = 0
+	w.status.CasesUpdated = 0
+	from := w.state.HighWaterMark
+	w.mutex.Unlock()
+
+	err := w.sync(ctx, from, startedAt)
+
+	completedAt := w.now()
+	w.mutex.Lock()
+	w.status.Running = false
+	w.status.LastSyncCompletedAt = &completedAt
+	if err != nil {
+		w.status.LastError = err.Error()
+	} else {
+		w.status.LastError = ""
+		w.status.LastSuccessAt = &completedAt
+	}
+	status := w.status
+	w.mutex.Unlock()
+
+	if err != nil {
+		w.logger.ErrorWithSpan(span, "Queue sync failed", logrus.Fields{"error": err.Error()})
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return err
+	}
+
+	w.logger.InfoWithSpan(span, "Queue sync completed", logrus.Fields{
+		"claimsCreated":           status.ClaimsCreated,
+		"claimsUpdated":           status.ClaimsUpdated,
+		"chargebacksAcknowledged": status.ChargebacksAcknowledged,
+		"casesUpdated":            status.CasesUpdated,
+	})
+	return nil
+}
+
+func (w *QueueSyncWorker) sync(ctx context.Context, from, to time.Time) error {
+	queues, err := w.queueNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	if from.IsZero() {
+		from = to.Add(-time.Duration(w.config.InitialLookback) * time.Hour)
+	}
+
+	for windowStart := from; windowStart.Before(to); {
+		windowEnd := windowStart.Add(queueSyncWindow)
+		if windowEnd.After(to) {
+			windowEnd = to
+		}
+
+		for _, queueName := range queues {
+			if err := w.syncQueueWindow(ctx, queueName, windowStart.Add(-queueSyncOverlap), windowEnd); err != nil {
+				return err
+			}
+		}
+
+		if err := w.advanceHighWaterMark(windowEnd); err != nil {
+			return err
+		}
+		windowStart = windowEnd
+	}
+
+	if err := w.acknowledgeChargebacks(ctx); err != nil {
+		return err
+	}
+	return w.syncCaseStatuses(ctx)
+}
+
+// queueNames returns the configured queues, or every queue Mastercom lists
+func (w *QueueSyncWorker) queueNames(ctx context.Context) ([]string, error) {
+	if len(w.config.Queues) > 0 {
+		return w.config.Queues, nil
+	}
+
+	queues, err := w.client.GetQueues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list queues: %w", err)
+	}
+
+	names := make([]string, 0, len(queues))
+	for _, queue := range queues {
+		names = append(names, queue.QueueName)
+	}
+	return names, nil
+}
+
+// syncQueueWindow pages through the claims modified in a queue between from and to
+func (w *QueueSyncWorker) syncQueueWindow(ctx context.Context, queueName string, from, to time.Time) error {
+	for page := 1; ; page++ {
+		content, err := w.client.GetQueueSummaryPost(ctx, &mastercom.GetQueueContentRequest{
+			QueueName:            queueName,
+			LastModifiedDateFrom: from.UTC().Format(queueSyncDateLayout),
+			LastModifiedDateTo:   to.UTC().Format(queueSyncDateLayout),
+			PageNb:               strconv.Itoa(page),
+		})
+		if err != nil {
+			return fmt.Errorf("read %s queue page %d: %w", queueName, page, err)
+		}
+
+		for _, summary := range content.ClaimList {
+			if summary.QueueName == "" {
+				summary.QueueName = queueName
+			}
+			if err := w.syncClaim(ctx, summary); err != nil {
+				return err
+			}
+		}
+
+		pageCount, _ := strconv.Atoi(content.PageCount)
+		if page >= pageCount {
+			return nil
+		}
+	}
+}
+
+// syncClaim stores a claim from a queue listing, reading its details only when the listing
+// shows it changed since the last sync
+func (w *QueueSyncWorker) syncClaim(ctx context.Context, summary mastercom.ClaimSummary) error {
+	if w.claimService.IsCurrent(summary.ClaimID, summary.QueueName, summary.LastModifiedDate) {
+		return nil
+	}
+
+	detail, err := w.client.GetClaimDetail(ctx, summary.ClaimID)
+	if err != nil {
+		return fmt.Errorf("read claim %s: %w", summary.ClaimID, err)
+	}
+
+	claim := newClaimFromMastercom(summary, detail)
+	switch w.claimService.UpsertClaim(claim) {
+	case models.ClaimCreated:
+		w.incrementStatus(func(status *models.QueueSyncStatus) { status.ClaimsCreated++ })
+	case models.ClaimUpdated:
+		w.incrementStatus(func(status *models.QueueSyncStatus) { status.ClaimsUpdated++ })
+	}
+
+	// Case filing claims for cases we filed carry their lifecycle status back to the local case
+	if claim.CaseID != "" && claim.CaseFilingStatus != "" {
+		changed, err := w.caseService.UpdateMastercomStatus(claim.CaseID, claim.CaseFilingStatus, "")
+		if err != nil && !errors.Is(err, ErrCaseNotFound) {
+			return err
+		}
+		if changed {
+			w.incrementStatus(func(status *models.QueueSyncStatus) { status.CasesUpdated++ })
+		}
+	}
+	return nil
+}
+
+// acknowledgeChargebacks marks stored chargebacks as processed in Mastercom
+func (w *QueueSyncWorker) acknowledgeChargebacks(ctx context.Context) error {
+	pending := w.claimService.PendingAcknowledgements()
+
+	for start := 0; start < len(pending); start += maxChargebackAcknowledgements {
+		end := start + maxChargebackAcknowledgements
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+
+		claimIDs := make(map[string]string, len(batch))
+		request := &mastercom.ChargebackMarkProcessedRequest{
+			ChargebackList: make([]mastercom.ChargebackMarkProcessedRequestStructure, 0, len(batch)),
+		}
+		for _, chargeback := range batch {
+			claimIDs[chargeback.ChargebackID] = chargeback.ClaimID
+			request.ChargebackList = append(request.ChargebackList, mastercom.ChargebackMarkProcessedRequestStructure{
+				ClaimID:      chargeback.ClaimID,
+				ChargebackID: chargeback.ChargebackID,
+			})
+		}
+
+		response, err := w.client.AcknowledgeChargebacks(ctx, request)
+		if err != nil {
+			return fmt.Errorf("acknowledge chargebacks: %w", err)
+		}
+
+		for _, result := range response.ChargebackResponseList {
+			claimID, ok := claimIDs[result.ChargebackID]
+			if !ok {
+				continue
+			}
+			if err := w.claimService.RecordAcknowledgement(claimID, result.ChargebackID, result.Status, result.FailureReason); err != nil {
+				return err
+			}
+			if result.Status == models.ChargebackAckProcessed {
+				w.incrementStatus(func(status *models.QueueSyncStatus) { status.ChargebacksAcknowledged++ })
+			}
+		}
+	}
+	return nil
+}
+
+// syncCaseStatuses refreshes the Mastercom image status of open filed cases
+func (w *QueueSyncWorker) syncCaseStatuses(ctx context.Context) error {
+	caseIDs := w.caseService.ListMastercomCaseIDs()
+
+	for start := 0; start < len(caseIDs); start += models.MaxCaseFilingStatusCases {
+		end := start + models.MaxCaseFilingStatusCases
+		if end > len(caseIDs) {
+			end = len(caseIDs)
+		}
+
+		request := &mastercom.CaseFilingStatusRequest{}
+		for _, caseID := range caseIDs[start:end] {
+			request.CaseFilingList = append(request.CaseFilingList, mastercom.CaseFilingStatusRequestStructure{CaseID: caseID})
+		}
+
+		response, err := w.client.RetrieveCaseFilingStatus(ctx, request)
+		if err != nil {
+			return fmt.Errorf("retrieve case filing status: %w", err)
+		}
+
+		for _, result := range response.CaseFilingResponseList {
+			changed, err := w.caseService.UpdateMastercomStatus(result.CaseID, "", result.Status)
+			if err != nil && !errors.Is(err, ErrCaseNotFound) {
+				return err
+			}
+			if changed {
+				w.incrementStatus(func(status *models.QueueSyncStatus) { status.CasesUpdated++ })
+			}
+		}
+	}
+	return nil
+}
+
+func (w *QueueSyncWorker) advanceHighWaterMark(highWaterMark time.Time) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	state := w.state
+	state.HighWaterMark = highWaterMark
+	if err := w.store.Save(&state); err != nil {
+		return err
+	}
+	w.state = state
+	return nil
+}
+
+func (w *QueueSyncWorker) incrementStatus(update func(status *models.QueueSyncStatus)) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	update(&w.status)
+}
+
+// newClaimFromMastercom maps a queue listing and the claim's details onto a local claim
+func newClaimFromMastercom(summary mastercom.ClaimSummary, detail *mastercom.ClaimDetail) *models.Claim {
+	claim := &models.Claim{
+		ClaimID:           summary.ClaimID,
+		ClaimType:         summary.ClaimType,
+		ClaimValue:        summary.ClaimValue,
+		QueueName:         summary.QueueName,
+		ProgressState:     summary.ProgressState,
+		IsOpen:            summary.IsOpen,
+		IsIssuer:          summary.IsIssuer,
+		IsAcquirer:        summary.IsAcquirer,
+		IssuerID:          summary.IssuerID,
+		AcquirerID:        summary.AcquirerID,
+		PrimaryAccountNum: summary.PrimaryAccountNum,
+		AcquirerRefNum:    summary.AcquirerRefNum,
+		TransactionID:     summary.TransactionID,
+		MerchantID:        summary.MerchantID,
+		DueDate:           summary.DueDate,
+		CreateDate:        summary.CreateDate,
+		LastModifiedBy:    summary.LastModifiedBy,
+		LastModifiedDate:  summary.LastModifiedDate,
+	}
+
+	if detail.CaseFilingDetails != nil {
+		claim.CaseFilingStatus = detail.CaseFilingDetails.CaseFilingStatus
+		if detail.CaseFilingDetails.CaseFilingDetails != nil {
+			claim.CaseID = detail.CaseFilingDetails.CaseFilingDetails.CaseID
+		}
+	}
+
+	for _, chargeback := range detail.ChargebackDetails {
+		claim.Chargebacks = append(claim.Chargebacks, models.Chargeback{
+			ChargebackID:     chargeback.ChargebackID,
+			ClaimID:          summary.ClaimID,
+			ChargebackType:   chargeback.ChargebackType,
+			ChargebackRefNum: chargeback.ChargebackRefNum,
+			Amount:           chargeback.Amount,
+			Currency:         chargeback.Currency,
+			ReasonCode:       chargeback.ReasonCode,
+			CreateDate:       chargeback.CreateDate,
+			DocumentStatus:   chargeback.DocumentStatus,
+			Reversed:         chargeback.Reversed,
+			Reversal:         chargeback.Reversal,
+			RejectReason:     chargeback.RejectReason,
+		})
+	}
+
+	for _, fee := range detail.FeeDetails {
+		claim.Fees = append(claim.Fees, models.Fee{
+			FeeID:             fee.FeeID,
+			ClaimID:           summary.ClaimID,
+			Amount:            fee.FeeAmount,
+			Currency:          fee.Currency,
+			Reason:            fee.Reason,
+			FeeDate:           fee.FeeDate,
+			DestinationMember: fee.DestinationMember,
+			CreditSender:      fee.CreditSender,
+			CreditReceiver:    fee.CreditReceiver,
+			RejectReason:      fee.RejectReason,
+		})
+	}
+
+	return claim
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueueSyncClient serves queue pages and claim details from memory
+type fakeQueueSyncClient struct {
+	queues       []string
+	pages        map[string][][]mastercom.ClaimSummary
+	details      map[string]*mastercom.ClaimDetail
+	caseStatuses map[string]string
+	failQueue    string
+
+	queueRequests   []*mastercom.GetQueueContentRequest
+	detailRequests  []string
+	acknowledged    []mastercom.ChargebackMarkProcessedRequestStructure
+	statusRequested []string
+}
+
+func (f *fakeQueueSyncClient) GetQueues(ctx context.Context) ([]mastercom.Queue, error) {
+	queues := make([]mastercom.Queue, 0, len(f.queues))
+	for _, name := range f.queues {
+		queues = append(queues, mastercom.Queue{QueueName: name})
+	}
+	return queues, nil
+}
+
+func (f *fakeQueueSyncClient) GetQueueSummaryPost(ctx context.Context, body *mastercom.GetQueueContentRequest) (*mastercom.QueueContentSummary, error) {
+	f.queueRequests = append(f.queueRequests, body)
+	if body.QueueName == f.failQueue {
+		return nil, &mastercom.APIError{StatusCode: http.StatusServiceUnavailable}
+	}
+
+	pages := f.pages[body.QueueName]
+	page, _ := strconv.Atoi(body.PageNb)
+	content := &mastercom.QueueContentSummary{PageCount: strconv.Itoa(len(pages))}
+	if page >= 1 && page <= len(pages) {
+		content.ClaimList = pages[page-1]
+	}
+	return content, nil
+}
+
+func (f *fakeQueueSyncClient) GetClaimDetail(ctx context.Context, claimID string) (*mastercom.ClaimDetail, error) {
+	f.detailRequests = append(f.detailRequests, claimID)
+	if detail, ok := f.details[claimID]; ok {
+		return detail, nil
+	}
+	return &mastercom.ClaimDetail{ClaimID: claimID}, nil
+}
+
+func (f *fakeQueueSyncClient) AcknowledgeChargebacks(ctx context.Context, body *mastercom.ChargebackMarkProcessedRequest) (*mastercom.ChargebackMarkProcessedResponse, error) {
+	response := &mastercom.ChargebackMarkProcessedResponse{}
+	for _, chargeback := range body.ChargebackList {
+		f.acknowledged = append(f.acknowledged, chargeback)
+		response.ChargebackResponseList = append(response.ChargebackResponseList, mastercom.ChargebackMarkProcessedResponseStructure{
+			ChargebackID: chargeback.ChargebackID,
+			Status:       models.ChargebackAckProcessed,
+		})
+	}
+	return response, nil
+}
+
+func (f *fakeQueueSyncClient) RetrieveCaseFilingStatus(ctx context.Context, body *mastercom.CaseFilingStatusRequest) (*mastercom.CaseFilingStatusResponse, error) {
+	response := &mastercom.CaseFilingStatusResponse{}
+	for _, caseFiling := range body.CaseFilingList {
+		f.statusRequested = append(f.statusRequested, caseFiling.CaseID)
+		response.CaseFilingResponseList = append(response.CaseFilingResponseList, mastercom.CaseFilingStatusResponseStructure{
+			CaseID: caseFiling.CaseID,
+			Status: f.caseStatuses[caseFiling.CaseID],
+		})
+	}
+	return response, nil
+}
+
+func setupQueueSyncWorker(t *testing.T, client QueueSyncClient) (*QueueSyncWorker, *ClaimService, *CaseService, *FileSyncStateStore) {
+	logger := logger.NewDatadogLogger()
+	claimService := NewClaimService(logger)
+	caseService := NewCaseService(logger)
+	store := NewFileSyncStateStore(filepath.Join(t.TempDir(), "state", "queue-sync.json"))
+	config := &models.QueueSyncConfig{Enabled: true, Interval: 1, InitialLookback: 24, MaxLag: 600}
+	return NewQueueSyncWorker(claimService, caseService, client, store, config, logger), claimService, caseService, store
+}
+
+func newFakeQueueSyncClient() *fakeQueueSyncClient {
+	return &fakeQueueSyncClient{
+		queues: []string{"Unworked", "Closed"},
+		pages: map[string][][]mastercom.ClaimSummary{
+			"Unworked": {
+				{{ClaimID: "200002020654", LastModifiedDate: "2024-07-15T12:01:30", IsOpen: true}},
+				{{ClaimID: "200002020655", LastModifiedDate: "2024-07-15T12:02:30", IsOpen: true}},
+			},
+			"Closed": {
+				{{ClaimID: "200002000151", QueueName: "Closed", LastModifiedDate: "2024-07-15T12:03:30"}},
+			},
+		},
+		details: map[string]*mastercom.ClaimDetail{
+			"200002020654": {
+				ChargebackDetails: []mastercom.ChargebackDetails{{
+					ChargebackID: "300002063556", ChargebackType: "CHARGEBACK", Amount: "25.50", Currency: "USD",
+				}},
+				FeeDetails: []mastercom.FeeDetails{{
+					FeeID: "300002002247", FeeAmount: "0.24", Currency: "USD", Reason: "7623", FeeDate: "2024-07-15",
+				}},
+			},
+			"200002000151": {CaseFilingDetails: &mastercom.CaseFilingLifeCycle{
+				CaseFilingStatus:  "Closed",
+				CaseFilingDetails: &mastercom.CaseFilingDetails{CaseID: "9000000012"},
+			}},
+		},
+		caseStatuses: map[string]string{"9000000012": "COMPLETED"},
+	}
+}
+
+func TestQueueSyncWorker_SyncOnce(t *testing.T) {
+	client := newFakeQueueSyncClient()
+	worker, claimService, caseService, store := setupQueueSyncWorker(t, client)
+
+	// A case we filed earlier is updated from its case filing claim
+	filed := createMockCase()
+	filed.QueueName = models.QueueSubmitted
+	filed.MastercomCaseID = "9000000012"
+	require.NoError(t, caseService.CreateCase(filed))
+
+	now := time.Date(2024, 7, 16, 12, 0, 0, 0, time.UTC)
+	worker.now = func() time.Time { return now }
+
+	require.NoError(t, worker.SyncOnce(context.Background()))
+
+	// Every page of every queue is read for the initial lookback window
+	require.Len(t, client.queueRequests, 3)
+	assert.Equal(t, "Unworked", client.queueRequests[0].QueueName)
+	assert.Equal(t, "2024-07-15T11:59", client.queueRequests[0].LastModifiedDateFrom)
+	assert.Equal(t, "2024-07-16T12:00", client.queueRequests[0].LastModifiedDateTo)
+	assert.Equal(t, "2", client.queueRequests[1].PageNb)
+
+	claim, err := claimService.GetClaim("200002020654")
+	require.NoError(t, err)
+	assert.Equal(t, "Unworked", claim.QueueName)
+	require.Len(t, claim.Chargebacks, 1)
+	assert.True(t, claim.Chargebacks[0].Acknowledged)
+	require.Len(t, claim.Fees, 1)
+	assert.Equal(t, models.Fee{FeeID: "300002002247", ClaimID: "200002020654", Amount: "0.24", Currency: "USD", Reason: "7623", FeeDate: "2024-07-15"}, claim.Fees[0])
+	assert.Equal(t, []mastercom.ChargebackMarkProcessedRequestStructure{{ClaimID: "200002020654", ChargebackID: "300002063556"}}, client.acknowledged)
+
+	_, err = claimService.GetClaim("200002020655")
+	require.NoError(t, err)
+
+	updated, err := caseService.GetCase(filed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Closed", updated.MastercomStatus)
+	assert.Equal(t, "COMPLETED", updated.MastercomImageStatus)
+
+	// The high-water mark is persisted
+	state, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, now, state.HighWaterMark)
+
+	status := worker.Status()
+	assert.Equal(t, 3, status.ClaimsCreated)
+	assert.Equal(t, 1, status.ChargebacksAcknowledged)
+	assert.Equal(t, 2, status.CasesUpdated)
+	assert.Empty(t, status.LastError)
+	assert.NotNil(t, status.LastSuccessAt)
+	assert.False(t, status.Stale)
+
+	// Unchanged claims are not read again and chargebacks are acknowledged once
+	client.queueRequests = nil
+	client.detailRequests = nil
+	now = now.Add(5 * time.Minute)
+	require.NoError(t, worker.SyncOnce(context.Background()))
+	assert.Empty(t, client.detailRequests)
+	assert.Len(t, client.acknowledged, 1)
+	assert.Equal(t, "2024-07-16T11:59", client.queueRequests[0].LastModifiedDateFrom)
+	assert.Equal(t, 0, worker.Status().ClaimsCreated)
+}
+
+func TestQueueSyncWorker_ResumesFromHighWaterMark(t *testing.T) {
+	client := newFakeQueueSyncClient()
+	client.queues = []string{"Unworked"}
+	worker, _, _, store := setupQueueSyncWorker(t, client)
+
+	highWaterMark := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(&models.QueueSyncState{HighWaterMark: highWaterMark}))
+
+	// A new worker picks up the saved state
+	restarted := NewQueueSyncWorker(worker.claimService, worker.caseService, client, store, worker.config, worker.logger)
+	now := time.Date(2024, 7, 12, 0, 0, 0, 0, time.UTC)
+	restarted.now = func() time.Time { return now }
+
+	require.NoError(t, restarted.SyncOnce(context.Background()))
+
+	// Eleven days are read in windows of at most five days, two pages each
+	var windows []string
+	for _, request := range client.queueRequests {
+		if request.PageNb == "1" {
+			windows = append(windows, request.LastModifiedDateFrom+"/"+request.LastModifiedDateTo)
+		}
+	}
+	assert.Equal(t, []string{
+		"2024-06-30T23:59/2024-07-06T00:00",
+		"2024-07-05T23:59/2024-07-11T00:00",
+		"2024-07-10T23:59/2024-07-12T00:00",
+	}, windows)
+}
+
+func TestQueueSyncWorker_FailureKeepsHighWaterMark(t *testing.T) {
+	client := newFakeQueueSyncClient()
+	client.failQueue = "Closed"
+	worker, claimService, _, store := setupQueueSyncWorker(t, client)
+
+	highWaterMark := time.Date(2024, 7, 16, 11, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(&models.QueueSyncState{HighWaterMark: highWaterMark}))
+	worker.state.HighWaterMark = highWaterMark
+
+	now := time.Date(2024, 7, 16, 12, 0, 0, 0, time.UTC)
+	worker.now = func() time.Time { return now }
+
+	err := worker.SyncOnce(context.Background())
+	require.Error(t, err)
+
+	// Claims read before the failure are kept, but the window is read again next time
+	_, err = claimService.GetClaim("200002020654")
+	assert.NoError(t, err)
+
+	state, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, highWaterMark, state.HighWaterMark)
+
+	status := worker.Status()
+	assert.Contains(t, status.LastError, "Closed")
+	assert.Nil(t, status.LastSuccessAt)
+	assert.Equal(t, int64(3600), status.LagSeconds)
+	assert.True(t, status.Stale)
+}
+
+func TestFileSyncStateStore(t *testing.T) {
+	store := NewFileSyncStateStore(filepath.Join(t.TempDir(), "queue-sync.json"))
+
+	state, err := store.Load()
+	require.NoError(t, err)
+	assert.True(t, state.HighWaterMark.IsZero())
+
+	highWaterMark := time.Date(2024, 7, 16, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(&models.QueueSyncState{HighWaterMark: highWaterMark}))
+
+	state, err = store.Load()
+	require.NoError(t, err)
+	assert.True(t, highWaterMark.Equal(state.HighWaterMark))
+}
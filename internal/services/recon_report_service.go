@@ -0,0 +1,246 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+const reconDateLayout = "2006-01-02"
+
+var (
+	// ErrReconReportNotFound is returned when a report identifier is not known
+	ErrReconReportNotFound = errors.New("recon report not found")
+	// ErrInvalidReconReportRequest is returned when a report request is malformed
+	ErrInvalidReconReportRequest = errors.New("invalid recon report request")
+)
+
+// ReconActivitySource provides activity records for reconciliation reports
+type ReconActivitySource interface {
+	// ReconActivity returns activity between start (inclusive) and end (exclusive).
+	// When icas is not empty only activity for those ICAs is returned.
+	ReconActivity(start, end time.Time, icas []string) ([]models.ReconRecord, error)
+}
+
+// ReconReportService generates reconciliation reports asynchronously from activity sources
+type ReconReportService struct {
+	sources []ReconActivitySource
+	reports map[string]*models.ReconReport
+	mutex   sync.RWMutex
+	logger  *logger.DatadogLogger
+}
+
+// NewReconReportService creates a new recon report service aggregating the given sources
+func NewReconReportService(logger *logger.DatadogLogger, sources ...ReconActivitySource) *ReconReportService {
+	return &ReconReportService{
+		sources: sources,
+		reports: make(map[string]*models.ReconReport),
+		logger:  logger,
+	}
+}
+
+// RequestReport validates a request and starts generating the report in the background,
+// returning the report immediately in the Unavailable state
+func (s *ReconReportService) RequestReport(req *models.ReconReportRequest) (*models.ReconReport, error) {
+	start, end, err := parseReconDates(req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := models.NewReconReport(req)
+
+	s.mutex.Lock()
+	s.reports[report.ID] = report
+	s.mutex.Unlock()
+
+	s.logger.Info("Recon report requested", logrus.Fields{
+		"reportId":  report.ID,
+		"startDate": req.StartDate,
+		"endDate":   req.EndDate,
+	})
+
+	go s.generate(report.ID, start, end, req.ICA)
+
+	return s.GetReport(report.ID)
+}
+
+// GetReport returns a snapshot of a report
+func (s *ReconReportService) GetReport(reportID string) (*models.ReconReport, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	report, exists := s.reports[reportID]
+	if !exists {
+		return nil, ErrReconReportNotFound
+	}
+
+	snapshot := *report
+	return &snapshot, nil
+}
+
+// ScopeReconReportRequest keeps a report request to the ICAs of scope. A request naming no
+// ICAs is for every ICA of scope, and ErrICANotPermitted is returned for a request naming an
+// ICA outside it.
+func ScopeReconReportRequest(req *models.ReconReportRequest, scope *models.ICAScope) error {
+	if scope.Unrestricted() {
+		return nil
+	}
+	if len(req.ICA) == 0 {
+		req.ICA = append([]string(nil), scope.ICAs...)
+		return nil
+	}
+	for _, ica := range req.ICA {
+		if !scope.Permits(ica) {
+			return fmt.Errorf("%w: %s", ErrICANotPermitted, ica)
+		}
+	}
+	return nil
+}
+
+// CheckReportScope returns ErrReconReportNotFound for a report that does not exist or covers
+// an ICA outside scope. A report requested for every ICA is only within an unrestricted scope.
+func (s *ReconReportService) CheckReportScope(reportID string, scope *models.ICAScope) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	report, exists := s.reports[reportID]
+	if !exists {
+		return ErrReconReportNotFound
+	}
+	if scope.Unrestricted() {
+		return nil
+	}
+	if len(report.Request.ICA) == 0 {
+		return ErrReconReportNotFound
+	}
+	for _, ica := range report.Request.ICA {
+		if !scope.Permits(ica) {
+			return ErrReconReportNotFound
+		}
+	}
+	return nil
+}
+
+// generate aggregates activity from every source and stores the outcome on the report
+func (s *ReconReportService) generate(reportID string, start, end time.Time, icas []string) {
+	var records []models.ReconRecord
+	var genErr error
+	for _, source := range s.sources {
+		sourceRecords, err := source.ReconActivity(start, end, icas)
+		if err != nil {
+			genErr = err
+			break
+		}
+		records = append(records, sourceRecords...)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].ActivityDate.Before(records[j].ActivityDate)
+	})
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	report := s.reports[reportID]
+	completedAt := time.Now()
+	report.CompletedAt = &completedAt
+
+	if genErr != nil {
+		report.Status = models.ReconReportFailed
+		report.Error = genErr.Error()
+		s.logger.Error("Recon report generation failed", logrus.Fields{
+			"reportId": reportID,
+			"error":    genErr.Error(),
+		})
+		return
+	}
+
+	report.Status = models.ReconReportAvailable
+	report.Records = records
+	s.logger.Info("Recon report generated", logrus.Fields{
+		"reportId":    reportID,
+		"recordCount": len(records),
+	})
+}
+
+// parseReconDates validates a request's date range, returning the start of the first day
+// and the start of the day after the last day
+func parseReconDates(req *models.ReconReportRequest) (time.Time, time.Time, error) {
+	start, err := time.Parse(reconDateLayout, req.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: startDate must be yyyy-MM-dd", ErrInvalidReconReportRequest)
+	}
+	end, err := time.Parse(reconDateLayout, req.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: endDate must be yyyy-MM-dd", ErrInvalidReconReportRequest)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: endDate is before startDate", ErrInvalidReconReportRequest)
+	}
+
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// RenderReconReportCSV renders report records in the recon report CSV format
+func RenderReconReportCSV(records []models.ReconRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	rows := [][]string{{
+		"Activity Type",
+		"Reference ID",
+		"ICA",
+		"Primary Account Number",
+		"Amount",
+		"Currency",
+		"Reason Code",
+		"Status",
+		"Activity Date",
+	}}
+	for _, record := range records {
+		rows = append(rows, []string{
+			record.ActivityType,
+			record.ReferenceID,
+			record.ICA,
+			maskAccountNumber(record.PrimaryAccountNumber),
+			fmt.Sprintf("%.2f", record.Amount),
+			record.Currency,
+			record.ReasonCode,
+			record.Status,
+			record.ActivityDate.UTC().Format(time.RFC3339),
+		})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// maskAccountNumber keeps only the last four digits of an account number
+func maskAccountNumber(pan string) string {
+	if len(pan) <= 4 {
+		return pan
+	}
+	return strings.Repeat("*", len(pan)-4) + pan[len(pan)-4:]
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
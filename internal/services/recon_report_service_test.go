@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReconSource struct{}
+
+func (failingReconSource) ReconActivity(start, end time.Time, icas []string) ([]models.ReconRecord, error) {
+	return nil, errors.New("source unavailable")
+}
+
+func waitForReconReport(t *testing.T, service *ReconReportService, reportID string) *models.ReconReport {
+	var report *models.ReconReport
+	require.Eventually(t, func() bool {
+		var err error
+		report, err = service.GetReport(reportID)
+		require.NoError(t, err)
+		return report.Status != models.ReconReportUnavailable
+	}, time.Second, 10*time.Millisecond)
+	return report
+}
+
+func TestReconReportService_RequestReport(t *testing.T) {
+	logger := logger.NewDatadogLogger()
+	caseService := NewCaseService(logger)
+	require.NoError(t, caseService.CreateCase(createMockCase()))
+
+	service := NewReconReportService(logger, caseService)
+	today := time.Now().Format(reconDateLayout)
+
+	report, err := service.RequestReport(&models.ReconReportRequest{StartDate: today, EndDate: today})
+	require.NoError(t, err)
+	assert.NotEmpty(t, report.ID)
+
+	report = waitForReconReport(t, service, report.ID)
+	assert.Equal(t, models.ReconReportAvailable, report.Status)
+	require.Len(t, report.Records, 1)
+	assert.Equal(t, models.ReconActivityCase, report.Records[0].ActivityType)
+	assert.Equal(t, "test-case-id", report.Records[0].ReferenceID)
+	assert.Equal(t, 100.00, report.Records[0].Amount)
+
+	// ICA filter matches the filed against ICA
+	report, err = service.RequestReport(&models.ReconReportRequest{ICA: []string{"654321"}, StartDate: today, EndDate: today})
+	require.NoError(t, err)
+	report = waitForReconReport(t, service, report.ID)
+	require.Len(t, report.Records, 1)
+	assert.Equal(t, "654321", report.Records[0].ICA)
+
+	// A range excluding every case
+	report, err = service.RequestReport(&models.ReconReportRequest{StartDate: "2017-01-01", EndDate: "2017-01-31"})
+	require.NoError(t, err)
+	report = waitForReconReport(t, service, report.ID)
+	assert.Equal(t, models.ReconReportAvailable, report.Status)
+	assert.Empty(t, report.Records)
+}
+
+func TestReconReportService_FeeActivity(t *testing.T) {
+	logger := logger.NewDatadogLogger()
+	claimService := NewClaimService(logger)
+	claim := createMockClaim()
+	claim.Fees = []models.Fee{{FeeID: "300002002247", ClaimID: claim.ClaimID, Amount: "0.24", Currency: "USD", Reason: "7623", FeeDate: "2024-07-15"}}
+	claimService.UpsertClaim(claim)
+
+	service := NewReconReportService(logger, claimService, NewClaimFeeSource(claimService))
+	report, err := service.RequestReport(&models.ReconReportRequest{StartDate: "2024-07-15", EndDate: "2024-07-15"})
+	require.NoError(t, err)
+
+	report = waitForReconReport(t, service, report.ID)
+	require.Len(t, report.Records, 2)
+	activityTypes := []string{report.Records[0].ActivityType, report.Records[1].ActivityType}
+	assert.ElementsMatch(t, []string{models.ReconActivityChargeback, models.ReconActivityFee}, activityTypes)
+}
+
+func TestReconReportService_RequestReport_Invalid(t *testing.T) {
+	service := NewReconReportService(logger.NewDatadogLogger())
+
+	_, err := service.RequestReport(&models.ReconReportRequest{StartDate: "2024/01/01", EndDate: "2024-01-31"})
+	assert.ErrorIs(t, err, ErrInvalidReconReportRequest)
+
+	_, err = service.RequestReport(&models.ReconReportRequest{StartDate: "2024-02-01", EndDate: "2024-01-31"})
+	assert.ErrorIs(t, err, ErrInvalidReconReportRequest)
+
+	_, err = service.GetReport("nonexistent-id")
+	assert.ErrorIs(t, err, ErrReconReportNotFound)
+}
+
+func TestReconReportService_Scope(t *testing.T) {
+	service := NewReconReportService(logger.NewDatadogLogger())
+	scope := &models.ICAScope{ICAs: []string{"123456", "654321"}}
+
+	// A request naming no ICAs covers every ICA of the scope
+	req := &models.ReconReportRequest{StartDate: "2024-01-01", EndDate: "2024-01-31"}
+	require.NoError(t, ScopeReconReportRequest(req, scope))
+	assert.Equal(t, []string{"123456", "654321"}, req.ICA)
+	report, err := service.RequestReport(req)
+	require.NoError(t, err)
+	assert.NoError(t, service.CheckReportScope(report.ID, scope))
+	assert.ErrorIs(t, service.CheckReportScope(report.ID, &models.ICAScope{ICAs: []string{"123456"}}), ErrReconReportNotFound)
+	assert.NoError(t, service.CheckReportScope(report.ID, nil))
+
+	req = &models.ReconReportRequest{ICA: []string{"654321", "999999"}, StartDate: "2024-01-01", EndDate: "2024-01-31"}
+	assert.ErrorIs(t, ScopeReconReportRequest(req, scope), ErrICANotPermitted)
+
+	// A report for every ICA is only within an unrestricted scope
+	req = &models.ReconReportRequest{StartDate: "2024-01-01", EndDate: "2024-01-31"}
+	require.NoError(t, ScopeReconReportRequest(req, &models.ICAScope{ICAs: []string{models.AllICAs}}))
+	assert.Empty(t, req.ICA)
+	report, err = service.RequestReport(req)
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.CheckReportScope(report.ID, scope), ErrReconReportNotFound)
+	assert.ErrorIs(t, service.CheckReportScope("nonexistent-id", nil), ErrReconReportNotFound)
+}
+
+func TestReconReportService_GenerationFailure(t *testing.T) {
+	service := NewReconReportService(logger.NewDatadogLogger(), failingReconSource{})
+
+	report, err := service.RequestReport(&models.ReconReportRequest{StartDate: "2024-01-01", EndDate: "2024-01-31"})
+	require.NoError(t, err)
+
+	report = waitForReconReport(t, service, report.ID)
+	assert.Equal(t, models.ReconReportFailed, report.Status)
+	assert.Equal(t, "source unavailable", report.Error)
+}
+
+func TestRenderReconReportCSV(t *testing.T) {
+	data, err := RenderReconReportCSV([]models.ReconRecord{{
+		ActivityType:         models.ReconActivityCase,
+		ReferenceID:          "case-1",
+		ICA:                  "123456",
+		PrimaryAccountNumber: "4111111111111111",
+		Amount:               12.5,
+		Currency:             "USD",
+		ReasonCode:           "4853",
+		Status:               "PENDING",
+		ActivityDate:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "Activity Type,Reference ID"))
+	assert.Equal(t, "CASE,case-1,123456,************1111,12.50,USD,4853,PENDING,2024-01-02T03:04:05Z", lines[1])
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RetentionService purges case evidence once its retention period has ended
+type RetentionService struct {
+	caseService     *CaseService
+	documentService *DocumentService
+	config          *models.RetentionConfig
+	logger          *logger.DatadogLogger
+	now             func() time.Time
+}
+
+// NewRetentionService creates a new retention service
+func NewRetentionService(caseService *CaseService, documentService *DocumentService, config *models.RetentionConfig, logger *logger.DatadogLogger) *RetentionService {
+	return &RetentionService{
+		caseService:     caseService,
+		documentService: documentService,
+		config:          config,
+		logger:          logger,
+		now:             time.Now,
+	}
+}
+
+// Config returns the retention configuration in force
+func (s *RetentionService) Config() *models.RetentionConfig {
+	return s.config
+}
+
+// Policy returns the retention policy for a case of caseType in queueName, preferring a policy
+// naming the case type, then one naming the queue, over wildcards. It returns false when no
+// policy applies.
+func (s *RetentionService) Policy(caseType, queueName string) (models.RetentionPolicy, bool) {
+	best, bestScore := models.RetentionPolicy{}, -1
+	for _, policy := range s.config.Policies {
+		if !policy.Matches(caseType, queueName) {
+			continue
+		}
+		score := 0
+		if policy.CaseType != models.RetentionAny {
+			score += 2
+		}
+		if policy.Queue != models.RetentionAny {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = policy, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// Purge removes the cases and documents past their retention period, and deleted documents
+// past their grace period, recording each purge in the case's audit history. Cases on legal
+// hold are kept, with their documents. A dry run, which the configuration can force, only
+// reports what would be purged.
+func (s *RetentionService) Purge(dryRun bool) *models.PurgeReport {
+	now := s.now()
+	report := &models.PurgeReport{
+		DryRun:    dryRun || s.config.DryRun,
+		StartedAt: now,
+		Cases:     []models.PurgedCase{},
+		Documents: []models.PurgedDocument{},
+		Held:      []string{},
+	}
+
+	for _, caseObj := range s.caseService.ListAllCases() {
+		policy, ok := s.Policy(caseObj.CaseType, caseObj.QueueName)
+		if !ok {
+			continue
+		}
+		since := caseObj.QueuedAt
+		if since.IsZero() {
+			since = caseObj.UpdatedAt
+		}
+		purgeCase := policy.CaseDays > 0 && !now.Before(since.AddDate(0, 0, policy.CaseDays))
+		purgeDocuments := purgeCase || (policy.DocumentDays > 0 && !now.Before(since.AddDate(0, 0, policy.DocumentDays)))
+		if !purgeDocuments {
+			continue
+		}
+
+		documents := s.documentService.ListAllCaseDocuments(caseObj.ID)
+		if !purgeCase && len(documents) == 0 {
+			continue
+		}
+		if caseObj.LegalHold != nil {
+			report.Held = append(report.Held, caseObj.ID)
+			continue
+		}
+
+		if !s.purgeDocuments(report, documents, models.PurgeReasonCaseRetention, policy) {
+			continue
+		}
+		if purgeCase {
+			s.purgeCase(report, &caseObj, policy)
+		} else if !report.DryRun {
+			s.syncCaseDocuments(caseObj.ID)
+		}
+	}
+
+	if s.config.DeletedDocumentDays > 0 {
+		// A dry run leaves the documents it reported above in place; they are not reported twice
+		reported := make(map[string]bool, len(report.Documents))
+		for _, purged := range report.Documents {
+			reported[purged.DocumentID] = true
+		}
+		deletedBefore := now.AddDate(0, 0, -s.config.DeletedDocumentDays)
+		for _, document := range s.documentService.ListDeletedDocuments(deletedBefore) {
+			if reported[document.ID] || s.caseService.OnLegalHold(document.CaseID) {
+				continue
+			}
+			s.purgeDocuments(report, []*models.Document{document}, models.PurgeReasonDeleted, models.RetentionPolicy{})
+		}
+	}
+
+	s.logger.Info("Retention purge completed", logrus.Fields{
+		"dryRun":    report.DryRun,
+		"cases":     len(report.Cases),
+		"documents": len(report.Documents),
+		"held":      len(report.Held),
+	})
+	return report
+}
+
+// purgeDocuments purges documents and adds them to the report. It reports false when the
+// documents' case was placed on legal hold in the meantime, leaving the rest in place.
+func (s *RetentionService) purgeDocuments(report *models.PurgeReport, documents []*models.Document, reason string, policy models.RetentionPolicy) bool {
+	for _, document := range documents {
+		purged := models.PurgedDocument{
+			DocumentID: document.ID,
+			CaseID:     document.CaseID,
+			FileName:   document.FileName,
+			Versions:   document.Version,
+			Reason:     reason,
+		}
+		if report.DryRun {
+			report.Documents = append(report.Documents, purged)
+			continue
+		}
+
+		if s.caseService.OnLegalHold(document.CaseID) {
+			report.Held = append(report.Held, document.CaseID)
+			return false
+		}
+		versions, err := s.documentService.PurgeDocument(document.ID)
+		if err != nil {
+			if !errors.Is(err, ErrDocumentNotFound) {
+				s.logger.Error("Failed to purge document", logrus.Fields{
+					"documentId": document.ID,
+					"caseId":     document.CaseID,
+					"error":      err.Error(),
+				})
+			}
+			continue
+		}
+		purged.Versions = versions
+		report.Documents = append(report.Documents, purged)
+
+		details := map[string]string{
+			"documentId": document.ID,
+			"fileName":   document.FileName,
+			"sha256":     document.SHA256,
+			"versions":   strconv.Itoa(versions),
+			"reason":     reason,
+		}
+		if reason == models.PurgeReasonCaseRetention {
+			details["policy"] = policyName(policy)
+		}
+		s.caseService.RecordCaseAudit(document.CaseID, models.CaseAuditDocumentPurged, details)
+	}
+	return true
+}
+
+// purgeCase purges a case whose documents have been purged and adds it to the report
+func (s *RetentionService) purgeCase(report *models.PurgeReport, caseObj *models.Case, policy models.RetentionPolicy) {
+	purged := models.PurgedCase{
+		CaseID:    caseObj.ID,
+		CaseType:  caseObj.CaseType,
+		QueueName: caseObj.QueueName,
+	}
+	if report.DryRun {
+		report.Cases = append(report.Cases, purged)
+		return
+	}
+
+	err := s.caseService.PurgeCase(caseObj.ID, map[string]string{
+		"caseType": caseObj.CaseType,
+		"queue":    caseObj.QueueName,
+		"policy":   policyName(policy),
+	})
+	switch {
+	case errors.Is(err, ErrCaseOnLegalHold):
+		report.Held = append(report.Held, caseObj.ID)
+	case err != nil:
+		if !errors.Is(err, ErrCaseNotFound) {
+			s.logger.Error("Failed to purge case", logrus.Fields{
+				"caseId": caseObj.ID,
+				"error":  err.Error(),
+			})
+		}
+	default:
+		report.Cases = append(report.Cases, purged)
+	}
+}
+
+// syncCaseDocuments refreshes a case's document list after its documents were purged
+func (s *RetentionService) syncCaseDocuments(caseID string) {
+	documents, err := s.documentService.GetDocumentsByCaseID(caseID)
+	if err != nil {
+		return
+	}
+	if err := s.caseService.SetCaseDocuments(caseID, documents); err != nil && !errors.Is(err, ErrCaseNotFound) {
+		s.logger.Error("Failed to refresh case documents after purge", logrus.Fields{
+			"caseId": caseID,
+			"error":  err.Error(),
+		})
+	}
+}
+
+// policyName identifies a policy in audit entries, as caseType:queue
+func policyName(policy models.RetentionPolicy) string {
+	return policy.CaseType + ":" + policy.Queue
+}
+
+// RetentionWorker periodically purges evidence past its retention period
+type RetentionWorker struct {
+	retentionService *RetentionService
+	config           *models.RetentionConfig
+	logger           *logger.DatadogLogger
+}
+
+// NewRetentionWorker creates a new retention worker
+func NewRetentionWorker(retentionService *RetentionService, config *models.RetentionConfig, logger *logger.DatadogLogger) *RetentionWorker {
+	return &RetentionWorker{
+		retentionService: retentionService,
+		config:           config,
+		logger:           logger,
+	}
+}
+
+// Run purges expired evidence until ctx is cancelled
+func (w *RetentionWorker) Run(ctx context.Context) {
+	interval := time.Duration(w.config.Interval) * time.Second
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	w.logger.Info("Retention worker started", logrus.Fields{
+		"interval": interval.String(),
+		"dryRun":   w.config.DryRun,
+		"policies": len(w.config.Policies),
+	})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Retention worker stopped", nil)
+			return
+		case <-ticker.C:
+			report := w.retentionService.Purge(false)
+			if report.DryRun {
+				for _, purged := range report.Cases {
+					w.logger.Info("Retention dry run: case would be purged", logrus.Fields{
+						"caseId":   purged.CaseID,
+						"caseType": purged.CaseType,
+						"queue":    purged.QueueName,
+					})
+				}
+				for _, purged := range report.Documents {
+					w.logger.Info("Retention dry run: document would be purged", logrus.Fields{
+						"documentId": purged.DocumentID,
+						"caseId":     purged.CaseID,
+						"reason":     purged.Reason,
+					})
+				}
+			}
+		}
+	}
+}
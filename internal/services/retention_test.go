@@ -0,0 +1,248 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRetentionService(config *models.RetentionConfig) (*RetentionService, *CaseDocumentService, *CaseService, *DocumentService) {
+	logger := logger.NewDatadogLogger()
+	caseService := NewCaseService(logger)
+	documentService := NewDocumentService(logger)
+	caseDocumentService := NewCaseDocumentService(caseService, documentService, logger)
+	return NewRetentionService(caseService, documentService, config, logger), caseDocumentService, caseService, documentService
+}
+
+// closedCase stores a case closed at closedAt, with one document
+func closedCase(t *testing.T, caseDocumentService *CaseDocumentService, caseService *CaseService, id, caseType string, closedAt time.Time) *models.Document {
+	caseObj := createMockCase()
+	caseObj.ID = id
+	caseObj.CaseType = caseType
+	caseObj.QueueName = models.QueueClosed
+	caseObj.QueuedAt = closedAt
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	document := models.NewDocument(id, "receipt.pdf", "application/pdf", []byte("evidence for "+id), "test-user", "")
+	require.NoError(t, caseDocumentService.AttachDocument(id, document))
+	return document
+}
+
+func auditActions(t *testing.T, caseService *CaseService, caseID string) []string {
+	entries, err := caseService.GetCaseAudit(caseID)
+	require.NoError(t, err)
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func TestRetentionService_Policy(t *testing.T) {
+	service, _, _, _ := setupRetentionService(&models.RetentionConfig{Policies: []models.RetentionPolicy{
+		{CaseType: "*", Queue: "*", DocumentDays: 1000},
+		{CaseType: "*", Queue: models.QueueClosed, DocumentDays: 365},
+		{CaseType: "PRE_ARBITRATION", Queue: "*", DocumentDays: 180},
+		{CaseType: "PRE_ARBITRATION", Queue: models.QueueClosed, DocumentDays: 90},
+	}})
+
+	policy, ok := service.Policy("PRE_ARBITRATION", models.QueueClosed)
+	require.True(t, ok)
+	assert.Equal(t, 90, policy.DocumentDays)
+	policy, _ = service.Policy("PRE_ARBITRATION", models.QueueWorked)
+	assert.Equal(t, 180, policy.DocumentDays)
+	policy, _ = service.Policy("ARBITRATION", models.QueueClosed)
+	assert.Equal(t, 365, policy.DocumentDays)
+	policy, _ = service.Policy("ARBITRATION", models.QueueWorked)
+	assert.Equal(t, 1000, policy.DocumentDays)
+
+	empty, _, _, _ := setupRetentionService(&models.RetentionConfig{})
+	_, ok = empty.Policy("ARBITRATION", models.QueueClosed)
+	assert.False(t, ok)
+}
+
+func TestRetentionService_PurgesAfterClosure(t *testing.T) {
+	service, caseDocumentService, caseService, documentService := setupRetentionService(&models.RetentionConfig{Policies: []models.RetentionPolicy{
+		{CaseType: "*", Queue: models.QueueClosed, DocumentDays: 90, CaseDays: 365},
+	}})
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	recent := closedCase(t, caseDocumentService, caseService, "closed-recently", "PRE_ARBITRATION", now.AddDate(0, 0, -10))
+	expired := closedCase(t, caseDocumentService, caseService, "closed-last-quarter", "PRE_ARBITRATION", now.AddDate(0, 0, -100))
+	require.NoError(t, documentService.ReplaceDocumentContent(expired.ID, models.NewDocument("", "receipt.pdf", "application/pdf", []byte("second version"), "test-user", "")))
+	closedCase(t, caseDocumentService, caseService, "closed-last-year", "ARBITRATION", now.AddDate(0, 0, -400))
+
+	report := service.Purge(false)
+	assert.False(t, report.DryRun)
+	require.Len(t, report.Documents, 2)
+	require.Len(t, report.Cases, 1)
+	assert.Equal(t, "closed-last-year", report.Cases[0].CaseID)
+	assert.Empty(t, report.Held)
+
+	// Recently closed cases keep everything
+	_, err := documentService.GetDocument(recent.ID)
+	assert.NoError(t, err)
+
+	// Documents past their retention period are gone with every version and their content
+	_, err = documentService.GetDocument(expired.ID)
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	_, err = documentService.RestoreDocument(expired.ID)
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	_, err = documentService.GetDocumentVersion(expired.ID, 1)
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	assert.NotContains(t, documentService.contents, expired.SHA256)
+	caseObj, err := caseService.GetCase("closed-last-quarter")
+	require.NoError(t, err)
+	assert.Empty(t, caseObj.Documents)
+
+	// Cases past theirs are gone too, with their audit history kept
+	_, err = caseService.GetCase("closed-last-year")
+	assert.ErrorIs(t, err, ErrCaseNotFound)
+	assert.Contains(t, auditActions(t, caseService, "closed-last-year"), models.CaseAuditPurged)
+
+	entries, err := caseService.GetCaseAudit("closed-last-quarter")
+	require.NoError(t, err)
+	purged := entries[len(entries)-1]
+	assert.Equal(t, models.CaseAuditDocumentPurged, purged.Action)
+	assert.Equal(t, expired.ID, purged.Details["documentId"])
+	assert.Equal(t, "2", purged.Details["versions"])
+	assert.Equal(t, models.PurgeReasonCaseRetention, purged.Details["reason"])
+	assert.Equal(t, "*:Closed", purged.Details["policy"])
+
+	// A second purge finds nothing left to do
+	report = service.Purge(false)
+	assert.Empty(t, report.Documents)
+	assert.Empty(t, report.Cases)
+}
+
+func TestRetentionService_DryRun(t *testing.T) {
+	config := &models.RetentionConfig{Policies: []models.RetentionPolicy{
+		{CaseType: "*", Queue: models.QueueClosed, DocumentDays: 30, CaseDays: 30},
+	}}
+	service, caseDocumentService, caseService, documentService := setupRetentionService(config)
+	document := closedCase(t, caseDocumentService, caseService, "closed-case", "PRE_ARBITRATION", time.Now().AddDate(0, 0, -60))
+
+	report := service.Purge(true)
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Documents, 1)
+	assert.Equal(t, document.ID, report.Documents[0].DocumentID)
+	assert.Equal(t, 1, report.Documents[0].Versions)
+	require.Len(t, report.Cases, 1)
+
+	// Nothing is purged, nor audited
+	_, err := documentService.GetDocument(document.ID)
+	assert.NoError(t, err)
+	_, err = caseService.GetCase("closed-case")
+	assert.NoError(t, err)
+	assert.NotContains(t, auditActions(t, caseService, "closed-case"), models.CaseAuditDocumentPurged)
+
+	// The configuration can force dry runs
+	config.DryRun = true
+	report = service.Purge(false)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Cases, 1)
+	_, err = caseService.GetCase("closed-case")
+	assert.NoError(t, err)
+}
+
+func TestRetentionService_LegalHold(t *testing.T) {
+	service, caseDocumentService, caseService, documentService := setupRetentionService(&models.RetentionConfig{
+		DeletedDocumentDays: 30,
+		Policies: []models.RetentionPolicy{
+			{CaseType: "*", Queue: models.QueueClosed, DocumentDays: 30, CaseDays: 30},
+		},
+	})
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	document := closedCase(t, caseDocumentService, caseService, "held-case", "PRE_ARBITRATION", now.AddDate(0, 0, -60))
+	require.NoError(t, documentService.DeleteDocument(document.ID))
+	deletedAt := now.AddDate(0, 0, -45)
+	document.DeletedAt = &deletedAt
+
+	_, err := caseService.PlaceLegalHold("held-case", &models.LegalHold{Reason: "Litigation", PlacedBy: "counsel"})
+	require.NoError(t, err)
+
+	report := service.Purge(false)
+	assert.Empty(t, report.Documents)
+	assert.Empty(t, report.Cases)
+	assert.Equal(t, []string{"held-case"}, report.Held)
+	_, err = documentService.RestoreDocument(document.ID)
+	require.NoError(t, err)
+
+	// Held cases cannot be deleted either
+	assert.ErrorIs(t, caseDocumentService.DeleteCase("held-case", true), ErrCaseOnLegalHold)
+	assert.ErrorIs(t, caseService.DeleteCase("held-case"), ErrCaseOnLegalHold)
+
+	_, err = caseService.ReleaseLegalHold("held-case", "counsel")
+	require.NoError(t, err)
+	_, err = caseService.ReleaseLegalHold("held-case", "counsel")
+	assert.ErrorIs(t, err, ErrCaseNotOnLegalHold)
+
+	report = service.Purge(false)
+	assert.Len(t, report.Documents, 1)
+	assert.Len(t, report.Cases, 1)
+	assert.Equal(t, []string{
+		models.CaseAuditLegalHoldPlaced,
+		models.CaseAuditLegalHoldReleased,
+		models.CaseAuditDocumentPurged,
+		models.CaseAuditPurged,
+	}, auditActions(t, caseService, "held-case")[2:])
+}
+
+func TestRetentionService_PurgesDeletedDocuments(t *testing.T) {
+	service, caseDocumentService, caseService, documentService := setupRetentionService(&models.RetentionConfig{DeletedDocumentDays: 30})
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	caseObj := createMockCase()
+	require.NoError(t, caseService.CreateCase(caseObj))
+	kept := models.NewDocument(caseObj.ID, "kept.pdf", "application/pdf", []byte("kept"), "test-user", "")
+	recent := models.NewDocument(caseObj.ID, "recent.pdf", "application/pdf", []byte("recent"), "test-user", "")
+	old := models.NewDocument(caseObj.ID, "old.pdf", "application/pdf", []byte("old"), "test-user", "")
+	for _, document := range []*models.Document{kept, recent, old} {
+		require.NoError(t, caseDocumentService.AttachDocument(caseObj.ID, document))
+	}
+	require.NoError(t, documentService.DeleteDocument(recent.ID))
+	require.NoError(t, documentService.DeleteDocument(old.ID))
+	deletedAt := now.AddDate(0, 0, -31)
+	documentService.documents[old.ID].DeletedAt = &deletedAt
+
+	report := service.Purge(false)
+	require.Len(t, report.Documents, 1)
+	assert.Equal(t, old.ID, report.Documents[0].DocumentID)
+	assert.Equal(t, models.PurgeReasonDeleted, report.Documents[0].Reason)
+
+	_, err := documentService.RestoreDocument(old.ID)
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	_, err = documentService.RestoreDocument(recent.ID)
+	assert.NoError(t, err)
+	_, err = documentService.GetDocument(kept.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, auditActions(t, caseService, caseObj.ID), models.CaseAuditDocumentPurged)
+}
+
+func TestCaseService_TransitionCaseSetsQueuedAt(t *testing.T) {
+	service := setupCaseService()
+	caseObj := createMockCase()
+	caseObj.QueueName = models.QueueWorked
+	require.NoError(t, service.CreateCase(caseObj))
+
+	before := time.Now()
+	closed, err := service.TransitionCase(caseObj.ID, models.QueueActionClose)
+	require.NoError(t, err)
+	assert.False(t, closed.QueuedAt.Before(before))
+
+	// Updates keep the queue time and legal hold
+	_, err = service.PlaceLegalHold(caseObj.ID, &models.LegalHold{Reason: "Litigation"})
+	require.NoError(t, err)
+	update := createMockCase()
+	require.NoError(t, service.UpdateCase(update))
+	assert.Equal(t, closed.QueuedAt, update.QueuedAt)
+	assert.NotNil(t, update.LegalHold)
+}
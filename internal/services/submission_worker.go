@@ -0,0 +1,450 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/mastercom"
+	"mastercom-service/pkg/pan"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// submissionErrorSource marks submission errors raised locally rather than by Mastercom
+const submissionErrorSource = "mastercom-service"
+
+// CaseFilingClient files cases with Mastercom; *mastercom.Client implements it
+type CaseFilingClient interface {
+	CreateCaseFiling(ctx context.Context, body *mastercom.CreateCaseRequest) (*mastercom.CaseFilingResponse, error)
+}
+
+// SubmissionWorker files cases waiting in the Submitted queue with Mastercom, retrying
+// transient failures with exponential backoff
+type SubmissionWorker struct {
+	caseService     *CaseService
+	documentService *DocumentService
+	client          CaseFilingClient
+	config          *models.SubmissionConfig
+	logger          *logger.DatadogLogger
+	now             func() time.Time
+}
+
+// NewSubmissionWorker creates a new submission worker
+func NewSubmissionWorker(caseService *CaseService, documentService *DocumentService, client CaseFilingClient, config *models.SubmissionConfig, logger *logger.DatadogLogger) *SubmissionWorker {
+	return &SubmissionWorker{
+		caseService:     caseService,
+		documentService: documentService,
+		client:          client,
+		config:          config,
+		logger:          logger,
+		now:             time.Now,
+	}
+}
+
+// Run polls for cases ready to file until ctx is cancelled
+func (w *SubmissionWorker) Run(ctx context.Context) {
+	interval := time.Duration(w.config.Interval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	w.logger.Info("Case submission worker started", logrus.Fields{"interval": interval.String()})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.ProcessOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Case submission worker stopped", nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessOnce attempts to file every case that is currently due and returns the number
+// of cases attempted
+func (w *SubmissionWorker) ProcessOnce(ctx context.Context) int {
+	attempted := 0
+	for _, caseObj := range w.caseService.ListCasesReadyForSubmission(w.now()) {
+		if ctx.Err() != nil {
+			break
+		}
+		if w.submitCase(ctx, &caseObj) {
+			attempted++
+		}
+	}
+	return attempted
+}
+
+// submitCase files a single case and records the outcome on it. It reports whether filing
+// was attempted.
+func (w *SubmissionWorker) submitCase(ctx context.Context, caseObj *models.Case) bool {
+	span := tracer.StartSpan("case.submit", tracer.ResourceName("SubmitCase"))
+	defer span.Finish()
+
+	span.SetTag("case.id", caseObj.ID)
+	span.SetTag("case.submission_attempt", caseObj.SubmissionAttempts+1)
+
+	documents, err := w.submissionDocuments(caseObj.ID)
+	if err != nil {
+		w.recordFailure(span, caseObj, err)
+		return true
+	}
+
+	// Documents awaiting a malware scan hold the case back without using up an attempt
+	for _, document := range documents {
+		if document.ProcessingStatus == models.DocumentStatusQuarantined {
+			w.logger.InfoWithSpan(span, "Case submission deferred until its documents are scanned", logrus.Fields{
+				"caseId":     caseObj.ID,
+				"documentId": document.ID,
+			})
+			span.SetTag("case.submission_deferred", true)
+			return false
+		}
+	}
+
+	// Only the filing itself sees the full card number
+	accountNumber, err := w.caseService.DetokenizePAN(ctx, caseObj.ID, map[string]string{"purpose": "case filing"})
+	if err != nil {
+		w.recordFailure(span, caseObj, err)
+		return true
+	}
+
+	request, err := newCaseFilingRequest(caseObj, accountNumber, documents)
+	if err != nil {
+		w.recordFailure(span, caseObj, err)
+		return true
+	}
+
+	response, err := w.client.CreateCaseFiling(ctx, request)
+	if err != nil {
+		// Shutting down is not a failed attempt; the case is picked up again on restart
+		if ctx.Err() != nil {
+			return true
+		}
+		w.recordFailure(span, caseObj, err)
+		return true
+	}
+
+	if _, err := w.caseService.MarkCaseSubmitted(caseObj.ID, response.CaseID); err != nil {
+		w.logger.ErrorWithSpan(span, "Failed to record case submission", logrus.Fields{
+			"caseId": caseObj.ID,
+			"error":  err.Error(),
+		})
+		return true
+	}
+
+	for _, document := range documents {
+		w.markDelivered(document)
+		// The documents merged into a bundle are delivered with it
+		for _, sourceID := range document.DerivedFrom {
+			if source, err := w.documentService.GetDocument(sourceID); err == nil {
+				w.markDelivered(source)
+			}
+		}
+	}
+
+	span.SetTag("case.mastercom_id", response.CaseID)
+	w.logger.InfoWithSpan(span, "Case filed with Mastercom", logrus.Fields{
+		"caseId":          caseObj.ID,
+		"mastercomCaseId": response.CaseID,
+		"documents":       len(documents),
+	})
+	return true
+}
+
+// recordFailure stores a failed attempt on the case, scheduling a retry when the failure is
+// transient and attempts remain
+func (w *SubmissionWorker) recordFailure(span tracer.Span, caseObj *models.Case, err error) {
+	attempt := caseObj.SubmissionAttempts + 1
+	transient := isTransientSubmissionError(err)
+
+	status := models.SubmissionStatusRejected
+	var retryAt *time.Time
+	if transient {
+		status = models.SubmissionStatusFailed
+		if attempt < w.config.MaxAttempts {
+			next := w.now().Add(w.backoff(attempt))
+			retryAt = &next
+		}
+	}
+
+	w.logger.ErrorWithSpan(span, "Case submission failed", logrus.Fields{
+		"caseId":    caseObj.ID,
+		"attempt":   attempt,
+		"transient": transient,
+		"error":     err.Error(),
+	})
+	span.SetTag("error", true)
+	span.SetTag("error.message", err.Error())
+
+	if _, recordErr := w.caseService.RecordSubmissionFailure(caseObj.ID, status, w.submissionErrors(err), retryAt); recordErr != nil {
+		w.logger.ErrorWithSpan(span, "Failed to record case submission failure", logrus.Fields{
+			"caseId": caseObj.ID,
+			"error":  recordErr.Error(),
+		})
+	}
+}
+
+// backoff returns the delay before retrying after the given attempt: BackoffBase doubled for
+// each previous attempt, capped at BackoffMax
+func (w *SubmissionWorker) backoff(attempt int) time.Duration {
+	delay := time.Duration(w.config.BackoffBase) * time.Second
+	maxDelay := time.Duration(w.config.BackoffMax) * time.Second
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// submissionErrors converts an error into the details stored on the case, keeping each
+// error Mastercom returned
+func (w *SubmissionWorker) submissionErrors(err error) []models.SubmissionError {
+	occurredAt := w.now()
+
+	apiErr, ok := mastercom.AsAPIError(err)
+	if !ok || len(apiErr.Errors) == 0 {
+		return []models.SubmissionError{{
+			Source:      submissionErrorSource,
+			Description: err.Error(),
+			Recoverable: isTransientSubmissionError(err),
+			OccurredAt:  occurredAt,
+		}}
+	}
+
+	submissionErrors := make([]models.SubmissionError, 0, len(apiErr.Errors))
+	for _, mcErr := range apiErr.Errors {
+		var details map[string]string
+		if len(mcErr.Details) > 0 {
+			details = make(map[string]string, len(mcErr.Details))
+			for _, detail := range mcErr.Details {
+				details[detail.Name] = detail.Value
+			}
+		}
+
+		submissionErrors = append(submissionErrors, models.SubmissionError{
+			Source:      mcErr.Source,
+			ReasonCode:  mcErr.ReasonCode,
+			Description: mcErr.Description,
+			Recoverable: mcErr.Recoverable,
+			Details:     details,
+			OccurredAt:  occurredAt,
+		})
+	}
+	return submissionErrors
+}
+
+// submissionDocuments returns the case documents to file, oldest first. Rejected documents
+// are left out. When the case has an evidence bundle, only the newest bundle is filed. Each
+// document's content is verified against its checksum.
+func (w *SubmissionWorker) submissionDocuments(caseID string) ([]*models.Document, error) {
+	documents, err := w.documentService.GetDocumentsByCaseID(caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var submittable, bundles []*models.Document
+	for _, document := range documents {
+		if document.ProcessingStatus == models.DocumentStatusRejected {
+			continue
+		}
+		if len(document.DerivedFrom) > 0 {
+			bundles = append(bundles, document)
+		} else {
+			submittable = append(submittable, document)
+		}
+	}
+	if len(bundles) > 0 {
+		submittable = bundles
+	}
+
+	sort.Slice(submittable, func(i, j int) bool {
+		return submittable[i].UploadedAt.Before(submittable[j].UploadedAt)
+	})
+	if len(bundles) > 0 {
+		submittable = submittable[len(submittable)-1:]
+	}
+
+	// Content altered since upload is never filed
+	for _, document := range submittable {
+		if err := w.documentService.VerifyContent(document); err != nil {
+			return nil, err
+		}
+	}
+	return submittable, nil
+}
+
+// markDelivered advances a filed document through any remaining processing statuses to DELIVERED
+func (w *SubmissionWorker) markDelivered(document *models.Document) {
+	for _, status := range []string{models.DocumentStatusValidated, models.DocumentStatusConverted, models.DocumentStatusDelivered} {
+		current, err := w.documentService.GetDocument(document.ID)
+		if err != nil {
+			return
+		}
+		if !documentStatusBefore(current.ProcessingStatus, status) {
+			continue
+		}
+		if _, err := w.documentService.UpdateDocumentStatus(document.ID, status, "Filed with Mastercom"); err != nil {
+			w.logger.Error("Failed to update document status", logrus.Fields{
+				"documentId": document.ID,
+				"status":     status,
+				"error":      err.Error(),
+			})
+			return
+		}
+	}
+}
+
+// documentStatusBefore reports whether current precedes target in the delivery pipeline
+func documentStatusBefore(current, target string) bool {
+	order := map[string]int{
+		"":                             0,
+		models.DocumentStatusReceived:  0,
+		models.DocumentStatusValidated: 1,
+		models.DocumentStatusConverted: 2,
+		models.DocumentStatusDelivered: 3,
+	}
+	currentRank, ok := order[current]
+	if !ok {
+		return false
+	}
+	return currentRank < order[target]
+}
+
+// isTransientSubmissionError reports whether a failed submission may succeed if retried
+// unchanged: network failures, throttling and Mastercom server errors
+func isTransientSubmissionError(err error) bool {
+	if apiErr, ok := mastercom.AsAPIError(err); ok {
+		return apiErr.Temporary()
+	}
+	// Content altered since upload, or a card number missing from the vault, will not be fixed by retrying
+	if errors.Is(err, ErrDocumentIntegrity) || errors.Is(err, pan.ErrTokenNotFound) {
+		return false
+	}
+	_, invalid := err.(*caseFilingRequestError)
+	return !invalid
+}
+
+// caseFilingRequestError is returned when a case cannot be turned into a filing request
+type caseFilingRequestError struct {
+	reason string
+}
+
+func (e *caseFilingRequestError) Error() string {
+	return "invalid case filing: " + e.reason
+}
+
+// newCaseFilingRequest maps a case, its full card number and its documents onto a Mastercom
+// case filing request
+func newCaseFilingRequest(caseObj *models.Case, accountNumber string, documents []*models.Document) (*mastercom.CreateCaseRequest, error) {
+	filingAs, err := mastercomFilingAs(caseObj.FilingAs)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := caseObj.DisputeAmount
+	if amount == 0 {
+		amount = caseObj.TransactionAmount
+	}
+	currency := caseObj.DisputeCurrency
+	if currency == "" {
+		currency = caseObj.TransactionCurrency
+	}
+
+	memo := caseObj.ReasonDescription
+	if memo == "" {
+		memo = fmt.Sprintf("Reason code %s", caseObj.ReasonCode)
+	}
+
+	request := &mastercom.CreateCaseRequest{
+		CaseType:             caseObj.CaseType,
+		CustomerFilingNumber: caseObj.ID,
+		DisputeAmount:        fmt.Sprintf("%.2f", amount),
+		CurrencyCode:         currency,
+		FiledAgainstICA:      caseObj.FiledAgainstIca,
+		FilingAs:             filingAs,
+		FilingICA:            caseObj.FilingIca,
+		Memo:                 memo,
+		PrimaryAccountNum:    accountNumber,
+		ChargebackReasonCode: caseObj.ReasonCode,
+		MerchantName:         caseObj.MerchantName,
+	}
+
+	attachment, err := bundleDocuments(caseObj.ID, documents)
+	if err != nil {
+		return nil, err
+	}
+	request.FileAttachment = attachment
+
+	return request, nil
+}
+
+// mastercomFilingAs maps the local filing party onto Mastercom's I/A codes
+func mastercomFilingAs(filingAs string) (string, error) {
+	switch filingAs {
+	case "I", "ISSUER":
+		return "I", nil
+	case "A", "ACQUIRER":
+		return "A", nil
+	default:
+		return "", &caseFilingRequestError{reason: fmt.Sprintf("unknown filingAs %q", filingAs)}
+	}
+}
+
+// bundleDocuments returns the file attachment for a filing: a single document as is, or
+// several documents zipped together. Cases without documents are filed without an attachment.
+func bundleDocuments(caseID string, documents []*models.Document) (*mastercom.DocumentStructure, error) {
+	switch len(documents) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &mastercom.DocumentStructure{
+			Filename: documents[0].FileName,
+			File:     base64.StdEncoding.EncodeToString(documents[0].Content),
+		}, nil
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	names := make(map[string]int)
+	for _, document := range documents {
+		name := document.FileName
+		if count := names[name]; count > 0 {
+			name = fmt.Sprintf("%d_%s", count, name)
+		}
+		names[document.FileName]++
+
+		entry, err := archive.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("bundle documents: %w", err)
+		}
+		if _, err := entry.Write(document.Content); err != nil {
+			return nil, fmt.Errorf("bundle documents: %w", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("bundle documents: %w", err)
+	}
+
+	return &mastercom.DocumentStructure{
+		Filename: caseID + ".zip",
+		File:     base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
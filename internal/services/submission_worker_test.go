@@ -0,0 +1,337 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCaseFilingClient returns queued results in order and records the requests it receives
+type fakeCaseFilingClient struct {
+	results  []error
+	requests []*mastercom.CreateCaseRequest
+}
+
+func (f *fakeCaseFilingClient) CreateCaseFiling(ctx context.Context, body *mastercom.CreateCaseRequest) (*mastercom.CaseFilingResponse, error) {
+	f.requests = append(f.requests, body)
+	if len(f.results) > 0 {
+		err := f.results[0]
+		f.results = f.results[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &mastercom.CaseFilingResponse{CaseID: "MC-1001"}, nil
+}
+
+func setupSubmissionWorker(client CaseFilingClient) (*SubmissionWorker, *CaseService, *DocumentService) {
+	logger := logger.NewDatadogLogger()
+	caseService := NewCaseService(logger)
+	documentService := NewDocumentService(logger)
+	config := &models.SubmissionConfig{Enabled: true, Interval: 1, MaxAttempts: 3, BackoffBase: 30, BackoffMax: 90}
+	return NewSubmissionWorker(caseService, documentService, client, config, logger), caseService, documentService
+}
+
+// createSubmittedCase stores the mock case and moves it to the Submitted queue
+func createSubmittedCase(t *testing.T, caseService *CaseService) *models.Case {
+	caseObj := createMockCase()
+	caseObj.QueueName = models.QueueWorked
+	require.NoError(t, caseService.CreateCase(caseObj))
+	_, err := caseService.TransitionCase(caseObj.ID, models.QueueActionSubmit)
+	require.NoError(t, err)
+	return caseObj
+}
+
+func TestSubmissionWorker_SubmitsReadyCases(t *testing.T) {
+	client := &fakeCaseFilingClient{}
+	worker, caseService, documentService := setupSubmissionWorker(client)
+	caseObj := createSubmittedCase(t, caseService)
+
+	first := models.NewDocument(caseObj.ID, "evidence.pdf", ".pdf", []byte("first"), "test-user", "")
+	second := models.NewDocument(caseObj.ID, "evidence.pdf", ".pdf", []byte("second"), "test-user", "")
+	second.UploadedAt = first.UploadedAt.Add(time.Second)
+	require.NoError(t, documentService.UploadDocument(first))
+	require.NoError(t, documentService.UploadDocument(second))
+
+	// A case still being worked is not picked up
+	unworked := createMockCase()
+	unworked.ID = "unworked-case-id"
+	unworked.QueueName = models.QueueWorked
+	require.NoError(t, caseService.CreateCase(unworked))
+
+	assert.Equal(t, 1, worker.ProcessOnce(context.Background()))
+	require.Len(t, client.requests, 1)
+
+	request := client.requests[0]
+	assert.Equal(t, "PRE_ARBITRATION", request.CaseType)
+	assert.Equal(t, "I", request.FilingAs)
+	assert.Equal(t, "123456", request.FilingICA)
+	assert.Equal(t, "654321", request.FiledAgainstICA)
+	assert.Equal(t, "100.00", request.DisputeAmount)
+	assert.Equal(t, "USD", request.CurrencyCode)
+	assert.Equal(t, caseObj.ID, request.CustomerFilingNumber)
+	// The filing carries the full card number from the vault
+	assert.Equal(t, "4111111111111111", request.PrimaryAccountNum)
+
+	// Both documents are zipped into one attachment, with the duplicate name made unique
+	require.NotNil(t, request.FileAttachment)
+	assert.Equal(t, caseObj.ID+".zip", request.FileAttachment.Filename)
+	data, err := base64.StdEncoding.DecodeString(request.FileAttachment.File)
+	require.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+	assert.Equal(t, "evidence.pdf", archive.File[0].Name)
+	assert.Equal(t, "1_evidence.pdf", archive.File[1].Name)
+
+	submitted, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "MC-1001", submitted.MastercomCaseID)
+	assert.Equal(t, models.SubmissionStatusSubmitted, submitted.SubmissionStatus)
+	assert.Equal(t, models.CaseStatusSubmitted, submitted.Status)
+	assert.Equal(t, 1, submitted.SubmissionAttempts)
+	assert.NotNil(t, submitted.SubmittedAt)
+
+	for _, document := range []*models.Document{first, second} {
+		stored, err := documentService.GetDocument(document.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DocumentStatusDelivered, stored.ProcessingStatus)
+	}
+
+	// Submitted cases are not filed again
+	assert.Equal(t, 0, worker.ProcessOnce(context.Background()))
+}
+
+func TestSubmissionWorker_SingleDocumentAttachedDirectly(t *testing.T) {
+	client := &fakeCaseFilingClient{}
+	worker, caseService, documentService := setupSubmissionWorker(client)
+	caseObj := createSubmittedCase(t, caseService)
+
+	document := models.NewDocument(caseObj.ID, "evidence.pdf", ".pdf", []byte("content"), "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+
+	worker.ProcessOnce(context.Background())
+	require.Len(t, client.requests, 1)
+	require.NotNil(t, client.requests[0].FileAttachment)
+	assert.Equal(t, "evidence.pdf", client.requests[0].FileAttachment.Filename)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("content")), client.requests[0].FileAttachment.File)
+}
+
+func TestSubmissionWorker_FilesNewestEvidenceBundle(t *testing.T) {
+	client := &fakeCaseFilingClient{}
+	worker, caseService, documentService := setupSubmissionWorker(client)
+	caseObj := createSubmittedCase(t, caseService)
+
+	source := models.NewDocument(caseObj.ID, "evidence.pdf", ".pdf", []byte("source"), "test-user", "")
+	older := models.NewDocument(caseObj.ID, "bundle-1.pdf", ".pdf", []byte("older"), "test-user", "")
+	older.DerivedFrom = []string{source.ID}
+	older.UploadedAt = source.UploadedAt.Add(time.Second)
+	newer := models.NewDocument(caseObj.ID, "bundle-2.pdf", ".pdf", []byte("newer"), "test-user", "")
+	newer.DerivedFrom = []string{source.ID}
+	newer.UploadedAt = source.UploadedAt.Add(2 * time.Second)
+	for _, document := range []*models.Document{source, older, newer} {
+		require.NoError(t, documentService.UploadDocument(document))
+	}
+
+	worker.ProcessOnce(context.Background())
+	require.Len(t, client.requests, 1)
+	require.NotNil(t, client.requests[0].FileAttachment)
+	assert.Equal(t, "bundle-2.pdf", client.requests[0].FileAttachment.Filename)
+
+	// The bundle's sources are delivered with it; the superseded bundle is not
+	for document, status := range map[*models.Document]string{
+		source: models.DocumentStatusDelivered,
+		newer:  models.DocumentStatusDelivered,
+		older:  models.DocumentStatusReceived,
+	} {
+		stored, err := documentService.GetDocument(document.ID)
+		require.NoError(t, err)
+		assert.Equal(t, status, stored.ProcessingStatus, document.FileName)
+	}
+}
+
+func TestSubmissionWorker_WaitsForQuarantinedDocuments(t *testing.T) {
+	client := &fakeCaseFilingClient{}
+	worker, caseService, documentService := setupSubmissionWorker(client)
+	scanner := &fakeScanner{err: errors.New("clamd: connection refused")}
+	documentService.EnableScanning(scanner, &models.ScanConfig{MaxAttempts: 5})
+	caseObj := createSubmittedCase(t, caseService)
+
+	document := models.NewDocument(caseObj.ID, "evidence.pdf", ".pdf", []byte("content"), "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+
+	// Deferred without using up an attempt
+	assert.Equal(t, 0, worker.ProcessOnce(context.Background()))
+	assert.Empty(t, client.requests)
+	waiting, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, waiting.SubmissionAttempts)
+
+	scanner.setErr(nil)
+	documentService.RescanQuarantined(context.Background())
+	assert.Equal(t, 1, worker.ProcessOnce(context.Background()))
+	assert.Len(t, client.requests, 1)
+}
+
+func TestSubmissionWorker_RetriesTransientFailures(t *testing.T) {
+	client := &fakeCaseFilingClient{results: []error{
+		&mastercom.APIError{StatusCode: http.StatusServiceUnavailable},
+		errors.New("connection reset by peer"),
+		&mastercom.APIError{StatusCode: http.StatusTooManyRequests},
+	}}
+	worker, caseService, _ := setupSubmissionWorker(client)
+	caseObj := createSubmittedCase(t, caseService)
+
+	now := time.Now()
+	worker.now = func() time.Time { return now }
+
+	// First failure schedules a retry after the base delay
+	worker.ProcessOnce(context.Background())
+	retrying, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubmissionStatusPending, retrying.SubmissionStatus)
+	assert.Equal(t, 1, retrying.SubmissionAttempts)
+	require.NotNil(t, retrying.NextSubmissionAt)
+	assert.Equal(t, now.Add(30*time.Second), *retrying.NextSubmissionAt)
+	assert.Equal(t, models.QueueSubmitted, retrying.QueueName)
+
+	// Not due yet
+	assert.Equal(t, 0, worker.ProcessOnce(context.Background()))
+
+	// Second failure doubles the delay
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, 1, worker.ProcessOnce(context.Background()))
+	retrying, _ = caseService.GetCase(caseObj.ID)
+	assert.Equal(t, 2, retrying.SubmissionAttempts)
+	assert.Equal(t, now.Add(60*time.Second), *retrying.NextSubmissionAt)
+	require.Len(t, retrying.SubmissionErrors, 1)
+	assert.Equal(t, "connection reset by peer", retrying.SubmissionErrors[0].Description)
+
+	// The last attempt gives up and surfaces the case in Rejects
+	now = now.Add(60 * time.Second)
+	worker.ProcessOnce(context.Background())
+	failed, _ := caseService.GetCase(caseObj.ID)
+	assert.Equal(t, models.SubmissionStatusFailed, failed.SubmissionStatus)
+	assert.Equal(t, 3, failed.SubmissionAttempts)
+	assert.Nil(t, failed.NextSubmissionAt)
+	assert.Equal(t, models.QueueRejects, failed.QueueName)
+	assert.Len(t, client.requests, 3)
+}
+
+func TestSubmissionWorker_PermanentRejection(t *testing.T) {
+	client := &fakeCaseFilingClient{results: []error{
+		&mastercom.APIError{StatusCode: http.StatusBadRequest, Errors: []mastercom.Error{{
+			Source:      "disputeAmount",
+			ReasonCode:  "INVALID_VALUE",
+			Description: "Dispute amount exceeds the transaction amount",
+			Recoverable: true,
+			Details:     []mastercom.ErrorDetail{{Name: "ErrorDetailCode", Value: "050007"}},
+		}}},
+	}}
+	worker, caseService, documentService := setupSubmissionWorker(client)
+	caseObj := createSubmittedCase(t, caseService)
+
+	document := models.NewDocument(caseObj.ID, "evidence.pdf", ".pdf", []byte("content"), "test-user", "")
+	require.NoError(t, documentService.UploadDocument(document))
+
+	worker.ProcessOnce(context.Background())
+
+	rejected, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubmissionStatusRejected, rejected.SubmissionStatus)
+	assert.Equal(t, models.CaseStatusRejected, rejected.Status)
+	assert.Equal(t, models.QueueRejects, rejected.QueueName)
+	assert.Empty(t, rejected.MastercomCaseID)
+	assert.Nil(t, rejected.NextSubmissionAt)
+
+	require.Len(t, rejected.SubmissionErrors, 1)
+	submissionErr := rejected.SubmissionErrors[0]
+	assert.Equal(t, "disputeAmount", submissionErr.Source)
+	assert.Equal(t, "INVALID_VALUE", submissionErr.ReasonCode)
+	assert.Equal(t, "Dispute amount exceeds the transaction amount", submissionErr.Description)
+	assert.True(t, submissionErr.Recoverable)
+	assert.Equal(t, map[string]string{"ErrorDetailCode": "050007"}, submissionErr.Details)
+
+	// Documents are not marked delivered
+	stored, _ := documentService.GetDocument(document.ID)
+	assert.Equal(t, models.DocumentStatusReceived, stored.ProcessingStatus)
+
+	// Not retried until an analyst corrects and resubmits the case
+	assert.Equal(t, 0, worker.ProcessOnce(context.Background()))
+
+	_, err = caseService.TransitionCase(caseObj.ID, models.QueueActionAcknowledge)
+	require.NoError(t, err)
+	resubmitted, err := caseService.TransitionCase(caseObj.ID, models.QueueActionSubmit)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubmissionStatusPending, resubmitted.SubmissionStatus)
+	assert.Empty(t, resubmitted.SubmissionErrors)
+
+	assert.Equal(t, 1, worker.ProcessOnce(context.Background()))
+	submitted, _ := caseService.GetCase(caseObj.ID)
+	assert.Equal(t, "MC-1001", submitted.MastercomCaseID)
+}
+
+func TestSubmissionWorker_InvalidCaseRejectedWithoutCallingMastercom(t *testing.T) {
+	client := &fakeCaseFilingClient{}
+	worker, caseService, _ := setupSubmissionWorker(client)
+
+	caseObj := createMockCase()
+	caseObj.FilingAs = "MERCHANT"
+	caseObj.QueueName = models.QueueSubmitted
+	require.NoError(t, caseService.CreateCase(caseObj))
+
+	worker.ProcessOnce(context.Background())
+	assert.Empty(t, client.requests)
+
+	rejected, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubmissionStatusRejected, rejected.SubmissionStatus)
+	require.Len(t, rejected.SubmissionErrors, 1)
+	assert.Contains(t, rejected.SubmissionErrors[0].Description, "MERCHANT")
+}
+
+func TestSubmissionWorker_Backoff(t *testing.T) {
+	worker, _, _ := setupSubmissionWorker(&fakeCaseFilingClient{})
+
+	assert.Equal(t, 30*time.Second, worker.backoff(1))
+	assert.Equal(t, 60*time.Second, worker.backoff(2))
+	assert.Equal(t, 90*time.Second, worker.backoff(3))
+	assert.Equal(t, 90*time.Second, worker.backoff(10))
+}
+
+func TestSubmissionWorker_RunStopsOnCancel(t *testing.T) {
+	client := &fakeCaseFilingClient{}
+	worker, caseService, _ := setupSubmissionWorker(client)
+	caseObj := createSubmittedCase(t, caseService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		submitted, err := caseService.GetCase(caseObj.ID)
+		return err == nil && submitted.SubmissionStatus == models.SubmissionStatusSubmitted
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop after cancellation")
+	}
+}
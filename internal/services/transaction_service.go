@@ -0,0 +1,286 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInvalidTransactionSearch is returned when a search request violates the search rules
+var ErrInvalidTransactionSearch = errors.New("invalid transaction search")
+
+const (
+	searchDateLayout  = "2006-01-02"
+	banknetDateLayout = "060102"
+)
+
+// TransactionService searches original transactions held by a TransactionSource
+type TransactionService struct {
+	source TransactionSource
+	config *models.TransactionSourceConfig
+	logger *logger.DatadogLogger
+	now    func() time.Time
+}
+
+// NewTransactionService creates a new transaction service instance
+func NewTransactionService(source TransactionSource, config *models.TransactionSourceConfig, logger *logger.DatadogLogger) *TransactionService {
+	return &TransactionService{
+		source: source,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// transactionSearch holds a validated and parsed search request
+type transactionSearch struct {
+	*models.TransactionSearchRequest
+	startDate  time.Time
+	endDate    time.Time
+	amountFrom int64
+	amountTo   int64
+}
+
+// SearchTransactions finds authorizations by PAN and authorization date range, or by
+// ARN and clearing date range for late presentments, among the transactions of scope
+func (s *TransactionService) SearchTransactions(req *models.TransactionSearchRequest, scope *models.ICAScope) (*models.TransactionSummary, error) {
+	search, err := s.validateSearch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.source.Records()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []models.AuthorizationSummary{}
+	for i := range records {
+		if !scope.PermitsTransaction(&records[i]) {
+			continue
+		}
+		if summary, ok := search.match(&records[i]); ok {
+			summaries = append(summaries, summary)
+		}
+	}
+
+	s.logger.Info("Transaction search completed", logrus.Fields{
+		"resultCount": len(summaries),
+		"byArn":       req.AcquirerRefNumber != "",
+		"byPan":       req.PrimaryAccountNum != "",
+	})
+
+	return &models.TransactionSummary{
+		AuthorizationSummaryCount: strconv.Itoa(len(summaries)),
+		Message:                   fmt.Sprintf("Search returned %d records", len(summaries)),
+		AuthorizationSummary:      summaries,
+	}, nil
+}
+
+// GetClearingDetail returns the clearing details for a transaction
+func (s *TransactionService) GetClearingDetail(transactionID string) (*models.ClearingDetail, error) {
+	return s.source.GetClearing(transactionID)
+}
+
+// GetAuthorizationDetail returns the authorization details for a transaction
+func (s *TransactionService) GetAuthorizationDetail(transactionID string) (*models.AuthorizationDetail, error) {
+	return s.source.GetAuthorization(transactionID)
+}
+
+// CheckTransactionScope returns ErrTransactionNotFound for a transaction that no record holds
+// or that belongs to no ICA of scope
+func (s *TransactionService) CheckTransactionScope(transactionID string, scope *models.ICAScope) error {
+	records, err := s.source.Records()
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		if records[i].HasTransaction(transactionID) && scope.PermitsTransaction(&records[i]) {
+			return nil
+		}
+	}
+	return ErrTransactionNotFound
+}
+
+// ClaimHasTransaction reports whether a transaction belongs to a claim: it is the claim's
+// transaction, or the authorization or a clearing record of the same original transaction
+func (s *TransactionService) ClaimHasTransaction(claim *models.Claim, transactionID string) (bool, error) {
+	if claim.TransactionID == "" {
+		return false, nil
+	}
+	if claim.TransactionID == transactionID {
+		return true, nil
+	}
+
+	records, err := s.source.Records()
+	if err != nil {
+		return false, err
+	}
+	for i := range records {
+		if records[i].HasTransaction(claim.TransactionID) {
+			return records[i].HasTransaction(transactionID), nil
+		}
+	}
+	return false, nil
+}
+
+// validateSearch applies the Mastercom transaction search rules to a request
+func (s *TransactionService) validateSearch(req *models.TransactionSearchRequest) (*transactionSearch, error) {
+	if req.PrimaryAccountNum == "" && req.AcquirerRefNumber == "" {
+		return nil, fmt.Errorf("%w: primaryAccountNum or acquirerRefNumber is required", ErrInvalidTransactionSearch)
+	}
+	if req.BankNetRefNumber != "" && req.AcquirerRefNumber != "" {
+		return nil, fmt.Errorf("%w: bankNetRefNumber cannot be combined with acquirerRefNumber", ErrInvalidTransactionSearch)
+	}
+	if req.BankNetRefNumber != "" && req.PrimaryAccountNum == "" {
+		return nil, fmt.Errorf("%w: bankNetRefNumber requires primaryAccountNum", ErrInvalidTransactionSearch)
+	}
+
+	startDate, err := time.Parse(searchDateLayout, req.TranStartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tranStartDate must be yyyy-MM-dd", ErrInvalidTransactionSearch)
+	}
+	endDate, err := time.Parse(searchDateLayout, req.TranEndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tranEndDate must be yyyy-MM-dd", ErrInvalidTransactionSearch)
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("%w: tranEndDate is before tranStartDate", ErrInvalidTransactionSearch)
+	}
+	if endDate.Sub(startDate) > time.Duration(s.config.MaxSearchRangeDays)*24*time.Hour {
+		return nil, fmt.Errorf("%w: search range exceeds %d days", ErrInvalidTransactionSearch, s.config.MaxSearchRangeDays)
+	}
+	if startDate.Before(s.now().AddDate(0, 0, -s.config.MaxHistoryDays)) {
+		return nil, fmt.Errorf("%w: search history is limited to %d days", ErrInvalidTransactionSearch, s.config.MaxHistoryDays)
+	}
+
+	search := &transactionSearch{
+		TransactionSearchRequest: req,
+		startDate:                startDate,
+		endDate:                  endDate,
+		amountFrom:               -1,
+		amountTo:                 -1,
+	}
+	if req.TransAmountFrom != "" {
+		if search.amountFrom, err = strconv.ParseInt(req.TransAmountFrom, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: transAmountFrom must be numeric", ErrInvalidTransactionSearch)
+		}
+	}
+	if req.TransAmountTo != "" {
+		if search.amountTo, err = strconv.ParseInt(req.TransAmountTo, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: transAmountTo must be numeric", ErrInvalidTransactionSearch)
+		}
+	}
+	if search.amountFrom >= 0 && search.amountTo >= 0 && search.amountFrom > search.amountTo {
+		return nil, fmt.Errorf("%w: transAmountFrom exceeds transAmountTo", ErrInvalidTransactionSearch)
+	}
+
+	return search, nil
+}
+
+// match returns the authorization summary for a record if it satisfies the search
+func (q *transactionSearch) match(record *models.TransactionRecord) (models.AuthorizationSummary, bool) {
+	auth := &record.Authorization
+
+	if q.PrimaryAccountNum != "" && auth.PrimaryAccountNumber != q.PrimaryAccountNum {
+		return models.AuthorizationSummary{}, false
+	}
+	if q.BankNetRefNumber != "" && auth.FinancialNetworkCode+auth.BanknetReferenceNumber != q.BankNetRefNumber {
+		return models.AuthorizationSummary{}, false
+	}
+	if !q.amountInRange(auth.TransactionAmountLocal) {
+		return models.AuthorizationSummary{}, false
+	}
+
+	var clearings []models.ClearingDetail
+	if q.AcquirerRefNumber != "" {
+		// Late presentments are located by the ARN and clearing date of the first presentment
+		for _, clearing := range record.Clearings {
+			if clearing.AcquirerReferenceData == q.AcquirerRefNumber && q.dateInRange(clearing.CentralSiteBusinessDate) {
+				clearings = append(clearings, clearing)
+			}
+		}
+		if len(clearings) == 0 {
+			return models.AuthorizationSummary{}, false
+		}
+	} else {
+		if !q.dateInRange(auth.BanknetDate) {
+			return models.AuthorizationSummary{}, false
+		}
+		clearings = record.Clearings
+	}
+
+	return newAuthorizationSummary(auth, clearings), true
+}
+
+func (q *transactionSearch) dateInRange(value string) bool {
+	date, err := time.Parse(banknetDateLayout, value)
+	if err != nil {
+		return false
+	}
+	return !date.Before(q.startDate) && !date.After(q.endDate)
+}
+
+func (q *transactionSearch) amountInRange(value string) bool {
+	if q.amountFrom < 0 && q.amountTo < 0 {
+		return true
+	}
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	if q.amountFrom >= 0 && amount < q.amountFrom {
+		return false
+	}
+	if q.amountTo >= 0 && amount > q.amountTo {
+		return false
+	}
+	return true
+}
+
+func newAuthorizationSummary(auth *models.AuthorizationDetail, clearings []models.ClearingDetail) models.AuthorizationSummary {
+	clearingSummaries := make([]models.ClearingSummary, 0, len(clearings))
+	for _, clearing := range clearings {
+		clearingSummaries = append(clearingSummaries, models.ClearingSummary{
+			PrimaryAccountNumber:               clearing.PrimaryAccountNumber,
+			TransactionAmountLocal:             clearing.TransactionAmountLocal,
+			DateAndTimeLocal:                   clearing.LocalTransactionDateTime,
+			CardDataInputCapability:            clearing.CardDataInputCapability,
+			CardholderAuthenticationCapability: clearing.CardholderAuthenticationCapability,
+			CardPresent:                        clearing.CardPresentData,
+			AcquirerReferenceNumber:            clearing.AcquirerReferenceData,
+			CardAcceptorName:                   clearing.CardAcceptorName,
+			CurrencyCode:                       clearing.TransactionCurrencyCode,
+			InstallmentPaymentDataBrazil:       clearing.InstallmentPaymentDataBrazil,
+			TransactionID:                      clearing.TransactionID,
+			SettlementIndicator:                clearing.SettlementIndicator,
+			MessageReversalIndicator:           clearing.MessageReversalIndicator,
+		})
+	}
+
+	return models.AuthorizationSummary{
+		OriginalMessageTypeIdentifier: auth.OriginalMessageTypeIdentifier,
+		BanknetDate:                   auth.BanknetDate,
+		TransactionAmountUsd:          auth.TransactionAmountUsd,
+		PrimaryAccountNumber:          auth.PrimaryAccountNumber,
+		ProcessingCode:                auth.ProcessingCode,
+		TransactionAmountLocal:        auth.TransactionAmountLocal,
+		AuthorizationDateAndTime:      auth.TransmissionDateAndTime,
+		AuthenticationID:              auth.AuthorizationIDResponse,
+		CardAcceptorName:              auth.CardAcceptorName,
+		CardAcceptorCity:              auth.CardAcceptorCity,
+		CardAcceptorState:             auth.CardAcceptorState,
+		CurrencyCode:                  auth.TransactionCurrencyCode,
+		ChipPresent:                   auth.ChipPresent,
+		TransactionID:                 auth.TransactionID,
+		Track1:                        auth.Track1,
+		Track2:                        auth.Track2,
+		ClearingSummary:               clearingSummaries,
+	}
+}
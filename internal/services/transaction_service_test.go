@@ -0,0 +1,219 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createMockTransactionRecords() []models.TransactionRecord {
+	return []models.TransactionRecord{
+		{
+			Authorization: models.AuthorizationDetail{
+				TransactionID:           "auth-1",
+				PrimaryAccountNumber:    "5488888888887192",
+				BanknetDate:             "240620",
+				BanknetReferenceNumber:  "U68FRG",
+				FinancialNetworkCode:    "MPL",
+				CardAcceptorName:        "Amazon",
+				TransactionAmountLocal:  "000000010000",
+				TransactionCurrencyCode: "840",
+			},
+			Clearings: []models.ClearingDetail{
+				{
+					TransactionID:            "clearing-1",
+					PrimaryAccountNumber:     "5488888888887192",
+					AcquirerReferenceData:    "05413364365000000000667",
+					CardAcceptorName:         "Amazon",
+					CardAcceptorBusinessCode: "5411",
+					CentralSiteBusinessDate:  "240621",
+					LocalTransactionDateTime: "240620160100",
+					TransactionAmountLocal:   "10000",
+					TransactionCurrencyCode:  "840",
+				},
+			},
+			ICAs: []string{"123456", "654321"},
+		},
+		{
+			Authorization: models.AuthorizationDetail{
+				TransactionID:           "auth-2",
+				PrimaryAccountNumber:    "5488888888887192",
+				BanknetDate:             "240301",
+				TransactionAmountLocal:  "000000002500",
+				TransactionCurrencyCode: "840",
+			},
+			Clearings: []models.ClearingDetail{
+				{
+					TransactionID:           "clearing-2",
+					PrimaryAccountNumber:    "5488888888887192",
+					AcquirerReferenceData:   "05436847276000293995738",
+					CentralSiteBusinessDate: "240715",
+					TransactionAmountLocal:  "2500",
+					TransactionCurrencyCode: "840",
+				},
+			},
+		},
+	}
+}
+
+func setupTransactionService() *TransactionService {
+	config := &models.TransactionSourceConfig{MaxSearchRangeDays: 30, MaxHistoryDays: 730}
+	service := NewTransactionService(NewLocalTransactionSource(createMockTransactionRecords()), config, logger.NewDatadogLogger())
+	service.now = func() time.Time { return time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC) }
+	return service
+}
+
+func TestTransactionService_SearchByPan(t *testing.T) {
+	service := setupTransactionService()
+
+	summary, err := service.SearchTransactions(&models.TransactionSearchRequest{
+		PrimaryAccountNum: "5488888888887192",
+		TranStartDate:     "2024-06-17",
+		TranEndDate:       "2024-07-17",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "1", summary.AuthorizationSummaryCount)
+	require.Len(t, summary.AuthorizationSummary, 1)
+	assert.Equal(t, "auth-1", summary.AuthorizationSummary[0].TransactionID)
+	require.Len(t, summary.AuthorizationSummary[0].ClearingSummary, 1)
+	assert.Equal(t, "clearing-1", summary.AuthorizationSummary[0].ClearingSummary[0].TransactionID)
+}
+
+func TestTransactionService_SearchByArnAndClearingDate(t *testing.T) {
+	service := setupTransactionService()
+
+	// The authorization is outside the range but the late presentment clearing is inside it
+	summary, err := service.SearchTransactions(&models.TransactionSearchRequest{
+		AcquirerRefNumber: "05436847276000293995738",
+		TranStartDate:     "2024-07-01",
+		TranEndDate:       "2024-07-30",
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, summary.AuthorizationSummary, 1)
+	assert.Equal(t, "auth-2", summary.AuthorizationSummary[0].TransactionID)
+	assert.Equal(t, "05436847276000293995738", summary.AuthorizationSummary[0].ClearingSummary[0].AcquirerReferenceNumber)
+}
+
+func TestTransactionService_SearchByBanknetReference(t *testing.T) {
+	service := setupTransactionService()
+
+	summary, err := service.SearchTransactions(&models.TransactionSearchRequest{
+		PrimaryAccountNum: "5488888888887192",
+		BankNetRefNumber:  "MPLU68FRG",
+		TranStartDate:     "2024-06-01",
+		TranEndDate:       "2024-06-30",
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, summary.AuthorizationSummary, 1)
+	assert.Equal(t, "auth-1", summary.AuthorizationSummary[0].TransactionID)
+}
+
+func TestTransactionService_SearchAmountRange(t *testing.T) {
+	service := setupTransactionService()
+
+	summary, err := service.SearchTransactions(&models.TransactionSearchRequest{
+		PrimaryAccountNum: "5488888888887192",
+		TransAmountFrom:   "20000",
+		TranStartDate:     "2024-06-17",
+		TranEndDate:       "2024-07-17",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0", summary.AuthorizationSummaryCount)
+	assert.Empty(t, summary.AuthorizationSummary)
+}
+
+func TestTransactionService_SearchValidation(t *testing.T) {
+	service := setupTransactionService()
+
+	tests := []struct {
+		name string
+		req  models.TransactionSearchRequest
+	}{
+		{"missing pan and arn", models.TransactionSearchRequest{TranStartDate: "2024-07-01", TranEndDate: "2024-07-02"}},
+		{"bnr with arn", models.TransactionSearchRequest{PrimaryAccountNum: "5488888888887192", AcquirerRefNumber: "05436847276000293995738", BankNetRefNumber: "MPLU68FRG", TranStartDate: "2024-07-01", TranEndDate: "2024-07-02"}},
+		{"bnr without pan", models.TransactionSearchRequest{AcquirerRefNumber: "05436847276000293995738", BankNetRefNumber: "MPLU68FRG", TranStartDate: "2024-07-01", TranEndDate: "2024-07-02"}},
+		{"range too long", models.TransactionSearchRequest{PrimaryAccountNum: "5488888888887192", TranStartDate: "2024-06-01", TranEndDate: "2024-07-15"}},
+		{"end before start", models.TransactionSearchRequest{PrimaryAccountNum: "5488888888887192", TranStartDate: "2024-07-15", TranEndDate: "2024-07-01"}},
+		{"history too old", models.TransactionSearchRequest{PrimaryAccountNum: "5488888888887192", TranStartDate: "2021-01-01", TranEndDate: "2021-01-30"}},
+		{"bad date", models.TransactionSearchRequest{PrimaryAccountNum: "5488888888887192", TranStartDate: "2024/07/01", TranEndDate: "2024-07-02"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SearchTransactions(&tt.req, nil)
+			assert.ErrorIs(t, err, ErrInvalidTransactionSearch)
+		})
+	}
+}
+
+func TestTransactionService_Scope(t *testing.T) {
+	service := setupTransactionService()
+	search := &models.TransactionSearchRequest{
+		PrimaryAccountNum: "5488888888887192",
+		TranStartDate:     "2024-06-17",
+		TranEndDate:       "2024-07-17",
+	}
+
+	summary, err := service.SearchTransactions(search, &models.ICAScope{ICAs: []string{"654321"}})
+	require.NoError(t, err)
+	assert.Equal(t, "1", summary.AuthorizationSummaryCount)
+	summary, err = service.SearchTransactions(search, &models.ICAScope{ICAs: []string{"999999"}})
+	require.NoError(t, err)
+	assert.Equal(t, "0", summary.AuthorizationSummaryCount)
+
+	assert.NoError(t, service.CheckTransactionScope("clearing-1", &models.ICAScope{ICAs: []string{"123456"}}))
+	assert.ErrorIs(t, service.CheckTransactionScope("clearing-1", &models.ICAScope{ICAs: []string{"999999"}}), ErrTransactionNotFound)
+	// Transactions held without ICAs are only within an unrestricted scope
+	assert.ErrorIs(t, service.CheckTransactionScope("clearing-2", &models.ICAScope{ICAs: []string{"123456"}}), ErrTransactionNotFound)
+	assert.NoError(t, service.CheckTransactionScope("clearing-2", &models.ICAScope{ICAs: []string{models.AllICAs}}))
+	assert.ErrorIs(t, service.CheckTransactionScope("missing", nil), ErrTransactionNotFound)
+}
+
+func TestTransactionService_GetDetails(t *testing.T) {
+	service := setupTransactionService()
+
+	clearing, err := service.GetClearingDetail("clearing-1")
+	require.NoError(t, err)
+	assert.Equal(t, "05413364365000000000667", clearing.AcquirerReferenceData)
+
+	authorization, err := service.GetAuthorizationDetail("auth-1")
+	require.NoError(t, err)
+	assert.Equal(t, "U68FRG", authorization.BanknetReferenceNumber)
+
+	_, err = service.GetClearingDetail("auth-1")
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+	_, err = service.GetAuthorizationDetail("nonexistent-id")
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+}
+
+func TestLoadLocalTransactionSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transactions.json")
+	data, err := json.Marshal(createMockTransactionRecords())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	source, err := LoadLocalTransactionSource(path)
+	require.NoError(t, err)
+
+	records, err := source.Records()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	empty, err := LoadLocalTransactionSource("")
+	require.NoError(t, err)
+	records, err = empty.Records()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = LoadLocalTransactionSource(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
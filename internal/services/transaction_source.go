@@ -0,0 +1,93 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"mastercom-service/internal/models"
+)
+
+// ErrTransactionNotFound is returned when a transaction is not known to the source
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// TransactionSource provides access to original authorization and clearing records
+type TransactionSource interface {
+	// Records returns every transaction record held by the source
+	Records() ([]models.TransactionRecord, error)
+	// GetAuthorization returns the authorization with the given transaction ID
+	GetAuthorization(transactionID string) (*models.AuthorizationDetail, error)
+	// GetClearing returns the clearing record with the given transaction ID
+	GetClearing(transactionID string) (*models.ClearingDetail, error)
+}
+
+// LocalTransactionSource is an in-memory TransactionSource fed from a JSON file
+type LocalTransactionSource struct {
+	records []models.TransactionRecord
+	mutex   sync.RWMutex
+}
+
+// NewLocalTransactionSource creates a transaction source holding the given records
+func NewLocalTransactionSource(records []models.TransactionRecord) *LocalTransactionSource {
+	return &LocalTransactionSource{records: records}
+}
+
+// LoadLocalTransactionSource creates a transaction source from a JSON file containing
+// an array of transaction records. An empty path yields an empty source.
+func LoadLocalTransactionSource(path string) (*LocalTransactionSource, error) {
+	if path == "" {
+		return NewLocalTransactionSource(nil), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []models.TransactionRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	return NewLocalTransactionSource(records), nil
+}
+
+func (s *LocalTransactionSource) Records() ([]models.TransactionRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]models.TransactionRecord, len(s.records))
+	copy(records, s.records)
+	return records, nil
+}
+
+func (s *LocalTransactionSource) GetAuthorization(transactionID string) (*models.AuthorizationDetail, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for i := range s.records {
+		if s.records[i].Authorization.TransactionID == transactionID {
+			authorization := s.records[i].Authorization
+			return &authorization, nil
+		}
+	}
+
+	return nil, ErrTransactionNotFound
+}
+
+func (s *LocalTransactionSource) GetClearing(transactionID string) (*models.ClearingDetail, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for i := range s.records {
+		for j := range s.records[i].Clearings {
+			if s.records[i].Clearings[j].TransactionID == transactionID {
+				clearing := s.records[i].Clearings[j]
+				return &clearing, nil
+			}
+		}
+	}
+
+	return nil, ErrTransactionNotFound
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrUploadNotFound is returned for unknown or expired uploads
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrInvalidUpload is returned when an upload is created or finalized with bad parameters
+	ErrInvalidUpload = errors.New("invalid upload")
+	// ErrUploadTooLarge is returned when an upload would exceed its declared or allowed length
+	ErrUploadTooLarge = errors.New("upload too large")
+	// ErrUploadOffsetMismatch is returned when a chunk does not start where the upload left off
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+	// ErrUploadIncomplete is returned when finalizing an upload before all of it has arrived
+	ErrUploadIncomplete = errors.New("upload incomplete")
+	// ErrUploadChecksumMismatch is returned when received content does not match its checksum
+	ErrUploadChecksumMismatch = errors.New("upload checksum mismatch")
+	// ErrUploadFinalized is returned when changing an upload that is being or has been finalized
+	ErrUploadFinalized = errors.New("upload already finalized")
+)
+
+// uploadState is a stored upload and whether it is being finalized
+type uploadState struct {
+	upload     models.Upload
+	finalizing bool
+}
+
+// UploadService receives large documents in resumable chunks and stores each complete upload
+// as a document in the DocumentService
+type UploadService struct {
+	uploads         map[string]*uploadState
+	mutex           sync.Mutex
+	documentService *DocumentService
+	validator       *DocumentValidator
+	config          *models.UploadConfig
+	logger          *logger.DatadogLogger
+	now             func() time.Time
+}
+
+// NewUploadService creates a new upload service
+func NewUploadService(documentService *DocumentService, validator *DocumentValidator, config *models.UploadConfig, logger *logger.DatadogLogger) *UploadService {
+	return &UploadService{
+		uploads:         make(map[string]*uploadState),
+		documentService: documentService,
+		validator:       validator,
+		config:          config,
+		logger:          logger,
+		now:             time.Now,
+	}
+}
+
+// MaxSize returns the largest upload accepted, or 0 when there is no limit
+func (s *UploadService) MaxSize() int64 {
+	return s.validator.MaxSize()
+}
+
+// CreateUpload starts a resumable upload, assigning its ID and expiry
+func (s *UploadService) CreateUpload(upload *models.Upload) error {
+	if upload.CaseID == "" {
+		return fmt.Errorf("%w: caseId is required", ErrInvalidUpload)
+	}
+	if upload.FileName == "" {
+		return fmt.Errorf("%w: filename is required", ErrInvalidUpload)
+	}
+	if upload.Length <= 0 {
+		return fmt.Errorf("%w: length must be positive", ErrInvalidUpload)
+	}
+	if maxSize := s.MaxSize(); maxSize > 0 && upload.Length > maxSize {
+		return fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrUploadTooLarge, upload.Length, maxSize)
+	}
+
+	now := s.now()
+	upload.ID = uuid.New().String()
+	upload.Offset = 0
+	upload.Content = nil
+	upload.CreatedAt = now
+	upload.ExpiresAt = now.Add(s.expiry())
+
+	s.mutex.Lock()
+	s.uploads[upload.ID] = &uploadState{upload: *upload}
+	s.mutex.Unlock()
+
+	s.logger.Info("Upload created", logrus.Fields{
+		"uploadId": upload.ID,
+		"caseId":   upload.CaseID,
+		"filename": upload.FileName,
+		"length":   upload.Length,
+	})
+	return nil
+}
+
+// GetUpload returns an upload's progress, without its content
+func (s *UploadService) GetUpload(uploadID string) (*models.Upload, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, err := s.lookup(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	return snapshotUpload(state), nil
+}
+
+// AppendChunk adds data received at offset to an upload and returns its progress. The offset
+// must be where the upload left off.
+func (s *UploadService) AppendChunk(uploadID string, offset int64, data []byte) (*models.Upload, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, err := s.lookup(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	upload := &state.upload
+	if state.finalizing || upload.DocumentID != "" {
+		return nil, ErrUploadFinalized
+	}
+	if offset != upload.Offset {
+		return nil, fmt.Errorf("%w: upload is at offset %d, not %d", ErrUploadOffsetMismatch, upload.Offset, offset)
+	}
+	if offset+int64(len(data)) > upload.Length {
+		return nil, fmt.Errorf("%w: chunk ends past the %d byte length", ErrUploadTooLarge, upload.Length)
+	}
+
+	upload.Content = append(upload.Content, data...)
+	upload.Offset += int64(len(data))
+	upload.ExpiresAt = s.now().Add(s.expiry())
+	return snapshotUpload(state), nil
+}
+
+// FinalizeUpload checks a complete upload against its SHA-256 checksum, given as sha256:<hex>,
+// and stores it as a document. Finalizing again with the same checksum returns the same
+// document. An upload rejected by validation or the malware scan is discarded.
+func (s *UploadService) FinalizeUpload(uploadID, checksum string) (*models.Document, error) {
+	expected, err := parseSHA256Checksum(checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	state, err := s.lookup(uploadID)
+	if err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	upload := state.upload
+	switch {
+	case upload.DocumentID != "":
+		s.mutex.Unlock()
+		if !strings.EqualFold(upload.Checksum, checksum) {
+			return nil, ErrUploadChecksumMismatch
+		}
+		return s.documentService.GetDocument(upload.DocumentID)
+	case state.finalizing:
+		s.mutex.Unlock()
+		return nil, ErrUploadFinalized
+	case upload.Offset < upload.Length:
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %d of %d bytes received", ErrUploadIncomplete, upload.Offset, upload.Length)
+	}
+	if actual := sha256.Sum256(upload.Content); actual != expected {
+		s.mutex.Unlock()
+		return nil, ErrUploadChecksumMismatch
+	}
+	state.finalizing = true
+	s.mutex.Unlock()
+
+	// Validation and the malware scan run without holding up other uploads
+	fileType, err := s.validator.Validate(upload.FileName, upload.Content)
+	if err != nil {
+		s.discard(uploadID)
+		return nil, err
+	}
+
+	document := models.NewDocument(upload.CaseID, upload.FileName, fileType, upload.Content, upload.UploadedBy, upload.Description)
+	if err := s.documentService.UploadDocument(document); err != nil {
+		s.mutex.Lock()
+		state.finalizing = false
+		s.mutex.Unlock()
+		return nil, err
+	}
+
+	// Kept until it expires so that a client that lost the response can finalize again
+	s.mutex.Lock()
+	state.finalizing = false
+	state.upload.DocumentID = document.ID
+	state.upload.Checksum = checksum
+	state.upload.Content = nil
+	s.mutex.Unlock()
+
+	s.logger.Info("Upload finalized", logrus.Fields{
+		"uploadId":   uploadID,
+		"documentId": document.ID,
+		"caseId":     document.CaseID,
+		"fileSize":   document.FileSize,
+	})
+	return document, nil
+}
+
+// DeleteUpload abandons an upload
+func (s *UploadService) DeleteUpload(uploadID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, err := s.lookup(uploadID)
+	if err != nil {
+		return err
+	}
+	if state.finalizing {
+		return ErrUploadFinalized
+	}
+	delete(s.uploads, uploadID)
+	return nil
+}
+
+// ExpireUploads discards uploads that have not progressed within the expiry period and
+// returns how many were discarded
+func (s *UploadService) ExpireUploads() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	expired := 0
+	for id, state := range s.uploads {
+		if state.finalizing || now.Before(state.upload.ExpiresAt) {
+			continue
+		}
+		if state.upload.DocumentID == "" {
+			s.logger.Info("Abandoned upload discarded", logrus.Fields{
+				"uploadId": id,
+				"caseId":   state.upload.CaseID,
+				"offset":   state.upload.Offset,
+				"length":   state.upload.Length,
+			})
+		}
+		delete(s.uploads, id)
+		expired++
+	}
+	return expired
+}
+
+// lookup returns a live upload; the caller must hold the mutex
+func (s *UploadService) lookup(uploadID string) (*uploadState, error) {
+	state, exists := s.uploads[uploadID]
+	if !exists || (!state.finalizing && !s.now().Before(state.upload.ExpiresAt)) {
+		return nil, ErrUploadNotFound
+	}
+	return state, nil
+}
+
+func (s *UploadService) discard(uploadID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.uploads, uploadID)
+}
+
+func (s *UploadService) expiry() time.Duration {
+	if s.config.Expiry <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(s.config.Expiry) * time.Second
+}
+
+func snapshotUpload(state *uploadState) *models.Upload {
+	upload := state.upload
+	upload.Content = nil
+	return &upload
+}
+
+// parseSHA256Checksum reads a checksum given as sha256:<hex>
+func parseSHA256Checksum(checksum string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	algorithm, value, ok := strings.Cut(checksum, ":")
+	if !ok || !strings.EqualFold(algorithm, "sha256") {
+		return sum, fmt.Errorf("%w: checksum must be sha256:<hex>", ErrInvalidUpload)
+	}
+	decoded, err := hex.DecodeString(value)
+	if err != nil || len(decoded) != sha256.Size {
+		return sum, fmt.Errorf("%w: checksum must be sha256:<hex>", ErrInvalidUpload)
+	}
+	copy(sum[:], decoded)
+	return sum, nil
+}
+
+// UploadExpiryWorker periodically discards abandoned uploads
+type UploadExpiryWorker struct {
+	uploadService *UploadService
+	config        *models.UploadConfig
+	logger        *logger.DatadogLogger
+}
+
+// NewUploadExpiryWorker creates a new upload expiry worker
+func NewUploadExpiryWorker(uploadService *UploadService, config *models.UploadConfig, logger *logger.DatadogLogger) *UploadExpiryWorker {
+	return &UploadExpiryWorker{
+		uploadService: uploadService,
+		config:        config,
+		logger:        logger,
+	}
+}
+
+// Run discards expired uploads until ctx is cancelled
+func (w *UploadExpiryWorker) Run(ctx context.Context) {
+	interval := time.Duration(w.config.CleanupInterval) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	w.logger.Info("Upload expiry worker started", logrus.Fields{"interval": interval.String()})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Upload expiry worker stopped", nil)
+			return
+		case <-ticker.C:
+			if expired := w.uploadService.ExpireUploads(); expired > 0 {
+				w.logger.Info("Expired uploads discarded", logrus.Fields{"count": expired})
+			}
+		}
+	}
+}
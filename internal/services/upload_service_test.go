@@ -0,0 +1,140 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupUploadService() (*UploadService, *DocumentService) {
+	logger := logger.NewDatadogLogger()
+	documentService := NewDocumentService(logger)
+	validator := NewDocumentValidator(&models.DocumentValidationConfig{Types: []models.DocumentTypeRule{
+		{MIMEType: "application/pdf", Extensions: []string{".pdf"}, MaxSize: 4096, MaxPages: 10},
+	}})
+	config := &models.UploadConfig{Expiry: 3600, CleanupInterval: 60}
+	return NewUploadService(documentService, validator, config, logger), documentService
+}
+
+func sha256Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestUploadService_ResumableUpload(t *testing.T) {
+	service, documentService := setupUploadService()
+	content := bundlePDF(3)
+
+	upload := &models.Upload{CaseID: "case-1", FileName: "scan.pdf", UploadedBy: "test-user", Length: int64(len(content))}
+	require.NoError(t, service.CreateUpload(upload))
+	assert.NotEmpty(t, upload.ID)
+
+	// Chunks must continue where the upload left off
+	progress, err := service.AppendChunk(upload.ID, 0, content[:100])
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), progress.Offset)
+
+	_, err = service.AppendChunk(upload.ID, 50, content[50:])
+	assert.ErrorIs(t, err, ErrUploadOffsetMismatch)
+
+	_, err = service.FinalizeUpload(upload.ID, sha256Checksum(content))
+	assert.ErrorIs(t, err, ErrUploadIncomplete)
+
+	_, err = service.AppendChunk(upload.ID, 100, append(content[100:], 'x'))
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+
+	progress, err = service.AppendChunk(upload.ID, 100, content[100:])
+	require.NoError(t, err)
+	assert.Equal(t, upload.Length, progress.Offset)
+
+	_, err = service.FinalizeUpload(upload.ID, sha256Checksum([]byte("other")))
+	assert.ErrorIs(t, err, ErrUploadChecksumMismatch)
+	_, err = service.FinalizeUpload(upload.ID, "md5:abc")
+	assert.ErrorIs(t, err, ErrInvalidUpload)
+
+	document, err := service.FinalizeUpload(upload.ID, sha256Checksum(content))
+	require.NoError(t, err)
+	assert.Equal(t, "case-1", document.CaseID)
+	assert.Equal(t, "application/pdf", document.FileType)
+	assert.Equal(t, content, document.Content)
+
+	stored, err := documentService.GetDocument(document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "scan.pdf", stored.FileName)
+
+	// Finalizing again returns the same document
+	again, err := service.FinalizeUpload(upload.ID, sha256Checksum(content))
+	require.NoError(t, err)
+	assert.Equal(t, document.ID, again.ID)
+
+	_, err = service.AppendChunk(upload.ID, upload.Length, []byte("x"))
+	assert.ErrorIs(t, err, ErrUploadFinalized)
+}
+
+func TestUploadService_CreateUploadErrors(t *testing.T) {
+	service, _ := setupUploadService()
+
+	for name, upload := range map[string]*models.Upload{
+		"no case":      {FileName: "scan.pdf", Length: 10},
+		"no file name": {CaseID: "case-1", Length: 10},
+		"no length":    {CaseID: "case-1", FileName: "scan.pdf"},
+	} {
+		assert.ErrorIs(t, service.CreateUpload(upload), ErrInvalidUpload, name)
+	}
+
+	err := service.CreateUpload(&models.Upload{CaseID: "case-1", FileName: "scan.pdf", Length: 4097})
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+}
+
+func TestUploadService_RejectedUploadIsDiscarded(t *testing.T) {
+	service, _ := setupUploadService()
+	content := []byte("MZ\x90\x00 not a document")
+
+	upload := &models.Upload{CaseID: "case-1", FileName: "scan.pdf", Length: int64(len(content))}
+	require.NoError(t, service.CreateUpload(upload))
+	_, err := service.AppendChunk(upload.ID, 0, content)
+	require.NoError(t, err)
+
+	_, err = service.FinalizeUpload(upload.ID, sha256Checksum(content))
+	assert.ErrorIs(t, err, ErrDocumentRejected)
+
+	_, err = service.GetUpload(upload.ID)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+}
+
+func TestUploadService_ExpireUploads(t *testing.T) {
+	service, _ := setupUploadService()
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	abandoned := &models.Upload{CaseID: "case-1", FileName: "scan.pdf", Length: 10}
+	require.NoError(t, service.CreateUpload(abandoned))
+	active := &models.Upload{CaseID: "case-1", FileName: "scan.pdf", Length: 10}
+	require.NoError(t, service.CreateUpload(active))
+
+	// Each chunk extends the expiry
+	now = now.Add(30 * time.Minute)
+	_, err := service.AppendChunk(active.ID, 0, []byte("01234"))
+	require.NoError(t, err)
+
+	now = now.Add(45 * time.Minute)
+	_, err = service.GetUpload(abandoned.ID)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+	assert.Equal(t, 1, service.ExpireUploads())
+
+	progress, err := service.GetUpload(active.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), progress.Offset)
+
+	require.NoError(t, service.DeleteUpload(active.ID))
+	_, err = service.GetUpload(active.ID)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+	assert.Equal(t, 0, service.ExpireUploads())
+}
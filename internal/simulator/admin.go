@@ -0,0 +1,138 @@
+package simulator
+
+import (
+	"errors"
+	"net/http"
+
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeedChargebackRequest describes an inbound chargeback raised by the counterparty
+type SeedChargebackRequest struct {
+	IssuerICA         string `json:"issuerIca"`
+	AcquirerICA       string `json:"acquirerIca"`
+	Amount            string `json:"amount"`
+	Currency          string `json:"currency"`
+	ReasonCode        string `json:"reasonCode"`
+	ChargebackType    string `json:"chargebackType"`
+	PrimaryAccountNum string `json:"primaryAccountNum"`
+	AcquirerRefNum    string `json:"acquirerRefNum"`
+	TransactionID     string `json:"transactionId"`
+}
+
+// SeedChargebackResponse identifies a seeded chargeback
+type SeedChargebackResponse struct {
+	ClaimID      string `json:"claimId"`
+	ChargebackID string `json:"chargebackId"`
+}
+
+func (s *Simulator) listFaults(c *gin.Context) {
+	c.JSON(http.StatusOK, s.faults.list())
+}
+
+func (s *Simulator) addFault(c *gin.Context) {
+	var rule FaultRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	added, err := s.faults.add(rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, added)
+}
+
+func (s *Simulator) clearFaults(c *gin.Context) {
+	if err := s.faults.reset(nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Simulator) removeFault(c *gin.Context) {
+	if err := s.faults.remove(c.Param("id")); err != nil {
+		if errors.Is(err, ErrFaultNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Fault rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Simulator) reset(c *gin.Context) {
+	if err := s.Reset(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// seedChargeback places a claim with a chargeback received from the counterparty in the
+// Unworked queue, where it waits to be acknowledged
+func (s *Simulator) seedChargeback(c *gin.Context) {
+	var req SeedChargebackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Amount == "" {
+		req.Amount = "100.00"
+	}
+	if req.Currency == "" {
+		req.Currency = "USD"
+	}
+	if req.ReasonCode == "" {
+		req.ReasonCode = "4853"
+	}
+	if req.ChargebackType == "" {
+		req.ChargebackType = "CHARGEBACK"
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	claim := &simClaim{
+		id:                s.state.newClaimID(),
+		claimType:         claimTypeStandard,
+		amount:            req.Amount,
+		currency:          req.Currency,
+		transactionID:     req.TransactionID,
+		primaryAccountNum: req.PrimaryAccountNum,
+		acquirerRefNum:    req.AcquirerRefNum,
+		issuerID:          req.IssuerICA,
+		acquirerID:        req.AcquirerICA,
+		createdAt:         now,
+	}
+	claim.move(queueUnworked, now)
+
+	chargeback := &simChargeback{
+		details: mastercom.ChargebackDetails{
+			ChargebackID:   s.state.newChargebackID(),
+			ClaimID:        claim.id,
+			ChargebackType: req.ChargebackType,
+			Amount:         req.Amount,
+			Currency:       req.Currency,
+			ReasonCode:     req.ReasonCode,
+			CreateDate:     now.Format(dateLayout),
+		},
+		inbound:   true,
+		createdAt: now,
+	}
+	chargeback.details.ChargebackRefNum = chargeback.details.ChargebackID[len(chargeback.details.ChargebackID)-10:]
+
+	s.state.claims[claim.id] = claim
+	s.state.chargebacks[chargeback.details.ChargebackID] = chargeback
+	claim.chargebackIDs = []string{chargeback.details.ChargebackID}
+
+	c.JSON(http.StatusCreated, SeedChargebackResponse{ClaimID: claim.id, ChargebackID: chargeback.details.ChargebackID})
+}
@@ -0,0 +1,354 @@
+package simulator
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Case filing statuses
+const (
+	caseStatusOpen      = "Open"
+	caseStatusRebutted  = "Rebutted"
+	caseStatusEscalated = "Escalated"
+	caseStatusRejected  = "Rejected"
+	caseStatusClosed    = "Closed"
+	caseStatusWithdrawn = "Withdrawn"
+)
+
+// Case filing actions accepted by PUT /v6/cases/{case-id}
+const (
+	caseActionAccept   = "ACCEPT"
+	caseActionReject   = "REJECT"
+	caseActionRebut    = "REBUT"
+	caseActionEscalate = "ESCALATE"
+	caseActionWithdraw = "WITHDRAW"
+	caseActionDocRetry = "DOC_RETRY"
+	caseActionFiled    = "FILED"
+)
+
+// escalatedCaseTypes maps pre-arbitration and pre-compliance cases to the case type they
+// become when escalated
+var escalatedCaseTypes = map[string]string{"1": "2", "3": "4"}
+
+// createCase files a case, opening a case filing claim in the Submitted queue
+func (s *Simulator) createCase(c *gin.Context) {
+	var req mastercom.CreateCaseRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !requireFields(c, map[string]string{
+		"caseType":        req.CaseType,
+		"disputeAmount":   req.DisputeAmount,
+		"currencyCode":    req.CurrencyCode,
+		"filedAgainstIca": req.FiledAgainstICA,
+		"filingAs":        req.FilingAs,
+		"filingIca":       req.FilingICA,
+		"memo":            req.Memo,
+	}, "caseType", "disputeAmount", "currencyCode", "filedAgainstIca", "filingAs", "filingIca", "memo") {
+		return
+	}
+	switch req.CaseType {
+	case "1", "2", "3", "4":
+	default:
+		writeError(c, http.StatusBadRequest, "caseType", "INVALID_FIELD", "caseType must be 1, 2, 3 or 4", true)
+		return
+	}
+	if req.FilingAs != "I" && req.FilingAs != "A" {
+		writeError(c, http.StatusBadRequest, "filingAs", "INVALID_FIELD", "filingAs must be I or A", true)
+		return
+	}
+	if err := validateAttachment(req.FileAttachment); err != nil {
+		writeError(c, http.StatusBadRequest, "fileAttachment", "INVALID_DOCUMENT", err.Error(), true)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	caseID := s.state.newCaseID()
+	claim := &simClaim{
+		id:                s.state.newClaimID(),
+		claimType:         claimTypeCaseFiled,
+		amount:            req.DisputeAmount,
+		currency:          req.CurrencyCode,
+		primaryAccountNum: req.PrimaryAccountNum,
+		acquirerRefNum:    req.AcquirerRefNum,
+		isIssuer:          req.FilingAs == "I",
+		createdAt:         now,
+		caseID:            caseID,
+	}
+	if claim.isIssuer {
+		claim.issuerID, claim.acquirerID = req.FilingICA, req.FiledAgainstICA
+	} else {
+		claim.issuerID, claim.acquirerID = req.FiledAgainstICA, req.FilingICA
+	}
+	claim.move(queueSubmitted, now)
+
+	s.state.claims[claim.id] = claim
+	s.state.cases[caseID] = &simCase{
+		details: mastercom.CaseFilingDetails{
+			ClaimID:              claim.id,
+			ClaimType:            claimTypeCaseFiled,
+			CaseID:               caseID,
+			CaseType:             req.CaseType,
+			ChargebackRefNum:     req.ChargebackRefNum,
+			CurrencyCode:         req.CurrencyCode,
+			CustomerFilingNumber: req.CustomerFilingNumber,
+			ReasonCode:           req.ChargebackReasonCode,
+			DisputeAmount:        req.DisputeAmount,
+			DueDate:              req.DueDate,
+			FilingAgaintstICA:    req.FiledAgainstICA,
+			FilingAs:             req.FilingAs,
+			FilingICA:            req.FilingICA,
+			MerchantName:         req.MerchantName,
+			PrimaryAccountNum:    req.PrimaryAccountNum,
+			ViolationCode:        req.ViolationCode,
+			ViolationDate:        req.ViolationDate,
+		},
+		claimID:    claim.id,
+		status:     caseStatusOpen,
+		history:    []mastercom.CaseFilingRespHistory{{Action: caseActionFiled, Memo: req.Memo, ResponseDate: now.Format(dateTimeLayout)}},
+		attachment: req.FileAttachment,
+		uploadedAt: now,
+		createdAt:  now,
+	}
+
+	c.JSON(http.StatusOK, mastercom.CaseFilingResponse{CaseID: caseID})
+}
+
+// updateCase applies a case filing action, moving the case filing claim between queues
+func (s *Simulator) updateCase(c *gin.Context) {
+	var req mastercom.UpdateCaseRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := validateAttachment(req.FileAttachment); err != nil {
+		writeError(c, http.StatusBadRequest, "fileAttachment", "INVALID_DOCUMENT", err.Error(), true)
+		return
+	}
+
+	switch req.Action {
+	case caseActionAccept, caseActionReject, caseActionRebut, caseActionDocRetry:
+		if req.Memo == "" {
+			writeError(c, http.StatusBadRequest, "memo", "MISSING_REQUIRED_FIELD", "memo is required for "+req.Action, true)
+			return
+		}
+	case caseActionEscalate, caseActionWithdraw:
+	default:
+		writeError(c, http.StatusBadRequest, "action", "INVALID_ACTION", "Unsupported case filing action", true)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseFiling, ok := s.lookupCase(c)
+	if !ok {
+		return
+	}
+	if caseFiling.status == caseStatusClosed || caseFiling.status == caseStatusWithdrawn {
+		writeError(c, http.StatusBadRequest, "caseId", "CASE_CLOSED", "Case filing is "+caseFiling.status, false)
+		return
+	}
+
+	now := s.now()
+	claim := s.state.claims[caseFiling.claimID]
+	switch req.Action {
+	case caseActionAccept:
+		caseFiling.status = caseStatusClosed
+		claim.move(queueClosed, now)
+	case caseActionReject:
+		caseFiling.status = caseStatusRejected
+		claim.move(queueRejects, now)
+	case caseActionRebut:
+		caseFiling.status = caseStatusRebutted
+		claim.move(queueSubmitted, now)
+	case caseActionEscalate:
+		escalated, ok := escalatedCaseTypes[caseFiling.details.CaseType]
+		if !ok {
+			writeError(c, http.StatusBadRequest, "action", "INVALID_ACTION", "Only pre-arbitration and pre-compliance cases can be escalated", true)
+			return
+		}
+		caseFiling.details.CaseType = escalated
+		caseFiling.status = caseStatusEscalated
+		claim.move(queueSubmitted, now)
+	case caseActionWithdraw:
+		caseFiling.status = caseStatusWithdrawn
+		claim.move(queueClosed, now)
+	case caseActionDocRetry:
+		if req.FileAttachment == nil {
+			writeError(c, http.StatusBadRequest, "fileAttachment", "MISSING_REQUIRED_FIELD", "fileAttachment is required for DOC_RETRY", true)
+			return
+		}
+		claim.lastModified = now
+	}
+
+	if req.FileAttachment != nil {
+		caseFiling.attachment = req.FileAttachment
+		caseFiling.uploadedAt = now
+	}
+	caseFiling.history = append(caseFiling.history, mastercom.CaseFilingRespHistory{
+		Action:       req.Action,
+		Memo:         req.Memo,
+		ResponseDate: now.Format(dateTimeLayout),
+	})
+
+	c.JSON(http.StatusOK, mastercom.CaseFilingResponse{CaseID: caseFiling.details.CaseID})
+}
+
+// getCaseDocuments returns a case filing's document once it has been processed
+func (s *Simulator) getCaseDocuments(c *gin.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caseFiling, ok := s.lookupCase(c)
+	if !ok {
+		return
+	}
+
+	if status := imageStatus(caseFiling.attachment, caseFiling.uploadedAt, s.now(), s.options.ImageDelay); status != imageCompleted {
+		writeError(c, http.StatusNotFound, "caseId", "DOCUMENT_NOT_AVAILABLE", "Document status is "+status, status == imagePending)
+		return
+	}
+
+	document, err := zippedAttachment(caseFiling.details.CaseID, caseFiling.attachment)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "caseId", "DOCUMENT_ERROR", err.Error(), false)
+		return
+	}
+
+	c.JSON(http.StatusOK, mastercom.DocumentResponseStructure{FileAttachment: document})
+}
+
+// caseFilingStatus reports the document processing status of case filings
+func (s *Simulator) caseFilingStatus(c *gin.Context) {
+	var req mastercom.CaseFilingStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	response := mastercom.CaseFilingStatusResponse{}
+	for _, item := range req.CaseFilingList {
+		status := imageUnavailable
+		if caseFiling, exists := s.state.cases[item.CaseID]; exists {
+			status = imageStatus(caseFiling.attachment, caseFiling.uploadedAt, now, s.options.ImageDelay)
+		}
+		response.CaseFilingResponseList = append(response.CaseFilingResponseList, mastercom.CaseFilingStatusResponseStructure{
+			CaseID: item.CaseID,
+			Status: status,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// caseFilingImageStatus lists the case filings whose documents, uploaded in a date range,
+// have the requested status
+func (s *Simulator) caseFilingImageStatus(c *gin.Context) {
+	var req mastercom.CaseFilingImageStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	switch req.Status {
+	case imageCompleted, imageFailed, imageUnprocessed:
+	default:
+		writeError(c, http.StatusBadRequest, "status", "INVALID_FIELD", "status must be COMPLETED, FAILED or UNPROCESSED", true)
+		return
+	}
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "startDate", "INVALID_FIELD", "startDate must be yyyy-MM-dd", true)
+		return
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil || end.Before(start) {
+		writeError(c, http.StatusBadRequest, "endDate", "INVALID_FIELD", "endDate must be yyyy-MM-dd and not before startDate", true)
+		return
+	}
+	end = end.AddDate(0, 0, 1)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	response := mastercom.CaseFilingImageStatusResponse{}
+	for _, caseFiling := range s.sortedCases() {
+		if caseFiling.attachment == nil {
+			continue
+		}
+		uploaded := caseFiling.uploadedAt.UTC()
+		if uploaded.Before(start) || !uploaded.Before(end) {
+			continue
+		}
+
+		status := imageStatus(caseFiling.attachment, caseFiling.uploadedAt, now, s.options.ImageDelay)
+		if status == imagePending {
+			status = imageUnprocessed
+		}
+		if status == req.Status {
+			response.CaseFilingImageStatusList = append(response.CaseFilingImageStatusList, mastercom.CaseFilingImageStatusResponseStructure{
+				CaseID: caseFiling.details.CaseID,
+				Status: status,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// retrieveCaseClaims returns the claim of each known case filing
+func (s *Simulator) retrieveCaseClaims(c *gin.Context) {
+	var req mastercom.CaseFilingClaimsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	response := mastercom.CaseFilingClaimsResponse{}
+	for _, item := range req.CaseFilingList {
+		if caseFiling, exists := s.state.cases[item.CaseID]; exists {
+			response.CaseFilingResponseList = append(response.CaseFilingResponseList, mastercom.CaseFilingClaim{
+				CaseID:  item.CaseID,
+				ClaimID: caseFiling.claimID,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// lookupCase returns the case filing named in the path, responding 404 when it does not
+// exist. The caller must hold the mutex.
+func (s *Simulator) lookupCase(c *gin.Context) (*simCase, bool) {
+	caseFiling, exists := s.state.cases[c.Param("case-id")]
+	if !exists {
+		writeError(c, http.StatusNotFound, "caseId", "CASE_NOT_FOUND", "Case filing not found", false)
+		return nil, false
+	}
+	return caseFiling, true
+}
+
+// sortedCases returns every case filing in ID order. The caller must hold the mutex.
+func (s *Simulator) sortedCases() []*simCase {
+	cases := make([]*simCase, 0, len(s.state.cases))
+	for _, caseFiling := range s.state.cases {
+		cases = append(cases, caseFiling)
+	}
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].details.CaseID < cases[j].details.CaseID
+	})
+	return cases
+}
@@ -0,0 +1,393 @@
+package simulator
+
+import (
+	"net/http"
+
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Chargeback acknowledgement outcomes
+const (
+	ackProcessed = "PROCESSED"
+	ackFailure   = "FAILURE"
+)
+
+// createClaim opens an issuer claim in the Worked queue
+func (s *Simulator) createClaim(c *gin.Context) {
+	var req mastercom.CreateClaimRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !requireFields(c, map[string]string{
+		"disputedAmount":        req.DisputedAmount,
+		"disputedCurrency":      req.DisputedCurrency,
+		"claimType":             req.ClaimType,
+		"clearingTransactionId": req.ClearingTransactionID,
+	}, "disputedAmount", "disputedCurrency", "claimType", "clearingTransactionId") {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	claim := &simClaim{
+		id:            s.state.newClaimID(),
+		claimType:     req.ClaimType,
+		amount:        req.DisputedAmount,
+		currency:      req.DisputedCurrency,
+		transactionID: req.ClearingTransactionID,
+		isIssuer:      true,
+		createdAt:     now,
+	}
+	claim.move(queueWorked, now)
+	s.state.claims[claim.id] = claim
+
+	c.JSON(http.StatusOK, mastercom.ClaimResponse{ClaimID: claim.id})
+}
+
+func (s *Simulator) getClaim(c *gin.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	claim, ok := s.lookupClaim(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, s.claimDetail(claim))
+}
+
+// updateClaim closes or reopens a claim
+func (s *Simulator) updateClaim(c *gin.Context) {
+	var req mastercom.UpdateClaimRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	claim, ok := s.lookupClaim(c)
+	if !ok {
+		return
+	}
+
+	switch req.Action {
+	case "CLOSE":
+		if !claim.isOpen {
+			writeError(c, http.StatusBadRequest, "action", "CLAIM_CLOSED", "Claim is already closed", true)
+			return
+		}
+		claim.move(queueClosed, s.now())
+	case "REOPEN":
+		if claim.isOpen {
+			writeError(c, http.StatusBadRequest, "action", "CLAIM_OPEN", "Claim is already open", true)
+			return
+		}
+		claim.move(queueWorked, s.now())
+	default:
+		writeError(c, http.StatusBadRequest, "action", "INVALID_ACTION", "action must be CLOSE or REOPEN", true)
+		return
+	}
+
+	c.JSON(http.StatusOK, mastercom.ClaimResponse{ClaimID: claim.id})
+}
+
+// createChargeback raises a chargeback or presentment on a claim, moving it to Submitted
+func (s *Simulator) createChargeback(c *gin.Context) {
+	var req mastercom.CreateChargebackRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !requireFields(c, map[string]string{
+		"amount":            req.Amount,
+		"chargebackType":    req.ChargebackType,
+		"currency":          req.Currency,
+		"documentIndicator": req.DocumentIndicator,
+		"reasonCode":        req.ReasonCode,
+	}, "amount", "chargebackType", "currency", "documentIndicator", "reasonCode") {
+		return
+	}
+	if err := validateAttachment(req.FileAttachment); err != nil {
+		writeError(c, http.StatusBadRequest, "fileAttachment", "INVALID_DOCUMENT", err.Error(), true)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	claim, ok := s.lookupClaim(c)
+	if !ok {
+		return
+	}
+	if !claim.isOpen {
+		writeError(c, http.StatusBadRequest, "claimId", "CLAIM_CLOSED", "Chargebacks cannot be raised on a closed claim", true)
+		return
+	}
+
+	now := s.now()
+	chargeback := &simChargeback{
+		details: mastercom.ChargebackDetails{
+			ChargebackID:        s.state.newChargebackID(),
+			ClaimID:             claim.id,
+			ChargebackType:      req.ChargebackType,
+			Amount:              req.Amount,
+			Currency:            req.Currency,
+			ReasonCode:          req.ReasonCode,
+			DocumentIndicator:   req.DocumentIndicator,
+			MessageText:         req.MessageText,
+			IsPartialChargeback: req.IsPartialChargeback,
+			CreateDate:          now.Format(dateLayout),
+		},
+		attachment: req.FileAttachment,
+		uploadedAt: now,
+		createdAt:  now,
+	}
+	chargeback.details.ChargebackRefNum = chargeback.details.ChargebackID[len(chargeback.details.ChargebackID)-10:]
+
+	s.state.chargebacks[chargeback.details.ChargebackID] = chargeback
+	claim.chargebackIDs = append(claim.chargebackIDs, chargeback.details.ChargebackID)
+	claim.move(queueSubmitted, now)
+
+	c.JSON(http.StatusOK, mastercom.ChargebackResponse{ChargebackID: chargeback.details.ChargebackID})
+}
+
+// updateChargeback attaches a document to an existing chargeback
+func (s *Simulator) updateChargeback(c *gin.Context) {
+	var req mastercom.UpdateChargebackRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := validateAttachment(req.FileAttachment); err != nil {
+		writeError(c, http.StatusBadRequest, "fileAttachment", "INVALID_DOCUMENT", err.Error(), true)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	claim, chargeback, ok := s.lookupChargeback(c)
+	if !ok {
+		return
+	}
+
+	now := s.now()
+	if req.FileAttachment != nil {
+		chargeback.attachment = req.FileAttachment
+		chargeback.uploadedAt = now
+	}
+	claim.lastModified = now
+
+	c.JSON(http.StatusOK, mastercom.ChargebackResponse{ChargebackID: chargeback.details.ChargebackID})
+}
+
+// reverseChargeback records a reversal of a chargeback
+func (s *Simulator) reverseChargeback(c *gin.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	claim, chargeback, ok := s.lookupChargeback(c)
+	if !ok {
+		return
+	}
+	if chargeback.details.Reversed || chargeback.details.Reversal {
+		writeError(c, http.StatusBadRequest, "chargebackId", "INVALID_REVERSAL", "Chargeback cannot be reversed", true)
+		return
+	}
+
+	now := s.now()
+	reversal := &simChargeback{
+		details:   chargeback.details,
+		createdAt: now,
+	}
+	reversal.details.ChargebackID = s.state.newChargebackID()
+	reversal.details.Reversal = true
+	reversal.details.CreateDate = now.Format(dateLayout)
+	chargeback.details.Reversed = true
+
+	s.state.chargebacks[reversal.details.ChargebackID] = reversal
+	claim.chargebackIDs = append(claim.chargebackIDs, reversal.details.ChargebackID)
+	claim.lastModified = now
+
+	c.JSON(http.StatusOK, mastercom.ChargebackResponse{ChargebackID: reversal.details.ChargebackID})
+}
+
+// getChargebackDocuments returns a chargeback's document once it has been processed
+func (s *Simulator) getChargebackDocuments(c *gin.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, chargeback, ok := s.lookupChargeback(c)
+	if !ok {
+		return
+	}
+
+	if status := imageStatus(chargeback.attachment, chargeback.uploadedAt, s.now(), s.options.ImageDelay); status != imageCompleted {
+		writeError(c, http.StatusNotFound, "chargebackId", "DOCUMENT_NOT_AVAILABLE", "Document status is "+status, status == imagePending)
+		return
+	}
+
+	document, err := zippedAttachment(chargeback.details.ChargebackID, chargeback.attachment)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "chargebackId", "DOCUMENT_ERROR", err.Error(), false)
+		return
+	}
+
+	c.JSON(http.StatusOK, mastercom.DocumentResponseStructure{FileAttachment: document})
+}
+
+// acknowledgeChargebacks marks inbound chargebacks as processed, moving their claims
+// out of the Unworked queue
+func (s *Simulator) acknowledgeChargebacks(c *gin.Context) {
+	var req mastercom.ChargebackMarkProcessedRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	response := mastercom.ChargebackMarkProcessedResponse{}
+	for _, item := range req.ChargebackList {
+		result := mastercom.ChargebackMarkProcessedResponseStructure{ChargebackID: item.ChargebackID, Status: ackFailure}
+
+		chargeback, exists := s.state.chargebacks[item.ChargebackID]
+		switch {
+		case !exists || chargeback.details.ClaimID != item.ClaimID:
+			result.FailureReason = "Chargeback not found"
+		case !chargeback.inbound:
+			result.FailureReason = "Only received chargebacks can be acknowledged"
+		case chargeback.acknowledged:
+			result.FailureReason = "Chargeback already processed"
+		default:
+			chargeback.acknowledged = true
+			result.Status = ackProcessed
+			result.FailureReason = ""
+
+			claim := s.state.claims[item.ClaimID]
+			if claim.queueName == queueUnworked {
+				claim.move(queueWorked, now)
+			}
+		}
+
+		response.ChargebackResponseList = append(response.ChargebackResponseList, result)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// chargebackStatus reports the document processing status of chargebacks
+func (s *Simulator) chargebackStatus(c *gin.Context) {
+	var req mastercom.ChargebackStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	response := mastercom.ChargebackStatusResponse{}
+	for _, item := range req.ChargebackList {
+		status := imageUnavailable
+		if chargeback, exists := s.state.chargebacks[item.ChargebackID]; exists && chargeback.details.ClaimID == item.ClaimID {
+			status = imageDocNotApplicable
+			if chargeback.attachment != nil {
+				status = imageStatus(chargeback.attachment, chargeback.uploadedAt, now, s.options.ImageDelay)
+			}
+		}
+
+		response.ChargebackResponseList = append(response.ChargebackResponseList, mastercom.ChargebackStatusResponseStructure{
+			ChargebackID: item.ChargebackID,
+			ClaimID:      item.ClaimID,
+			Status:       status,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// lookupClaim returns the claim named in the path, responding 404 when it does not exist.
+// The caller must hold the mutex.
+func (s *Simulator) lookupClaim(c *gin.Context) (*simClaim, bool) {
+	claim, exists := s.state.claims[c.Param("claim-id")]
+	if !exists {
+		writeError(c, http.StatusNotFound, "claimId", "CLAIM_NOT_FOUND", "Claim not found", false)
+		return nil, false
+	}
+	return claim, true
+}
+
+// lookupChargeback returns the claim and chargeback named in the path, responding 404
+// when either does not exist. The caller must hold the mutex.
+func (s *Simulator) lookupChargeback(c *gin.Context) (*simClaim, *simChargeback, bool) {
+	claim, ok := s.lookupClaim(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	chargeback, exists := s.state.chargebacks[c.Param("chargeback-id")]
+	if !exists || chargeback.details.ClaimID != claim.id {
+		writeError(c, http.StatusNotFound, "chargebackId", "CHARGEBACK_NOT_FOUND", "Chargeback not found", false)
+		return nil, nil, false
+	}
+	return claim, chargeback, true
+}
+
+// claimDetail renders a claim with its chargebacks and case filing. The caller must hold
+// the mutex.
+func (s *Simulator) claimDetail(claim *simClaim) mastercom.ClaimDetail {
+	summary := claim.summary()
+	detail := mastercom.ClaimDetail{
+		AcquirerID:        summary.AcquirerID,
+		AcquirerRefNum:    summary.AcquirerRefNum,
+		PrimaryAccountNum: summary.PrimaryAccountNum,
+		ClaimID:           summary.ClaimID,
+		ClaimType:         summary.ClaimType,
+		ClaimValue:        summary.ClaimValue,
+		ClearingNetwork:   summary.ClearingNetwork,
+		CreateDate:        summary.CreateDate,
+		DueDate:           summary.DueDate,
+		TransactionID:     summary.TransactionID,
+		IsAccurate:        boolString(summary.IsAccurate),
+		IsAcquirer:        boolString(summary.IsAcquirer),
+		IsIssuer:          boolString(summary.IsIssuer),
+		IsOpen:            boolString(summary.IsOpen),
+		IssuerID:          summary.IssuerID,
+		LastModifiedBy:    summary.LastModifiedBy,
+		LastModifiedDate:  summary.LastModifiedDate,
+		QueueName:         summary.QueueName,
+	}
+
+	now := s.now()
+	for _, chargebackID := range claim.chargebackIDs {
+		chargeback := s.state.chargebacks[chargebackID]
+		details := chargeback.details
+		if chargeback.attachment != nil {
+			details.DocumentStatus = imageStatus(chargeback.attachment, chargeback.uploadedAt, now, s.options.ImageDelay)
+		}
+		detail.ChargebackDetails = append(detail.ChargebackDetails, details)
+	}
+
+	if caseFiling, exists := s.state.cases[claim.caseID]; exists {
+		details := caseFiling.details
+		detail.CaseFilingDetails = &mastercom.CaseFilingLifeCycle{
+			CaseFilingStatus:      caseFiling.status,
+			CaseFilingDetails:     &details,
+			CaseFilingRespHistory: append([]mastercom.CaseFilingRespHistory(nil), caseFiling.history...),
+		}
+	}
+
+	return detail
+}
+
+func boolString(value bool) string {
+	if value {
+		return "true"
+	}
+	return "false"
+}
@@ -0,0 +1,279 @@
+package simulator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultRateLimitWindow = time.Second
+
+// ErrFaultNotFound is returned when removing a fault rule that does not exist
+var ErrFaultNotFound = errors.New("fault rule not found")
+
+// FaultRule injects latency, errors or rate limiting into matching API requests
+type FaultRule struct {
+	// ID identifies the rule; one is assigned when empty
+	ID string `json:"id"`
+	// Method restricts the rule to one HTTP method; empty matches every method
+	Method string `json:"method,omitempty"`
+	// PathPrefix restricts the rule to paths starting with it; empty matches every path
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	// LatencyMs delays matching requests
+	LatencyMs int `json:"latencyMs,omitempty"`
+	// StatusCode fails matching requests with a Mastercom error of this status
+	StatusCode int `json:"statusCode,omitempty"`
+	// Probability is the chance a matching request is affected; zero means always
+	Probability float64 `json:"probability,omitempty"`
+	// RateLimit allows this many matching requests per window, answering the rest with 429
+	RateLimit int `json:"rateLimit,omitempty"`
+	// WindowMs is the rate limit window, one second by default
+	WindowMs int `json:"windowMs,omitempty"`
+	// Times removes the rule after it has affected this many requests; zero means never
+	Times int `json:"times,omitempty"`
+}
+
+// Validate checks the rule injects something and its settings are in range
+func (r *FaultRule) Validate() error {
+	if r.LatencyMs == 0 && r.StatusCode == 0 && r.RateLimit == 0 {
+		return errors.New("one of latencyMs, statusCode or rateLimit is required")
+	}
+	if r.LatencyMs < 0 || r.RateLimit < 0 || r.WindowMs < 0 || r.Times < 0 {
+		return errors.New("latencyMs, rateLimit, windowMs and times must not be negative")
+	}
+	if r.StatusCode != 0 && (r.StatusCode < 400 || r.StatusCode > 599) {
+		return errors.New("statusCode must be between 400 and 599")
+	}
+	if r.Probability < 0 || r.Probability > 1 {
+		return errors.New("probability must be between 0 and 1")
+	}
+	return nil
+}
+
+func (r *FaultRule) matches(method, path string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	return strings.HasPrefix(path, r.PathPrefix)
+}
+
+func (r *FaultRule) window() time.Duration {
+	if r.WindowMs == 0 {
+		return defaultRateLimitWindow
+	}
+	return time.Duration(r.WindowMs) * time.Millisecond
+}
+
+// LoadFaults reads a JSON array of fault rules from a file
+func LoadFaults(path string) ([]FaultRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read faults: %w", err)
+	}
+
+	var rules []FaultRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse faults %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// activeFault is an installed rule and its counters
+type activeFault struct {
+	rule     FaultRule
+	applied  int
+	requests []time.Time
+}
+
+// faultInjector applies the installed fault rules to API requests
+type faultInjector struct {
+	mutex  sync.Mutex
+	faults []*activeFault
+	random *rand.Rand
+	nextID int
+	now    func() time.Time
+	sleep  func(*gin.Context, time.Duration)
+}
+
+func newFaultInjector(rules []FaultRule, seed int64) (*faultInjector, error) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	f := &faultInjector{
+		random: rand.New(rand.NewSource(seed)),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+	if err := f.reset(rules); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// reset replaces the installed rules
+func (f *faultInjector) reset(rules []FaultRule) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.faults = nil
+	for _, rule := range rules {
+		if _, err := f.addLocked(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *faultInjector) add(rule FaultRule) (FaultRule, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.addLocked(rule)
+}
+
+func (f *faultInjector) addLocked(rule FaultRule) (FaultRule, error) {
+	if err := rule.Validate(); err != nil {
+		return FaultRule{}, fmt.Errorf("invalid fault rule %q: %w", rule.ID, err)
+	}
+	if rule.ID == "" {
+		f.nextID++
+		rule.ID = "fault-" + strconv.Itoa(f.nextID)
+	}
+
+	f.faults = append(f.faults, &activeFault{rule: rule})
+	return rule, nil
+}
+
+func (f *faultInjector) remove(id string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i, fault := range f.faults {
+		if fault.rule.ID == id {
+			f.faults = append(f.faults[:i], f.faults[i+1:]...)
+			return nil
+		}
+	}
+	return ErrFaultNotFound
+}
+
+func (f *faultInjector) list() []FaultRule {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	rules := make([]FaultRule, 0, len(f.faults))
+	for _, fault := range f.faults {
+		rules = append(rules, fault.rule)
+	}
+	return rules
+}
+
+// injection is what the matching rules do to one request
+type injection struct {
+	latency    time.Duration
+	statusCode int
+	retryAfter time.Duration
+}
+
+// evaluate applies the installed rules to a request, counting it against rate limits
+// and expiring rules that have been used up
+func (f *faultInjector) evaluate(method, path string) injection {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := f.now()
+	var result injection
+	remaining := f.faults[:0]
+	for _, fault := range f.faults {
+		if fault.rule.matches(method, path) && f.apply(fault, now, &result) {
+			fault.applied++
+		}
+		if fault.rule.Times == 0 || fault.applied < fault.rule.Times {
+			remaining = append(remaining, fault)
+		}
+	}
+	f.faults = remaining
+
+	return result
+}
+
+// apply adds a matching rule's effect to the injection, reporting whether it had any
+func (f *faultInjector) apply(fault *activeFault, now time.Time, result *injection) bool {
+	rule := fault.rule
+
+	if rule.RateLimit > 0 {
+		window := rule.window()
+		recent := fault.requests[:0]
+		for _, at := range fault.requests {
+			if now.Sub(at) < window {
+				recent = append(recent, at)
+			}
+		}
+		fault.requests = recent
+
+		if len(recent) < rule.RateLimit {
+			fault.requests = append(fault.requests, now)
+			return false
+		}
+		if result.statusCode == 0 {
+			result.statusCode = http.StatusTooManyRequests
+			result.retryAfter = window - now.Sub(recent[0])
+		}
+		return true
+	}
+
+	if rule.Probability > 0 && f.random.Float64() >= rule.Probability {
+		return false
+	}
+
+	result.latency += time.Duration(rule.LatencyMs) * time.Millisecond
+	if rule.StatusCode != 0 && result.statusCode == 0 {
+		result.statusCode = rule.StatusCode
+	}
+	return true
+}
+
+// middleware delays or fails API requests as the installed rules dictate
+func (f *faultInjector) middleware(c *gin.Context) {
+	result := f.evaluate(c.Request.Method, c.Request.URL.Path)
+
+	if result.latency > 0 {
+		f.sleep(c, result.latency)
+	}
+	if result.statusCode == 0 {
+		c.Next()
+		return
+	}
+
+	if result.statusCode == http.StatusTooManyRequests {
+		seconds := int((result.retryAfter + time.Second - 1) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+
+	reasonCode := strings.ToUpper(strings.ReplaceAll(http.StatusText(result.statusCode), " ", "_"))
+	writeError(c, result.statusCode, "Simulator", reasonCode, "Injected fault", result.statusCode >= http.StatusInternalServerError || result.statusCode == http.StatusTooManyRequests)
+	c.Abort()
+}
+
+// sleepContext waits for d or until the request is cancelled
+func sleepContext(c *gin.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-c.Request.Context().Done():
+	}
+}
@@ -0,0 +1,97 @@
+package simulator
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Simulator) getQueueNames(c *gin.Context) {
+	c.JSON(http.StatusOK, queues)
+}
+
+// getQueue lists every claim in a queue
+func (s *Simulator) getQueue(c *gin.Context) {
+	queueName := c.Query("queue-name")
+	if !isQueue(queueName) {
+		writeError(c, http.StatusBadRequest, "queue-name", "INVALID_QUEUE", "Unknown queue name", true)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	claims := []mastercom.ClaimSummary{}
+	for _, claim := range s.state.claimsInQueue(queueName) {
+		claims = append(claims, claim.summary())
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
+
+// getQueueContent pages through the claims in a queue modified in a date range. Like
+// Mastercom, the range has minute precision and both ends are inclusive.
+func (s *Simulator) getQueueContent(c *gin.Context) {
+	var req mastercom.GetQueueContentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !isQueue(req.QueueName) {
+		writeError(c, http.StatusBadRequest, "queueName", "INVALID_QUEUE", "Unknown queue name", true)
+		return
+	}
+
+	var from, to time.Time
+	var err error
+	if req.LastModifiedDateFrom != "" {
+		if from, err = time.Parse(queueRangeLayout, req.LastModifiedDateFrom); err != nil {
+			writeError(c, http.StatusBadRequest, "lastModifiedDateFrom", "INVALID_FIELD", "lastModifiedDateFrom must be yyyy-MM-ddTHH:mm", true)
+			return
+		}
+	}
+	if req.LastModifiedDateTo != "" {
+		if to, err = time.Parse(queueRangeLayout, req.LastModifiedDateTo); err != nil {
+			writeError(c, http.StatusBadRequest, "lastModifiedDateTo", "INVALID_FIELD", "lastModifiedDateTo must be yyyy-MM-ddTHH:mm", true)
+			return
+		}
+	}
+
+	page := 1
+	if req.PageNb != "" {
+		if page, err = strconv.Atoi(req.PageNb); err != nil || page < 1 {
+			writeError(c, http.StatusBadRequest, "pageNb", "INVALID_FIELD", "pageNb must be a positive number", true)
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var matched []mastercom.ClaimSummary
+	for _, claim := range s.state.claimsInQueue(req.QueueName) {
+		modified := claim.lastModified.UTC().Truncate(time.Minute)
+		if !from.IsZero() && modified.Before(from) {
+			continue
+		}
+		if !to.IsZero() && modified.After(to) {
+			continue
+		}
+		matched = append(matched, claim.summary())
+	}
+
+	pageCount := (len(matched) + s.options.PageSize - 1) / s.options.PageSize
+	content := mastercom.QueueContentSummary{PageCount: strconv.Itoa(pageCount)}
+	if start := (page - 1) * s.options.PageSize; start < len(matched) {
+		end := start + s.options.PageSize
+		if end > len(matched) {
+			end = len(matched)
+		}
+		content.ClaimList = matched[start:end]
+	}
+
+	c.JSON(http.StatusOK, content)
+}
@@ -0,0 +1,131 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"time"
+
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Reconciliation report statuses
+const (
+	reconAvailable   = "Available"
+	reconUnavailable = "Unavailable"
+)
+
+var reconColumns = []string{"claimId", "chargebackId", "chargebackType", "reversal", "amount", "currency", "reasonCode", "createDate", "issuerIca", "acquirerIca"}
+
+// requestReconReport accepts a report request; the report materialises after ReconDelay
+func (s *Simulator) requestReconReport(c *gin.Context) {
+	var req mastercom.ReconReportDataAcknowledgeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "startDate", "INVALID_FIELD", "startDate must be yyyy-MM-dd", true)
+		return
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil || end.Before(start) {
+		writeError(c, http.StatusBadRequest, "endDate", "INVALID_FIELD", "endDate must be yyyy-MM-dd and not before startDate", true)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	reportID := uuid.New().String()
+	s.state.reports[reportID] = &simReport{request: req, requestedAt: s.now()}
+
+	c.JSON(http.StatusOK, mastercom.ReconReportDataAcknowledgeResponse{ReportIdentifier: reportID})
+}
+
+// retrieveReconReport returns a report as a base64 encoded CSV of the chargebacks created
+// in its date range, once it is available
+func (s *Simulator) retrieveReconReport(c *gin.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	report, exists := s.state.reports[c.Param("reportIdentifier")]
+	if !exists {
+		writeError(c, http.StatusNotFound, "reportIdentifier", "REPORT_NOT_FOUND", "Report not found", false)
+		return
+	}
+
+	if s.now().Sub(report.requestedAt) < s.options.ReconDelay {
+		c.JSON(http.StatusOK, mastercom.ReconReportDataRetrivalResponse{Status: reconUnavailable})
+		return
+	}
+
+	data, err := s.reconCSV(report.request)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "reportIdentifier", "REPORT_ERROR", err.Error(), false)
+		return
+	}
+
+	c.JSON(http.StatusOK, mastercom.ReconReportDataRetrivalResponse{
+		Status: reconAvailable,
+		Data:   base64.StdEncoding.EncodeToString(data),
+	})
+}
+
+// reconCSV renders the chargebacks matching a report request. The caller must hold the
+// mutex.
+func (s *Simulator) reconCSV(req mastercom.ReconReportDataAcknowledgeRequest) ([]byte, error) {
+	start, _ := time.Parse(dateLayout, req.StartDate)
+	end, _ := time.Parse(dateLayout, req.EndDate)
+	end = end.AddDate(0, 0, 1)
+
+	icas := make(map[string]bool, len(req.ICA))
+	for _, ica := range req.ICA {
+		icas[ica] = true
+	}
+
+	var rows [][]string
+	for _, chargeback := range s.state.chargebacks {
+		created := chargeback.createdAt.UTC()
+		if created.Before(start) || !created.Before(end) {
+			continue
+		}
+
+		claim := s.state.claims[chargeback.details.ClaimID]
+		if len(icas) > 0 && !icas[claim.issuerID] && !icas[claim.acquirerID] {
+			continue
+		}
+
+		details := chargeback.details
+		rows = append(rows, []string{
+			details.ClaimID,
+			details.ChargebackID,
+			details.ChargebackType,
+			boolString(details.Reversal),
+			details.Amount,
+			details.Currency,
+			details.ReasonCode,
+			details.CreateDate,
+			claim.issuerID,
+			claim.acquirerID,
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i][1] < rows[j][1] })
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(reconColumns); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
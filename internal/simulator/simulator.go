@@ -0,0 +1,221 @@
+// Package simulator is an in-memory Mastercom API for offline development and integration
+// tests. Claims, chargebacks, case filings and reconciliation reports are stateful; the
+// remaining operations in mastercom-swagger.yaml are served from the spec's samples. Faults
+// (latency, server errors and rate limits) can be injected through the /__sim admin API.
+package simulator
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/mastercom"
+	"mastercom-service/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultImageDelay = 5 * time.Second
+	defaultReconDelay = 5 * time.Second
+	defaultPageSize   = 100
+)
+
+// Options configures a Simulator
+type Options struct {
+	// SpecPath is the OpenAPI spec whose operations without stateful behaviour are served
+	// from their x-samples; when empty only the stateful operations are served
+	SpecPath string
+	// ImageDelay is how long uploaded documents stay PENDING before they are COMPLETED
+	ImageDelay time.Duration
+	// ReconDelay is how long a requested reconciliation report stays Unavailable
+	ReconDelay time.Duration
+	// PageSize is the number of claims per queue content page
+	PageSize int
+	// Faults are installed when the simulator starts and after every reset
+	Faults []FaultRule
+	// Seed makes probabilistic faults reproducible; zero seeds from the clock
+	Seed int64
+	// RequireOAuth rejects API requests without an OAuth Authorization header
+	RequireOAuth bool
+}
+
+// Simulator serves the Mastercom API from memory
+type Simulator struct {
+	options Options
+	logger  *logger.DatadogLogger
+	now     func() time.Time
+
+	mutex sync.Mutex
+	state *state
+
+	faults *faultInjector
+	engine *gin.Engine
+}
+
+// New creates a simulator and its routes
+func New(options Options, logger *logger.DatadogLogger) (*Simulator, error) {
+	if options.ImageDelay == 0 {
+		options.ImageDelay = defaultImageDelay
+	}
+	if options.ReconDelay == 0 {
+		options.ReconDelay = defaultReconDelay
+	}
+	if options.PageSize <= 0 {
+		options.PageSize = defaultPageSize
+	}
+
+	s := &Simulator{
+		options: options,
+		logger:  logger,
+		now:     time.Now,
+		state:   newState(),
+	}
+
+	faults, err := newFaultInjector(options.Faults, options.Seed)
+	if err != nil {
+		return nil, err
+	}
+	s.faults = faults
+
+	var samples []specOperation
+	if options.SpecPath != "" {
+		samples, err = loadSpecOperations(options.SpecPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.engine = s.routes(samples)
+	return s, nil
+}
+
+// Handler returns the simulator's HTTP handler
+func (s *Simulator) Handler() http.Handler {
+	return s.engine
+}
+
+// Reset discards all state and restores the startup fault rules
+func (s *Simulator) Reset() error {
+	s.mutex.Lock()
+	s.state = newState()
+	s.mutex.Unlock()
+
+	return s.faults.reset(s.options.Faults)
+}
+
+// operation is a stateful handler for one spec operation
+type operation struct {
+	method  string
+	path    string
+	handler gin.HandlerFunc
+}
+
+func (s *Simulator) operations() []operation {
+	return []operation{
+		{http.MethodPost, "/v6/cases", s.createCase},
+		{http.MethodPut, "/v6/cases/{case-id}", s.updateCase},
+		{http.MethodGet, "/v6/cases/{case-id}/documents", s.getCaseDocuments},
+		{http.MethodPut, "/v6/cases/status", s.caseFilingStatus},
+		{http.MethodPut, "/v6/cases/imagestatus", s.caseFilingImageStatus},
+		{http.MethodPut, "/v6/cases/retrieve/claims", s.retrieveCaseClaims},
+		{http.MethodPost, "/v6/claims", s.createClaim},
+		{http.MethodGet, "/v6/claims/{claim-id}", s.getClaim},
+		{http.MethodPut, "/v6/claims/{claim-id}", s.updateClaim},
+		{http.MethodPost, "/v6/claims/{claim-id}/chargebacks", s.createChargeback},
+		{http.MethodPut, "/v6/claims/{claim-id}/chargebacks/{chargeback-id}", s.updateChargeback},
+		{http.MethodPost, "/v6/claims/{claim-id}/chargebacks/{chargeback-id}/reversal", s.reverseChargeback},
+		{http.MethodGet, "/v6/claims/{claim-id}/chargebacks/{chargeback-id}/documents", s.getChargebackDocuments},
+		{http.MethodPut, "/v6/chargebacks/acknowledge", s.acknowledgeChargebacks},
+		{http.MethodPut, "/v6/chargebacks/status", s.chargebackStatus},
+		{http.MethodGet, "/v6/queues/names", s.getQueueNames},
+		{http.MethodGet, "/v6/queues", s.getQueue},
+		{http.MethodPost, "/v6/queues", s.getQueueContent},
+		{http.MethodPost, "/v6/reconreport/data/request", s.requestReconReport},
+		{http.MethodPost, "/v6/reconreport/data/retrieval/{reportIdentifier}", s.retrieveReconReport},
+	}
+}
+
+func (s *Simulator) routes(samples []specOperation) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	if s.logger != nil {
+		router.Use(middleware.Logger(s.logger))
+	}
+
+	admin := router.Group("/__sim")
+	{
+		admin.GET("/faults", s.listFaults)
+		admin.POST("/faults", s.addFault)
+		admin.DELETE("/faults", s.clearFaults)
+		admin.DELETE("/faults/:id", s.removeFault)
+		admin.POST("/reset", s.reset)
+		admin.POST("/chargebacks", s.seedChargeback)
+	}
+
+	api := router.Group("", s.requireOAuth, s.faults.middleware)
+
+	stateful := make(map[string]bool)
+	for _, op := range s.operations() {
+		stateful[op.method+" "+op.path] = true
+		api.Handle(op.method, ginPath(op.path), op.handler)
+	}
+	for _, sample := range samples {
+		if stateful[sample.method+" "+sample.path] {
+			continue
+		}
+		api.Handle(sample.method, ginPath(sample.path), sampleHandler(sample))
+	}
+
+	return router
+}
+
+// requireOAuth rejects unsigned requests when the simulator is configured to
+func (s *Simulator) requireOAuth(c *gin.Context) {
+	if s.options.RequireOAuth && !strings.HasPrefix(c.GetHeader("Authorization"), "OAuth ") {
+		writeError(c, http.StatusUnauthorized, "Authorization", "UNAUTHORIZED", "OAuth signature required", false)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// ginPath converts an OpenAPI path template to a gin route
+func ginPath(path string) string {
+	path = strings.ReplaceAll(path, "{", ":")
+	return strings.ReplaceAll(path, "}", "")
+}
+
+// writeError responds with the Mastercom error model
+func writeError(c *gin.Context, status int, source, reasonCode, description string, recoverable bool) {
+	c.JSON(status, mastercom.Errors{Errors: []mastercom.Error{{
+		RequestID:   uuid.New().String(),
+		Source:      source,
+		ReasonCode:  reasonCode,
+		Description: description,
+		Recoverable: recoverable,
+	}}})
+}
+
+// bindJSON decodes the request body, responding with a Mastercom error when it is malformed
+func bindJSON(c *gin.Context, out interface{}) bool {
+	if err := c.ShouldBindJSON(out); err != nil {
+		writeError(c, http.StatusBadRequest, "Body", "INVALID_REQUEST", "Invalid request body: "+err.Error(), true)
+		return false
+	}
+	return true
+}
+
+// requireFields responds with a Mastercom error naming the first empty required field
+func requireFields(c *gin.Context, fields map[string]string, order ...string) bool {
+	for _, name := range order {
+		if fields[name] == "" {
+			writeError(c, http.StatusBadRequest, name, "MISSING_REQUIRED_FIELD", name+" is required", true)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,380 @@
+package simulator
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// simClock is a settable clock shared by the simulator and its fault injector
+type simClock struct {
+	now time.Time
+}
+
+func (c *simClock) Now() time.Time { return c.now }
+
+func setupSimulator(t *testing.T, options Options) (*Simulator, *simClock) {
+	gin.SetMode(gin.TestMode)
+
+	sim, err := New(options, nil)
+	require.NoError(t, err)
+
+	clock := &simClock{now: time.Date(2024, 7, 16, 12, 0, 0, 0, time.UTC)}
+	sim.now = clock.Now
+	sim.faults.now = clock.Now
+	return sim, clock
+}
+
+func doJSON(t *testing.T, sim *Simulator, method, path string, body interface{}, out interface{}) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	sim.Handler().ServeHTTP(w, req)
+
+	if out != nil && w.Code < 300 {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), out))
+	}
+	return w
+}
+
+func decodeErrors(t *testing.T, w *httptest.ResponseRecorder) mastercom.Errors {
+	var errs mastercom.Errors
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errs))
+	require.NotEmpty(t, errs.Errors)
+	return errs
+}
+
+func createCaseRequest() mastercom.CreateCaseRequest {
+	return mastercom.CreateCaseRequest{
+		CaseType:        "1",
+		DisputeAmount:   "100.00",
+		CurrencyCode:    "USD",
+		FiledAgainstICA: "654321",
+		FilingAs:        "I",
+		FilingICA:       "123456",
+		Memo:            "Pre-arbitration for reason code 4853",
+		FileAttachment: &mastercom.DocumentStructure{
+			Filename: "evidence.pdf",
+			File:     base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 evidence")),
+		},
+	}
+}
+
+func TestSimulator_CaseFilingLifecycle(t *testing.T) {
+	sim, clock := setupSimulator(t, Options{ImageDelay: time.Minute})
+
+	// Required fields are validated with the Mastercom error model
+	invalid := createCaseRequest()
+	invalid.Memo = ""
+	w := doJSON(t, sim, http.MethodPost, "/v6/cases", invalid, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "memo", decodeErrors(t, w).Errors[0].Source)
+
+	invalid = createCaseRequest()
+	invalid.CaseType = "PRE_ARBITRATION"
+	w = doJSON(t, sim, http.MethodPost, "/v6/cases", invalid, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "caseType", decodeErrors(t, w).Errors[0].Source)
+
+	var created mastercom.CaseFilingResponse
+	w = doJSON(t, sim, http.MethodPost, "/v6/cases", createCaseRequest(), &created)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEmpty(t, created.CaseID)
+
+	// The case filing claim is in the Submitted queue
+	var claims mastercom.CaseFilingClaimsResponse
+	doJSON(t, sim, http.MethodPut, "/v6/cases/retrieve/claims", mastercom.CaseFilingClaimsRequest{
+		CaseFilingList: []mastercom.CaseFilingIDSourceRequest{{CaseID: created.CaseID, IsIssuer: true}, {CaseID: "unknown"}},
+	}, &claims)
+	require.Len(t, claims.CaseFilingResponseList, 1)
+	claimID := claims.CaseFilingResponseList[0].ClaimID
+
+	var detail mastercom.ClaimDetail
+	doJSON(t, sim, http.MethodGet, "/v6/claims/"+claimID, nil, &detail)
+	assert.Equal(t, queueSubmitted, detail.QueueName)
+	assert.Equal(t, "123456", detail.IssuerID)
+	require.NotNil(t, detail.CaseFilingDetails)
+	assert.Equal(t, caseStatusOpen, detail.CaseFilingDetails.CaseFilingStatus)
+
+	// Documents are processed after the image delay
+	statusRequest := mastercom.CaseFilingStatusRequest{CaseFilingList: []mastercom.CaseFilingStatusRequestStructure{{CaseID: created.CaseID}}}
+	var status mastercom.CaseFilingStatusResponse
+	doJSON(t, sim, http.MethodPut, "/v6/cases/status", statusRequest, &status)
+	assert.Equal(t, imagePending, status.CaseFilingResponseList[0].Status)
+
+	w = doJSON(t, sim, http.MethodGet, "/v6/cases/"+created.CaseID+"/documents", nil, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	imageRequest := mastercom.CaseFilingImageStatusRequest{Status: imageUnprocessed, StartDate: "2024-07-16", EndDate: "2024-07-16"}
+	var images mastercom.CaseFilingImageStatusResponse
+	doJSON(t, sim, http.MethodPut, "/v6/cases/imagestatus", imageRequest, &images)
+	require.Len(t, images.CaseFilingImageStatusList, 1)
+
+	clock.now = clock.now.Add(time.Minute)
+	doJSON(t, sim, http.MethodPut, "/v6/cases/status", statusRequest, &status)
+	assert.Equal(t, imageCompleted, status.CaseFilingResponseList[0].Status)
+
+	var document mastercom.DocumentResponseStructure
+	w = doJSON(t, sim, http.MethodGet, "/v6/cases/"+created.CaseID+"/documents", nil, &document)
+	require.Equal(t, http.StatusOK, w.Code)
+	content, err := base64.StdEncoding.DecodeString(document.FileAttachment.File)
+	require.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 1)
+	assert.Equal(t, "evidence.pdf", archive.File[0].Name)
+
+	// Actions move the case through its lifecycle
+	w = doJSON(t, sim, http.MethodPut, "/v6/cases/"+created.CaseID, mastercom.UpdateCaseRequest{Action: caseActionRebut}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(t, sim, http.MethodPut, "/v6/cases/"+created.CaseID, mastercom.UpdateCaseRequest{Action: caseActionEscalate}, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	doJSON(t, sim, http.MethodGet, "/v6/claims/"+claimID, nil, &detail)
+	assert.Equal(t, "2", detail.CaseFilingDetails.CaseFilingDetails.CaseType)
+	assert.Equal(t, caseStatusEscalated, detail.CaseFilingDetails.CaseFilingStatus)
+
+	w = doJSON(t, sim, http.MethodPut, "/v6/cases/"+created.CaseID, mastercom.UpdateCaseRequest{Action: caseActionAccept, Memo: "Accepted"}, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	doJSON(t, sim, http.MethodGet, "/v6/claims/"+claimID, nil, &detail)
+	assert.Equal(t, queueClosed, detail.QueueName)
+	assert.Equal(t, "false", detail.IsOpen)
+	assert.Len(t, detail.CaseFilingDetails.CaseFilingRespHistory, 3)
+
+	w = doJSON(t, sim, http.MethodPut, "/v6/cases/"+created.CaseID, mastercom.UpdateCaseRequest{Action: caseActionWithdraw}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "CASE_CLOSED", decodeErrors(t, w).Errors[0].ReasonCode)
+}
+
+func TestSimulator_ChargebackAcknowledgement(t *testing.T) {
+	sim, _ := setupSimulator(t, Options{})
+
+	var seeded SeedChargebackResponse
+	w := doJSON(t, sim, http.MethodPost, "/__sim/chargebacks", SeedChargebackRequest{IssuerICA: "123456", AcquirerICA: "654321"}, &seeded)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var unworked []mastercom.ClaimSummary
+	doJSON(t, sim, http.MethodGet, "/v6/queues?queue-name=Unworked", nil, &unworked)
+	require.Len(t, unworked, 1)
+	assert.Equal(t, seeded.ClaimID, unworked[0].ClaimID)
+
+	request := mastercom.ChargebackMarkProcessedRequest{ChargebackList: []mastercom.ChargebackMarkProcessedRequestStructure{
+		{ClaimID: seeded.ClaimID, ChargebackID: seeded.ChargebackID},
+		{ClaimID: seeded.ClaimID, ChargebackID: "unknown"},
+	}}
+	var response mastercom.ChargebackMarkProcessedResponse
+	doJSON(t, sim, http.MethodPut, "/v6/chargebacks/acknowledge", request, &response)
+	require.Len(t, response.ChargebackResponseList, 2)
+	assert.Equal(t, ackProcessed, response.ChargebackResponseList[0].Status)
+	assert.Equal(t, ackFailure, response.ChargebackResponseList[1].Status)
+
+	// The acknowledged claim moves to the Worked queue and cannot be acknowledged twice
+	var detail mastercom.ClaimDetail
+	doJSON(t, sim, http.MethodGet, "/v6/claims/"+seeded.ClaimID, nil, &detail)
+	assert.Equal(t, queueWorked, detail.QueueName)
+
+	doJSON(t, sim, http.MethodPut, "/v6/chargebacks/acknowledge", request, &response)
+	assert.Equal(t, ackFailure, response.ChargebackResponseList[0].Status)
+	assert.Equal(t, "Chargeback already processed", response.ChargebackResponseList[0].FailureReason)
+
+	// Our own chargebacks are submitted, not acknowledged
+	var claim mastercom.ClaimResponse
+	doJSON(t, sim, http.MethodPost, "/v6/claims", mastercom.CreateClaimRequest{
+		DisputedAmount: "10.00", DisputedCurrency: "USD", ClaimType: "Standard", ClearingTransactionID: "txn",
+	}, &claim)
+	var chargeback mastercom.ChargebackResponse
+	w = doJSON(t, sim, http.MethodPost, "/v6/claims/"+claim.ClaimID+"/chargebacks", mastercom.CreateChargebackRequest{
+		Amount: "10.00", ChargebackType: "CHARGEBACK", Currency: "USD", DocumentIndicator: "false", ReasonCode: "4853",
+	}, &chargeback)
+	require.Equal(t, http.StatusOK, w.Code)
+	doJSON(t, sim, http.MethodGet, "/v6/claims/"+claim.ClaimID, nil, &detail)
+	assert.Equal(t, queueSubmitted, detail.QueueName)
+
+	var status mastercom.ChargebackStatusResponse
+	doJSON(t, sim, http.MethodPut, "/v6/chargebacks/status", mastercom.ChargebackStatusRequest{ChargebackList: []mastercom.ChargebackStatusRequestStructure{
+		{ClaimID: claim.ClaimID, ChargebackID: chargeback.ChargebackID},
+	}}, &status)
+	assert.Equal(t, imageDocNotApplicable, status.ChargebackResponseList[0].Status)
+}
+
+func TestSimulator_QueueContentPaging(t *testing.T) {
+	sim, clock := setupSimulator(t, Options{PageSize: 2})
+
+	for i := 0; i < 3; i++ {
+		doJSON(t, sim, http.MethodPost, "/__sim/chargebacks", SeedChargebackRequest{}, nil)
+		clock.now = clock.now.Add(30 * time.Second)
+	}
+
+	request := mastercom.GetQueueContentRequest{
+		QueueName:            queueUnworked,
+		LastModifiedDateFrom: "2024-07-16T12:00",
+		LastModifiedDateTo:   "2024-07-16T12:01",
+		PageNb:               "1",
+	}
+	var content mastercom.QueueContentSummary
+	doJSON(t, sim, http.MethodPost, "/v6/queues", request, &content)
+	assert.Equal(t, "2", content.PageCount)
+	assert.Len(t, content.ClaimList, 2)
+
+	request.PageNb = "2"
+	doJSON(t, sim, http.MethodPost, "/v6/queues", request, &content)
+	require.Len(t, content.ClaimList, 1)
+	assert.Equal(t, "2024-07-16T12:01:00", content.ClaimList[0].LastModifiedDate)
+
+	// The range is inclusive at minute precision
+	request = mastercom.GetQueueContentRequest{QueueName: queueUnworked, LastModifiedDateFrom: "2024-07-16T12:01"}
+	doJSON(t, sim, http.MethodPost, "/v6/queues", request, &content)
+	assert.Equal(t, "1", content.PageCount)
+
+	request.LastModifiedDateFrom = "2024-07-16 12:01"
+	w := doJSON(t, sim, http.MethodPost, "/v6/queues", request, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(t, sim, http.MethodPost, "/v6/queues", mastercom.GetQueueContentRequest{QueueName: "Unknown"}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSimulator_ReconReportDelay(t *testing.T) {
+	sim, clock := setupSimulator(t, Options{ReconDelay: 10 * time.Second})
+	doJSON(t, sim, http.MethodPost, "/__sim/chargebacks", SeedChargebackRequest{IssuerICA: "123456"}, nil)
+	doJSON(t, sim, http.MethodPost, "/__sim/chargebacks", SeedChargebackRequest{IssuerICA: "999999"}, nil)
+
+	var requested mastercom.ReconReportDataAcknowledgeResponse
+	w := doJSON(t, sim, http.MethodPost, "/v6/reconreport/data/request", mastercom.ReconReportDataAcknowledgeRequest{
+		ICA: []string{"123456"}, StartDate: "2024-07-16", EndDate: "2024-07-16",
+	}, &requested)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report mastercom.ReconReportDataRetrivalResponse
+	doJSON(t, sim, http.MethodPost, "/v6/reconreport/data/retrieval/"+requested.ReportIdentifier, nil, &report)
+	assert.Equal(t, reconUnavailable, report.Status)
+	assert.Empty(t, report.Data)
+
+	clock.now = clock.now.Add(10 * time.Second)
+	doJSON(t, sim, http.MethodPost, "/v6/reconreport/data/retrieval/"+requested.ReportIdentifier, nil, &report)
+	assert.Equal(t, reconAvailable, report.Status)
+
+	data, err := base64.StdEncoding.DecodeString(report.Data)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[1]), "123456")
+
+	w = doJSON(t, sim, http.MethodPost, "/v6/reconreport/data/retrieval/unknown", nil, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(t, sim, http.MethodPost, "/v6/reconreport/data/request", mastercom.ReconReportDataAcknowledgeRequest{StartDate: "2024-07-16", EndDate: "2024-07-15"}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSimulator_Faults(t *testing.T) {
+	sim, clock := setupSimulator(t, Options{})
+	var slept time.Duration
+	sim.faults.sleep = func(c *gin.Context, d time.Duration) { slept += d }
+
+	// A server error for the next request only
+	var rule FaultRule
+	w := doJSON(t, sim, http.MethodPost, "/__sim/faults", FaultRule{Method: "GET", PathPrefix: "/v6/queues", StatusCode: http.StatusServiceUnavailable, Times: 1}, &rule)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.NotEmpty(t, rule.ID)
+
+	w = doJSON(t, sim, http.MethodGet, "/v6/queues/names", nil, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.True(t, decodeErrors(t, w).Errors[0].Recoverable)
+
+	w = doJSON(t, sim, http.MethodGet, "/v6/queues/names", nil, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var rules []FaultRule
+	doJSON(t, sim, http.MethodGet, "/__sim/faults", nil, &rules)
+	assert.Empty(t, rules)
+
+	// Latency
+	doJSON(t, sim, http.MethodPost, "/__sim/faults", FaultRule{ID: "slow", LatencyMs: 250}, nil)
+	doJSON(t, sim, http.MethodGet, "/v6/queues/names", nil, nil)
+	assert.Equal(t, 250*time.Millisecond, slept)
+	w = doJSON(t, sim, http.MethodDelete, "/__sim/faults/slow", nil, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(t, sim, http.MethodDelete, "/__sim/faults/slow", nil, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Rate limiting
+	doJSON(t, sim, http.MethodPost, "/__sim/faults", FaultRule{RateLimit: 2, WindowMs: 1000}, nil)
+	assert.Equal(t, http.StatusOK, doJSON(t, sim, http.MethodGet, "/v6/queues/names", nil, nil).Code)
+	clock.now = clock.now.Add(400 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, doJSON(t, sim, http.MethodGet, "/v6/queues/names", nil, nil).Code)
+	w = doJSON(t, sim, http.MethodGet, "/v6/queues/names", nil, nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	clock.now = clock.now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, doJSON(t, sim, http.MethodGet, "/v6/queues/names", nil, nil).Code)
+
+	// Admin requests are never faulted
+	assert.Equal(t, http.StatusOK, doJSON(t, sim, http.MethodGet, "/__sim/faults", nil, nil).Code)
+
+	w = doJSON(t, sim, http.MethodPost, "/__sim/faults", FaultRule{StatusCode: 200}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Equal(t, http.StatusNoContent, doJSON(t, sim, http.MethodDelete, "/__sim/faults", nil, nil).Code)
+	doJSON(t, sim, http.MethodGet, "/__sim/faults", nil, &rules)
+	assert.Empty(t, rules)
+}
+
+func TestSimulator_ResetRestoresStartupFaults(t *testing.T) {
+	sim, _ := setupSimulator(t, Options{Faults: []FaultRule{{ID: "startup", PathPrefix: "/v6/healthcheck", StatusCode: http.StatusInternalServerError}}})
+
+	doJSON(t, sim, http.MethodPost, "/__sim/chargebacks", SeedChargebackRequest{}, nil)
+	doJSON(t, sim, http.MethodDelete, "/__sim/faults", nil, nil)
+
+	assert.Equal(t, http.StatusNoContent, doJSON(t, sim, http.MethodPost, "/__sim/reset", nil, nil).Code)
+
+	var rules []FaultRule
+	doJSON(t, sim, http.MethodGet, "/__sim/faults", nil, &rules)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "startup", rules[0].ID)
+
+	var unworked []mastercom.ClaimSummary
+	doJSON(t, sim, http.MethodGet, "/v6/queues?queue-name=Unworked", nil, &unworked)
+	assert.Empty(t, unworked)
+
+	_, err := New(Options{Faults: []FaultRule{{ID: "empty"}}}, nil)
+	assert.Error(t, err)
+}
+
+func TestSimulator_SpecSamples(t *testing.T) {
+	sim, _ := setupSimulator(t, Options{SpecPath: "../../mastercom-swagger.yaml", RequireOAuth: true})
+
+	// Unsigned requests are rejected
+	w := doJSON(t, sim, http.MethodGet, "/v6/healthcheck", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/v6/healthcheck", nil)
+	req.Header.Set("Authorization", `OAuth oauth_consumer_key="key"`)
+	w = httptest.NewRecorder()
+	sim.Handler().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var health []map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Equal(t, "true", health[0]["status"])
+}
@@ -0,0 +1,95 @@
+package simulator
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// specOperation is an operation from the OpenAPI spec and its sample response
+type specOperation struct {
+	method   string
+	path     string
+	response string
+}
+
+// specSample is one entry of an operation's x-samples extension
+type specSample struct {
+	Name     string            `yaml:"name"`
+	Response map[string]string `yaml:"response"`
+}
+
+type specOperationObject struct {
+	Samples []specSample `yaml:"x-samples"`
+}
+
+type specDocument struct {
+	Paths map[string]map[string]yaml.Node `yaml:"paths"`
+}
+
+var specMethods = map[string]string{
+	"get":    http.MethodGet,
+	"put":    http.MethodPut,
+	"post":   http.MethodPost,
+	"delete": http.MethodDelete,
+	"patch":  http.MethodPatch,
+}
+
+// loadSpecOperations reads every operation in an OpenAPI spec with its first sample's
+// JSON response
+func loadSpecOperations(path string) ([]specOperation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read spec: %w", err)
+	}
+
+	var doc specDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse spec %s: %w", path, err)
+	}
+
+	var operations []specOperation
+	for specPath, item := range doc.Paths {
+		for key, node := range item {
+			method, ok := specMethods[strings.ToLower(key)]
+			if !ok {
+				continue
+			}
+
+			var object specOperationObject
+			if err := node.Decode(&object); err != nil {
+				return nil, fmt.Errorf("parse %s %s: %w", key, specPath, err)
+			}
+
+			operation := specOperation{method: method, path: specPath, response: "{}"}
+			if len(object.Samples) > 0 {
+				if response, ok := object.Samples[0].Response["application/json"]; ok {
+					operation.response = response
+				}
+			}
+			operations = append(operations, operation)
+		}
+	}
+
+	// Registration order must not depend on map iteration
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].path != operations[j].path {
+			return operations[i].path < operations[j].path
+		}
+		return operations[i].method < operations[j].method
+	})
+	return operations, nil
+}
+
+// sampleHandler serves an operation's sample response
+func sampleHandler(operation specOperation) gin.HandlerFunc {
+	body := []byte(operation.response)
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", body)
+	}
+}
@@ -0,0 +1,256 @@
+package simulator
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mastercom-service/pkg/mastercom"
+)
+
+// Queue names, matching the queues Mastercom returns
+const (
+	queueUnworked  = "Unworked"
+	queueWorked    = "Worked"
+	queueSubmitted = "Submitted"
+	queueRejects   = "Rejects"
+	queueClosed    = "Closed"
+)
+
+var queues = []mastercom.Queue{
+	{QueueName: queueUnworked, QueueDescription: "Unworked Disputes"},
+	{QueueName: queueWorked, QueueDescription: "Worked Disputes"},
+	{QueueName: queueSubmitted, QueueDescription: "Submitted Disputes"},
+	{QueueName: queueRejects, QueueDescription: "Rejected Disputes"},
+	{QueueName: queueClosed, QueueDescription: "Closed Disputes"},
+}
+
+// Image statuses reported for case filing and chargeback documents
+const (
+	imageCompleted        = "COMPLETED"
+	imagePending          = "PENDING"
+	imageFailed           = "FAILED"
+	imageUnavailable      = "UNAVAILABLE"
+	imageUnprocessed      = "UNPROCESSED"
+	imageDocNotApplicable = "DOC_NOT_APPLICABLE"
+)
+
+const (
+	dateLayout         = "2006-01-02"
+	dateTimeLayout     = "2006-01-02T15:04:05"
+	queueRangeLayout   = "2006-01-02T15:04"
+	claimTypeStandard  = "Standard"
+	claimTypeCaseFiled = "CaseFiling"
+)
+
+// supportedImageTypes are the attachment types Mastercom converts
+var supportedImageTypes = map[string]bool{".pdf": true, ".jpg": true, ".jpeg": true, ".tif": true, ".tiff": true, ".zip": true}
+
+type state struct {
+	claims      map[string]*simClaim
+	chargebacks map[string]*simChargeback
+	cases       map[string]*simCase
+	reports     map[string]*simReport
+
+	nextClaimID      int64
+	nextChargebackID int64
+	nextCaseID       int64
+}
+
+func newState() *state {
+	return &state{
+		claims:           make(map[string]*simClaim),
+		chargebacks:      make(map[string]*simChargeback),
+		cases:            make(map[string]*simCase),
+		reports:          make(map[string]*simReport),
+		nextClaimID:      200000000001,
+		nextChargebackID: 300000000001,
+		nextCaseID:       9000000001,
+	}
+}
+
+func (st *state) newClaimID() string {
+	id := fmt.Sprintf("%d", st.nextClaimID)
+	st.nextClaimID++
+	return id
+}
+
+func (st *state) newChargebackID() string {
+	id := fmt.Sprintf("%d", st.nextChargebackID)
+	st.nextChargebackID++
+	return id
+}
+
+func (st *state) newCaseID() string {
+	id := fmt.Sprintf("%d", st.nextCaseID)
+	st.nextCaseID++
+	return id
+}
+
+// simClaim is a claim and its position in the queues
+type simClaim struct {
+	id                string
+	claimType         string
+	amount            string
+	currency          string
+	transactionID     string
+	primaryAccountNum string
+	acquirerRefNum    string
+	issuerID          string
+	acquirerID        string
+	isIssuer          bool
+	queueName         string
+	isOpen            bool
+	createdAt         time.Time
+	lastModified      time.Time
+	chargebackIDs     []string
+	caseID            string
+}
+
+// move puts the claim in a queue, closing or reopening it to match
+func (cl *simClaim) move(queueName string, now time.Time) {
+	cl.queueName = queueName
+	cl.isOpen = queueName != queueClosed
+	cl.lastModified = now
+}
+
+func (cl *simClaim) summary() mastercom.ClaimSummary {
+	return mastercom.ClaimSummary{
+		AcquirerID:        cl.acquirerID,
+		AcquirerRefNum:    cl.acquirerRefNum,
+		PrimaryAccountNum: cl.primaryAccountNum,
+		ClaimID:           cl.id,
+		ClaimType:         cl.claimType,
+		ClaimValue:        strings.TrimSpace(cl.amount + " " + cl.currency),
+		ClearingNetwork:   "GCMS",
+		CreateDate:        cl.createdAt.Format(dateLayout),
+		DueDate:           cl.createdAt.AddDate(0, 0, 45).Format(dateLayout),
+		TransactionID:     cl.transactionID,
+		IsAccurate:        true,
+		IsAcquirer:        !cl.isIssuer,
+		IsIssuer:          cl.isIssuer,
+		IsOpen:            cl.isOpen,
+		IssuerID:          cl.issuerID,
+		LastModifiedBy:    "simulator",
+		LastModifiedDate:  cl.lastModified.Format(dateTimeLayout),
+		QueueName:         cl.queueName,
+	}
+}
+
+// simChargeback is a chargeback or second presentment on a claim
+type simChargeback struct {
+	details mastercom.ChargebackDetails
+	// inbound chargebacks were raised by the counterparty and can be acknowledged
+	inbound      bool
+	acknowledged bool
+	attachment   *mastercom.DocumentStructure
+	uploadedAt   time.Time
+	createdAt    time.Time
+}
+
+// simCase is a case filing
+type simCase struct {
+	details    mastercom.CaseFilingDetails
+	claimID    string
+	status     string
+	history    []mastercom.CaseFilingRespHistory
+	attachment *mastercom.DocumentStructure
+	uploadedAt time.Time
+	createdAt  time.Time
+}
+
+// simReport is a requested reconciliation report
+type simReport struct {
+	request     mastercom.ReconReportDataAcknowledgeRequest
+	requestedAt time.Time
+}
+
+// imageStatus returns the processing status of an attachment uploaded at uploadedAt
+func imageStatus(attachment *mastercom.DocumentStructure, uploadedAt, now time.Time, delay time.Duration) string {
+	if attachment == nil {
+		return imageUnavailable
+	}
+	if !supportedImageTypes[strings.ToLower(filepath.Ext(attachment.Filename))] {
+		return imageFailed
+	}
+	if now.Sub(uploadedAt) < delay {
+		return imagePending
+	}
+	return imageCompleted
+}
+
+// validateAttachment checks a document is base64 encoded and has a file name
+func validateAttachment(attachment *mastercom.DocumentStructure) error {
+	if attachment == nil {
+		return nil
+	}
+	if attachment.Filename == "" {
+		return fmt.Errorf("fileAttachment.filename is required")
+	}
+	if _, err := base64.StdEncoding.DecodeString(attachment.File); err != nil {
+		return fmt.Errorf("fileAttachment.file is not base64 encoded")
+	}
+	return nil
+}
+
+// zippedAttachment returns an attachment as Mastercom serves documents: a base64 encoded ZIP
+func zippedAttachment(name string, attachment *mastercom.DocumentStructure) (*mastercom.DocumentStructureResp, error) {
+	content, err := base64.StdEncoding.DecodeString(attachment.File)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(attachment.Filename), ".zip") {
+		return &mastercom.DocumentStructureResp{Filename: name + ".zip", File: attachment.File}, nil
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	entry, err := archive.Create(attachment.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := entry.Write(content); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return &mastercom.DocumentStructureResp{
+		Filename: name + ".zip",
+		File:     base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// claimsInQueue returns the claims in a queue, least recently modified first
+func (st *state) claimsInQueue(queueName string) []*simClaim {
+	var claims []*simClaim
+	for _, claim := range st.claims {
+		if claim.queueName == queueName {
+			claims = append(claims, claim)
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		if !claims[i].lastModified.Equal(claims[j].lastModified) {
+			return claims[i].lastModified.Before(claims[j].lastModified)
+		}
+		return claims[i].id < claims[j].id
+	})
+	return claims
+}
+
+func isQueue(name string) bool {
+	for _, queue := range queues {
+		if queue.QueueName == name {
+			return true
+		}
+	}
+	return false
+}
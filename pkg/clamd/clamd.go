@@ -0,0 +1,216 @@
+// Package clamd is a client for the ClamAV daemon. It speaks the clamd socket protocol over
+// TCP or a Unix socket, streaming content to the daemon with INSTREAM so that the daemon
+// does not need access to the files being scanned.
+package clamd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds a command when its context has no deadline
+	DefaultTimeout = 30 * time.Second
+	// chunkSize is the size of the INSTREAM chunks sent to the daemon
+	chunkSize = 64 * 1024
+)
+
+var (
+	// ErrSizeLimitExceeded is returned when content is larger than the daemon's StreamMaxLength
+	ErrSizeLimitExceeded = errors.New("clamd: stream size limit exceeded")
+	// ErrScanFailed is returned when the daemon reports an error instead of a verdict
+	ErrScanFailed = errors.New("clamd: scan failed")
+	// ErrUnexpectedReply is returned when the daemon's reply cannot be understood
+	ErrUnexpectedReply = errors.New("clamd: unexpected reply")
+
+	errReadContent = errors.New("reading content")
+)
+
+// Result is the daemon's verdict on scanned content
+type Result struct {
+	// Infected is set when the daemon found malware
+	Infected bool
+	// Signature names the malware found
+	Signature string
+}
+
+// Client sends commands to a clamd daemon, opening a connection per command
+type Client struct {
+	network string
+	address string
+	timeout time.Duration
+	dialer  net.Dialer
+}
+
+// NewClient creates a client for the daemon at address, given as tcp://host:port,
+// unix:///path/to/clamd.sock, host:port or an absolute socket path. A zero timeout uses
+// DefaultTimeout.
+func NewClient(address string, timeout time.Duration) (*Client, error) {
+	network, addr, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{network: network, address: addr, timeout: timeout}, nil
+}
+
+func parseAddress(address string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	case strings.HasPrefix(address, "unix://"):
+		return "unix", strings.TrimPrefix(address, "unix://"), nil
+	case strings.HasPrefix(address, "/"):
+		return "unix", address, nil
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return "", "", fmt.Errorf("clamd: invalid address %q: %w", address, err)
+	}
+	return "tcp", address, nil
+}
+
+// Ping checks that the daemon is reachable and answering
+func (c *Client) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("%w: %q", ErrUnexpectedReply, reply)
+	}
+	return nil
+}
+
+// Version returns the daemon's engine and signature database versions
+func (c *Client) Version(ctx context.Context) (string, error) {
+	return c.command(ctx, "VERSION", nil)
+}
+
+// Scan streams content to the daemon and returns its verdict
+func (c *Client) Scan(ctx context.Context, content io.Reader) (*Result, error) {
+	reply, err := c.command(ctx, "INSTREAM", content)
+	if err != nil {
+		return nil, err
+	}
+	return parseScanReply(reply)
+}
+
+// parseScanReply reads a reply such as "stream: OK" or "stream: Eicar-Signature FOUND"
+func parseScanReply(reply string) (*Result, error) {
+	if strings.HasPrefix(reply, "INSTREAM size limit exceeded") {
+		return nil, ErrSizeLimitExceeded
+	}
+	if strings.HasSuffix(reply, " ERROR") {
+		return nil, fmt.Errorf("%w: %s", ErrScanFailed, strings.TrimSuffix(reply, " ERROR"))
+	}
+
+	_, verdict, ok := strings.Cut(reply, ": ")
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("%w: %q", ErrUnexpectedReply, reply)
+	case verdict == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnexpectedReply, reply)
+	}
+}
+
+// command sends a null-terminated command, followed by content as INSTREAM chunks when given,
+// and returns the daemon's null-terminated reply
+func (c *Client) command(ctx context.Context, name string, content io.Reader) (string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	conn, err := c.dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	// Unblock reads and writes as soon as the context is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if _, err := conn.Write([]byte("z" + name + "\x00")); err != nil {
+		return "", c.connError(ctx, err)
+	}
+	if content != nil {
+		// The daemon replies and closes the connection early when the stream is too large,
+		// so a failed write is reported through the reply when there is one
+		if err := writeChunks(conn, content); err != nil {
+			if errors.Is(err, errReadContent) {
+				return "", fmt.Errorf("clamd: %w", err)
+			}
+			if reply, readErr := readReply(conn); readErr == nil {
+				return reply, nil
+			}
+			return "", c.connError(ctx, err)
+		}
+	}
+
+	reply, err := readReply(conn)
+	if err != nil {
+		return "", c.connError(ctx, err)
+	}
+	return reply, nil
+}
+
+// writeChunks sends content as length-prefixed chunks ending with a zero-length chunk
+func writeChunks(w io.Writer, content io.Reader) error {
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(content, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, writeErr := w.Write(buf[:4+n]); writeErr != nil {
+				return writeErr
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", errReadContent, err)
+		}
+	}
+	_, err := w.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+func readReply(r io.Reader) (string, error) {
+	reply, err := bufio.NewReader(r).ReadBytes(0)
+	if err != nil && (len(reply) == 0 || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
+
+// connError prefers the context's error when it caused the connection to fail
+func (c *Client) connError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("clamd: %w", ctxErr)
+	}
+	// The connection deadline can pass a moment before the context notices its own
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return fmt.Errorf("clamd: %w", context.DeadlineExceeded)
+	}
+	return fmt.Errorf("clamd: %w", err)
+}
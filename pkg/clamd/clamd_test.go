@@ -0,0 +1,228 @@
+package clamd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eicar is the EICAR anti-malware test file
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeDaemon answers the clamd protocol, flagging streams that contain the EICAR test file
+type fakeDaemon struct {
+	listener net.Listener
+
+	mutex     sync.Mutex
+	maxStream int
+	hang      bool
+	reply     string
+	commands  []string
+	streamed  []byte
+}
+
+func startFakeDaemon(t *testing.T, network, address string) *fakeDaemon {
+	listener, err := net.Listen(network, address)
+	require.NoError(t, err)
+	daemon := &fakeDaemon{listener: listener, maxStream: 1 << 20}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go daemon.serve(conn)
+		}
+	}()
+	return daemon
+}
+
+func (d *fakeDaemon) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	command, err := reader.ReadString(0)
+	if err != nil {
+		return
+	}
+	command = strings.TrimSuffix(command, "\x00")
+	d.mutex.Lock()
+	d.commands = append(d.commands, command)
+	hang, reply, maxStream := d.hang, d.reply, d.maxStream
+	d.mutex.Unlock()
+
+	if hang {
+		io.Copy(io.Discard, conn)
+		return
+	}
+
+	switch command {
+	case "zPING":
+		reply = "PONG"
+	case "zVERSION":
+		reply = "ClamAV 1.3.1/27400/Tue Sep 24 08:00:00 2024"
+	case "zINSTREAM":
+		var streamed []byte
+		for {
+			var size uint32
+			if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if len(streamed)+int(size) > maxStream {
+				conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+				return
+			}
+			chunk := make([]byte, size)
+			if _, err := io.ReadFull(reader, chunk); err != nil {
+				return
+			}
+			streamed = append(streamed, chunk...)
+		}
+		d.mutex.Lock()
+		d.streamed = streamed
+		d.mutex.Unlock()
+
+		if reply == "" {
+			reply = "stream: OK"
+			if bytes.Contains(streamed, []byte(eicar)) {
+				reply = "stream: Eicar-Test-Signature FOUND"
+			}
+		}
+	default:
+		reply = "UNKNOWN COMMAND"
+	}
+	conn.Write([]byte(reply + "\x00"))
+}
+
+// set changes the daemon's behaviour between commands
+func (d *fakeDaemon) set(change func(d *fakeDaemon)) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	change(d)
+}
+
+func newTestClient(t *testing.T, daemon *fakeDaemon) *Client {
+	address := daemon.listener.Addr().String()
+	if daemon.listener.Addr().Network() == "unix" {
+		address = "unix://" + address
+	}
+	client, err := NewClient(address, time.Second)
+	require.NoError(t, err)
+	return client
+}
+
+func TestNewClient_Address(t *testing.T) {
+	for address, want := range map[string][2]string{
+		"tcp://clamav:3310":           {"tcp", "clamav:3310"},
+		"clamav:3310":                 {"tcp", "clamav:3310"},
+		"unix:///run/clamd/clamd.ctl": {"unix", "/run/clamd/clamd.ctl"},
+		"/run/clamd/clamd.ctl":        {"unix", "/run/clamd/clamd.ctl"},
+	} {
+		client, err := NewClient(address, 0)
+		require.NoError(t, err, address)
+		assert.Equal(t, want, [2]string{client.network, client.address}, address)
+		assert.Equal(t, DefaultTimeout, client.timeout)
+	}
+
+	_, err := NewClient("clamav", 0)
+	assert.Error(t, err)
+}
+
+func TestClient_Scan(t *testing.T) {
+	daemon := startFakeDaemon(t, "tcp", "127.0.0.1:0")
+	client := newTestClient(t, daemon)
+
+	require.NoError(t, client.Ping(context.Background()))
+	version, err := client.Version(context.Background())
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(version, "ClamAV "))
+
+	result, err := client.Scan(context.Background(), strings.NewReader("%PDF-1.4 clean evidence"))
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = client.Scan(context.Background(), strings.NewReader("%PDF-1.4 "+eicar))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+
+	// Content spanning several chunks arrives intact
+	large := bytes.Repeat([]byte("0123456789"), chunkSize/4)
+	_, err = client.Scan(context.Background(), bytes.NewReader(large))
+	require.NoError(t, err)
+	daemon.set(func(d *fakeDaemon) {
+		assert.Equal(t, large, d.streamed)
+		assert.Equal(t, []string{"zPING", "zVERSION", "zINSTREAM", "zINSTREAM", "zINSTREAM"}, d.commands)
+	})
+}
+
+func TestClient_ScanUnixSocket(t *testing.T) {
+	daemon := startFakeDaemon(t, "unix", filepath.Join(t.TempDir(), "clamd.sock"))
+	client := newTestClient(t, daemon)
+
+	result, err := client.Scan(context.Background(), strings.NewReader(eicar))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+}
+
+func TestClient_ScanErrors(t *testing.T) {
+	daemon := startFakeDaemon(t, "tcp", "127.0.0.1:0")
+	client := newTestClient(t, daemon)
+
+	daemon.set(func(d *fakeDaemon) { d.maxStream = 1024 })
+	_, err := client.Scan(context.Background(), bytes.NewReader(make([]byte, 4*chunkSize)))
+	assert.ErrorIs(t, err, ErrSizeLimitExceeded)
+
+	daemon.set(func(d *fakeDaemon) { d.maxStream, d.reply = 1<<20, "Can't allocate memory ERROR" })
+	_, err = client.Scan(context.Background(), strings.NewReader("content"))
+	assert.ErrorIs(t, err, ErrScanFailed)
+	assert.Contains(t, err.Error(), "Can't allocate memory")
+
+	daemon.set(func(d *fakeDaemon) { d.reply = "garbled" })
+	_, err = client.Scan(context.Background(), strings.NewReader("content"))
+	assert.ErrorIs(t, err, ErrUnexpectedReply)
+}
+
+func TestClient_Timeout(t *testing.T) {
+	daemon := startFakeDaemon(t, "tcp", "127.0.0.1:0")
+	daemon.set(func(d *fakeDaemon) { d.hang = true })
+	client := newTestClient(t, daemon)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Scan(ctx, strings.NewReader("content"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	client, err := NewClient(address, time.Second)
+	require.NoError(t, err)
+	err = client.Ping(context.Background())
+	require.Error(t, err)
+	var opErr *net.OpError
+	assert.True(t, errors.As(err, &opErr))
+}
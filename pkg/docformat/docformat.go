@@ -0,0 +1,51 @@
+// Package docformat identifies document formats from their content and inspects the formats
+// accepted as case evidence.
+package docformat
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// MIME types of the document formats this package inspects
+const (
+	MIMETypePDF  = "application/pdf"
+	MIMETypeTIFF = "image/tiff"
+	MIMETypeJPEG = "image/jpeg"
+	MIMETypePNG  = "image/png"
+)
+
+var (
+	// ErrUnsupportedFormat is returned when a format cannot be inspected
+	ErrUnsupportedFormat = errors.New("docformat: unsupported format")
+	// ErrMalformed is returned when content does not follow the structure of its format
+	ErrMalformed = errors.New("docformat: malformed document")
+	// ErrUnsupportedConversion is returned when a document cannot be converted to the
+	// requested format, such as a PDF into a TIFF, which would need a PDF renderer
+	ErrUnsupportedConversion = errors.New("docformat: unsupported conversion")
+)
+
+// Detect returns the MIME type of content from its signature, without parameters. Content
+// that matches no known signature is application/octet-stream.
+func Detect(content []byte) string {
+	detected := mimetype.Detect(content).String()
+	if i := strings.IndexByte(detected, ';'); i >= 0 {
+		detected = detected[:i]
+	}
+	return detected
+}
+
+// PageCount returns the number of pages in a PDF or TIFF document. Other formats return
+// ErrUnsupportedFormat.
+func PageCount(mimeType string, content []byte) (int, error) {
+	switch mimeType {
+	case MIMETypePDF:
+		return pdfPageCount(content)
+	case MIMETypeTIFF:
+		return tiffPageCount(content)
+	default:
+		return 0, ErrUnsupportedFormat
+	}
+}
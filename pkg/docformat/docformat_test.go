@@ -0,0 +1,119 @@
+package docformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPDF builds an uncompressed PDF with the given number of pages
+func testPDF(pages int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	kids := ""
+	for i := 0; i < pages; i++ {
+		kids += fmt.Sprintf("%d 0 R ", i+3)
+	}
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", kids, pages)
+	for i := 0; i < pages; i++ {
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>\nendobj\n", i+3)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+// testTIFF builds a little or big endian TIFF with the given number of directories
+func testTIFF(order binary.ByteOrder, pages int) []byte {
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(8))
+	for i := 0; i < pages; i++ {
+		// One ImageWidth entry per directory
+		binary.Write(&buf, order, uint16(1))
+		binary.Write(&buf, order, []uint16{256, 3})
+		binary.Write(&buf, order, []uint32{1, 1})
+		next := uint32(0)
+		if i < pages-1 {
+			next = uint32(buf.Len() + 4)
+		}
+		binary.Write(&buf, order, next)
+	}
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	var jpegBuf, pngBuf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpegBuf, img, nil))
+	require.NoError(t, png.Encode(&pngBuf, img))
+
+	assert.Equal(t, MIMETypePDF, Detect(testPDF(1)))
+	assert.Equal(t, MIMETypeTIFF, Detect(testTIFF(binary.BigEndian, 1)))
+	assert.Equal(t, MIMETypeJPEG, Detect(jpegBuf.Bytes()))
+	assert.Equal(t, MIMETypePNG, Detect(pngBuf.Bytes()))
+	assert.Equal(t, "application/vnd.microsoft.portable-executable", Detect(append([]byte("MZ\x90\x00"), make([]byte, 60)...)))
+	assert.Equal(t, "text/plain", Detect([]byte("not a document")))
+}
+
+func TestPageCount_PDF(t *testing.T) {
+	pages, err := PageCount(MIMETypePDF, testPDF(3))
+	require.NoError(t, err)
+	assert.Equal(t, 3, pages)
+
+	// A page tree stored in a compressed object stream is found after inflating it
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte("<< /Type /Pages /Kids [4 0 R 5 0 R] /Count 7 >> << /Type /Page /Parent 2 0 R >>"))
+	writer.Close()
+	var doc bytes.Buffer
+	fmt.Fprintf(&doc, "%%PDF-1.5\n3 0 obj\n<< /Type /ObjStm /Filter /FlateDecode /Length %d >>\nstream\n", compressed.Len())
+	doc.Write(compressed.Bytes())
+	doc.WriteString("\nendstream\nendobj\n%%EOF\n")
+
+	pages, err = PageCount(MIMETypePDF, doc.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 7, pages)
+
+	_, err = PageCount(MIMETypePDF, []byte("%PDF-1.4\n%%EOF"))
+	assert.ErrorIs(t, err, ErrMalformed)
+	_, err = PageCount(MIMETypePDF, []byte("MZ"))
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestPageCount_TIFF(t *testing.T) {
+	pages, err := PageCount(MIMETypeTIFF, testTIFF(binary.LittleEndian, 4))
+	require.NoError(t, err)
+	assert.Equal(t, 4, pages)
+
+	pages, err = PageCount(MIMETypeTIFF, testTIFF(binary.BigEndian, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 1, pages)
+
+	// A directory that points back at itself is rejected rather than followed forever
+	looped := testTIFF(binary.LittleEndian, 1)
+	binary.LittleEndian.PutUint32(looped[len(looped)-4:], 8)
+	_, err = PageCount(MIMETypeTIFF, looped)
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	truncated := testTIFF(binary.LittleEndian, 2)
+	_, err = PageCount(MIMETypeTIFF, truncated[:len(truncated)-6])
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestPageCount_Unsupported(t *testing.T) {
+	_, err := PageCount(MIMETypeJPEG, []byte{0xff, 0xd8, 0xff})
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
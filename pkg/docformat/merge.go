@@ -0,0 +1,244 @@
+package docformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"strconv"
+)
+
+// Source is a document or image to be merged into a bundle, in page order. Image is used
+// instead of Content when set.
+type Source struct {
+	MIMEType string
+	Content  []byte
+	Image    image.Image
+}
+
+// US Letter in PDF points
+const (
+	letterWidth  = 612.0
+	letterHeight = 792.0
+)
+
+// MergePDF merges sources into a single PDF: the pages of PDF sources are copied as they
+// are, and each image becomes a page of its own. TIFF sources cannot be merged into a PDF.
+func MergePDF(sources []Source) ([]byte, error) {
+	writer := &pdfWriter{}
+	pagesRef := writer.alloc()
+	var kids pdfArray
+
+	for index, source := range sources {
+		var err error
+		switch {
+		case source.Image != nil:
+			var ref pdfRef
+			ref, err = addImagePage(writer, pagesRef, source.Image, nil)
+			kids = append(kids, ref)
+		case source.MIMEType == MIMETypePDF:
+			var refs []pdfRef
+			refs, err = importPDFPages(writer, pagesRef, source.Content)
+			for _, ref := range refs {
+				kids = append(kids, ref)
+			}
+		case source.MIMEType == MIMETypeJPEG:
+			var config image.Config
+			config, err = jpeg.DecodeConfig(bytes.NewReader(source.Content))
+			if err == nil {
+				var ref pdfRef
+				ref, err = addImagePage(writer, pagesRef, nil, &jpegImage{config: config, data: source.Content})
+				kids = append(kids, ref)
+			}
+		case source.MIMEType == MIMETypePNG:
+			var img image.Image
+			img, _, err = image.Decode(bytes.NewReader(source.Content))
+			if err == nil {
+				var ref pdfRef
+				ref, err = addImagePage(writer, pagesRef, img, nil)
+				kids = append(kids, ref)
+			}
+		default:
+			err = fmt.Errorf("%w: %s into PDF", ErrUnsupportedConversion, source.MIMEType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("source %d: %w", index+1, err)
+		}
+	}
+
+	return writePageTree(writer, pagesRef, kids), nil
+}
+
+// writePageTree fills in the page tree root reserved as pagesRef with kids, and writes the
+// document with a catalog pointing at it
+func writePageTree(writer *pdfWriter, pagesRef pdfRef, kids pdfArray) []byte {
+	pages := newPDFDict()
+	pages.set("/Type", pdfName("/Pages"))
+	pages.set("/Kids", kids)
+	pages.set("/Count", pdfRaw(strconv.Itoa(len(kids))))
+	writer.set(pagesRef, pages)
+
+	catalog := newPDFDict()
+	catalog.set("/Type", pdfName("/Catalog"))
+	catalog.set("/Pages", pagesRef)
+	return writer.bytes(writer.add(catalog))
+}
+
+// MergeTIFF merges sources into a single multi-page TIFF: the pages of TIFF sources are
+// copied as they are, and each image becomes an 8-bit grayscale page. PDF sources cannot be
+// merged into a TIFF.
+func MergeTIFF(sources []Source) ([]byte, error) {
+	var pages []tiffPage
+	for index, source := range sources {
+		var err error
+		switch {
+		case source.Image != nil:
+			var page tiffPage
+			page, err = grayTIFFPage(source.Image)
+			pages = append(pages, page)
+		case source.MIMEType == MIMETypeTIFF:
+			var copied []tiffPage
+			copied, err = readTIFFPages(source.Content)
+			pages = append(pages, copied...)
+		case source.MIMEType == MIMETypeJPEG || source.MIMEType == MIMETypePNG:
+			var img image.Image
+			img, _, err = image.Decode(bytes.NewReader(source.Content))
+			if err == nil {
+				var page tiffPage
+				page, err = grayTIFFPage(img)
+				pages = append(pages, page)
+			}
+		default:
+			err = fmt.Errorf("%w: %s into TIFF", ErrUnsupportedConversion, source.MIMEType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("source %d: %w", index+1, err)
+		}
+	}
+	return encodeTIFF(pages), nil
+}
+
+func importPDFPages(writer *pdfWriter, parent pdfRef, content []byte) ([]pdfRef, error) {
+	file, err := parsePDF(content)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := file.pages()
+	if err != nil {
+		return nil, err
+	}
+	return importPages(writer, parent, file, pages), nil
+}
+
+// importPages copies the pages of a parsed PDF into writer under parent
+func importPages(writer *pdfWriter, parent pdfRef, file *pdfFile, pages []pdfPage) []pdfRef {
+	importer := newPDFImporter(file, writer)
+	importer.reserve(pages)
+	refs := make([]pdfRef, 0, len(pages))
+	for _, page := range pages {
+		refs = append(refs, importer.importPage(page, parent))
+	}
+	return refs
+}
+
+// jpegImage is a JPEG embedded in a PDF without decoding it
+type jpegImage struct {
+	config image.Config
+	data   []byte
+}
+
+// addImagePage adds a page showing a decoded image, or a JPEG as is, at 150 dots per inch,
+// scaled down to fit a US Letter page when larger
+func addImagePage(writer *pdfWriter, parent pdfRef, img image.Image, jpg *jpegImage) (pdfRef, error) {
+	xobject := newPDFDict()
+	xobject.set("/Type", pdfName("/XObject"))
+	xobject.set("/Subtype", pdfName("/Image"))
+	xobject.set("/BitsPerComponent", pdfRaw("8"))
+
+	var width, height int
+	var data []byte
+	if jpg != nil {
+		width, height, data = jpg.config.Width, jpg.config.Height, jpg.data
+		xobject.set("/Filter", pdfName("/DCTDecode"))
+		switch jpg.config.ColorModel {
+		case color.GrayModel:
+			xobject.set("/ColorSpace", pdfName("/DeviceGray"))
+		case color.CMYKModel:
+			// Adobe CMYK JPEGs store inverted values
+			xobject.set("/ColorSpace", pdfName("/DeviceCMYK"))
+			xobject.set("/Decode", pdfArray{pdfRaw("1"), pdfRaw("0"), pdfRaw("1"), pdfRaw("0"), pdfRaw("1"), pdfRaw("0"), pdfRaw("1"), pdfRaw("0")})
+		default:
+			xobject.set("/ColorSpace", pdfName("/DeviceRGB"))
+		}
+	} else {
+		bounds := img.Bounds()
+		width, height = bounds.Dx(), bounds.Dy()
+		samples, colorSpace := imageSamples(img)
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(samples); err != nil {
+			return pdfRef{}, err
+		}
+		if err := zw.Close(); err != nil {
+			return pdfRef{}, err
+		}
+		data = compressed.Bytes()
+		xobject.set("/Filter", pdfName("/FlateDecode"))
+		xobject.set("/ColorSpace", pdfName(colorSpace))
+	}
+	if width <= 0 || height <= 0 {
+		return pdfRef{}, fmt.Errorf("%w: empty image", ErrMalformed)
+	}
+	xobject.set("/Width", pdfRaw(strconv.Itoa(width)))
+	xobject.set("/Height", pdfRaw(strconv.Itoa(height)))
+	imageRef := writer.add(&pdfStream{dict: xobject, data: data})
+
+	drawWidth, drawHeight := float64(width)*72/pageDPI, float64(height)*72/pageDPI
+	if scale := min(letterWidth/drawWidth, letterHeight/drawHeight); scale < 1 {
+		drawWidth, drawHeight = drawWidth*scale, drawHeight*scale
+	}
+	content := fmt.Sprintf("q %.2f 0 0 %.2f %.2f %.2f cm /Im0 Do Q",
+		drawWidth, drawHeight, (letterWidth-drawWidth)/2, (letterHeight-drawHeight)/2)
+	contentRef := writer.add(&pdfStream{dict: newPDFDict(), data: []byte(content)})
+
+	xobjects := newPDFDict()
+	xobjects.set("/Im0", imageRef)
+	resources := newPDFDict()
+	resources.set("/XObject", xobjects)
+
+	page := newPDFDict()
+	page.set("/Type", pdfName("/Page"))
+	page.set("/Parent", parent)
+	page.set("/MediaBox", pdfArray{pdfRaw("0"), pdfRaw("0"), pdfRaw("612"), pdfRaw("792")})
+	page.set("/Resources", resources)
+	page.set("/Contents", contentRef)
+	return writer.add(page), nil
+}
+
+// imageSamples returns the 8-bit samples of an image, gray for grayscale images and RGB
+// composited over white otherwise
+func imageSamples(img image.Image) ([]byte, string) {
+	bounds := img.Bounds()
+	if gray, ok := img.(*image.Gray); ok {
+		samples := make([]byte, 0, bounds.Dx()*bounds.Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			offset := gray.PixOffset(bounds.Min.X, y)
+			samples = append(samples, gray.Pix[offset:offset+bounds.Dx()]...)
+		}
+		return samples, "/DeviceGray"
+	}
+
+	samples := make([]byte, 0, 3*bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Transparent pixels are drawn over a white page
+			white := 0xFFFF - a
+			samples = append(samples, uint8((r+white)>>8), uint8((g+white)>>8), uint8((b+white)>>8))
+		}
+	}
+	return samples, "/DeviceRGB"
+}
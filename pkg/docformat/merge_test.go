@@ -0,0 +1,208 @@
+package docformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// objectStreamPDF builds a PDF 1.5 file whose page and catalog live in a compressed object
+// stream, with the page size inherited from the page tree and the trailer in an XRef stream
+func objectStreamPDF() []byte {
+	objects := "<< /Type /Catalog /Pages 2 0 R >> << /Type /Page /Parent 2 0 R /Contents 4 0 R >>"
+	header := "1 0 3 34 "
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte(header + objects))
+	writer.Close()
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.5\n")
+	doc.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 300 400] >>\nendobj\n")
+	doc.WriteString("4 0 obj\n<< /Length 8 >>\nstream\n0 0 m S \nendstream\nendobj\n")
+	fmt.Fprintf(&doc, "5 0 obj\n<< /Type /ObjStm /N 2 /First %d /Filter /FlateDecode /Length %d >>\nstream\n", len(header), compressed.Len())
+	doc.Write(compressed.Bytes())
+	doc.WriteString("\nendstream\nendobj\n")
+	doc.WriteString("6 0 obj\n<< /Type /XRef /Root 1 0 R /Size 7 /Length 0 >>\nstream\n\nendstream\nendobj\n%%EOF\n")
+	return doc.Bytes()
+}
+
+// bigEndianTIFF builds a one page, two pixel, uncompressed big endian TIFF
+func bigEndianTIFF() []byte {
+	var buf bytes.Buffer
+	order := binary.BigEndian
+	buf.WriteString("MM")
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(8))
+
+	entries := []struct {
+		tag, typ uint16
+		value    uint32
+	}{
+		{tagImageWidth, tiffShort, 2}, {tagImageLength, tiffShort, 1}, {tagBitsPerSample, tiffShort, 8},
+		{tagCompression, tiffShort, 1}, {tagPhotometric, tiffShort, 1}, {tagStripOffsets, tiffLong, 0},
+		{tagRowsPerStrip, tiffShort, 1}, {tagStripByteCounts, tiffLong, 2}, {tagXResolution, tiffRational, 0},
+	}
+	directoryEnd := 8 + 2 + 12*len(entries) + 4
+	binary.Write(&buf, order, uint16(len(entries)))
+	for _, entry := range entries {
+		binary.Write(&buf, order, []uint16{entry.tag, entry.typ})
+		binary.Write(&buf, order, uint32(1))
+		switch entry.tag {
+		case tagStripOffsets:
+			binary.Write(&buf, order, uint32(directoryEnd+8))
+		case tagXResolution:
+			binary.Write(&buf, order, uint32(directoryEnd))
+		default:
+			if entry.typ == tiffShort {
+				binary.Write(&buf, order, []uint16{uint16(entry.value), 0})
+			} else {
+				binary.Write(&buf, order, entry.value)
+			}
+		}
+	}
+	binary.Write(&buf, order, uint32(0))
+	binary.Write(&buf, order, []uint32{300, 1})
+	buf.Write([]byte{0x10, 0xF0})
+	return buf.Bytes()
+}
+
+func TestMergePDF(t *testing.T) {
+	cover := RenderTextPage([]string{"Case 123"})
+	merged, err := MergePDF([]Source{
+		{Image: cover},
+		{MIMEType: MIMETypePDF, Content: testPDF(2)},
+		{MIMEType: MIMETypeJPEG, Content: testJPEG(t, 3000, 200)},
+		{MIMEType: MIMETypePNG, Content: testPNG(t, 10, 10)},
+		{MIMEType: MIMETypePDF, Content: objectStreamPDF()},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, MIMETypePDF, Detect(merged))
+
+	pages, err := PageCount(MIMETypePDF, merged)
+	require.NoError(t, err)
+	assert.Equal(t, 6, pages)
+
+	file, err := parsePDF(merged)
+	require.NoError(t, err)
+	parsed, err := file.pages()
+	require.NoError(t, err)
+	require.Len(t, parsed, 6)
+
+	// The JPEG is embedded as is and scaled down to fit the page
+	jpegPage := file.dict(file.dict(parsed[3].dict.get("/Resources")).get("/XObject"))
+	jpegImage := file.resolve(jpegPage.get("/Im0")).(*pdfStream)
+	assert.Equal(t, pdfName("/DCTDecode"), jpegImage.dict.get("/Filter"))
+	assert.Equal(t, testJPEG(t, 3000, 200), jpegImage.data)
+	contents := file.resolve(parsed[3].dict.get("/Contents")).(*pdfStream)
+	assert.Contains(t, string(contents.data), "612.00 0 0 40.80")
+
+	// Attributes inherited from the source page tree are made explicit
+	assert.Equal(t, pdfArray{pdfRaw("0"), pdfRaw("0"), pdfRaw("300"), pdfRaw("400")}, parsed[5].dict.get("/MediaBox"))
+	content := file.resolve(parsed[5].dict.get("/Contents")).(*pdfStream)
+	assert.Equal(t, "0 0 m S ", string(content.data))
+}
+
+func TestMergeTIFF(t *testing.T) {
+	merged, err := MergeTIFF([]Source{
+		{Image: RenderTextPage([]string{"Case 123"})},
+		{MIMEType: MIMETypeTIFF, Content: bigEndianTIFF()},
+		{MIMEType: MIMETypeJPEG, Content: testJPEG(t, 2550, 100)},
+		{MIMEType: MIMETypePNG, Content: testPNG(t, 10, 10)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, MIMETypeTIFF, Detect(merged))
+
+	pages, err := readTIFFPages(merged)
+	require.NoError(t, err)
+	require.Len(t, pages, 4)
+
+	entry := func(page tiffPage, tag uint16) []uint32 {
+		for _, e := range page.entries {
+			if e.tag == tag {
+				return tiffValues(e.typ, e.count, e.value)
+			}
+		}
+		return nil
+	}
+
+	// Copied pages keep their values and image data, rewritten little endian
+	assert.Equal(t, []uint32{2}, entry(pages[1], tagImageWidth))
+	assert.Equal(t, [][]byte{{0x10, 0xF0}}, pages[1].chunks)
+	for _, e := range pages[1].entries {
+		if e.tag == tagXResolution {
+			assert.Equal(t, []byte{44, 1, 0, 0, 1, 0, 0, 0}, e.value)
+		}
+	}
+
+	// Images are halved to fit the page width and stored as Deflate compressed grayscale
+	assert.Equal(t, []uint32{1275}, entry(pages[2], tagImageWidth))
+	assert.Equal(t, []uint32{50}, entry(pages[2], tagImageLength))
+	assert.Equal(t, []uint32{8}, entry(pages[2], tagCompression))
+	reader, err := zlib.NewReader(bytes.NewReader(pages[2].chunks[0]))
+	require.NoError(t, err)
+	pixels, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Len(t, pixels, 1275*50)
+}
+
+func TestMerge_UnsupportedConversions(t *testing.T) {
+	_, err := MergeTIFF([]Source{{MIMEType: MIMETypePDF, Content: testPDF(1)}})
+	assert.ErrorIs(t, err, ErrUnsupportedConversion)
+
+	_, err = MergePDF([]Source{{MIMEType: MIMETypeTIFF, Content: bigEndianTIFF()}})
+	assert.ErrorIs(t, err, ErrUnsupportedConversion)
+
+	_, err = MergePDF([]Source{{MIMEType: MIMETypePDF, Content: []byte("%PDF-1.4\ntrailer\n<< /Root 1 0 R /Encrypt 2 0 R >>\n")}})
+	assert.ErrorIs(t, err, ErrUnsupportedConversion)
+
+	_, err = MergePDF([]Source{{MIMEType: MIMETypeJPEG, Content: []byte("not a jpeg")}})
+	assert.Error(t, err)
+}
+
+func TestRenderTextPage(t *testing.T) {
+	long := "Reason " + string(bytes.Repeat([]byte("word "), 40))
+	page := RenderTextPage([]string{"Case summary", long, "café"})
+	assert.Equal(t, image.Rect(0, 0, pageWidth, pageHeight), page.Bounds())
+
+	// The long line wraps onto the following lines
+	inked := func(line int) bool {
+		for y := pageMargin + line*lineHeight; y < pageMargin+(line+1)*lineHeight; y++ {
+			for x := 0; x < pageWidth; x++ {
+				if page.GrayAt(x, y).Y == 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for line := 0; line < 6; line++ {
+		assert.True(t, inked(line), "line %d", line)
+	}
+	assert.False(t, inked(6))
+	assert.Len(t, wrapLines([]string{long}), 4)
+}
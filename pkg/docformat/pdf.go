@@ -0,0 +1,76 @@
+package docformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// pagesCount matches the page count of a page tree node
+	pagesCount = regexp.MustCompile(`/Type\s*/Pages\b[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages\b`)
+	// pageObject matches a page object, but not a page tree node
+	pageObject = regexp.MustCompile(`/Type\s*/Page\b`)
+	// streamData matches the data of a stream object
+	streamData = regexp.MustCompile(`(?s)stream\r?\n(.*?)endstream`)
+)
+
+// maxInflatedStream bounds how much of a compressed stream is inflated, to guard against
+// decompression bombs
+const maxInflatedStream = 16 << 20
+
+// pdfPageCount reads the page count from the root of the page tree, which is the largest
+// count of any page tree node. Page trees stored in compressed object streams are inflated
+// first. Documents without a page tree fall back to counting page objects.
+func pdfPageCount(content []byte) (int, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(content, "\x00\t\n\f\r "), []byte("%PDF-")) {
+		return 0, fmt.Errorf("%w: missing PDF header", ErrMalformed)
+	}
+
+	sections := [][]byte{content}
+	for _, match := range streamData.FindAllSubmatch(content, -1) {
+		if inflated, ok := inflate(match[1]); ok {
+			sections = append(sections, inflated)
+		}
+	}
+
+	count, pages := 0, 0
+	for _, section := range sections {
+		for _, match := range pagesCount.FindAllSubmatch(section, -1) {
+			value := match[1]
+			if len(value) == 0 {
+				value = match[2]
+			}
+			if n, err := strconv.Atoi(string(value)); err == nil && n > count {
+				count = n
+			}
+		}
+		pages += len(pageObject.FindAllIndex(section, -1))
+	}
+
+	if count > 0 {
+		return count, nil
+	}
+	if pages > 0 {
+		return pages, nil
+	}
+	return 0, fmt.Errorf("%w: no pages found", ErrMalformed)
+}
+
+// inflate decompresses a Flate encoded stream, reporting false when it is not one
+func inflate(data []byte) ([]byte, bool) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	defer reader.Close()
+
+	inflated, err := io.ReadAll(io.LimitReader(reader, maxInflatedStream))
+	if err != nil && len(inflated) == 0 {
+		return nil, false
+	}
+	return inflated, true
+}
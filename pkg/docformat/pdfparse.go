@@ -0,0 +1,469 @@
+package docformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// PDF objects are held as the types below. Numbers, strings, booleans and null are kept as
+// the text they were written as, so they can be written back unchanged.
+type (
+	pdfObject any
+	pdfName   string
+	pdfRaw    string
+	pdfArray  []pdfObject
+	pdfRef    struct{ num, gen int }
+	pdfDict   struct {
+		keys   []pdfName
+		values map[pdfName]pdfObject
+	}
+	pdfStream struct {
+		dict *pdfDict
+		data []byte
+	}
+)
+
+var pdfNull = pdfRaw("null")
+
+func newPDFDict() *pdfDict {
+	return &pdfDict{values: make(map[pdfName]pdfObject)}
+}
+
+func (d *pdfDict) get(key pdfName) pdfObject {
+	return d.values[key]
+}
+
+func (d *pdfDict) set(key pdfName, value pdfObject) {
+	if _, ok := d.values[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+	d.values[key] = value
+}
+
+func (d *pdfDict) remove(key pdfName) {
+	if _, ok := d.values[key]; !ok {
+		return
+	}
+	delete(d.values, key)
+	for i, existing := range d.keys {
+		if existing == key {
+			d.keys = append(d.keys[:i], d.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// pdfFile is the object table of a parsed PDF
+type pdfFile struct {
+	objects map[int]pdfObject
+	trailer *pdfDict
+}
+
+var (
+	indirectObject = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	trailerKeyword = regexp.MustCompile(`trailer\s*<<`)
+)
+
+// parsePDF reads every object in a PDF by scanning for object definitions, rather than
+// trusting the cross-reference table, so that files with damaged offsets still parse. Later
+// definitions replace earlier ones, as incremental updates do.
+func parsePDF(content []byte) (*pdfFile, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(content, "\x00\t\n\f\r "), []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: missing PDF header", ErrMalformed)
+	}
+
+	file := &pdfFile{objects: make(map[int]pdfObject)}
+	for _, match := range indirectObject.FindAllSubmatchIndex(content, -1) {
+		num, _ := strconv.Atoi(string(content[match[2]:match[3]]))
+		parser := &pdfParser{data: content, pos: match[1]}
+		object, err := parser.parseIndirect()
+		if err != nil {
+			continue
+		}
+		file.objects[num] = object
+	}
+
+	// Objects compressed into object streams only fill gaps left by direct definitions
+	for _, object := range file.objects {
+		stream, ok := object.(*pdfStream)
+		if !ok || stream.dict.get("/Type") != pdfName("/ObjStm") {
+			continue
+		}
+		file.expandObjectStream(stream)
+	}
+
+	for _, match := range trailerKeyword.FindAllIndex(content, -1) {
+		parser := &pdfParser{data: content, pos: match[1] - 2}
+		if dict, err := parser.parseObject(); err == nil {
+			if trailer, ok := dict.(*pdfDict); ok {
+				file.trailer = trailer
+			}
+		}
+	}
+	if file.trailer == nil {
+		// Files with cross-reference streams keep the trailer in the stream dictionary
+		for _, object := range file.objects {
+			if stream, ok := object.(*pdfStream); ok && stream.dict.get("/Type") == pdfName("/XRef") {
+				file.trailer = stream.dict
+			}
+		}
+	}
+
+	if file.trailer != nil && file.trailer.get("/Encrypt") != nil {
+		return nil, fmt.Errorf("%w: encrypted PDFs cannot be merged", ErrUnsupportedConversion)
+	}
+	return file, nil
+}
+
+func (f *pdfFile) expandObjectStream(stream *pdfStream) {
+	data, err := decodeStream(stream)
+	if err != nil {
+		return
+	}
+	count, _ := strconv.Atoi(string(asRaw(f.resolve(stream.dict.get("/N")))))
+	first, _ := strconv.Atoi(string(asRaw(f.resolve(stream.dict.get("/First")))))
+	if first <= 0 || first > len(data) {
+		return
+	}
+
+	header := &pdfParser{data: data[:first]}
+	for i := 0; i < count; i++ {
+		num, numErr := header.parseInteger()
+		offset, offsetErr := header.parseInteger()
+		if numErr != nil || offsetErr != nil {
+			return
+		}
+		if _, defined := f.objects[num]; defined || first+offset >= len(data) {
+			continue
+		}
+		parser := &pdfParser{data: data, pos: first + offset}
+		if object, err := parser.parseObject(); err == nil {
+			f.objects[num] = object
+		}
+	}
+}
+
+// resolve follows a reference to the object it names; unknown references are null
+func (f *pdfFile) resolve(object pdfObject) pdfObject {
+	for i := 0; i < 32; i++ {
+		ref, ok := object.(pdfRef)
+		if !ok {
+			return object
+		}
+		resolved, ok := f.objects[ref.num]
+		if !ok {
+			return pdfNull
+		}
+		object = resolved
+	}
+	return pdfNull
+}
+
+func (f *pdfFile) dict(object pdfObject) *pdfDict {
+	switch resolved := f.resolve(object).(type) {
+	case *pdfDict:
+		return resolved
+	case *pdfStream:
+		return resolved.dict
+	}
+	return nil
+}
+
+// pdfPage is a leaf of the page tree with the attributes it inherits from its ancestors
+type pdfPage struct {
+	ref       pdfRef
+	dict      *pdfDict
+	inherited map[pdfName]pdfObject
+}
+
+// pdfInheritable lists the page attributes a page may inherit from the page tree
+var pdfInheritable = []pdfName{"/Resources", "/MediaBox", "/CropBox", "/Rotate"}
+
+// pages returns the pages of the document in order
+func (f *pdfFile) pages() ([]pdfPage, error) {
+	var root *pdfDict
+	if f.trailer != nil {
+		root = f.dict(f.trailer.get("/Root"))
+	}
+	if root == nil {
+		for _, object := range f.objects {
+			if dict, ok := object.(*pdfDict); ok && dict.get("/Type") == pdfName("/Catalog") {
+				root = dict
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%w: no document catalog", ErrMalformed)
+	}
+
+	var pages []pdfPage
+	visited := make(map[int]bool)
+	var walk func(node pdfObject, inherited map[pdfName]pdfObject)
+	walk = func(node pdfObject, inherited map[pdfName]pdfObject) {
+		ref, ok := node.(pdfRef)
+		if !ok || visited[ref.num] {
+			return
+		}
+		visited[ref.num] = true
+		dict := f.dict(ref)
+		if dict == nil {
+			return
+		}
+
+		attributes := make(map[pdfName]pdfObject, len(inherited))
+		for key, value := range inherited {
+			attributes[key] = value
+		}
+		for _, key := range pdfInheritable {
+			if value := dict.get(key); value != nil {
+				attributes[key] = value
+			}
+		}
+
+		kids, isTree := f.resolve(dict.get("/Kids")).(pdfArray)
+		if dict.get("/Type") == pdfName("/Page") || !isTree {
+			pages = append(pages, pdfPage{ref: ref, dict: dict, inherited: attributes})
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, attributes)
+		}
+	}
+	walk(root.get("/Pages"), nil)
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("%w: no pages found", ErrMalformed)
+	}
+	return pages, nil
+}
+
+func asRaw(object pdfObject) pdfRaw {
+	raw, _ := object.(pdfRaw)
+	return raw
+}
+
+// decodeStream returns the data of an uncompressed or Flate compressed stream without a predictor
+func decodeStream(stream *pdfStream) ([]byte, error) {
+	switch filter := stream.dict.get("/Filter").(type) {
+	case nil:
+		return stream.data, nil
+	case pdfName:
+		if filter != "/FlateDecode" || stream.dict.get("/DecodeParms") != nil {
+			return nil, fmt.Errorf("%w: stream filter %s", ErrUnsupportedConversion, filter)
+		}
+	default:
+		return nil, fmt.Errorf("%w: chained stream filters", ErrUnsupportedConversion)
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(stream.data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, maxInflatedStream))
+}
+
+// pdfParser reads PDF objects from a position in a buffer
+type pdfParser struct {
+	data []byte
+	pos  int
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		if !isPDFWhitespace(c) {
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *pdfParser) token() string {
+	start := p.pos
+	for p.pos < len(p.data) && !isPDFWhitespace(p.data[p.pos]) && !isPDFDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+func (p *pdfParser) parseInteger() (int, error) {
+	p.skipSpace()
+	return strconv.Atoi(p.token())
+}
+
+// parseIndirect parses the body of an indirect object, after its "num gen obj" header
+func (p *pdfParser) parseIndirect() (pdfObject, error) {
+	object, err := p.parseObject()
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := object.(*pdfDict)
+	if !ok {
+		return object, nil
+	}
+
+	p.skipSpace()
+	if !bytes.HasPrefix(p.data[p.pos:], []byte("stream")) {
+		return dict, nil
+	}
+	p.pos += len("stream")
+	if bytes.HasPrefix(p.data[p.pos:], []byte("\r\n")) {
+		p.pos += 2
+	} else if p.pos < len(p.data) && (p.data[p.pos] == '\n' || p.data[p.pos] == '\r') {
+		p.pos++
+	}
+
+	// Trust a direct /Length when endstream follows it, otherwise search for endstream
+	start := p.pos
+	if length, err := strconv.Atoi(string(asRaw(dict.get("/Length")))); err == nil && length >= 0 && start+length <= len(p.data) {
+		after := bytes.TrimLeft(p.data[start+length:], "\x00\t\n\f\r ")
+		if bytes.HasPrefix(after, []byte("endstream")) {
+			return &pdfStream{dict: dict, data: p.data[start : start+length]}, nil
+		}
+	}
+	end := bytes.Index(p.data[start:], []byte("endstream"))
+	if end < 0 {
+		return nil, fmt.Errorf("%w: unterminated stream", ErrMalformed)
+	}
+	data := p.data[start : start+end]
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	return &pdfStream{dict: dict, data: data}, nil
+}
+
+func (p *pdfParser) parseObject() (pdfObject, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrMalformed)
+	}
+
+	switch c := p.data[p.pos]; {
+	case c == '/':
+		p.pos++
+		return pdfName("/" + p.token()), nil
+	case bytes.HasPrefix(p.data[p.pos:], []byte("<<")):
+		return p.parseDict()
+	case c == '<':
+		end := bytes.IndexByte(p.data[p.pos:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated hex string", ErrMalformed)
+		}
+		raw := pdfRaw(p.data[p.pos : p.pos+end+1])
+		p.pos += end + 1
+		return raw, nil
+	case c == '(':
+		return p.parseLiteralString()
+	case c == '[':
+		p.pos++
+		array := pdfArray{}
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.data) {
+				return nil, fmt.Errorf("%w: unterminated array", ErrMalformed)
+			}
+			if p.data[p.pos] == ']' {
+				p.pos++
+				return array, nil
+			}
+			element, err := p.parseObject()
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, element)
+		}
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumberOrRef(), nil
+	default:
+		token := p.token()
+		switch token {
+		case "true", "false", "null":
+			return pdfRaw(token), nil
+		}
+		return nil, fmt.Errorf("%w: unexpected token %q", ErrMalformed, token)
+	}
+}
+
+func (p *pdfParser) parseDict() (pdfObject, error) {
+	p.pos += 2
+	dict := newPDFDict()
+	for {
+		p.skipSpace()
+		if bytes.HasPrefix(p.data[p.pos:], []byte(">>")) {
+			p.pos += 2
+			return dict, nil
+		}
+		key, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			return nil, fmt.Errorf("%w: dictionary key is not a name", ErrMalformed)
+		}
+		value, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		dict.set(name, value)
+	}
+}
+
+func (p *pdfParser) parseLiteralString() (pdfObject, error) {
+	start, depth := p.pos, 0
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case '\\':
+			p.pos++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return pdfRaw(p.data[start:p.pos]), nil
+			}
+		}
+		p.pos++
+	}
+	return nil, fmt.Errorf("%w: unterminated string", ErrMalformed)
+}
+
+// parseNumberOrRef reads a number, or a "num gen R" reference when one starts here
+func (p *pdfParser) parseNumberOrRef() pdfObject {
+	number := p.token()
+	num, err := strconv.Atoi(number)
+	if err != nil {
+		return pdfRaw(number)
+	}
+
+	saved := p.pos
+	p.skipSpace()
+	if gen, err := strconv.Atoi(p.token()); err == nil {
+		p.skipSpace()
+		if p.pos < len(p.data) && p.data[p.pos] == 'R' && (p.pos+1 == len(p.data) || isPDFWhitespace(p.data[p.pos+1]) || isPDFDelimiter(p.data[p.pos+1])) {
+			p.pos++
+			return pdfRef{num: num, gen: gen}
+		}
+	}
+	p.pos = saved
+	return pdfRaw(number)
+}
@@ -0,0 +1,169 @@
+package docformat
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// pdfWriter assembles a new PDF from objects numbered from 1
+type pdfWriter struct {
+	objects []pdfObject
+}
+
+// alloc reserves an object number to be filled in with set
+func (w *pdfWriter) alloc() pdfRef {
+	w.objects = append(w.objects, pdfNull)
+	return pdfRef{num: len(w.objects)}
+}
+
+func (w *pdfWriter) set(ref pdfRef, object pdfObject) {
+	w.objects[ref.num-1] = object
+}
+
+func (w *pdfWriter) add(object pdfObject) pdfRef {
+	ref := w.alloc()
+	w.set(ref, object)
+	return ref
+}
+
+// bytes writes the document with the given catalog
+func (w *pdfWriter) bytes(root pdfRef) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(w.objects))
+	for i, object := range w.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		writePDFObject(&buf, object)
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.objects)+1, root.num, xref)
+	return buf.Bytes()
+}
+
+func writePDFObject(buf *bytes.Buffer, object pdfObject) {
+	switch value := object.(type) {
+	case pdfName:
+		buf.WriteString(string(value))
+	case pdfRaw:
+		buf.WriteString(string(value))
+	case pdfRef:
+		fmt.Fprintf(buf, "%d %d R", value.num, value.gen)
+	case pdfArray:
+		buf.WriteByte('[')
+		for i, element := range value {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			writePDFObject(buf, element)
+		}
+		buf.WriteByte(']')
+	case *pdfDict:
+		buf.WriteString("<<")
+		for _, key := range value.keys {
+			buf.WriteString(string(key))
+			buf.WriteByte(' ')
+			writePDFObject(buf, value.values[key])
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(">>")
+	case *pdfStream:
+		value.dict.set("/Length", pdfRaw(strconv.Itoa(len(value.data))))
+		writePDFObject(buf, value.dict)
+		buf.WriteString("\nstream\n")
+		buf.Write(value.data)
+		buf.WriteString("\nendstream")
+	default:
+		buf.WriteString("null")
+	}
+}
+
+// pdfImporter copies pages of a source PDF, with every object they use, into a writer
+type pdfImporter struct {
+	source  *pdfFile
+	writer  *pdfWriter
+	numbers map[int]pdfRef
+}
+
+func newPDFImporter(source *pdfFile, writer *pdfWriter) *pdfImporter {
+	return &pdfImporter{source: source, writer: writer, numbers: make(map[int]pdfRef)}
+}
+
+// reserve numbers the pages to import up front, so that links between them are kept
+func (i *pdfImporter) reserve(pages []pdfPage) {
+	for _, page := range pages {
+		if _, ok := i.numbers[page.ref.num]; !ok {
+			i.numbers[page.ref.num] = i.writer.alloc()
+		}
+	}
+}
+
+// importPage copies a page under a new parent, making its inherited attributes explicit
+func (i *pdfImporter) importPage(page pdfPage, parent pdfRef) pdfRef {
+	ref, ok := i.numbers[page.ref.num]
+	if !ok {
+		ref = i.writer.alloc()
+		i.numbers[page.ref.num] = ref
+	}
+
+	dict := i.copy(page.dict).(*pdfDict)
+	dict.remove("/Parent")
+	// The structure tree and article threads of the source document are not copied
+	dict.remove("/StructParents")
+	dict.remove("/B")
+	for _, key := range pdfInheritable {
+		if dict.get(key) == nil && page.inherited[key] != nil {
+			dict.set(key, i.copy(page.inherited[key]))
+		}
+	}
+	dict.set("/Parent", parent)
+
+	i.writer.set(ref, dict)
+	return ref
+}
+
+// copy deep copies an object, copying the objects it references under new numbers.
+// References to the source page tree become null, so other pages are not pulled in.
+func (i *pdfImporter) copy(object pdfObject) pdfObject {
+	switch value := object.(type) {
+	case pdfRef:
+		if ref, ok := i.numbers[value.num]; ok {
+			return ref
+		}
+		target, ok := i.source.objects[value.num]
+		if !ok {
+			return pdfNull
+		}
+		if dict, isDict := target.(*pdfDict); isDict && dict.get("/Type") == pdfName("/Pages") {
+			return pdfNull
+		}
+		ref := i.writer.alloc()
+		i.numbers[value.num] = ref
+		i.writer.set(ref, i.copy(target))
+		return ref
+	case pdfArray:
+		copied := make(pdfArray, len(value))
+		for index, element := range value {
+			copied[index] = i.copy(element)
+		}
+		return copied
+	case *pdfDict:
+		copied := newPDFDict()
+		for _, key := range value.keys {
+			copied.set(key, i.copy(value.values[key]))
+		}
+		return copied
+	case *pdfStream:
+		return &pdfStream{dict: i.copy(value.dict).(*pdfDict), data: value.data}
+	default:
+		return value
+	}
+}
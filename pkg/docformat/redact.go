@@ -0,0 +1,415 @@
+package docformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"mastercom-service/pkg/pan"
+)
+
+// tjWordSpace is the TJ adjustment, in thousandths of a text space unit, taken to separate
+// words rather than kern letters
+const tjWordSpace = -200
+
+// RedactPDF masks the card numbers found in the text of a PDF, keeping their last four
+// digits, and returns the redacted PDF with the number of card numbers masked. A PDF without
+// card numbers is returned as it is.
+//
+// Text is read from the page content streams and the forms they draw, for fonts with
+// single byte codes; text in composite (Type0) fonts and text drawn as images cannot be
+// read. The redacted PDF is rebuilt from its pages alone, so annotations, form fields,
+// metadata and earlier revisions of the file are left out.
+func RedactPDF(content []byte) ([]byte, int, error) {
+	file, err := parsePDF(content)
+	if err != nil {
+		return nil, 0, err
+	}
+	pages, err := file.pages()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	redactor := &pdfRedactor{file: file, visited: make(map[*pdfStream]bool)}
+	for _, page := range pages {
+		resources := file.dict(page.inherited["/Resources"])
+		contents := file.resolve(page.dict.get("/Contents"))
+		streams, isArray := contents.(pdfArray)
+		if !isArray {
+			streams = pdfArray{contents}
+		}
+		for _, stream := range streams {
+			if err := redactor.redactStream(file.resolve(stream), resources); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if redactor.masked == 0 {
+		return content, 0, nil
+	}
+
+	for _, page := range pages {
+		page.dict.remove("/Annots")
+	}
+	writer := &pdfWriter{}
+	pagesRef := writer.alloc()
+	var kids pdfArray
+	for _, ref := range importPages(writer, pagesRef, file, pages) {
+		kids = append(kids, ref)
+	}
+	return writePageTree(writer, pagesRef, kids), redactor.masked, nil
+}
+
+// pdfRedactor masks card numbers in content streams, counting those it masks
+type pdfRedactor struct {
+	file    *pdfFile
+	visited map[*pdfStream]bool
+	masked  int
+}
+
+// redactStream masks the card numbers in a content stream drawn with resources, and in the
+// form XObjects it may draw
+func (r *pdfRedactor) redactStream(object pdfObject, resources *pdfDict) error {
+	stream, ok := object.(*pdfStream)
+	if !ok || r.visited[stream] {
+		return nil
+	}
+	r.visited[stream] = true
+
+	data, err := decodeStream(stream)
+	if err != nil {
+		return fmt.Errorf("reading content stream: %w", err)
+	}
+	var fonts *pdfDict
+	if resources != nil {
+		fonts = r.file.dict(resources.get("/Font"))
+	}
+	composite := func(font pdfName) bool {
+		if fonts == nil {
+			return false
+		}
+		dict := r.file.dict(fonts.get(font))
+		return dict != nil && dict.get("/Subtype") == pdfName("/Type0")
+	}
+	redacted, masked, err := redactContent(data, composite)
+	if err != nil {
+		return err
+	}
+	if masked > 0 {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		writer.Write(redacted)
+		writer.Close()
+		stream.data = compressed.Bytes()
+		stream.dict.set("/Filter", pdfName("/FlateDecode"))
+		stream.dict.remove("/DecodeParms")
+		r.masked += masked
+	}
+
+	// Forms draw text of their own, with their own resources when they have them
+	if resources == nil {
+		return nil
+	}
+	xobjects := r.file.dict(resources.get("/XObject"))
+	if xobjects == nil {
+		return nil
+	}
+	for _, key := range xobjects.keys {
+		form, ok := r.file.resolve(xobjects.get(key)).(*pdfStream)
+		if !ok || form.dict.get("/Subtype") != pdfName("/Form") {
+			continue
+		}
+		formResources := r.file.dict(form.dict.get("/Resources"))
+		if formResources == nil {
+			formResources = resources
+		}
+		if err := r.redactStream(form, formResources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shownString is a string drawn by a text-showing operator, decoded, with its offset in
+// the text of the content stream
+type shownString struct {
+	element int // index in the TJ array, or -1 for a single string operand
+	text    []byte
+	hex     bool
+	offset  int
+}
+
+// shownOperand is the operand of a text-showing operator and where it is in the stream
+type shownOperand struct {
+	start, end int
+	object     pdfObject
+	strings    []*shownString
+}
+
+// redactContent masks the card numbers shown by the text operators of a content stream and
+// returns the stream with the masked strings written back. Strings drawn in a font for which
+// composite reports true are skipped.
+func redactContent(data []byte, composite func(font pdfName) bool) ([]byte, int, error) {
+	var text bytes.Buffer
+	separate := func() {
+		if text.Len() > 0 && text.Bytes()[text.Len()-1] != ' ' {
+			text.WriteByte(' ')
+		}
+	}
+
+	var shown []*shownOperand
+	show := func(operand *shownOperand, element int, raw pdfRaw, skip bool) {
+		decoded, isHex, ok := decodePDFString(raw)
+		if !ok || skip {
+			separate()
+			return
+		}
+		operand.strings = append(operand.strings, &shownString{element: element, text: decoded, hex: isHex, offset: text.Len()})
+		text.Write(decoded)
+	}
+
+	type operandAt struct {
+		start, end int
+		object     pdfObject
+	}
+	var operands []operandAt
+	inComposite := false
+	parser := &pdfParser{data: data}
+	for {
+		parser.skipSpace()
+		if parser.pos >= len(data) {
+			break
+		}
+		start := parser.pos
+		c := data[start]
+		if c == '{' || c == '}' {
+			parser.pos++
+			continue
+		}
+		if c == '/' || c == '(' || c == '<' || c == '[' || c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9') {
+			object, err := parser.parseObject()
+			if err != nil {
+				return nil, 0, err
+			}
+			operands = append(operands, operandAt{start: start, end: parser.pos, object: object})
+			continue
+		}
+
+		operator := parser.token()
+		if operator == "" {
+			return nil, 0, fmt.Errorf("%w: unexpected %q in content stream", ErrMalformed, c)
+		}
+		switch operator {
+		case "true", "false", "null":
+			operands = append(operands, operandAt{start: start, end: parser.pos, object: pdfRaw(operator)})
+			continue
+		case "ID":
+			// Inline image data runs from after a single space to the EI operator
+			if err := skipInlineImage(parser); err != nil {
+				return nil, 0, err
+			}
+		case "Tf":
+			if len(operands) == 2 {
+				font, _ := operands[0].object.(pdfName)
+				inComposite = composite(font)
+			}
+		case "BT", "ET", "Td", "TD", "Tm", "T*":
+			separate()
+		case "Tj", "'", "\"":
+			if operator != "Tj" {
+				separate()
+			}
+			if len(operands) > 0 {
+				last := operands[len(operands)-1]
+				if raw, ok := last.object.(pdfRaw); ok {
+					operand := &shownOperand{start: last.start, end: last.end, object: raw}
+					show(operand, -1, raw, inComposite)
+					shown = append(shown, operand)
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				last := operands[len(operands)-1]
+				if array, ok := last.object.(pdfArray); ok {
+					operand := &shownOperand{start: last.start, end: last.end, object: array}
+					for element, value := range array {
+						raw, _ := value.(pdfRaw)
+						if len(raw) > 0 && (raw[0] == '(' || raw[0] == '<') {
+							show(operand, element, raw, inComposite)
+						} else if adjustment, err := strconv.ParseFloat(string(raw), 64); err == nil && adjustment <= tjWordSpace {
+							separate()
+						}
+					}
+					shown = append(shown, operand)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+
+	matches := pan.Find(text.String())
+	if len(matches) == 0 {
+		return data, 0, nil
+	}
+	masked := make([]bool, text.Len())
+	for _, match := range matches {
+		keep := 4
+		for i := match.End - 1; i >= match.Start; i-- {
+			if c := text.Bytes()[i]; c < '0' || c > '9' {
+				continue
+			}
+			if keep > 0 {
+				keep--
+				continue
+			}
+			masked[i] = true
+		}
+	}
+
+	var redacted bytes.Buffer
+	written := 0
+	for _, operand := range shown {
+		changed := false
+		for _, str := range operand.strings {
+			for i := range str.text {
+				if masked[str.offset+i] {
+					str.text[i] = '*'
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		object := operand.object
+		if array, ok := object.(pdfArray); ok {
+			copied := append(pdfArray{}, array...)
+			for _, str := range operand.strings {
+				copied[str.element] = encodePDFString(str.text, str.hex)
+			}
+			object = copied
+		} else {
+			object = encodePDFString(operand.strings[0].text, operand.strings[0].hex)
+		}
+		redacted.Write(data[written:operand.start])
+		writePDFObject(&redacted, object)
+		written = operand.end
+	}
+	redacted.Write(data[written:])
+	return redacted.Bytes(), len(matches), nil
+}
+
+// skipInlineImage moves the parser past the data of an inline image, which starts after the
+// ID operator and ends at an EI operator
+func skipInlineImage(parser *pdfParser) error {
+	data := parser.data
+	for search := parser.pos + 1; search < len(data); {
+		index := bytes.Index(data[search:], []byte("EI"))
+		if index < 0 {
+			break
+		}
+		end := search + index
+		if isPDFWhitespace(data[end-1]) && (end+2 == len(data) || isPDFWhitespace(data[end+2]) || isPDFDelimiter(data[end+2])) {
+			parser.pos = end + 2
+			return nil
+		}
+		search = end + 2
+	}
+	return fmt.Errorf("%w: unterminated inline image", ErrMalformed)
+}
+
+// decodePDFString returns the bytes of a literal or hex string, and whether it was hex
+func decodePDFString(raw pdfRaw) ([]byte, bool, bool) {
+	if len(raw) >= 2 && raw[0] == '<' && raw[len(raw)-1] == '>' {
+		digits := make([]byte, 0, len(raw))
+		for i := 1; i < len(raw)-1; i++ {
+			if c := raw[i]; (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+				digits = append(digits, c)
+			}
+		}
+		// An odd final digit is followed by an implied zero
+		if len(digits)%2 == 1 {
+			digits = append(digits, '0')
+		}
+		decoded, err := hex.DecodeString(string(digits))
+		return decoded, true, err == nil
+	}
+	if len(raw) < 2 || raw[0] != '(' || raw[len(raw)-1] != ')' {
+		return nil, false, false
+	}
+
+	body := raw[1 : len(raw)-1]
+	decoded := make([]byte, 0, len(body))
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\r':
+			// End of line markers read as a single line feed
+			if i+1 < len(body) && body[i+1] == '\n' {
+				i++
+			}
+			decoded = append(decoded, '\n')
+			continue
+		case c != '\\' || i+1 == len(body):
+			decoded = append(decoded, c)
+			continue
+		}
+
+		i++
+		switch escaped := body[i]; escaped {
+		case 'n':
+			decoded = append(decoded, '\n')
+		case 'r':
+			decoded = append(decoded, '\r')
+		case 't':
+			decoded = append(decoded, '\t')
+		case 'b':
+			decoded = append(decoded, '\b')
+		case 'f':
+			decoded = append(decoded, '\f')
+		case '\r':
+			// A backslash at the end of a line continues the string on the next
+			if i+1 < len(body) && body[i+1] == '\n' {
+				i++
+			}
+		case '\n':
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			value := 0
+			for digits := 0; digits < 3 && i < len(body) && body[i] >= '0' && body[i] <= '7'; digits++ {
+				value = value*8 + int(body[i]-'0')
+				i++
+			}
+			i--
+			decoded = append(decoded, byte(value))
+		default:
+			decoded = append(decoded, escaped)
+		}
+	}
+	return decoded, false, true
+}
+
+// encodePDFString writes text as a hex string, or as a literal string escaping the bytes
+// that are not printable ASCII
+func encodePDFString(text []byte, asHex bool) pdfRaw {
+	if asHex {
+		return pdfRaw("<" + hex.EncodeToString(text) + ">")
+	}
+	var encoded bytes.Buffer
+	encoded.WriteByte('(')
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			encoded.WriteByte('\\')
+			encoded.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&encoded, "\\%03o", c)
+		default:
+			encoded.WriteByte(c)
+		}
+	}
+	encoded.WriteByte(')')
+	return pdfRaw(encoded.String())
+}
@@ -0,0 +1,167 @@
+package docformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textPDF builds a one page PDF drawing content with font F1 of the given subtype, with a
+// link annotation. The content stream is Flate compressed when compress is set.
+func textPDF(content, fontSubtype string, compress bool) []byte {
+	stream, filter := []byte(content), ""
+	if compress {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		writer.Write(stream)
+		writer.Close()
+		stream, filter = compressed.Bytes(), " /Filter /FlateDecode"
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	doc.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	doc.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 5 0 R >> >> >>\nendobj\n")
+	doc.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Annots [6 0 R] >>\nendobj\n")
+	fmt.Fprintf(&doc, "4 0 obj\n<< /Length %d%s >>\nstream\n", len(stream), filter)
+	doc.Write(stream)
+	doc.WriteString("\nendstream\nendobj\n")
+	fmt.Fprintf(&doc, "5 0 obj\n<< /Type /Font /Subtype /%s /BaseFont /Helvetica >>\nendobj\n", fontSubtype)
+	doc.WriteString("6 0 obj\n<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] >>\nendobj\n")
+	doc.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return doc.Bytes()
+}
+
+// pageContent returns the decoded content stream of the only page of a PDF
+func pageContent(t *testing.T, content []byte) (string, *pdfDict) {
+	file, err := parsePDF(content)
+	require.NoError(t, err)
+	pages, err := file.pages()
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+
+	stream, ok := file.resolve(pages[0].dict.get("/Contents")).(*pdfStream)
+	require.True(t, ok)
+	data, err := decodeStream(stream)
+	require.NoError(t, err)
+	return string(data), pages[0].dict
+}
+
+func TestRedactPDF(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "single string",
+			content: "BT /F1 12 Tf 72 700 Td (Card 4111 1111 1111 1111 exp 12/25) Tj ET",
+			want:    "(Card **** **** **** 1111 exp 12/25) Tj",
+		},
+		{
+			name:    "kerned array with a hex string",
+			content: "BT /F1 12 Tf 72 700 Td [(41) -20 <3131313131> 15 (111111111) ( ok)] TJ ET",
+			want:    "[(**) -20 <2a2a2a2a2a> 15 (*****1111) ( ok)] TJ",
+		},
+		{
+			name:    "groups on separate lines",
+			content: "BT /F1 12 Tf 72 700 Td (5555 5555) Tj 0 -14 Td (5555 4444) Tj ET",
+			want:    "(**** ****) Tj 0 -14 Td (**** 4444) Tj",
+		},
+		{
+			name:    "escaped literal string",
+			content: "BT /F1 12 Tf (\\(\\064111111111111111\\)) Tj ET",
+			want:    "(\\(************1111\\)) Tj",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, compress := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s compressed=%v", tt.name, compress), func(t *testing.T) {
+				redacted, masked, err := RedactPDF(textPDF(tt.content, "Type1", compress))
+				require.NoError(t, err)
+				assert.Equal(t, 1, masked)
+
+				content, page := pageContent(t, redacted)
+				assert.Contains(t, content, tt.want)
+				assert.Nil(t, page.get("/Annots"), "annotations are left out")
+
+				count, err := PageCount(MIMETypePDF, redacted)
+				require.NoError(t, err)
+				assert.Equal(t, 1, count)
+			})
+		}
+	}
+}
+
+func TestRedactPDF_NothingToMask(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		fontSubtype string
+	}{
+		{name: "failing the Luhn check", content: "BT /F1 12 Tf (Card 4111 1111 1111 1112) Tj ET", fontSubtype: "Type1"},
+		{name: "inside a longer number", content: "BT /F1 12 Tf (Order 41111111111111111111) Tj ET", fontSubtype: "Type1"},
+		{name: "not shown as text", content: "4111111111111111 0 m S", fontSubtype: "Type1"},
+		{name: "composite font", content: "BT /F1 12 Tf <0034003100310031> Tj (4111111111111111) Tj ET", fontSubtype: "Type0"},
+		{name: "inline image", content: "BI /W 16 /H 1 /BPC 8 /CS /G ID (4111111111111111) Tj\nEI Q", fontSubtype: "Type1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := textPDF(tt.content, tt.fontSubtype, false)
+			redacted, masked, err := RedactPDF(original)
+			require.NoError(t, err)
+			assert.Equal(t, 0, masked)
+			assert.Equal(t, original, redacted)
+		})
+	}
+}
+
+func TestRedactPDF_Forms(t *testing.T) {
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	doc.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	doc.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	doc.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Fm1 5 0 R >> /Font << /F1 6 0 R >> >> /Contents 4 0 R >>\nendobj\n")
+	doc.WriteString("4 0 obj\n<< /Length 11 >>\nstream\n/Fm1 Do q Q\nendstream\nendobj\n")
+	form := "BT /F1 10 Tf (PAN 5555555555554444) Tj ET"
+	fmt.Fprintf(&doc, "5 0 obj\n<< /Type /XObject /Subtype /Form /BBox [0 0 100 100] /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(form), form)
+	doc.WriteString("6 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>\nendobj\n")
+	doc.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+
+	redacted, masked, err := RedactPDF(doc.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 1, masked)
+	assert.Contains(t, string(inflateAll(t, redacted)), "(PAN ************4444)")
+	assert.NotContains(t, string(inflateAll(t, redacted)), "5555555555554444")
+}
+
+func TestRedactPDF_Unsupported(t *testing.T) {
+	_, _, err := RedactPDF([]byte("not a pdf"))
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	content := bytes.Replace(textPDF("BT (4111111111111111) Tj ET", "Type1", false), []byte("/Length"), []byte("/Filter /LZWDecode /Length"), 1)
+	_, _, err = RedactPDF(content)
+	assert.ErrorIs(t, err, ErrUnsupportedConversion)
+}
+
+// inflateAll returns the decoded data of every stream in a PDF
+func inflateAll(t *testing.T, content []byte) []byte {
+	file, err := parsePDF(content)
+	require.NoError(t, err)
+	var all bytes.Buffer
+	for _, object := range file.objects {
+		if stream, ok := object.(*pdfStream); ok {
+			data, err := decodeStream(stream)
+			require.NoError(t, err)
+			all.Write(data)
+			all.WriteByte('\n')
+		}
+	}
+	return all.Bytes()
+}
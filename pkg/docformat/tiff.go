@@ -0,0 +1,81 @@
+package docformat
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// maxTIFFPages bounds the directories followed, so that a directory chain that loops back
+// on itself is reported as malformed
+const maxTIFFPages = 10000
+
+// tiffPageCount counts the image file directories of a TIFF or BigTIFF file, one per page
+func tiffPageCount(content []byte) (int, error) {
+	if len(content) < 8 {
+		return 0, fmt.Errorf("%w: truncated TIFF header", ErrMalformed)
+	}
+
+	var order binary.ByteOrder
+	switch string(content[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, fmt.Errorf("%w: invalid TIFF byte order", ErrMalformed)
+	}
+
+	// Classic TIFF uses 32-bit offsets and 12-byte entries; BigTIFF 64-bit offsets and 20-byte entries
+	var offset uint64
+	var countSize, entrySize, offsetSize uint64
+	switch order.Uint16(content[2:4]) {
+	case 42:
+		offset = uint64(order.Uint32(content[4:8]))
+		countSize, entrySize, offsetSize = 2, 12, 4
+	case 43:
+		if len(content) < 16 {
+			return 0, fmt.Errorf("%w: truncated BigTIFF header", ErrMalformed)
+		}
+		offset = order.Uint64(content[8:16])
+		countSize, entrySize, offsetSize = 8, 20, 8
+	default:
+		return 0, fmt.Errorf("%w: invalid TIFF version", ErrMalformed)
+	}
+
+	size := uint64(len(content))
+	visited := make(map[uint64]bool)
+	pages := 0
+	for offset != 0 {
+		if visited[offset] || pages >= maxTIFFPages {
+			return 0, fmt.Errorf("%w: TIFF directories loop", ErrMalformed)
+		}
+		visited[offset] = true
+
+		if offset > size || size-offset < countSize {
+			return 0, fmt.Errorf("%w: TIFF directory out of bounds", ErrMalformed)
+		}
+		var entries uint64
+		if countSize == 2 {
+			entries = uint64(order.Uint16(content[offset:]))
+		} else {
+			entries = order.Uint64(content[offset:])
+		}
+
+		next := offset + countSize
+		if entries > (size-next)/entrySize || size-next-entries*entrySize < offsetSize {
+			return 0, fmt.Errorf("%w: TIFF directory out of bounds", ErrMalformed)
+		}
+		next += entries * entrySize
+		if offsetSize == 4 {
+			offset = uint64(order.Uint32(content[next:]))
+		} else {
+			offset = order.Uint64(content[next:])
+		}
+		pages++
+	}
+
+	if pages == 0 {
+		return 0, fmt.Errorf("%w: no TIFF directories", ErrMalformed)
+	}
+	return pages, nil
+}
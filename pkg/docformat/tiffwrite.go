@@ -0,0 +1,314 @@
+package docformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// TIFF tags written or rewritten when assembling pages
+const (
+	tagImageWidth      = 256
+	tagImageLength     = 257
+	tagBitsPerSample   = 258
+	tagCompression     = 259
+	tagPhotometric     = 262
+	tagStripOffsets    = 273
+	tagSamplesPerPixel = 277
+	tagRowsPerStrip    = 278
+	tagStripByteCounts = 279
+	tagXResolution     = 282
+	tagYResolution     = 283
+	tagResolutionUnit  = 296
+	tagTileOffsets     = 324
+	tagTileByteCounts  = 325
+)
+
+// TIFF field types
+const (
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+// tiffTypeSizes is the size in bytes of one value of each TIFF field type
+var tiffTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 4, 6: 1, 7: 1, 8: 2, 9: 4, 10: 4, 11: 4, 12: 8}
+
+// tiffDroppedTags point at data elsewhere in the source file that is not copied
+var tiffDroppedTags = map[uint16]bool{
+	tagStripOffsets: true, tagStripByteCounts: true, tagTileOffsets: true, tagTileByteCounts: true,
+	330: true, 513: true, 514: true, 34665: true, 34853: true, 40965: true,
+}
+
+// tiffEntry is a directory entry whose value is held little endian
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// tiffPage is one image file directory and the strips or tiles of image data it points at
+type tiffPage struct {
+	entries []tiffEntry
+	chunks  [][]byte
+	tiled   bool
+}
+
+func shortEntry(tag uint16, value uint16) tiffEntry {
+	return tiffEntry{tag: tag, typ: tiffShort, count: 1, value: binary.LittleEndian.AppendUint16(nil, value)}
+}
+
+func longEntry(tag uint16, value uint32) tiffEntry {
+	return tiffEntry{tag: tag, typ: tiffLong, count: 1, value: binary.LittleEndian.AppendUint32(nil, value)}
+}
+
+func rationalEntry(tag uint16, numerator, denominator uint32) tiffEntry {
+	value := binary.LittleEndian.AppendUint32(nil, numerator)
+	return tiffEntry{tag: tag, typ: tiffRational, count: 1, value: binary.LittleEndian.AppendUint32(value, denominator)}
+}
+
+// grayTIFFPage encodes an image as an 8-bit grayscale page compressed with Deflate, scaled
+// down to fit a US Letter page at 150 dots per inch
+func grayTIFFPage(img image.Image) (tiffPage, error) {
+	gray := fitPage(img)
+	bounds := gray.Bounds()
+
+	var strip bytes.Buffer
+	writer := zlib.NewWriter(&strip)
+	for y := 0; y < bounds.Dy(); y++ {
+		offset := y * gray.Stride
+		if _, err := writer.Write(gray.Pix[offset : offset+bounds.Dx()]); err != nil {
+			return tiffPage{}, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return tiffPage{}, err
+	}
+
+	return tiffPage{
+		entries: []tiffEntry{
+			longEntry(tagImageWidth, uint32(bounds.Dx())),
+			longEntry(tagImageLength, uint32(bounds.Dy())),
+			shortEntry(tagBitsPerSample, 8),
+			shortEntry(tagCompression, 8),
+			shortEntry(tagPhotometric, 1),
+			shortEntry(tagSamplesPerPixel, 1),
+			longEntry(tagRowsPerStrip, uint32(bounds.Dy())),
+			rationalEntry(tagXResolution, pageDPI, 1),
+			rationalEntry(tagYResolution, pageDPI, 1),
+			shortEntry(tagResolutionUnit, 2),
+		},
+		chunks: [][]byte{strip.Bytes()},
+	}, nil
+}
+
+// fitPage converts an image to grayscale, averaging it down to fit within a page
+func fitPage(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	// Transparent pixels are drawn over a white page
+	draw.Draw(gray, gray.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(gray, gray.Bounds(), img, bounds.Min, draw.Over)
+
+	scale := max(float64(bounds.Dx())/pageWidth, float64(bounds.Dy())/pageHeight)
+	if scale <= 1 {
+		return gray
+	}
+
+	width, height := max(int(float64(bounds.Dx())/scale), 1), max(int(float64(bounds.Dy())/scale), 1)
+	scaled := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*bounds.Dy()/height, max((y+1)*bounds.Dy()/height, y*bounds.Dy()/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*bounds.Dx()/width, max((x+1)*bounds.Dx()/width, x*bounds.Dx()/width+1)
+			sum := 0
+			for sy := y0; sy < y1; sy++ {
+				row := gray.Pix[sy*gray.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					sum += int(row[sx])
+				}
+			}
+			scaled.Pix[y*scaled.Stride+x] = uint8(sum / ((y1 - y0) * (x1 - x0)))
+		}
+	}
+	return scaled
+}
+
+// readTIFFPages reads every page of a classic TIFF file so it can be copied into another,
+// keeping its image data as is
+func readTIFFPages(content []byte) ([]tiffPage, error) {
+	if _, err := tiffPageCount(content); err != nil {
+		return nil, err
+	}
+
+	order := binary.ByteOrder(binary.LittleEndian)
+	if string(content[:2]) == "MM" {
+		order = binary.BigEndian
+	}
+	if order.Uint16(content[2:4]) != 42 {
+		return nil, fmt.Errorf("%w: BigTIFF pages cannot be copied", ErrUnsupportedConversion)
+	}
+
+	var pages []tiffPage
+	offset := order.Uint32(content[4:8])
+	for offset != 0 {
+		count := int(order.Uint16(content[offset:]))
+		page := tiffPage{}
+		var chunkOffsets, chunkCounts []uint32
+		bigSamples, oldJPEG := false, false
+
+		for i := 0; i < count; i++ {
+			entry := content[int(offset)+2+12*i:]
+			tag, typ, n := order.Uint16(entry), order.Uint16(entry[2:]), order.Uint32(entry[4:])
+			size, known := tiffTypeSizes[typ]
+			if !known {
+				continue
+			}
+			length := size * int(n)
+			if typ == tiffRational || typ == 10 {
+				length *= 2
+			}
+			raw := entry[8:12]
+			if length > 4 {
+				at := int(order.Uint32(entry[8:]))
+				if at > len(content) || len(content)-at < length {
+					return nil, fmt.Errorf("%w: TIFF value out of bounds", ErrMalformed)
+				}
+				raw = content[at:]
+			}
+			value := littleEndian(order, raw[:length], size)
+			values := tiffValues(typ, n, value)
+
+			switch tag {
+			case tagStripOffsets, tagTileOffsets:
+				chunkOffsets = values
+				page.tiled = tag == tagTileOffsets
+			case tagStripByteCounts, tagTileByteCounts:
+				chunkCounts = values
+			case tagBitsPerSample:
+				for _, bits := range values {
+					bigSamples = bigSamples || bits > 8
+				}
+			case tagCompression:
+				oldJPEG = len(values) > 0 && values[0] == 6
+			}
+			if !tiffDroppedTags[tag] {
+				page.entries = append(page.entries, tiffEntry{tag: tag, typ: typ, count: n, value: value})
+			}
+		}
+
+		if oldJPEG {
+			return nil, fmt.Errorf("%w: old-style JPEG TIFF pages cannot be copied", ErrUnsupportedConversion)
+		}
+		if bigSamples && order == binary.BigEndian {
+			return nil, fmt.Errorf("%w: big endian TIFF pages with more than 8 bits per sample cannot be copied", ErrUnsupportedConversion)
+		}
+		if len(chunkOffsets) == 0 || len(chunkOffsets) != len(chunkCounts) {
+			return nil, fmt.Errorf("%w: TIFF page without image data", ErrMalformed)
+		}
+		for i, at := range chunkOffsets {
+			if int(at) > len(content) || len(content)-int(at) < int(chunkCounts[i]) {
+				return nil, fmt.Errorf("%w: TIFF image data out of bounds", ErrMalformed)
+			}
+			page.chunks = append(page.chunks, content[at:at+chunkCounts[i]])
+		}
+
+		pages = append(pages, page)
+		offset = order.Uint32(content[int(offset)+2+12*count:])
+	}
+	return pages, nil
+}
+
+// littleEndian returns a copy of TIFF values with each element of size bytes little endian
+func littleEndian(order binary.ByteOrder, raw []byte, size int) []byte {
+	value := append([]byte(nil), raw...)
+	if order == binary.LittleEndian || size == 1 {
+		return value
+	}
+	for i := 0; i+size <= len(value); i += size {
+		for a, b := i, i+size-1; a < b; a, b = a+1, b-1 {
+			value[a], value[b] = value[b], value[a]
+		}
+	}
+	return value
+}
+
+// tiffValues decodes SHORT and LONG values, the types used for offsets and sample sizes
+func tiffValues(typ uint16, count uint32, value []byte) []uint32 {
+	values := make([]uint32, 0, count)
+	for i := 0; i < int(count); i++ {
+		switch typ {
+		case tiffShort:
+			values = append(values, uint32(binary.LittleEndian.Uint16(value[2*i:])))
+		case tiffLong:
+			values = append(values, binary.LittleEndian.Uint32(value[4*i:]))
+		}
+	}
+	return values
+}
+
+// encodeTIFF writes pages as a little endian multi-page TIFF file
+func encodeTIFF(pages []tiffPage) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("II*\x00\x00\x00\x00\x00")
+	nextPointer := 4
+
+	for _, page := range pages {
+		offsets := make([]byte, 0, 4*len(page.chunks))
+		counts := make([]byte, 0, 4*len(page.chunks))
+		for _, chunk := range page.chunks {
+			offsets = binary.LittleEndian.AppendUint32(offsets, uint32(buf.Len()))
+			counts = binary.LittleEndian.AppendUint32(counts, uint32(len(chunk)))
+			buf.Write(chunk)
+		}
+
+		offsetTag, countTag := uint16(tagStripOffsets), uint16(tagStripByteCounts)
+		if page.tiled {
+			offsetTag, countTag = tagTileOffsets, tagTileByteCounts
+		}
+		entries := append([]tiffEntry{
+			{tag: offsetTag, typ: tiffLong, count: uint32(len(page.chunks)), value: offsets},
+			{tag: countTag, typ: tiffLong, count: uint32(len(page.chunks)), value: counts},
+		}, page.entries...)
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+		// Values that do not fit in an entry are written ahead of the directory, word aligned
+		external := make(map[int]uint32)
+		for i, entry := range entries {
+			if len(entry.value) > 4 {
+				if buf.Len()%2 == 1 {
+					buf.WriteByte(0)
+				}
+				external[i] = uint32(buf.Len())
+				buf.Write(entry.value)
+			}
+		}
+		if buf.Len()%2 == 1 {
+			buf.WriteByte(0)
+		}
+
+		directory := buf.Len()
+		binary.LittleEndian.PutUint32(buf.Bytes()[nextPointer:], uint32(directory))
+		buf.Write(binary.LittleEndian.AppendUint16(nil, uint16(len(entries))))
+		for i, entry := range entries {
+			field := binary.LittleEndian.AppendUint16(nil, entry.tag)
+			field = binary.LittleEndian.AppendUint16(field, entry.typ)
+			field = binary.LittleEndian.AppendUint32(field, entry.count)
+			if at, ok := external[i]; ok {
+				field = binary.LittleEndian.AppendUint32(field, at)
+			} else {
+				field = append(field, entry.value...)
+				field = append(field, make([]byte, 4-len(entry.value))...)
+			}
+			buf.Write(field)
+		}
+		nextPointer = buf.Len()
+		buf.Write([]byte{0, 0, 0, 0})
+	}
+	return buf.Bytes()
+}
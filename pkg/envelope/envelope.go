@@ -0,0 +1,223 @@
+// Package envelope encrypts data at rest with envelope encryption. Each value is sealed with
+// AES-256-GCM under a data key of its own, and the data key is stored alongside it wrapped by
+// a key-encryption key. Rotating the key-encryption key re-wraps the data keys without
+// touching the data.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// KeySize is the size in bytes of data keys and key-encryption keys
+const KeySize = 32
+
+var (
+	// ErrInvalidKey is returned for a key-encryption key that is not KeySize bytes
+	ErrInvalidKey = errors.New("invalid key-encryption key")
+	// ErrUnknownKey is returned for a key ID the key ring does not hold
+	ErrUnknownKey = errors.New("unknown key-encryption key")
+	// ErrKeyConflict is returned when a key ID already held is given different key bytes
+	ErrKeyConflict = errors.New("key-encryption key conflicts with the key held under its ID")
+	// ErrOpen is returned when a data key or the data it sealed cannot be decrypted, as when
+	// either was altered
+	ErrOpen = errors.New("envelope could not be opened")
+)
+
+// Envelope is a sealed value with its wrapped data key. WrappedKey and Ciphertext each hold
+// their nonce followed by the AES-GCM output.
+type Envelope struct {
+	// KeyID identifies the key-encryption key that wrapped the data key
+	KeyID      string `json:"keyId"`
+	WrappedKey []byte `json:"wrappedKey"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// KeyRing holds key-encryption keys by ID. New data keys are wrapped by the primary key; the
+// others are kept to open what they wrapped until it is re-wrapped.
+type KeyRing struct {
+	primary string
+	keys    map[string]*keyEncryptionKey
+	mutex   sync.RWMutex
+}
+
+type keyEncryptionKey struct {
+	key  []byte
+	aead cipher.AEAD
+}
+
+// GenerateKey returns a random key of KeySize bytes
+func GenerateKey() []byte {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	return key
+}
+
+// NewKeyRing creates a key ring holding keys, wrapping new data keys with the one under primary
+func NewKeyRing(primary string, keys map[string][]byte) (*KeyRing, error) {
+	ring := &KeyRing{}
+	if err := ring.Load(primary, keys); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// Load replaces the keys of the key ring. Anything wrapped by a key no longer held can no
+// longer be opened, so keys are only dropped once their data keys have been re-wrapped.
+func (r *KeyRing) Load(primary string, keys map[string][]byte) error {
+	loaded, err := newKeyEncryptionKeys(primary, keys)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.primary = primary
+	r.keys = loaded
+	return nil
+}
+
+// Add adds keys to the key ring and makes primary the key that wraps new data keys. Keys
+// already held stay, so that data keys they wrapped can be re-wrapped.
+func (r *KeyRing) Add(primary string, keys map[string][]byte) error {
+	added, err := newKeyEncryptionKeys(primary, keys)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, kek := range added {
+		if held, exists := r.keys[id]; exists && string(held.key) != string(kek.key) {
+			return fmt.Errorf("%w: %s", ErrKeyConflict, id)
+		}
+	}
+	for id, kek := range added {
+		r.keys[id] = kek
+	}
+	r.primary = primary
+	return nil
+}
+
+func newKeyEncryptionKeys(primary string, keys map[string][]byte) (map[string]*keyEncryptionKey, error) {
+	if _, exists := keys[primary]; !exists {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primary)
+	}
+
+	loaded := make(map[string]*keyEncryptionKey, len(keys))
+	for id, key := range keys {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("%w: %s is %d bytes, not %d", ErrInvalidKey, id, len(key), KeySize)
+		}
+		loaded[id] = &keyEncryptionKey{key: key, aead: newAEAD(key)}
+	}
+	return loaded, nil
+}
+
+// newAEAD returns AES-256-GCM under a key of KeySize bytes
+func newAEAD(key []byte) cipher.AEAD {
+	// AES accepts any 32 byte key, and GCM any AES block
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	return aead
+}
+
+// PrimaryKeyID returns the ID of the key that wraps new data keys
+func (r *KeyRing) PrimaryKeyID() string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.primary
+}
+
+// Seal encrypts plaintext under a new data key wrapped by the primary key. The additional
+// data is authenticated but not stored; the same must be given to Open, which binds the
+// envelope to what it belongs to.
+func (r *KeyRing) Seal(plaintext, additionalData []byte) *Envelope {
+	dataKey := GenerateKey()
+
+	r.mutex.RLock()
+	primary := r.primary
+	kek := r.keys[primary]
+	r.mutex.RUnlock()
+
+	return &Envelope{
+		KeyID:      primary,
+		WrappedKey: seal(kek.aead, dataKey, []byte(primary)),
+		Ciphertext: seal(newAEAD(dataKey), plaintext, additionalData),
+	}
+}
+
+// Open decrypts an envelope sealed with the same additional data
+func (r *KeyRing) Open(envelope *Envelope, additionalData []byte) ([]byte, error) {
+	dataKey, err := r.unwrap(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(newAEAD(dataKey), envelope.Ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOpen, err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap wraps an envelope's data key with the primary key in place of the key that wrapped
+// it, leaving the ciphertext as it is. It reports whether the envelope changed; one already
+// wrapped by the primary key is left alone.
+func (r *KeyRing) Rewrap(envelope *Envelope) (bool, error) {
+	r.mutex.RLock()
+	primary := r.primary
+	kek := r.keys[primary]
+	r.mutex.RUnlock()
+
+	if envelope.KeyID == primary {
+		return false, nil
+	}
+	dataKey, err := r.unwrap(envelope)
+	if err != nil {
+		return false, err
+	}
+
+	envelope.KeyID = primary
+	envelope.WrappedKey = seal(kek.aead, dataKey, []byte(primary))
+	return true, nil
+}
+
+// unwrap returns an envelope's data key. The key ID is authenticated with it, so a wrapped
+// key cannot be passed off as wrapped by another key.
+func (r *KeyRing) unwrap(envelope *Envelope) ([]byte, error) {
+	r.mutex.RLock()
+	kek, exists := r.keys[envelope.KeyID]
+	r.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, envelope.KeyID)
+	}
+
+	dataKey, err := open(kek.aead, envelope.WrappedKey, []byte(envelope.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: data key wrapped by %s: %v", ErrOpen, envelope.KeyID, err)
+	}
+	return dataKey, nil
+}
+
+// seal returns a random nonce followed by plaintext sealed under it
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	return aead.Seal(nonce, nonce, plaintext, additionalData)
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonceSize := aead.NonceSize()
+	return aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+}
@@ -0,0 +1,113 @@
+package envelope
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRing_SealOpen(t *testing.T) {
+	ring, err := NewKeyRing("k1", map[string][]byte{"k1": GenerateKey()})
+	require.NoError(t, err)
+
+	envelope := ring.Seal([]byte("jane@example.com"), []byte("case-1"))
+	assert.Equal(t, "k1", envelope.KeyID)
+	assert.NotContains(t, string(envelope.Ciphertext), "jane@example.com")
+
+	plaintext, err := ring.Open(envelope, []byte("case-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", string(plaintext))
+
+	// Each value gets its own data key
+	other := ring.Seal([]byte("jane@example.com"), []byte("case-1"))
+	assert.NotEqual(t, envelope.WrappedKey, other.WrappedKey)
+
+	// The additional data, data key and ciphertext are all authenticated
+	_, err = ring.Open(envelope, []byte("case-2"))
+	assert.ErrorIs(t, err, ErrOpen)
+	tampered := *envelope
+	tampered.Ciphertext = append([]byte{}, envelope.Ciphertext...)
+	tampered.Ciphertext[len(tampered.Ciphertext)-1] ^= 1
+	_, err = ring.Open(&tampered, []byte("case-1"))
+	assert.ErrorIs(t, err, ErrOpen)
+	swapped := *envelope
+	swapped.WrappedKey = other.WrappedKey
+	_, err = ring.Open(&swapped, []byte("case-1"))
+	assert.ErrorIs(t, err, ErrOpen)
+
+	unknown := *envelope
+	unknown.KeyID = "k0"
+	_, err = ring.Open(&unknown, []byte("case-1"))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestNewKeyRing_InvalidKeys(t *testing.T) {
+	_, err := NewKeyRing("k2", map[string][]byte{"k1": GenerateKey()})
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	_, err = NewKeyRing("k1", map[string][]byte{"k1": []byte("short")})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestKeyRing_Rotation(t *testing.T) {
+	k1, k2 := GenerateKey(), GenerateKey()
+	ring, err := NewKeyRing("k1", map[string][]byte{"k1": k1})
+	require.NoError(t, err)
+	envelope := ring.Seal([]byte("secret"), nil)
+	ciphertext := envelope.Ciphertext
+
+	assert.ErrorIs(t, ring.Add("k1", map[string][]byte{"k1": k2}), ErrKeyConflict)
+	require.NoError(t, ring.Add("k2", map[string][]byte{"k2": k2}))
+	assert.Equal(t, "k2", ring.PrimaryKeyID())
+
+	// Data keys wrapped by the old key still open until re-wrapped
+	plaintext, err := ring.Open(envelope, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	changed, err := ring.Rewrap(envelope)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "k2", envelope.KeyID)
+	assert.Equal(t, ciphertext, envelope.Ciphertext)
+	changed, err = ring.Rewrap(envelope)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// Once re-wrapped, the old key can be dropped
+	require.NoError(t, ring.Load("k2", map[string][]byte{"k2": k2}))
+	plaintext, err = ring.Open(envelope, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+}
+
+func TestKeyRing_Fields(t *testing.T) {
+	type contact struct {
+		ID    string
+		Name  string `encrypt:"true"`
+		Email string `encrypt:"true"`
+		Phone string `encrypt:"true"`
+	}
+	ring, err := NewKeyRing("k1", map[string][]byte{"k1": GenerateKey()})
+	require.NoError(t, err)
+
+	record := contact{ID: "case-1", Name: "Jane Doe", Email: "jane@example.com"}
+	sealed := ring.SealFields(&record, []byte(record.ID))
+	assert.Equal(t, contact{ID: "case-1"}, record)
+	assert.Len(t, sealed, 2)
+	assert.Equal(t, "k1", sealed["Email"].KeyID)
+
+	require.NoError(t, ring.OpenFields(&record, sealed, []byte("case-1")))
+	assert.Equal(t, contact{ID: "case-1", Name: "Jane Doe", Email: "jane@example.com"}, record)
+
+	// Envelopes are bound to their field and record
+	swapped := Fields{"Name": sealed["Email"]}
+	assert.ErrorIs(t, ring.OpenFields(&contact{}, swapped, []byte("case-1")), ErrOpen)
+	assert.ErrorIs(t, ring.OpenFields(&contact{}, sealed, []byte("case-2")), ErrOpen)
+
+	require.NoError(t, ring.Add("k2", map[string][]byte{"k2": GenerateKey()}))
+	rewrapped, err := ring.RewrapFields(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rewrapped)
+	assert.Equal(t, "k2", sealed["Name"].KeyID)
+}
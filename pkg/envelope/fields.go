@@ -0,0 +1,77 @@
+package envelope
+
+import (
+	"reflect"
+)
+
+// Tag marks the string fields of a struct that SealFields encrypts, as `encrypt:"true"`
+const Tag = "encrypt"
+
+// Fields holds the envelopes of a struct's encrypted fields by field name
+type Fields map[string]*Envelope
+
+// SealFields seals the non-empty string fields of the struct v points to that are tagged
+// encrypt:"true", clearing them, and returns their envelopes. Each field is sealed with the
+// additional data and its name, so envelopes cannot be swapped between fields or records.
+func (r *KeyRing) SealFields(v any, additionalData []byte) Fields {
+	value := reflect.ValueOf(v).Elem()
+	sealed := Fields{}
+	for _, field := range taggedFields(value.Type()) {
+		plaintext := value.FieldByIndex(field.Index)
+		if plaintext.String() == "" {
+			continue
+		}
+		sealed[field.Name] = r.Seal([]byte(plaintext.String()), fieldData(additionalData, field.Name))
+		plaintext.SetString("")
+	}
+	return sealed
+}
+
+// OpenFields restores the fields of the struct v points to from the envelopes SealFields
+// returned for it. Fields are restored up to the first that cannot be opened.
+func (r *KeyRing) OpenFields(v any, sealed Fields, additionalData []byte) error {
+	value := reflect.ValueOf(v).Elem()
+	for _, field := range taggedFields(value.Type()) {
+		envelope, exists := sealed[field.Name]
+		if !exists {
+			continue
+		}
+		plaintext, err := r.Open(envelope, fieldData(additionalData, field.Name))
+		if err != nil {
+			return err
+		}
+		value.FieldByIndex(field.Index).SetString(string(plaintext))
+	}
+	return nil
+}
+
+// RewrapFields re-wraps the data keys of every envelope in sealed with the primary key and
+// returns the number re-wrapped
+func (r *KeyRing) RewrapFields(sealed Fields) (int, error) {
+	rewrapped := 0
+	for _, envelope := range sealed {
+		changed, err := r.Rewrap(envelope)
+		if err != nil {
+			return rewrapped, err
+		}
+		if changed {
+			rewrapped++
+		}
+	}
+	return rewrapped, nil
+}
+
+// taggedFields returns the string fields of a struct type tagged encrypt:"true"
+func taggedFields(structType reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for _, field := range reflect.VisibleFields(structType) {
+		if field.Tag.Get(Tag) == "true" && field.Type.Kind() == reflect.String {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func fieldData(additionalData []byte, name string) []byte {
+	return append(append(append([]byte{}, additionalData...), ':'), name...)
+}
@@ -0,0 +1,302 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minReloadInterval limits how often a token signed by an unknown key reloads the key set
+const minReloadInterval = 30 * time.Second
+
+// maxKeySetSize limits the size of a key set read from a URL
+const maxKeySetSize = 1 << 20
+
+// publicKey is a verification key of a key set
+type publicKey struct {
+	key crypto.PublicKey
+	// algorithm is the algorithm the key is restricted to, if any
+	algorithm string
+}
+
+// KeySet holds the public keys of a JSON Web Key Set read from a file or a URL. The keys are
+// read again once the refresh interval has passed, and when a token names a key the set does
+// not hold, so keys the issuer rotates in are picked up without a restart.
+type KeySet struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+	keys    map[string]*publicKey
+	loaded  time.Time
+	// attempted is when the set was last read, successfully or not
+	attempted time.Time
+	// minReload limits how often the set is read
+	minReload time.Duration
+	// loading is the read of the set in progress, if any
+	loading *loadCall
+	mutex   sync.Mutex
+}
+
+// loadCall is a read of the key set, shared by every caller waiting for it
+type loadCall struct {
+	started time.Time
+	done    chan struct{}
+	err     error
+}
+
+// NewKeySet creates a key set read from source, an http(s) URL or a file path, and reloaded
+// every refresh interval. It is read on first use.
+func NewKeySet(source string, refresh time.Duration) *KeySet {
+	return &KeySet{
+		source:    source,
+		refresh:   refresh,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		minReload: minReloadInterval,
+	}
+}
+
+// Load reads the key set from its source, replacing the keys held. A read already in
+// progress is waited for instead.
+func (s *KeySet) Load(ctx context.Context) error {
+	s.mutex.Lock()
+	call, started := s.beginLoad()
+	s.mutex.Unlock()
+	return s.load(ctx, call, started)
+}
+
+// beginLoad returns the read of the key set in progress, or starts one and reports that it
+// did. The caller must hold the mutex.
+func (s *KeySet) beginLoad() (*loadCall, bool) {
+	if s.loading != nil {
+		return s.loading, false
+	}
+	s.attempted = time.Now()
+	s.loading = &loadCall{started: s.attempted, done: make(chan struct{})}
+	return s.loading, true
+}
+
+// load runs a read of the key set the caller started, or waits for one another caller
+// started, and returns its error. The source is read without holding the mutex, which is
+// only taken to swap in the keys read.
+func (s *KeySet) load(ctx context.Context, call *loadCall, started bool) error {
+	if !started {
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	keys, err := s.fetch(ctx)
+
+	s.mutex.Lock()
+	if err == nil {
+		s.keys = keys
+		s.loaded = call.started
+	}
+	call.err = err
+	s.loading = nil
+	s.mutex.Unlock()
+	close(call.done)
+	return err
+}
+
+// fetch reads and parses the key set
+func (s *KeySet) fetch(ctx context.Context) (map[string]*publicKey, error) {
+	data, err := s.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: read key set: %w", err)
+	}
+	return parseKeySet(data)
+}
+
+func (s *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "https://") && !strings.HasPrefix(s.source, "http://") {
+		return os.ReadFile(s.source)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, maxKeySetSize))
+}
+
+// key returns the key with ID kid, or the only key of the set when kid is empty. The set is
+// read again when its refresh interval has passed or it does not hold the key, at most every
+// minReloadInterval. Concurrent callers share a single read. A source that cannot be read
+// leaves the keys last read in use.
+func (s *KeySet) key(ctx context.Context, kid string) (*publicKey, error) {
+	s.mutex.Lock()
+	key := s.find(kid)
+	stale := s.keys == nil || time.Since(s.loaded) >= s.refresh
+	call, started := s.loading, false
+	if (stale || key == nil) && call == nil && time.Since(s.attempted) >= s.minReload {
+		call, started = s.beginLoad()
+	}
+	s.mutex.Unlock()
+
+	var loadErr error
+	if (stale || key == nil) && call != nil {
+		if loadErr = s.load(ctx, call, started); loadErr == nil {
+			s.mutex.Lock()
+			key = s.find(kid)
+			s.mutex.Unlock()
+		}
+	}
+
+	if key == nil {
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// find returns the key with ID kid. The caller must hold the mutex.
+func (s *KeySet) find(kid string) *publicKey {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key
+		}
+	}
+	return s.keys[kid]
+}
+
+// jsonWebKey is a key of a JSON Web Key Set, as defined by RFC 7517
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// parseKeySet parses the signature verification keys of a JSON Web Key Set. Keys of other
+// types or uses are skipped.
+func parseKeySet(data []byte) (map[string]*publicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: parse key set: %w", err)
+	}
+
+	keys := make(map[string]*publicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch jwk.KeyType {
+		case "RSA":
+			key, err = parseRSAKey(jwk)
+		case "EC":
+			key, err = parseECKey(jwk)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jwt: parse key %q: %w", jwk.KeyID, err)
+		}
+		keys[jwk.KeyID] = &publicKey{key: key, algorithm: jwk.Algorithm}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwt: key set has no signature keys")
+	}
+	return keys, nil
+}
+
+func parseRSAKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := decodeInt(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeInt(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	if n.BitLen() < 2048 {
+		return nil, fmt.Errorf("RSA key of %d bits is too small", n.BitLen())
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func parseECKey(jwk jsonWebKey) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	var point ecdh.Curve
+	switch jwk.Curve {
+	case "P-256":
+		curve, point = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, point = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, point = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("invalid %s point", jwk.Curve)
+	}
+	// Parsing the uncompressed point checks that it is on the curve
+	if _, err := point.NewPublicKey(append([]byte{4}, append(x, y...)...)); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
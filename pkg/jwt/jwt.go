@@ -0,0 +1,247 @@
+// Package jwt verifies JSON Web Tokens signed with the asymmetric algorithms of RFC 7518
+// against the keys of a JSON Web Key Set. Tokens signed with a shared secret or not at all
+// are refused.
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMalformed is returned for a token that is not a signed JWT in compact form
+	ErrMalformed = errors.New("jwt: malformed token")
+	// ErrUnsupportedAlgorithm is returned for a token signed with an algorithm not accepted
+	ErrUnsupportedAlgorithm = errors.New("jwt: unsupported signing algorithm")
+	// ErrUnknownKey is returned for a token signed with a key the key set does not hold
+	ErrUnknownKey = errors.New("jwt: unknown signing key")
+	// ErrInvalidSignature is returned for a token whose signature does not verify
+	ErrInvalidSignature = errors.New("jwt: invalid signature")
+	// ErrExpired is returned for a token past its expiry, or without one
+	ErrExpired = errors.New("jwt: token expired")
+	// ErrNotYetValid is returned for a token used before its not-before time
+	ErrNotYetValid = errors.New("jwt: token not yet valid")
+	// ErrInvalidIssuer is returned for a token from another issuer
+	ErrInvalidIssuer = errors.New("jwt: invalid issuer")
+	// ErrInvalidAudience is returned for a token not intended for the verifier
+	ErrInvalidAudience = errors.New("jwt: invalid audience")
+)
+
+// algorithm describes how a signing algorithm verifies a signature
+type algorithm struct {
+	hash crypto.Hash
+	// verify checks signature over digest with key, reporting false for a key of another type
+	verify func(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool
+}
+
+var algorithms = map[string]algorithm{
+	"RS256": {crypto.SHA256, verifyPKCS1},
+	"RS384": {crypto.SHA384, verifyPKCS1},
+	"RS512": {crypto.SHA512, verifyPKCS1},
+	"PS256": {crypto.SHA256, verifyPSS},
+	"PS384": {crypto.SHA384, verifyPSS},
+	"PS512": {crypto.SHA512, verifyPSS},
+	"ES256": {crypto.SHA256, verifyECDSA(256)},
+	"ES384": {crypto.SHA384, verifyECDSA(384)},
+	"ES512": {crypto.SHA512, verifyECDSA(521)},
+}
+
+// Claims holds the claims of a verified token
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	// raw holds every claim of the token as decoded from JSON
+	raw map[string]any
+}
+
+// Strings returns a claim holding a string or an array of strings, such as a roles claim. A
+// string is split on spaces, as the scope claim lists scopes.
+func (c *Claims) Strings(name string) []string {
+	switch value := c.raw[name].(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Verifier verifies tokens signed by the keys of a key set, for an issuer and an audience
+type Verifier struct {
+	keys *KeySet
+	// issuer and audience are not checked when empty
+	issuer   string
+	audience string
+	// leeway allows for clock skew with the issuer
+	leeway time.Duration
+	now    func() time.Time
+}
+
+// NewVerifier creates a verifier of tokens signed by keys. Tokens must be issued by issuer
+// and intended for audience when these are not empty.
+func NewVerifier(keys *KeySet, issuer, audience string) *Verifier {
+	return &Verifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		leeway:   time.Minute,
+		now:      time.Now,
+	}
+}
+
+// Verify checks the signature, validity period, issuer and audience of a token in compact
+// form, and returns its claims. Tokens must carry an expiry.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+		Critical  []any  `json:"crit"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if len(header.Critical) > 0 {
+		return nil, fmt.Errorf("%w: unsupported critical header", ErrMalformed)
+	}
+	alg, ok := algorithms[header.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	key, err := v.keys.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.algorithm != "" && key.algorithm != header.Algorithm {
+		return nil, fmt.Errorf("%w: key %q is for %s", ErrUnsupportedAlgorithm, header.KeyID, key.algorithm)
+	}
+	digest := alg.hash.New()
+	digest.Write([]byte(parts[0] + "." + parts[1]))
+	if !alg.verify(key.key, alg.hash, digest.Sum(nil), signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	claims := &Claims{}
+	if err := decodeSegment(parts[1], &claims.raw); err != nil {
+		return nil, err
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validate reads the registered claims of a token and checks them
+func (v *Verifier) validate(claims *Claims) error {
+	claims.Issuer, _ = claims.raw["iss"].(string)
+	claims.Subject, _ = claims.raw["sub"].(string)
+	claims.Audience = claims.Strings("aud")
+	if audience, ok := claims.raw["aud"].(string); ok {
+		// A single audience is not split on spaces
+		claims.Audience = []string{audience}
+	}
+
+	now := v.now()
+	expiry, ok := claims.raw["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: no expiry", ErrExpired)
+	}
+	claims.ExpiresAt = numericDate(expiry)
+	if !now.Before(claims.ExpiresAt.Add(v.leeway)) {
+		return ErrExpired
+	}
+	if notBefore, ok := claims.raw["nbf"].(float64); ok && now.Add(v.leeway).Before(numericDate(notBefore)) {
+		return ErrNotYetValid
+	}
+
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return fmt.Errorf("%w: %q", ErrInvalidIssuer, claims.Issuer)
+	}
+	if v.audience != "" && !contains(claims.Audience, v.audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	return nil
+}
+
+func numericDate(seconds float64) time.Time {
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*1e9))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func verifyPKCS1(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+	rsaKey, ok := key.(*rsa.PublicKey)
+	return ok && rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature) == nil
+}
+
+func verifyPSS(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+	rsaKey, ok := key.(*rsa.PublicKey)
+	return ok && rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+}
+
+// verifyECDSA returns a check of signatures by keys on the curve of curveBits bits, the one
+// curve each ES algorithm is defined for. JWS encodes the signature as the two integers r and
+// s, each padded to the size of the curve.
+func verifyECDSA(curveBits int) func(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+	return func(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve.Params().BitSize != curveBits {
+			return false
+		}
+		size := (curveBits + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(ecKey, digest, r, s)
+	}
+}
@@ -0,0 +1,260 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeSegment(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	signingInput := encodeSegment(t, map[string]any{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	signingInput := encodeSegment(t, map[string]any{"alg": "ES256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+	require.NoError(t, err)
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]any {
+	return map[string]any{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]any {
+	return map[string]any{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"alg": "ES256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func writeKeySet(t *testing.T, path string, keys ...map[string]any) {
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":   "https://issuer.example.com",
+		"sub":   "analyst-1",
+		"aud":   "mastercom-service",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"analyst", "viewer"},
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeKeySet(t, path, rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+	verifier := NewVerifier(NewKeySet(path, time.Hour), "https://issuer.example.com", "mastercom-service")
+
+	claims, err := verifier.Verify(context.Background(), signRS256(t, rsaKey, "rsa-1", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "analyst-1", claims.Subject)
+	assert.Equal(t, []string{"mastercom-service"}, claims.Audience)
+	assert.Equal(t, []string{"analyst", "viewer"}, claims.Strings("roles"))
+
+	claims, err = verifier.Verify(context.Background(), signES256(t, ecKey, "ec-1", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "analyst-1", claims.Subject)
+
+	tests := []struct {
+		name  string
+		token func() string
+		err   error
+	}{
+		{"expired", func() string {
+			claims := validClaims()
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return signRS256(t, rsaKey, "rsa-1", claims)
+		}, ErrExpired},
+		{"no expiry", func() string {
+			claims := validClaims()
+			delete(claims, "exp")
+			return signRS256(t, rsaKey, "rsa-1", claims)
+		}, ErrExpired},
+		{"not yet valid", func() string {
+			claims := validClaims()
+			claims["nbf"] = time.Now().Add(time.Hour).Unix()
+			return signRS256(t, rsaKey, "rsa-1", claims)
+		}, ErrNotYetValid},
+		{"other issuer", func() string {
+			claims := validClaims()
+			claims["iss"] = "https://other.example.com"
+			return signRS256(t, rsaKey, "rsa-1", claims)
+		}, ErrInvalidIssuer},
+		{"other audience", func() string {
+			claims := validClaims()
+			claims["aud"] = []string{"other-service"}
+			return signRS256(t, rsaKey, "rsa-1", claims)
+		}, ErrInvalidAudience},
+		{"tampered claims", func() string {
+			parts := strings.Split(signRS256(t, rsaKey, "rsa-1", validClaims()), ".")
+			claims := validClaims()
+			claims["roles"] = []string{"admin"}
+			return parts[0] + "." + encodeSegment(t, claims) + "." + parts[2]
+		}, ErrInvalidSignature},
+		{"key of another type", func() string {
+			return signRS256(t, rsaKey, "ec-1", validClaims())
+		}, ErrUnsupportedAlgorithm},
+		{"unsigned", func() string {
+			return encodeSegment(t, map[string]any{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + "."
+		}, ErrUnsupportedAlgorithm},
+		{"shared secret", func() string {
+			return encodeSegment(t, map[string]any{"alg": "HS256", "kid": "rsa-1"}) + "." + encodeSegment(t, validClaims()) + ".c2ln"
+		}, ErrUnsupportedAlgorithm},
+		{"not a token", func() string { return "not-a-token" }, ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), tt.token())
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestKeySet_ReloadsForUnknownKey(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	second, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var requests atomic.Int32
+	keys := []map[string]any{rsaJWK("k1", first)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer server.Close()
+
+	keySet := NewKeySet(server.URL, time.Hour)
+	verifier := NewVerifier(keySet, "", "")
+	_, err = verifier.Verify(context.Background(), signRS256(t, first, "k1", validClaims()))
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), signRS256(t, first, "k1", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "the set is read once until it is due")
+
+	// A key the issuer rotates in is picked up
+	keys = append(keys, rsaJWK("k2", second))
+	_, err = verifier.Verify(context.Background(), signRS256(t, second, "k2", validClaims()))
+	assert.ErrorIs(t, err, ErrUnknownKey, "reloads are rate limited")
+	keySet.minReload = 0
+	_, err = verifier.Verify(context.Background(), signRS256(t, second, "k2", validClaims()))
+	require.NoError(t, err)
+
+	// The keys last read stay in use when the source fails
+	server.Close()
+	_, err = verifier.Verify(context.Background(), signRS256(t, first, "k1", validClaims()))
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), signRS256(t, first, "k3", validClaims()))
+	assert.Error(t, err)
+}
+
+func TestKeySet_SharesReads(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{rsaJWK("k1", first)}})
+	}))
+	defer server.Close()
+	keySet := NewKeySet(server.URL, time.Hour)
+	keySet.minReload = 0
+
+	// Callers arriving while the set is read wait for that read
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		go func() {
+			_, err := keySet.key(context.Background(), "k1")
+			errs <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	release <- struct{}{}
+	for i := 0; i < 8; i++ {
+		require.NoError(t, <-errs)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Keys held are served while a reload for an unknown key waits on the source
+	go func() {
+		_, err := keySet.key(context.Background(), "k2")
+		errs <- err
+	}()
+	require.Eventually(t, func() bool { return requests.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+	key, err := keySet.key(context.Background(), "k1")
+	require.NoError(t, err)
+	assert.NotNil(t, key)
+	close(release)
+	assert.ErrorIs(t, <-errs, ErrUnknownKey)
+}
+
+func TestParseKeySet(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = parseKeySet([]byte(`{"keys":[` + mustJSON(t, rsaJWK("small", small)) + `]}`))
+	assert.ErrorContains(t, err, "too small")
+
+	_, err = parseKeySet([]byte(`{"keys":[{"kty":"oct","kid":"secret","k":"c2VjcmV0"}]}`))
+	assert.ErrorContains(t, err, "no signature keys")
+
+	_, err = parseKeySet([]byte(`{"keys":[{"kty":"EC","kid":"bad","crv":"P-256","x":"` + strings.Repeat("A", 43) + `","y":"` + strings.Repeat("A", 43) + `"}]}`))
+	assert.Error(t, err, "a point off the curve is refused")
+}
+
+func mustJSON(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
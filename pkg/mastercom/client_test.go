@@ -0,0 +1,122 @@
+package mastercom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL + "/mastercom")
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_CreateCaseFiling(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/mastercom/v6/cases", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var req CreateCaseRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "1", req.CaseType)
+
+		json.NewEncoder(w).Encode(CaseFilingResponse{CaseID: "536092"})
+	})
+
+	resp, err := client.CreateCaseFiling(context.Background(), &CreateCaseRequest{CaseType: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "536092", resp.CaseID)
+}
+
+func TestClient_PathAndQueryParameters(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/mastercom/v6/queues", r.URL.Path)
+		assert.Equal(t, "Unworked", r.URL.Query().Get("queue-name"))
+
+		json.NewEncoder(w).Encode([]ClaimSummary{{ClaimID: "200002020654"}})
+	})
+
+	summaries, err := client.GetQueueSummary(context.Background(), &GetQueueSummaryParams{QueueName: "Unworked"})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "200002020654", summaries[0].ClaimID)
+
+	client = setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/mastercom/v6/claims/a%2Fb/chargebacks/1/reversal", r.URL.EscapedPath())
+		json.NewEncoder(w).Encode(ChargebackResponse{ChargebackID: "1"})
+	})
+
+	_, err = client.CreateChargebackReversal(context.Background(), "a/b", "1")
+	require.NoError(t, err)
+}
+
+func TestClient_APIError(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Correlation-Id", "corr-1")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"Errors":[{"Source":"SYSTEM","ReasonCode":"INVALID_REQUEST","Description":"Invalid request.","Recoverable":true}]}`))
+	})
+
+	_, err := client.GetClaimDetail(context.Background(), "200002020654")
+	require.Error(t, err)
+
+	apiErr, ok := AsAPIError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "corr-1", apiErr.CorrelationID)
+	assert.True(t, apiErr.HasReasonCode("INVALID_REQUEST"))
+	assert.True(t, apiErr.Recoverable())
+	assert.False(t, apiErr.Temporary())
+	assert.Contains(t, err.Error(), "INVALID_REQUEST: Invalid request.")
+}
+
+func TestClient_GatewayError(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"Errors":{"Error":[{"Source":"GATEWAY","ReasonCode":"SYSTEM_ERROR","Description":"Service unavailable"}]}}`))
+	})
+
+	_, err := client.GetQueues(context.Background())
+	apiErr, ok := AsAPIError(err)
+	require.True(t, ok)
+	assert.True(t, apiErr.HasReasonCode("SYSTEM_ERROR"))
+	assert.True(t, apiErr.Temporary())
+	assert.False(t, apiErr.Recoverable())
+}
+
+func TestClient_ContextCancelled(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetQueues(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, ok := AsAPIError(err)
+	assert.False(t, ok)
+}
+
+func TestNewClient_InvalidBaseURL(t *testing.T) {
+	_, err := NewClient("not a url")
+	assert.Error(t, err)
+
+	client, err := NewClient(SandboxBaseURL, WithUserAgent("test"), WithHTTPClient(http.DefaultClient))
+	require.NoError(t, err)
+	assert.Equal(t, "test", client.userAgent)
+	assert.Equal(t, http.DefaultClient, client.httpClient)
+}
@@ -0,0 +1,100 @@
+package mastercom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned for non-2xx Mastercom responses, carrying the decoded error model
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// CorrelationID is the Mastercard correlation ID of the failed request, when returned
+	CorrelationID string
+	Errors        []Error
+	// Body is the raw response body, kept when it could not be decoded as the error model
+	Body string
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("mastercom: %s %s returned %d", e.Method, e.Path, e.StatusCode)
+	}
+
+	descriptions := make([]string, 0, len(e.Errors))
+	for _, apiErr := range e.Errors {
+		descriptions = append(descriptions, fmt.Sprintf("%s: %s", apiErr.ReasonCode, apiErr.Description))
+	}
+	return fmt.Sprintf("mastercom: %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, strings.Join(descriptions, "; "))
+}
+
+// Recoverable reports whether Mastercom flagged every error as fixable by the client
+func (e *APIError) Recoverable() bool {
+	if len(e.Errors) == 0 {
+		return false
+	}
+	for _, apiErr := range e.Errors {
+		if !apiErr.Recoverable {
+			return false
+		}
+	}
+	return true
+}
+
+// Temporary reports whether the request may succeed if retried unchanged
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// HasReasonCode reports whether any returned error has the given reason code
+func (e *APIError) HasReasonCode(reasonCode string) bool {
+	for _, apiErr := range e.Errors {
+		if apiErr.ReasonCode == reasonCode {
+			return true
+		}
+	}
+	return false
+}
+
+// AsAPIError returns the *APIError in err's chain, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+// gatewayErrors is the error envelope returned by the Mastercard API gateway, which
+// nests the error list one level deeper than the specification's Errors model
+type gatewayErrors struct {
+	Errors struct {
+		Error []Error `json:"Error"`
+	} `json:"Errors"`
+}
+
+func newAPIError(method, path string, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		Method:        method,
+		Path:          path,
+		StatusCode:    resp.StatusCode,
+		CorrelationID: resp.Header.Get("Correlation-Id"),
+	}
+
+	var specErrors Errors
+	if err := json.Unmarshal(body, &specErrors); err == nil && len(specErrors.Errors) > 0 {
+		apiErr.Errors = specErrors.Errors
+		return apiErr
+	}
+
+	var envelope gatewayErrors
+	if err := json.Unmarshal(body, &envelope); err == nil && len(envelope.Errors.Error) > 0 {
+		apiErr.Errors = envelope.Errors.Error
+		return apiErr
+	}
+
+	apiErr.Body = string(body)
+	return apiErr
+}
@@ -0,0 +1,524 @@
+// Command gen generates the typed models and operations of the mastercom client
+// from the Mastercom OpenAPI specification.
+//
+//	go run ./internal/gen -spec ../../mastercom-swagger.yaml -out .
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultTags are the specification tags whose operations the client covers
+const defaultTags = "Case Filing,Claims,Chargebacks,Retrievals,Queues,Reconciliation"
+
+const refPrefix = "#/components/schemas/"
+
+// htmlBreaks strips the HTML line breaks some descriptions contain
+var htmlBreaks = strings.NewReplacer("<br>", "", "<br/>", "", "<br />", "")
+
+// commonInitialisms are words rendered in upper case in Go identifiers
+var commonInitialisms = map[string]string{
+	"Api":  "API",
+	"Arn":  "ARN",
+	"Http": "HTTP",
+	"Ica":  "ICA",
+	"Id":   "ID",
+	"Ids":  "IDs",
+	"Json": "JSON",
+	"Pan":  "PAN",
+	"Url":  "URL",
+}
+
+type spec struct {
+	Paths      map[string]pathItem `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type pathItem struct {
+	Get    *operation `yaml:"get"`
+	Put    *operation `yaml:"put"`
+	Post   *operation `yaml:"post"`
+	Delete *operation `yaml:"delete"`
+	Patch  *operation `yaml:"patch"`
+}
+
+type operation struct {
+	Tags        []string            `yaml:"tags"`
+	Summary     string              `yaml:"summary"`
+	OperationID string              `yaml:"operationId"`
+	Parameters  []parameter         `yaml:"parameters"`
+	RequestBody *content            `yaml:"requestBody"`
+	Responses   map[string]*content `yaml:"responses"`
+}
+
+type parameter struct {
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *schema `yaml:"schema"`
+}
+
+type content struct {
+	Content map[string]struct {
+		Schema *schema `yaml:"schema"`
+	} `yaml:"content"`
+}
+
+type schema struct {
+	Ref         string     `yaml:"$ref"`
+	Type        string     `yaml:"type"`
+	Description string     `yaml:"description"`
+	Required    []string   `yaml:"required"`
+	Items       *schema    `yaml:"items"`
+	Properties  properties `yaml:"properties"`
+}
+
+type property struct {
+	Name   string
+	Schema *schema
+}
+
+// properties keeps schema properties in specification order
+type properties []property
+
+func (p *properties) UnmarshalYAML(node *yaml.Node) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var s schema
+		if err := node.Content[i+1].Decode(&s); err != nil {
+			return err
+		}
+		*p = append(*p, property{Name: node.Content[i].Value, Schema: &s})
+	}
+	return nil
+}
+
+type endpoint struct {
+	Method    string
+	Path      string
+	Operation *operation
+}
+
+func main() {
+	specPath := flag.String("spec", "mastercom-swagger.yaml", "path to the Mastercom OpenAPI specification")
+	outDir := flag.String("out", ".", "directory to write the generated files to")
+	tags := flag.String("tags", defaultTags, "comma separated specification tags to generate operations for")
+	flag.Parse()
+
+	raw, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatalf("read spec: %v", err)
+	}
+
+	files, err := Generate(raw, strings.Split(*tags, ","))
+	if err != nil {
+		log.Fatalf("generate: %v", err)
+	}
+
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(*outDir, name), src, 0o644); err != nil {
+			log.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+// Generate renders the generated client files, keyed by file name
+func Generate(raw []byte, tags []string) (map[string][]byte, error) {
+	var s spec
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+
+	endpoints := selectEndpoints(&s, tags)
+
+	// Models are generated for every schema the operations reach, plus the error model
+	used := map[string]bool{}
+	for _, name := range []string{"Errors", "Error", "ErrorDetail"} {
+		markSchema(&s, name, used)
+	}
+	for _, e := range endpoints {
+		if body := jsonSchema(e.Operation.RequestBody); body != nil {
+			markRefs(&s, body, used)
+		}
+		if resp := jsonSchema(successResponse(e.Operation)); resp != nil {
+			markRefs(&s, resp, used)
+		}
+	}
+
+	models, err := renderModels(&s, used)
+	if err != nil {
+		return nil, err
+	}
+	operations, err := renderOperations(endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"models.gen.go":     models,
+		"operations.gen.go": operations,
+	}, nil
+}
+
+func selectEndpoints(s *spec, tags []string) []endpoint {
+	wanted := map[string]bool{}
+	for _, tag := range tags {
+		wanted[strings.TrimSpace(tag)] = true
+	}
+
+	var endpoints []endpoint
+	for path, item := range s.Paths {
+		for _, e := range []endpoint{
+			{"GET", path, item.Get},
+			{"PUT", path, item.Put},
+			{"POST", path, item.Post},
+			{"DELETE", path, item.Delete},
+			{"PATCH", path, item.Patch},
+		} {
+			if e.Operation == nil || len(e.Operation.Tags) == 0 || !wanted[e.Operation.Tags[0]] {
+				continue
+			}
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		return goName(endpoints[i].Operation.OperationID) < goName(endpoints[j].Operation.OperationID)
+	})
+	return endpoints
+}
+
+func jsonSchema(c *content) *schema {
+	if c == nil {
+		return nil
+	}
+	if media, ok := c.Content["application/json"]; ok {
+		return media.Schema
+	}
+	return nil
+}
+
+func successResponse(op *operation) *content {
+	for _, code := range []string{"200", "201"} {
+		if resp, ok := op.Responses[code]; ok {
+			return resp
+		}
+	}
+	return nil
+}
+
+func markSchema(s *spec, name string, used map[string]bool) {
+	if used[name] {
+		return
+	}
+	def, ok := s.Components.Schemas[name]
+	if !ok {
+		return
+	}
+	used[name] = true
+	markRefs(s, def, used)
+}
+
+func markRefs(s *spec, sch *schema, used map[string]bool) {
+	if sch.Ref != "" {
+		markSchema(s, strings.TrimPrefix(sch.Ref, refPrefix), used)
+		return
+	}
+	if sch.Items != nil {
+		markRefs(s, sch.Items, used)
+	}
+	for _, prop := range sch.Properties {
+		markRefs(s, prop.Schema, used)
+	}
+}
+
+func renderModels(s *spec, used map[string]bool) ([]byte, error) {
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return goName(names[i]) < goName(names[j]) })
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString("package mastercom\n\n")
+
+	for _, name := range names {
+		def := s.Components.Schemas[name]
+		typeName := goName(name)
+		writeComment(&buf, "", typeName, def.Description)
+
+		if def.Type != "object" && len(def.Properties) == 0 {
+			fmt.Fprintf(&buf, "type %s %s\n\n", typeName, goType(def, true))
+			continue
+		}
+
+		required := map[string]bool{}
+		for _, r := range def.Required {
+			required[r] = true
+		}
+
+		fmt.Fprintf(&buf, "type %s struct {\n", typeName)
+		seen := map[string]int{}
+		for _, prop := range def.Properties {
+			fieldName := goName(prop.Name)
+			if seen[fieldName]++; seen[fieldName] > 1 {
+				fieldName = fmt.Sprintf("%s%d", fieldName, seen[fieldName])
+			}
+			tag := prop.Name
+			if !required[prop.Name] {
+				tag += ",omitempty"
+			}
+			writeComment(&buf, "\t", "", prop.Schema.Description)
+			fmt.Fprintf(&buf, "\t%s %s `json:%q`\n", fieldName, goType(prop.Schema, required[prop.Name]), tag)
+		}
+		buf.WriteString("}\n\n")
+	}
+
+	return formatSource(buf.Bytes())
+}
+
+func renderOperations(endpoints []endpoint) ([]byte, error) {
+	var buf bytes.Buffer
+	usesURL := false
+
+	for _, e := range endpoints {
+		op := e.Operation
+		name := goName(op.OperationID)
+
+		var pathParams, queryParams []parameter
+		for _, p := range op.Parameters {
+			switch p.In {
+			case "path":
+				pathParams = append(pathParams, p)
+			case "query":
+				queryParams = append(queryParams, p)
+			}
+		}
+		if len(pathParams) > 0 || len(queryParams) > 0 {
+			usesURL = true
+		}
+
+		paramsType := name + "Params"
+		if len(queryParams) > 0 {
+			fmt.Fprintf(&buf, "// %s holds the query parameters of %s\n", paramsType, name)
+			fmt.Fprintf(&buf, "type %s struct {\n", paramsType)
+			for _, p := range queryParams {
+				writeComment(&buf, "\t", "", p.Description)
+				fmt.Fprintf(&buf, "\t%s string\n", goName(p.Name))
+			}
+			buf.WriteString("}\n\n")
+		}
+
+		args := []string{"ctx context.Context"}
+		for _, p := range pathParams {
+			args = append(args, lowerFirst(goName(p.Name))+" string")
+		}
+		if len(queryParams) > 0 {
+			args = append(args, "params *"+paramsType)
+		}
+		body := jsonSchema(op.RequestBody)
+		if body != nil {
+			args = append(args, "body "+goPointerType(body))
+		}
+
+		result := "struct{}"
+		resp := jsonSchema(successResponse(op))
+		if resp != nil {
+			result = goType(resp, true)
+		}
+
+		summary := strings.TrimSpace(op.Summary)
+		if summary == "" {
+			summary = "calls " + op.OperationID
+		}
+		fmt.Fprintf(&buf, "// %s calls %s %s.\n//\n// %s.\n", name, e.Method, e.Path, strings.TrimSuffix(summary, "."))
+
+		returnType := "*" + result
+		if strings.HasPrefix(result, "[]") {
+			returnType = result
+		}
+		fmt.Fprintf(&buf, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), returnType)
+
+		path := fmt.Sprintf("%q", e.Path)
+		if len(pathParams) > 0 {
+			path = "\"" + e.Path + "\""
+			for _, p := range pathParams {
+				path = strings.Replace(path, "{"+p.Name+"}", "\" + url.PathEscape("+lowerFirst(goName(p.Name))+") + \"", 1)
+			}
+			path = strings.TrimSuffix(path, " + \"\"")
+		}
+		fmt.Fprintf(&buf, "\tpath := %s\n", path)
+
+		query := "nil"
+		if len(queryParams) > 0 {
+			query = "query"
+			buf.WriteString("\tquery := url.Values{}\n\tif params != nil {\n")
+			for _, p := range queryParams {
+				field := goName(p.Name)
+				fmt.Fprintf(&buf, "\t\tif params.%s != \"\" {\n\t\t\tquery.Set(%q, params.%s)\n\t\t}\n", field, p.Name, field)
+			}
+			buf.WriteString("\t}\n")
+		}
+
+		bodyArg := "nil"
+		if body != nil {
+			bodyArg = "body"
+		}
+
+		if strings.HasPrefix(result, "[]") {
+			fmt.Fprintf(&buf, "\tvar out %s\n", result)
+			fmt.Fprintf(&buf, "\tif err := c.do(ctx, http.Method%s, path, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", methodConst(e.Method), query, bodyArg)
+			buf.WriteString("\treturn out, nil\n}\n\n")
+			continue
+		}
+		fmt.Fprintf(&buf, "\tout := new(%s)\n", result)
+		fmt.Fprintf(&buf, "\tif err := c.do(ctx, http.Method%s, path, %s, %s, out); err != nil {\n\t\treturn nil, err\n\t}\n", methodConst(e.Method), query, bodyArg)
+		buf.WriteString("\treturn out, nil\n}\n\n")
+	}
+
+	var file bytes.Buffer
+	file.WriteString(header)
+	file.WriteString("package mastercom\n\n")
+	file.WriteString("import (\n\t\"context\"\n\t\"net/http\"\n")
+	if usesURL {
+		file.WriteString("\t\"net/url\"\n")
+	}
+	file.WriteString(")\n\n")
+	file.Write(buf.Bytes())
+
+	return formatSource(file.Bytes())
+}
+
+const header = "// Code generated by go run ./internal/gen; DO NOT EDIT.\n\n"
+
+func formatSource(src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w\n%s", err, src)
+	}
+	return formatted, nil
+}
+
+// goType returns the Go type of a schema. Optional references to objects are pointers
+// so they are left out of requests when unset.
+func goType(sch *schema, required bool) string {
+	if sch.Ref != "" {
+		name := goName(strings.TrimPrefix(sch.Ref, refPrefix))
+		if !required {
+			return "*" + name
+		}
+		return name
+	}
+
+	switch sch.Type {
+	case "array":
+		if sch.Items == nil {
+			return "[]any"
+		}
+		return "[]" + goType(sch.Items, true)
+	case "boolean":
+		return "bool"
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "string":
+		return "string"
+	default:
+		return "any"
+	}
+}
+
+func goPointerType(sch *schema) string {
+	t := goType(sch, true)
+	if strings.HasPrefix(t, "[]") {
+		return t
+	}
+	return "*" + t
+}
+
+func methodConst(method string) string {
+	return method[:1] + strings.ToLower(method[1:])
+}
+
+// writeComment writes the first line of a description as a comment, prefixed by name when set
+func writeComment(buf *bytes.Buffer, indent, name, description string) {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(description), "\n", 2)[0])
+	line = strings.TrimSpace(htmlBreaks.Replace(line))
+	if line == "" {
+		if name != "" {
+			fmt.Fprintf(buf, "%s// %s is generated from the %s schema.\n", indent, name, name)
+		}
+		return
+	}
+	if name != "" {
+		line = fmt.Sprintf("%s is generated from the %s schema: %s", name, name, line)
+	}
+	fmt.Fprintf(buf, "%s// %s\n", indent, line)
+}
+
+// goName converts a specification identifier into an exported Go identifier
+func goName(s string) string {
+	var words []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words = append(words, splitCamel(part)...)
+	}
+
+	var b strings.Builder
+	for _, word := range words {
+		word = strings.ToUpper(word[:1]) + word[1:]
+		if initialism, ok := commonInitialisms[word]; ok {
+			word = initialism
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+// splitCamel splits a camelCase word before each upper case letter that follows a lower case one
+func splitCamel(s string) []string {
+	var words []string
+	start := 0
+	runes := []rune(s)
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	upper := 0
+	for upper < len(s) && unicode.IsUpper(rune(s[upper])) {
+		upper++
+	}
+	if upper > 1 && upper < len(s) {
+		// The last capital of a leading initialism starts the next word, e.g. ICAList -> icaList
+		upper--
+	}
+	if upper == 0 {
+		return s
+	}
+	return strings.ToLower(s[:upper]) + s[upper:]
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedFilesUpToDate fails when the committed client no longer matches the spec
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "mastercom-swagger.yaml"))
+	if err != nil {
+		t.Fatalf("read spec: %v", err)
+	}
+
+	files, err := Generate(raw, strings.Split(defaultTags, ","))
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join("..", "..", name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date, run make gen-mastercom", name)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	cases := map[string]string{
+		"createCaseFiling":                "CreateCaseFiling",
+		"Update Claim":                    "UpdateClaim",
+		"case-id":                         "CaseID",
+		"getQueueContentRequest":          "GetQueueContentRequest",
+		"refundReversalReferenceId":       "RefundReversalReferenceID",
+		"reconReportDataRetrivalResponse": "ReconReportDataRetrivalResponse",
+	}
+	for in, want := range cases {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if got := lowerFirst("ICAList"); got != "icaList" {
+		t.Errorf("lowerFirst(ICAList) = %q", got)
+	}
+	if got := lowerFirst("ClaimID"); got != "claimID" {
+		t.Errorf("lowerFirst(ClaimID) = %q", got)
+	}
+}
@@ -0,0 +1,1041 @@
+// Code generated by go run ./internal/gen; DO NOT EDIT.
+
+package mastercom
+
+// AcquirerFulfillmentRequest is generated from the AcquirerFulfillmentRequest schema.
+type AcquirerFulfillmentRequest struct {
+	// Acquirer Response Code.
+	AcquirerResponseCd string `json:"acquirerResponseCd"`
+	// Refund/Reversal Type.
+	RefundReversalType string `json:"refundReversalType,omitempty"`
+	// Refund/Reversal Date.
+	RefundReversalDate string `json:"refundReversalDate,omitempty"`
+	// Refund/Reversal Amount.
+	RefundReversalAmount string `json:"refundReversalAmount,omitempty"`
+	// Refund/Reversal Currency.
+	RefundReversalCurrency string `json:"refundReversalCurrency,omitempty"`
+	// For Transaction type Authorized transactions:
+	RefundReversalReferenceID string `json:"refundReversalReferenceId,omitempty"`
+	// Memo.
+	Memo string `json:"memo,omitempty"`
+}
+
+// AcquirerFulfillmentResponse is generated from the AcquirerFulfillmentResponse schema.
+type AcquirerFulfillmentResponse struct {
+	// Same identifier assigned as to the retrieval request. A fulfillment request id will be generated in the background and can be retrieved on a retrieve claim API call.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// CardholderDisputeChargebackDrfDocumentStructure is generated from the CardholderDisputeChargebackDrfDocumentStructure schema.
+type CardholderDisputeChargebackDrfDocumentStructure struct {
+	// The transaction amount.
+	TransactionAmount string `json:"transactionAmount,omitempty"`
+	// Type of Cardholder Dispute.
+	Type string `json:"type,omitempty"`
+	// CONDITIONAL: In case of PRODUCT_DISPUTE, delivery date of the goods or services.
+	DeliveryDateOfGoodsOrServices string `json:"deliveryDateOfGoodsOrServices,omitempty"`
+	// CONDITIONAL: In case of NOT_PROVIDED, expected delivery date of the goods or services.
+	ExpectedDeliveryDateOfGoodOrServices string `json:"expectedDeliveryDateOfGoodOrServices,omitempty"`
+	// CONDITIONAL: In case of DIGITAL_GOODS, return or cancellation of the goods or services.
+	ReturnDate string `json:"returnDate,omitempty"`
+	// CONDITIONAL: In case of RECURRING_CANCELLED, cancellation of the goods or services.
+	CancellationDate string `json:"cancellationDate,omitempty"`
+	// Did the cardholder participate in the transaction?
+	CardholderParticipation bool `json:"cardholderParticipation,omitempty"`
+	// Describe the cardholder’s compliant in sufficient detail to meet the requirements for the chargeback as described in the Chargeback Guide and to enable all parties to understand the dispute.
+	DisputeDetails string `json:"disputeDetails,omitempty"`
+	// Customer Service/Chargeback Representative.
+	ChargebackRepresentative string `json:"chargebackRepresentative,omitempty"`
+}
+
+// CardholderVerificationMethodStructure is generated from the CardholderVerificationMethodStructure schema.
+type CardholderVerificationMethodStructure struct {
+	// Online PIN Preferring.
+	OnlinePIN string `json:"onlinePIN,omitempty"`
+	// Offline PIN.
+	OfflinePIN string `json:"offlinePIN,omitempty"`
+	// Signature
+	Signature string `json:"signature,omitempty"`
+	// None (No CVM)
+	None string `json:"none,omitempty"`
+}
+
+// CaseFilingClaim is generated from the CaseFilingClaim schema.
+type CaseFilingClaim struct {
+	// The case filing id
+	CaseID string `json:"caseId,omitempty"`
+	// The associated claimId.
+	ClaimID string `json:"claimId,omitempty"`
+}
+
+// CaseFilingClaimsRequest is generated from the CaseFilingClaimsRequest schema.
+type CaseFilingClaimsRequest struct {
+	// A list of case filing ids, maximum list size is 2000
+	CaseFilingList []CaseFilingIDSourceRequest `json:"caseFilingList"`
+}
+
+// CaseFilingClaimsResponse is generated from the CaseFilingClaimsResponse schema.
+type CaseFilingClaimsResponse struct {
+	// A list of case ids and its associated claim ids.
+	CaseFilingResponseList []CaseFilingClaim `json:"caseFilingResponseList,omitempty"`
+}
+
+// CaseFilingDetails is generated from the CaseFilingDetails schema.
+type CaseFilingDetails struct {
+	// Claim identifier associated with the standard claimId
+	ClaimID string `json:"claimId,omitempty"`
+	// Claim Type
+	ClaimType string `json:"claimType,omitempty"`
+	// Identifier assigned to the case filing
+	CaseID string `json:"caseId,omitempty"`
+	// Type of Case Filing. The following number values represent each case type.  1-Pre-arbitration  2-Arbitration  3-Pre-compliance 4-Compliance
+	CaseType string `json:"caseType,omitempty"`
+	// A list of Chargeback Reference numbers
+	ChargebackRefNum []string `json:"chargebackRefNum,omitempty"`
+	// The case currency. Value should be standard alpha currency code.
+	CurrencyCode string `json:"currencyCode,omitempty"`
+	// Customer filing number which is the filing party's internal number
+	CustomerFilingNumber string `json:"customerFilingNumber,omitempty"`
+	// Credit date when the violationCode is 1.4 in the case of pre-compliance or compliance case filing. The format should be yyyy-MM-dd
+	CreditDate string `json:"creditDate,omitempty"`
+	// Chargeback date when the violationCode is 1.4 in the case of pre-compliance or compliance case filing. The format should be yyyy-MM-dd
+	ChargebackDate string `json:"chargebackDate,omitempty"`
+	// Reason code is returned when the case type is pre-arbitration or arbitration.
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// Dispute amount.  The currency will be determined by the ICA region entered in the Filed ICA and Filed Against ICA
+	DisputeAmount string `json:"disputeAmount,omitempty"`
+	// Due date when the response is required.  The format should be yyyy-MM-dd
+	DueDate string `json:"dueDate,omitempty"`
+	// Filed Against ICA
+	FilingAgaintstICA string `json:"filingAgaintstIca,omitempty"`
+	// Filing case as Issuer or Acquirer. Following values represents each type I-ISSUER  A-ACQUIRER
+	FilingAs string `json:"filingAs,omitempty"`
+	// Filing ICA
+	FilingICA string `json:"filingIca,omitempty"`
+	// Merchant name for filing pre-arbitration and arbitration case
+	MerchantName string `json:"merchantName,omitempty"`
+	// The primary account number
+	PrimaryAccountNum string `json:"primaryAccountNum,omitempty"`
+	// Violation code
+	ViolationCode string `json:"violationCode,omitempty"`
+	// Violation Date
+	ViolationDate string `json:"violationDate,omitempty"`
+	// Ruling Date
+	RulingDate string `json:"rulingDate,omitempty"`
+	// Ruling Status.  Valid values are Reviewed, Filed In Error, Declined, Expired, Favor Sender, Favor Receiver
+	RulingStatus string `json:"rulingStatus,omitempty"`
+	// The virtual account number
+	VirtualAccountNum string `json:"virtualAccountNum,omitempty"`
+}
+
+// CaseFilingEbdfStructure is generated from the CaseFilingEbdfStructure schema: When CaseFilingEbdfDocuments is used for automatic EBDF document generation of expeditedBillingDrfDocument  (form name of Dispute Resolution Form - Pre-Compliance/Compliance)  or smsLinkedCaseFilingDrfDocument no other documents should be attached on the call. Attaching documents will lead to call failure.
+type CaseFilingEbdfStructure struct {
+	ExpeditedBillingDrfDocument    *ExpeditedBillingDrfDocumentStructure    `json:"expeditedBillingDrfDocument,omitempty"`
+	SmsLinkedCaseFilingDrfDocument *SmsLinkedCaseFilingDrfDocumentStructure `json:"smsLinkedCaseFilingDrfDocument,omitempty"`
+}
+
+// CaseFilingIDSourceRequest is generated from the CaseFilingIDSourceRequest schema.
+type CaseFilingIDSourceRequest struct {
+	// The case filing id.
+	CaseID string `json:"caseId"`
+	// In a case filling context, if 'true' the caller is on the sender side, if 'false' on the receiver side.
+	IsIssuer bool `json:"isIssuer"`
+}
+
+// CaseFilingImageStatusRequest is generated from the CaseFilingImageStatusRequest schema.
+type CaseFilingImageStatusRequest struct {
+	// Case filing image status.
+	Status string `json:"status"`
+	// Case filing image processing start date.
+	StartDate string `json:"startDate"`
+	// Case filing image processing end date.
+	EndDate string `json:"endDate"`
+}
+
+// CaseFilingImageStatusResponse is generated from the CaseFilingImageStatusResponse schema.
+type CaseFilingImageStatusResponse struct {
+	// A list of case filing image statuses
+	CaseFilingImageStatusList []CaseFilingImageStatusResponseStructure `json:"caseFilingImageStatusList,omitempty"`
+}
+
+// CaseFilingImageStatusResponseStructure is generated from the CaseFilingImageStatusResponseStructure schema.
+type CaseFilingImageStatusResponseStructure struct {
+	// Case Id
+	CaseID string `json:"caseId,omitempty"`
+	// Status of case filing images, the valid values are: COMPLETED, FAILED, UNPROCESSED.COMPLETED: Image was processed and no further action required. FAILED: Some failure happened during image process flow, i.e,The image could not be converted, The image is not imported, Image extension not supported etc.UNPROCESSED: The image is unavailable because it is not picked up by mastercom internal processes yet.
+	Status string `json:"status,omitempty"`
+}
+
+// CaseFilingLifeCycle is generated from the CaseFilingLifeCycle schema.
+type CaseFilingLifeCycle struct {
+	// Case Filing Status
+	CaseFilingStatus      string                  `json:"caseFilingStatus,omitempty"`
+	CaseFilingDetails     *CaseFilingDetails      `json:"caseFilingDetails,omitempty"`
+	CaseFilingRespHistory []CaseFilingRespHistory `json:"caseFilingRespHistory,omitempty"`
+}
+
+// CaseFilingRespHistory is generated from the CaseFilingRespHistory schema.
+type CaseFilingRespHistory struct {
+	// Memo pertaining to the case
+	Memo string `json:"memo,omitempty"`
+	// Action taken by party.
+	Action string `json:"action,omitempty"`
+	// The date and the response was provided
+	ResponseDate string `json:"responseDate,omitempty"`
+}
+
+// CaseFilingResponse is generated from the CaseFilingResponse schema.
+type CaseFilingResponse struct {
+	// The case filing id
+	CaseID string `json:"caseId,omitempty"`
+}
+
+// CaseFilingStatusRequest is generated from the CaseFilingStatusRequest schema.
+type CaseFilingStatusRequest struct {
+	// A list of case filing ids to query, maximum list size is 2000
+	CaseFilingList []CaseFilingStatusRequestStructure `json:"caseFilingList"`
+}
+
+// CaseFilingStatusRequestStructure is generated from the CaseFilingStatusRequestStructure schema.
+type CaseFilingStatusRequestStructure struct {
+	// Case Id
+	CaseID string `json:"caseId"`
+}
+
+// CaseFilingStatusResponse is generated from the CaseFilingStatusResponse schema.
+type CaseFilingStatusResponse struct {
+	// A list of case filing statuses
+	CaseFilingResponseList []CaseFilingStatusResponseStructure `json:"caseFilingResponseList,omitempty"`
+}
+
+// CaseFilingStatusResponseStructure is generated from the CaseFilingStatusResponseStructure schema.
+type CaseFilingStatusResponseStructure struct {
+	// Case Id
+	CaseID string `json:"caseId,omitempty"`
+	// Status of case filing images.
+	Status string `json:"status,omitempty"`
+}
+
+// ChargebackAmountNameValueDetail is generated from the ChargebackAmountNameValueDetail schema.
+type ChargebackAmountNameValueDetail struct {
+	// The name of the element
+	Name string `json:"name,omitempty"`
+	// The value of the element
+	Value string `json:"value,omitempty"`
+}
+
+// ChargebackDetails is generated from the ChargebackDetails schema.
+type ChargebackDetails struct {
+	// The chargeback currency.  The data should be standard currency alpha code
+	Currency string `json:"currency,omitempty"`
+	// This is the date of the chargeback creation
+	CreateDate string `json:"createDate,omitempty"`
+	// Document Indicator
+	DocumentIndicator string `json:"documentIndicator,omitempty"`
+	// Member message text to be used for the chargeback
+	MessageText string `json:"messageText,omitempty"`
+	// Chargeback Amount
+	Amount string `json:"amount,omitempty"`
+	// Chargeback Reason Code
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// Indicates a partial chargeback
+	IsPartialChargeback bool `json:"isPartialChargeback,omitempty"`
+	// Provide the chargeback type.  The following values are valid - CHARGEBACK, SECOND_PRESENTMENT
+	ChargebackType string `json:"chargebackType,omitempty"`
+	// Identifier assigned to the Chargeback
+	ChargebackID string `json:"chargebackId,omitempty"`
+	// Claim identifier
+	ClaimID string `json:"claimId,omitempty"`
+	// Indicates this chargeback has been reversed
+	Reversed bool `json:"reversed,omitempty"`
+	// Indicates this chargeback is a reversal chargeback
+	Reversal bool `json:"reversal,omitempty"`
+	// Contains card issuer reference data for a specific cardholder transaction. This number must be unique within BIN. It is used to track the chargeback throughout its life cycle
+	ChargebackRefNum string `json:"chargebackRefNum,omitempty"`
+	// The document status on chargebacks is helpful for customers to identify the chargebacks that need documents to be uploaded within the stipulated 8 days limit for uploading documentation after chargeback creation.
+	DocumentStatus string `json:"documentStatus,omitempty"`
+	// Reconciliation amount of the chargeback type. Amount will only be retrieved by the receiver side of the dispute cycle.
+	ReconciliationAmount string `json:"reconciliationAmount,omitempty"`
+	// Reconciliation currency of the chargeback type. Currency will only be retrieved by the receiver side of the dispute cycle.
+	ReconciliationCurrency string `json:"reconciliationCurrency,omitempty"`
+	// Reason for the reject.
+	RejectReason string `json:"rejectReason,omitempty"`
+	// Edit exclusion code to bypass clearing system edits. Valid Values - Y ,B0,B1,B2,B3,B4,B5,B6,B7,B8,B9,BA,BB,BC,BD,BE,BF,BG,BH,BI,BJ,BK,BL,BM,BN,BO,BP,BQ,BR,BS,BT,BU,BV,BW,BX,BY,BZ,SPACES.
+	EditExclusionCode string `json:"editExclusionCode,omitempty"`
+	// Cardholder/Issuer did not receive refund when a first chargeback was rejected by Collaboration with reason code 5000 indicating refund provided. 20 days after rejection of CB through collaboration. This field is only applicable if chargebackType is CHARGEBACK. Valid values are: true, false.
+	RefundNotReceivedIndicator string `json:"refundNotReceivedIndicator,omitempty"`
+	// The actual status of the credit voucher
+	CreditVoucherStatus string `json:"creditVoucherStatus,omitempty"`
+	// Currency Conversion Assessment amount applied for full first chargeback, to indicate, if Currency Conversion Assessment was included or not for qualified transactions.
+	CurrencyConversionAssessmentCCAIncluded string `json:"currencyConversionAssessmentCCAIncluded,omitempty"`
+	// Currency Conversion Assessment amount Fee associated with full first chargeback.
+	CurrencyConversionAssessmentCCAAmount string `json:"currencyConversionAssessmentCCAAmount,omitempty"`
+	// Identifies the merchant's category in Japan referred to as the Common Merchant Category Code (CMC). Mastercard uses this value to identify a link to a corresponding Mastercard Assigned ID.
+	JapanCommonMerchantCode string `json:"japanCommonMerchantCode,omitempty"`
+	// Provides information about the installment payment option selected by the cardholder at the point of interaction.
+	InstallmentData string `json:"installmentData,omitempty"`
+	// Specific to Brazil Flex Card transactions to communicate the product code used for clearing.
+	FlexCode string `json:"flexCode,omitempty"`
+	// Issuer has acknowledged First-Party Trust evidence and proceeded with the chargeback.
+	AcknowledgeFirstPartyTrustEvidence string `json:"acknowledgeFirstPartyTrustEvidence,omitempty"`
+}
+
+// ChargebackDocIndicatorsNameValueDetail is generated from the ChargebackDocIndicatorsNameValueDetail schema.
+type ChargebackDocIndicatorsNameValueDetail struct {
+	// The name of the element
+	Name string `json:"name,omitempty"`
+	// The value of the element
+	Value string `json:"value,omitempty"`
+}
+
+// ChargebackEbdfStructure is generated from the ChargebackEbdfStructure schema: NOTE: When chargebackEbdfDocuments is used for automatic EBDF document generation for transactionInformation (or) fraudDrfDocument (or) cardholderDisputeChargebackDrfDocument (or) pointOfInteractionErrorsDrfDocument, no other documents should be attached on the call and documentIndicator must be set to true. Attaching documents or setting documentIndicator to false will lead to call failure.
+type ChargebackEbdfStructure struct {
+	TransactionInformation                 *TransactionInformationEbdfStructure             `json:"transactionInformation,omitempty"`
+	FraudDrfDocument                       *FraudDrfDocumentStructure                       `json:"fraudDrfDocument,omitempty"`
+	CardholderDisputeChargebackDrfDocument *CardholderDisputeChargebackDrfDocumentStructure `json:"cardholderDisputeChargebackDrfDocument,omitempty"`
+	PointOfInteractionErrorsDrfDocument    *PointOfInteractionErrorsDrfDocumentStructure    `json:"pointOfInteractionErrorsDrfDocument,omitempty"`
+}
+
+// ChargebackMarkProcessedRequest is generated from the ChargebackMarkProcessedRequest schema.
+type ChargebackMarkProcessedRequest struct {
+	// A list of Chargeback Ids to acknowledge, maximum list size is 500.
+	ChargebackList []ChargebackMarkProcessedRequestStructure `json:"chargebackList"`
+}
+
+// ChargebackMarkProcessedRequestStructure is generated from the ChargebackMarkProcessedRequestStructure schema.
+type ChargebackMarkProcessedRequestStructure struct {
+	// Claim Id.
+	ClaimID string `json:"claimId"`
+	// Chargeback Id.
+	ChargebackID string `json:"chargebackId"`
+}
+
+// ChargebackMarkProcessedResponse is generated from the ChargebackMarkProcessedResponse schema.
+type ChargebackMarkProcessedResponse struct {
+	// A list of Chargeback statuses
+	ChargebackResponseList []ChargebackMarkProcessedResponseStructure `json:"chargebackResponseList,omitempty"`
+}
+
+// ChargebackMarkProcessedResponseStructure is generated from the ChargebackMarkProcessedResponseStructure schema.
+type ChargebackMarkProcessedResponseStructure struct {
+	// Chargeback Id marked as processed
+	ChargebackID string `json:"chargebackId,omitempty"`
+	// The status of the chargeback processing.
+	Status string `json:"status,omitempty"`
+	// The failure reason of the chargeback processing
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// ChargebackMessageTextsNameValueDetail is generated from the ChargebackMessageTextsNameValueDetail schema.
+type ChargebackMessageTextsNameValueDetail struct {
+	// The name of the element
+	Name string `json:"name,omitempty"`
+	// The value of the element
+	Value string `json:"value,omitempty"`
+}
+
+// ChargebackReasonCodesNameValueDetail is generated from the ChargebackReasonCodesNameValueDetail schema.
+type ChargebackReasonCodesNameValueDetail struct {
+	// The name of the element
+	Name string `json:"name,omitempty"`
+	// The value of the element
+	Value string `json:"value,omitempty"`
+}
+
+// ChargebackResponse is generated from the ChargebackResponse schema.
+type ChargebackResponse struct {
+	// Identifier assigned to the Chargeback
+	ChargebackID string `json:"chargebackId,omitempty"`
+}
+
+// ChargebackStatusRequest is generated from the ChargebackStatusRequest schema.
+type ChargebackStatusRequest struct {
+	// A list of Chargeback Ids to query, maximum list size is 2000
+	ChargebackList []ChargebackStatusRequestStructure `json:"chargebackList"`
+}
+
+// ChargebackStatusRequestStructure is generated from the ChargebackStatusRequestStructure schema.
+type ChargebackStatusRequestStructure struct {
+	// Claim Id.
+	ClaimID string `json:"claimId"`
+	// Chargeback Id.
+	ChargebackID string `json:"chargebackId"`
+}
+
+// ChargebackStatusResponse is generated from the ChargebackStatusResponse schema.
+type ChargebackStatusResponse struct {
+	// A list of chargeback image statuses
+	ChargebackResponseList []ChargebackStatusResponseStructure `json:"chargebackResponseList,omitempty"`
+}
+
+// ChargebackStatusResponseStructure is generated from the ChargebackStatusResponseStructure schema.
+type ChargebackStatusResponseStructure struct {
+	// Chargeback Id
+	ChargebackID string `json:"chargebackId,omitempty"`
+	// Claim Id
+	ClaimID string `json:"claimId,omitempty"`
+	// Status of chargebacks, the valid values are: COMPLETED, FAILED, PENDING, UNAVAILABLE AND DOC_NOT_APPLICABLE. COMPLETED: Image was processed and no further action required. FAILED: Some failure happened during image process flow, i.e,The image could not be converted, The image is not imported, Image extension not supported etc. PENDING: The image is pending to be processed. DOC_NOT_APPLICABLE: The dispute does not require a document. UNAVAILABLE: The image is unavailable because it is not picked up by mastercom internal processes yet.
+	Status string `json:"status,omitempty"`
+}
+
+// ClaimDetail is generated from the ClaimDetail schema.
+type ClaimDetail struct {
+	// Acquirer Inst Id
+	AcquirerID string `json:"acquirerId,omitempty"`
+	// Acquirer Reference Number
+	AcquirerRefNum string `json:"acquirerRefNum,omitempty"`
+	// Card Number for which the Claim is opened
+	PrimaryAccountNum string `json:"primaryAccountNum,omitempty"`
+	// Claim Id
+	ClaimID string `json:"claimId,omitempty"`
+	// Claim Type
+	ClaimType string `json:"claimType,omitempty"`
+	// The value of the claim
+	ClaimValue string `json:"claimValue,omitempty"`
+	// Contain all Standard Claim Ids associated with the claimType of CaseFiling.  This field will contain a comma delimited list.
+	StandardClaims string `json:"standardClaims,omitempty"`
+	// The clearing due date of the claim
+	ClearingDueDate string `json:"clearingDueDate,omitempty"`
+	// Clearing Network
+	ClearingNetwork string `json:"clearingNetwork,omitempty"`
+	// This is the date of the Claim creation
+	CreateDate string `json:"createDate,omitempty"`
+	// The due date of the claim
+	DueDate string `json:"dueDate,omitempty"`
+	// An alphanumeric identifier that ties the clearingTransactionId and authTransactionId to the Claim. The format is TI:<ClearingSummary.transactionId>#<AuthorizationSummary.transactionId>
+	TransactionID string `json:"transactionId,omitempty"`
+	// True if the claim value is accurate
+	IsAccurate string `json:"isAccurate,omitempty"`
+	// True if the claim is acquirer
+	IsAcquirer string `json:"isAcquirer,omitempty"`
+	// True if the claim is issuer
+	IsIssuer string `json:"isIssuer,omitempty"`
+	// True if the claim is open
+	IsOpen string `json:"isOpen,omitempty"`
+	// The issuer institution identifier
+	IssuerID string `json:"issuerId,omitempty"`
+	// User who signed this event
+	LastModifiedBy string `json:"lastModifiedBy,omitempty"`
+	// The date of the last claim modification
+	LastModifiedDate string `json:"lastModifiedDate,omitempty"`
+	// Returns the related merchant identifier
+	MerchantID string `json:"merchantId,omitempty"`
+	// The queue name to which the claim has been allocated
+	QueueName string `json:"queueName,omitempty"`
+	// The Switch Serial Number is a unique transaction identification number generated (or assigned) by the Single Message System
+	SwitchSerialNumber string `json:"switchSerialNumber,omitempty"`
+	// Identifier assigned by Mastercom to the fraud reporting event reported through Mastercom. The auditControNumber is used as a reference in the FLD request API to subsequently modify, delete or convert a suspended to a confirmed fraud record and is echoed back.
+	AuditControlNumber   string               `json:"auditControlNumber,omitempty"`
+	CaseFilingDetails    *CaseFilingLifeCycle `json:"caseFilingDetails,omitempty"`
+	RetrievalDetails     *RetrievalSummary    `json:"retrievalDetails,omitempty"`
+	ChargebackDetails    []ChargebackDetails  `json:"chargebackDetails,omitempty"`
+	FeeDetails           []FeeDetails         `json:"feeDetails,omitempty"`
+	RetrievalDetailsList []RetrievalSummary   `json:"retrievalDetailsList,omitempty"`
+}
+
+// ClaimResponse is generated from the ClaimResponse schema.
+type ClaimResponse struct {
+	// Identifier assigned to the Claim
+	ClaimID string `json:"claimId,omitempty"`
+}
+
+// ClaimSummary is generated from the ClaimSummary schema.
+type ClaimSummary struct {
+	// Acquirer Inst Id
+	AcquirerID string `json:"acquirerId,omitempty"`
+	// Acquirer Reference Number
+	AcquirerRefNum string `json:"acquirerRefNum,omitempty"`
+	// Card Number for which the Claim is opened
+	PrimaryAccountNum string `json:"primaryAccountNum,omitempty"`
+	// Claim Id
+	ClaimID string `json:"claimId,omitempty"`
+	// Claim Type
+	ClaimType string `json:"claimType,omitempty"`
+	// The value of the claim
+	ClaimValue string `json:"claimValue,omitempty"`
+	// The clearing due date of the claim
+	ClearingDueDate string `json:"clearingDueDate,omitempty"`
+	// Clearing Network
+	ClearingNetwork string `json:"clearingNetwork,omitempty"`
+	// This is the date of the Claim creation
+	CreateDate string `json:"createDate,omitempty"`
+	// The due date of the claim
+	DueDate string `json:"dueDate,omitempty"`
+	// A 9 digit numeric identifier used by mastercom internal processes and it is not equivalent to clearing or authorization transaction id.
+	TransactionID string `json:"transactionId,omitempty"`
+	// True if the claim value is accurate
+	IsAccurate bool `json:"isAccurate,omitempty"`
+	// True if the claim is acquirer
+	IsAcquirer bool `json:"isAcquirer,omitempty"`
+	// True if the claim is issuer
+	IsIssuer bool `json:"isIssuer,omitempty"`
+	// True if the claim is open
+	IsOpen bool `json:"isOpen,omitempty"`
+	// The issuer institution identifier
+	IssuerID string `json:"issuerId,omitempty"`
+	// User who signed this event
+	LastModifiedBy string `json:"lastModifiedBy,omitempty"`
+	// The date of the last claim modification
+	LastModifiedDate string `json:"lastModifiedDate,omitempty"`
+	// Returns the related merchant identifier
+	MerchantID string `json:"merchantId,omitempty"`
+	// The progress state of the claim
+	ProgressState string `json:"progressState,omitempty"`
+	// The queue name to which the claim has been allocated
+	QueueName string `json:"queueName,omitempty"`
+	// The actual status of the credit voucher
+	CreditVoucherStatus string `json:"creditVoucherStatus,omitempty"`
+	// Date and time by which the acquirer can respond to a Collaboration request.
+	CollaborationExpirationDateTime string `json:"collaborationExpirationDateTime,omitempty"`
+}
+
+// CreateCaseRequest is generated from the CreateCaseRequest schema.
+type CreateCaseRequest struct {
+	// Type of Case Filing.
+	CaseType string `json:"caseType"`
+	// A list of Chargeback Reference numbers.
+	ChargebackRefNum []string `json:"chargebackRefNum,omitempty"`
+	// Customer filing number which is the filing party's internal number.
+	CustomerFilingNumber string `json:"customerFilingNumber,omitempty"`
+	// Dispute amount. The currency will be determined by the ICA region entered in the Filed ICA and Filed Against ICA.
+	DisputeAmount string `json:"disputeAmount"`
+	// The case currency. Value should be standard alpha currency code.
+	CurrencyCode string `json:"currencyCode"`
+	// Due date when the response is required.
+	DueDate        string             `json:"dueDate,omitempty"`
+	FileAttachment *DocumentStructure `json:"fileAttachment,omitempty"`
+	// Filed Against ICA.
+	FiledAgainstICA string `json:"filedAgainstIca"`
+	// Filing case as Issuer or Acquirer.
+	FilingAs string `json:"filingAs"`
+	// Filing ICA.
+	FilingICA string `json:"filingIca"`
+	// Enter a Memo pertaining to the case.
+	Memo string `json:"memo"`
+	// Enter a MessageText pertaining to the case.
+	MessageText string `json:"messageText,omitempty"`
+	// Change reason code Flag.
+	ChangeReasonCodeFlag string `json:"changeReasonCodeFlag,omitempty"`
+	// Updated Chargeback Reason Code.
+	UpdatedChargebackReasonCode string `json:"updatedChargebackReasonCode,omitempty"`
+	// Change reason Code reason.
+	ChangeReasonCodeReason string `json:"changeReasonCodeReason,omitempty"`
+	// The primary account number.
+	PrimaryAccountNum string `json:"primaryAccountNum,omitempty"`
+	// The acquirer reference number.
+	AcquirerRefNum string `json:"acquirerRefNum,omitempty"`
+	// Chargeback Reason Code.
+	ChargebackReasonCode string `json:"chargebackReasonCode,omitempty"`
+	// Merchant name.
+	MerchantName string `json:"merchantName,omitempty"`
+	// Violation code.
+	ViolationCode string `json:"violationCode,omitempty"`
+	// Violation Date.
+	ViolationDate string `json:"violationDate,omitempty"`
+	// Chargeback Date.
+	ChargebackDate string `json:"chargebackDate,omitempty"`
+	// Credit Date.
+	CreditDate              string                   `json:"creditDate,omitempty"`
+	CaseFilingEbdfDocuments *CaseFilingEbdfStructure `json:"caseFilingEbdfDocuments,omitempty"`
+}
+
+// CreateChargebackRequest is generated from the CreateChargebackRequest schema.
+type CreateChargebackRequest struct {
+	// Amount of CB should be OT amount (DE4). US Issuers should always submit in USD. For more details refer to the GCMS Reference Manual.
+	Amount string `json:"amount"`
+	// Provide the chargeback.
+	ChargebackType string `json:"chargebackType"`
+	// The chargeback currency. The data should be standard currency alpha code or numeric code. Currency should correspond with the amount submitted for chargeback creation Length: 3 Valid Values/Format: A-Z (Uppercase Alphabetic Letter) OR Numeric
+	Currency string `json:"currency"`
+	// Document Indicator defines if a document is required for the dispute.
+	DocumentIndicator string `json:"documentIndicator"`
+	// Chargeback Reason Code provides the chargeback receiver with the reason for sending the chargeback.
+	ReasonCode string `json:"reasonCode"`
+	// Indicator to notify this is a credit posted as a purchase.
+	CredPostedAsPurchase bool `json:"credPostedAsPurchase,omitempty"`
+	// Indicates a partial chargeback.  Defaults to false.
+	IsPartialChargeback bool `json:"isPartialChargeback,omitempty"`
+	// Member message text to be used for the chargeback.
+	MessageText string `json:"messageText,omitempty"`
+	// CONDITIONAL: Required for Argentina and Uruguay's Settlement Service participation ID codes (LA00003201, LA00003202, LA00085801, LA00085802, LA00084011, LA00084012). The date may not be prior to the current date or beyond 90 days from the current date.
+	SettlementDate string `json:"settlementDate,omitempty"`
+	// Accepts a chargeback ID when 'chargebackType' is set to 'SECOND_PRESENTMENT'
+	DisputeChargebackID string `json:"disputeChargebackID,omitempty"`
+	// Applies only to LAC installments (Argentina and Uruguay). PDS 1015. Contains the VAT amount for the installment fee.
+	LocalTax1IVA string `json:"localTax1IVA,omitempty"`
+	// Applies only to LAC installments (Argentina and Uruguay). PDS 1028. Contains the VAT amount for the installment fee.
+	InstallmentFee string `json:"installmentFee,omitempty"`
+	// Edit exclusion code to bypass clearing system edits.
+	EditExclusionCode string `json:"editExclusionCode,omitempty"`
+	// Cardholder/Issuer did not receive refund when a first chargeback was rejected by Collaboration with reason code 5000 indicating refund provided 20 days after rejection of CB through collaboration.
+	RefundNotReceivedIndicator string `json:"refundNotReceivedIndicator,omitempty"`
+	// Currency Conversion Assessment amount applied for full first chargeback, to indicate, if Currency Conversion Assessment was included or not for qualified transactions.
+	IncludeCurrencyConversionAssessmentCCA string `json:"includeCurrencyConversionAssessmentCCA,omitempty"`
+	// Issuer has acknowledged First-Party Trust evidence and proceeded with the chargeback. Defaults to false. This field is not applicable to second presentments.
+	AcknowledgeFirstPartyTrustEvidence bool                     `json:"acknowledgeFirstPartyTrustEvidence,omitempty"`
+	FileAttachment                     *DocumentStructure       `json:"fileAttachment,omitempty"`
+	ChargebackEbdfDocuments            *ChargebackEbdfStructure `json:"chargebackEbdfDocuments,omitempty"`
+}
+
+// CreateClaimRequest is generated from the CreateClaimRequest schema.
+type CreateClaimRequest struct {
+	// The total amount of the original transaction in the dispute process. The disputedAmount should be equal to the original transaction amount (DE4) from the clearing record.
+	DisputedAmount string `json:"disputedAmount"`
+	// Currency of amount disputed in the claim. disputedCurrency can be provided as standard alpha code or numeric code
+	DisputedCurrency string `json:"disputedCurrency"`
+	// Type of claim to be created.
+	ClaimType string `json:"claimType"`
+	// The Clearing Transaction Identifier from Clearing Summary Results.
+	ClearingTransactionID string `json:"clearingTransactionId"`
+	// The Authorization Transaction Identifier from Authorization Summary Results.
+	AuthTransactionID string `json:"authTransactionId,omitempty"`
+}
+
+// CreateRetrievalRequest is generated from the CreateRetrievalRequest schema.
+type CreateRetrievalRequest struct {
+	// Retrieval Request Reason Codes.
+	RetrievalRequestReason string `json:"retrievalRequestReason"`
+	// Documentation Needed Indicator.
+	DocNeeded string `json:"docNeeded"`
+	// Instructions for Healthcare.
+	InstructionsForHealthcare string `json:"instructionsForHealthcare,omitempty"`
+}
+
+// CreateRetrievalResponse is generated from the CreateRetrievalResponse schema.
+type CreateRetrievalResponse struct {
+	// Identifier assigned to the retrieval request.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// CurrenciesNameValueDetail is generated from the CurrenciesNameValueDetail schema.
+type CurrenciesNameValueDetail struct {
+	// The name of the element
+	Name string `json:"name,omitempty"`
+	// The value of the element
+	Value string `json:"value,omitempty"`
+}
+
+// Cycle is generated from the Cycle schema: Optional cycle values from 1 to 7.
+type Cycle int
+
+// DocumentResponseStructure is generated from the DocumentResponseStructure schema.
+type DocumentResponseStructure struct {
+	FileAttachment *DocumentStructureResp `json:"fileAttachment,omitempty"`
+}
+
+// DocumentStructure is generated from the DocumentStructure schema: CONDITIONAL: Unless specified as REQUIRED, fileAttachment object is OPTIONAL. When fileAttachment is provided, then fileName and file parameters are required. The base64 encoded string must represent a ZIP, JPG, TIFF, or PDF file. Please note: ZIP files may contain JPG, TIFF or PDF files.
+type DocumentStructure struct {
+	// File name of image.
+	Filename string `json:"filename,omitempty"`
+	// File converted to a base64 encoded string.
+	File string `json:"file,omitempty"`
+}
+
+// DocumentStructureResp is generated from the DocumentStructureResp schema.
+type DocumentStructureResp struct {
+	// File name of image.  The filename will have an extension of .zip.
+	Filename string `json:"filename,omitempty"`
+	// File converted to a base64 encoded string.  File Format is ZIP  Note: ZIP file may contain these formats...JPG, TIFF, PDF
+	File string `json:"file,omitempty"`
+}
+
+// Error is generated from the Error schema.
+type Error struct {
+	// Request id for the error
+	RequestID string `json:"RequestId,omitempty"`
+	// Source for the error
+	Source string `json:"Source,omitempty"`
+	// Reason code for error
+	ReasonCode string `json:"ReasonCode,omitempty"`
+	// Brief description of error
+	Description string `json:"Description,omitempty"`
+	// Indicates whether the client can make changes to resolve this issue
+	Recoverable bool `json:"Recoverable,omitempty"`
+	// Detail structure containing error detail code
+	Details []ErrorDetail `json:"Details,omitempty"`
+}
+
+// ErrorDetail is generated from the ErrorDetail schema.
+type ErrorDetail struct {
+	// Type of information provided by the element
+	Name string `json:"Name,omitempty"`
+	// The value of the element
+	Value string `json:"Value,omitempty"`
+}
+
+// Errors is generated from the Errors schema.
+type Errors struct {
+	// List of Errors returned to service
+	Errors []Error `json:"Errors,omitempty"`
+}
+
+// ExpeditedBillingDrfDocumentStructure is generated from the ExpeditedBillingDrfDocumentStructure schema.
+type ExpeditedBillingDrfDocumentStructure struct {
+	// CONDITIONAL: The cardholder's name is optional on Dispute Resolution Form - Pre-Compliance/Compliance form.
+	CardholderName string `json:"cardholderName,omitempty"`
+	// The Acquirer’s Reference Data.
+	AcquirerRefData string `json:"acquirerRefData,omitempty"`
+	// The transaction date.
+	TransactionDate string `json:"transactionDate,omitempty"`
+	// The total transaction amount.
+	TransactionAmount string `json:"transactionAmount,omitempty"`
+	// Give a reasonably specific description of the dispute.
+	DisputeDescription string `json:"disputeDescription,omitempty"`
+	// Who is the company representative or government agency representative on behalf of the corporate card.
+	Certification string `json:"certification,omitempty"`
+	// Customer Service/Chargeback Representative.
+	ChargebackRepresentative string `json:"chargebackRepresentative,omitempty"`
+}
+
+// FeeDetails is generated from the FeeDetails schema.
+type FeeDetails struct {
+	// Merchant Id associated with this fee collection..if any
+	CardAcceptorIDCode string `json:"cardAcceptorIdCode,omitempty"`
+	// Card number when required by the reason code
+	CardNumber string `json:"cardNumber,omitempty"`
+	// Code identifying the country
+	CountryCode string `json:"countryCode,omitempty"`
+	// Currency of the fee
+	Currency string `json:"currency,omitempty"`
+	// Date the fee was attached to the claim
+	FeeDate string `json:"feeDate,omitempty"`
+	// Destination member for the fee collection
+	DestinationMember string `json:"destinationMember,omitempty"`
+	// Identifier assigned to the fee
+	FeeID string `json:"feeId,omitempty"`
+	// Amount of the fee.
+	FeeAmount string `json:"feeAmount,omitempty"`
+	// Credit the Sender
+	CreditSender bool `json:"creditSender,omitempty"`
+	// Credit the Receiver
+	CreditReceiver bool `json:"creditReceiver,omitempty"`
+	// Message regarding fee
+	Message string `json:"message,omitempty"`
+	// Collection Reason Code
+	Reason string `json:"reason,omitempty"`
+	// Fee reject reason.
+	RejectReason string `json:"rejectReason,omitempty"`
+	// Contains card issuer reference data for a specific cardholder transaction. This number must be unique within BIN. It is used to track the chargeback throughout its life cycle
+	ChargebackRefNum string `json:"chargebackRefNum,omitempty"`
+	// Reconciliation amount of the fee. Amount will only be retrieved by the receiver side of the fee
+	ReconciliationAmount string `json:"reconciliationAmount,omitempty"`
+	// Reconciliation currency of the fee. Currency will only be retrieved by the receiver side of the fee
+	ReconciliationCurrency string `json:"reconciliationCurrency,omitempty"`
+	// Identifies the merchant's category in Japan referred to as the Common Merchant Category Code (CMC). Mastercard uses this value to identify a link to a corresponding Mastercard Assigned ID.
+	JapanCommonMerchantCode string `json:"japanCommonMerchantCode,omitempty"`
+	// Provides information about the installment payment option selected by the cardholder at the point of interaction.
+	InstallmentData string `json:"installmentData,omitempty"`
+	// Specific to Brazil Flex Card transactions to communicate the product code used for clearing.
+	FlexCode string `json:"flexCode,omitempty"`
+}
+
+// FraudDrfDocumentStructure is generated from the FraudDrfDocumentStructure schema.
+type FraudDrfDocumentStructure struct {
+	// Numeric value of number of transactions being disputed.
+	NumberOfItems string `json:"numberOfItems,omitempty"`
+	// Enter any of the valid values comma separated.
+	Types string `json:"types,omitempty"`
+	// Additional information, if needed
+	AdditionalInformation string `json:"additionalInformation,omitempty"`
+	// Customer Service/Chargeback Representative
+	ChargebackRepresentative string `json:"chargebackRepresentative,omitempty"`
+	// The card issuer region.
+	CardIssuerRegion             string                                 `json:"cardIssuerRegion,omitempty"`
+	CardholderVerificationMethod *CardholderVerificationMethodStructure `json:"cardholderVerificationMethod,omitempty"`
+}
+
+// GetQueueContentRequest is generated from the GetQueueContentRequest schema.
+type GetQueueContentRequest struct {
+	// The queue to be queried for a list of claims.
+	QueueName string `json:"queueName"`
+	// Start of claim’s last modified date range.
+	LastModifiedDateFrom string `json:"lastModifiedDateFrom,omitempty"`
+	// End of claim’s last modified date range.
+	LastModifiedDateTo string `json:"lastModifiedDateTo,omitempty"`
+	// The queue data will be retrieved in separate sets.  The pageNb field indicates which page should be returned.  The total page counts available in a date range will be returned in the pageCount field. Possible values are 1,2,3 etc. If page number is not provided, value will default to 1.
+	PageNb string `json:"pageNb,omitempty"`
+}
+
+// IssuerFulfillmentRequest is generated from the IssuerFulfillmentRequest schema.
+type IssuerFulfillmentRequest struct {
+	// This is a test memo.
+	Memo string `json:"memo,omitempty"`
+	// Issuer Response Code.
+	IssuerResponseCd string `json:"issuerResponseCd"`
+	// Reject Reason Code.
+	RejectReasonCd string `json:"rejectReasonCd,omitempty"`
+}
+
+// LoadDataForChargebackResponse is generated from the LoadDataForChargebackResponse schema.
+type LoadDataForChargebackResponse struct {
+	// List of valid currencies
+	Currencies []CurrenciesNameValueDetail `json:"currencies,omitempty"`
+	// List of valid doc indicators
+	DocIndicators []ChargebackDocIndicatorsNameValueDetail `json:"docIndicators,omitempty"`
+	// List of valid message texts
+	MessageTexts []ChargebackMessageTextsNameValueDetail `json:"messageTexts,omitempty"`
+	// List of valid reason codes
+	ReasonCodes []ChargebackReasonCodesNameValueDetail `json:"reasonCodes,omitempty"`
+	Amount      *ChargebackAmountNameValueDetail       `json:"amount,omitempty"`
+}
+
+// LoadDataForChargebacksRequest is generated from the LoadDataForChargebacksRequest schema.
+type LoadDataForChargebacksRequest struct {
+	// The type of chargeback.The default value is CHARGEBACK
+	ChargebackType string `json:"chargebackType"`
+	// Reason Code
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// The currency in with the chargeback will be created.
+	Currency string `json:"currency,omitempty"`
+}
+
+// LoadDataForRetrievalResponse is generated from the LoadDataForRetrievalResponse schema.
+type LoadDataForRetrievalResponse struct {
+	// List of valid docNeeded fields
+	DocNeeded []RetrievalDocNeededNameValueDetail `json:"docNeeded,omitempty"`
+	// List of valid reason codes
+	ReasonCodes []RetrievalReasonCodesNameValueDetail `json:"reasonCodes,omitempty"`
+}
+
+// PointOfInteractionErrorsDrfDocumentStructure is generated from the PointOfInteractionErrorsDrfDocumentStructure schema.
+type PointOfInteractionErrorsDrfDocumentStructure struct {
+	// The transaction amount.
+	TransactionAmount string `json:"transactionAmount,omitempty"`
+	// The type of the error.
+	Type string `json:"type,omitempty"`
+	// CONDITIONAL: in case of DUPLICATE_DEBIT, Alternate means of payment details.
+	AlternateMeansOfPaymentDetails string `json:"alternateMeansOfPaymentDetails,omitempty"`
+	// Describe the cardholder’s compliant in sufficient detail to meet the requirements for the chargeback as described in the Chargeback Guide and to enable all parties to understand the dispute.
+	DisputeDetails string `json:"disputeDetails,omitempty"`
+	// Customer Service/Chargeback Representative.
+	ChargebackRepresentative string `json:"chargebackRepresentative,omitempty"`
+}
+
+// Queue is generated from the Queue schema.
+type Queue struct {
+	// Queue Name.  This is used as input in other apis
+	QueueName string `json:"queueName,omitempty"`
+	// This describes the contents of the queue
+	QueueDescription string `json:"queueDescription,omitempty"`
+}
+
+// QueueContentSummary is generated from the QueueContentSummary schema.
+type QueueContentSummary struct {
+	// The number of pages queue results can be returned
+	PageCount string         `json:"pageCount,omitempty"`
+	ClaimList []ClaimSummary `json:"claimList,omitempty"`
+}
+
+// ReconReportDataAcknowledgeRequest is generated from the ReconReportDataAcknowledgeRequest schema.
+type ReconReportDataAcknowledgeRequest struct {
+	// Interbank Card Association number used to identify the member in transaction.
+	ICA []string `json:"ica,omitempty"`
+	// Start date for the reconciliation report.
+	StartDate string `json:"startDate"`
+	// End date for the reconciliation report.
+	EndDate string `json:"endDate"`
+	// It represents the cycle(s) where the clearing data was exchanged.
+	Cycles []Cycle `json:"cycles,omitempty"`
+	// It is set to "true" because the enhanced reconciliation report is available.
+	EnhancedReconciliationReportFlag bool `json:"enhancedReconciliationReportFlag,omitempty"`
+}
+
+// ReconReportDataAcknowledgeResponse is generated from the ReconReportDataAcknowledgeResponse schema.
+type ReconReportDataAcknowledgeResponse struct {
+	// A 36 bit UUID identifier that identifies the current reconciliation report generation request.
+	ReportIdentifier string `json:"reportIdentifier,omitempty"`
+}
+
+// ReconReportDataRetrivalResponse is generated from the ReconReportDataRetrivalResponse schema.
+type ReconReportDataRetrivalResponse struct {
+	// Status of polling request. This can be Available, Unavailable or Failed. Failed status occurs if the data processing underwent some kind of error leading to unavailability of the report.
+	Status string `json:"status,omitempty"`
+	// Base64 encoded String containing binary data for the CSV document. Decode the field to get a byte array that can be converted into a CSV file or String/Stream
+	Data string `json:"data,omitempty"`
+}
+
+// RetrievalDocNeededNameValueDetail is generated from the RetrievalDocNeededNameValueDetail schema.
+type RetrievalDocNeededNameValueDetail struct {
+	// The name of the element
+	Name string `json:"name,omitempty"`
+	// The value of the element
+	Value string `json:"value,omitempty"`
+}
+
+// RetrievalReasonCodesNameValueDetail is generated from the RetrievalReasonCodesNameValueDetail schema.
+type RetrievalReasonCodesNameValueDetail struct {
+	// The name of the element
+	Name string `json:"name,omitempty"`
+	// The value of the element
+	Value string `json:"value,omitempty"`
+}
+
+// RetrievalResponse is generated from the RetrievalResponse schema.
+type RetrievalResponse struct {
+	// Identifier assigned to the fulfillment.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// RetrievalStatusRequest is generated from the RetrievalStatusRequest schema.
+type RetrievalStatusRequest struct {
+	// A list of Request Ids to query, maximum list size is 2000
+	RetrievalList []RetrievalStatusRequestStructure `json:"retrievalList"`
+}
+
+// RetrievalStatusRequestStructure is generated from the RetrievalStatusRequestStructure schema.
+type RetrievalStatusRequestStructure struct {
+	// Claim Id for the Retrieval Request.
+	ClaimID string `json:"claimId"`
+	// Retrieval Request Id.
+	RequestID string `json:"requestId"`
+}
+
+// RetrievalStatusResponse is generated from the RetrievalStatusResponse schema.
+type RetrievalStatusResponse struct {
+	// A list of retrieval image statuses
+	RetrievalResponseList []RetrievalStatusResponseStructure `json:"retrievalResponseList,omitempty"`
+}
+
+// RetrievalStatusResponseStructure is generated from the RetrievalStatusResponseStructure schema.
+type RetrievalStatusResponseStructure struct {
+	// Claim Id
+	ClaimID string `json:"claimId,omitempty"`
+	// Request Id
+	RequestID string `json:"requestId,omitempty"`
+	// When retrieving status of an image, the valid values are: COMPLETED, FAILED, PENDING, UNAVAILABLE AND DOC_NOT_APPLICABLE. COMPLETED: Image was processed and no further action required. FAILED: Some failure happened during image process flow, i.e,The image could not be converted, The image is not imported, Image extension not supported etc. PENDING: The image is pending to be processed. DOC_NOT_APPLICABLE: The dispute does not require a document. UNAVAILABLE: The image is unavailable because it is not picked up by mastercom internal processes yet.
+	Status string `json:"status,omitempty"`
+}
+
+// RetrievalSummary is generated from the RetrievalSummary schema.
+type RetrievalSummary struct {
+	// Acquirer Reference Number is a unique number that tags a credit card transaction when it goes from the merchants bank (The Acquiring Bank) through the card scheme to the cardholders bank (The Issuer)
+	AcquirerRefNum string `json:"acquirerRefNum,omitempty"`
+	// Predetermined response code chosen by the Acquirer
+	AcquirerResponseCd string `json:"acquirerResponseCd,omitempty"`
+	// The memo the acquirer created when fulfilling the retrieval request
+	AcquirerMemo string `json:"acquirerMemo,omitempty"`
+	// Date the acquirer responded to the fulfillment request
+	AcquirerResponseDt string `json:"acquirerResponseDt,omitempty"`
+	// Amount of the claim
+	Amount string `json:"amount,omitempty"`
+	// The retrieval currency.  The data should be standard currency alpha code
+	Currency string `json:"currency,omitempty"`
+	// Claim identifier
+	ClaimID string `json:"claimId,omitempty"`
+	// This is the date of the Retrieval Request creation
+	CreateDate string `json:"createDate,omitempty"`
+	// This is the date of the Retrieval Request cancelation
+	CancelDate string `json:"cancelDate,omitempty"`
+	// This is the date of the Retrieval Request reversion
+	ReverseDate string `json:"reverseDate,omitempty"`
+	// This is the date of the Retrieval Request rejection by GCMS
+	RejectDate string `json:"rejectDate,omitempty"`
+	// Documentation Needed Indicator. Possible values are
+	DocNeeded string `json:"docNeeded,omitempty"`
+	// Predetermined response code chosen by the Issuer
+	IssuerResponseCd string `json:"issuerResponseCd,omitempty"`
+	// Predetermined reject reason codes
+	IssuerRejectRsnCd string `json:"issuerRejectRsnCd,omitempty"`
+	// Memo pertaining to the case
+	IssuerMemo string `json:"issuerMemo,omitempty"`
+	// Date the issuer responded to the fulfillment
+	IssuerResponseDt string `json:"issuerResponseDt,omitempty"`
+	// The image review decision
+	ImageReviewDecision string `json:"imageReviewDecision,omitempty"`
+	// Date the image review decision occurred
+	ImageReviewDt string `json:"imageReviewDt,omitempty"`
+	// Primary Account Number
+	PrimaryAcctNum string `json:"primaryAcctNum,omitempty"`
+	// Retrieval Request reject reason.
+	RejectReason string `json:"rejectReason,omitempty"`
+	// Identifier assigned to the fulfillment
+	RequestID string `json:"requestId,omitempty"`
+	// Retrieval Request Reason
+	RetrievalRequestReason string `json:"retrievalRequestReason,omitempty"`
+	// Contains card issuer reference data for a specific cardholder transaction. This number must be unique within BIN. It is used to track the chargeback throughout its life cycle
+	ChargebackRefNum string `json:"chargebackRefNum,omitempty"`
+	// The field would show Processed or Rejected or Pending or Cancelled status depending on the GCMS processing of the retrieval request. Pending- Item created but NOT yet sent to GCMS Cancelled- Item discarded without being sent to GCMS Processed- Item is being sent for clearing (via ipmClearingOutput batch job) Rejected- If item being rejected from GCMS (No update on Issuer side claim).
+	AcquirerResponseNotificationStatus string `json:"acquirerResponseNotificationStatus,omitempty"`
+	// Instructions for healthcare
+	InstructionsForHealthcare string `json:"instructionsForHealthcare,omitempty"`
+	// Refund/Reversal Date
+	RefundReversalDate string `json:"refundReversalDate,omitempty"`
+	// Refund/Reversal Amount
+	RefundReversalAmount string `json:"refundReversalAmount,omitempty"`
+	// Refund/Reversal Currency
+	RefundReversalCurrency string `json:"refundReversalCurrency,omitempty"`
+	// Refund/Reversal Type
+	RefundReversalType string `json:"refundReversalType,omitempty"`
+	// Refund/Reversal reference ID
+	RefundReversalReferenceID string `json:"refundReversalReferenceId,omitempty"`
+	// Memo
+	Memo string `json:"memo,omitempty"`
+	// Specific to Brazil Flex Card transactions to communicate the product code used for clearing.
+	FlexCode string `json:"flexCode,omitempty"`
+	// Date and time by which the acquirer can respond to a Collaboration request.
+	CollaborationExpirationDateTime string `json:"collaborationExpirationDateTime,omitempty"`
+}
+
+// SmsLinkedCaseFilingDrfDocumentStructure is generated from the SmsLinkedCaseFilingDrfDocumentStructure schema.
+type SmsLinkedCaseFilingDrfDocumentStructure struct {
+	// The contact name.
+	ContactName string `json:"contactName,omitempty"`
+	// The company or institution name.
+	CompanyOrInstitution string `json:"companyOrInstitution,omitempty"`
+	// The contact email.
+	ContactEmail string `json:"contactEmail,omitempty"`
+	// The reason for filing case.
+	ReasonForFilingCase string `json:"reasonForFilingCase,omitempty"`
+	// The processor Id.
+	ProcessorID string `json:"processorId,omitempty"`
+	// The Acquirer Reference Data or Switch Serial Number.
+	AcquirerSwitchSerialNum string `json:"acquirerSwitchSerialNum,omitempty"`
+	// The Transaction or Settlement Date.
+	TransactionOrSettlementDate string `json:"transactionOrSettlementDate,omitempty"`
+	// A numeric count of number of transactions being disputed.
+	NumberOfTransactions string `json:"numberOfTransactions,omitempty"`
+}
+
+// TransactionInformationEbdfStructure is generated from the TransactionInformationEbdfStructure schema.
+type TransactionInformationEbdfStructure struct {
+	// Acquirers Reference Data or Switch Serial Number.
+	AcquirerRefDataOrSwitchSerialNum string `json:"acquirerRefDataOrSwitchSerialNum,omitempty"`
+	// The Merchant Name
+	MerchantName string `json:"merchantName,omitempty"`
+	// Transaction or Settlement Date
+	TransactionOrSettlementDate string `json:"transactionOrSettlementDate,omitempty"`
+	// The Disputed Amount.
+	DisputedAmount string `json:"disputedAmount,omitempty"`
+}
+
+// UpdateCaseRequest is generated from the UpdateCaseRequest schema.
+type UpdateCaseRequest struct {
+	// Action to be performed on case.
+	Action         string             `json:"action"`
+	FileAttachment *DocumentStructure `json:"fileAttachment,omitempty"`
+	// Memo pertaining to the case.
+	Memo string `json:"memo,omitempty"`
+	// Rebutted as Sender or Receiver.
+	RebuttedAs string `json:"rebuttedAs,omitempty"`
+	// Uploading document as Sender or Receiver.
+	DocRetryAs string `json:"docRetryAs,omitempty"`
+}
+
+// UpdateChargebackRequest is generated from the UpdateChargebackRequest schema.
+type UpdateChargebackRequest struct {
+	// Memo.
+	Memo string `json:"memo,omitempty"`
+	// Action to be performed on 1st chargeback.
+	CreditVoucherAction string             `json:"creditVoucherAction,omitempty"`
+	FileAttachment      *DocumentStructure `json:"fileAttachment,omitempty"`
+}
+
+// UpdateClaimRequest is generated from the UpdateClaimRequest schema.
+type UpdateClaimRequest struct {
+	// The due date for opening the claim.
+	OpenClaimDueDate string `json:"openClaimDueDate,omitempty"`
+	// Action to perform on claim.
+	Action string `json:"action"`
+	// Reason code for closing the claim.
+	CloseClaimReasonCode string `json:"closeClaimReasonCode,omitempty"`
+}
@@ -0,0 +1,386 @@
+// Code generated by go run ./internal/gen; DO NOT EDIT.
+
+package mastercom
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// AcknowledgeChargebacks calls PUT /v6/chargebacks/acknowledge.
+//
+// Acknowledge chargeback or representment.
+func (c *Client) AcknowledgeChargebacks(ctx context.Context, body *ChargebackMarkProcessedRequest) (*ChargebackMarkProcessedResponse, error) {
+	path := "/v6/chargebacks/acknowledge"
+	out := new(ChargebackMarkProcessedResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AcqFulfillRetrievalRequest calls POST /v6/claims/{claim-id}/retrievalrequests/{request-id}/fulfillments.
+//
+// Add transaction information document.
+func (c *Client) AcqFulfillRetrievalRequest(ctx context.Context, claimID string, requestID string, body *AcquirerFulfillmentRequest) (*AcquirerFulfillmentResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/retrievalrequests/" + url.PathEscape(requestID) + "/fulfillments"
+	out := new(AcquirerFulfillmentResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateCaseFiling calls POST /v6/cases.
+//
+// Create a new case.
+func (c *Client) CreateCaseFiling(ctx context.Context, body *CreateCaseRequest) (*CaseFilingResponse, error) {
+	path := "/v6/cases"
+	out := new(CaseFilingResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateChargeback calls POST /v6/claims/{claim-id}/chargebacks.
+//
+// Create chargeback or second presentment.
+func (c *Client) CreateChargeback(ctx context.Context, claimID string, body *CreateChargebackRequest) (*ChargebackResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/chargebacks"
+	out := new(ChargebackResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateChargebackReversal calls POST /v6/claims/{claim-id}/chargebacks/{chargeback-id}/reversal.
+//
+// Reverse chargeback.
+func (c *Client) CreateChargebackReversal(ctx context.Context, claimID string, chargebackID string) (*ChargebackResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/chargebacks/" + url.PathEscape(chargebackID) + "/reversal"
+	out := new(ChargebackResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateClaim calls POST /v6/claims.
+//
+// Create new claim.
+func (c *Client) CreateClaim(ctx context.Context, body *CreateClaimRequest) (*ClaimResponse, error) {
+	path := "/v6/claims"
+	out := new(ClaimResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateRetrievalRequest calls POST /v6/claims/{claim-id}/retrievalrequests.
+//
+// Create retrieval request.
+func (c *Client) CreateRetrievalRequest(ctx context.Context, claimID string, body *CreateRetrievalRequest) (*CreateRetrievalResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/retrievalrequests"
+	out := new(CreateRetrievalResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetCaseFilingDocParams holds the query parameters of GetCaseFilingDoc
+type GetCaseFilingDocParams struct {
+	// File Format.
+	Format string
+	// Adding field for future use. Please leave blank at this time.
+	Memo string
+}
+
+// GetCaseFilingDoc calls GET /v6/cases/{case-id}/documents.
+//
+// Retrieve case documents.
+func (c *Client) GetCaseFilingDoc(ctx context.Context, caseID string, params *GetCaseFilingDocParams) (*DocumentResponseStructure, error) {
+	path := "/v6/cases/" + url.PathEscape(caseID) + "/documents"
+	query := url.Values{}
+	if params != nil {
+		if params.Format != "" {
+			query.Set("format", params.Format)
+		}
+		if params.Memo != "" {
+			query.Set("memo", params.Memo)
+		}
+	}
+	out := new(DocumentResponseStructure)
+	if err := c.do(ctx, http.MethodGet, path, query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetChargebackDocParams holds the query parameters of GetChargebackDoc
+type GetChargebackDocParams struct {
+	// File Format.
+	Format string
+}
+
+// GetChargebackDoc calls GET /v6/claims/{claim-id}/chargebacks/{chargeback-id}/documents.
+//
+// Retrieve chargeback documents.
+func (c *Client) GetChargebackDoc(ctx context.Context, claimID string, chargebackID string, params *GetChargebackDocParams) (*DocumentResponseStructure, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/chargebacks/" + url.PathEscape(chargebackID) + "/documents"
+	query := url.Values{}
+	if params != nil {
+		if params.Format != "" {
+			query.Set("format", params.Format)
+		}
+	}
+	out := new(DocumentResponseStructure)
+	if err := c.do(ctx, http.MethodGet, path, query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetClaimDetail calls GET /v6/claims/{claim-id}.
+//
+// Retrieve claim details.
+func (c *Client) GetClaimDetail(ctx context.Context, claimID string) (*ClaimDetail, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID)
+	out := new(ClaimDetail)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetDataForCreateChargeback calls POST /v6/claims/{claim-id}/chargebacks/loaddataforchargebacks.
+//
+// Retrieve chargeback data.
+func (c *Client) GetDataForCreateChargeback(ctx context.Context, claimID string, body *LoadDataForChargebacksRequest) (*LoadDataForChargebackResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/chargebacks/loaddataforchargebacks"
+	out := new(LoadDataForChargebackResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetDataForCreateRetrievalRequest calls GET /v6/claims/{claim-id}/retrievalrequests/loaddataforretrievalrequests.
+//
+// Retrieve retrieval related information.
+func (c *Client) GetDataForCreateRetrievalRequest(ctx context.Context, claimID string) (*LoadDataForRetrievalResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/retrievalrequests/loaddataforretrievalrequests"
+	out := new(LoadDataForRetrievalResponse)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetQueueSummaryParams holds the query parameters of GetQueueSummary
+type GetQueueSummaryParams struct {
+	// The queue to be queried for a list of claims.
+	QueueName string
+}
+
+// GetQueueSummary calls GET /v6/queues.
+//
+// Retrieve claims by queue name.
+func (c *Client) GetQueueSummary(ctx context.Context, params *GetQueueSummaryParams) ([]ClaimSummary, error) {
+	path := "/v6/queues"
+	query := url.Values{}
+	if params != nil {
+		if params.QueueName != "" {
+			query.Set("queue-name", params.QueueName)
+		}
+	}
+	var out []ClaimSummary
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetQueueSummaryPost calls POST /v6/queues.
+//
+// Retrieve claims by queue name and date range.
+func (c *Client) GetQueueSummaryPost(ctx context.Context, body *GetQueueContentRequest) (*QueueContentSummary, error) {
+	path := "/v6/queues"
+	out := new(QueueContentSummary)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetQueues calls GET /v6/queues/names.
+//
+// Retrieve list of queue names.
+func (c *Client) GetQueues(ctx context.Context) ([]Queue, error) {
+	path := "/v6/queues/names"
+	var out []Queue
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetRetrievalDocParams holds the query parameters of GetRetrievalDoc
+type GetRetrievalDocParams struct {
+	// File Format.
+	Format string
+}
+
+// GetRetrievalDoc calls GET /v6/claims/{claim-id}/retrievalrequests/{request-id}/documents.
+//
+// Document status for retrieval requests.
+func (c *Client) GetRetrievalDoc(ctx context.Context, claimID string, requestID string, params *GetRetrievalDocParams) (*DocumentResponseStructure, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/retrievalrequests/" + url.PathEscape(requestID) + "/documents"
+	query := url.Values{}
+	if params != nil {
+		if params.Format != "" {
+			query.Set("format", params.Format)
+		}
+	}
+	out := new(DocumentResponseStructure)
+	if err := c.do(ctx, http.MethodGet, path, query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IssuerResponseRetrievalRequest calls POST /v6/claims/{claim-id}/retrievalrequests/{request-id}/fulfillments/response.
+//
+// Approve or reject retrieval.
+func (c *Client) IssuerResponseRetrievalRequest(ctx context.Context, claimID string, requestID string, body *IssuerFulfillmentRequest) (*RetrievalResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/retrievalrequests/" + url.PathEscape(requestID) + "/fulfillments/response"
+	out := new(RetrievalResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReconReportDataAcknowledge calls POST /v6/reconreport/data/request.
+//
+// Create recon report.
+func (c *Client) ReconReportDataAcknowledge(ctx context.Context, body *ReconReportDataAcknowledgeRequest) (*ReconReportDataAcknowledgeResponse, error) {
+	path := "/v6/reconreport/data/request"
+	out := new(ReconReportDataAcknowledgeResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReconReportDataRetrieval calls POST /v6/reconreport/data/retrieval/{reportIdentifier}.
+//
+// Retrieve recon report.
+func (c *Client) ReconReportDataRetrieval(ctx context.Context, reportIdentifier string) (*ReconReportDataRetrivalResponse, error) {
+	path := "/v6/reconreport/data/retrieval/" + url.PathEscape(reportIdentifier)
+	out := new(ReconReportDataRetrivalResponse)
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RetrieveCaseFilingImageStatus calls PUT /v6/cases/imagestatus.
+//
+// Search case document by status.
+func (c *Client) RetrieveCaseFilingImageStatus(ctx context.Context, body *CaseFilingImageStatusRequest) (*CaseFilingImageStatusResponse, error) {
+	path := "/v6/cases/imagestatus"
+	out := new(CaseFilingImageStatusResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RetrieveCaseFilingStatus calls PUT /v6/cases/status.
+//
+// Check case document status.
+func (c *Client) RetrieveCaseFilingStatus(ctx context.Context, body *CaseFilingStatusRequest) (*CaseFilingStatusResponse, error) {
+	path := "/v6/cases/status"
+	out := new(CaseFilingStatusResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RetrieveChargebackStatus calls PUT /v6/chargebacks/status.
+//
+// Retrieve document status by chargeback.
+func (c *Client) RetrieveChargebackStatus(ctx context.Context, body *ChargebackStatusRequest) (*ChargebackStatusResponse, error) {
+	path := "/v6/chargebacks/status"
+	out := new(ChargebackStatusResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RetrieveClaims calls PUT /v6/cases/retrieve/claims.
+//
+// Retrieve claims by case.
+func (c *Client) RetrieveClaims(ctx context.Context, body *CaseFilingClaimsRequest) (*CaseFilingClaimsResponse, error) {
+	path := "/v6/cases/retrieve/claims"
+	out := new(CaseFilingClaimsResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RetrieveFulfillmentStatus calls PUT /v6/retrievalrequests/status.
+//
+// Document status for retrieval events.
+func (c *Client) RetrieveFulfillmentStatus(ctx context.Context, body *RetrievalStatusRequest) (*RetrievalStatusResponse, error) {
+	path := "/v6/retrievalrequests/status"
+	out := new(RetrievalStatusResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateCaseFiling calls PUT /v6/cases/{case-id}.
+//
+// Update or respond to case.
+func (c *Client) UpdateCaseFiling(ctx context.Context, caseID string, body *UpdateCaseRequest) (*CaseFilingResponse, error) {
+	path := "/v6/cases/" + url.PathEscape(caseID)
+	out := new(CaseFilingResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateChargeback calls PUT /v6/claims/{claim-id}/chargebacks/{chargeback-id}.
+//
+// Attach document to chargeback.
+func (c *Client) UpdateChargeback(ctx context.Context, claimID string, chargebackID string, body *UpdateChargebackRequest) (*ChargebackResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID) + "/chargebacks/" + url.PathEscape(chargebackID)
+	out := new(ChargebackResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateClaim calls PUT /v6/claims/{claim-id}.
+//
+// Take action on claim.
+func (c *Client) UpdateClaim(ctx context.Context, claimID string, body *UpdateClaimRequest) (*ClaimResponse, error) {
+	path := "/v6/claims/" + url.PathEscape(claimID)
+	out := new(ClaimResponse)
+	if err := c.do(ctx, http.MethodPut, path, nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"mastercom-service/pkg/jwt"
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// APIKeyHeader carries an API key
+const APIKeyHeader = "X-API-Key"
+
+// ErrInvalidCredentials is returned by authenticators for a credential they do not accept
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// AuthOptions configures how Auth identifies callers
+type AuthOptions struct {
+	// APIKey returns the principal of an API key; API keys are refused when it is nil
+	APIKey func(key string) (*Principal, error)
+	// BearerToken returns the principal of a bearer token; bearer tokens are refused when it is nil
+	BearerToken func(ctx context.Context, token string) (*Principal, error)
+	// Exempt lists the paths served without authentication
+	Exempt []string
+}
+
+// Auth authenticates every request, other than to the exempt paths, with an API key in the
+// X-API-Key header or a bearer token in the Authorization header, and records its principal.
+// Requests without an accepted credential are answered with 401.
+func Auth(options AuthOptions, logger *logger.DatadogLogger) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(options.Exempt))
+	for _, path := range options.Exempt {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if exempt[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		principal, err := authenticate(c, options)
+		if err != nil {
+			logger.Error("Request authentication failed", logrus.Fields{
+				"client_ip": c.ClientIP(),
+				"method":    c.Request.Method,
+				"path":      c.Request.URL.Path,
+				"error":     err.Error(),
+			})
+			AddSpanError(c.Request.Context(), err)
+			c.Header("WWW-Authenticate", `Bearer realm="mastercom-service"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+
+		AddSpanTag(c.Request.Context(), "auth.subject", principal.Subject)
+		AddSpanTag(c.Request.Context(), "auth.method", principal.Method)
+		SetPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+func authenticate(c *gin.Context, options AuthOptions) (*Principal, error) {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		if options.APIKey == nil {
+			return nil, errors.New("API keys are not accepted")
+		}
+		return options.APIKey(key)
+	}
+
+	scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return nil, errors.New("no credentials")
+	}
+	if options.BearerToken == nil {
+		return nil, errors.New("bearer tokens are not accepted")
+	}
+	return options.BearerToken(c.Request.Context(), strings.TrimSpace(token))
+}
+
+// TokenPrincipal returns an authenticator of bearer tokens verified by verifier. The principal
+// of a token is its subject, holding the roles listed in its rolesClaim claim and acting for
+// the ICAs listed in its icasClaim claim.
+func TokenPrincipal(verifier *jwt.Verifier, rolesClaim, icasClaim string) func(ctx context.Context, token string) (*Principal, error) {
+	return func(ctx context.Context, token string) (*Principal, error) {
+		claims, err := verifier.Verify(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if claims.Subject == "" {
+			return nil, errors.New("token has no subject")
+		}
+		return &Principal{
+			Subject: claims.Subject,
+			Roles:   claims.Strings(rolesClaim),
+			ICAs:    claims.Strings(icasClaim),
+			Method:  AuthMethodToken,
+		}, nil
+	}
+}
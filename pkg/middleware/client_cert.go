@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/mtls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ClientCertificate answers with 403 requests to the paths allowlist lists that do not come
+// with a client certificate of a subject allowed them, and tags the request's span with the
+// subject of the client certificate it comes with
+func ClientCertificate(allowlist *mtls.Allowlist, logger *logger.DatadogLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := allowlist.Check(c.Request.URL.Path, c.Request.TLS); err != nil {
+			logger.Error("Client certificate not permitted", logrus.Fields{
+				"client_ip": c.ClientIP(),
+				"method":    c.Request.Method,
+				"path":      c.Request.URL.Path,
+				"subject":   mtls.Subject(c.Request.TLS),
+				"error":     err.Error(),
+			})
+			AddSpanError(c.Request.Context(), err)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Client certificate not permitted"})
+			return
+		}
+
+		if subject := mtls.Subject(c.Request.TLS); subject != "" {
+			AddSpanTag(c.Request.Context(), "tls.client_subject", subject)
+		}
+		c.Next()
+	}
+}
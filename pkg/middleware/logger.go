@@ -21,7 +21,16 @@ func Logger(logger *logger.DatadogLogger) gin.HandlerFunc {
 			"latency":      param.Latency,
 			"user_agent":   param.Request.UserAgent(),
 			"error":        param.ErrorMessage,
+			"subject":      principalSubject(param.Keys),
 		})
 		return ""
 	})
 }
+
+// principalSubject returns the subject of the principal among a request's context keys, if any
+func principalSubject(keys map[string]any) string {
+	if principal, ok := keys[principalKey].(*Principal); ok && principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// principalKey is the gin context key holding the caller of a request
+const principalKey = "principal"
+
+// How a principal was authenticated
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodToken  = "jwt"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller, such as an API key name or a token subject
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles"`
+	// ICAs are the ICAs the caller may act for, where * stands for every ICA
+	ICAs []string `json:"icas"`
+	// Method is how the principal was authenticated
+	Method string `json:"method,omitempty"`
+}
+
+// HasRole reports whether the principal holds any of roles
+func (p *Principal) HasRole(roles ...string) bool {
+	if p == nil {
+		return false
+	}
+	for _, held := range p.Roles {
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetPrincipal records the caller of a request for the handlers after it
+func SetPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(principalKey, principal)
+}
+
+// PrincipalFrom returns the caller of a request, or nil when the request is not authenticated
+func PrincipalFrom(c *gin.Context) *Principal {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return nil
+	}
+	principal, _ := value.(*Principal)
+	return principal
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+
+	"mastercom-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Roles a principal can hold, each allowed what the roles before it are
+const (
+	// RoleViewer reads cases, documents and queues
+	RoleViewer = "viewer"
+	// RoleAnalyst works cases: filing, updating and moving them through the queues
+	RoleAnalyst = "analyst"
+	// RoleSupervisor also deletes and restores evidence, closes cases and places legal holds
+	RoleSupervisor = "supervisor"
+	// RoleAdmin also administers the service
+	RoleAdmin = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer:     1,
+	RoleAnalyst:    2,
+	RoleSupervisor: 3,
+	RoleAdmin:      4,
+}
+
+// Permits reports whether the principal holds role, or a role allowed more than it
+func (p *Principal) Permits(role string) bool {
+	if p == nil {
+		return false
+	}
+	required, known := roleRanks[role]
+	if !known {
+		return false
+	}
+	for _, held := range p.Roles {
+		if roleRanks[held] >= required {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRoles answers with 403 a request whose principal is not permitted the role that
+// permissions give its route, keyed by method and route path such as "GET /api/v6/cases/:id".
+// Routes that permissions do not list need RoleAdmin. Requests without a principal, to routes
+// exempt from authentication, and requests matching no route are passed on.
+func RequireRoles(permissions map[string]string, logger *logger.DatadogLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFrom(c)
+		route := c.FullPath()
+		if principal == nil || route == "" {
+			c.Next()
+			return
+		}
+
+		role, listed := permissions[c.Request.Method+" "+route]
+		if !listed {
+			role = RoleAdmin
+		}
+		if !principal.Permits(role) {
+			logger.Error("Request not permitted for the caller's roles", logrus.Fields{
+				"subject": principal.Subject,
+				"roles":   principal.Roles,
+				"method":  c.Request.Method,
+				"path":    c.Request.URL.Path,
+				"role":    role,
+			})
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not permitted", "requiredRole": role})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,127 @@
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrClientCertificateRequired is returned for a listed route reached without a client certificate
+	ErrClientCertificateRequired = errors.New("client certificate required")
+	// ErrSubjectNotAllowed is returned for a client certificate whose subject a route does not allow
+	ErrSubjectNotAllowed = errors.New("client certificate subject not allowed")
+)
+
+// Allowlist limits routes to client certificates with given subjects. Routes are HTTP paths
+// or gRPC methods, listed by prefix: /api/v6/webhooks lists /api/v6/webhooks/ethoca, and
+// /mastercom.CaseService lists its methods. A subject containing = is matched against the
+// certificate's whole distinguished name, such as CN=ethoca,O=Ethoca; any other subject is
+// matched against its common name.
+type Allowlist struct {
+	subjects map[string][]string
+	// prefixes are the listed prefixes, longest first
+	prefixes []string
+}
+
+// NewAllowlist returns an allowlist of the subjects allowed each route prefix
+func NewAllowlist(subjects map[string][]string) *Allowlist {
+	allowlist := &Allowlist{subjects: subjects}
+	for prefix := range subjects {
+		allowlist.prefixes = append(allowlist.prefixes, prefix)
+	}
+	sort.Slice(allowlist.prefixes, func(i, j int) bool {
+		return len(allowlist.prefixes[i]) > len(allowlist.prefixes[j])
+	})
+	return allowlist
+}
+
+// Check returns nil when route is not listed, or state carries a client certificate of a
+// subject allowed it. Client certificates are verified during the handshake.
+func (a *Allowlist) Check(route string, state *tls.ConnectionState) error {
+	allowed, listed := a.match(route)
+	if !listed {
+		return nil
+	}
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ErrClientCertificateRequired
+	}
+
+	subject := state.PeerCertificates[0].Subject
+	for _, entry := range allowed {
+		if matchSubject(entry, subject.String(), subject.CommonName) {
+			return nil
+		}
+	}
+	return ErrSubjectNotAllowed
+}
+
+func (a *Allowlist) match(route string) ([]string, bool) {
+	if a == nil {
+		return nil, false
+	}
+	for _, prefix := range a.prefixes {
+		if route == prefix || strings.HasPrefix(route, strings.TrimSuffix(prefix, "/")+"/") {
+			return a.subjects[prefix], true
+		}
+	}
+	return nil, false
+}
+
+func matchSubject(entry, distinguishedName, commonName string) bool {
+	if strings.Contains(entry, "=") {
+		return entry == distinguishedName
+	}
+	return entry == commonName
+}
+
+// Subject returns the subject of the client certificate of state, or "" without one
+func Subject(state *tls.ConnectionState) string {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ""
+	}
+	return state.PeerCertificates[0].Subject.String()
+}
+
+// UnaryServerInterceptor refuses unary calls to listed methods without an allowed client
+// certificate with PermissionDenied
+func (a *Allowlist) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := a.checkPeer(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor refuses streams to listed methods without an allowed client
+// certificate with PermissionDenied
+func (a *Allowlist) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.checkPeer(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+func (a *Allowlist) checkPeer(ctx context.Context, method string) error {
+	var state *tls.ConnectionState
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state = &info.State
+		}
+	}
+	if err := a.Check(method, state); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Package mtls serves TLS with certificates that are read again when their files change, and
+// verifies client certificates against a CA bundle and per-route subject allowlists.
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"mastercom-service/pkg/logger"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// reloadDelay lets a rotation that writes several files finish before they are read again
+const reloadDelay = 500 * time.Millisecond
+
+// ErrUntrustedClient is returned for a client certificate the client CA bundle does not verify
+var ErrUntrustedClient = errors.New("client certificate not trusted")
+
+// Options configures Certificates
+type Options struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile, when set, holds the PEM CAs verifying client certificates
+	ClientCAFile string
+	// RequireClientCert refuses connections without a client certificate; otherwise they are
+	// accepted, and a client certificate that is sent must be verified
+	RequireClientCert bool
+}
+
+// Certificates holds a server certificate and client CA bundle read from files. Watch reads
+// them again when the files change, so rotating them needs no restart; files that cannot be
+// read leave the previous certificates in use.
+type Certificates struct {
+	options     Options
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	mutex       sync.RWMutex
+	logger      *logger.DatadogLogger
+}
+
+// NewCertificates reads the certificates of options
+func NewCertificates(options Options, logger *logger.DatadogLogger) (*Certificates, error) {
+	c := &Certificates{options: options, logger: logger}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload reads the certificate files again
+func (c *Certificates) Reload() error {
+	certificate, err := tls.LoadX509KeyPair(c.options.CertFile, c.options.KeyFile)
+	if err != nil {
+		return fmt.Errorf("server certificate: %w", err)
+	}
+	certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("server certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if c.options.ClientCAFile != "" {
+		data, err := os.ReadFile(c.options.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("client CA bundle: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("client CA bundle %s holds no PEM certificates", c.options.ClientCAFile)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.certificate = &certificate
+	c.clientCAs = clientCAs
+	c.logger.Info("TLS certificates loaded", logrus.Fields{
+		"certFile":  c.options.CertFile,
+		"subject":   certificate.Leaf.Subject.String(),
+		"notAfter":  certificate.Leaf.NotAfter,
+		"clientCAs": c.options.ClientCAFile,
+	})
+	return nil
+}
+
+// TLSConfig returns a server configuration presenting the current certificate and verifying
+// client certificates against the current client CA bundle
+func (c *Certificates) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			c.mutex.RLock()
+			defer c.mutex.RUnlock()
+			return c.certificate, nil
+		},
+	}
+	if c.options.ClientCAFile == "" {
+		return config
+	}
+
+	// The bundle can change after the configuration is handed out, so client certificates are
+	// verified here rather than through ClientCAs
+	config.ClientAuth = tls.RequestClientCert
+	if c.options.RequireClientCert {
+		config.ClientAuth = tls.RequireAnyClientCert
+	}
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return nil
+		}
+		return c.verifyClient(state.PeerCertificates)
+	}
+	return config
+}
+
+func (c *Certificates) verifyClient(chain []*x509.Certificate) error {
+	c.mutex.RLock()
+	clientCAs := c.clientCAs
+	c.mutex.RUnlock()
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range chain[1:] {
+		intermediates.AddCert(certificate)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUntrustedClient, err)
+	}
+	return nil
+}
+
+// Watch reloads the certificates when their files change, until ctx is done. The directories
+// holding them are watched, so files replaced by renames or symlink swaps, as mounted secrets
+// are, are followed.
+func (c *Certificates) Watch(ctx context.Context) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		c.logger.Error("Failed to watch TLS certificates", logrus.Fields{"error": err.Error()})
+		return
+	}
+	defer watcher.Close()
+
+	for _, file := range []string{c.options.CertFile, c.options.KeyFile, c.options.ClientCAFile} {
+		if file == "" || slices.Contains(watcher.WatchList(), filepath.Dir(file)) {
+			continue
+		}
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			c.logger.Error("Failed to watch TLS certificates", logrus.Fields{"file": file, "error": err.Error()})
+			return
+		}
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			c.logger.Error("TLS certificate watch failed", logrus.Fields{"error": err.Error()})
+		case <-timer.C:
+			if err := c.Reload(); err != nil {
+				c.logger.Error("Failed to reload TLS certificates, keeping the previous ones", logrus.Fields{"error": err.Error()})
+			}
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mastercom-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testIssuer struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+// newTestCA returns a self-signed CA named name
+func newTestCA(t *testing.T, name string) *testIssuer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testIssuer{certificate: certificate, key: key}
+}
+
+// issue returns a PEM certificate and key for subject, used for usage
+func (ca *testIssuer) issue(t *testing.T, subject pkix.Name, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testIssuer) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.certificate.Raw})
+}
+
+// writeServerFiles writes a server certificate for subject, issued by ca, and clientCA's bundle
+func writeServerFiles(t *testing.T, dir string, ca, clientCA *testIssuer, subject string) Options {
+	options := Options{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "client-ca.crt"),
+	}
+	certPEM, keyPEM := ca.issue(t, pkix.Name{CommonName: subject}, x509.ExtKeyUsageServerAuth)
+	require.NoError(t, os.WriteFile(options.KeyFile, keyPEM, 0600))
+	require.NoError(t, os.WriteFile(options.CertFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(options.ClientCAFile, clientCA.pem(), 0600))
+	return options
+}
+
+// startTestServer serves TLS with certificates, answering with the client certificate subject
+// after checking it against allowlist, and returns its address
+func startTestServer(t *testing.T, certificates *Certificates, allowlist *Allowlist) string {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", certificates.TLSConfig())
+	require.NoError(t, err)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := allowlist.Check(r.URL.Path, r.TLS); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			io.WriteString(w, Subject(r.TLS))
+		}),
+		ErrorLog: log.New(io.Discard, "", 0),
+	}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return listener.Addr().String()
+}
+
+// newTestClient trusts ca and presents the client certificate, when given
+func newTestClient(t *testing.T, ca *testIssuer, certPEM, keyPEM []byte) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	config := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if certPEM != nil {
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		require.NoError(t, err)
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+}
+
+func get(client *http.Client, address, path string) (int, string, error) {
+	response, err := client.Get("https://" + address + path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	return response.StatusCode, string(body), nil
+}
+
+func TestCertificates_ClientVerification(t *testing.T) {
+	serverCA, clientCA, otherCA := newTestCA(t, "server-ca"), newTestCA(t, "client-ca"), newTestCA(t, "other-ca")
+	options := writeServerFiles(t, t.TempDir(), serverCA, clientCA, "localhost")
+	certificates, err := NewCertificates(options, logger.NewDatadogLogger())
+	require.NoError(t, err)
+	allowlist := NewAllowlist(map[string][]string{
+		"/api/v6/webhooks/ethoca": {"ethoca", "CN=ethoca-staging,O=Ethoca"},
+	})
+	server := startTestServer(t, certificates, allowlist)
+
+	ethocaCert, ethocaKey := clientCA.issue(t, pkix.Name{CommonName: "ethoca", Organization: []string{"Ethoca"}}, x509.ExtKeyUsageClientAuth)
+	stagingCert, stagingKey := clientCA.issue(t, pkix.Name{CommonName: "ethoca-staging", Organization: []string{"Ethoca"}}, x509.ExtKeyUsageClientAuth)
+	partnerCert, partnerKey := clientCA.issue(t, pkix.Name{CommonName: "partner"}, x509.ExtKeyUsageClientAuth)
+	untrustedCert, untrustedKey := otherCA.issue(t, pkix.Name{CommonName: "ethoca"}, x509.ExtKeyUsageClientAuth)
+
+	// Unlisted routes are served with or without a client certificate
+	status, _, err := get(newTestClient(t, serverCA, nil, nil), server, "/api/v6/cases")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	status, body, err := get(newTestClient(t, serverCA, partnerCert, partnerKey), server, "/api/v6/cases")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "CN=partner", body)
+
+	// Listed routes need an allowed subject, by common name or distinguished name
+	status, _, err = get(newTestClient(t, serverCA, nil, nil), server, "/api/v6/webhooks/ethoca")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _, err = get(newTestClient(t, serverCA, partnerCert, partnerKey), server, "/api/v6/webhooks/ethoca/stats")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _, err = get(newTestClient(t, serverCA, ethocaCert, ethocaKey), server, "/api/v6/webhooks/ethoca/stats")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	status, _, err = get(newTestClient(t, serverCA, stagingCert, stagingKey), server, "/api/v6/webhooks/ethoca")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	status, _, err = get(newTestClient(t, serverCA, ethocaCert, ethocaKey), server, "/api/v6/webhooks/ethocax")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status, "prefixes match whole path segments")
+
+	// Certificates from other CAs fail the handshake
+	_, _, err = get(newTestClient(t, serverCA, untrustedCert, untrustedKey), server, "/api/v6/cases")
+	assert.Error(t, err)
+
+	// Connections without a client certificate are refused when one is required
+	options.RequireClientCert = true
+	required, err := NewCertificates(options, logger.NewDatadogLogger())
+	require.NoError(t, err)
+	requiredServer := startTestServer(t, required, allowlist)
+	_, _, err = get(newTestClient(t, serverCA, nil, nil), requiredServer, "/api/v6/cases")
+	assert.Error(t, err)
+	status, _, err = get(newTestClient(t, serverCA, partnerCert, partnerKey), requiredServer, "/api/v6/cases")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestCertificates_Watch(t *testing.T) {
+	serverCA, clientCA, rotatedClientCA := newTestCA(t, "server-ca"), newTestCA(t, "client-ca"), newTestCA(t, "rotated-client-ca")
+	dir := t.TempDir()
+	options := writeServerFiles(t, dir, serverCA, clientCA, "localhost")
+	certificates, err := NewCertificates(options, logger.NewDatadogLogger())
+	require.NoError(t, err)
+	server := startTestServer(t, certificates, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go certificates.Watch(ctx)
+	// Let the watcher register the directory before the files change
+	time.Sleep(100 * time.Millisecond)
+
+	servedSerial := func() *big.Int {
+		connection, err := tls.Dial("tcp", server, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return nil
+		}
+		defer connection.Close()
+		return connection.ConnectionState().PeerCertificates[0].SerialNumber
+	}
+	original := servedSerial()
+	require.NotNil(t, original)
+
+	// Rotated files are served without a restart, with the rotated client CA bundle
+	rotatedCert, rotatedKey := rotatedClientCA.issue(t, pkix.Name{CommonName: "partner"}, x509.ExtKeyUsageClientAuth)
+	writeServerFiles(t, dir, serverCA, rotatedClientCA, "localhost")
+	require.Eventually(t, func() bool {
+		serial := servedSerial()
+		return serial != nil && serial.Cmp(original) != 0
+	}, 5*time.Second, 50*time.Millisecond)
+	status, _, err := get(newTestClient(t, serverCA, rotatedCert, rotatedKey), server, "/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	// A broken rotation leaves the previous certificates in use
+	rotated := servedSerial()
+	require.NoError(t, os.WriteFile(options.CertFile, []byte("not a certificate"), 0600))
+	time.Sleep(2 * reloadDelay)
+	assert.Equal(t, 0, servedSerial().Cmp(rotated))
+	assert.Error(t, certificates.Reload())
+}
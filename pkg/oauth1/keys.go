@@ -0,0 +1,72 @@
+package oauth1
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// LoadSigningKey loads an RSA signing key from a PKCS#12 keystore (.p12, .pfx) or, for
+// any other extension, a PEM encoded private key
+func LoadSigningKey(path, password string) (*rsa.PrivateKey, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".p12", ".pfx":
+		return LoadPKCS12Key(path, password)
+	default:
+		return LoadPEMKey(path)
+	}
+}
+
+// LoadPKCS12Key loads the RSA private key from a PKCS#12 keystore
+func LoadPKCS12Key(path, password string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("oauth1: read keystore: %w", err)
+	}
+
+	key, _, err := pkcs12.Decode(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("oauth1: decode keystore: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("oauth1: keystore key is %T, not RSA", key)
+	}
+	return rsaKey, nil
+}
+
+// LoadPEMKey loads an RSA private key from a PKCS#1 or PKCS#8 PEM file
+func LoadPEMKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("oauth1: read key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("oauth1: no PEM block found in key file")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("oauth1: parse key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("oauth1: key is %T, not RSA", key)
+	}
+	return rsaKey, nil
+}
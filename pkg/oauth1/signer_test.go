@@ -0,0 +1,203 @@
+package oauth1
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Known-good vectors, produced independently with:
+//
+//	printf '%s' "$BASE_STRING" | openssl dgst -sha256 -sign testdata/test_key.pem | base64
+//	printf '%s' "$BODY" | openssl dgst -sha256 -binary | base64
+const (
+	vectorURL         = "https://SANDBOX.api.mastercard.com:443/mastercom/v6/cases?b=2&a=1%203"
+	vectorBody        = `{"caseType":"1"}`
+	vectorBodyHash    = "/srG9/aW2yQYtTDlHZCsJcekBIHifKslRgWQF6/Z8Rc="
+	vectorConsumerKey = "test-consumer-key!abc"
+	vectorNonce       = "uTeLPs6K2Ho3wVKt"
+	vectorTimestamp   = 1700000000
+	vectorBaseString  = "POST&https%3A%2F%2Fsandbox.api.mastercard.com%2Fmastercom%2Fv6%2Fcases&a%3D1%25203%26b%3D2%26oauth_body_hash%3D%252FsrG9%252FaW2yQYtTDlHZCsJcekBIHifKslRgWQF6%252FZ8Rc%253D%26oauth_consumer_key%3Dtest-consumer-key%2521abc%26oauth_nonce%3DuTeLPs6K2Ho3wVKt%26oauth_signature_method%3DRSA-SHA256%26oauth_timestamp%3D1700000000%26oauth_version%3D1.0"
+	vectorSignature   = "Fbf3LE80QLy5TiM+GMED8fRno2I/WA9quTkAkpQUJVkjszrSxbjMlFK3ZhwTn/XkBtRn3rbPnszQ7LdLz4QXO4UPwcM24CT5wvm1tM5zStK7AIV2KU5Ps6ClGwq+KXOoQET+wWrX7cS37ZIDnc0l9wS23gEje35WVwaOqw8bnjTH3DJUYe7DoEof9FCl5brae58w0IhLmJbWguXEB9MGcAvAmJd2PawWGk25gNoTwti2azF554Y+v5dK+/SNl4TeVEit+2MuTQ4PRoAwWy/RbyzzTi+IpE3TIoGzSYMPr9FrIM4flr+JcDi8ZntRzVeNFSeB9DkO33xiRx7Zpmz9Hg=="
+	emptyBodyHash     = "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+)
+
+func setupTestSigner(t *testing.T) *Signer {
+	key, err := LoadPEMKey("testdata/test_key.pem")
+	require.NoError(t, err)
+
+	signer, err := NewSigner(vectorConsumerKey, key)
+	require.NoError(t, err)
+	signer.now = func() time.Time { return time.Unix(vectorTimestamp, 0) }
+	signer.nonce = func() (string, error) { return vectorNonce, nil }
+	return signer
+}
+
+// parseAuthorizationHeader returns the decoded parameters of an OAuth Authorization header
+func parseAuthorizationHeader(t *testing.T, header string) map[string]string {
+	require.True(t, strings.HasPrefix(header, "OAuth "))
+
+	params := map[string]string{}
+	for _, pair := range strings.Split(strings.TrimPrefix(header, "OAuth "), ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		require.True(t, ok)
+		decoded, err := url.PathUnescape(strings.Trim(value, `"`))
+		require.NoError(t, err)
+		params[key] = decoded
+	}
+	return params
+}
+
+func TestPercentEncode(t *testing.T) {
+	cases := map[string]string{
+		"Ladies + Gentlemen": "Ladies%20%2B%20Gentlemen",
+		"An encoded string!": "An%20encoded%20string%21",
+		"Dogs, Cats & Mice":  "Dogs%2C%20Cats%20%26%20Mice",
+		"☃":                  "%E2%98%83",
+		"-._~":               "-._~",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, PercentEncode(in), in)
+	}
+}
+
+func TestBodyHash(t *testing.T) {
+	assert.Equal(t, vectorBodyHash, BodyHash([]byte(vectorBody)))
+	assert.Equal(t, emptyBodyHash, BodyHash(nil))
+}
+
+func TestSignatureBaseString(t *testing.T) {
+	u, err := url.Parse(vectorURL)
+	require.NoError(t, err)
+
+	baseString, err := SignatureBaseString("post", u, map[string]string{
+		"oauth_body_hash":        vectorBodyHash,
+		"oauth_consumer_key":     vectorConsumerKey,
+		"oauth_nonce":            vectorNonce,
+		"oauth_signature_method": "RSA-SHA256",
+		"oauth_timestamp":        "1700000000",
+		"oauth_version":          "1.0",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, vectorBaseString, baseString)
+}
+
+func TestSigner_AuthorizationHeader(t *testing.T) {
+	signer := setupTestSigner(t)
+	u, err := url.Parse(vectorURL)
+	require.NoError(t, err)
+
+	header, err := signer.AuthorizationHeader(http.MethodPost, u, []byte(vectorBody))
+	require.NoError(t, err)
+
+	params := parseAuthorizationHeader(t, header)
+	assert.Equal(t, vectorSignature, params["oauth_signature"])
+	assert.Equal(t, vectorBodyHash, params["oauth_body_hash"])
+	assert.Equal(t, vectorConsumerKey, params["oauth_consumer_key"])
+	assert.Equal(t, "RSA-SHA256", params["oauth_signature_method"])
+	assert.Equal(t, "1700000000", params["oauth_timestamp"])
+
+	// The signature verifies against the public key
+	signature, err := base64.StdEncoding.DecodeString(params["oauth_signature"])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(vectorBaseString))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&signer.privateKey.PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func TestSigner_RandomNonce(t *testing.T) {
+	key, err := LoadPEMKey("testdata/test_key.pem")
+	require.NoError(t, err)
+	signer, err := NewSigner(vectorConsumerKey, key)
+	require.NoError(t, err)
+
+	u, _ := url.Parse("https://api.mastercard.com/mastercom/v6/queues/names")
+	first, err := signer.AuthorizationHeader(http.MethodGet, u, nil)
+	require.NoError(t, err)
+	second, err := signer.AuthorizationHeader(http.MethodGet, u, nil)
+	require.NoError(t, err)
+
+	firstNonce := parseAuthorizationHeader(t, first)["oauth_nonce"]
+	assert.Len(t, firstNonce, nonceLength)
+	assert.NotEqual(t, firstNonce, parseAuthorizationHeader(t, second)["oauth_nonce"])
+	assert.Equal(t, emptyBodyHash, parseAuthorizationHeader(t, first)["oauth_body_hash"])
+}
+
+func TestNewSigner_Invalid(t *testing.T) {
+	key, err := LoadPEMKey("testdata/test_key.pem")
+	require.NoError(t, err)
+
+	_, err = NewSigner("", key)
+	assert.Error(t, err)
+	_, err = NewSigner(vectorConsumerKey, nil)
+	assert.Error(t, err)
+}
+
+func TestTransport_SignsRequests(t *testing.T) {
+	signer := setupTestSigner(t)
+
+	var received *http.Request
+	var receivedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(signer, nil)}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/mastercom/v6/cases?b=2&a=1%203", strings.NewReader(vectorBody))
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The body still reaches the server and the caller's request is left unsigned
+	assert.Equal(t, vectorBody, receivedBody)
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	params := parseAuthorizationHeader(t, received.Header.Get("Authorization"))
+	assert.Equal(t, vectorBodyHash, params["oauth_body_hash"])
+
+	// The signature covers the test server URL, so recompute the base string for it
+	u, _ := url.Parse(server.URL + "/mastercom/v6/cases?b=2&a=1%203")
+	baseString, err := SignatureBaseString(http.MethodPost, u, map[string]string{
+		"oauth_body_hash":        vectorBodyHash,
+		"oauth_consumer_key":     vectorConsumerKey,
+		"oauth_nonce":            vectorNonce,
+		"oauth_signature_method": "RSA-SHA256",
+		"oauth_timestamp":        "1700000000",
+		"oauth_version":          "1.0",
+	})
+	require.NoError(t, err)
+	signature, err := base64.StdEncoding.DecodeString(params["oauth_signature"])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(baseString))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&signer.privateKey.PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	pemKey, err := LoadSigningKey("testdata/test_key.pem", "")
+	require.NoError(t, err)
+
+	p12Key, err := LoadSigningKey("testdata/test_keystore.p12", "keystorepassword")
+	require.NoError(t, err)
+	assert.True(t, pemKey.Equal(p12Key))
+
+	_, err = LoadSigningKey("testdata/test_keystore.p12", "wrong-password")
+	assert.Error(t, err)
+
+	_, err = LoadSigningKey("testdata/missing.pem", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package oauth1
+
+import (
+	"net/http"
+)
+
+// Transport is an http.RoundTripper that signs every request before sending it
+type Transport struct {
+	Signer *Signer
+	// Base sends the signed requests; http.DefaultTransport is used when nil
+	Base http.RoundTripper
+}
+
+// NewTransport creates a signing transport on top of base
+func NewTransport(signer *Signer, base http.RoundTripper) *Transport {
+	return &Transport{Signer: signer, Base: base}
+}
+
+// RoundTrip signs a copy of the request, leaving the caller's request unmodified
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed := req.Clone(req.Context())
+	if err := t.Signer.Sign(signed); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	return t.base().RoundTrip(signed)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
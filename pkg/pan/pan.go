@@ -0,0 +1,186 @@
+// Package pan validates, finds and masks payment card numbers (primary account numbers)
+package pan
+
+import "strings"
+
+// Card numbers are 13 to 19 digits long
+const (
+	MinLength = 13
+	MaxLength = 19
+)
+
+// Valid reports whether number is 13 to 19 digits passing the Luhn check
+func Valid(number string) bool {
+	if len(number) < MinLength || len(number) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(number); i++ {
+		if !isDigit(number[i]) {
+			return false
+		}
+	}
+	return Luhn(number)
+}
+
+// Luhn reports whether a string of digits passes the Luhn (mod 10) check
+func Luhn(digits string) bool {
+	if digits == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		if !isDigit(digits[i]) {
+			return false
+		}
+		digit := int(digits[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Mask replaces every digit but the last four with an asterisk, keeping any separators
+func Mask(number string) string {
+	masked := []byte(number)
+	keep := 4
+	for i := len(masked) - 1; i >= 0; i-- {
+		if !isDigit(masked[i]) {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		masked[i] = '*'
+	}
+	return string(masked)
+}
+
+// BINLength is the number of leading digits, the bank identification number, that may be
+// kept alongside the last four digits when a card number is truncated
+const BINLength = 6
+
+// BIN returns the bank identification number of a valid card number
+func BIN(number string) string {
+	return number[:BINLength]
+}
+
+// Last4 returns the last four digits of a valid card number
+func Last4(number string) string {
+	return number[len(number)-4:]
+}
+
+// Truncate keeps the BIN and last four digits of a valid card number, replacing the digits
+// between with asterisks
+func Truncate(number string) string {
+	return BIN(number) + strings.Repeat("*", len(number)-BINLength-4) + Last4(number)
+}
+
+// Truncated reports whether number has the form Truncate returns
+func Truncated(number string) bool {
+	if len(number) < MinLength || len(number) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(number); i++ {
+		if (i < BINLength || i >= len(number)-4) != isDigit(number[i]) {
+			return false
+		}
+		if !isDigit(number[i]) && number[i] != '*' {
+			return false
+		}
+	}
+	return true
+}
+
+// Match is the position of a card number in text, as byte offsets [Start, End). The card
+// number may be split into groups by single spaces or dashes.
+type Match struct {
+	Start, End int
+}
+
+// Number returns the digits of the card number matched in text
+func (m Match) Number(text string) string {
+	var digits strings.Builder
+	for i := m.Start; i < m.End; i++ {
+		if isDigit(text[i]) {
+			digits.WriteByte(text[i])
+		}
+	}
+	return digits.String()
+}
+
+// digitGroup is a run of digits in text
+type digitGroup struct {
+	start, end int
+}
+
+// Find returns the card numbers in text. Digits are read in groups, such as the blocks of
+// four printed on receipts, joined by a single space or dash. A card number starts at the
+// start of a group and ends at the end of one, so digits inside a longer number, such as an
+// order reference, are never matched. The longest valid number from each group wins.
+func Find(text string) []Match {
+	var matches []Match
+	var groups []digitGroup
+
+	flush := func() {
+		for first := 0; first < len(groups); {
+			matched := false
+			for last := len(groups) - 1; last >= first; last-- {
+				if digits := countDigits(groups[first : last+1]); digits >= MinLength && digits <= MaxLength {
+					match := Match{Start: groups[first].start, End: groups[last].end}
+					if Luhn(match.Number(text)) {
+						matches = append(matches, match)
+						first = last + 1
+						matched = true
+						break
+					}
+				}
+			}
+			if !matched {
+				first++
+			}
+		}
+		groups = groups[:0]
+	}
+
+	for i := 0; i < len(text); {
+		if !isDigit(text[i]) {
+			flush()
+			i++
+			continue
+		}
+		start := i
+		for i < len(text) && isDigit(text[i]) {
+			i++
+		}
+		groups = append(groups, digitGroup{start: start, end: i})
+
+		// A single separator continues the number; anything else ends it
+		if i+1 < len(text) && (text[i] == ' ' || text[i] == '-') && isDigit(text[i+1]) {
+			i++
+			continue
+		}
+		flush()
+	}
+	flush()
+	return matches
+}
+
+func countDigits(groups []digitGroup) int {
+	count := 0
+	for _, group := range groups {
+		count += group.end - group.start
+	}
+	return count
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,85 @@
+package pan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("4111111111111111"))
+	assert.True(t, Valid("5555555555554444"))
+	assert.True(t, Valid("378282246310005"))
+	assert.True(t, Valid("6011000990139424"))
+
+	assert.False(t, Valid("4111111111111112"), "fails the Luhn check")
+	assert.False(t, Valid("4111 1111 1111 1111"), "separators")
+	assert.False(t, Valid("4222222222"), "too short")
+	assert.False(t, Valid("41111111111111111111"), "too long")
+	assert.False(t, Valid(""))
+}
+
+func TestLuhn(t *testing.T) {
+	assert.True(t, Luhn("79927398713"))
+	assert.False(t, Luhn("79927398710"))
+	assert.False(t, Luhn("7992739871a"))
+	assert.False(t, Luhn(""))
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "************1111", Mask("4111111111111111"))
+	assert.Equal(t, "**** **** **** 4444", Mask("5555 5555 5555 4444"))
+	assert.Equal(t, "***-1234", Mask("987-1234"))
+	assert.Equal(t, "123", Mask("123"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "411111", BIN("4111111111111111"))
+	assert.Equal(t, "1111", Last4("4111111111111111"))
+	assert.Equal(t, "411111******1111", Truncate("4111111111111111"))
+	assert.Equal(t, "378282*****0005", Truncate("378282246310005"))
+
+	assert.True(t, Truncated("411111******1111"))
+	assert.True(t, Truncated("378282*****0005"))
+	assert.False(t, Truncated("4111111111111111"), "not truncated")
+	assert.False(t, Truncated("************1111"), "no BIN")
+	assert.False(t, Truncated("411111**1*****1111"), "digits between")
+	assert.False(t, Truncated("411111-*****1111"))
+	assert.False(t, Truncated("411111*1111"), "too short")
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "plain", text: "Card 4111111111111111 declined", want: []string{"4111111111111111"}},
+		{name: "grouped by spaces", text: "PAN: 4111 1111 1111 1111.", want: []string{"4111 1111 1111 1111"}},
+		{name: "grouped by dashes", text: "5555-5555-5555-4444", want: []string{"5555-5555-5555-4444"}},
+		{name: "followed by other digits", text: "4111 1111 1111 1111 12/25", want: []string{"4111 1111 1111 1111"}},
+		{name: "preceded by other digits", text: "Ref 12345 4111 1111 1111 1111", want: []string{"4111 1111 1111 1111"}},
+		{name: "several", text: "378282246310005 and 6011000990139424", want: []string{"378282246310005", "6011000990139424"}},
+		{name: "failing the Luhn check", text: "4111111111111112", want: nil},
+		{name: "inside a longer number", text: "order 41111111111111111111", want: nil},
+		{name: "double separator", text: "4111  1111 1111 1111", want: nil},
+		{name: "too short", text: "4222222222", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var found []string
+			for _, match := range Find(tt.text) {
+				found = append(found, tt.text[match.Start:match.End])
+			}
+			assert.Equal(t, tt.want, found)
+		})
+	}
+}
+
+func TestMatch_Number(t *testing.T) {
+	text := "Card 4111-1111-1111-1111"
+	matches := Find(text)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "4111111111111111", matches[0].Number(text))
+}
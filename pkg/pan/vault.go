@@ -0,0 +1,128 @@
+package pan
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"mastercom-service/pkg/envelope"
+)
+
+// TokenPrefix starts every token issued by LocalVault
+const TokenPrefix = "tok_"
+
+var (
+	// ErrInvalidNumber is returned when tokenizing something that is not a valid card number
+	ErrInvalidNumber = errors.New("invalid card number")
+	// ErrTokenNotFound is returned when detokenizing a token the vault did not issue
+	ErrTokenNotFound = errors.New("token not found")
+)
+
+// Vault exchanges card numbers for tokens that stand in for them, and tokens back for the
+// card numbers. Callers keep only tokens. An implementation backed by an HSM or a
+// tokenization provider can replace LocalVault without other changes.
+type Vault interface {
+	Tokenize(ctx context.Context, number string) (string, error)
+	Detokenize(ctx context.Context, token string) (string, error)
+}
+
+// LocalVault is an in-memory Vault, so card numbers are lost with it. Each is sealed in an
+// envelope whose data key is wrapped by the vault's key ring. Tokens are random, and a card
+// number tokenized again gets the same token.
+type LocalVault struct {
+	keys *envelope.KeyRing
+	// indexKey keys the HMAC that finds the token of a card number already tokenized
+	indexKey []byte
+	// sealed holds each token's card number
+	sealed map[string]*envelope.Envelope
+	index  map[string]string
+	mutex  sync.RWMutex
+}
+
+// NewLocalVault creates an empty in-memory vault sealing card numbers under keys, or under a
+// key generated for the vault when keys is nil
+func NewLocalVault(keys *envelope.KeyRing) *LocalVault {
+	if keys == nil {
+		keys, _ = envelope.NewKeyRing("local", map[string][]byte{"local": envelope.GenerateKey()})
+	}
+
+	return &LocalVault{
+		keys:     keys,
+		indexKey: envelope.GenerateKey(),
+		sealed:   make(map[string]*envelope.Envelope),
+		index:    make(map[string]string),
+	}
+}
+
+// Tokenize returns the token standing in for a valid card number
+func (v *LocalVault) Tokenize(ctx context.Context, number string) (string, error) {
+	if !Valid(number) {
+		return "", ErrInvalidNumber
+	}
+
+	mac := hmac.New(sha256.New, v.indexKey)
+	mac.Write([]byte(number))
+	fingerprint := hex.EncodeToString(mac.Sum(nil))
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if token, exists := v.index[fingerprint]; exists {
+		return token, nil
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	token := TokenPrefix + hex.EncodeToString(id)
+
+	// The token is bound to its envelope, so sealed numbers cannot be swapped between tokens
+	v.sealed[token] = v.keys.Seal([]byte(number), []byte(token))
+	v.index[fingerprint] = token
+	return token, nil
+}
+
+// Detokenize returns the card number a token stands in for
+func (v *LocalVault) Detokenize(ctx context.Context, token string) (string, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return "", ErrTokenNotFound
+	}
+
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	sealed, exists := v.sealed[token]
+	if !exists {
+		return "", ErrTokenNotFound
+	}
+
+	number, err := v.keys.Open(sealed, []byte(token))
+	if err != nil {
+		return "", fmt.Errorf("opening token %s: %w", token, err)
+	}
+	return string(number), nil
+}
+
+// RewrapKeys re-wraps the data keys of the card numbers in the vault with the primary key of
+// its key ring, and returns the number re-wrapped
+func (v *LocalVault) RewrapKeys() (int, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	rewrapped := 0
+	for token, sealed := range v.sealed {
+		changed, err := v.keys.Rewrap(sealed)
+		if err != nil {
+			return rewrapped, fmt.Errorf("re-wrapping token %s: %w", token, err)
+		}
+		if changed {
+			rewrapped++
+		}
+	}
+	return rewrapped, nil
+}
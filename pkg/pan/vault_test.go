@@ -0,0 +1,66 @@
+package pan
+
+import (
+	"testing"
+
+	"mastercom-service/pkg/envelope"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalVault(t *testing.T) {
+	vault := NewLocalVault(nil)
+	ctx := t.Context()
+
+	token, err := vault.Tokenize(ctx, "4111111111111111")
+	require.NoError(t, err)
+	assert.Regexp(t, `^tok_[0-9a-f]{32}$`, token)
+	assert.NotContains(t, token, "1111")
+
+	number, err := vault.Detokenize(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "4111111111111111", number)
+
+	// The same number keeps its token; another gets its own
+	again, err := vault.Tokenize(ctx, "4111111111111111")
+	require.NoError(t, err)
+	assert.Equal(t, token, again)
+	other, err := vault.Tokenize(ctx, "5555555555554444")
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	_, err = vault.Tokenize(ctx, "4111111111111112")
+	assert.ErrorIs(t, err, ErrInvalidNumber)
+	_, err = vault.Detokenize(ctx, "tok_unknown")
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+	_, err = vault.Detokenize(ctx, "4111111111111111")
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+
+	// Tokens from another vault are not recognised
+	_, err = NewLocalVault(nil).Detokenize(ctx, token)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
+
+func TestLocalVault_RewrapKeys(t *testing.T) {
+	keys, err := envelope.NewKeyRing("k1", map[string][]byte{"k1": envelope.GenerateKey()})
+	require.NoError(t, err)
+	vault := NewLocalVault(keys)
+	ctx := t.Context()
+
+	token, err := vault.Tokenize(ctx, "4111111111111111")
+	require.NoError(t, err)
+	assert.Equal(t, "k1", vault.sealed[token].KeyID)
+
+	k2 := envelope.GenerateKey()
+	require.NoError(t, keys.Add("k2", map[string][]byte{"k2": k2}))
+	rewrapped, err := vault.RewrapKeys()
+	require.NoError(t, err)
+	assert.Equal(t, 1, rewrapped)
+	assert.Equal(t, "k2", vault.sealed[token].KeyID)
+
+	require.NoError(t, keys.Load("k2", map[string][]byte{"k2": k2}))
+	number, err := vault.Detokenize(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "4111111111111111", number)
+}
@@ -0,0 +1,130 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker is a consecutive-failure circuit breaker with half-open probing
+type breaker struct {
+	threshold   int
+	openTimeout time.Duration
+	probes      int
+	now         func() time.Time
+	onChange    func(from, to State)
+
+	mutex    sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  int
+}
+
+func newBreaker(config Config, now func() time.Time, onChange func(from, to State)) *breaker {
+	probes := config.HalfOpenProbes
+	if probes <= 0 {
+		probes = 1
+	}
+	return &breaker{
+		threshold:   config.FailureThreshold,
+		openTimeout: config.OpenTimeout,
+		probes:      probes,
+		now:         now,
+		onChange:    onChange,
+		state:       StateClosed,
+	}
+}
+
+// allow reports whether a request may be sent, admitting it as a probe when half-open
+func (b *breaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == StateOpen {
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.transition(StateHalfOpen)
+	}
+	if b.state == StateHalfOpen {
+		if b.probing >= b.probes {
+			return ErrCircuitOpen
+		}
+		b.probing++
+	}
+	return nil
+}
+
+// record reports the outcome of an allowed request
+func (b *breaker) record(success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	case StateHalfOpen:
+		b.probing--
+		if success {
+			b.failures = 0
+			b.transition(StateClosed)
+			return
+		}
+		b.open()
+	}
+}
+
+// cancel releases an allowed request that was never sent
+func (b *breaker) cancel() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == StateHalfOpen && b.probing > 0 {
+		b.probing--
+	}
+}
+
+func (b *breaker) open() {
+	b.openedAt = b.now()
+	b.probing = 0
+	b.transition(StateOpen)
+}
+
+func (b *breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
+
+func (b *breaker) snapshot() (State, int, time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state := b.state
+	// An open circuit whose timeout has passed admits the next request as a probe
+	if state == StateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		state = StateHalfOpen
+	}
+	return state, b.failures, b.openedAt
+}
@@ -0,0 +1,119 @@
+// Package resilience protects calls to partner APIs with per-endpoint rate limits, a cap
+// on concurrent requests, a circuit breaker and budgeted retries that honour Retry-After.
+//
+// A Policy holds the state shared by every client of one partner; Policy.Wrap returns an
+// http.RoundTripper that applies it. Wrap the transport that signs requests, so that each
+// retry is signed afresh.
+package resilience
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen is returned without calling the partner while its circuit is open
+	ErrCircuitOpen = errors.New("resilience: circuit open")
+	// ErrTooManyInFlight is returned when no concurrency slot frees up within the queue timeout
+	ErrTooManyInFlight = errors.New("resilience: too many requests in flight")
+)
+
+// State is the state of a circuit breaker
+type State string
+
+const (
+	// StateClosed lets every request through
+	StateClosed State = "closed"
+	// StateOpen fails requests fast until the open timeout has passed
+	StateOpen State = "open"
+	// StateHalfOpen lets a limited number of probe requests through to test recovery
+	StateHalfOpen State = "half-open"
+)
+
+// Limit is a token bucket refilled at Rate requests per second, holding up to Burst tokens.
+// A zero Rate is unlimited.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Config configures a Policy. Zero values disable the corresponding protection.
+type Config struct {
+	// DefaultLimit applies to every endpoint without an entry in EndpointLimits
+	DefaultLimit Limit
+	// EndpointLimits overrides the limit per endpoint, keyed like "GET /v6/claims/{id}"
+	EndpointLimits map[string]Limit
+	// Endpoint names the endpoint of a request; DefaultEndpoint is used when nil
+	Endpoint func(*http.Request) string
+
+	// MaxConcurrent caps the requests in flight to the partner
+	MaxConcurrent int
+	// QueueTimeout is how long a request waits for a concurrency slot
+	QueueTimeout time.Duration
+
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before probing
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of concurrent probe requests while half-open
+	HalfOpenProbes int
+
+	// MaxRetries is the number of retries per request
+	MaxRetries int
+	// RetryBackoff is the first retry delay when the partner sends no Retry-After, doubled
+	// on each further retry
+	RetryBackoff time.Duration
+	// MaxRetryAfter is the longest Retry-After honoured; longer waits are not retried
+	MaxRetryAfter time.Duration
+	// RetryBudget is the ratio of retries to requests allowed over time
+	RetryBudget float64
+	// RetryBudgetBurst is the number of retries allowed before the budget has accrued
+	RetryBudgetBurst int
+
+	// Metrics receives request, retry and circuit measurements when set
+	Metrics Metrics
+	// OnStateChange is called when the circuit changes state
+	OnStateChange func(name string, from, to State)
+}
+
+// Metrics receives measurements. It matches the DogStatsD client, so a statsd client can
+// be passed directly.
+type Metrics interface {
+	Gauge(name string, value float64, tags []string, rate float64) error
+	Incr(name string, tags []string, rate float64) error
+	Timing(name string, value time.Duration, tags []string, rate float64) error
+}
+
+// idSegment matches path segments that identify a resource rather than name an operation
+var idSegment = regexp.MustCompile(`[0-9]`)
+
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// DefaultEndpoint names a request by its method and path, with segments containing digits
+// (other than version segments) replaced by {id}
+func DefaultEndpoint(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		if idSegment.MatchString(segment) && !versionSegment.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
+
+func (c Config) endpoint(req *http.Request) string {
+	if c.Endpoint != nil {
+		return c.Endpoint(req)
+	}
+	return DefaultEndpoint(req)
+}
+
+func (c Config) limit(endpoint string) Limit {
+	if limit, ok := c.EndpointLimits[endpoint]; ok {
+		return limit
+	}
+	return c.DefaultLimit
+}
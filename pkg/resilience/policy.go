@@ -0,0 +1,437 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Stats is a snapshot of a policy's state and counters
+type Stats struct {
+	Name                string     `json:"name"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+	InFlight            int64      `json:"inFlight"`
+	Requests            int64      `json:"requests"`
+	Retries             int64      `json:"retries"`
+	RejectedOpen        int64      `json:"rejectedOpen"`
+	RejectedInFlight    int64      `json:"rejectedInFlight"`
+	RetryBudget         float64    `json:"retryBudget"`
+}
+
+// Policy is the rate limiters, concurrency slots, circuit breaker and retry budget shared
+// by every client of one partner
+type Policy struct {
+	name    string
+	config  Config
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+	breaker *breaker
+	slots   chan struct{}
+
+	mutex       sync.Mutex
+	limiters    map[string]*rate.Limiter
+	retryTokens float64
+
+	inFlight         atomic.Int64
+	requests         atomic.Int64
+	retries          atomic.Int64
+	rejectedOpen     atomic.Int64
+	rejectedInFlight atomic.Int64
+}
+
+// NewPolicy creates the shared state for a partner
+func NewPolicy(name string, config Config) *Policy {
+	p := &Policy{
+		name:        name,
+		config:      config,
+		now:         time.Now,
+		sleep:       sleepContext,
+		limiters:    make(map[string]*rate.Limiter),
+		retryTokens: float64(config.RetryBudgetBurst),
+	}
+	if config.MaxConcurrent > 0 {
+		p.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	p.breaker = newBreaker(config, func() time.Time { return p.now() }, p.stateChanged)
+	return p
+}
+
+// Name returns the partner name
+func (p *Policy) Name() string {
+	return p.name
+}
+
+// Wrap returns a transport that applies the policy to requests sent through next
+func (p *Policy) Wrap(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{policy: p, next: next}
+}
+
+// Stats returns a snapshot of the policy
+func (p *Policy) Stats() Stats {
+	state, failures, openedAt := p.breaker.snapshot()
+
+	p.mutex.Lock()
+	budget := p.retryTokens
+	p.mutex.Unlock()
+
+	stats := Stats{
+		Name:                p.name,
+		State:               state,
+		ConsecutiveFailures: failures,
+		InFlight:            p.inFlight.Load(),
+		Requests:            p.requests.Load(),
+		Retries:             p.retries.Load(),
+		RejectedOpen:        p.rejectedOpen.Load(),
+		RejectedInFlight:    p.rejectedInFlight.Load(),
+		RetryBudget:         budget,
+	}
+	if state != StateClosed {
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
+
+// Transport is an http.RoundTripper applying a Policy
+type Transport struct {
+	policy *Policy
+	next   http.RoundTripper
+}
+
+// RoundTrip sends a request, retrying throttled and failed attempts within the budget
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.policy
+	ctx := req.Context()
+	endpoint := p.config.endpoint(req)
+	p.depositRetryToken()
+
+	for attempt := 0; ; attempt++ {
+		outgoing := req
+		if attempt > 0 {
+			var err error
+			if outgoing, err = rewind(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.attempt(ctx, outgoing, endpoint)
+
+		delay, retry := p.retryDelay(req, resp, err, attempt)
+		if !retry || !rewindable(req) || !p.withdrawRetryToken() {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		p.retries.Add(1)
+		p.incr("partner.retries", endpoint)
+		if err := p.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends a request once, subject to the circuit, rate limit and concurrency cap
+func (t *Transport) attempt(ctx context.Context, req *http.Request, endpoint string) (*http.Response, error) {
+	p := t.policy
+
+	if err := p.breaker.allow(); err != nil {
+		p.rejectedOpen.Add(1)
+		p.incr("partner.rejected", endpoint, "reason:circuit_open")
+		closeBody(req)
+		return nil, err
+	}
+
+	if err := p.limiter(endpoint).Wait(ctx); err != nil {
+		p.breaker.cancel()
+		closeBody(req)
+		return nil, err
+	}
+
+	release, err := p.acquire(ctx)
+	if err != nil {
+		p.breaker.cancel()
+		p.rejectedInFlight.Add(1)
+		p.incr("partner.rejected", endpoint, "reason:in_flight")
+		closeBody(req)
+		return nil, err
+	}
+
+	p.requests.Add(1)
+	start := p.now()
+	resp, err := t.next.RoundTrip(req)
+	p.observe(endpoint, start, resp, err)
+
+	switch {
+	case ctx.Err() != nil:
+		// The caller gave up; that says nothing about the partner
+		p.breaker.cancel()
+	default:
+		p.breaker.record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	}
+
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The slot is held until the caller has read the response
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// retryDelay decides whether and when to retry an attempt. Throttling (429) and
+// unavailability (503) mean the partner did not act on the request, so any method is
+// retried; other failures are only retried for idempotent methods.
+func (p *Policy) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.config.MaxRetries {
+		return 0, false
+	}
+
+	backoff := p.config.RetryBackoff << attempt
+	switch {
+	case errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrTooManyInFlight):
+		return 0, false
+	case err != nil:
+		if req.Context().Err() != nil || !idempotent(req.Method) {
+			return 0, false
+		}
+		return backoff, true
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), p.now()); ok {
+			if p.config.MaxRetryAfter > 0 && retryAfter > p.config.MaxRetryAfter {
+				return 0, false
+			}
+			return retryAfter, true
+		}
+		return backoff, true
+	case resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusGatewayTimeout:
+		return backoff, idempotent(req.Method)
+	default:
+		return 0, false
+	}
+}
+
+// depositRetryToken credits the budget for a new request
+func (p *Policy) depositRetryToken() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.retryTokens = math.Min(p.retryTokens+p.config.RetryBudget, math.Max(float64(p.config.RetryBudgetBurst), 1))
+}
+
+// withdrawRetryToken spends budget on a retry, reporting whether there was any
+func (p *Policy) withdrawRetryToken() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.retryTokens < 1 {
+		return false
+	}
+	p.retryTokens--
+	return true
+}
+
+func (p *Policy) limiter(endpoint string) *rate.Limiter {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	limiter, ok := p.limiters[endpoint]
+	if !ok {
+		limit := p.config.limit(endpoint)
+		if limit.Rate <= 0 {
+			limiter = rate.NewLimiter(rate.Inf, 0)
+		} else {
+			burst := limit.Burst
+			if burst <= 0 {
+				burst = 1
+			}
+			limiter = rate.NewLimiter(rate.Limit(limit.Rate), burst)
+		}
+		p.limiters[endpoint] = limiter
+	}
+	return limiter
+}
+
+// acquire takes a concurrency slot, waiting at most the queue timeout
+func (p *Policy) acquire(ctx context.Context) (func(), error) {
+	if p.slots == nil {
+		p.inFlight.Add(1)
+		return func() { p.inFlight.Add(-1) }, nil
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		timer := time.NewTimer(p.config.QueueTimeout)
+		defer timer.Stop()
+
+		select {
+		case p.slots <- struct{}{}:
+		case <-timer.C:
+			return nil, ErrTooManyInFlight
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	p.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.inFlight.Add(-1)
+			<-p.slots
+		})
+	}, nil
+}
+
+func (p *Policy) observe(endpoint string, start time.Time, resp *http.Response, err error) {
+	if p.config.Metrics == nil {
+		return
+	}
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	tags := p.tags(endpoint, "status:"+status)
+	p.config.Metrics.Timing("partner.request.duration", p.now().Sub(start), tags, 1)
+	p.config.Metrics.Incr("partner.requests", tags, 1)
+	p.config.Metrics.Gauge("partner.in_flight", float64(p.inFlight.Load()), p.tags(""), 1)
+
+	state, _, _ := p.breaker.snapshot()
+	p.config.Metrics.Gauge("partner.circuit_state", stateValue(state), p.tags(""), 1)
+}
+
+func (p *Policy) stateChanged(from, to State) {
+	if p.config.Metrics != nil {
+		p.config.Metrics.Gauge("partner.circuit_state", stateValue(to), p.tags(""), 1)
+		p.config.Metrics.Incr("partner.circuit_transitions", p.tags("", "state:"+string(to)), 1)
+	}
+	if p.config.OnStateChange != nil {
+		p.config.OnStateChange(p.name, from, to)
+	}
+}
+
+func (p *Policy) incr(name, endpoint string, extra ...string) {
+	if p.config.Metrics != nil {
+		p.config.Metrics.Incr(name, p.tags(endpoint, extra...), 1)
+	}
+}
+
+func (p *Policy) tags(endpoint string, extra ...string) []string {
+	tags := []string{"partner:" + p.name}
+	if endpoint != "" {
+		tags = append(tags, "endpoint:"+endpoint)
+	}
+	return append(tags, extra...)
+}
+
+// stateValue maps a circuit state to a gauge value: 0 closed, 1 half-open, 2 open
+func stateValue(state State) float64 {
+	switch state {
+	case StateHalfOpen:
+		return 1
+	case StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// releasingBody frees a concurrency slot when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// rewindable reports whether a request's body can be sent again
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of a request with a fresh body for a retry
+func rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry.Body = body
+	return retry, nil
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
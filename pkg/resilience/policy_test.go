@@ -0,0 +1,340 @@
+package resilience
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable clock whose sleeps advance time
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mutex.Lock()
+	c.slept = append(c.slept, d)
+	c.mutex.Unlock()
+	c.Advance(d)
+	return ctx.Err()
+}
+
+// recordingMetrics keeps the names of submitted metrics
+type recordingMetrics struct {
+	mutex sync.Mutex
+	names []string
+	tags  [][]string
+}
+
+func (m *recordingMetrics) record(name string, tags []string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.names = append(m.names, name)
+	m.tags = append(m.tags, tags)
+	return nil
+}
+
+func (m *recordingMetrics) Gauge(name string, value float64, tags []string, rate float64) error {
+	return m.record(name, tags)
+}
+
+func (m *recordingMetrics) Incr(name string, tags []string, rate float64) error {
+	return m.record(name, tags)
+}
+
+func (m *recordingMetrics) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	return m.record(name, tags)
+}
+
+func setupPolicy(config Config) (*Policy, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 7, 16, 12, 0, 0, 0, time.UTC)}
+	policy := NewPolicy("mastercom", config)
+	policy.now = clock.Now
+	policy.sleep = clock.Sleep
+	return policy, clock
+}
+
+func newClient(policy *Policy) *http.Client {
+	return &http.Client{Transport: policy.Wrap(nil)}
+}
+
+func TestDefaultEndpoint(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v6/claims/200002020654/chargebacks/300002063556/documents", nil)
+	assert.Equal(t, "GET /v6/claims/{id}/chargebacks/{id}/documents", DefaultEndpoint(req))
+
+	req = httptest.NewRequest(http.MethodPut, "/v6/chargebacks/acknowledge", nil)
+	assert.Equal(t, "PUT /v6/chargebacks/acknowledge", DefaultEndpoint(req))
+}
+
+func TestTransport_RetriesHonourRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mutex.Lock()
+		bodies = append(bodies, string(body))
+		mutex.Unlock()
+
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy, clock := setupPolicy(Config{MaxRetries: 2, RetryBackoff: time.Second, MaxRetryAfter: 10 * time.Second, RetryBudget: 0.2, RetryBudgetBurst: 5})
+
+	resp, err := newClient(policy).Post(server.URL+"/v6/cases", "application/json", strings.NewReader(`{"caseType":"1"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// A throttled POST is retried with the same body after the advertised delay
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []time.Duration{3 * time.Second}, clock.slept)
+	assert.Equal(t, []string{`{"caseType":"1"}`, `{"caseType":"1"}`}, bodies)
+
+	stats := policy.Stats()
+	assert.Equal(t, int64(2), stats.Requests)
+	assert.Equal(t, int64(1), stats.Retries)
+	assert.Equal(t, int64(0), stats.InFlight)
+}
+
+func TestTransport_RetryLimits(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusBadGateway
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if status == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", "120")
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	policy, clock := setupPolicy(Config{MaxRetries: 2, RetryBackoff: time.Second, MaxRetryAfter: 10 * time.Second, RetryBudgetBurst: 10})
+	client := newClient(policy)
+
+	// Idempotent requests back off exponentially up to the retry limit
+	resp, err := client.Get(server.URL + "/v6/queues/names")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.slept)
+
+	// A bad gateway may have reached the partner, so POSTs are not retried
+	calls.Store(0)
+	resp, err = client.Post(server.URL+"/v6/cases", "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Waits longer than the maximum Retry-After are returned to the caller
+	status = http.StatusServiceUnavailable
+	calls.Store(0)
+	resp, err = client.Get(server.URL + "/v6/queues/names")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTransport_RetryBudget(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy, _ := setupPolicy(Config{MaxRetries: 3, RetryBudget: 0.5, RetryBudgetBurst: 2})
+	client := newClient(policy)
+
+	// The burst allows two retries, after which each request earns half a retry
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL + "/v6/healthcheck")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, int64(2+1), policy.Stats().Retries)
+	assert.Equal(t, int32(3+2+1), calls.Load())
+}
+
+func TestTransport_CircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var transitions []State
+	metrics := &recordingMetrics{}
+	policy, clock := setupPolicy(Config{
+		FailureThreshold: 2,
+		OpenTimeout:      30 * time.Second,
+		Metrics:          metrics,
+		OnStateChange:    func(name string, from, to State) { transitions = append(transitions, to) },
+	})
+	client := newClient(policy)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/v6/healthcheck")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, StateOpen, policy.Stats().State)
+
+	// While open, requests fail fast without reaching the partner
+	_, err := client.Get(server.URL + "/v6/healthcheck")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int64(1), policy.Stats().RejectedOpen)
+
+	// After the open timeout a failed probe reopens the circuit
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, StateHalfOpen, policy.Stats().State)
+	resp, err := client.Get(server.URL + "/v6/healthcheck")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, StateOpen, policy.Stats().State)
+
+	// A successful probe closes it
+	failing.Store(false)
+	clock.Advance(30 * time.Second)
+	resp, err = client.Get(server.URL + "/v6/healthcheck")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	stats := policy.Stats()
+	assert.Equal(t, StateClosed, stats.State)
+	assert.Nil(t, stats.OpenedAt)
+	assert.Equal(t, []State{StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}, transitions)
+	assert.Contains(t, metrics.names, "partner.circuit_state")
+	assert.Contains(t, metrics.names, "partner.rejected")
+	assert.Contains(t, metrics.tags[0], "partner:mastercom")
+}
+
+func TestTransport_HalfOpenAdmitsLimitedProbes(t *testing.T) {
+	policy, clock := setupPolicy(Config{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenProbes: 1})
+
+	require.NoError(t, policy.breaker.allow())
+	policy.breaker.record(false)
+	clock.Advance(time.Second)
+
+	require.NoError(t, policy.breaker.allow())
+	assert.ErrorIs(t, policy.breaker.allow(), ErrCircuitOpen)
+
+	// A probe that was never sent frees its place
+	policy.breaker.cancel()
+	assert.NoError(t, policy.breaker.allow())
+}
+
+func TestTransport_ConcurrencyCap(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy, _ := setupPolicy(Config{MaxConcurrent: 1, QueueTimeout: 10 * time.Millisecond})
+	client := newClient(policy)
+
+	done := make(chan error)
+	go func() {
+		resp, err := client.Get(server.URL + "/v6/queues/names")
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	<-started
+	assert.Equal(t, int64(1), policy.Stats().InFlight)
+
+	_, err := client.Get(server.URL + "/v6/queues/names")
+	assert.ErrorIs(t, err, ErrTooManyInFlight)
+	assert.Equal(t, int64(1), policy.Stats().RejectedInFlight)
+
+	close(release)
+	require.NoError(t, <-done)
+	assert.Equal(t, int64(0), policy.Stats().InFlight)
+}
+
+func TestTransport_RateLimitPerEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy := NewPolicy("mastercom", Config{
+		EndpointLimits: map[string]Limit{"GET /v6/queues/names": {Rate: 20, Burst: 1}},
+	})
+	client := newClient(policy)
+
+	get := func(path string) {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// Unlimited endpoints are not delayed
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		get("/v6/healthcheck")
+	}
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// The limited endpoint is held to 20 requests per second
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		get("/v6/queues/names")
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	first := registry.Policy("mastercom", Config{MaxRetries: 1})
+	assert.Same(t, first, registry.Policy("mastercom", Config{MaxRetries: 5}))
+	registry.Policy("ethoca", Config{})
+
+	stats := registry.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "ethoca", stats[0].Name)
+	assert.Equal(t, StateClosed, stats[1].State)
+}
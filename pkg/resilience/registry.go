@@ -0,0 +1,48 @@
+package resilience
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry shares one Policy per partner between the clients that call it
+type Registry struct {
+	mutex    sync.Mutex
+	policies map[string]*Policy
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{policies: make(map[string]*Policy)}
+}
+
+// Policy returns the partner's policy, creating it from config on first use. Later calls
+// return the existing policy and ignore config.
+func (r *Registry) Policy(name string, config Config) *Policy {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	policy, ok := r.policies[name]
+	if !ok {
+		policy = NewPolicy(name, config)
+		r.policies[name] = policy
+	}
+	return policy
+}
+
+// Stats returns a snapshot of every policy, ordered by partner name
+func (r *Registry) Stats() []Stats {
+	r.mutex.Lock()
+	policies := make([]*Policy, 0, len(r.policies))
+	for _, policy := range r.policies {
+		policies = append(policies, policy)
+	}
+	r.mutex.Unlock()
+
+	stats := make([]Stats, 0, len(policies))
+	for _, policy := range policies {
+		stats = append(stats, policy.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
@@ -12,10 +12,10 @@ import (
 
 	"mastercom-service/internal/handlers"
 	"mastercom-service/internal/models"
+	"mastercom-service/pkg/logger"
 	"mastercom-service/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,8 +24,7 @@ func setupIntegrationTestServer() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	
 	// Initialize logger
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger := logger.NewDatadogLogger()
 	
 	// Initialize handlers
 	handlers.InitHandlers(logger)
@@ -127,7 +126,7 @@ func TestCompleteCaseWorkflow(t *testing.T) {
 	assert.Equal(t, "PENDING", createdCase.Status)
 	
 	// Step 2: Upload a document for the case
-	documentContent := "This is a test document for the case"
+	documentContent := "%PDF-1.4\n1 0 obj\n<< /Type /Pages /Kids [] /Count 1 >>\nendobj\n%%EOF\n"
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 	
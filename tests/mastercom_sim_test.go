@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mastercom-service/internal/models"
+	"mastercom-service/internal/services"
+	"mastercom-service/internal/simulator"
+	"mastercom-service/pkg/logger"
+	"mastercom-service/pkg/mastercom"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMastercomSimulator starts the simulator and returns a client for it
+func setupMastercomSimulator(t *testing.T) (*httptest.Server, *mastercom.Client) {
+	gin.SetMode(gin.TestMode)
+
+	sim, err := simulator.New(simulator.Options{ImageDelay: time.Millisecond, ReconDelay: time.Millisecond}, nil)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(sim.Handler())
+	t.Cleanup(server.Close)
+
+	client, err := mastercom.NewClient(server.URL)
+	require.NoError(t, err)
+	return server, client
+}
+
+// postSimulator calls the simulator's admin API
+func postSimulator(t *testing.T, server *httptest.Server, path string, body, out interface{}) {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(data))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Less(t, resp.StatusCode, 300)
+
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+}
+
+func createSimulatorCase(t *testing.T, caseService *services.CaseService, documentService *services.DocumentService) *models.Case {
+	caseObj := &models.Case{
+		ID:                   "sim-case-id",
+		CaseType:             "1",
+		PrimaryAccountNumber: "4111111111111111",
+		TransactionAmount:    100.00,
+		TransactionCurrency:  "USD",
+		TransactionDate:      time.Now(),
+		TransactionID:        "123456789",
+		MerchantName:         "Test Merchant",
+		ReasonCode:           "4853",
+		FilingAs:             "ISSUER",
+		FilingIca:            "123456",
+		FiledAgainstIca:      "654321",
+		QueueName:            models.QueueWorked,
+	}
+	require.NoError(t, caseService.CreateCase(caseObj))
+	require.NoError(t, documentService.UploadDocument(models.NewDocument(caseObj.ID, "evidence.pdf", ".pdf", []byte("%PDF-1.4"), "test-user", "")))
+
+	_, err := caseService.TransitionCase(caseObj.ID, models.QueueActionSubmit)
+	require.NoError(t, err)
+	return caseObj
+}
+
+func TestMastercomSimulator_SubmissionAndQueueSync(t *testing.T) {
+	server, client := setupMastercomSimulator(t)
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	documentService := services.NewDocumentService(logger)
+	claimService := services.NewClaimService(logger)
+
+	// A case submitted locally is filed with Mastercom
+	caseObj := createSimulatorCase(t, caseService, documentService)
+	submission := services.NewSubmissionWorker(caseService, documentService, client,
+		&models.SubmissionConfig{Enabled: true, Interval: 1, MaxAttempts: 3, BackoffBase: 30, BackoffMax: 90}, logger)
+	assert.Equal(t, 1, submission.ProcessOnce(context.Background()))
+
+	filed, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, filed.MastercomCaseID)
+	assert.Equal(t, models.SubmissionStatusSubmitted, filed.SubmissionStatus)
+
+	// A chargeback raised by the counterparty waits in the Unworked queue
+	var seeded simulator.SeedChargebackResponse
+	postSimulator(t, server, "/__sim/chargebacks", simulator.SeedChargebackRequest{IssuerICA: "654321", AcquirerICA: "123456"}, &seeded)
+
+	store := services.NewFileSyncStateStore(filepath.Join(t.TempDir(), "queue-sync.json"))
+	sync := services.NewQueueSyncWorker(claimService, caseService, client, store,
+		&models.QueueSyncConfig{Enabled: true, Interval: 1, InitialLookback: 1, MaxLag: 600}, logger)
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, sync.SyncOnce(context.Background()))
+
+	claim, err := claimService.GetClaim(seeded.ClaimID)
+	require.NoError(t, err)
+	require.Len(t, claim.Chargebacks, 1)
+	assert.True(t, claim.Chargebacks[0].Acknowledged)
+
+	synced, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Open", synced.MastercomStatus)
+	assert.Equal(t, "COMPLETED", synced.MastercomImageStatus)
+
+	// Acknowledging moved the claim to Worked in Mastercom
+	detail, err := client.GetClaimDetail(context.Background(), seeded.ClaimID)
+	require.NoError(t, err)
+	assert.Equal(t, "Worked", detail.QueueName)
+}
+
+func TestMastercomSimulator_InjectedOutageIsRetried(t *testing.T) {
+	server, client := setupMastercomSimulator(t)
+	logger := logger.NewDatadogLogger()
+	caseService := services.NewCaseService(logger)
+	documentService := services.NewDocumentService(logger)
+
+	postSimulator(t, server, "/__sim/faults", simulator.FaultRule{Method: "POST", PathPrefix: "/v6/cases", StatusCode: http.StatusServiceUnavailable, Times: 1}, nil)
+
+	caseObj := createSimulatorCase(t, caseService, documentService)
+	submission := services.NewSubmissionWorker(caseService, documentService, client,
+		&models.SubmissionConfig{Enabled: true, Interval: 1, MaxAttempts: 3, BackoffBase: 30, BackoffMax: 90}, logger)
+	submission.ProcessOnce(context.Background())
+
+	pending, err := caseService.GetCase(caseObj.ID)
+	require.NoError(t, err)
+	assert.Empty(t, pending.MastercomCaseID)
+	assert.Equal(t, models.SubmissionStatusPending, pending.SubmissionStatus)
+	assert.Equal(t, 1, pending.SubmissionAttempts)
+	require.NotNil(t, pending.NextSubmissionAt)
+	require.Len(t, pending.SubmissionErrors, 1)
+	assert.Equal(t, "Simulator", pending.SubmissionErrors[0].Source)
+}